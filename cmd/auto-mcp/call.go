@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/brizzai/auto-mcp/internal/config"
+	"github.com/brizzai/auto-mcp/internal/plugins"
+	"github.com/brizzai/auto-mcp/internal/requester"
+	"github.com/brizzai/auto-mcp/internal/secrets"
+	"github.com/brizzai/auto-mcp/internal/server/tool"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/spf13/cobra"
+)
+
+var callCmd = &cobra.Command{
+	Use:   "call <tool_name>",
+	Short: "Call a tool directly and print its result",
+	Long: `Call builds the upstream request for a tool exactly as the server does, with the
+configured authentication, headers, argument defaults and body encoding, sends it
+and prints the tool result. It needs no MCP client, which makes it handy for
+debugging a single tool. Tools acting on behalf of the signed-in user cannot be
+called, as there is none.`,
+	Example: `  auto-mcp call get_pet_petid --args '{"petId": 42}'
+  auto-mcp call post_pet --args @pet.json --verbose`,
+	Args: cobra.ExactArgs(1),
+	RunE: runCall,
+}
+
+var (
+	callArgs    string
+	callVerbose bool
+)
+
+func init() {
+	callCmd.Flags().StringVar(&callArgs, "args", "{}", "Tool arguments as a JSON object, or @file to read them from a file")
+	callCmd.Flags().BoolVar(&callVerbose, "verbose", false, "Print the upstream request and the response headers to stderr, with credentials redacted")
+}
+
+func runCall(cmd *cobra.Command, args []string) error {
+	arguments, err := readCallArguments(callArgs)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if _, err := secrets.NewResolver(cmd.Context(), cfg); err != nil {
+		return fmt.Errorf("failed to resolve secrets: %w", err)
+	}
+	tools, err := loadRouteTools(cfg)
+	if err != nil {
+		return err
+	}
+
+	name := args[0]
+	var route *mcp.Tool
+	var routeConfig *requester.RouteConfig
+	for _, t := range tools {
+		if t.Tool.Name == name {
+			route, routeConfig = &t.Tool, t.RouteConfig
+			break
+		}
+	}
+	if route == nil {
+		return fmt.Errorf("unknown tool %q, run \"auto-mcp tools\" to list the available tools", name)
+	}
+
+	r := requester.NewHTTPRequester(requester.HTTPRequesterParams{
+		ServiceConfig: &cfg.EndpointConfig,
+		AuthManager:   requester.NewHTTPAuthManager(&cfg.EndpointConfig),
+	})
+	hooks, err := plugins.Load(cfg.Plugins)
+	if err != nil {
+		return fmt.Errorf("failed to load plugins: %w", err)
+	}
+	r.SetHooks(hooks)
+	if callVerbose {
+		r.SetTransport(&tracingTransport{out: cmd.ErrOrStderr(), next: http.DefaultTransport})
+	}
+	executor, err := r.BuildRouteExecutor(routeConfig)
+	if err != nil {
+		return fmt.Errorf("failed to build request for %s: %w", name, err)
+	}
+
+	handler := tool.NewHandler(cfg, false, nil, nil).CreateHandler(route, routeConfig, executor)
+	request := mcp.CallToolRequest{}
+	request.Params.Name = name
+	request.Params.Arguments = arguments
+	result, err := handler(cmd.Context(), request)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if result.IsError {
+		out = cmd.ErrOrStderr()
+	}
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			_, _ = fmt.Fprintln(out, prettyJSON(text.Text))
+		}
+	}
+	if len(result.Meta) > 0 {
+		meta, _ := json.MarshalIndent(result.Meta, "", "  ")
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "_meta: %s\n", meta)
+	}
+	if result.IsError {
+		return errors.New("the tool returned an error")
+	}
+	return nil
+}
+
+// readCallArguments returns the --args JSON object, reading it from a file
+// when it starts with @. The raw JSON is passed on like arguments sent by an
+// MCP client, so numbers keep their precision.
+func readCallArguments(value string) (json.RawMessage, error) {
+	data := []byte(value)
+	if path, ok := strings.CutPrefix(value, "@"); ok {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("failed to read arguments: %w", err)
+		}
+	}
+
+	var arguments map[string]json.RawMessage
+	if err := json.Unmarshal(data, &arguments); err != nil || arguments == nil {
+		return nil, fmt.Errorf("--args must be a JSON object")
+	}
+	return data, nil
+}
+
+// prettyJSON indents JSON text and returns anything else unchanged
+func prettyJSON(text string) string {
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, []byte(text), "", "  "); err != nil {
+		return text
+	}
+	return indented.String()
+}
+
+// tracingTransport prints each upstream request and response head
+type tracingTransport struct {
+	out  io.Writer
+	next http.RoundTripper
+}
+
+func (t *tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	_, _ = fmt.Fprintf(t.out, "> %s %s\n", req.Method, req.URL)
+	writeHeaders(t.out, "> ", req.Header)
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			data, _ := io.ReadAll(io.LimitReader(body, 4096))
+			_ = body.Close()
+			if len(data) > 0 {
+				_, _ = fmt.Fprintf(t.out, ">\n%s\n", data)
+			}
+		}
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	_, _ = fmt.Fprintf(t.out, "< %s %s\n", resp.Proto, resp.Status)
+	writeHeaders(t.out, "< ", resp.Header)
+	_, _ = fmt.Fprintln(t.out)
+	return resp, nil
+}
+
+func writeHeaders(w io.Writer, prefix string, header http.Header) {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range header[name] {
+			if config.IsSecretKey(name) {
+				value = "[REDACTED]"
+			}
+			_, _ = fmt.Fprintf(w, "%s%s: %s\n", prefix, name, value)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/brizzai/auto-mcp/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the configuration",
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the effective configuration with secrets redacted and the source of each value",
+	Args:  cobra.NoArgs,
+	RunE:  runConfigShow,
+}
+
+func init() {
+	configCmd.AddCommand(configShowCmd)
+}
+
+// runConfigShow prints the settings even when the configuration is invalid,
+// so the offending value can be found
+func runConfigShow(cmd *cobra.Command, args []string) error {
+	_, loadErr := config.Load()
+	if err := config.WriteEffectiveSettings(cmd.OutOrStdout()); err != nil {
+		return fmt.Errorf("failed to print configuration: %w", err)
+	}
+	if loadErr != nil {
+		return fmt.Errorf("configuration error: %w", loadErr)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"github.com/brizzai/auto-mcp/internal/parser"
+	"github.com/spf13/cobra"
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff <old-spec> <new-spec>",
+	Short: "Show the tool changes between two versions of a spec",
+	Long: `Diff parses both spec versions with the same adjustments file and reports added,
+removed and changed tools, including argument and response schema changes, so
+API upgrades can be reviewed before new tools reach agents. Changes that may
+break existing callers are marked. With --adjustments it also lists adjustments
+entries that no longer match an operation of the new spec.`,
+	Example: `  auto-mcp diff petstore-v1.json petstore-v2.json --adjustments adjustments.yaml`,
+	Args:    cobra.ExactArgs(2),
+	RunE:    runDiff,
+}
+
+var (
+	diffAdjustments string
+	diffFormat      string
+)
+
+func init() {
+	diffCmd.Flags().StringVar(&diffAdjustments, "adjustments", "", "Adjustments file applied to both versions")
+	diffCmd.Flags().StringVarP(&diffFormat, "format", "o", parser.DiffFormatText, "Output format (text|json)")
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	diff, err := parser.DiffSpecs(args[0], args[1], diffAdjustments)
+	if err != nil {
+		return err
+	}
+	return diff.Write(cmd.OutOrStdout(), diffFormat)
+}
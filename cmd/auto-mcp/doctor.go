@@ -0,0 +1,238 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/brizzai/auto-mcp/internal/auth/providers"
+	"github.com/brizzai/auto-mcp/internal/auth/store"
+	"github.com/brizzai/auto-mcp/internal/config"
+	"github.com/brizzai/auto-mcp/internal/parser"
+	"github.com/brizzai/auto-mcp/internal/plugins"
+	"github.com/brizzai/auto-mcp/internal/requester"
+	"github.com/brizzai/auto-mcp/internal/secrets"
+	"github.com/spf13/cobra"
+	"golang.org/x/oauth2/github"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check that the server is ready to serve, end to end",
+	Long: `Doctor goes beyond validate: besides the configuration, spec and adjustments file
+it loads plugins, resolves Vault secrets, checks that the upstream API is reachable, calls
+endpoint.probe_path with the configured credentials when it is set, and checks
+that the OAuth identity provider and token store can be reached. It prints a
+report of every check and exits with a non-zero status when one fails.`,
+	Args: cobra.NoArgs,
+	RunE: runDoctor,
+}
+
+var doctorTimeout time.Duration
+
+func init() {
+	doctorCmd.Flags().DurationVar(&doctorTimeout, "timeout", 10*time.Second, "Timeout of each network check")
+}
+
+// Check results
+const (
+	checkPass = "PASS"
+	checkFail = "FAIL"
+	checkSkip = "SKIP"
+)
+
+// doctorReport prints check results as they complete
+type doctorReport struct {
+	out    io.Writer
+	failed int
+}
+
+func (r *doctorReport) add(status, check, detail string) {
+	if status == checkFail {
+		r.failed++
+	}
+	_, _ = fmt.Fprintf(r.out, "%s  %-13s  %s\n", status, check, detail)
+}
+
+// addResult records a pass with detail, or a failure with err
+func (r *doctorReport) addResult(check, detail string, err error) {
+	if err != nil {
+		r.add(checkFail, check, err.Error())
+		return
+	}
+	r.add(checkPass, check, detail)
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	report := &doctorReport{out: cmd.OutOrStdout()}
+
+	cfg, err := config.Load()
+	report.addResult("configuration", "loaded", err)
+	if err != nil {
+		return errors.New("the configuration could not be loaded")
+	}
+
+	resolver, secretsErr := secrets.NewResolver(cmd.Context(), cfg)
+	switch {
+	case secretsErr != nil:
+		report.add(checkFail, "secrets", secretsErr.Error())
+	case resolver == nil:
+		report.add(checkSkip, "secrets", "no vault:// references")
+	default:
+		report.add(checkPass, "secrets", "resolved from Vault")
+	}
+
+	if len(cfg.Plugins) == 0 {
+		report.add(checkSkip, "plugins", "no plugins configured")
+	} else {
+		_, err := plugins.Load(cfg.Plugins)
+		report.addResult("plugins", fmt.Sprintf("%d loaded", len(cfg.Plugins)), err)
+	}
+
+	doctorAdjustments(report, cfg)
+	tools, err := loadRouteTools(cfg)
+	report.addResult("spec", fmt.Sprintf("%s generates %d tools", cfg.SwaggerFile, len(tools)), err)
+
+	doctorUpstream(cmd.Context(), report, cfg, secretsErr == nil)
+	doctorOAuth(cmd.Context(), report, cfg)
+
+	if report.failed > 0 {
+		return fmt.Errorf("%d checks failed", report.failed)
+	}
+	return nil
+}
+
+// doctorAdjustments loads the adjustments file on its own, as the parser
+// ignores a missing file
+func doctorAdjustments(report *doctorReport, cfg *config.Config) {
+	if cfg.AdjustmentsFile == "" {
+		report.add(checkSkip, "adjustments", "no adjustments file configured")
+		return
+	}
+	if _, err := os.Stat(cfg.AdjustmentsFile); err != nil {
+		report.add(checkFail, "adjustments", err.Error())
+		return
+	}
+	err := parser.NewAdjuster().Load(cfg.AdjustmentsFile)
+	report.addResult("adjustments", cfg.AdjustmentsFile+" loaded", err)
+}
+
+// doctorUpstream checks that the base URL answers, then calls the probe
+// route through the requester so headers, auth and session login apply
+func doctorUpstream(ctx context.Context, report *doctorReport, cfg *config.Config, resolved bool) {
+	endpoint := &cfg.EndpointConfig
+	if endpoint.BaseURL == "" {
+		report.add(checkFail, "upstream", "endpoint.base_url is not set")
+		return
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, doctorTimeout)
+	defer cancel()
+	status, err := doctorGet(checkCtx, endpoint.BaseURL)
+	report.addResult("upstream", fmt.Sprintf("%s answered HTTP %d", endpoint.BaseURL, status), err)
+	if err != nil {
+		return
+	}
+
+	switch {
+	case endpoint.ProbePath == "":
+		report.add(checkSkip, "upstream auth", "set endpoint.probe_path to check the credentials")
+		return
+	case !resolved:
+		report.add(checkSkip, "upstream auth", "the credentials could not be resolved")
+		return
+	case endpoint.ForwardAuthToken && endpoint.AuthType == config.AuthTypeNone:
+		report.add(checkSkip, "upstream auth", "requests use the caller's forwarded token")
+		return
+	}
+
+	r := requester.NewHTTPRequester(requester.HTTPRequesterParams{
+		ServiceConfig: endpoint,
+		AuthManager:   requester.NewHTTPAuthManager(endpoint),
+	})
+	r.SetTimeout(doctorTimeout)
+	executor, err := r.BuildRouteExecutor(&requester.RouteConfig{Method: http.MethodGet, Path: endpoint.ProbePath})
+	if err != nil {
+		report.add(checkFail, "upstream auth", err.Error())
+		return
+	}
+
+	resp, err := executor(ctx, nil)
+	probe := "GET " + endpoint.ProbePath
+	switch {
+	case err != nil:
+		report.add(checkFail, "upstream auth", fmt.Sprintf("%s: %v", probe, err))
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		report.add(checkFail, "upstream auth", fmt.Sprintf("%s: credentials rejected with HTTP %d", probe, resp.StatusCode))
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		report.add(checkFail, "upstream auth", fmt.Sprintf("%s: unexpected HTTP %d", probe, resp.StatusCode))
+	default:
+		report.add(checkPass, "upstream auth", fmt.Sprintf("%s answered HTTP %d", probe, resp.StatusCode))
+	}
+}
+
+// doctorOAuth creates the identity provider, which discovers OIDC issuers,
+// and connects to the token store
+func doctorOAuth(ctx context.Context, report *doctorReport, cfg *config.Config) {
+	if cfg.OAuth == nil || !cfg.OAuth.Enabled {
+		report.add(checkSkip, "oauth", "oauth is disabled")
+		return
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, doctorTimeout)
+	defer cancel()
+
+	oauth := cfg.OAuth
+	if oauth.Provider != "internal" && oauth.ClientID == "" {
+		report.add(checkFail, "oauth", "oauth.client_id is not set")
+	} else {
+		var detail string
+		var err error
+		switch oauth.Provider {
+		case "google":
+			_, err = providers.NewGoogleProvider(oauth)
+			detail = "discovered https://accounts.google.com"
+		case "github":
+			_, err = doctorGet(checkCtx, github.Endpoint.AuthURL)
+			detail = "github.com is reachable"
+		case "oidc":
+			_, err = providers.NewOIDCProvider(checkCtx, oauth)
+			detail = "discovered " + oauth.IssuerURL
+		case "internal":
+			_, err = providers.NewInternalProvider(oauth)
+			detail = "users loaded"
+		default:
+			err = fmt.Errorf("unknown provider %q", oauth.Provider)
+		}
+		report.addResult("oauth", detail, err)
+	}
+
+	if oauth.Store.Type != store.TypeRedis {
+		return
+	}
+	s, err := store.New(oauth.Store)
+	if err == nil {
+		_ = s.Close()
+	}
+	report.addResult("oauth store", "connected to Redis "+oauth.Store.Redis.Addr, err)
+}
+
+// doctorGet reports the status of a plain GET; any response means the
+// server is reachable
+func doctorGet(ctx context.Context, url string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	_ = resp.Body.Close()
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/brizzai/auto-mcp/internal/config"
+	"github.com/brizzai/auto-mcp/internal/parser"
+	"github.com/brizzai/auto-mcp/internal/tui"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// defaultEditAdjustmentsFile is where serve --edit saves the edits when no
+// adjustments file is configured
+const defaultEditAdjustmentsFile = "adjustments.yaml"
+
+// errEditQuit is returned when the TUI of serve --edit is quit before the
+// edits are saved
+var errEditQuit = errors.New("the editor was quit before saving, the server was not started")
+
+// editAdjustments opens the config-builder TUI on the spec of cfg and saves
+// the edits into its adjustments file, which the server then starts with.
+// Without an adjustments file they are saved into adjustments.yaml.
+func editAdjustments(cfg *config.Config) error {
+	// The TUI takes the terminal that stdio serves over
+	if cfg.Server.Mode == config.ServerModeSTDIO {
+		return fmt.Errorf("--edit needs the sse or http mode, stdio servers are launched by MCP clients")
+	}
+	configured := cfg.AdjustmentsFile != ""
+	if !configured {
+		cfg.AdjustmentsFile = defaultEditAdjustmentsFile
+	}
+
+	adjuster := parser.NewAdjuster()
+	swaggerParser := parser.NewSwaggerParser(adjuster)
+	if err := swaggerParser.Init(cfg.SwaggerFile, ""); err != nil {
+		return err
+	}
+	if _, err := os.Stat(cfg.AdjustmentsFile); err == nil {
+		if err := adjuster.Load(cfg.AdjustmentsFile); err != nil {
+			return fmt.Errorf("failed to load adjustments file: %w", err)
+		}
+	}
+
+	app := tui.NewAppModel(swaggerParser.GetRouteTools(), adjuster).
+		WithAdjustmentsFile(cfg.AdjustmentsFile).
+		WithSwaggerFile(cfg.SwaggerFile).
+		WithSaveOnFinish()
+	m, err := tea.NewProgram(app, tea.WithAltScreen()).Run()
+	if err != nil {
+		return fmt.Errorf("failed to run the editor: %w", err)
+	}
+	final := m.(tui.AppModel)
+	if err := final.Err(); err != nil {
+		return err
+	}
+	if !final.IsFinished() {
+		return errEditQuit
+	}
+	if !configured {
+		fmt.Fprintf(os.Stderr, "Saved the adjustments into %s, pass --adjustments-file %s to serve with them next time\n", cfg.AdjustmentsFile, cfg.AdjustmentsFile)
+	}
+	return nil
+}
@@ -3,86 +3,48 @@ package main
 import (
 	"context"
 	"fmt"
-	"log"
 	"os"
-	"runtime/debug"
 
 	"github.com/brizzai/auto-mcp/internal/config"
-	"github.com/brizzai/auto-mcp/internal/parser"
-	"github.com/brizzai/auto-mcp/internal/requester"
-	"github.com/brizzai/auto-mcp/internal/server"
-
-	"github.com/brizzai/auto-mcp/internal/logger"
+	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
-	"go.uber.org/fx"
-	"go.uber.org/zap"
 )
 
 func main() {
-	// Initialize all command-line flags
-	showVersion := pflag.BoolP("version", "v", false, "Show version information")
-	config.InitFlags()
-	pflag.Parse()
-
-	if *showVersion {
-		fmt.Println(config.GetVersionInfo())
-		os.Exit(0)
-	}
-
-	// Load configuration
-	cfg, err := config.Load()
-	if err != nil {
-		log.Fatalf("Failed to load configuration: %v", err)
-	}
-
-	// Override disable_console setting if server mode is stdio
-	if cfg.Server.Mode == config.ServerModeSTDIO {
-		cfg.Logging.DisableConsole = true
+	if err := rootCmd.ExecuteContext(context.Background()); err != nil {
+		os.Exit(1)
 	}
+}
 
-	// Initialize logger
-	if err := logger.InitLogger(&cfg.Logging); err != nil {
-		log.Fatalf("Failed to initialize logger: %v", err)
-	}
+// rootCmd serves the MCP server when no subcommand is given, so existing
+// invocations keep working
+var rootCmd = &cobra.Command{
+	Use:   "auto-mcp",
+	Short: "Serve an OpenAPI/Swagger API as an MCP server",
+	Long: `Auto MCP turns an OpenAPI/Swagger definition into an MCP server, exposing every
+operation as a tool. Configuration comes from config.yaml, AUTO_MCP_* environment
+variables and the flags below.`,
+	Args:         cobra.NoArgs,
+	RunE:         runServe,
+	SilenceUsage: true,
+	Version:      config.GetVersionInfo(),
+}
 
-	// Recover from panics
-	defer func() {
-		if r := recover(); r != nil {
-			logger.Error("Application panic recovered",
-				zap.Any("error", r),
-				zap.String("stack", string(debug.Stack())))
-		}
-	}()
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print version information",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), config.GetVersionInfo())
+	},
+}
 
-	// Create app with dependencies
-	app := fx.New(
-		fx.NopLogger,
-		parser.Module,
-		server.Module,
-		requester.Module,
-		// Config Provider
-		fx.Provide(func() *config.Config { return cfg }),
-		fx.Provide(func() *config.EndpointConfig { return &cfg.EndpointConfig }),
-		fx.Invoke(func(lc fx.Lifecycle, srv *server.Server) {
-			appCtx, cancel := context.WithCancel(context.Background())
-			lc.Append(fx.Hook{
-				OnStart: func(ctx context.Context) error {
-					go func() {
-						if err := srv.Start(appCtx); err != nil {
-							logger.Error("Server exited with error", zap.Error(err))
-							os.Exit(1)
-						}
-					}()
-					return nil
-				},
-				OnStop: func(ctx context.Context) error {
-					cancel()
-					return nil
-				},
-			})
-		}),
-	)
+func init() {
+	// The configuration flags are shared by every command and read by config.Load
+	config.InitFlags()
+	rootCmd.PersistentFlags().AddFlagSet(pflag.CommandLine)
+	rootCmd.SetVersionTemplate("{{.Version}}\n")
 
-	// Start the application
-	app.Run()
+	addDumpToolsFlag(rootCmd)
+	rootCmd.AddCommand(serveCmd, validateCmd, doctorCmd, toolsCmd, callCmd, diffCmd, versionCmd, configCmd)
 }
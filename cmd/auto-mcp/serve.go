@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime/debug"
+	"time"
+
+	"github.com/brizzai/auto-mcp/internal/config"
+	"github.com/brizzai/auto-mcp/internal/logger"
+	"github.com/brizzai/auto-mcp/internal/parser"
+	"github.com/brizzai/auto-mcp/internal/requester"
+	"github.com/brizzai/auto-mcp/internal/secrets"
+	"github.com/brizzai/auto-mcp/internal/server"
+	"github.com/brizzai/auto-mcp/internal/telemetry"
+	"github.com/brizzai/auto-mcp/internal/workspace"
+	"github.com/spf13/cobra"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run the MCP server (the default command)",
+	Args:  cobra.NoArgs,
+	RunE:  runServe,
+}
+
+// serveEdit opens the config-builder TUI before serving
+var serveEdit bool
+
+// dumpToolsDir is set by the deprecated --dump-tools flag, replaced by
+// "auto-mcp tools --out-dir"
+var dumpToolsDir string
+
+func init() {
+	addDumpToolsFlag(serveCmd)
+	serveCmd.Flags().BoolVar(&serveEdit, "edit", false, "Edit the adjustments of the spec in the config-builder TUI, save them and serve with them")
+}
+
+func addDumpToolsFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&dumpToolsDir, "dump-tools", "", "Write the generated tool schemas as JSON files to this directory and exit")
+	_ = cmd.Flags().MarkDeprecated("dump-tools", "use \"auto-mcp tools --out-dir <dir>\" instead")
+}
+
+// runServe loads the configuration and runs the server until it is stopped
+func runServe(cmd *cobra.Command, args []string) error {
+	// Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	if dumpToolsDir != "" {
+		return dumpToolSchemas(cmd, cfg, dumpToolsDir)
+	}
+
+	if serveEdit {
+		if err := editAdjustments(cfg); err != nil {
+			return err
+		}
+	}
+
+	// Override disable_console setting if server mode is stdio
+	if cfg.Server.Mode == config.ServerModeSTDIO {
+		cfg.Logging.DisableConsole = true
+	}
+
+	// Initialize logger
+	if err := logger.InitLogger(&cfg.Logging); err != nil {
+		return fmt.Errorf("failed to initialize logger: %w", err)
+	}
+
+	// Resolve vault:// credentials before anything reads them
+	resolver, err := secrets.NewResolver(context.Background(), cfg)
+	if err != nil {
+		return fmt.Errorf("failed to resolve secrets: %w", err)
+	}
+
+	// Recover from panics
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("Application panic recovered",
+				zap.Any("error", r),
+				zap.String("stack", string(debug.Stack())))
+		}
+	}()
+
+	// Create app with dependencies
+	app := fx.New(
+		fx.NopLogger,
+		// Leave the server time to drain before fx gives up on OnStop
+		fx.StopTimeout(cfg.Server.ShutdownGracePeriod()+5*time.Second),
+		parser.Module,
+		server.Module,
+		requester.Module,
+		telemetry.Module,
+		workspace.Module,
+		// Config Provider
+		fx.Provide(func() *config.Config { return cfg }),
+		fx.Provide(func() *config.EndpointConfig { return &cfg.EndpointConfig }),
+		fx.Invoke(func(lc fx.Lifecycle, srv *server.Server, ws *workspace.Workspace) {
+			srv.UseSecrets(resolver)
+			srv.UseWorkspace(ws)
+			appCtx, cancel := context.WithCancel(context.Background())
+			stopped := make(chan struct{})
+			lc.Append(fx.Hook{
+				OnStart: func(ctx context.Context) error {
+					go func() {
+						defer close(stopped)
+						if err := srv.Start(appCtx); err != nil {
+							logger.Error("Server exited with error", zap.Error(err))
+							os.Exit(1)
+						}
+					}()
+					return nil
+				},
+				OnStop: func(ctx context.Context) error {
+					cancel()
+					// Wait for in-flight requests to drain
+					select {
+					case <-stopped:
+						return nil
+					case <-ctx.Done():
+						return ctx.Err()
+					}
+				},
+			})
+		}),
+	)
+
+	// Start the application
+	app.Run()
+	return nil
+}
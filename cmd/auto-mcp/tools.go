@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/brizzai/auto-mcp/internal/config"
+	"github.com/brizzai/auto-mcp/internal/parser"
+	"github.com/spf13/cobra"
+)
+
+var toolsCmd = &cobra.Command{
+	Use:   "tools",
+	Short: "List the tools generated from the spec and adjustments",
+	Long: `Tools parses the spec and adjustments file like the server does and prints every
+generated tool with its HTTP method and path, description and arguments, without
+starting the server. Use --format markdown to review the MCP surface in pull requests.`,
+	Args: cobra.NoArgs,
+	RunE: runTools,
+}
+
+var (
+	toolsOutDir string
+	toolsFormat string
+)
+
+func init() {
+	toolsCmd.Flags().StringVarP(&toolsFormat, "format", "o", parser.CatalogFormatTable, "Output format (table|json|markdown)")
+	toolsCmd.Flags().StringVar(&toolsOutDir, "out-dir", "", "Write one JSON schema file per tool to this directory instead of listing them")
+}
+
+func runTools(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if toolsOutDir != "" {
+		return dumpToolSchemas(cmd, cfg, toolsOutDir)
+	}
+
+	tools, err := loadRouteTools(cfg)
+	if err != nil {
+		return err
+	}
+	return parser.WriteToolCatalog(cmd.OutOrStdout(), tools, toolsFormat)
+}
+
+// loadRouteTools parses the spec and adjustments into tools named as the
+// server registers them
+func loadRouteTools(cfg *config.Config) ([]*parser.RouteTool, error) {
+	adjuster := parser.NewAdjuster()
+	adjuster.SetLocale(cfg.Server.Locale)
+	p := parser.NewSwaggerParser(adjuster)
+	if err := p.Init(cfg.SwaggerFile, cfg.AdjustmentsFile); err != nil {
+		return nil, fmt.Errorf("failed to parse swagger file: %w", err)
+	}
+	tools := p.GetRouteTools()
+	for _, tool := range tools {
+		tool.Tool.Name = cfg.Server.ToolPrefix + tool.Tool.Name
+	}
+	return tools, nil
+}
+
+// dumpToolSchemas writes one JSON file per generated tool
+func dumpToolSchemas(cmd *cobra.Command, cfg *config.Config, dir string) error {
+	tools, err := loadRouteTools(cfg)
+	if err != nil {
+		return err
+	}
+	if err := parser.WriteToolSchemas(dir, tools); err != nil {
+		return fmt.Errorf("failed to write tool schemas: %w", err)
+	}
+	_, err = fmt.Fprintf(cmd.OutOrStdout(), "Wrote %d tool schemas to %s\n", len(tools), dir)
+	return err
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/brizzai/auto-mcp/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the configuration, spec and adjustments without starting the server",
+	Long: `Validate loads the configuration the same way the server does and parses the
+spec and adjustments file. It exits with a non-zero status on the first error,
+so it can run in CI before a deployment.`,
+	Args: cobra.NoArgs,
+	RunE: runValidate,
+}
+
+func runValidate(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	tools, err := loadRouteTools(cfg)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(cmd.OutOrStdout(), "Configuration is valid: %s generates %d tools\n", cfg.SwaggerFile, len(tools))
+	return err
+}
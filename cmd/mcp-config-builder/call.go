@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/brizzai/auto-mcp/internal/config"
+	"github.com/brizzai/auto-mcp/internal/plugins"
+	"github.com/brizzai/auto-mcp/internal/requester"
+	"github.com/brizzai/auto-mcp/internal/secrets"
+	"github.com/brizzai/auto-mcp/internal/tui"
+)
+
+// newCallFunc sends the requests of routes tried in the TUI like auto-mcp
+// does, to the endpoint and with the credentials of its configuration:
+// config.yaml, AUTO_MCP_* variables and --base-url. When the configuration
+// can't be loaded, trying a route shows why.
+func newCallFunc(ctx context.Context) tui.CallFunc {
+	r, err := newRequester(ctx)
+	if err != nil {
+		return func(context.Context, *requester.RouteConfig, map[string]any) (*requester.Response, error) {
+			return nil, err
+		}
+	}
+	return func(ctx context.Context, route *requester.RouteConfig, args map[string]any) (*requester.Response, error) {
+		executor, err := r.BuildRouteExecutor(route)
+		if err != nil {
+			return nil, err
+		}
+		return executor(ctx, args)
+	}
+}
+
+func newRequester(ctx context.Context) (*requester.HTTPRequester, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the auto-mcp configuration: %w", err)
+	}
+	if _, err := secrets.NewResolver(ctx, cfg); err != nil {
+		return nil, fmt.Errorf("failed to resolve secrets: %w", err)
+	}
+
+	r := requester.NewHTTPRequester(requester.HTTPRequesterParams{
+		ServiceConfig: &cfg.EndpointConfig,
+		AuthManager:   requester.NewHTTPAuthManager(&cfg.EndpointConfig),
+	})
+	hooks, err := plugins.Load(cfg.Plugins)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load plugins: %w", err)
+	}
+	r.SetHooks(hooks)
+	return r, nil
+}
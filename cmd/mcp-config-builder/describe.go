@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/brizzai/auto-mcp/internal/describer"
+	"github.com/brizzai/auto-mcp/internal/parser"
+	"github.com/pterm/pterm"
+)
+
+// describeConcurrency is how many descriptions are written at once
+const describeConcurrency = 4
+
+var (
+	llmDescribe bool
+	llmConfig   describer.Config
+)
+
+func init() {
+	flags := rootCmd.Flags()
+	flags.BoolVar(&llmDescribe, "llm-describe", false, "Rewrite terse or missing descriptions with an LLM, proposed for review in the TUI")
+	flags.StringVar(&llmConfig.Provider, "llm-provider", describer.ProviderOpenAI, "API flavor of the LLM endpoint (openai|anthropic)")
+	flags.StringVar(&llmConfig.URL, "llm-url", "", "Base URL of the LLM endpoint, defaults to the provider's API")
+	flags.StringVar(&llmConfig.Model, "llm-model", "", "Model writing the descriptions")
+}
+
+// generateDescriptions writes descriptions with the configured LLM for the
+// routes whose description is terse or missing. The API key is read from
+// AUTO_MCP_LLM_API_KEY. Routes failing are reported and left as they are.
+func generateDescriptions(ctx context.Context, routeTools []*parser.RouteTool, adjuster *parser.Adjuster) (map[*parser.RouteTool]string, error) {
+	llmConfig.APIKey = os.Getenv("AUTO_MCP_LLM_API_KEY")
+	d, err := describer.New(llmConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	// Descriptions already adjusted are kept
+	var terse []*parser.RouteTool
+	for _, route := range routeTools {
+		if adjuster.GetDescription(route.RouteConfig.Path, route.RouteConfig.Method, "") == "" && describer.Terse(route.RouteConfig.Description) {
+			terse = append(terse, route)
+		}
+	}
+	if len(terse) == 0 {
+		return nil, nil
+	}
+
+	bar, err := pterm.DefaultProgressbar.WithTotal(len(terse)).WithTitle("Writing descriptions").Start()
+	if err != nil {
+		return nil, err
+	}
+	var mu sync.Mutex
+	descriptions, errs := d.DescribeAll(ctx, terse, describeConcurrency, func() {
+		mu.Lock()
+		bar.Increment()
+		mu.Unlock()
+	})
+	_, _ = bar.Stop()
+	for _, err := range errs {
+		pterm.Warning.Println(err)
+	}
+	if len(descriptions) == 0 {
+		return nil, fmt.Errorf("no description could be written")
+	}
+	pterm.Info.Printfln("Wrote %d descriptions, flagged [generated] for review.", len(descriptions))
+	return descriptions, nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/brizzai/auto-mcp/internal/models"
+	"github.com/brizzai/auto-mcp/internal/parser"
+	"github.com/brizzai/auto-mcp/internal/tui"
+	"github.com/pterm/pterm"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	outFile string
+	filter  parser.RouteFilter
+)
+
+func init() {
+	flags := rootCmd.Flags()
+	flags.StringVar(&outFile, "out", "", "Write the adjustments file here without starting the TUI (\"-\" for stdout, JSON for a .json file)")
+	flags.StringSliceVar(&filter.IncludeTags, "include-tags", nil, "Keep only operations with one of these tags")
+	flags.StringSliceVar(&filter.ExcludeTags, "exclude-tags", nil, "Drop operations with one of these tags")
+	flags.StringSliceVar(&filter.IncludeMethods, "include-methods", nil, "Keep only these HTTP methods")
+	flags.StringSliceVar(&filter.ExcludeMethods, "exclude-methods", nil, "Drop these HTTP methods")
+	flags.StringSliceVar(&filter.IncludePaths, "include-paths", nil, "Keep only paths matching these patterns, e.g. /pet/* or /store/**")
+	flags.StringSliceVar(&filter.ExcludePaths, "exclude-paths", nil, "Drop paths matching these patterns")
+}
+
+// filterFlags are only used without the TUI
+var filterFlags = []string{"include-tags", "exclude-tags", "include-methods", "exclude-methods", "include-paths", "exclude-paths"}
+
+// runHeadless selects routes with the filter flags and writes the adjustments
+// file. Other sections of an existing --adjustments-file are kept, so CI can
+// regenerate the route selection whenever the spec changes.
+func runHeadless() error {
+	swaggerParser := parser.NewSwaggerParser(parser.NewAdjuster())
+	if err := swaggerParser.Init(swaggerFile, ""); err != nil {
+		return fmt.Errorf("error parsing swagger file: %w", err)
+	}
+	routeTools := swaggerParser.GetRouteTools()
+	selected := parser.SelectRoutes(routeTools, filter)
+	if len(selected) == 0 {
+		// An empty routes section would select every operation
+		return fmt.Errorf("the filters match none of the %d operations", len(routeTools))
+	}
+
+	var adjustments models.MCPAdjustments
+	if adjustmentsFile != "" {
+		data, err := os.ReadFile(adjustmentsFile)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("error reading adjustments file: %w", err)
+		}
+		if err := yaml.Unmarshal(data, &adjustments); err != nil {
+			return fmt.Errorf("error parsing adjustments file: %w", err)
+		}
+	}
+	adjustments.Routes = parser.RouteSelections(selected)
+
+	yamlData, err := tui.EncodeAdjustments(adjustments, outFile)
+	if err != nil {
+		return err
+	}
+	if outFile == "-" {
+		_, err = os.Stdout.Write(yamlData)
+		return err
+	}
+	if err := os.WriteFile(outFile, yamlData, 0o644); err != nil {
+		return err
+	}
+	pterm.Info.Printfln("Kept %s routes out of %s, wrote %s.",
+		pterm.LightGreen(len(selected)), pterm.White(len(routeTools)), outFile)
+	return nil
+}
@@ -1,8 +1,10 @@
 package main
 
 import (
+	"fmt"
 	"os"
 	"runtime/debug"
+	"strings"
 
 	"github.com/pterm/pterm"
 
@@ -10,7 +12,9 @@ import (
 	"github.com/brizzai/auto-mcp/internal/parser"
 	"github.com/brizzai/auto-mcp/internal/tui"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 func main() {
@@ -20,6 +24,8 @@ func main() {
 var (
 	swaggerFile     string
 	adjustmentsFile string
+	theme           string
+	suggest         int
 )
 
 // rootCmd represents the base command
@@ -27,7 +33,11 @@ var rootCmd = &cobra.Command{
 	Use:   "mcp-config-builder",
 	Short: "A tool to build MCP config from Swagger",
 	Long: `MCP Config Builder is a CLI tool that helps you build MCP config from Swagger/OpenAPI definitions.
-It allows you to filter out routes and adjust descriptions to optimize your API.`,
+It allows you to filter out routes and adjust descriptions to optimize your API.
+With --out it writes the adjustments file without the TUI, selecting routes with
+the --include-* and --exclude-* flags, e.g. to regenerate it in CI:
+
+  mcp-config-builder --swagger-file swagger.json --include-tags pet --exclude-methods DELETE --out adjustment.yaml`,
 	Run: runTUI,
 }
 
@@ -49,9 +59,26 @@ func Execute() {
 }
 
 func init() {
-	rootCmd.PersistentFlags().StringVar(&swaggerFile, "swagger-file", "", "Path to the Swagger/OpenAPI file")
-	rootCmd.PersistentFlags().StringVar(&adjustmentsFile, "adjustments-file", "", "Path to the MCP adjustments file")
+	// config.Load reads these flags too, for the routes tried in the TUI
+	pflag.StringVar(&swaggerFile, "swagger-file", "", "Path to the Swagger/OpenAPI file")
+	pflag.StringVar(&adjustmentsFile, "adjustments-file", "", "Path to the MCP adjustments file")
+	pflag.String("base-url", "", "Base URL of the upstream API routes are tried against, overriding config.yaml")
+	rootCmd.PersistentFlags().AddFlagSet(pflag.CommandLine)
 	rootCmd.PersistentFlags().BoolP("version", "v", false, "Show version information")
+	rootCmd.Flags().IntVar(&suggest, "suggest", 0, "Propose edits trimming the routes to this many tools, to review in the TUI")
+	rootCmd.Flags().StringVar(&theme, "theme", defaultTheme(), fmt.Sprintf("Colors of the TUI (%s), AUTO_MCP_THEME sets the default", strings.Join(tui.ThemeNames(), "|")))
+}
+
+// defaultTheme returns the theme of AUTO_MCP_THEME, no-color when NO_COLOR
+// is set, or the default theme
+func defaultTheme() string {
+	if theme := os.Getenv("AUTO_MCP_THEME"); theme != "" {
+		return theme
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return "no-color"
+	}
+	return tui.DefaultTheme
 }
 
 // runTUI is the main function that runs the TUI
@@ -63,32 +90,99 @@ func runTUI(cmd *cobra.Command, args []string) {
 			os.Exit(2)
 		}
 	}()
-	// Create a new parser
-	adjuster := parser.NewAdjuster()
-	swaggerParser := parser.NewSwaggerParser(adjuster)
-
-	if swaggerFile == "" {
-		pterm.Error.Println("Swagger file is required, you must supply it with --swagger-file")
+	// With stdout piped, e.g. to export to it with "-", the TUI and messages
+	// go to stderr
+	piped := !isTerminal(os.Stdout)
+	if piped {
+		lipgloss.SetDefaultRenderer(lipgloss.NewRenderer(os.Stderr))
+		pterm.SetDefaultOutput(os.Stderr)
+	}
+	if err := tui.SetTheme(theme); err != nil {
+		pterm.Error.Println(err)
 		os.Exit(1)
 	}
+	if theme == "no-color" {
+		pterm.DisableColor()
+	}
 
-	// Parse the swagger file
-	err := swaggerParser.Init(swaggerFile, "") // no adjustments file for builder in edit mode
-	if err != nil {
-		pterm.Error.Printf("Error parsing swagger file: %v\n", err)
+	if outFile != "" {
+		if swaggerFile == "" {
+			pterm.Error.Println("Swagger file is required, you must supply it with --swagger-file")
+			os.Exit(1)
+		}
+		for _, name := range []string{"suggest", "llm-describe"} {
+			if cmd.Flags().Changed(name) {
+				pterm.Error.Printfln("--%s proposes edits to review in the TUI, it can't be used with --out", name)
+				os.Exit(1)
+			}
+		}
+		if err := runHeadless(); err != nil {
+			pterm.Error.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+	if suggest < 0 {
+		pterm.Error.Println("--suggest must be a positive tool count")
 		os.Exit(1)
 	}
+	for _, name := range filterFlags {
+		if cmd.Flags().Changed(name) {
+			pterm.Error.Printfln("--%s requires --out", name)
+			os.Exit(1)
+		}
+	}
+
+	// Create a new parser
+	adjuster := parser.NewAdjuster()
+	swaggerParser := parser.NewSwaggerParser(adjuster)
+
+	// Parse the swagger file, or prompt for the spec in the TUI without one
+	if swaggerFile != "" {
+		err := swaggerParser.Init(swaggerFile, "") // no adjustments file for builder in edit mode
+		if err != nil {
+			pterm.Error.Printf("Error parsing swagger file: %v\n", err)
+			os.Exit(1)
+		}
+	}
 
 	// Get the route tools
 	routeTools := swaggerParser.GetRouteTools()
-	err = adjuster.Load(adjustmentsFile)
+	err := adjuster.Load(adjustmentsFile)
 	if err != nil {
 		pterm.Error.Printf("Error loading adjustments file: %v\n", err)
 		os.Exit(1)
 	}
 
+	if recovery := tui.RecoveryFile(adjustmentsFile); fileExists(recovery) {
+		pterm.Warning.Printfln("%s holds unsaved edits of an earlier session, pass it as --adjustments-file to continue with them", recovery)
+	}
+
 	// Create and run the TUI with the new AppModel
-	p := tea.NewProgram(tui.NewAppModel(routeTools, adjuster), tea.WithAltScreen())
+	app := tui.NewAppModel(routeTools, adjuster).WithCall(newCallFunc(cmd.Context())).WithAdjustmentsFile(adjustmentsFile).WithSwaggerFile(swaggerFile)
+	if suggest > 0 {
+		app = app.WithSuggestions(suggest)
+	}
+	if llmDescribe {
+		if swaggerFile == "" {
+			pterm.Error.Println("--llm-describe requires --swagger-file")
+			os.Exit(1)
+		}
+		descriptions, err := generateDescriptions(cmd.Context(), routeTools, adjuster)
+		if err != nil {
+			pterm.Error.Printf("Error writing descriptions: %v\n", err)
+			os.Exit(1)
+		}
+		app = app.WithGeneratedDescriptions(descriptions)
+	}
+	if swaggerFile == "" {
+		app = app.WithSpecPrompt()
+	}
+	options := []tea.ProgramOption{tea.WithAltScreen()}
+	if piped {
+		options = append(options, tea.WithOutput(os.Stderr))
+	}
+	p := tea.NewProgram(app, options...)
 
 	// Run the program
 	m, err := p.Run()
@@ -102,6 +196,12 @@ func runTUI(cmd *cobra.Command, args []string) {
 
 	// Only display summary if the TUI completed successfully (user reached export page)
 	if finalModel.IsFinished() {
+		if output := finalModel.Output(); output != nil {
+			if _, err := os.Stdout.Write(output); err != nil {
+				pterm.Error.Printf("Error writing adjustments: %v\n", err)
+				os.Exit(1)
+			}
+		}
 		validRoutes := finalModel.GetRoutesUpdates()
 		filteredRoutesCount := 0
 		for _, route := range validRoutes {
@@ -111,6 +211,17 @@ func runTUI(cmd *cobra.Command, args []string) {
 		}
 		pterm.Info.Printfln("Processing complete. Kept %s routes out of %s.",
 			pterm.LightGreen(filteredRoutesCount),
-			pterm.White(len(routeTools)))
+			pterm.White(len(validRoutes)))
 	}
 }
+
+// isTerminal reports whether f is a terminal rather than a pipe or file
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,65 @@
+// Command scrub is an example auto-mcp plugin. It removes configured fields
+// from JSON responses before the model sees them and hides DELETE tools.
+//
+// Build it with the same Go version and auto-mcp sources as the server:
+//
+//	go build -buildmode=plugin -o scrub.so ./examples/plugins/scrub
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/brizzai/auto-mcp/pkg/hooks"
+)
+
+type scrubber struct {
+	fields []string
+}
+
+// New is looked up by auto-mcp. config["fields"] lists the response fields
+// to remove, separated by commas.
+func New(config map[string]string) (any, error) {
+	var fields []string
+	for _, field := range strings.Split(config["fields"], ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			fields = append(fields, field)
+		}
+	}
+	return &scrubber{fields: fields}, nil
+}
+
+// PostResponse drops the fields from top-level objects and arrays of objects
+func (s *scrubber) PostResponse(_ context.Context, resp *hooks.Response) error {
+	var body any
+	if err := json.Unmarshal(resp.Body, &body); err != nil {
+		return nil // not JSON, leave it alone
+	}
+	items, ok := body.([]any)
+	if !ok {
+		items = []any{body}
+	}
+	for _, item := range items {
+		if object, ok := item.(map[string]any); ok {
+			for _, field := range s.fields {
+				delete(object, field)
+			}
+		}
+	}
+	scrubbed, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	resp.Body = scrubbed
+	return nil
+}
+
+// IncludeTool hides tools that delete data
+func (s *scrubber) IncludeTool(tool hooks.Tool) bool {
+	return tool.Method != http.MethodDelete
+}
+
+// main is unused; go build requires it outside -buildmode=plugin
+func main() {}
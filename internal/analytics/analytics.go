@@ -0,0 +1,264 @@
+// Package analytics counts the calls, errors and latencies of every tool, so
+// tools agents never use and tools that keep failing can be found. Statistics
+// live in memory and are optionally persisted to a file across restarts.
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/brizzai/auto-mcp/internal/config"
+	"github.com/brizzai/auto-mcp/internal/logger"
+	"go.uber.org/zap"
+)
+
+// maxSamples bounds the latencies kept per tool; percentiles are computed
+// over the most recent calls
+const maxSamples = 1024
+
+// Thresholds for listing a tool as failing in the periodic summary
+const (
+	failingMinCalls  = 5
+	failingErrorRate = 0.5
+)
+
+// summaryTopTools is the number of busiest tools named in the summary
+const summaryTopTools = 5
+
+// ToolStats describes the usage of one tool
+type ToolStats struct {
+	Tool      string     `json:"tool"`
+	Calls     int64      `json:"calls"`
+	Errors    int64      `json:"errors"`
+	ErrorRate float64    `json:"error_rate"`
+	P50Ms     float64    `json:"p50_ms"`
+	P95Ms     float64    `json:"p95_ms"`
+	LastCall  *time.Time `json:"last_call,omitempty"`
+}
+
+// Report is the usage of every tool since Since, busiest tools first
+type Report struct {
+	Since time.Time   `json:"since"`
+	Tools []ToolStats `json:"tools"`
+}
+
+// usage holds the counters of a tool, also its persisted form
+type usage struct {
+	Calls    int64     `json:"calls"`
+	Errors   int64     `json:"errors"`
+	LastCall time.Time `json:"last_call"`
+	// Latencies is a ring of the most recent durations in milliseconds,
+	// Next the position the next sample is written to
+	Latencies []float64 `json:"latencies_ms"`
+	Next      int       `json:"next"`
+}
+
+// state is the persisted form of a Recorder
+type state struct {
+	Since time.Time         `json:"since"`
+	Tools map[string]*usage `json:"tools"`
+}
+
+// Recorder collects tool usage statistics. It is safe for concurrent use.
+type Recorder struct {
+	cfg config.AnalyticsConfig
+	now func() time.Time
+
+	mu    sync.Mutex
+	state state
+}
+
+// New creates the recorder described by cfg, or returns nil when analytics
+// are disabled. Statistics saved to cfg.File by a previous run are loaded.
+func New(cfg config.AnalyticsConfig) (*Recorder, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	r := &Recorder{
+		cfg:   cfg,
+		now:   time.Now,
+		state: state{Since: time.Now(), Tools: make(map[string]*usage)},
+	}
+	if cfg.File == "" {
+		return r, nil
+	}
+
+	data, err := os.ReadFile(cfg.File)
+	if errors.Is(err, os.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read analytics file: %w", err)
+	}
+	var saved state
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("invalid analytics file %s: %w", cfg.File, err)
+	}
+	if saved.Tools != nil {
+		r.state = saved
+	}
+	return r, nil
+}
+
+// Record adds a call of tool that took d and failed or not
+func (r *Recorder) Record(tool string, d time.Duration, failed bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u, ok := r.state.Tools[tool]
+	if !ok {
+		u = &usage{}
+		r.state.Tools[tool] = u
+	}
+	u.Calls++
+	if failed {
+		u.Errors++
+	}
+	u.LastCall = r.now()
+
+	ms := float64(d) / float64(time.Millisecond)
+	switch {
+	case len(u.Latencies) < maxSamples:
+		u.Latencies = append(u.Latencies, ms)
+		u.Next = len(u.Latencies) % maxSamples
+	case u.Next >= 0 && u.Next < maxSamples:
+		u.Latencies[u.Next] = ms
+		u.Next = (u.Next + 1) % maxSamples
+	default:
+		// A hand-edited file may hold any position
+		u.Latencies[0], u.Next = ms, 1
+	}
+}
+
+// Report returns the statistics of every tool called so far and of the
+// known tools, which are listed with zero calls when they were never called
+func (r *Recorder) Report(known []string) Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	report := Report{Since: r.state.Since, Tools: make([]ToolStats, 0, len(r.state.Tools)+len(known))}
+	for name, u := range r.state.Tools {
+		report.Tools = append(report.Tools, u.stats(name))
+	}
+	for _, name := range known {
+		if _, ok := r.state.Tools[name]; !ok {
+			report.Tools = append(report.Tools, ToolStats{Tool: name})
+		}
+	}
+	sort.Slice(report.Tools, func(i, j int) bool {
+		a, b := report.Tools[i], report.Tools[j]
+		if a.Calls != b.Calls {
+			return a.Calls > b.Calls
+		}
+		return a.Tool < b.Tool
+	})
+	return report
+}
+
+// stats computes the statistics of u
+func (u *usage) stats(name string) ToolStats {
+	s := ToolStats{Tool: name, Calls: u.Calls, Errors: u.Errors}
+	if u.Calls > 0 {
+		s.ErrorRate = float64(u.Errors) / float64(u.Calls)
+		last := u.LastCall
+		s.LastCall = &last
+	}
+	if len(u.Latencies) > 0 {
+		sorted := append([]float64(nil), u.Latencies...)
+		sort.Float64s(sorted)
+		s.P50Ms = percentile(sorted, 0.5)
+		s.P95Ms = percentile(sorted, 0.95)
+	}
+	return s
+}
+
+// percentile returns the nearest-rank percentile p of sorted values
+func percentile(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return math.Round(sorted[rank]*100) / 100
+}
+
+// Save writes the statistics to the configured file, if any. The file is
+// replaced atomically so a crash never leaves it half written.
+func (r *Recorder) Save() error {
+	if r.cfg.File == "" {
+		return nil
+	}
+	r.mu.Lock()
+	data, err := json.Marshal(r.state)
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(r.cfg.File), filepath.Base(r.cfg.File)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), r.cfg.File)
+}
+
+// Run logs a usage summary and saves the statistics on every summary
+// interval until ctx is done
+func (r *Recorder) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.Interval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.logSummary()
+			if err := r.Save(); err != nil {
+				logger.Warn("Failed to save tool analytics", zap.Error(err))
+			}
+		}
+	}
+}
+
+// logSummary logs the total calls, the busiest tools and the failing ones
+func (r *Recorder) logSummary() {
+	report := r.Report(nil)
+	var calls, errs int64
+	busiest := make([]string, 0, summaryTopTools)
+	var failing []string
+	for _, s := range report.Tools {
+		calls += s.Calls
+		errs += s.Errors
+		if len(busiest) < summaryTopTools {
+			busiest = append(busiest, fmt.Sprintf("%s=%d", s.Tool, s.Calls))
+		}
+		if s.Calls >= failingMinCalls && s.ErrorRate >= failingErrorRate {
+			failing = append(failing, fmt.Sprintf("%s=%.0f%%", s.Tool, 100*s.ErrorRate))
+		}
+	}
+	logger.Info("Tool usage summary",
+		zap.Time("since", report.Since),
+		zap.Int64("calls", calls),
+		zap.Int64("errors", errs),
+		zap.Int("tools_called", len(report.Tools)),
+		zap.Strings("busiest", busiest),
+		zap.Strings("failing", failing),
+	)
+}
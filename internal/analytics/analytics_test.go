@@ -0,0 +1,84 @@
+package analytics
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/brizzai/auto-mcp/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_Disabled(t *testing.T) {
+	r, err := New(config.AnalyticsConfig{})
+	require.NoError(t, err)
+	assert.Nil(t, r)
+}
+
+func TestRecorder_Report(t *testing.T) {
+	r, err := New(config.AnalyticsConfig{Enabled: true})
+	require.NoError(t, err)
+
+	for i := 1; i <= 100; i++ {
+		r.Record("search", time.Duration(i)*time.Millisecond, i%10 == 0)
+	}
+	r.Record("delete", 5*time.Millisecond, true)
+
+	report := r.Report([]string{"search", "delete", "export"})
+	require.Len(t, report.Tools, 3)
+
+	search := report.Tools[0]
+	assert.Equal(t, "search", search.Tool)
+	assert.Equal(t, int64(100), search.Calls)
+	assert.Equal(t, int64(10), search.Errors)
+	assert.InDelta(t, 0.1, search.ErrorRate, 1e-9)
+	assert.Equal(t, 50.0, search.P50Ms)
+	assert.Equal(t, 95.0, search.P95Ms)
+	assert.NotNil(t, search.LastCall)
+
+	assert.Equal(t, "delete", report.Tools[1].Tool)
+	assert.Equal(t, 1.0, report.Tools[1].ErrorRate)
+
+	// Known tools that were never called are listed with zero calls
+	assert.Equal(t, ToolStats{Tool: "export"}, report.Tools[2])
+}
+
+func TestRecorder_LatencyWindow(t *testing.T) {
+	r, err := New(config.AnalyticsConfig{Enabled: true})
+	require.NoError(t, err)
+
+	// Percentiles only cover the most recent calls
+	for i := 0; i < maxSamples; i++ {
+		r.Record("search", time.Second, false)
+	}
+	for i := 0; i < maxSamples; i++ {
+		r.Record("search", time.Millisecond, false)
+	}
+	stats := r.Report(nil).Tools[0]
+	assert.Equal(t, int64(2*maxSamples), stats.Calls)
+	assert.Equal(t, 1.0, stats.P95Ms)
+}
+
+func TestRecorder_Persistence(t *testing.T) {
+	cfg := config.AnalyticsConfig{Enabled: true, File: filepath.Join(t.TempDir(), "analytics.json")}
+	r, err := New(cfg)
+	require.NoError(t, err)
+	r.Record("search", 20*time.Millisecond, false)
+	r.Record("search", 40*time.Millisecond, true)
+	require.NoError(t, r.Save())
+
+	restored, err := New(cfg)
+	require.NoError(t, err)
+	want, err := json.Marshal(r.Report(nil))
+	require.NoError(t, err)
+	got, err := json.Marshal(restored.Report(nil))
+	require.NoError(t, err)
+	assert.JSONEq(t, string(want), string(got))
+
+	restored.Record("search", 60*time.Millisecond, false)
+	stats := restored.Report(nil).Tools[0]
+	assert.Equal(t, int64(3), stats.Calls)
+	assert.Equal(t, 40.0, stats.P50Ms)
+}
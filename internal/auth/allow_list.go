@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"context"
+	"strings"
+
+	"github.com/brizzai/auto-mcp/internal/auth/models"
+	"github.com/brizzai/auto-mcp/internal/auth/providers"
+	"github.com/brizzai/auto-mcp/internal/config"
+	"github.com/brizzai/auto-mcp/internal/logger"
+	"go.uber.org/zap"
+)
+
+// allowListProvider rejects valid tokens of users whose email is not in
+// oauth.allowed_emails or oauth.allowed_domains, for providers anyone can
+// sign in with
+type allowListProvider struct {
+	providers.OAuthProvider
+	emails  map[string]bool
+	domains map[string]bool
+}
+
+// withAllowList restricts provider to the allowed users; without an
+// allow-list every authenticated user is accepted
+func withAllowList(provider providers.OAuthProvider, cfg *config.OAuthConfig) providers.OAuthProvider {
+	if len(cfg.AllowedEmails) == 0 && len(cfg.AllowedDomains) == 0 {
+		return provider
+	}
+	p := &allowListProvider{
+		OAuthProvider: provider,
+		emails:        make(map[string]bool, len(cfg.AllowedEmails)),
+		domains:       make(map[string]bool, len(cfg.AllowedDomains)),
+	}
+	for _, email := range cfg.AllowedEmails {
+		p.emails[strings.ToLower(strings.TrimSpace(email))] = true
+	}
+	for _, domain := range cfg.AllowedDomains {
+		p.domains[strings.ToLower(strings.TrimPrefix(strings.TrimSpace(domain), "@"))] = true
+	}
+	return p
+}
+
+// ValidateAccessToken validates token with the provider, then checks its user
+func (p *allowListProvider) ValidateAccessToken(ctx context.Context, token string) (*models.UserInfo, error) {
+	userInfo, err := p.OAuthProvider.ValidateAccessToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if !p.allowed(userInfo) {
+		logger.Warn("Rejected user outside the OAuth allow-list",
+			zap.String("user_id", userInfo.ID), zap.String("email", userInfo.Email))
+		return nil, providers.ErrUserNotAllowed
+	}
+	return userInfo, nil
+}
+
+// allowed matches the user's email; emails the provider reports as
+// unverified are not trusted
+func (p *allowListProvider) allowed(userInfo *models.UserInfo) bool {
+	if userInfo.Email == "" || userInfo.Metadata["email_verified"] == false {
+		return false
+	}
+	email := strings.ToLower(userInfo.Email)
+	if p.emails[email] {
+		return true
+	}
+	at := strings.LastIndex(email, "@")
+	return at >= 0 && p.domains[email[at+1:]]
+}
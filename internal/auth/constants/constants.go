@@ -24,7 +24,7 @@ var DefaultScopes = []string{"openid", "profile", "email"}
 var (
 	SupportedResponseTypes = []string{"code"}
 	SupportedResponseModes = []string{"query"}
-	SupportedGrantTypes    = []string{"authorization_code"}
+	SupportedGrantTypes    = []string{"authorization_code", "refresh_token"}
 	SupportedAuthMethods   = []string{"none"}
 )
 
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// Lifetimes of the one-time values of the authorization code flow
+const (
+	// authorizationTTL bounds how long the user may take to sign in
+	authorizationTTL = 10 * time.Minute
+	// codeTTL bounds how long an issued code may wait to be redeemed
+	codeTTL = time.Minute
+)
+
+// PKCE code verifiers are 43 to 128 characters long (RFC 7636 section 4.1)
+const (
+	minVerifierLength = 43
+	maxVerifierLength = 128
+)
+
+// randomHex returns n random bytes, hex encoded
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// verifyPKCE checks verifier against an S256 code challenge
+func verifyPKCE(challenge, verifier string) bool {
+	if len(verifier) < minVerifierLength || len(verifier) > maxVerifierLength {
+		return false
+	}
+	computed := oauth2.S256ChallengeFromVerifier(verifier)
+	return subtle.ConstantTimeCompare([]byte(computed), []byte(challenge)) == 1
+}
+
+// redirectAllowListed reports whether uri matches one of the configured
+// patterns. An empty allow-list accepts every URI.
+func redirectAllowListed(patterns []string, uri string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(uri, prefix) {
+				return true
+			}
+			continue
+		}
+		if redirectURIAllowed([]string{pattern}, uri) {
+			return true
+		}
+	}
+	return false
+}
+
+// redirectWithParams redirects to the client's redirect URI, adding params
+// and the client's state to its query
+func redirectWithParams(w http.ResponseWriter, r *http.Request, redirectURI, state string, params url.Values) {
+	u, err := url.Parse(redirectURI)
+	if err != nil {
+		http.Error(w, "Invalid redirect URI", http.StatusInternalServerError)
+		return
+	}
+	query := u.Query()
+	for key, values := range params {
+		query[key] = values
+	}
+	if state != "" {
+		query.Set("state", state)
+	}
+	u.RawQuery = query.Encode()
+	http.Redirect(w, r, u.String(), http.StatusFound)
+}
+
+// redirectError reports an authorization error to the client (RFC 6749 section 4.1.2.1)
+func redirectError(w http.ResponseWriter, r *http.Request, redirectURI, state, code, description string) {
+	redirectWithParams(w, r, redirectURI, state, url.Values{"error": {code}, "error_description": {description}})
+}
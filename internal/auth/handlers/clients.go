@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/brizzai/auto-mcp/internal/auth/constants"
+	"github.com/brizzai/auto-mcp/internal/auth/store"
+	"github.com/brizzai/auto-mcp/internal/logger"
+	"github.com/brizzai/auto-mcp/internal/utils"
+	"go.uber.org/zap"
+)
+
+var (
+	errInvalidRedirectURI    = errors.New("invalid_redirect_uri")
+	errInvalidClientMetadata = errors.New("invalid_client_metadata")
+	errInvalidClient         = errors.New("invalid_client")
+)
+
+// forbiddenRedirectSchemes can run code or read local files when navigated to
+var forbiddenRedirectSchemes = []string{"javascript", "data", "file", "vbscript"}
+
+// registrationRequest is the client metadata accepted by HandleRegister (RFC 7591)
+type registrationRequest struct {
+	ClientName              string   `json:"client_name"`
+	RedirectURIs            []string `json:"redirect_uris"`
+	GrantTypes              []string `json:"grant_types"`
+	ResponseTypes           []string `json:"response_types"`
+	TokenEndpointAuthMethod string   `json:"token_endpoint_auth_method"`
+}
+
+// validate checks the metadata and fills in defaults
+func (r *registrationRequest) validate() error {
+	if r.ClientName == "" {
+		return fmt.Errorf("%w: client_name is required", errInvalidClientMetadata)
+	}
+	if len(r.RedirectURIs) == 0 {
+		return fmt.Errorf("%w: at least one redirect_uri is required", errInvalidRedirectURI)
+	}
+	for _, uri := range r.RedirectURIs {
+		if err := validateRedirectURI(uri); err != nil {
+			return err
+		}
+	}
+
+	if len(r.GrantTypes) == 0 {
+		r.GrantTypes = []string{"authorization_code"}
+	}
+	for _, grantType := range r.GrantTypes {
+		if !slices.Contains(constants.SupportedGrantTypes, grantType) {
+			return fmt.Errorf("%w: unsupported grant_type %q", errInvalidClientMetadata, grantType)
+		}
+	}
+	if len(r.ResponseTypes) == 0 {
+		r.ResponseTypes = []string{"code"}
+	}
+	for _, responseType := range r.ResponseTypes {
+		if !slices.Contains(constants.SupportedResponseTypes, responseType) {
+			return fmt.Errorf("%w: unsupported response_type %q", errInvalidClientMetadata, responseType)
+		}
+	}
+	// Only public clients are supported, no secrets are issued
+	if r.TokenEndpointAuthMethod != "" && !slices.Contains(constants.SupportedAuthMethods, r.TokenEndpointAuthMethod) {
+		return fmt.Errorf("%w: unsupported token_endpoint_auth_method %q", errInvalidClientMetadata, r.TokenEndpointAuthMethod)
+	}
+	r.TokenEndpointAuthMethod = constants.SupportedAuthMethods[0]
+	return nil
+}
+
+// validateRedirectURI accepts absolute URIs without fragments: https, http to
+// a loopback address, or a native app's private-use scheme (RFC 8252)
+func validateRedirectURI(uri string) error {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme == "" {
+		return fmt.Errorf("%w: %q is not an absolute URI", errInvalidRedirectURI, uri)
+	}
+	if u.Fragment != "" || strings.Contains(uri, "#") {
+		return fmt.Errorf("%w: %q must not contain a fragment", errInvalidRedirectURI, uri)
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	switch {
+	case scheme == "https":
+		if u.Host == "" {
+			return fmt.Errorf("%w: %q has no host", errInvalidRedirectURI, uri)
+		}
+	case scheme == "http":
+		if !isLoopback(u.Hostname()) {
+			return fmt.Errorf("%w: %q must use https unless it points to a loopback address", errInvalidRedirectURI, uri)
+		}
+	case slices.Contains(forbiddenRedirectSchemes, scheme):
+		return fmt.Errorf("%w: scheme %q is not allowed", errInvalidRedirectURI, u.Scheme)
+	}
+	return nil
+}
+
+// redirectURIAllowed reports whether uri matches a registered redirect URI
+// exactly. Loopback redirect URIs may use any port, as native apps pick one
+// at runtime (RFC 8252 section 7.3).
+func redirectURIAllowed(registered []string, uri string) bool {
+	if slices.Contains(registered, uri) {
+		return true
+	}
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "http" || !isLoopback(u.Hostname()) {
+		return false
+	}
+	return slices.ContainsFunc(registered, func(candidate string) bool {
+		c, err := url.Parse(candidate)
+		return err == nil && c.Scheme == u.Scheme && c.Hostname() == u.Hostname() &&
+			c.Path == u.Path && c.RawQuery == u.RawQuery
+	})
+}
+
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// newClientID returns an unguessable client ID
+func newClientID() (string, error) {
+	id, err := randomHex(16)
+	if err != nil {
+		return "", err
+	}
+	return "client-" + id, nil
+}
+
+// resolveRedirect loads the registered client and checks redirectURI against
+// its registration and the configured allow-list. An empty redirectURI resolves to the client's only
+// registered URI.
+func (h *Handler) resolveRedirect(ctx context.Context, clientID, redirectURI string) (string, error) {
+	client, err := h.loadClient(ctx, clientID)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case redirectURI == "" && len(client.RedirectURIs) == 1:
+		redirectURI = client.RedirectURIs[0]
+	case redirectURI == "":
+		return "", fmt.Errorf("%w: redirect_uri is required", errInvalidRedirectURI)
+	case !redirectURIAllowed(client.RedirectURIs, redirectURI):
+		return "", fmt.Errorf("%w: redirect_uri is not registered for this client", errInvalidRedirectURI)
+	}
+	// The allow-list may have been tightened since the client registered
+	if !redirectAllowListed(h.cfg.AllowedRedirectURIs, redirectURI) {
+		return "", fmt.Errorf("%w: redirect_uri is not in oauth.allowed_redirect_uris", errInvalidRedirectURI)
+	}
+	return redirectURI, nil
+}
+
+// loadClient returns the registered client
+func (h *Handler) loadClient(ctx context.Context, clientID string) (*store.Client, error) {
+	if clientID == "" {
+		return nil, fmt.Errorf("%w: client_id is required", errInvalidClient)
+	}
+	client, err := store.GetClient(ctx, h.store, clientID)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, fmt.Errorf("%w: unknown client_id, register the client first", errInvalidClient)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load client: %w", err)
+	}
+	return client, nil
+}
+
+// errorCode returns the OAuth error code for err
+func errorCode(err error, fallback string) string {
+	for _, known := range []error{errInvalidRedirectURI, errInvalidClientMetadata, errInvalidClient} {
+		if errors.Is(err, known) {
+			return known.Error()
+		}
+	}
+	return fallback
+}
+
+// errorDescription strips the error code prefix from err's message
+func errorDescription(err error) string {
+	return strings.TrimPrefix(err.Error(), errorCode(err, "")+": ")
+}
+
+// writeClientError reports a failed client lookup. Unknown clients get
+// invalidClientStatus, other client errors 400 and store failures 500.
+func writeClientError(w http.ResponseWriter, err error, invalidClientStatus int) {
+	code := errorCode(err, "")
+	switch code {
+	case "":
+		logger.Error("Failed to resolve OAuth client", zap.Error(err))
+		utils.WriteError(w, "server_error", "Failed to load client", http.StatusInternalServerError)
+	case errInvalidClient.Error():
+		utils.WriteError(w, code, errorDescription(err), invalidClientStatus)
+	default:
+		utils.WriteError(w, code, errorDescription(err), http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,321 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/brizzai/auto-mcp/internal/auth/models"
+	"github.com/brizzai/auto-mcp/internal/auth/store"
+	"github.com/brizzai/auto-mcp/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+// fakeProvider records the parameters of the authorization it is asked for
+type fakeProvider struct {
+	authState        string
+	authChallenge    string
+	authRedirect     string
+	exchangeCode     string
+	exchangeVerifier string
+	exchangeRedirect string
+}
+
+func (p *fakeProvider) GetAuthURL(state, codeChallenge, codeChallengeMethod, redirectURI string) string {
+	p.authState = state
+	p.authChallenge = codeChallenge
+	p.authRedirect = redirectURI
+	return "https://idp.example.com/authorize"
+}
+
+func (p *fakeProvider) ExchangeCode(ctx context.Context, code, codeVerifier, redirectURI string) (*oauth2.Token, error) {
+	p.exchangeCode = code
+	p.exchangeVerifier = codeVerifier
+	p.exchangeRedirect = redirectURI
+	return &oauth2.Token{AccessToken: "token", RefreshToken: "valid-refresh"}, nil
+}
+
+func (p *fakeProvider) ValidateToken(ctx context.Context, token *oauth2.Token) (*models.UserInfo, error) {
+	return &models.UserInfo{}, nil
+}
+
+func (p *fakeProvider) RefreshToken(ctx context.Context, refreshToken string) (*oauth2.Token, error) {
+	if refreshToken != "valid-refresh" {
+		return nil, errors.New("invalid refresh token")
+	}
+	return &oauth2.Token{AccessToken: "refreshed", RefreshToken: refreshToken}, nil
+}
+
+func (p *fakeProvider) ValidateAccessToken(ctx context.Context, token string) (*models.UserInfo, error) {
+	return &models.UserInfo{}, nil
+}
+
+func register(t *testing.T, h *Handler, body string) (*httptest.ResponseRecorder, map[string]interface{}) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.HandleRegister(rec, httptest.NewRequest(http.MethodPost, "/oauth/register", strings.NewReader(body)))
+	var resp map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	return rec, resp
+}
+
+func TestHandleRegister(t *testing.T) {
+	h := NewHandler(&fakeProvider{}, &config.OAuthConfig{}, nil, store.NewMemory())
+
+	rec, resp := register(t, h, `{"client_name": "Inspector", "redirect_uris": ["http://localhost:6274/callback"]}`)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	clientID := resp["client_id"].(string)
+	assert.True(t, strings.HasPrefix(clientID, "client-"))
+	assert.Equal(t, "none", resp["token_endpoint_auth_method"])
+
+	client, err := store.GetClient(context.Background(), h.store, clientID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"http://localhost:6274/callback"}, client.RedirectURIs)
+
+	tests := map[string]struct {
+		body string
+		code string
+	}{
+		"missing redirect uris": {`{"client_name": "a"}`, "invalid_redirect_uri"},
+		"plain http":            {`{"client_name": "a", "redirect_uris": ["http://evil.example.com/cb"]}`, "invalid_redirect_uri"},
+		"fragment":              {`{"client_name": "a", "redirect_uris": ["https://app.example.com/cb#x"]}`, "invalid_redirect_uri"},
+		"javascript":            {`{"client_name": "a", "redirect_uris": ["javascript:alert(1)"]}`, "invalid_redirect_uri"},
+		"relative":              {`{"client_name": "a", "redirect_uris": ["/cb"]}`, "invalid_redirect_uri"},
+		"grant type":            {`{"client_name": "a", "redirect_uris": ["https://app.example.com/cb"], "grant_types": ["password"]}`, "invalid_client_metadata"},
+		"auth method":           {`{"client_name": "a", "redirect_uris": ["https://app.example.com/cb"], "token_endpoint_auth_method": "private_key_jwt"}`, "invalid_client_metadata"},
+		"missing name":          {`{"redirect_uris": ["https://app.example.com/cb"]}`, "invalid_client_metadata"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			rec, resp := register(t, h, tt.body)
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Equal(t, tt.code, resp["error"])
+		})
+	}
+
+	// Native apps may use a private-use scheme
+	rec, _ = register(t, h, `{"client_name": "a", "redirect_uris": ["cursor://anysphere.cursor-mcp/oauth/callback"]}`)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+}
+
+func authorize(h *Handler, query url.Values) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.HandleAuthorize(rec, httptest.NewRequest(http.MethodGet, "/oauth/authorize?"+query.Encode(), nil))
+	return rec
+}
+
+func callback(h *Handler, query url.Values) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.HandleAuthCallback(rec, httptest.NewRequest(http.MethodGet, "/oauth/callback?"+query.Encode(), nil))
+	return rec
+}
+
+// authorizeCode runs the authorize and callback steps and returns the code
+// issued to the client
+func authorizeCode(t *testing.T, h *Handler, provider *fakeProvider, clientID, verifier string) string {
+	t.Helper()
+	rec := authorize(h, url.Values{
+		"client_id":             {clientID},
+		"redirect_uri":          {"https://app.example.com/cb"},
+		"state":                 {"client-state"},
+		"code_challenge":        {oauth2.S256ChallengeFromVerifier(verifier)},
+		"code_challenge_method": {"S256"},
+	})
+	require.Equal(t, http.StatusFound, rec.Code)
+
+	rec = callback(h, url.Values{"state": {provider.authState}, "code": {"upstream-code"}})
+	require.Equal(t, http.StatusFound, rec.Code)
+	location, err := url.Parse(rec.Header().Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, "app.example.com", location.Host)
+	assert.Equal(t, "client-state", location.Query().Get("state"))
+	return location.Query().Get("code")
+}
+
+func TestRedirectURIEnforcement(t *testing.T) {
+	provider := &fakeProvider{}
+	h := NewHandler(provider, &config.OAuthConfig{}, nil, store.NewMemory())
+	_, resp := register(t, h, `{"client_name": "Inspector", "redirect_uris": ["http://127.0.0.1:6274/callback", "https://app.example.com/cb"]}`)
+	clientID := resp["client_id"].(string)
+	pkce := func(query url.Values) url.Values {
+		query.Set("code_challenge", oauth2.S256ChallengeFromVerifier(oauth2.GenerateVerifier()))
+		query.Set("code_challenge_method", "S256")
+		return query
+	}
+
+	rec := authorize(h, pkce(url.Values{"client_id": {clientID}, "redirect_uri": {"https://app.example.com/cb"}}))
+	assert.Equal(t, http.StatusFound, rec.Code)
+	assert.Equal(t, "https://idp.example.com/authorize", rec.Header().Get("Location"))
+	// The provider always calls back to the server
+	assert.Equal(t, "http://example.com/oauth/callback", provider.authRedirect)
+
+	// Loopback redirects may use another port
+	rec = authorize(h, pkce(url.Values{"client_id": {clientID}, "redirect_uri": {"http://127.0.0.1:50123/callback"}}))
+	assert.Equal(t, http.StatusFound, rec.Code)
+
+	rec = authorize(h, pkce(url.Values{"client_id": {clientID}, "redirect_uri": {"https://evil.example.com/cb"}}))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "invalid_redirect_uri")
+
+	// Several URIs are registered, so the client must pick one
+	rec = authorize(h, pkce(url.Values{"client_id": {clientID}}))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = authorize(h, pkce(url.Values{"client_id": {"client-unknown"}, "redirect_uri": {"https://app.example.com/cb"}}))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "invalid_client")
+}
+
+func TestAuthorizationFlow(t *testing.T) {
+	provider := &fakeProvider{}
+	h := NewHandler(provider, &config.OAuthConfig{}, nil, store.NewMemory())
+	_, resp := register(t, h, `{"client_name": "Inspector", "redirect_uris": ["https://app.example.com/cb"]}`)
+	clientID := resp["client_id"].(string)
+	verifier := oauth2.GenerateVerifier()
+
+	code := authorizeCode(t, h, provider, clientID, verifier)
+	require.NotEmpty(t, code)
+	// The provider sees the server's state and PKCE challenge, not the client's
+	assert.NotEqual(t, "client-state", provider.authState)
+	assert.NotEqual(t, oauth2.S256ChallengeFromVerifier(verifier), provider.authChallenge)
+
+	rec := postToken(h, url.Values{"grant_type": {"authorization_code"}, "code": {code}, "client_id": {clientID},
+		"redirect_uri": {"https://app.example.com/cb"}, "code_verifier": {verifier}})
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "upstream-code", provider.exchangeCode)
+	assert.Equal(t, provider.authChallenge, oauth2.S256ChallengeFromVerifier(provider.exchangeVerifier))
+	assert.Equal(t, "http://example.com/oauth/callback", provider.exchangeRedirect)
+
+	// Codes are single use
+	rec = postToken(h, url.Values{"grant_type": {"authorization_code"}, "code": {code}, "client_id": {clientID}, "code_verifier": {verifier}})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "invalid_grant")
+
+	// A wrong verifier burns the code
+	code = authorizeCode(t, h, provider, clientID, verifier)
+	rec = postToken(h, url.Values{"grant_type": {"authorization_code"}, "code": {code}, "client_id": {clientID}, "code_verifier": {oauth2.GenerateVerifier()}})
+	assert.Contains(t, rec.Body.String(), "PKCE verification failed")
+	rec = postToken(h, url.Values{"grant_type": {"authorization_code"}, "code": {code}, "client_id": {clientID}, "code_verifier": {verifier}})
+	assert.Contains(t, rec.Body.String(), "invalid_grant")
+
+	_, other := register(t, h, `{"client_name": "Other", "redirect_uris": ["https://app.example.com/cb"]}`)
+	code = authorizeCode(t, h, provider, clientID, verifier)
+	rec = postToken(h, url.Values{"grant_type": {"authorization_code"}, "code": {code}, "client_id": {other["client_id"].(string)}, "code_verifier": {verifier}})
+	assert.Contains(t, rec.Body.String(), "issued to another client")
+
+	code = authorizeCode(t, h, provider, clientID, verifier)
+	rec = postToken(h, url.Values{"grant_type": {"authorization_code"}, "code": {code}, "code_verifier": {verifier}})
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	// PKCE is required
+	rec = authorize(h, url.Values{"client_id": {clientID}, "state": {"client-state"}})
+	require.Equal(t, http.StatusFound, rec.Code)
+	location, err := url.Parse(rec.Header().Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, "invalid_request", location.Query().Get("error"))
+	assert.Equal(t, "client-state", location.Query().Get("state"))
+
+	rec = authorize(h, url.Values{"client_id": {clientID}, "code_challenge": {"abc"}, "code_challenge_method": {"plain"}})
+	location, err = url.Parse(rec.Header().Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, "invalid_request", location.Query().Get("error"))
+
+	// Callbacks need a pending state and cannot be replayed
+	rec = callback(h, url.Values{"state": {"forged"}, "code": {"upstream-code"}})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = callback(h, url.Values{"state": {provider.authState}, "code": {"upstream-code"}})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	// Provider errors are passed on to the client
+	authorize(h, url.Values{"client_id": {clientID}, "state": {"s"}, "code_challenge": {oauth2.S256ChallengeFromVerifier(verifier)}, "code_challenge_method": {"S256"}})
+	rec = callback(h, url.Values{"state": {provider.authState}, "error": {"access_denied"}})
+	require.Equal(t, http.StatusFound, rec.Code)
+	assert.Contains(t, rec.Header().Get("Location"), "https://app.example.com/cb?error=access_denied")
+}
+
+func TestAllowedRedirectURIs(t *testing.T) {
+	cfg := &config.OAuthConfig{AllowedRedirectURIs: []string{"https://app.example.com/*", "http://localhost/callback"}}
+	h := NewHandler(&fakeProvider{}, cfg, nil, store.NewMemory())
+
+	rec, _ := register(t, h, `{"client_name": "a", "redirect_uris": ["https://app.example.com/oauth/cb", "http://localhost:6274/callback"]}`)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	rec, resp := register(t, h, `{"client_name": "a", "redirect_uris": ["https://app.example.com/cb", "https://evil.example.com/cb"]}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "invalid_redirect_uri", resp["error"])
+
+	// Tightening the allow-list applies to clients registered before
+	_, resp = register(t, h, `{"client_name": "a", "redirect_uris": ["https://app.example.com/cb"]}`)
+	cfg.AllowedRedirectURIs = []string{"https://app.example.com/oauth/*"}
+	rec = authorize(h, url.Values{"client_id": {resp["client_id"].(string)}, "code_challenge": {"abc"}, "code_challenge_method": {"S256"}})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "invalid_redirect_uri")
+}
+
+func postToken(h *Handler, form url.Values) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/oauth/token", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	h.HandleToken(rec, req)
+	return rec
+}
+
+func TestHandleToken_RefreshToken(t *testing.T) {
+	provider := &fakeProvider{}
+	h := NewHandler(provider, &config.OAuthConfig{}, nil, store.NewMemory())
+	_, resp := register(t, h, `{"client_name": "Inspector", "redirect_uris": ["https://app.example.com/cb"], "grant_types": ["authorization_code", "refresh_token"]}`)
+	clientID := resp["client_id"].(string)
+	assert.Equal(t, []interface{}{"authorization_code", "refresh_token"}, resp["grant_types"])
+
+	// Refresh tokens are only accepted once issued through the token endpoint
+	rec := postToken(h, url.Values{"grant_type": {"refresh_token"}, "refresh_token": {"valid-refresh"}, "client_id": {clientID}})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "invalid_grant")
+
+	verifier := oauth2.GenerateVerifier()
+	code := authorizeCode(t, h, provider, clientID, verifier)
+	rec = postToken(h, url.Values{"grant_type": {"authorization_code"}, "code": {code}, "client_id": {clientID}, "code_verifier": {verifier}})
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"refresh_token":"valid-refresh"`)
+
+	rec = postToken(h, url.Values{"grant_type": {"refresh_token"}, "refresh_token": {"valid-refresh"}, "client_id": {clientID}})
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"access_token":"refreshed"`)
+
+	rec = postToken(h, url.Values{"grant_type": {"refresh_token"}, "refresh_token": {"revoked"}, "client_id": {clientID}})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "invalid_grant")
+
+	rec = postToken(h, url.Values{"grant_type": {"refresh_token"}, "client_id": {clientID}})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "invalid_request")
+
+	rec = postToken(h, url.Values{"grant_type": {"refresh_token"}, "refresh_token": {"valid-refresh"}})
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	// Refresh tokens are bound to the client they were issued to
+	_, resp = register(t, h, `{"client_name": "Other", "redirect_uris": ["https://app.example.com/cb"], "grant_types": ["authorization_code", "refresh_token"]}`)
+	rec = postToken(h, url.Values{"grant_type": {"refresh_token"}, "refresh_token": {"valid-refresh"}, "client_id": {resp["client_id"].(string)}})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "issued to another client")
+
+	// The binding survives a refresh
+	rec = postToken(h, url.Values{"grant_type": {"refresh_token"}, "refresh_token": {"valid-refresh"}, "client_id": {clientID}})
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// Clients must register for the grant
+	_, resp = register(t, h, `{"client_name": "Other", "redirect_uris": ["https://app.example.com/cb"]}`)
+	rec = postToken(h, url.Values{"grant_type": {"refresh_token"}, "refresh_token": {"valid-refresh"}, "client_id": {resp["client_id"].(string)}})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "unauthorized_client")
+
+	rec = postToken(h, url.Values{"grant_type": {"password"}})
+	assert.Contains(t, rec.Body.String(), "unsupported_grant_type")
+}
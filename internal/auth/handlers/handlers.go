@@ -1,45 +1,73 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/brizzai/auto-mcp/internal/auth/constants"
 	"github.com/brizzai/auto-mcp/internal/auth/providers"
+	"github.com/brizzai/auto-mcp/internal/auth/store"
 	"github.com/brizzai/auto-mcp/internal/config"
 	"github.com/brizzai/auto-mcp/internal/logger"
 	"github.com/brizzai/auto-mcp/internal/utils"
 	"go.uber.org/zap"
+	"golang.org/x/oauth2"
 )
 
 // Handler handles OAuth-related HTTP requests
 type Handler struct {
 	authProvider providers.OAuthProvider
 	cfg          *config.OAuthConfig
+	serverCfg    *config.ServerConfig
+	store        store.Store
 }
 
-// NewHandler creates a new Handler instance
-func NewHandler(provider providers.OAuthProvider, cfg *config.OAuthConfig) *Handler {
+// NewHandler creates a new Handler instance. Registered clients are kept in st.
+func NewHandler(provider providers.OAuthProvider, cfg *config.OAuthConfig, serverCfg *config.ServerConfig, st store.Store) *Handler {
+	if serverCfg == nil {
+		serverCfg = &config.ServerConfig{}
+	}
 	return &Handler{
 		authProvider: provider,
 		cfg:          cfg,
+		serverCfg:    serverCfg,
+		store:        st,
 	}
 }
 
-// HandleProtectedResourceDiscovery handles /.well-known/oauth-protected-resource
+// ResourceMetadataURL returns the URL of the protected resource metadata,
+// which unauthorized responses point clients to
+func (h *Handler) ResourceMetadataURL(r *http.Request) string {
+	return h.serverCfg.ExternalURL(r) + "/.well-known/oauth-protected-resource"
+}
+
+// HandleProtectedResourceDiscovery handles /.well-known/oauth-protected-resource (RFC 9728).
+// The authorization server is auto-mcp itself unless oauth.authorization_server is set.
 func (h *Handler) HandleProtectedResourceDiscovery(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
+	baseURL := h.serverCfg.ExternalURL(r)
+	authorizationServer := baseURL
+	if h.cfg.AuthorizationServer != "" {
+		authorizationServer = strings.TrimSuffix(h.cfg.AuthorizationServer, "/")
+	}
 	discovery := map[string]interface{}{
-		"resource":              r.Host,
-		"authorization_servers": []string{r.Host},
-		"token_types_supported": []string{constants.TokenType},
-		"resource_metadata_uri": fmt.Sprintf("%s/.well-known/oauth-protected-resource", r.Host),
+		"resource":                 baseURL,
+		"authorization_servers":    []string{authorizationServer},
+		"bearer_methods_supported": []string{"header"},
+	}
+	if len(h.cfg.Scopes) > 0 {
+		discovery["scopes_supported"] = h.cfg.Scopes
 	}
 
 	utils.WriteJSON(w, discovery)
@@ -51,11 +79,12 @@ func (h *Handler) HandleAuthorizationServerDiscovery(w http.ResponseWriter, r *h
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
+	baseURL := h.serverCfg.ExternalURL(r)
 	discovery := map[string]interface{}{
-		"issuer":                                r.URL.Host,
-		"authorization_endpoint":                fmt.Sprintf("%s/oauth/authorize", r.Host),
-		"token_endpoint":                        fmt.Sprintf("%s/oauth/token", r.Host),
-		"registration_endpoint":                 fmt.Sprintf("%s/oauth/register", r.Host),
+		"issuer":                                baseURL,
+		"authorization_endpoint":                fmt.Sprintf("%s/oauth/authorize", baseURL),
+		"token_endpoint":                        fmt.Sprintf("%s/oauth/token", baseURL),
+		"registration_endpoint":                 fmt.Sprintf("%s/oauth/register", baseURL),
 		"token_endpoint_auth_methods_supported": constants.SupportedAuthMethods,
 		"scopes_supported":                      h.cfg.Scopes,
 		"response_types_supported":              constants.SupportedResponseTypes,
@@ -79,101 +108,314 @@ func (h *Handler) HandleToken(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	grantType := r.FormValue("grant_type")
-	if grantType != "authorization_code" {
+	switch r.FormValue("grant_type") {
+	case "authorization_code":
+		h.exchangeCode(w, r)
+	case "refresh_token":
+		h.refreshToken(w, r)
+	default:
 		utils.WriteError(w, "unsupported_grant_type", "Unsupported grant type", http.StatusBadRequest)
-		return
 	}
+}
 
+// exchangeCode handles the authorization_code grant. The code must have been
+// issued by HandleAuthCallback to the same client and redirect URI, and the
+// client must prove possession of the PKCE verifier.
+func (h *Handler) exchangeCode(w http.ResponseWriter, r *http.Request) {
 	code := r.FormValue("code")
 	if code == "" {
 		utils.WriteError(w, "invalid_request", "Code is required", http.StatusBadRequest)
 		return
 	}
 
+	client, err := h.loadClient(r.Context(), r.FormValue("client_id"))
+	if err != nil {
+		writeClientError(w, err, http.StatusUnauthorized)
+		return
+	}
+
+	// Taking the code makes it single use, even when the checks below fail
+	authz, err := store.TakeCode(r.Context(), h.store, code)
+	if errors.Is(err, store.ErrNotFound) {
+		utils.WriteError(w, "invalid_grant", "Authorization code is invalid, expired or already used", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to load authorization code", zap.Error(err))
+		utils.WriteError(w, "server_error", "Failed to load authorization code", http.StatusInternalServerError)
+		return
+	}
+	if authz.ClientID != client.ID {
+		utils.WriteError(w, "invalid_grant", "Authorization code was issued to another client", http.StatusBadRequest)
+		return
+	}
+	if redirectURI := r.FormValue("redirect_uri"); redirectURI != "" && redirectURI != authz.RedirectURI {
+		utils.WriteError(w, "invalid_grant", "redirect_uri does not match the authorization request", http.StatusBadRequest)
+		return
+	}
+	if !verifyPKCE(authz.CodeChallenge, r.FormValue("code_verifier")) {
+		utils.WriteError(w, "invalid_grant", "PKCE verification failed", http.StatusBadRequest)
+		return
+	}
+
 	tokenResp, err := h.authProvider.ExchangeCode(
 		r.Context(),
-		code,
-		r.FormValue("code_verifier"),
-		r.FormValue("redirect_uri"),
+		authz.Code,
+		authz.CodeVerifier,
+		authz.CallbackURL,
 	)
 	if err != nil {
 		logger.Error("Failed to exchange code", zap.Error(err))
 		utils.WriteError(w, "invalid_grant", err.Error(), http.StatusBadRequest)
 		return
 	}
+	if err := h.bindRefreshToken(r.Context(), tokenResp, client.ID); err != nil {
+		logger.Error("Failed to save refresh token", zap.Error(err))
+		utils.WriteError(w, "server_error", "Failed to issue tokens", http.StatusInternalServerError)
+		return
+	}
 	utils.WriteJSON(w, tokenResp)
 }
 
-// HandleRegister handles client registration
+// refreshTokenBindingTTL is how long an unused refresh token stays bound to
+// its client, renewed on every refresh
+const refreshTokenBindingTTL = 90 * 24 * time.Hour
+
+// bindRefreshToken binds the refresh token of tokenResp, if any, to the
+// client it is issued to
+func (h *Handler) bindRefreshToken(ctx context.Context, tokenResp *oauth2.Token, clientID string) error {
+	if tokenResp.RefreshToken == "" {
+		return nil
+	}
+	return store.BindRefreshToken(ctx, h.store, tokenResp.RefreshToken, clientID, refreshTokenBindingTTL)
+}
+
+// refreshToken handles the refresh_token grant by passing it through to the
+// provider, so clients can renew access without another consent screen. The
+// refresh token must have been issued to the same client (RFC 6749 section 6).
+func (h *Handler) refreshToken(w http.ResponseWriter, r *http.Request) {
+	refreshToken := r.FormValue("refresh_token")
+	if refreshToken == "" {
+		utils.WriteError(w, "invalid_request", "Refresh token is required", http.StatusBadRequest)
+		return
+	}
+
+	client, err := h.loadClient(r.Context(), r.FormValue("client_id"))
+	if err != nil {
+		writeClientError(w, err, http.StatusUnauthorized)
+		return
+	}
+	if !slices.Contains(client.GrantTypes, "refresh_token") {
+		utils.WriteError(w, "unauthorized_client", "Client is not registered for the refresh_token grant", http.StatusBadRequest)
+		return
+	}
+
+	boundClientID, err := store.RefreshTokenClient(r.Context(), h.store, refreshToken)
+	if errors.Is(err, store.ErrNotFound) {
+		utils.WriteError(w, "invalid_grant", "Refresh token is invalid or expired", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to load refresh token", zap.Error(err))
+		utils.WriteError(w, "server_error", "Failed to load refresh token", http.StatusInternalServerError)
+		return
+	}
+	if boundClientID != client.ID {
+		logger.Warn("Refresh token presented by another client", zap.String("client_id", client.ID))
+		utils.WriteError(w, "invalid_grant", "Refresh token was issued to another client", http.StatusBadRequest)
+		return
+	}
+
+	tokenResp, err := h.authProvider.RefreshToken(r.Context(), refreshToken)
+	if err != nil {
+		logger.Warn("Failed to refresh token", zap.String("client_id", client.ID), zap.Error(err))
+		utils.WriteError(w, "invalid_grant", "Refresh token is invalid or expired", http.StatusBadRequest)
+		return
+	}
+	// Providers that do not rotate refresh tokens keep the current one valid
+	if tokenResp.RefreshToken == "" {
+		tokenResp.RefreshToken = refreshToken
+	}
+	if tokenResp.RefreshToken != refreshToken {
+		if err := store.UnbindRefreshToken(r.Context(), h.store, refreshToken); err != nil {
+			logger.Warn("Failed to forget rotated refresh token", zap.Error(err))
+		}
+	}
+	if err := h.bindRefreshToken(r.Context(), tokenResp, client.ID); err != nil {
+		logger.Error("Failed to save refresh token", zap.Error(err))
+		utils.WriteError(w, "server_error", "Failed to issue tokens", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSON(w, tokenResp)
+}
+
+// HandleRegister handles dynamic client registration (RFC 7591). Clients are
+// kept in the store and their redirect URIs are enforced on authorize and token.
 func (h *Handler) HandleRegister(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	var req struct {
-		ClientName   string   `json:"client_name"`
-		RedirectURIs []string `json:"redirect_uris"`
-	}
-
+	var req registrationRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		utils.WriteError(w, "invalid_request", "Invalid request body", http.StatusBadRequest)
 		return
 	}
+	if err := req.validate(); err != nil {
+		utils.WriteError(w, errorCode(err, "invalid_client_metadata"), errorDescription(err), http.StatusBadRequest)
+		return
+	}
 
-	if req.ClientName == "" {
-		utils.WriteError(w, "invalid_request", "Client name is required", http.StatusBadRequest)
+	clientID, err := newClientID()
+	if err != nil {
+		logger.Error("Failed to generate client ID", zap.Error(err))
+		utils.WriteError(w, "server_error", "Failed to register client", http.StatusInternalServerError)
 		return
 	}
+	for _, uri := range req.RedirectURIs {
+		if !redirectAllowListed(h.cfg.AllowedRedirectURIs, uri) {
+			utils.WriteError(w, "invalid_redirect_uri", fmt.Sprintf("%q is not in oauth.allowed_redirect_uris", uri), http.StatusBadRequest)
+			return
+		}
+	}
 
-	clientID := fmt.Sprintf("client-%d", time.Now().UnixNano())
+	client := &store.Client{
+		ID:           clientID,
+		Name:         req.ClientName,
+		RedirectURIs: req.RedirectURIs,
+		GrantTypes:   req.GrantTypes,
+		CreatedAt:    time.Now().UTC(),
+	}
+	if err := store.SaveClient(r.Context(), h.store, client); err != nil {
+		logger.Error("Failed to save client registration", zap.Error(err))
+		utils.WriteError(w, "server_error", "Failed to register client", http.StatusInternalServerError)
+		return
+	}
+	logger.Info("Registered OAuth client", zap.String("client_id", client.ID), zap.String("client_name", client.Name))
 
 	resp := map[string]interface{}{
-		"client_id":                  clientID,
-		"token_endpoint_auth_method": "none",
-		"redirect_uris":              req.RedirectURIs,
+		"client_id":                  client.ID,
+		"client_id_issued_at":        client.CreatedAt.Unix(),
+		"client_name":                client.Name,
+		"redirect_uris":              client.RedirectURIs,
+		"grant_types":                client.GrantTypes,
+		"response_types":             req.ResponseTypes,
+		"token_endpoint_auth_method": req.TokenEndpointAuthMethod,
 	}
 
 	w.WriteHeader(http.StatusCreated)
 	utils.WriteJSON(w, resp)
 }
 
-// HandleAuthorize handles the authorization endpoint
+// HandleAuthorize handles the authorization endpoint. The client's request is
+// kept in the store under a server-generated state, and the user is sent to
+// the provider with that state and the server's own callback URL and PKCE
+// verifier.
 func (h *Handler) HandleAuthorize(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	state := r.URL.Query().Get("state")
-	codeChallenge := r.URL.Query().Get("code_challenge")
-	codeChallengeMethod := r.URL.Query().Get("code_challenge_method")
-	redirectURI := r.URL.Query().Get("redirect_uri")
+	query := r.URL.Query()
+	// Never redirect to an unverified URI, the code would leak to it
+	redirectURI, err := h.resolveRedirect(r.Context(), query.Get("client_id"), query.Get("redirect_uri"))
+	if err != nil {
+		writeClientError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	state := query.Get("state")
+	if responseType := query.Get("response_type"); responseType != "" && !slices.Contains(constants.SupportedResponseTypes, responseType) {
+		redirectError(w, r, redirectURI, state, "unsupported_response_type", "Only the code response type is supported")
+		return
+	}
+	// PKCE is required, a plain challenge would reveal the verifier
+	codeChallenge := query.Get("code_challenge")
+	if codeChallenge == "" {
+		redirectError(w, r, redirectURI, state, "invalid_request", "code_challenge is required")
+		return
+	}
+	codeChallengeMethod := query.Get("code_challenge_method")
+	if !slices.Contains(constants.SupportedPKCEMethods, codeChallengeMethod) {
+		redirectError(w, r, redirectURI, state, "invalid_request", "code_challenge_method must be S256")
+		return
+	}
 
-	authURL := h.authProvider.GetAuthURL(state, codeChallenge, codeChallengeMethod, redirectURI)
+	serverState, err := randomHex(32)
+	if err != nil {
+		logger.Error("Failed to generate state", zap.Error(err))
+		utils.WriteError(w, "server_error", "Failed to start authorization", http.StatusInternalServerError)
+		return
+	}
+	authz := &store.Authorization{
+		ClientID:            query.Get("client_id"),
+		RedirectURI:         redirectURI,
+		State:               state,
+		CodeChallenge:       codeChallenge,
+		CodeChallengeMethod: codeChallengeMethod,
+		CallbackURL:         h.serverCfg.ExternalURL(r) + "/oauth/callback",
+		CodeVerifier:        oauth2.GenerateVerifier(),
+	}
+	if err := store.SaveAuthorization(r.Context(), h.store, serverState, authz, authorizationTTL); err != nil {
+		logger.Error("Failed to save authorization", zap.Error(err))
+		utils.WriteError(w, "server_error", "Failed to start authorization", http.StatusInternalServerError)
+		return
+	}
+
+	authURL := h.authProvider.GetAuthURL(serverState, oauth2.S256ChallengeFromVerifier(authz.CodeVerifier), "S256", authz.CallbackURL)
 	http.Redirect(w, r, authURL, http.StatusFound)
 }
 
-// HandleAuthCallback handles the OAuth callback
+// HandleAuthCallback handles the provider's redirect after sign-in. The state
+// must belong to a pending authorization; the provider's code is kept
+// server-side and the client receives a new code bound to its PKCE challenge.
 func (h *Handler) HandleAuthCallback(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	code := r.URL.Query().Get("code")
-	state := r.URL.Query().Get("state")
+	query := r.URL.Query()
+	state := query.Get("state")
+	if state == "" {
+		utils.WriteError(w, "invalid_request", "State is required", http.StatusBadRequest)
+		return
+	}
+	// Taking the state makes it single use, a replayed callback is rejected
+	authz, err := store.TakeAuthorization(r.Context(), h.store, state)
+	if errors.Is(err, store.ErrNotFound) {
+		utils.WriteError(w, "invalid_request", "Unknown or expired state", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to load authorization", zap.Error(err))
+		utils.WriteError(w, "server_error", "Failed to load authorization", http.StatusInternalServerError)
+		return
+	}
 
-	if code == "" {
-		utils.WriteError(w, "invalid_request", "Code is required", http.StatusBadRequest)
+	if providerError := query.Get("error"); providerError != "" {
+		redirectError(w, r, authz.RedirectURI, authz.State, providerError, query.Get("error_description"))
+		return
+	}
+	authz.Code = query.Get("code")
+	if authz.Code == "" {
+		redirectError(w, r, authz.RedirectURI, authz.State, "invalid_request", "The provider did not return a code")
 		return
 	}
 
-	resp := map[string]interface{}{
-		"code":  code,
-		"state": state,
+	code, err := randomHex(32)
+	if err != nil {
+		logger.Error("Failed to generate authorization code", zap.Error(err))
+		redirectError(w, r, authz.RedirectURI, authz.State, "server_error", "Failed to issue authorization code")
+		return
+	}
+	if err := store.SaveCode(r.Context(), h.store, code, authz, codeTTL); err != nil {
+		logger.Error("Failed to save authorization code", zap.Error(err))
+		redirectError(w, r, authz.RedirectURI, authz.State, "server_error", "Failed to issue authorization code")
+		return
 	}
 
-	utils.WriteJSON(w, resp)
+	redirectWithParams(w, r, authz.RedirectURI, authz.State, url.Values{"code": {code}})
 }
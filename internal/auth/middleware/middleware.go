@@ -3,6 +3,7 @@ package middleware
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -27,10 +28,15 @@ type AuthInfo struct {
 	Email  string
 	Name   string
 	Token  string
+	// Scopes are the scopes granted to the access token
+	Scopes []string
+	// Claims holds additional provider-specific profile attributes
+	Claims map[string]interface{}
 }
 
-// Authenticate middleware validates JWT or access token with the IDP
-func Authenticate(provider providers.OAuthProvider) func(http.Handler) http.Handler {
+// Authenticate middleware validates JWT or access token with the IDP.
+// Unauthorized responses point clients at resourceMetadataURL (RFC 9728).
+func Authenticate(provider providers.OAuthProvider, resourceMetadataURL func(r *http.Request) string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			logger.Debug("Authenticate middleware request",
@@ -41,12 +47,19 @@ func Authenticate(provider providers.OAuthProvider) func(http.Handler) http.Hand
 			)
 			token := extractToken(r)
 			if token == "" {
+				// No error in the challenge, the client has not tried to authenticate yet (RFC 6750 section 3.1)
+				w.Header().Set("WWW-Authenticate", bearerChallenge(resourceMetadataURL(r), "", ""))
 				writeError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
 				return
 			}
 
 			userInfo, err := provider.ValidateAccessToken(r.Context(), token)
+			if errors.Is(err, providers.ErrUserNotAllowed) {
+				writeError(w, http.StatusForbidden, "access_denied", err.Error())
+				return
+			}
 			if err != nil {
+				w.Header().Set("WWW-Authenticate", bearerChallenge(resourceMetadataURL(r), "invalid_token", err.Error()))
 				writeError(w, http.StatusUnauthorized, "invalid_token", err.Error())
 				return
 			}
@@ -56,6 +69,8 @@ func Authenticate(provider providers.OAuthProvider) func(http.Handler) http.Hand
 				Email:  userInfo.Email,
 				Name:   userInfo.Name,
 				Token:  token,
+				Scopes: userInfo.Scopes,
+				Claims: userInfo.Metadata,
 			})
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
@@ -83,6 +98,8 @@ func OptionalAuthenticate(provider providers.OAuthProvider) func(http.Handler) h
 				Email:  userInfo.Email,
 				Name:   userInfo.Name,
 				Token:  token,
+				Scopes: userInfo.Scopes,
+				Claims: userInfo.Metadata,
 			})
 
 			next.ServeHTTP(w, r.WithContext(ctx))
@@ -126,12 +143,24 @@ func extractToken(r *http.Request) string {
 	return r.URL.Query().Get(constants.TokenQueryParam)
 }
 
+// bearerChallenge builds a WWW-Authenticate value; code and description are
+// omitted when empty
+func bearerChallenge(resourceMetadataURL, code, description string) string {
+	challenge := fmt.Sprintf(`Bearer realm="MCP Server", resource_metadata=%s`, quoteParam(resourceMetadataURL))
+	if code != "" {
+		challenge += fmt.Sprintf(", error=%s, error_description=%s", quoteParam(code), quoteParam(description))
+	}
+	return challenge
+}
+
+// quoteParam quotes an auth-param value, escaping quotes and backslashes
+func quoteParam(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\r", " ", "\n", " ").Replace(value) + `"`
+}
+
 // writeError writes a JSON error response
 func writeError(w http.ResponseWriter, status int, code, message string) {
 	w.Header().Set("Content-Type", "application/json")
-	if status == http.StatusUnauthorized {
-		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="MCP Server", error="%s", error_description="%s"`, code, message))
-	}
 	w.WriteHeader(status)
 	if err := json.NewEncoder(w).Encode(map[string]string{
 		"error":             code,
@@ -2,10 +2,12 @@ package models
 
 // UserInfo represents authenticated user information from any provider
 type UserInfo struct {
-	ID       string
-	Email    string
-	Name     string
-	Picture  string
+	ID      string
+	Email   string
+	Name    string
+	Picture string
+	// Scopes are the scopes granted to the access token, when the provider reports them
+	Scopes   []string
 	Metadata map[string]interface{}
 }
 
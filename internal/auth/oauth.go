@@ -1,11 +1,13 @@
 package auth
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/brizzai/auto-mcp/internal/auth/handlers"
 	"github.com/brizzai/auto-mcp/internal/auth/middleware"
 	"github.com/brizzai/auto-mcp/internal/auth/providers"
+	"github.com/brizzai/auto-mcp/internal/auth/store"
 	"github.com/brizzai/auto-mcp/internal/config"
 )
 
@@ -13,16 +15,29 @@ import (
 type Service struct {
 	config       *config.OAuthConfig
 	authProvider providers.OAuthProvider
-	handler      *handlers.Handler
+	// validator validates access tokens, caching results in the store
+	validator providers.OAuthProvider
+	store     store.Store
+	handler   *handlers.Handler
 }
 
-// NewService creates a new OAuth service
-func NewService(cfg *config.OAuthConfig, provider providers.OAuthProvider) (*Service, error) {
-	handler := handlers.NewHandler(provider, cfg)
+// NewService creates a new OAuth service. serverCfg determines the URLs
+// advertised in discovery documents and may be nil.
+func NewService(cfg *config.OAuthConfig, provider providers.OAuthProvider, serverCfg *config.ServerConfig) (*Service, error) {
+	st, err := store.New(cfg.Store)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create oauth store: %w", err)
+	}
+	if login, ok := provider.(providers.LoginProvider); ok {
+		login.UseStore(st)
+	}
+	handler := handlers.NewHandler(provider, cfg, serverCfg, st)
 
 	return &Service{
 		config:       cfg,
 		authProvider: provider,
+		validator:    withAllowList(withTokenCache(provider, st, cfg.TokenCacheDuration()), cfg),
+		store:        st,
 		handler:      handler,
 	}, nil
 }
@@ -38,6 +53,10 @@ func (s *Service) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/oauth/token", s.handler.HandleToken)
 	mux.HandleFunc("/oauth/register", s.handler.HandleRegister)
 	mux.HandleFunc("/oauth/callback", s.handler.HandleAuthCallback)
+
+	if login, ok := s.authProvider.(providers.LoginProvider); ok {
+		login.RegisterRoutes(mux)
+	}
 }
 
 // WrapWithCors wraps the mux with authentication middleware
@@ -47,12 +66,27 @@ func (s *Service) WrapWithCors(handler http.Handler) http.Handler {
 
 // Authenticate returns the authentication middleware
 func (s *Service) Authenticate() func(http.Handler) http.Handler {
-	return middleware.Authenticate(s.authProvider)
+	return middleware.Authenticate(s.validator, s.handler.ResourceMetadataURL)
 }
 
 // OptionalAuthenticate returns the optional authentication middleware
 func (s *Service) OptionalAuthenticate() func(http.Handler) http.Handler {
-	return middleware.OptionalAuthenticate(s.authProvider)
+	return middleware.OptionalAuthenticate(s.validator)
+}
+
+// Close releases the store
+func (s *Service) Close() error {
+	return s.store.Close()
+}
+
+// SetClientSecret rotates the OAuth client secret, it reports false when the
+// provider does not use one
+func (s *Service) SetClientSecret(secret string) bool {
+	setter, ok := s.authProvider.(providers.ClientSecretSetter)
+	if ok {
+		setter.SetClientSecret(secret)
+	}
+	return ok
 }
 
 // GetProvider returns the configured auth provider
@@ -2,12 +2,16 @@ package auth
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/brizzai/auto-mcp/internal/auth/models"
+	"github.com/brizzai/auto-mcp/internal/auth/providers"
 	"github.com/brizzai/auto-mcp/internal/config"
 	"golang.org/x/oauth2"
 )
@@ -36,7 +40,7 @@ func (m *mockProvider) ValidateAccessToken(ctx context.Context, token string) (*
 func TestNewService(t *testing.T) {
 	cfg := &config.OAuthConfig{}
 	provider := &mockProvider{}
-	service, err := NewService(cfg, provider)
+	service, err := NewService(cfg, provider, nil)
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -54,7 +58,7 @@ func TestNewService(t *testing.T) {
 func TestRegisterRoutes(t *testing.T) {
 	cfg := &config.OAuthConfig{}
 	provider := &mockProvider{}
-	service, _ := NewService(cfg, provider)
+	service, _ := NewService(cfg, provider, nil)
 	mux := http.NewServeMux()
 	service.RegisterRoutes(mux)
 
@@ -78,7 +82,7 @@ func TestRegisterRoutes(t *testing.T) {
 func TestWrapWithCors(t *testing.T) {
 	cfg := &config.OAuthConfig{}
 	provider := &mockProvider{}
-	service, _ := NewService(cfg, provider)
+	service, _ := NewService(cfg, provider, nil)
 	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(204)
 	})
@@ -94,8 +98,161 @@ func TestWrapWithCors(t *testing.T) {
 func TestGetProvider(t *testing.T) {
 	cfg := &config.OAuthConfig{}
 	provider := &mockProvider{}
-	service, _ := NewService(cfg, provider)
+	service, _ := NewService(cfg, provider, nil)
 	if !reflect.DeepEqual(service.GetProvider(), provider) {
 		t.Errorf("GetProvider did not return the expected provider")
 	}
 }
+
+// countingProvider counts access token validations
+type countingProvider struct {
+	mockProvider
+	validations int
+}
+
+func (p *countingProvider) ValidateAccessToken(ctx context.Context, token string) (*models.UserInfo, error) {
+	p.validations++
+	return &models.UserInfo{ID: "user-" + token}, nil
+}
+
+func TestTokenCache(t *testing.T) {
+	provider := &countingProvider{}
+	service, err := NewService(&config.OAuthConfig{}, provider, nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	for _, token := range []string{"a", "a", "b", "a"} {
+		userInfo, err := service.validator.ValidateAccessToken(context.Background(), token)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if userInfo.ID != "user-"+token {
+			t.Errorf("expected user-%s, got %s", token, userInfo.ID)
+		}
+	}
+	if provider.validations != 2 {
+		t.Errorf("expected 2 provider validations, got %d", provider.validations)
+	}
+
+	// A negative TTL disables the cache
+	provider = &countingProvider{}
+	service, _ = NewService(&config.OAuthConfig{TokenCacheTTL: -1}, provider, nil)
+	_, _ = service.validator.ValidateAccessToken(context.Background(), "a")
+	_, _ = service.validator.ValidateAccessToken(context.Background(), "a")
+	if provider.validations != 2 {
+		t.Errorf("expected 2 provider validations, got %d", provider.validations)
+	}
+}
+
+// emailProvider treats the token as the user's email
+type emailProvider struct {
+	mockProvider
+}
+
+func (p *emailProvider) ValidateAccessToken(ctx context.Context, token string) (*models.UserInfo, error) {
+	email, unverified := strings.CutPrefix(token, "unverified:")
+	return &models.UserInfo{ID: email, Email: email, Metadata: map[string]interface{}{"email_verified": !unverified}}, nil
+}
+
+func TestAllowList(t *testing.T) {
+	cfg := &config.OAuthConfig{AllowedEmails: []string{"Contractor@Gmail.com"}, AllowedDomains: []string{"example.com"}}
+	service, err := NewService(cfg, &emailProvider{}, nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	tests := map[string]bool{
+		"alice@example.com":            true,
+		"ALICE@EXAMPLE.COM":            true,
+		"contractor@gmail.com":         true,
+		"mallory@gmail.com":            false,
+		"bob@sub.example.com":          false,
+		"bob@example.com.evil.com":     false,
+		"unverified:alice@example.com": false,
+		"":                             false,
+	}
+	for token, allowed := range tests {
+		_, err := service.validator.ValidateAccessToken(context.Background(), token)
+		if allowed && err != nil {
+			t.Errorf("%q: expected to be allowed, got %v", token, err)
+		}
+		if !allowed && !errors.Is(err, providers.ErrUserNotAllowed) {
+			t.Errorf("%q: expected ErrUserNotAllowed, got %v", token, err)
+		}
+	}
+
+	// Rejected users get 403 instead of being asked to sign in again
+	handler := service.Authenticate()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	req := httptest.NewRequest(http.MethodGet, "/mcp", nil)
+	req.Header.Set("Authorization", "Bearer mallory@gmail.com")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected 403, got %d", rec.Code)
+	}
+}
+
+// rejectingProvider rejects every access token
+type rejectingProvider struct {
+	mockProvider
+}
+
+func (p *rejectingProvider) ValidateAccessToken(ctx context.Context, token string) (*models.UserInfo, error) {
+	return nil, errors.New(`token "expired"`)
+}
+
+func TestAuthenticate_ResourceMetadata(t *testing.T) {
+	service, _ := NewService(&config.OAuthConfig{}, &rejectingProvider{}, &config.ServerConfig{PublicURL: "https://mcp.example.com/api"})
+	handler := service.Authenticate()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/mcp", nil))
+	expected := `Bearer realm="MCP Server", resource_metadata="https://mcp.example.com/api/.well-known/oauth-protected-resource"`
+	if got := rec.Header().Get("WWW-Authenticate"); got != expected {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/mcp", nil)
+	req.Header.Set("Authorization", "Bearer abc")
+	handler.ServeHTTP(rec, req)
+	expected += `, error="invalid_token", error_description="token \"expired\""`
+	if got := rec.Header().Get("WWW-Authenticate"); got != expected {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+}
+
+func TestProtectedResourceDiscovery(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      *config.OAuthConfig
+		expected string
+	}{
+		{"self", &config.OAuthConfig{}, "http://mcp.local"},
+		{"external", &config.OAuthConfig{AuthorizationServer: "https://keycloak.example.com/realms/acme/"}, "https://keycloak.example.com/realms/acme"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _ := NewService(tt.cfg, &mockProvider{}, nil)
+			mux := http.NewServeMux()
+			service.RegisterRoutes(mux)
+
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://mcp.local/.well-known/oauth-protected-resource", nil))
+			var metadata struct {
+				Resource             string   `json:"resource"`
+				AuthorizationServers []string `json:"authorization_servers"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &metadata); err != nil {
+				t.Fatalf("expected JSON, got %v", err)
+			}
+			if metadata.Resource != "http://mcp.local" {
+				t.Errorf("expected resource http://mcp.local, got %s", metadata.Resource)
+			}
+			if len(metadata.AuthorizationServers) != 1 || metadata.AuthorizationServers[0] != tt.expected {
+				t.Errorf("expected authorization server %s, got %v", tt.expected, metadata.AuthorizationServers)
+			}
+		})
+	}
+}
@@ -0,0 +1,213 @@
+// Package policy decides which tools an authenticated user may call based on
+// their scopes and identity claims.
+package policy
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/brizzai/auto-mcp/internal/auth/middleware"
+	"github.com/brizzai/auto-mcp/internal/config"
+)
+
+const (
+	// DefaultAllow lets users call tools no rule matches
+	DefaultAllow = "allow"
+	// DefaultDeny rejects calls to tools no rule matches
+	DefaultDeny = "deny"
+)
+
+// subject kinds accepted in rule and role definitions
+const (
+	kindAny         = "*"
+	kindUser        = "user"
+	kindEmail       = "email"
+	kindEmailDomain = "email_domain"
+	kindScope       = "scope"
+	kindGroup       = "group"
+	kindRole        = "role"
+	kindClaim       = "claim"
+)
+
+// subject is a single principal such as "role:admin" or "email_domain:example.com"
+type subject struct {
+	kind  string
+	name  string // claim name for claim subjects
+	value string
+}
+
+type rule struct {
+	tools   []string
+	methods []string
+	allow   []subject
+}
+
+// Policy maps users to the tools they may call. Rules are evaluated in order
+// and the first one matching a tool decides; tools no rule matches fall back
+// to the default.
+type Policy struct {
+	defaultAllow bool
+	roles        map[string][]subject
+	rules        []rule
+}
+
+// New compiles the policy configuration. It returns nil when no rules are
+// configured and the default allows everything.
+func New(cfg config.PolicyConfig) (*Policy, error) {
+	p := &Policy{roles: make(map[string][]subject, len(cfg.Roles))}
+	switch strings.ToLower(cfg.Default) {
+	case "", DefaultAllow:
+		p.defaultAllow = true
+	case DefaultDeny:
+	default:
+		return nil, fmt.Errorf("default must be %q or %q, got %q", DefaultAllow, DefaultDeny, cfg.Default)
+	}
+
+	for role, members := range cfg.Roles {
+		subjects, err := parseSubjects(members)
+		if err != nil {
+			return nil, fmt.Errorf("role %q: %w", role, err)
+		}
+		for _, s := range subjects {
+			if s.kind == kindRole {
+				return nil, fmt.Errorf("role %q: roles cannot include other roles", role)
+			}
+		}
+		p.roles[role] = subjects
+	}
+
+	for i, r := range cfg.Rules {
+		for _, pattern := range r.Tools {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("rule %d: invalid tool pattern %q: %w", i+1, pattern, err)
+			}
+		}
+		allow, err := parseSubjects(r.Allow)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i+1, err)
+		}
+		p.rules = append(p.rules, rule{tools: r.Tools, methods: r.Methods, allow: allow})
+	}
+
+	if len(p.rules) == 0 && p.defaultAllow {
+		return nil, nil
+	}
+	return p, nil
+}
+
+// Allowed reports whether the user may call the tool. method is the HTTP
+// method of the tool's upstream request, empty for tools without one.
+// A nil policy allows everything.
+func (p *Policy) Allowed(authInfo *middleware.AuthInfo, tool, method string) bool {
+	if p == nil {
+		return true
+	}
+	for _, r := range p.rules {
+		if !r.matches(tool, method) {
+			continue
+		}
+		return authInfo != nil && slices.ContainsFunc(r.allow, func(s subject) bool {
+			return p.satisfies(authInfo, s)
+		})
+	}
+	return p.defaultAllow
+}
+
+func (r rule) matches(tool, method string) bool {
+	if len(r.tools) > 0 && !slices.ContainsFunc(r.tools, func(pattern string) bool {
+		ok, _ := path.Match(pattern, tool)
+		return ok
+	}) {
+		return false
+	}
+	if len(r.methods) > 0 && !slices.ContainsFunc(r.methods, func(m string) bool {
+		return strings.EqualFold(m, method)
+	}) {
+		return false
+	}
+	return true
+}
+
+func (p *Policy) satisfies(authInfo *middleware.AuthInfo, s subject) bool {
+	switch s.kind {
+	case kindAny:
+		return true
+	case kindUser:
+		return authInfo.UserID == s.value
+	case kindEmail:
+		return authInfo.Email != "" && strings.EqualFold(authInfo.Email, s.value)
+	case kindEmailDomain:
+		_, domain, ok := strings.Cut(authInfo.Email, "@")
+		return ok && strings.EqualFold(domain, s.value)
+	case kindScope:
+		return slices.Contains(authInfo.Scopes, s.value)
+	case kindGroup:
+		return slices.Contains(claimValues(authInfo.Claims["groups"]), s.value)
+	case kindRole:
+		if slices.Contains(claimValues(authInfo.Claims["roles"]), s.value) {
+			return true
+		}
+		return slices.ContainsFunc(p.roles[s.value], func(member subject) bool {
+			return p.satisfies(authInfo, member)
+		})
+	case kindClaim:
+		return slices.Contains(claimValues(authInfo.Claims[s.name]), s.value)
+	}
+	return false
+}
+
+// claimValues flattens a string or list claim
+func claimValues(raw interface{}) []string {
+	switch v := raw.(type) {
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			values = append(values, fmt.Sprintf("%v", item))
+		}
+		return values
+	case nil:
+		return nil
+	default:
+		return []string{fmt.Sprintf("%v", v)}
+	}
+}
+
+func parseSubjects(entries []string) ([]subject, error) {
+	subjects := make([]subject, 0, len(entries))
+	for _, entry := range entries {
+		s, err := parseSubject(entry)
+		if err != nil {
+			return nil, err
+		}
+		subjects = append(subjects, s)
+	}
+	return subjects, nil
+}
+
+func parseSubject(entry string) (subject, error) {
+	if entry == kindAny {
+		return subject{kind: kindAny}, nil
+	}
+	kind, value, ok := strings.Cut(entry, ":")
+	if !ok || value == "" {
+		return subject{}, fmt.Errorf("invalid subject %q, expected <kind>:<value>", entry)
+	}
+	switch kind {
+	case kindUser, kindEmail, kindEmailDomain, kindScope, kindGroup, kindRole:
+		return subject{kind: kind, value: value}, nil
+	case kindClaim:
+		name, claimValue, ok := strings.Cut(value, "=")
+		if !ok || name == "" {
+			return subject{}, fmt.Errorf("invalid subject %q, expected claim:<name>=<value>", entry)
+		}
+		return subject{kind: kind, name: name, value: claimValue}, nil
+	default:
+		return subject{}, fmt.Errorf("unknown subject kind %q in %q", kind, entry)
+	}
+}
@@ -0,0 +1,117 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/brizzai/auto-mcp/internal/auth/middleware"
+	"github.com/brizzai/auto-mcp/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicy_Allowed(t *testing.T) {
+	p, err := New(config.PolicyConfig{
+		Roles: map[string][]string{
+			"admin": {"email:root@example.com", "group:ops"},
+		},
+		Rules: []config.PolicyRule{
+			{Methods: []string{"DELETE"}, Allow: []string{"role:admin"}},
+			{Tools: []string{"*_billing*"}, Allow: []string{"scope:billing", "claim:department=finance"}},
+			{Tools: []string{"get_*"}, Allow: []string{"*"}},
+			{Allow: []string{"email_domain:example.com"}},
+		},
+	})
+	require.NoError(t, err)
+
+	alice := &middleware.AuthInfo{UserID: "1", Email: "alice@example.com"}
+	root := &middleware.AuthInfo{UserID: "2", Email: "Root@Example.com"}
+	operator := &middleware.AuthInfo{UserID: "3", Email: "op@partner.io", Claims: map[string]interface{}{
+		"groups": []interface{}{"ops", "dev"},
+	}}
+	accountant := &middleware.AuthInfo{UserID: "4", Email: "acc@partner.io", Claims: map[string]interface{}{
+		"department": "finance",
+	}}
+	roleClaim := &middleware.AuthInfo{UserID: "5", Claims: map[string]interface{}{"roles": []string{"admin"}}}
+	scoped := &middleware.AuthInfo{UserID: "6", Scopes: []string{"billing"}}
+
+	tests := []struct {
+		name     string
+		user     *middleware.AuthInfo
+		tool     string
+		method   string
+		expected bool
+	}{
+		{"delete requires admin", alice, "delete_user", "DELETE", false},
+		{"admin by email", root, "delete_user", "DELETE", true},
+		{"admin by group", operator, "delete_user", "delete", true},
+		{"admin by roles claim", roleClaim, "delete_user", "DELETE", true},
+		{"billing by scope", scoped, "list_billing_accounts", "GET", true},
+		{"billing by claim", accountant, "update_billing", "POST", true},
+		{"billing denied", alice, "list_billing_accounts", "GET", false},
+		{"reads open to everyone", operator, "get_orders", "GET", true},
+		{"catch-all by email domain", alice, "create_order", "POST", true},
+		{"catch-all denied", operator, "create_order", "POST", false},
+		{"no user", nil, "get_orders", "GET", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, p.Allowed(tt.user, tt.tool, tt.method))
+		})
+	}
+}
+
+func TestPolicy_Default(t *testing.T) {
+	p, err := New(config.PolicyConfig{})
+	require.NoError(t, err)
+	assert.Nil(t, p, "an empty policy allows everything")
+	assert.True(t, p.Allowed(nil, "delete_user", "DELETE"))
+
+	p, err = New(config.PolicyConfig{
+		Default: "deny",
+		Rules:   []config.PolicyRule{{Tools: []string{"get_orders"}, Allow: []string{"*"}}},
+	})
+	require.NoError(t, err)
+	user := &middleware.AuthInfo{UserID: "1"}
+	assert.True(t, p.Allowed(user, "get_orders", "GET"))
+	assert.False(t, p.Allowed(user, "get_users", "GET"))
+}
+
+func TestNew_Invalid(t *testing.T) {
+	tests := map[string]config.PolicyConfig{
+		"default":       {Default: "maybe"},
+		"subject kind":  {Rules: []config.PolicyRule{{Allow: []string{"team:ops"}}}},
+		"subject value": {Rules: []config.PolicyRule{{Allow: []string{"role:"}}}},
+		"claim":         {Rules: []config.PolicyRule{{Allow: []string{"claim:department"}}}},
+		"tool pattern":  {Rules: []config.PolicyRule{{Tools: []string{"get_["}}}},
+		"nested role":   {Roles: map[string][]string{"admin": {"role:ops"}}},
+		"role subject":  {Roles: map[string][]string{"admin": {"admins"}}},
+	}
+	for name, cfg := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := New(cfg)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestTenants_Resolve(t *testing.T) {
+	tenants, err := NewTenants([]config.TenantConfig{
+		{Name: "acme", Match: []string{"email_domain:acme.com", "claim:org=acme"}},
+		{Name: "globex", Match: []string{"claim:hd=globex.io"}},
+	})
+	require.NoError(t, err)
+
+	name, ok := tenants.Resolve(&middleware.AuthInfo{Email: "jo@acme.com"})
+	assert.True(t, ok)
+	assert.Equal(t, "acme", name)
+
+	name, ok = tenants.Resolve(&middleware.AuthInfo{Claims: map[string]interface{}{"hd": "globex.io"}})
+	assert.True(t, ok)
+	assert.Equal(t, "globex", name)
+
+	_, ok = tenants.Resolve(&middleware.AuthInfo{Email: "jo@initech.com"})
+	assert.False(t, ok)
+
+	_, err = NewTenants([]config.TenantConfig{{Name: "acme", Match: []string{"domain:acme.com"}}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,55 @@
+package policy
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/brizzai/auto-mcp/internal/auth/middleware"
+	"github.com/brizzai/auto-mcp/internal/config"
+)
+
+type tenant struct {
+	name  string
+	match []subject
+}
+
+// Tenants picks the upstream tenant of an authenticated user. Tenants are
+// checked in order and the first one with a matching subject wins.
+type Tenants struct {
+	tenants []tenant
+	// matcher evaluates subjects; tenants have no roles of their own, so
+	// role:<name> only matches the user's roles claim
+	matcher *Policy
+}
+
+// NewTenants compiles the tenant match rules. It returns nil when no tenants are configured.
+func NewTenants(cfgs []config.TenantConfig) (*Tenants, error) {
+	if len(cfgs) == 0 {
+		return nil, nil
+	}
+	t := &Tenants{matcher: &Policy{}}
+	for _, cfg := range cfgs {
+		match, err := parseSubjects(cfg.Match)
+		if err != nil {
+			return nil, fmt.Errorf("tenant %q: %w", cfg.Name, err)
+		}
+		t.tenants = append(t.tenants, tenant{name: cfg.Name, match: match})
+	}
+	return t, nil
+}
+
+// Resolve returns the name of the user's tenant. A nil resolver, or a user
+// matching no tenant, resolves to nothing and uses the default endpoint.
+func (t *Tenants) Resolve(authInfo *middleware.AuthInfo) (string, bool) {
+	if t == nil || authInfo == nil {
+		return "", false
+	}
+	for _, tenant := range t.tenants {
+		if slices.ContainsFunc(tenant.match, func(s subject) bool {
+			return t.matcher.satisfies(authInfo, s)
+		}) {
+			return tenant.name, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,26 @@
+package providers
+
+import (
+	"sync/atomic"
+
+	"golang.org/x/oauth2"
+)
+
+// clientConfig holds a provider's OAuth client configuration. It is replaced
+// as a whole when the client secret rotates, so in-flight requests keep a
+// consistent copy.
+type clientConfig struct {
+	current atomic.Pointer[oauth2.Config]
+}
+
+// oauth2Config returns the current OAuth client configuration
+func (c *clientConfig) oauth2Config() *oauth2.Config {
+	return c.current.Load()
+}
+
+// SetClientSecret rotates the OAuth client secret
+func (c *clientConfig) SetClientSecret(secret string) {
+	updated := *c.current.Load()
+	updated.ClientSecret = secret
+	c.current.Store(&updated)
+}
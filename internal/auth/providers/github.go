@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/brizzai/auto-mcp/internal/auth/constants"
 	"github.com/brizzai/auto-mcp/internal/auth/models"
@@ -16,18 +17,18 @@ import (
 )
 
 type GitHubProvider struct {
-	oauth2Config *oauth2.Config
+	clientConfig
 }
 
 func NewGitHubProvider(cfg *config.OAuthConfig) *GitHubProvider {
-	return &GitHubProvider{
-		oauth2Config: &oauth2.Config{
-			ClientID:     cfg.ClientID,
-			ClientSecret: cfg.ClientSecret,
-			Endpoint:     github.Endpoint,
-			Scopes:       cfg.Scopes,
-		},
-	}
+	p := &GitHubProvider{}
+	p.current.Store(&oauth2.Config{
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		Endpoint:     github.Endpoint,
+		Scopes:       cfg.Scopes,
+	})
+	return p
 }
 
 func (p *GitHubProvider) GetAuthURL(state, codeChallenge, codeChallengeMethod, redirectURI string) string {
@@ -41,11 +42,11 @@ func (p *GitHubProvider) GetAuthURL(state, codeChallenge, codeChallengeMethod, r
 			oauth2.SetAuthURLParam("code_challenge_method", codeChallengeMethod),
 		)
 	}
-	return p.oauth2Config.AuthCodeURL(state, opts...)
+	return p.oauth2Config().AuthCodeURL(state, opts...)
 }
 
 func (p *GitHubProvider) ExchangeCode(ctx context.Context, code, codeVerifier, redirectURI string) (*oauth2.Token, error) {
-	cfg := *p.oauth2Config // copy
+	cfg := *p.oauth2Config() // copy
 	if redirectURI != "" {
 		cfg.RedirectURL = redirectURI
 	}
@@ -59,12 +60,12 @@ func (p *GitHubProvider) ExchangeCode(ctx context.Context, code, codeVerifier, r
 }
 
 func (p *GitHubProvider) ValidateToken(ctx context.Context, token *oauth2.Token) (*models.UserInfo, error) {
-	client := p.oauth2Config.Client(ctx, token)
+	client := p.oauth2Config().Client(ctx, token)
 	return p.getUserInfo(client)
 }
 
 func (p *GitHubProvider) RefreshToken(ctx context.Context, refreshToken string) (*oauth2.Token, error) {
-	return p.oauth2Config.TokenSource(ctx, &oauth2.Token{
+	return p.oauth2Config().TokenSource(ctx, &oauth2.Token{
 		RefreshToken: refreshToken,
 	}).Token()
 }
@@ -110,11 +111,20 @@ func (p *GitHubProvider) getUserInfo(client *http.Client) (*models.UserInfo, err
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
+	// GitHub reports the token's scopes in a response header
+	var scopes []string
+	for _, scope := range strings.Split(resp.Header.Get("X-OAuth-Scopes"), ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+
 	return &models.UserInfo{
 		ID:      fmt.Sprintf("%d", gh.ID),
 		Email:   gh.Email,
 		Name:    gh.Name,
 		Picture: gh.AvatarURL,
+		Scopes:  scopes,
 		Metadata: map[string]interface{}{
 			"login": gh.Login,
 		},
@@ -17,8 +17,8 @@ import (
 )
 
 type GoogleProvider struct {
-	oauth2Config *oauth2.Config
-	verifier     *oidc.IDTokenVerifier
+	clientConfig
+	verifier *oidc.IDTokenVerifier
 }
 
 func NewGoogleProvider(cfg *config.OAuthConfig) (*GoogleProvider, error) {
@@ -34,10 +34,11 @@ func NewGoogleProvider(cfg *config.OAuthConfig) (*GoogleProvider, error) {
 		Scopes:       cfg.Scopes,
 	}
 
-	return &GoogleProvider{
-		oauth2Config: oauth2Cfg,
-		verifier:     provider.Verifier(&oidc.Config{ClientID: cfg.ClientID}),
-	}, nil
+	p := &GoogleProvider{
+		verifier: provider.Verifier(&oidc.Config{ClientID: cfg.ClientID}),
+	}
+	p.current.Store(oauth2Cfg)
+	return p, nil
 }
 
 func (p *GoogleProvider) GetAuthURL(state, codeChallenge, codeChallengeMethod, redirectURI string) string {
@@ -51,11 +52,11 @@ func (p *GoogleProvider) GetAuthURL(state, codeChallenge, codeChallengeMethod, r
 			oauth2.SetAuthURLParam("code_challenge_method", codeChallengeMethod),
 		)
 	}
-	return p.oauth2Config.AuthCodeURL(state, opts...)
+	return p.oauth2Config().AuthCodeURL(state, opts...)
 }
 
 func (p *GoogleProvider) ExchangeCode(ctx context.Context, code, codeVerifier, redirectURI string) (*oauth2.Token, error) {
-	cfg := *p.oauth2Config // copy
+	cfg := *p.oauth2Config() // copy
 	if redirectURI != "" {
 		cfg.RedirectURL = redirectURI
 	}
@@ -98,7 +99,7 @@ func (p *GoogleProvider) ValidateToken(ctx context.Context, token *oauth2.Token)
 }
 
 func (p *GoogleProvider) RefreshToken(ctx context.Context, refreshToken string) (*oauth2.Token, error) {
-	return p.oauth2Config.TokenSource(ctx, &oauth2.Token{
+	return p.oauth2Config().TokenSource(ctx, &oauth2.Token{
 		RefreshToken: refreshToken,
 	}).Token()
 }
@@ -129,15 +130,27 @@ func (p *GoogleProvider) ValidateAccessToken(ctx context.Context, token string)
 		Email   string `json:"email"`
 		Name    string `json:"name"`
 		Picture string `json:"picture"`
+		// HostedDomain is the Google Workspace domain of the account
+		HostedDomain  string `json:"hd"`
+		EmailVerified *bool  `json:"email_verified"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&userInfo); err != nil {
 		return nil, fmt.Errorf("failed to decode userinfo response: %w", err)
 	}
 
+	metadata := map[string]interface{}{}
+	if userInfo.HostedDomain != "" {
+		metadata["hd"] = userInfo.HostedDomain
+	}
+	if userInfo.EmailVerified != nil {
+		metadata["email_verified"] = *userInfo.EmailVerified
+	}
+
 	return &models.UserInfo{
-		ID:      userInfo.Sub,
-		Email:   userInfo.Email,
-		Name:    userInfo.Name,
-		Picture: userInfo.Picture,
+		ID:       userInfo.Sub,
+		Email:    userInfo.Email,
+		Name:     userInfo.Name,
+		Picture:  userInfo.Picture,
+		Metadata: metadata,
 	}, nil
 }
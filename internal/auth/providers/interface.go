@@ -2,11 +2,18 @@ package providers
 
 import (
 	"context"
+	"errors"
+	"net/http"
 
 	"github.com/brizzai/auto-mcp/internal/auth/models"
+	"github.com/brizzai/auto-mcp/internal/auth/store"
 	"golang.org/x/oauth2"
 )
 
+// ErrUserNotAllowed is returned for valid tokens whose user is not allowed to
+// use the server
+var ErrUserNotAllowed = errors.New("user is not allowed to access this server")
+
 // OAuthProvider defines the interface that all OAuth providers must implement
 type OAuthProvider interface {
 	// GetAuthURL returns the authorization URL for the provider
@@ -24,3 +31,21 @@ type OAuthProvider interface {
 	// ValidateAccessToken validates a raw access token and returns user info
 	ValidateAccessToken(ctx context.Context, token string) (*models.UserInfo, error)
 }
+
+// ClientSecretSetter is implemented by providers that authenticate to the
+// identity provider with a client secret that can be rotated at runtime
+type ClientSecretSetter interface {
+	SetClientSecret(secret string)
+}
+
+// LoginProvider is implemented by providers that sign users in on the MCP
+// server itself instead of redirecting to an external identity provider
+type LoginProvider interface {
+	OAuthProvider
+
+	// UseStore shares the OAuth store, which holds pending authorizations
+	UseStore(st store.Store)
+
+	// RegisterRoutes registers the sign-in pages
+	RegisterRoutes(mux *http.ServeMux)
+}
@@ -0,0 +1,360 @@
+package providers
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/brizzai/auto-mcp/internal/auth/models"
+	"github.com/brizzai/auto-mcp/internal/auth/store"
+	"github.com/brizzai/auto-mcp/internal/config"
+	"github.com/brizzai/auto-mcp/internal/logger"
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// internalIssuer is the "iss" of tokens issued by the internal provider
+	internalIssuer = "auto-mcp"
+	// internalCodeKeyPrefix prefixes codes issued by the login page
+	internalCodeKeyPrefix = "internal-code:"
+	internalCodeTTL       = time.Minute
+
+	defaultInternalTokenTTL   = time.Hour
+	defaultInternalRefreshTTL = 30 * 24 * time.Hour
+	minSigningKeyLength       = 32
+
+	tokenUseAccess  = "access"
+	tokenUseRefresh = "refresh"
+)
+
+var errInvalidInternalToken = errors.New("invalid token")
+
+// InternalProvider signs users in against a local user list with its own
+// login page and issues HS256-signed JWTs, for deployments without an
+// external identity provider
+type InternalProvider struct {
+	users      map[string]config.InternalUser
+	key        []byte
+	signer     jose.Signer
+	tokenTTL   time.Duration
+	refreshTTL time.Duration
+	store      store.Store
+	now        func() time.Time
+	// dummyHash is checked for unknown users, so response times do not
+	// reveal which usernames exist
+	dummyHash []byte
+}
+
+// internalClaims are the claims added to the registered JWT claims
+type internalClaims struct {
+	TokenUse string `json:"token_use"`
+}
+
+// internalCode is an authorization code issued by the login page
+type internalCode struct {
+	Username      string `json:"username"`
+	CodeChallenge string `json:"code_challenge"`
+	RedirectURI   string `json:"redirect_uri"`
+}
+
+func NewInternalProvider(cfg *config.OAuthConfig) (*InternalProvider, error) {
+	users, err := loadInternalUsers(cfg.Internal)
+	if err != nil {
+		return nil, err
+	}
+
+	key := []byte(cfg.Internal.SigningKey)
+	if len(key) == 0 {
+		key = make([]byte, minSigningKeyLength)
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("failed to generate signing key: %w", err)
+		}
+		logger.Warn("oauth.internal.signing_key is not set, issued tokens will be invalid after a restart")
+	} else if len(key) < minSigningKeyLength {
+		return nil, fmt.Errorf("oauth.internal.signing_key must be at least %d bytes", minSigningKeyLength)
+	}
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.HS256, Key: key}, (&jose.SignerOptions{}).WithType("JWT"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token signer: %w", err)
+	}
+
+	// A random password nobody can send, well within bcrypt's 72-byte limit
+	dummyPassword := make([]byte, 32)
+	if _, err := rand.Read(dummyPassword); err != nil {
+		return nil, fmt.Errorf("failed to generate dummy password: %w", err)
+	}
+	dummyHash, err := bcrypt.GenerateFromPassword(dummyPassword, bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash dummy password: %w", err)
+	}
+
+	provider := &InternalProvider{
+		users:      users,
+		key:        key,
+		signer:     signer,
+		tokenTTL:   cfg.Internal.TokenTTL,
+		refreshTTL: cfg.Internal.RefreshTokenTTL,
+		store:      store.NewMemory(),
+		now:        time.Now,
+		dummyHash:  dummyHash,
+	}
+	if provider.tokenTTL <= 0 {
+		provider.tokenTTL = defaultInternalTokenTTL
+	}
+	if provider.refreshTTL <= 0 {
+		provider.refreshTTL = defaultInternalRefreshTTL
+	}
+	return provider, nil
+}
+
+// loadInternalUsers merges the configured users with the htpasswd file
+func loadInternalUsers(cfg config.InternalAuthConfig) (map[string]config.InternalUser, error) {
+	users := make(map[string]config.InternalUser)
+	add := func(user config.InternalUser) error {
+		if user.Username == "" {
+			return fmt.Errorf("oauth.internal: user without username")
+		}
+		if _, ok := users[user.Username]; ok {
+			return fmt.Errorf("oauth.internal: duplicate user %q", user.Username)
+		}
+		if _, err := bcrypt.Cost([]byte(user.PasswordHash)); err != nil {
+			return fmt.Errorf("oauth.internal: user %q: password_hash must be a bcrypt hash: %w", user.Username, err)
+		}
+		users[user.Username] = user
+		return nil
+	}
+
+	for _, user := range cfg.Users {
+		if err := add(user); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.HtpasswdFile != "" {
+		fileUsers, err := readHtpasswd(cfg.HtpasswdFile)
+		if err != nil {
+			return nil, err
+		}
+		for _, user := range fileUsers {
+			if err := add(user); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(users) == 0 {
+		return nil, fmt.Errorf("oauth.internal: at least one user is required")
+	}
+	return users, nil
+}
+
+// readHtpasswd reads "user:hash" lines; only bcrypt hashes are supported
+func readHtpasswd(path string) ([]config.InternalUser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open htpasswd file: %w", err)
+	}
+	defer f.Close()
+
+	var users []config.InternalUser
+	scanner := bufio.NewScanner(f)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		username, hash, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: expected user:hash", path, lineNumber)
+		}
+		users = append(users, config.InternalUser{Username: username, PasswordHash: hash})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read htpasswd file: %w", err)
+	}
+	return users, nil
+}
+
+// UseStore keeps issued codes in the shared OAuth store and lets the login
+// page look up pending authorizations
+func (p *InternalProvider) UseStore(st store.Store) {
+	p.store = st
+}
+
+// GetAuthURL returns the login page, which lives next to the callback URL.
+// Only the state is passed; the login page reads the PKCE challenge and
+// callback URL from the pending authorization it refers to.
+func (p *InternalProvider) GetAuthURL(state, codeChallenge, codeChallengeMethod, redirectURI string) string {
+	login := &url.URL{Path: "login"}
+	if base, err := url.Parse(redirectURI); err == nil && redirectURI != "" {
+		login = base.ResolveReference(login)
+	}
+	login.RawQuery = url.Values{"state": {state}}.Encode()
+	return login.String()
+}
+
+func (p *InternalProvider) ExchangeCode(ctx context.Context, code, codeVerifier, redirectURI string) (*oauth2.Token, error) {
+	data, err := p.store.Take(ctx, internalCodeKeyPrefix+code)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, fmt.Errorf("authorization code is invalid or expired")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load authorization code: %w", err)
+	}
+	var issued internalCode
+	if err := json.Unmarshal(data, &issued); err != nil {
+		return nil, fmt.Errorf("failed to decode authorization code: %w", err)
+	}
+
+	if issued.RedirectURI != redirectURI {
+		return nil, fmt.Errorf("redirect_uri does not match the authorization request")
+	}
+	challenge := oauth2.S256ChallengeFromVerifier(codeVerifier)
+	if subtle.ConstantTimeCompare([]byte(challenge), []byte(issued.CodeChallenge)) != 1 {
+		return nil, fmt.Errorf("PKCE verification failed")
+	}
+	user, ok := p.users[issued.Username]
+	if !ok {
+		return nil, fmt.Errorf("user %q no longer exists", issued.Username)
+	}
+	return p.issueTokens(user)
+}
+
+func (p *InternalProvider) ValidateToken(ctx context.Context, token *oauth2.Token) (*models.UserInfo, error) {
+	return p.ValidateAccessToken(ctx, token.AccessToken)
+}
+
+func (p *InternalProvider) RefreshToken(ctx context.Context, refreshToken string) (*oauth2.Token, error) {
+	user, err := p.parseToken(refreshToken, tokenUseRefresh)
+	if err != nil {
+		return nil, err
+	}
+	return p.issueTokens(user)
+}
+
+func (p *InternalProvider) ValidateAccessToken(ctx context.Context, token string) (*models.UserInfo, error) {
+	user, err := p.parseToken(token, tokenUseAccess)
+	if err != nil {
+		return nil, err
+	}
+
+	name := user.Name
+	if name == "" {
+		name = user.Username
+	}
+	metadata := map[string]interface{}{"username": user.Username}
+	if len(user.Groups) > 0 {
+		metadata["groups"] = user.Groups
+	}
+	return &models.UserInfo{
+		ID:       user.Username,
+		Email:    user.Email,
+		Name:     name,
+		Metadata: metadata,
+	}, nil
+}
+
+// authenticate checks a username and password against the user list
+func (p *InternalProvider) authenticate(username, password string) (config.InternalUser, bool) {
+	user, ok := p.users[username]
+	hash := p.dummyHash
+	if ok {
+		hash = []byte(user.PasswordHash)
+	}
+	if bcrypt.CompareHashAndPassword(hash, []byte(password)) != nil || !ok {
+		return config.InternalUser{}, false
+	}
+	return user, true
+}
+
+// issueCode stores a code for user that ExchangeCode redeems once
+func (p *InternalProvider) issueCode(ctx context.Context, username, codeChallenge, redirectURI string) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	code := hex.EncodeToString(b)
+
+	data, err := json.Marshal(internalCode{Username: username, CodeChallenge: codeChallenge, RedirectURI: redirectURI})
+	if err != nil {
+		return "", err
+	}
+	if err := p.store.Set(ctx, internalCodeKeyPrefix+code, data, internalCodeTTL); err != nil {
+		return "", err
+	}
+	return code, nil
+}
+
+func (p *InternalProvider) issueTokens(user config.InternalUser) (*oauth2.Token, error) {
+	now := p.now()
+	accessToken, err := p.signToken(user.Username, tokenUseAccess, now, p.tokenTTL)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := p.signToken(user.Username, tokenUseRefresh, now, p.refreshTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &oauth2.Token{
+		AccessToken:  accessToken,
+		TokenType:    "Bearer",
+		RefreshToken: refreshToken,
+		Expiry:       now.Add(p.tokenTTL),
+		ExpiresIn:    int64(p.tokenTTL.Seconds()),
+	}, nil
+}
+
+func (p *InternalProvider) signToken(subject, use string, now time.Time, ttl time.Duration) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	claims := jwt.Claims{
+		Issuer:   internalIssuer,
+		Subject:  subject,
+		IssuedAt: jwt.NewNumericDate(now),
+		Expiry:   jwt.NewNumericDate(now.Add(ttl)),
+		ID:       hex.EncodeToString(b),
+	}
+	token, err := jwt.Signed(p.signer).Claims(claims).Claims(internalClaims{TokenUse: use}).Serialize()
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+	return token, nil
+}
+
+// parseToken verifies a token issued by this provider for use and returns
+// its user, who must still be configured
+func (p *InternalProvider) parseToken(token, use string) (config.InternalUser, error) {
+	parsed, err := jwt.ParseSigned(token, []jose.SignatureAlgorithm{jose.HS256})
+	if err != nil {
+		return config.InternalUser{}, errInvalidInternalToken
+	}
+	var claims jwt.Claims
+	var extra internalClaims
+	if err := parsed.Claims(p.key, &claims, &extra); err != nil {
+		return config.InternalUser{}, errInvalidInternalToken
+	}
+	if err := claims.ValidateWithLeeway(jwt.Expected{Issuer: internalIssuer, Time: p.now()}, 0); err != nil {
+		return config.InternalUser{}, fmt.Errorf("%w: %v", errInvalidInternalToken, err)
+	}
+	if extra.TokenUse != use {
+		return config.InternalUser{}, fmt.Errorf("%w: expected a %s token", errInvalidInternalToken, use)
+	}
+	user, ok := p.users[claims.Subject]
+	if !ok {
+		return config.InternalUser{}, fmt.Errorf("%w: unknown user", errInvalidInternalToken)
+	}
+	return user, nil
+}
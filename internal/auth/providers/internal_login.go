@@ -0,0 +1,156 @@
+package providers
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+	"net/http"
+	"net/url"
+
+	"github.com/brizzai/auto-mcp/internal/auth/store"
+	"github.com/brizzai/auto-mcp/internal/logger"
+	"go.uber.org/zap"
+	"golang.org/x/oauth2"
+)
+
+// loginPath serves the internal provider's login page, next to /oauth/callback
+const loginPath = "/oauth/login"
+
+var loginTemplate = template.Must(template.New("login").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Sign in - auto-mcp</title>
+<style>
+body { font-family: system-ui, sans-serif; background: #f4f4f5; display: flex; justify-content: center; padding-top: 10vh; margin: 0; }
+main { background: #fff; border-radius: 8px; box-shadow: 0 1px 4px rgba(0,0,0,.15); padding: 2rem; width: 22rem; }
+h1 { font-size: 1.25rem; margin-top: 0; }
+label { display: block; margin-top: 1rem; font-size: .9rem; }
+input { box-sizing: border-box; width: 100%; padding: .5rem; margin-top: .25rem; }
+button { margin-top: 1.5rem; width: 100%; padding: .6rem; background: #18181b; color: #fff; border: 0; border-radius: 4px; cursor: pointer; }
+.error { color: #b91c1c; }
+.consent { color: #52525b; font-size: .9rem; }
+</style>
+</head>
+<body>
+<main>
+<h1>Sign in to auto-mcp</h1>
+{{if .Error}}<p class="error">{{.Error}}</p>{{end}}
+{{if .State}}
+<p class="consent"><strong>{{.ClientName}}</strong> is requesting access to the MCP server on your behalf. You will be returned to <strong>{{.RedirectHost}}</strong>.</p>
+<form method="post">
+<input type="hidden" name="state" value="{{.State}}">
+<label>Username <input name="username" value="{{.Username}}" autocomplete="username" required autofocus></label>
+<label>Password <input name="password" type="password" autocomplete="current-password" required></label>
+<button type="submit">Sign in and allow access</button>
+</form>
+{{end}}
+</main>
+</body>
+</html>
+`))
+
+// loginPage is the data rendered by loginTemplate; the form is only shown
+// for a pending authorization
+type loginPage struct {
+	State        string
+	ClientName   string
+	RedirectHost string
+	Username     string
+	Error        string
+}
+
+// RegisterRoutes registers the login page
+func (p *InternalProvider) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc(loginPath, p.handleLogin)
+}
+
+// handleLogin shows the login and consent form for a pending authorization
+// and, once the user signs in, sends a code to the server's callback
+func (p *InternalProvider) handleLogin(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Frame-Options", "DENY")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; form-action 'self'")
+
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form", http.StatusBadRequest)
+		return
+	}
+
+	state := r.FormValue("state")
+	authz, err := store.GetAuthorization(r.Context(), p.store, state)
+	if errors.Is(err, store.ErrNotFound) {
+		renderLogin(w, http.StatusBadRequest, loginPage{Error: "This sign-in link is invalid or has expired. Start again from your MCP client."})
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to load authorization", zap.Error(err))
+		http.Error(w, "Failed to load authorization", http.StatusInternalServerError)
+		return
+	}
+
+	page := loginPage{State: state, ClientName: authz.ClientID, RedirectHost: redirectHost(authz.RedirectURI)}
+	if client, err := store.GetClient(r.Context(), p.store, authz.ClientID); err == nil {
+		page.ClientName = client.Name
+	}
+	if r.Method == http.MethodGet {
+		renderLogin(w, http.StatusOK, page)
+		return
+	}
+
+	username := r.PostFormValue("username")
+	user, ok := p.authenticate(username, r.PostFormValue("password"))
+	if !ok {
+		logger.Warn("Failed sign-in", zap.String("username", username))
+		page.Username = username
+		page.Error = "Invalid username or password."
+		renderLogin(w, http.StatusUnauthorized, page)
+		return
+	}
+
+	// The server's own PKCE challenge binds the code to the pending authorization
+	code, err := p.issueCode(r.Context(), user.Username, oauth2.S256ChallengeFromVerifier(authz.CodeVerifier), authz.CallbackURL)
+	if err != nil {
+		logger.Error("Failed to issue authorization code", zap.Error(err))
+		http.Error(w, "Failed to issue authorization code", http.StatusInternalServerError)
+		return
+	}
+	logger.Info("User signed in", zap.String("username", user.Username), zap.String("client_id", authz.ClientID))
+
+	callback, err := url.Parse(authz.CallbackURL)
+	if err != nil {
+		http.Error(w, "Invalid callback URL", http.StatusInternalServerError)
+		return
+	}
+	callback.RawQuery = url.Values{"code": {code}, "state": {state}}.Encode()
+	http.Redirect(w, r, callback.String(), http.StatusFound)
+}
+
+func renderLogin(w http.ResponseWriter, status int, page loginPage) {
+	var buf bytes.Buffer
+	if err := loginTemplate.Execute(&buf, page); err != nil {
+		logger.Error("Failed to render login page", zap.Error(err))
+		http.Error(w, "Failed to render login page", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	_, _ = w.Write(buf.Bytes())
+}
+
+// redirectHost describes where the client will be sent, for the consent text
+func redirectHost(redirectURI string) string {
+	u, err := url.Parse(redirectURI)
+	if err != nil {
+		return redirectURI
+	}
+	if u.Host == "" {
+		return u.Scheme + ":"
+	}
+	return u.Host
+}
@@ -0,0 +1,171 @@
+package providers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brizzai/auto-mcp/internal/auth/store"
+	"github.com/brizzai/auto-mcp/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/oauth2"
+)
+
+const testSigningKey = "0123456789abcdef0123456789abcdef"
+
+func newTestInternalProvider(t *testing.T) (*InternalProvider, store.Store) {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte("s3cret"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	provider, err := NewInternalProvider(&config.OAuthConfig{Internal: config.InternalAuthConfig{
+		Users:      []config.InternalUser{{Username: "alice", PasswordHash: string(hash), Email: "alice@example.com", Groups: []string{"ops"}}},
+		SigningKey: testSigningKey,
+	}})
+	require.NoError(t, err)
+	st := store.NewMemory()
+	provider.UseStore(st)
+	return provider, st
+}
+
+func login(provider *InternalProvider, form url.Values) *httptest.ResponseRecorder {
+	mux := http.NewServeMux()
+	provider.RegisterRoutes(mux)
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/oauth/login", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	mux.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestInternalProvider_Login(t *testing.T) {
+	ctx := context.Background()
+	provider, st := newTestInternalProvider(t)
+	require.NoError(t, store.SaveClient(ctx, st, &store.Client{ID: "client-1", Name: "Inspector"}))
+
+	verifier := oauth2.GenerateVerifier()
+	callbackURL := "https://mcp.example.com/oauth/callback"
+	authz := &store.Authorization{ClientID: "client-1", RedirectURI: "http://localhost:6274/cb", CallbackURL: callbackURL, CodeVerifier: verifier}
+	require.NoError(t, store.SaveAuthorization(ctx, st, "state-1", authz, time.Minute))
+
+	authURL := provider.GetAuthURL("state-1", "challenge", "S256", callbackURL)
+	assert.Equal(t, "https://mcp.example.com/oauth/login?state=state-1", authURL)
+
+	mux := http.NewServeMux()
+	provider.RegisterRoutes(mux)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/oauth/login?state=state-1", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "<strong>Inspector</strong>")
+	assert.Contains(t, rec.Body.String(), "localhost:6274")
+	assert.Equal(t, "DENY", rec.Header().Get("X-Frame-Options"))
+
+	rec = login(provider, url.Values{"state": {"state-1"}, "username": {"alice"}, "password": {"wrong"}})
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Invalid username or password")
+	rec = login(provider, url.Values{"state": {"state-1"}, "username": {"mallory"}, "password": {"s3cret"}})
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	rec = login(provider, url.Values{"state": {"unknown"}, "username": {"alice"}, "password": {"s3cret"}})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = login(provider, url.Values{"state": {"state-1"}, "username": {"alice"}, "password": {"s3cret"}})
+	require.Equal(t, http.StatusFound, rec.Code)
+	location, err := url.Parse(rec.Header().Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, "mcp.example.com", location.Host)
+	assert.Equal(t, "/oauth/callback", location.Path)
+	assert.Equal(t, "state-1", location.Query().Get("state"))
+	code := location.Query().Get("code")
+
+	_, err = provider.ExchangeCode(ctx, code, oauth2.GenerateVerifier(), callbackURL)
+	assert.ErrorContains(t, err, "PKCE")
+	// Codes are single use
+	_, err = provider.ExchangeCode(ctx, code, verifier, callbackURL)
+	assert.Error(t, err)
+}
+
+func TestInternalProvider_Tokens(t *testing.T) {
+	ctx := context.Background()
+	provider, _ := newTestInternalProvider(t)
+	verifier := oauth2.GenerateVerifier()
+	code, err := provider.issueCode(ctx, "alice", oauth2.S256ChallengeFromVerifier(verifier), "https://mcp.example.com/oauth/callback")
+	require.NoError(t, err)
+
+	_, err = provider.ExchangeCode(ctx, code, verifier, "https://evil.example.com/oauth/callback")
+	assert.Error(t, err)
+
+	code, err = provider.issueCode(ctx, "alice", oauth2.S256ChallengeFromVerifier(verifier), "https://mcp.example.com/oauth/callback")
+	require.NoError(t, err)
+	token, err := provider.ExchangeCode(ctx, code, verifier, "https://mcp.example.com/oauth/callback")
+	require.NoError(t, err)
+	assert.Equal(t, int64(3600), token.ExpiresIn)
+
+	user, err := provider.ValidateAccessToken(ctx, token.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", user.ID)
+	assert.Equal(t, "alice@example.com", user.Email)
+	assert.Equal(t, []string{"ops"}, user.Metadata["groups"])
+
+	// Refresh tokens are not access tokens and vice versa
+	_, err = provider.ValidateAccessToken(ctx, token.RefreshToken)
+	assert.Error(t, err)
+	_, err = provider.RefreshToken(ctx, token.AccessToken)
+	assert.Error(t, err)
+	refreshed, err := provider.RefreshToken(ctx, token.RefreshToken)
+	require.NoError(t, err)
+	assert.NotEqual(t, token.AccessToken, refreshed.AccessToken)
+
+	provider.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	_, err = provider.ValidateAccessToken(ctx, token.AccessToken)
+	assert.Error(t, err)
+
+	// Tokens signed with another key are rejected
+	other, _ := newTestInternalProvider(t)
+	other.key = []byte("another-key-another-key-another-key")
+	_, err = other.ValidateAccessToken(ctx, "not-a-jwt")
+	assert.Error(t, err)
+	_, err = other.ValidateAccessToken(ctx, refreshed.AccessToken)
+	assert.Error(t, err)
+}
+
+func TestNewInternalProvider_Htpasswd(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("pw"), bcrypt.MinCost)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "htpasswd")
+	require.NoError(t, os.WriteFile(path, []byte("# users\nbob:"+string(hash)+"\n"), 0o600))
+
+	provider, err := NewInternalProvider(&config.OAuthConfig{Internal: config.InternalAuthConfig{HtpasswdFile: path}})
+	require.NoError(t, err)
+	_, ok := provider.authenticate("bob", "pw")
+	assert.True(t, ok)
+
+	require.NoError(t, os.WriteFile(path, []byte("bob:{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g=\n"), 0o600))
+	_, err = NewInternalProvider(&config.OAuthConfig{Internal: config.InternalAuthConfig{HtpasswdFile: path}})
+	assert.ErrorContains(t, err, "bcrypt")
+
+	_, err = NewInternalProvider(&config.OAuthConfig{})
+	assert.ErrorContains(t, err, "at least one user")
+
+	_, err = NewInternalProvider(&config.OAuthConfig{Internal: config.InternalAuthConfig{
+		Users:      []config.InternalUser{{Username: "bob", PasswordHash: string(hash)}},
+		SigningKey: "short",
+	}})
+	assert.ErrorContains(t, err, "signing_key")
+
+	// Keys longer than bcrypt's 72-byte limit are fine
+	provider, err = NewInternalProvider(&config.OAuthConfig{Internal: config.InternalAuthConfig{
+		Users:      []config.InternalUser{{Username: "bob", PasswordHash: string(hash)}},
+		SigningKey: strings.Repeat(testSigningKey, 4),
+	}})
+	require.NoError(t, err)
+	_, ok = provider.authenticate("nobody", strings.Repeat(testSigningKey, 4))
+	assert.False(t, ok)
+}
@@ -0,0 +1,211 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/brizzai/auto-mcp/internal/auth/models"
+	"github.com/brizzai/auto-mcp/internal/config"
+	"github.com/brizzai/auto-mcp/internal/logger"
+	"github.com/coreos/go-oidc/v3/oidc"
+	"go.uber.org/zap"
+	"golang.org/x/oauth2"
+)
+
+// OIDCProvider works with any OpenID Connect identity provider (Keycloak,
+// Dex, Okta, Entra ID, ...), discovering its endpoints and signing keys from
+// the issuer URL
+type OIDCProvider struct {
+	clientConfig
+	provider *oidc.Provider
+	// idVerifier checks ID tokens issued to our client
+	idVerifier *oidc.IDTokenVerifier
+	// accessVerifier checks JWT access tokens
+	accessVerifier *oidc.IDTokenVerifier
+	// requireJWT rejects access tokens that are not JWTs for the configured audience
+	requireJWT bool
+	// skipAudienceCheck accepts JWTs for any audience and checks JWTs that
+	// cannot be verified with userinfo
+	skipAudienceCheck bool
+}
+
+func NewOIDCProvider(ctx context.Context, cfg *config.OAuthConfig) (*OIDCProvider, error) {
+	if cfg.IssuerURL == "" {
+		return nil, fmt.Errorf("oauth.issuer_url is required for the oidc provider")
+	}
+	provider, err := oidc.NewProvider(ctx, cfg.IssuerURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover OIDC issuer %s: %w", cfg.IssuerURL, err)
+	}
+
+	scopes := cfg.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{oidc.ScopeOpenID, "profile", "email"}
+	}
+
+	// Without an audience, access tokens must have been issued to this client
+	audience := cfg.Audience
+	if audience == "" {
+		audience = cfg.ClientID
+	}
+	p := &OIDCProvider{
+		provider:   provider,
+		idVerifier: provider.Verifier(&oidc.Config{ClientID: cfg.ClientID}),
+		accessVerifier: provider.Verifier(&oidc.Config{
+			ClientID:          audience,
+			SkipClientIDCheck: cfg.SkipAudienceCheck,
+		}),
+		requireJWT:        cfg.Audience != "",
+		skipAudienceCheck: cfg.SkipAudienceCheck,
+	}
+	p.current.Store(&oauth2.Config{
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       scopes,
+	})
+	return p, nil
+}
+
+func (p *OIDCProvider) GetAuthURL(state, codeChallenge, codeChallengeMethod, redirectURI string) string {
+	opts := []oauth2.AuthCodeOption{}
+	if redirectURI != "" {
+		opts = append(opts, oauth2.SetAuthURLParam("redirect_uri", redirectURI))
+	}
+	if codeChallenge != "" {
+		opts = append(opts,
+			oauth2.SetAuthURLParam("code_challenge", codeChallenge),
+			oauth2.SetAuthURLParam("code_challenge_method", codeChallengeMethod),
+		)
+	}
+	return p.oauth2Config().AuthCodeURL(state, opts...)
+}
+
+func (p *OIDCProvider) ExchangeCode(ctx context.Context, code, codeVerifier, redirectURI string) (*oauth2.Token, error) {
+	cfg := *p.oauth2Config() // copy
+	if redirectURI != "" {
+		cfg.RedirectURL = redirectURI
+	}
+
+	opts := []oauth2.AuthCodeOption{}
+	if codeVerifier != "" {
+		opts = append(opts, oauth2.SetAuthURLParam("code_verifier", codeVerifier))
+	}
+
+	return cfg.Exchange(ctx, code, opts...)
+}
+
+func (p *OIDCProvider) ValidateToken(ctx context.Context, token *oauth2.Token) (*models.UserInfo, error) {
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, fmt.Errorf("no id_token in token response")
+	}
+
+	idToken, err := p.idVerifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify ID token: %w", err)
+	}
+	var claims map[string]interface{}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("failed to parse claims: %w", err)
+	}
+	return userInfoFromClaims(claims), nil
+}
+
+func (p *OIDCProvider) RefreshToken(ctx context.Context, refreshToken string) (*oauth2.Token, error) {
+	return p.oauth2Config().TokenSource(ctx, &oauth2.Token{
+		RefreshToken: refreshToken,
+	}).Token()
+}
+
+// ValidateAccessToken verifies JWT access tokens against the issuer's keys
+// and the audience. Opaque tokens, unless an audience is configured, and JWTs
+// that cannot be verified when the audience check is skipped, are checked
+// with the userinfo endpoint instead.
+func (p *OIDCProvider) ValidateAccessToken(ctx context.Context, token string) (*models.UserInfo, error) {
+	if isJWT(token) {
+		accessToken, err := p.accessVerifier.Verify(ctx, token)
+		if err == nil {
+			var claims map[string]interface{}
+			if err := accessToken.Claims(&claims); err != nil {
+				return nil, fmt.Errorf("failed to parse claims: %w", err)
+			}
+			return userInfoFromClaims(claims), nil
+		}
+		// userinfo would accept tokens the issuer signed for other clients
+		if !p.skipAudienceCheck {
+			return nil, fmt.Errorf("failed to verify access token: %w", err)
+		}
+		logger.Debug("Access token is not a verifiable JWT, using userinfo", zap.Error(err))
+	} else if p.requireJWT {
+		return nil, fmt.Errorf("access token is not a JWT")
+	}
+
+	userInfo, err := p.provider.UserInfo(ctx, oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to call userinfo endpoint: %w", err)
+	}
+	var claims map[string]interface{}
+	if err := userInfo.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("failed to decode userinfo response: %w", err)
+	}
+	return userInfoFromClaims(claims), nil
+}
+
+// isJWT reports whether token looks like a compact JWS
+func isJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
+
+// userInfoFromClaims maps standard OIDC claims onto UserInfo. Every claim is
+// kept in Metadata so policies and defaults can refer to it; Keycloak's
+// realm_access.roles is exposed as roles.
+func userInfoFromClaims(claims map[string]interface{}) *models.UserInfo {
+	str := func(name string) string {
+		value, _ := claims[name].(string)
+		return value
+	}
+
+	name := str("name")
+	if name == "" {
+		name = str("preferred_username")
+	}
+
+	if _, ok := claims["roles"]; !ok {
+		if realmAccess, ok := claims["realm_access"].(map[string]interface{}); ok {
+			if roles, ok := realmAccess["roles"]; ok {
+				claims["roles"] = roles
+			}
+		}
+	}
+
+	return &models.UserInfo{
+		ID:       str("sub"),
+		Email:    str("email"),
+		Name:     name,
+		Picture:  str("picture"),
+		Scopes:   tokenScopes(claims),
+		Metadata: claims,
+	}
+}
+
+// tokenScopes reads the space separated "scope" claim or the "scp" list used by some issuers
+func tokenScopes(claims map[string]interface{}) []string {
+	if scope, ok := claims["scope"].(string); ok {
+		return strings.Fields(scope)
+	}
+	switch scp := claims["scp"].(type) {
+	case string:
+		return strings.Fields(scp)
+	case []interface{}:
+		scopes := make([]string, 0, len(scp))
+		for _, s := range scp {
+			if s, ok := s.(string); ok {
+				scopes = append(scopes, s)
+			}
+		}
+		return scopes
+	}
+	return nil
+}
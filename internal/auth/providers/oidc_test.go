@@ -0,0 +1,164 @@
+package providers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/brizzai/auto-mcp/internal/config"
+	"github.com/go-jose/go-jose/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeIssuer serves OIDC discovery, JWKS and userinfo, and signs tokens
+type fakeIssuer struct {
+	*httptest.Server
+	signer jose.Signer
+}
+
+func newFakeIssuer(t *testing.T) *fakeIssuer {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: key},
+		(&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", "test"))
+	require.NoError(t, err)
+
+	issuer := &fakeIssuer{signer: signer}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"issuer":                                issuer.URL,
+			"authorization_endpoint":                issuer.URL + "/authorize",
+			"token_endpoint":                        issuer.URL + "/token",
+			"userinfo_endpoint":                     issuer.URL + "/userinfo",
+			"jwks_uri":                              issuer.URL + "/jwks",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+			{Key: &key.PublicKey, KeyID: "test", Algorithm: "RS256", Use: "sig"},
+		}})
+	})
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer opaque-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"sub": "user-2", "email": "bob@example.com", "preferred_username": "bob",
+		})
+	})
+	issuer.Server = httptest.NewServer(mux)
+	t.Cleanup(issuer.Close)
+	return issuer
+}
+
+func (i *fakeIssuer) token(t *testing.T, claims map[string]interface{}) string {
+	t.Helper()
+	payload := map[string]interface{}{
+		"iss": i.URL,
+		"iat": time.Now().Unix(),
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+	for k, v := range claims {
+		payload[k] = v
+	}
+	data, err := json.Marshal(payload)
+	require.NoError(t, err)
+	signed, err := i.signer.Sign(data)
+	require.NoError(t, err)
+	token, err := signed.CompactSerialize()
+	require.NoError(t, err)
+	return token
+}
+
+func TestOIDCProvider_ValidateAccessToken(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	ctx := context.Background()
+
+	p, err := NewOIDCProvider(ctx, &config.OAuthConfig{Provider: "oidc", IssuerURL: issuer.URL, ClientID: "auto-mcp"})
+	require.NoError(t, err)
+	assert.Equal(t, issuer.URL+"/authorize?client_id=auto-mcp&response_type=code&scope=openid+profile+email&state=xyz",
+		p.GetAuthURL("xyz", "", "", ""))
+
+	token := issuer.token(t, map[string]interface{}{
+		"sub":          "user-1",
+		"email":        "alice@example.com",
+		"name":         "Alice",
+		"aud":          "auto-mcp",
+		"scope":        "openid orders:write",
+		"groups":       []string{"ops"},
+		"realm_access": map[string]interface{}{"roles": []string{"admin"}},
+	})
+	userInfo, err := p.ValidateAccessToken(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", userInfo.ID)
+	assert.Equal(t, "alice@example.com", userInfo.Email)
+	assert.Equal(t, "Alice", userInfo.Name)
+	assert.Equal(t, []string{"openid", "orders:write"}, userInfo.Scopes)
+	assert.Equal(t, []interface{}{"ops"}, userInfo.Metadata["groups"])
+	assert.Equal(t, []interface{}{"admin"}, userInfo.Metadata["roles"])
+
+	// Opaque tokens are checked with the userinfo endpoint
+	userInfo, err = p.ValidateAccessToken(ctx, "opaque-token")
+	require.NoError(t, err)
+	assert.Equal(t, "user-2", userInfo.ID)
+	assert.Equal(t, "bob", userInfo.Name)
+
+	_, err = p.ValidateAccessToken(ctx, "unknown-token")
+	assert.Error(t, err)
+}
+
+func TestOIDCProvider_ClientIDAudience(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	ctx := context.Background()
+	otherClient := issuer.token(t, map[string]interface{}{"sub": "user-1", "aud": "other-client"})
+
+	// Without an audience, JWTs must have been issued to the client ID
+	p, err := NewOIDCProvider(ctx, &config.OAuthConfig{IssuerURL: issuer.URL, ClientID: "auto-mcp"})
+	require.NoError(t, err)
+	_, err = p.ValidateAccessToken(ctx, issuer.token(t, map[string]interface{}{"sub": "user-1", "aud": "auto-mcp"}))
+	assert.NoError(t, err)
+	_, err = p.ValidateAccessToken(ctx, otherClient)
+	assert.Error(t, err, "tokens issued to other clients are rejected")
+
+	p, err = NewOIDCProvider(ctx, &config.OAuthConfig{IssuerURL: issuer.URL, ClientID: "auto-mcp", SkipAudienceCheck: true})
+	require.NoError(t, err)
+	_, err = p.ValidateAccessToken(ctx, otherClient)
+	assert.NoError(t, err, "the audience check can be turned off")
+}
+
+func TestOIDCProvider_Audience(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	ctx := context.Background()
+
+	p, err := NewOIDCProvider(ctx, &config.OAuthConfig{IssuerURL: issuer.URL, ClientID: "auto-mcp", Audience: "https://mcp.example.com"})
+	require.NoError(t, err)
+
+	_, err = p.ValidateAccessToken(ctx, issuer.token(t, map[string]interface{}{"sub": "user-1", "aud": "https://mcp.example.com"}))
+	assert.NoError(t, err)
+
+	_, err = p.ValidateAccessToken(ctx, issuer.token(t, map[string]interface{}{"sub": "user-1", "aud": "https://other.example.com"}))
+	assert.Error(t, err, "tokens for another audience are rejected")
+
+	_, err = p.ValidateAccessToken(ctx, issuer.token(t, map[string]interface{}{
+		"sub": "user-1", "aud": "https://mcp.example.com", "exp": time.Now().Add(-time.Minute).Unix(),
+	}))
+	assert.Error(t, err, "expired tokens are rejected")
+
+	_, err = p.ValidateAccessToken(ctx, "opaque-token")
+	assert.Error(t, err, "opaque tokens cannot prove their audience")
+}
+
+func TestNewOIDCProvider_RequiresIssuer(t *testing.T) {
+	_, err := NewOIDCProvider(context.Background(), &config.OAuthConfig{Provider: "oidc"})
+	assert.Error(t, err)
+}
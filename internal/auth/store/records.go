@@ -0,0 +1,154 @@
+package store
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Key prefixes of the records kept in the store
+const (
+	clientKeyPrefix        = "client:"
+	authorizationKeyPrefix = "authorization:"
+	codeKeyPrefix          = "code:"
+	refreshTokenKeyPrefix  = "refresh_token:"
+)
+
+// Client is a dynamically registered OAuth client
+type Client struct {
+	ID           string    `json:"client_id"`
+	Name         string    `json:"client_name"`
+	RedirectURIs []string  `json:"redirect_uris"`
+	GrantTypes   []string  `json:"grant_types,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// SaveClient stores a registered client; registrations do not expire
+func SaveClient(ctx context.Context, s Store, client *Client) error {
+	return setJSON(ctx, s, clientKeyPrefix+client.ID, client, 0)
+}
+
+// GetClient returns a registered client or ErrNotFound
+func GetClient(ctx context.Context, s Store, clientID string) (*Client, error) {
+	var client Client
+	if err := getJSON(ctx, s, clientKeyPrefix+clientID, &client); err != nil {
+		return nil, err
+	}
+	return &client, nil
+}
+
+// Authorization is a sign-in in progress. It is saved under the state the
+// server sent to the provider while the user signs in, then under the code
+// issued to the client once the provider calls back.
+type Authorization struct {
+	ClientID    string `json:"client_id"`
+	RedirectURI string `json:"redirect_uri"`
+	// State is the client's state, returned unchanged with the code
+	State               string `json:"state,omitempty"`
+	CodeChallenge       string `json:"code_challenge"`
+	CodeChallengeMethod string `json:"code_challenge_method"`
+	// CallbackURL, CodeVerifier and Code are used to redeem the provider's
+	// code; the verifier never leaves the server
+	CallbackURL  string `json:"callback_url"`
+	CodeVerifier string `json:"code_verifier"`
+	Code         string `json:"code,omitempty"`
+}
+
+// SaveAuthorization stores a pending authorization under the server state
+func SaveAuthorization(ctx context.Context, s Store, state string, authz *Authorization, ttl time.Duration) error {
+	return setJSON(ctx, s, authorizationKeyPrefix+state, authz, ttl)
+}
+
+// GetAuthorization returns the pending authorization of state without
+// consuming it, or ErrNotFound
+func GetAuthorization(ctx context.Context, s Store, state string) (*Authorization, error) {
+	var authz Authorization
+	if err := getJSON(ctx, s, authorizationKeyPrefix+state, &authz); err != nil {
+		return nil, err
+	}
+	return &authz, nil
+}
+
+// TakeAuthorization returns and removes the pending authorization of state,
+// or ErrNotFound if it is unknown, expired or already used
+func TakeAuthorization(ctx context.Context, s Store, state string) (*Authorization, error) {
+	var authz Authorization
+	if err := takeJSON(ctx, s, authorizationKeyPrefix+state, &authz); err != nil {
+		return nil, err
+	}
+	return &authz, nil
+}
+
+// SaveCode stores the authorization redeemable with code
+func SaveCode(ctx context.Context, s Store, code string, authz *Authorization, ttl time.Duration) error {
+	return setJSON(ctx, s, codeKeyPrefix+code, authz, ttl)
+}
+
+// TakeCode returns and removes the authorization of code, so each code can
+// be redeemed once
+func TakeCode(ctx context.Context, s Store, code string) (*Authorization, error) {
+	var authz Authorization
+	if err := takeJSON(ctx, s, codeKeyPrefix+code, &authz); err != nil {
+		return nil, err
+	}
+	return &authz, nil
+}
+
+// BindRefreshToken records the client a refresh token was issued to. Only a
+// hash of the token is stored.
+func BindRefreshToken(ctx context.Context, s Store, refreshToken, clientID string, ttl time.Duration) error {
+	return s.Set(ctx, refreshTokenKey(refreshToken), []byte(clientID), ttl)
+}
+
+// RefreshTokenClient returns the ID of the client a refresh token was issued
+// to, or ErrNotFound
+func RefreshTokenClient(ctx context.Context, s Store, refreshToken string) (string, error) {
+	clientID, err := s.Get(ctx, refreshTokenKey(refreshToken))
+	if err != nil {
+		return "", err
+	}
+	return string(clientID), nil
+}
+
+// UnbindRefreshToken forgets a refresh token, e.g. once it was rotated
+func UnbindRefreshToken(ctx context.Context, s Store, refreshToken string) error {
+	return s.Delete(ctx, refreshTokenKey(refreshToken))
+}
+
+func refreshTokenKey(refreshToken string) string {
+	sum := sha256.Sum256([]byte(refreshToken))
+	return refreshTokenKeyPrefix + hex.EncodeToString(sum[:])
+}
+
+func setJSON(ctx context.Context, s Store, key string, value interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", key, err)
+	}
+	return s.Set(ctx, key, data, ttl)
+}
+
+func getJSON(ctx context.Context, s Store, key string, value interface{}) error {
+	data, err := s.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, value); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", key, err)
+	}
+	return nil
+}
+
+func takeJSON(ctx context.Context, s Store, key string, value interface{}) error {
+	data, err := s.Take(ctx, key)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, value); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", key, err)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package store
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/brizzai/auto-mcp/internal/config"
+	"github.com/redis/go-redis/v9"
+)
+
+// defaultKeyPrefix namespaces keys when several services share a Redis database
+const defaultKeyPrefix = "auto-mcp:"
+
+// Redis is a Store shared by every replica connected to the same Redis
+type Redis struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedis connects to the configured Redis server
+func NewRedis(cfg config.RedisConfig) (*Redis, error) {
+	if cfg.Addr == "" {
+		return nil, fmt.Errorf("redis addr is required")
+	}
+	opts := &redis.Options{
+		Addr:     cfg.Addr,
+		Username: cfg.Username,
+		Password: cfg.Password,
+		DB:       cfg.DB,
+	}
+	if cfg.TLS {
+		opts.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	client := redis.NewClient(opts)
+	if err := client.Ping(ctx).Err(); err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("failed to connect to redis at %s: %w", cfg.Addr, err)
+	}
+
+	prefix := cfg.KeyPrefix
+	if prefix == "" {
+		prefix = defaultKeyPrefix
+	}
+	return &Redis{client: client, prefix: prefix}, nil
+}
+
+// Get returns the value of key or ErrNotFound
+func (r *Redis) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := r.client.Get(ctx, r.prefix+key).Bytes()
+	return value, redisError(err)
+}
+
+// Set stores value under key, expiring after ttl unless it is 0
+func (r *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return r.client.Set(ctx, r.prefix+key, value, ttl).Err()
+}
+
+// Take returns the value of key and deletes it with GETDEL (Redis 6.2+)
+func (r *Redis) Take(ctx context.Context, key string) ([]byte, error) {
+	value, err := r.client.GetDel(ctx, r.prefix+key).Bytes()
+	return value, redisError(err)
+}
+
+// Delete removes key
+func (r *Redis) Delete(ctx context.Context, key string) error {
+	return r.client.Del(ctx, r.prefix+key).Err()
+}
+
+// Close closes the connection pool
+func (r *Redis) Close() error {
+	return r.client.Close()
+}
+
+func redisError(err error) error {
+	if errors.Is(err, redis.Nil) {
+		return ErrNotFound
+	}
+	return err
+}
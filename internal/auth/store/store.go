@@ -0,0 +1,49 @@
+// Package store persists OAuth state such as registered clients, pending
+// authorizations and cached token validations, in memory or in Redis so
+// several replicas can share it.
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/brizzai/auto-mcp/internal/config"
+)
+
+// Store types
+const (
+	TypeMemory = "memory"
+	TypeRedis  = "redis"
+)
+
+// ErrNotFound is returned for missing and expired keys
+var ErrNotFound = errors.New("not found")
+
+// Store is a key-value store with per-key expiry
+type Store interface {
+	// Get returns the value of key or ErrNotFound
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Set stores value under key. A ttl of 0 keeps it until deleted.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Take returns the value of key and deletes it atomically, so one-time
+	// values such as authorization state cannot be used twice
+	Take(ctx context.Context, key string) ([]byte, error)
+	// Delete removes key; deleting a missing key is not an error
+	Delete(ctx context.Context, key string) error
+	// Close releases the store's resources
+	Close() error
+}
+
+// New creates the store configured for OAuth
+func New(cfg config.OAuthStoreConfig) (Store, error) {
+	switch cfg.Type {
+	case "", TypeMemory:
+		return NewMemory(), nil
+	case TypeRedis:
+		return NewRedis(cfg.Redis)
+	default:
+		return nil, fmt.Errorf("unsupported store type %q, expected %s or %s", cfg.Type, TypeMemory, TypeRedis)
+	}
+}
@@ -0,0 +1,98 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/brizzai/auto-mcp/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testStore exercises the Store contract; advance moves the store's clock
+func testStore(t *testing.T, s Store, advance func(time.Duration)) {
+	ctx := context.Background()
+
+	_, err := s.Get(ctx, "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, s.Set(ctx, "forever", []byte("a"), 0))
+	require.NoError(t, s.Set(ctx, "short", []byte("b"), time.Minute))
+	value, err := s.Get(ctx, "short")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("b"), value)
+
+	advance(2 * time.Minute)
+	_, err = s.Get(ctx, "short")
+	assert.ErrorIs(t, err, ErrNotFound)
+	value, err = s.Get(ctx, "forever")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("a"), value)
+
+	// Take can only succeed once
+	value, err = s.Take(ctx, "forever")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("a"), value)
+	_, err = s.Take(ctx, "forever")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, s.Set(ctx, "deleted", []byte("c"), 0))
+	require.NoError(t, s.Delete(ctx, "deleted"))
+	require.NoError(t, s.Delete(ctx, "deleted"))
+	_, err = s.Get(ctx, "deleted")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	client := &Client{ID: "client-1", Name: "Inspector", RedirectURIs: []string{"http://localhost/cb"}}
+	require.NoError(t, SaveClient(ctx, s, client))
+	loaded, err := GetClient(ctx, s, "client-1")
+	require.NoError(t, err)
+	assert.Equal(t, client.RedirectURIs, loaded.RedirectURIs)
+	_, err = GetClient(ctx, s, "client-2")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, s.Close())
+}
+
+func TestMemory(t *testing.T) {
+	now := time.Now()
+	m := NewMemory()
+	m.now = func() time.Time { return now }
+	testStore(t, m, func(d time.Duration) { now = now.Add(d) })
+}
+
+func TestMemory_SweepsExpiredEntries(t *testing.T) {
+	now := time.Now()
+	m := NewMemory()
+	m.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	require.NoError(t, m.Set(ctx, "a", []byte("a"), time.Second))
+	now = now.Add(2 * memorySweepInterval)
+	require.NoError(t, m.Set(ctx, "b", []byte("b"), 0))
+	assert.Len(t, m.entries, 1)
+}
+
+func TestRedis(t *testing.T) {
+	mr := miniredis.RunT(t)
+	s, err := New(config.OAuthStoreConfig{Type: TypeRedis, Redis: config.RedisConfig{Addr: mr.Addr()}})
+	require.NoError(t, err)
+
+	require.NoError(t, s.Set(context.Background(), "key", []byte("value"), 0))
+	assert.True(t, mr.Exists("auto-mcp:key"), "keys are namespaced")
+
+	testStore(t, s, mr.FastForward)
+}
+
+func TestNew(t *testing.T) {
+	s, err := New(config.OAuthStoreConfig{})
+	require.NoError(t, err)
+	assert.IsType(t, &Memory{}, s)
+
+	_, err = New(config.OAuthStoreConfig{Type: "etcd"})
+	assert.Error(t, err)
+
+	_, err = New(config.OAuthStoreConfig{Type: TypeRedis})
+	assert.Error(t, err)
+}
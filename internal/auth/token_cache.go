@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/brizzai/auto-mcp/internal/auth/models"
+	"github.com/brizzai/auto-mcp/internal/auth/providers"
+	"github.com/brizzai/auto-mcp/internal/auth/store"
+	"github.com/brizzai/auto-mcp/internal/logger"
+	"go.uber.org/zap"
+)
+
+// tokenKeyPrefix prefixes cached validations; tokens are stored hashed
+const tokenKeyPrefix = "token:"
+
+// cachingProvider remembers successful access token validations so every MCP
+// request does not call the identity provider
+type cachingProvider struct {
+	providers.OAuthProvider
+	store store.Store
+	ttl   time.Duration
+}
+
+// withTokenCache caches validations for ttl; a non-positive ttl disables caching
+func withTokenCache(provider providers.OAuthProvider, s store.Store, ttl time.Duration) providers.OAuthProvider {
+	if ttl <= 0 {
+		return provider
+	}
+	return &cachingProvider{OAuthProvider: provider, store: s, ttl: ttl}
+}
+
+// ValidateAccessToken returns the cached user for token or validates it with the provider
+func (p *cachingProvider) ValidateAccessToken(ctx context.Context, token string) (*models.UserInfo, error) {
+	sum := sha256.Sum256([]byte(token))
+	key := tokenKeyPrefix + hex.EncodeToString(sum[:])
+
+	data, err := p.store.Get(ctx, key)
+	if err == nil {
+		var userInfo models.UserInfo
+		if err := json.Unmarshal(data, &userInfo); err == nil {
+			return &userInfo, nil
+		}
+	} else if !errors.Is(err, store.ErrNotFound) {
+		logger.Warn("Failed to read token cache", zap.Error(err))
+	}
+
+	userInfo, err := p.OAuthProvider.ValidateAccessToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if data, err := json.Marshal(userInfo); err == nil {
+		if err := p.store.Set(ctx, key, data, p.ttl); err != nil {
+			logger.Warn("Failed to write token cache", zap.Error(err))
+		}
+	}
+	return userInfo, nil
+}
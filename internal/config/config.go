@@ -2,13 +2,24 @@ package config
 
 import (
 	"fmt"
+	"net"
+	"net/http"
+	"net/url"
 	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"regexp"
 	"strings"
+	"time"
 
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
 
+// toolPrefixPattern restricts tool prefixes to characters valid in MCP tool names
+var toolPrefixPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]*$`)
+
 // Version information - set by GoReleaser during build
 var (
 	version = "dev"
@@ -22,12 +33,126 @@ func GetVersionInfo() string {
 }
 
 type Config struct {
-	Server          ServerConfig   `mapstructure:"server"`
-	Logging         LoggingConfig  `mapstructure:"logging"`
-	EndpointConfig  EndpointConfig `mapstructure:"endpoint"`
-	SwaggerFile     string         `mapstructure:"swagger_file"`
-	AdjustmentsFile string         `mapstructure:"adjustments_file"`
-	OAuth           *OAuthConfig   `mapstructure:"oauth"`
+	Server          ServerConfig    `mapstructure:"server"`
+	Logging         LoggingConfig   `mapstructure:"logging"`
+	EndpointConfig  EndpointConfig  `mapstructure:"endpoint"`
+	SwaggerFile     string          `mapstructure:"swagger_file"`
+	AdjustmentsFile string          `mapstructure:"adjustments_file"`
+	OAuth           *OAuthConfig    `mapstructure:"oauth"`
+	Telemetry       TelemetryConfig `mapstructure:"telemetry"`
+	Workspace       WorkspaceConfig `mapstructure:"workspace"`
+	Secrets         SecretsConfig   `mapstructure:"secrets"`
+	// Plugins run custom hooks on upstream requests, responses and tools
+	Plugins []PluginConfig `mapstructure:"plugins"`
+
+	// Files lists the configuration files read by Load, e.g. to watch them
+	Files []string `mapstructure:"-"`
+}
+
+// PluginConfig loads a Go plugin implementing the interfaces of pkg/hooks
+type PluginConfig struct {
+	Name string `mapstructure:"name"`
+	// Path is the plugin's shared object, built with -buildmode=plugin
+	Path string `mapstructure:"path"`
+	// Config is passed to the plugin's New function
+	Config map[string]string `mapstructure:"config"`
+}
+
+// SecretsConfig configures external secret stores. Credentials reference them
+// as vault://<path>#<key>.
+type SecretsConfig struct {
+	Vault VaultConfig `mapstructure:"vault"`
+}
+
+// VaultConfig connects to a HashiCorp Vault KV v2 secrets engine
+type VaultConfig struct {
+	// Address defaults to VAULT_ADDR
+	Address string `mapstructure:"address"`
+	// Namespace defaults to VAULT_NAMESPACE (Vault Enterprise)
+	Namespace string `mapstructure:"namespace"`
+	// Mount is the path of the KV v2 engine, defaults to "secret"
+	Mount string `mapstructure:"mount"`
+	// AuthMethod is token (default), approle or kubernetes
+	AuthMethod string `mapstructure:"auth_method"`
+	// AuthMount is the path of the auth method, defaults to its name
+	AuthMount string `mapstructure:"auth_mount"`
+	// Token is used by the token method, defaults to VAULT_TOKEN
+	Token string `mapstructure:"token"`
+	// RoleID and SecretID log in with the approle method
+	RoleID   string `mapstructure:"role_id"`
+	SecretID string `mapstructure:"secret_id"`
+	// Role logs in with the kubernetes method using the service account
+	// token in JWTFile, which defaults to the token mounted into pods
+	Role    string `mapstructure:"role"`
+	JWTFile string `mapstructure:"jwt_file"`
+	// RefreshInterval is how often secrets are read again, defaults to 5m
+	RefreshInterval time.Duration `mapstructure:"refresh_interval"`
+}
+
+// WorkspaceConfig configures the directory used for temporary files
+type WorkspaceConfig struct {
+	// Dir defaults to an auto-mcp directory under the system temp dir
+	Dir string `mapstructure:"dir"`
+	// MaxSizeBytes caps the total size of the workspace, 0 means unlimited
+	MaxSizeBytes int64 `mapstructure:"max_size_bytes"`
+	// TTL is how long files are kept, defaults to 1h
+	TTL time.Duration `mapstructure:"ttl"`
+	// CleanupInterval is how often expired files are removed, defaults to 5m
+	CleanupInterval time.Duration `mapstructure:"cleanup_interval"`
+}
+
+// Directory returns the configured workspace directory or the default
+func (c WorkspaceConfig) Directory() string {
+	if c.Dir == "" {
+		return filepath.Join(os.TempDir(), "auto-mcp")
+	}
+	return c.Dir
+}
+
+// TTLDuration returns the configured file TTL or the default
+func (c WorkspaceConfig) TTLDuration() time.Duration {
+	if c.TTL <= 0 {
+		return time.Hour
+	}
+	return c.TTL
+}
+
+// Interval returns the configured cleanup interval or the default
+func (c WorkspaceConfig) Interval() time.Duration {
+	if c.CleanupInterval <= 0 {
+		return 5 * time.Minute
+	}
+	return c.CleanupInterval
+}
+
+// TelemetryConfig configures OpenTelemetry tracing
+type TelemetryConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// ServiceName is reported as service.name, defaults to auto-mcp
+	ServiceName string `mapstructure:"service_name"`
+	// Endpoint is the OTLP/HTTP collector URL, e.g. http://localhost:4318.
+	// Empty falls back to the standard OTEL_EXPORTER_OTLP_* environment variables
+	Endpoint string `mapstructure:"endpoint"`
+	// Headers are sent with every export request, e.g. collector API keys
+	Headers map[string]string `mapstructure:"headers"`
+	// SampleRatio is the fraction of new traces to record, defaults to 1
+	SampleRatio float64 `mapstructure:"sample_ratio"`
+}
+
+// Service returns the configured service name or the default
+func (c TelemetryConfig) Service() string {
+	if c.ServiceName == "" {
+		return "auto-mcp"
+	}
+	return c.ServiceName
+}
+
+// Ratio returns the configured sample ratio or the default
+func (c TelemetryConfig) Ratio() float64 {
+	if c.SampleRatio <= 0 {
+		return 1
+	}
+	return c.SampleRatio
 }
 
 // AuthType represents the type of authentication to use
@@ -39,6 +164,8 @@ const (
 	AuthTypeBearer AuthType = "bearer"
 	AuthTypeAPIKey AuthType = "api_key"
 	AuthTypeOAuth2 AuthType = "oauth2"
+	// AuthTypeSession logs in with a configured request and reuses the session cookies
+	AuthTypeSession AuthType = "session"
 )
 
 type EndpointConfig struct {
@@ -46,6 +173,281 @@ type EndpointConfig struct {
 	AuthType   AuthType          `json:"auth_type" mapstructure:"auth_type"`
 	AuthConfig map[string]string `json:"auth_config" mapstructure:"auth_config"`
 	Headers    map[string]string `json:"headers" mapstructure:"headers"`
+	// Completions suggest argument values to MCP clients, next to enum values
+	Completions []CompletionConfig `json:"completions" mapstructure:"completions"`
+	// ForwardAuthToken sends the MCP caller's validated OAuth token upstream
+	// instead of the shared credential configured above
+	ForwardAuthToken bool `json:"forward_auth_token" mapstructure:"forward_auth_token"`
+	// OnBehalfOf configures the delegation header sent on routes selected in the adjustments file
+	OnBehalfOf OnBehalfOfConfig `json:"on_behalf_of" mapstructure:"on_behalf_of"`
+	// UnwrapEnvelopes returns only "data" from {"data": ..., "meta": ...} responses on every route
+	UnwrapEnvelopes bool `json:"unwrap_envelopes" mapstructure:"unwrap_envelopes"`
+	// Idempotency attaches an idempotency key to POST and PATCH requests
+	Idempotency IdempotencyConfig `json:"idempotency" mapstructure:"idempotency"`
+	// HTMLResponses converts text/html responses to markdown or text before they reach the model.
+	// Empty or raw returns the markup unchanged
+	HTMLResponses string `json:"html_responses" mapstructure:"html_responses"`
+	// TabularResponses converts CSV and NDJSON responses to JSON arrays
+	TabularResponses TabularResponsesConfig `json:"tabular_responses" mapstructure:"tabular_responses"`
+	// StrictDates forwards date and date-time arguments exactly as the model sent them
+	StrictDates bool `json:"strict_dates" mapstructure:"strict_dates"`
+	// RequestCompressionThreshold gzips request bodies of at least this many bytes, 0 disables it
+	RequestCompressionThreshold int `json:"request_compression_threshold" mapstructure:"request_compression_threshold"`
+	// RawRequest registers the http_request escape-hatch tool
+	RawRequest RawRequestConfig `json:"raw_request" mapstructure:"raw_request"`
+	// ProbePath is a cheap authenticated GET route, e.g. /me, that
+	// "auto-mcp doctor" calls to check the upstream credentials
+	ProbePath string `json:"probe_path" mapstructure:"probe_path"`
+	// Tenants route authenticated users to their own upstream base URL or credentials
+	Tenants []TenantConfig `json:"tenants" mapstructure:"tenants"`
+	// Cassette records upstream responses to disk or replays them offline
+	Cassette CassetteConfig `json:"cassette" mapstructure:"cassette"`
+	// BaseURLOverride lets MCP sessions target another allowed upstream base URL
+	BaseURLOverride BaseURLOverrideConfig `json:"base_url_override" mapstructure:"base_url_override"`
+}
+
+// BaseURLOverrideConfig lets MCP clients point a session at another upstream
+// base URL from an allow-list, e.g. a customer-specific API host
+type BaseURLOverrideConfig struct {
+	// Header carries the base URL on MCP requests, defaults to X-Auto-MCP-Base-URL
+	Header string `json:"header" mapstructure:"header"`
+	// Allowed lists the permitted base URLs. A "*" host label matches any
+	// single label, e.g. https://*.api.example.com. Empty disables overrides.
+	Allowed []string `json:"allowed" mapstructure:"allowed"`
+}
+
+// HeaderName returns the configured override header or the default
+func (c BaseURLOverrideConfig) HeaderName() string {
+	if c.Header == "" {
+		return "X-Auto-MCP-Base-URL"
+	}
+	return c.Header
+}
+
+// Enabled reports whether sessions may override the base URL
+func (c BaseURLOverrideConfig) Enabled() bool {
+	return len(c.Allowed) > 0
+}
+
+// Allows reports whether baseURL matches an allowed base URL: the same
+// scheme, a host matching label by label and a path at or below the
+// allowed path
+func (c BaseURLOverrideConfig) Allows(baseURL string) bool {
+	u, ok := parseBaseURL(baseURL)
+	if !ok || u.User != nil {
+		return false
+	}
+	for _, entry := range c.Allowed {
+		allowed, ok := parseBaseURL(entry)
+		if !ok || allowed.Scheme != u.Scheme || !matchHost(allowed.Host, u.Host) {
+			continue
+		}
+		prefix := strings.TrimSuffix(allowed.Path, "/")
+		if p := strings.TrimSuffix(u.Path, "/"); p == prefix || strings.HasPrefix(p, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// parseBaseURL parses an absolute http(s) URL without query or fragment
+func parseBaseURL(raw string) (*url.URL, bool) {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.RawQuery != "" || u.Fragment != "" {
+		return nil, false
+	}
+	return u, true
+}
+
+// matchHost matches host against pattern label by label, case-insensitively.
+// A "*" label matches any single non-empty label.
+func matchHost(pattern, host string) bool {
+	patternLabels := strings.Split(strings.ToLower(pattern), ".")
+	hostLabels := strings.Split(strings.ToLower(host), ".")
+	if len(patternLabels) != len(hostLabels) {
+		return false
+	}
+	for i, label := range patternLabels {
+		if label != hostLabels[i] && (label != "*" || hostLabels[i] == "") {
+			return false
+		}
+	}
+	return true
+}
+
+// Cassette modes
+const (
+	CassetteModeRecord = "record"
+	CassetteModeReplay = "replay"
+)
+
+// CassetteConfig records upstream request/response pairs into Dir, or
+// replays them from Dir without calling the upstream API
+type CassetteConfig struct {
+	// Mode is record or replay, empty disables cassettes
+	Mode string `json:"mode" mapstructure:"mode"`
+	Dir  string `json:"dir" mapstructure:"dir"`
+}
+
+// TenantConfig overrides the upstream endpoint for the users it matches.
+// Empty fields fall back to the endpoint settings; headers are merged.
+type TenantConfig struct {
+	Name string `json:"name" mapstructure:"name"`
+	// Match lists authorization policy subjects, e.g. "email_domain:acme.com" or "claim:org=acme"
+	Match      []string          `json:"match" mapstructure:"match"`
+	BaseURL    string            `json:"base_url" mapstructure:"base_url"`
+	AuthType   AuthType          `json:"auth_type" mapstructure:"auth_type"`
+	AuthConfig map[string]string `json:"auth_config" mapstructure:"auth_config"`
+	Headers    map[string]string `json:"headers" mapstructure:"headers"`
+}
+
+// RawRequestConfig restricts what the http_request tool may call
+type RawRequestConfig struct {
+	Enabled bool `json:"enabled" mapstructure:"enabled"`
+	// AllowedPaths are path.Match patterns, a trailing "/**" also matches everything below.
+	// Empty allows any path under the base URL
+	AllowedPaths []string `json:"allowed_paths" mapstructure:"allowed_paths"`
+	// AllowedMethods are the permitted HTTP methods, empty allows all
+	AllowedMethods []string `json:"allowed_methods" mapstructure:"allowed_methods"`
+}
+
+// TabularResponsesConfig converts text/csv, text/tab-separated-values and
+// NDJSON responses to JSON arrays of at most MaxRows rows
+type TabularResponsesConfig struct {
+	Enabled bool `json:"enabled" mapstructure:"enabled"`
+	// MaxRows caps the rows returned to the model, defaults to 100
+	MaxRows int `json:"max_rows" mapstructure:"max_rows"`
+	// Resources keeps the full payload of truncated responses in the
+	// workspace, readable as an MCP resource until the workspace TTL
+	Resources bool `json:"resources" mapstructure:"resources"`
+}
+
+// Rows returns the configured row cap or the default
+func (c TabularResponsesConfig) Rows() int {
+	if c.MaxRows <= 0 {
+		return 100
+	}
+	return c.MaxRows
+}
+
+// HTML response handling modes
+const (
+	HTMLResponsesRaw      = "raw"
+	HTMLResponsesMarkdown = "markdown"
+	HTMLResponsesText     = "text"
+)
+
+// Idempotency key strategies
+const (
+	IdempotencyStrategyUUID = "uuid"
+	IdempotencyStrategyHash = "hash"
+)
+
+// IdempotencyConfig describes how idempotency keys are generated for unsafe methods
+type IdempotencyConfig struct {
+	Enabled bool `json:"enabled" mapstructure:"enabled"`
+	// Header carries the key, defaults to Idempotency-Key
+	Header string `json:"header" mapstructure:"header"`
+	// Strategy is uuid (a new key per call) or hash (derived from method, URL and body). Defaults to uuid
+	Strategy string `json:"strategy" mapstructure:"strategy"`
+}
+
+// HeaderName returns the configured idempotency header or the default
+func (c IdempotencyConfig) HeaderName() string {
+	if c.Header == "" {
+		return "Idempotency-Key"
+	}
+	return c.Header
+}
+
+// CompletionConfig suggests values for the tool and prompt arguments named
+// Argument when MCP clients ask for completions
+type CompletionConfig struct {
+	Argument string `json:"argument" mapstructure:"argument"`
+	// Values are known values of the argument
+	Values []string `json:"values" mapstructure:"values"`
+	// ListPath is a GET route listing the known values, e.g. /pets
+	ListPath string `json:"list_path" mapstructure:"list_path"`
+	// ItemsPath selects the array of items in the list response, empty uses the whole body
+	ItemsPath string `json:"items_path" mapstructure:"items_path"`
+	// ValuePath selects the value within each item, e.g. id; empty uses the item itself
+	ValuePath string `json:"value_path" mapstructure:"value_path"`
+	// CacheTTL is how long listed values are reused, defaults to 5m
+	CacheTTL time.Duration `json:"cache_ttl" mapstructure:"cache_ttl"`
+	// HeaderNames suggests the request header names the API uses
+	HeaderNames bool `json:"header_names" mapstructure:"header_names"`
+}
+
+// validateCompletions checks that every completion names a unique argument
+// and has a source of values
+func validateCompletions(completions []CompletionConfig) error {
+	arguments := make(map[string]bool, len(completions))
+	for i, completion := range completions {
+		if completion.Argument == "" {
+			return fmt.Errorf("endpoint.completions[%d]: argument is required", i)
+		}
+		if arguments[completion.Argument] {
+			return fmt.Errorf("endpoint.completions[%d]: duplicate argument %q", i, completion.Argument)
+		}
+		arguments[completion.Argument] = true
+		if len(completion.Values) == 0 && completion.ListPath == "" && !completion.HeaderNames {
+			return fmt.Errorf("endpoint.completions[%d]: values, list_path or header_names is required", i)
+		}
+		if completion.ListPath != "" && !strings.HasPrefix(completion.ListPath, "/") {
+			return fmt.Errorf("endpoint.completions[%d].list_path: %q must start with /", i, completion.ListPath)
+		}
+		if completion.CacheTTL < 0 {
+			return fmt.Errorf("endpoint.completions[%d].cache_ttl: must not be negative", i)
+		}
+	}
+	return nil
+}
+
+// OnBehalfOfConfig describes how the authenticated user's identity is sent upstream
+type OnBehalfOfConfig struct {
+	// Header carries the identity, defaults to X-On-Behalf-Of
+	Header string `json:"header" mapstructure:"header"`
+	// Claim selects the identity: id, email, name or a provider specific claim. Defaults to email
+	Claim string `json:"claim" mapstructure:"claim"`
+	// SigningSecret, when set, adds an HMAC-SHA256 signature and timestamp header
+	SigningSecret string `json:"signing_secret" mapstructure:"signing_secret"`
+}
+
+// HeaderName returns the configured on-behalf-of header or the default
+func (c OnBehalfOfConfig) HeaderName() string {
+	if c.Header == "" {
+		return "X-On-Behalf-Of"
+	}
+	return c.Header
+}
+
+// ClaimName returns the configured identity claim or the default
+func (c OnBehalfOfConfig) ClaimName() string {
+	if c.Claim == "" {
+		return "email"
+	}
+	return c.Claim
+}
+
+// SupportConfig is appended as an escalation hint to server-side error results
+type SupportConfig struct {
+	// Contact is a team, channel or email, e.g. "#api-support"
+	Contact string `mapstructure:"contact"`
+	// RunbookURL links to troubleshooting documentation
+	RunbookURL string `mapstructure:"runbook_url"`
+}
+
+// Hint returns the escalation sentence, or "" when nothing is configured
+func (c SupportConfig) Hint() string {
+	switch {
+	case c.RunbookURL != "" && c.Contact != "":
+		return fmt.Sprintf("If this persists, see %s or contact %s.", c.RunbookURL, c.Contact)
+	case c.RunbookURL != "":
+		return fmt.Sprintf("If this persists, see %s.", c.RunbookURL)
+	case c.Contact != "":
+		return fmt.Sprintf("If this persists, contact %s.", c.Contact)
+	}
+	return ""
 }
 
 type ServerMode string
@@ -63,6 +465,216 @@ type ServerConfig struct {
 	Mode    ServerMode `mapstructure:"mode"`
 	Name    string     `mapstructure:"name"`
 	Version string     `mapstructure:"version"`
+	// Compression enables gzip for HTTP/SSE responses when the client accepts it
+	Compression bool `mapstructure:"compression"`
+	// MaxMessageSize caps the size in bytes of a single tool result, 0 means unlimited
+	MaxMessageSize int `mapstructure:"max_message_size"`
+	// Support tells end users where to go when the upstream API misbehaves
+	Support SupportConfig `mapstructure:"support"`
+	// HelperTools registers built-in time/date and UUID helper tools
+	HelperTools bool `mapstructure:"helper_tools"`
+	// LazyTools registers only search_tools, enable_tool and call_operation
+	// instead of one tool per operation, for very large specs
+	LazyTools bool `mapstructure:"lazy_tools"`
+	// Locale selects the description set of the adjustments file's locales
+	// section, e.g. "de"; empty uses the default descriptions
+	Locale string `mapstructure:"locale"`
+	// ToolPrefix is prepended to every tool name, e.g. "billing_", so several
+	// instances can be used from one client without name clashes
+	ToolPrefix string `mapstructure:"tool_prefix"`
+	// TLS serves the SSE/HTTP endpoint over HTTPS when a certificate is configured
+	TLS TLSConfig `mapstructure:"tls"`
+	// PublicURL is the URL clients use to reach the server, e.g. behind a
+	// reverse proxy. It is advertised in the SSE message endpoint and OAuth
+	// discovery documents; empty derives it from each request
+	PublicURL string `mapstructure:"public_url"`
+	// BasePath serves every route under this path prefix, e.g. /mcp
+	BasePath string `mapstructure:"base_path"`
+	// TrustedProxies are IPs or CIDRs whose X-Forwarded-For/-Proto/-Host headers are honored
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
+	// RateLimit caps requests to the HTTP/SSE endpoints
+	RateLimit RateLimitConfig `mapstructure:"rate_limit"`
+	// Concurrency caps the tool calls running at once, 0 disables a limit
+	Concurrency ConcurrencyConfig `mapstructure:"concurrency"`
+	// Analytics tracks call counts, error rates and latencies per tool
+	Analytics AnalyticsConfig `mapstructure:"analytics"`
+	// Admin serves the admin API under /admin when a token is configured
+	Admin AdminConfig `mapstructure:"admin"`
+	// StreamableHTTP configures sessions and heartbeats of the http mode
+	StreamableHTTP StreamableHTTPConfig `mapstructure:"streamable_http"`
+	// SSE configures keep-alives and reconnection of the sse mode streams
+	SSE SSEConfig `mapstructure:"sse"`
+	// Limits bounds request bodies and how long clients may take to send
+	// requests and read responses in http and sse mode
+	Limits LimitsConfig `mapstructure:"limits"`
+	// WatchConfig reloads the configuration, spec and adjustments when one of
+	// the files changes, in addition to on SIGHUP
+	WatchConfig bool `mapstructure:"watch_config"`
+	// ShutdownTimeout bounds how long shutdown waits for running tool calls
+	// and open connections, defaults to 20s
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
+}
+
+// RateLimitConfig holds request limits per minute, 0 disables a limit
+type RateLimitConfig struct {
+	PerIP      int `mapstructure:"per_ip"`
+	PerUser    int `mapstructure:"per_user"`
+	PerSession int `mapstructure:"per_session"`
+	// Burst is the number of requests allowed at once, defaults to the per-minute limit
+	Burst int `mapstructure:"burst"`
+}
+
+// ConcurrencyConfig caps the tool calls sent upstream at the same time.
+// Calls over a limit fail at once with a retry hint instead of queueing.
+type ConcurrencyConfig struct {
+	// MaxInFlight caps the calls running across all tools
+	MaxInFlight int `mapstructure:"max_in_flight"`
+	// PerTool caps the calls running for each tool, policies may override it per route
+	PerTool int `mapstructure:"per_tool"`
+}
+
+// StreamableHTTPConfig configures the streamable HTTP transport
+type StreamableHTTPConfig struct {
+	// Stateless treats every request as a new session and returns no session ID
+	Stateless bool `mapstructure:"stateless"`
+	// SessionIdleTimeout ends stateful sessions without requests or an open
+	// stream for this long, 0 keeps sessions until the client ends them
+	SessionIdleTimeout time.Duration `mapstructure:"session_idle_timeout"`
+	// HeartbeatInterval sends a ping on open GET streams this often so
+	// gateways do not close them as idle, 0 disables heartbeats
+	HeartbeatInterval time.Duration `mapstructure:"heartbeat_interval"`
+}
+
+// LimitsConfig protects the HTTP server from oversized requests and slow
+// clients, 0 disables a limit. Event streams are exempt from the read and
+// write timeouts.
+type LimitsConfig struct {
+	// MaxBodyBytes caps the size of request bodies
+	MaxBodyBytes int64 `mapstructure:"max_body_bytes"`
+	// ReadHeaderTimeout bounds reading the request headers
+	ReadHeaderTimeout time.Duration `mapstructure:"read_header_timeout"`
+	// ReadTimeout bounds reading the whole request, including the body
+	ReadTimeout time.Duration `mapstructure:"read_timeout"`
+	// WriteTimeout bounds handling a request and writing its response, so it
+	// must exceed the longest tool call
+	WriteTimeout time.Duration `mapstructure:"write_timeout"`
+	// IdleTimeout closes keep-alive connections without requests for this long
+	IdleTimeout time.Duration `mapstructure:"idle_timeout"`
+}
+
+// SSEConfig configures the event streams of the SSE transport
+type SSEConfig struct {
+	// KeepAliveInterval writes an SSE comment on open streams this often so
+	// proxies do not close them as idle, 0 disables keep-alive comments
+	KeepAliveInterval time.Duration `mapstructure:"keep_alive_interval"`
+	// PingInterval sends an MCP ping request on open streams this often, for
+	// clients that watch for messages rather than bytes, 0 disables pings
+	PingInterval time.Duration `mapstructure:"ping_interval"`
+	// RetryDelay tells clients how long to wait before reconnecting a dropped
+	// stream, 0 leaves it to the client
+	RetryDelay time.Duration `mapstructure:"retry_delay"`
+	// ResumeWindow numbers stream events and keeps the recent ones for this
+	// long after a stream drops, so a client reconnecting with Last-Event-ID
+	// receives the events lost in transit, 0 disables event IDs
+	ResumeWindow time.Duration `mapstructure:"resume_window"`
+}
+
+// AnalyticsConfig enables per-tool usage analytics
+type AnalyticsConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// File persists the statistics across restarts, empty keeps them in memory
+	File string `mapstructure:"file"`
+	// SummaryInterval is how often a usage summary is logged and the file
+	// saved, defaults to 1h
+	SummaryInterval time.Duration `mapstructure:"summary_interval"`
+}
+
+// Interval returns the configured summary interval or the default
+func (c AnalyticsConfig) Interval() time.Duration {
+	if c.SummaryInterval <= 0 {
+		return time.Hour
+	}
+	return c.SummaryInterval
+}
+
+// AdminConfig configures the admin API of the HTTP/SSE server
+type AdminConfig struct {
+	// Token is the bearer token admin requests must send, empty disables the admin API
+	Token string `mapstructure:"token"`
+}
+
+// TrustedProxyNets parses TrustedProxies, treating single IPs as /32 or /128 networks
+func (c *ServerConfig) TrustedProxyNets() ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(c.TrustedProxies))
+	for _, entry := range c.TrustedProxies {
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", entry)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", entry, err)
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets, nil
+}
+
+// ShutdownGracePeriod returns the configured shutdown timeout or the default
+func (c *ServerConfig) ShutdownGracePeriod() time.Duration {
+	if c.ShutdownTimeout <= 0 {
+		return 20 * time.Second
+	}
+	return c.ShutdownTimeout
+}
+
+// MountPath returns the base path as "" or "/prefix" without a trailing slash
+func (c *ServerConfig) MountPath() string {
+	trimmed := strings.Trim(c.BasePath, "/")
+	if trimmed == "" {
+		return ""
+	}
+	return "/" + trimmed
+}
+
+// ExternalURL returns the URL of the server root as seen by clients: the
+// public URL when configured, otherwise derived from the request
+func (c *ServerConfig) ExternalURL(r *http.Request) string {
+	if c.PublicURL != "" {
+		return strings.TrimSuffix(c.PublicURL, "/")
+	}
+	// A scheme on the request URL was set from X-Forwarded-Proto by a trusted proxy
+	scheme := r.URL.Scheme
+	if scheme == "" {
+		scheme = "http"
+		if r.TLS != nil {
+			scheme = "https"
+		}
+	}
+	return scheme + "://" + r.Host + c.MountPath()
+}
+
+// TLSConfig configures HTTPS for the SSE and HTTP modes
+type TLSConfig struct {
+	CertFile string `mapstructure:"cert_file"`
+	KeyFile  string `mapstructure:"key_file"`
+	// ClientCAFile verifies client certificates signed by these CAs
+	ClientCAFile string `mapstructure:"client_ca_file"`
+	// RequireClientCert rejects clients without a valid certificate
+	RequireClientCert bool `mapstructure:"require_client_cert"`
+}
+
+// Enabled reports whether a certificate is configured
+func (c TLSConfig) Enabled() bool {
+	return c.CertFile != ""
 }
 
 type LoggingConfig struct {
@@ -73,15 +685,178 @@ type LoggingConfig struct {
 	OutputPath        string `mapstructure:"output_path"`
 	AppendToFile      bool   `mapstructure:"append_to_file"`
 	DisableConsole    bool   `mapstructure:"disable_console"`
+	// MaxSizeMB rotates output_path once it reaches this size, defaults to 100
+	MaxSizeMB int `mapstructure:"max_size_mb"`
+	// MaxBackups is how many rotated files are kept, defaults to 5; negative keeps all
+	MaxBackups int `mapstructure:"max_backups"`
+	// MaxAgeDays removes rotated files older than this many days, 0 disables it
+	MaxAgeDays int `mapstructure:"max_age_days"`
+	// Compress gzips rotated files
+	Compress bool `mapstructure:"compress"`
 }
 
 type OAuthConfig struct {
 	Enabled      bool     `mapstructure:"enabled" `
-	Provider     string   `mapstructure:"provider"` // github or google
+	Provider     string   `mapstructure:"provider"` // github, google, oidc or internal
 	ClientID     string   `mapstructure:"client_id"`
 	ClientSecret string   `mapstructure:"client_secret"`
 	Scopes       []string `mapstructure:"scopes"`
 	AllowOrigins []string `mapstructure:"allow_origins"`
+	// AuthorizationServer is advertised to MCP clients in the protected
+	// resource metadata instead of auto-mcp's own OAuth endpoints
+	AuthorizationServer string `mapstructure:"authorization_server"`
+	// AllowedEmails and AllowedDomains restrict who may use the server after
+	// signing in. When both are empty every authenticated user is accepted
+	AllowedEmails  []string `mapstructure:"allowed_emails"`
+	AllowedDomains []string `mapstructure:"allowed_domains"`
+	// AllowedRedirectURIs restricts the redirect URIs clients may register.
+	// Entries match exactly, or by prefix when they end with "*"
+	AllowedRedirectURIs []string `mapstructure:"allowed_redirect_uris"`
+	// IssuerURL is the OpenID Connect issuer discovered by the oidc provider
+	IssuerURL string `mapstructure:"issuer_url"`
+	// Audience is the expected "aud" of JWT access tokens. When set, access
+	// tokens must be JWTs signed by the issuer; otherwise opaque tokens are
+	// checked with the userinfo endpoint and JWTs must be issued to ClientID
+	Audience string `mapstructure:"audience"`
+	// SkipAudienceCheck accepts JWT access tokens the issuer signed for any
+	// client, and JWTs that cannot be verified are checked with userinfo
+	SkipAudienceCheck bool `mapstructure:"skip_audience_check"`
+	// Internal configures the built-in provider's users and tokens
+	Internal InternalAuthConfig `mapstructure:"internal"`
+	// Policy restricts which tools authenticated users may call
+	Policy PolicyConfig `mapstructure:"policy"`
+	// Store keeps registered clients, pending authorizations and cached token validations
+	Store OAuthStoreConfig `mapstructure:"store"`
+	// TokenCacheTTL is how long a validated access token is trusted without
+	// asking the provider again. Defaults to 1m, negative disables caching
+	TokenCacheTTL time.Duration `mapstructure:"token_cache_ttl"`
+}
+
+// TokenCacheDuration returns the configured token cache TTL or the default
+func (c *OAuthConfig) TokenCacheDuration() time.Duration {
+	if c.TokenCacheTTL == 0 {
+		return time.Minute
+	}
+	return c.TokenCacheTTL
+}
+
+// InternalAuthConfig configures the internal provider, which signs users in
+// with a local user list and issues its own tokens
+type InternalAuthConfig struct {
+	// Users are the accounts that may sign in
+	Users []InternalUser `mapstructure:"users"`
+	// HtpasswdFile adds users from an htpasswd file with bcrypt hashes
+	HtpasswdFile string `mapstructure:"htpasswd_file"`
+	// SigningKey signs issued tokens (HS256, at least 32 bytes). When empty a
+	// random key is generated and tokens do not survive restarts
+	SigningKey string `mapstructure:"signing_key"`
+	// TokenTTL is the lifetime of access tokens, defaults to 1h
+	TokenTTL time.Duration `mapstructure:"token_ttl"`
+	// RefreshTokenTTL is the lifetime of refresh tokens, defaults to 720h
+	RefreshTokenTTL time.Duration `mapstructure:"refresh_token_ttl"`
+}
+
+// InternalUser is an account of the internal provider
+type InternalUser struct {
+	Username string `mapstructure:"username"`
+	// PasswordHash is a bcrypt hash, e.g. from `htpasswd -nbB user password`
+	PasswordHash string   `mapstructure:"password_hash"`
+	Email        string   `mapstructure:"email"`
+	Name         string   `mapstructure:"name"`
+	Groups       []string `mapstructure:"groups"`
+}
+
+// OAuthStoreConfig selects where OAuth state is kept
+type OAuthStoreConfig struct {
+	// Type is memory (default, per replica) or redis (shared between replicas)
+	Type  string      `mapstructure:"type"`
+	Redis RedisConfig `mapstructure:"redis"`
+}
+
+// RedisConfig holds the connection settings of a Redis server
+type RedisConfig struct {
+	Addr     string `mapstructure:"addr"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	DB       int    `mapstructure:"db"`
+	// KeyPrefix namespaces keys, defaults to "auto-mcp:"
+	KeyPrefix string `mapstructure:"key_prefix"`
+	TLS       bool   `mapstructure:"tls"`
+}
+
+// PolicyConfig maps scopes and identity claims to the tools a user may call
+type PolicyConfig struct {
+	// Default is "allow" (default) or "deny" for tools no rule matches
+	Default string `mapstructure:"default"`
+	// Roles names groups of subjects that rules can refer to as role:<name>
+	Roles map[string][]string `mapstructure:"roles"`
+	Rules []PolicyRule        `mapstructure:"rules"`
+}
+
+// PolicyRule allows the listed subjects to call the matching tools. A rule
+// without tools or methods matches every tool.
+type PolicyRule struct {
+	// Tools are tool name globs, without the server tool prefix
+	Tools []string `mapstructure:"tools"`
+	// Methods are the HTTP methods of the tools' upstream requests
+	Methods []string `mapstructure:"methods"`
+	// Allow lists subjects such as "role:admin", "scope:write" or "email_domain:example.com"
+	Allow []string `mapstructure:"allow"`
+}
+
+// defaultPort is the port served in sse and http mode
+const defaultPort = 8080
+
+// flagKeys maps configuration keys to the CLI flags that override them
+var flagKeys = map[string]string{
+	"swagger_file":        "swagger-file",
+	"adjustments_file":    "adjustments-file",
+	"endpoint.base_url":   "base-url",
+	"endpoint.auth_type":  "auth-type",
+	"server.port":         "port",
+	"server.mode":         "mode",
+	"server.watch_config": "watch-config",
+}
+
+// cassetteFlags set endpoint.cassette, which they have no single key of
+var cassetteFlags = []string{"record", "replay"}
+
+// setDefaults registers the values used when neither config.yaml, the
+// environment nor a flag sets them. Registering a key also lets AUTO_MCP_*
+// variables set it without a config file.
+func setDefaults() {
+	viper.SetDefault("server.mode", string(ServerModeSTDIO))
+	viper.SetDefault("server.port", defaultPort)
+	viper.SetDefault("server.host", "0.0.0.0")
+	viper.SetDefault("server.timeout", "30s")
+	viper.SetDefault("server.limits.max_body_bytes", 4<<20)
+	viper.SetDefault("server.limits.read_header_timeout", "10s")
+	viper.SetDefault("server.limits.read_timeout", "1m")
+	viper.SetDefault("server.limits.write_timeout", "5m")
+	viper.SetDefault("server.limits.idle_timeout", "2m")
+	viper.SetDefault("server.name", "Auto MCP")
+	viper.SetDefault("server.version", "1.0.0")
+	viper.SetDefault("logging.level", "info")
+	viper.SetDefault("logging.format", "json")
+	viper.SetDefault("endpoint.base_url", "")
+	viper.SetDefault("endpoint.auth_type", string(AuthTypeNone))
+	viper.SetDefault("endpoint.forward_auth_token", false)
+	viper.SetDefault("endpoint.probe_path", "")
+	viper.SetDefault("swagger_file", "")
+	viper.SetDefault("adjustments_file", "")
+	viper.SetDefault("oauth.enabled", false)
+	viper.SetDefault("oauth.provider", "")
+	viper.SetDefault("oauth.client_id", "")
+	viper.SetDefault("oauth.client_secret", "")
+}
+
+// cliValue returns the value of a flag without a configuration key, set on
+// the command line or by its AUTO_MCP_* variable
+func cliValue(name string) string {
+	if flag := pflag.CommandLine.Lookup(name); flag != nil && flag.Changed {
+		return flag.Value.String()
+	}
+	return os.Getenv("AUTO_MCP_" + strings.ToUpper(envKeyReplacer.Replace(name)))
 }
 
 // InitFlags initializes command line flags (without parsing)
@@ -89,6 +864,12 @@ func InitFlags() {
 	pflag.String("mode", string(ServerModeSTDIO), "Server mode (stdio|sse|http)")
 	pflag.String("swagger-file", "", "Path to the swagger file")
 	pflag.String("adjustments-file", "", "Path to the adjustments file")
+	pflag.Bool("watch-config", false, "Reload config.yaml, the swagger file and the adjustments file when they change")
+	pflag.String("base-url", "", "Base URL of the upstream API")
+	pflag.String("auth-type", "", "Upstream authentication type (none|basic|bearer|api_key|oauth2|session)")
+	pflag.Int("port", defaultPort, "Port to listen on in sse and http mode")
+	pflag.String("record", "", "Record upstream responses into this cassette directory")
+	pflag.String("replay", "", "Replay upstream responses from this cassette directory instead of calling the API")
 	// Note: no pflag.Parse() here as it's called in main.go
 }
 
@@ -96,22 +877,37 @@ func Load() (*Config, error) {
 	viper.Reset() // Ensure clean state
 
 	viper.SetEnvPrefix("AUTO_MCP")
-	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_", "-", "_"))
+	viper.SetEnvKeyReplacer(envKeyReplacer)
 	viper.AutomaticEnv()
 
-	if err := viper.BindPFlags(pflag.CommandLine); err != nil {
+	for key, flag := range flagKeys {
+		if f := pflag.CommandLine.Lookup(flag); f != nil {
+			if err := viper.BindPFlag(key, f); err != nil {
+				return nil, err
+			}
+		}
+	}
+	// AUTO_MCP_MODE predates server.mode and still sets it
+	if err := viper.BindEnv("server.mode", "AUTO_MCP_SERVER_MODE", "AUTO_MCP_MODE"); err != nil {
 		return nil, err
 	}
+	setDefaults()
 
-	// Load ./config.yaml first
+	// Load ./config.yaml first. It is optional: every setting can also come
+	// from flags and AUTO_MCP_* variables
 	viper.SetConfigName("config")
 	viper.SetConfigType("yaml")
 	viper.AddConfigPath(".")
 
 	viper.AddConfigPath("/etc/auto-mcp")
 
+	var files []string
 	if err := viper.ReadInConfig(); err != nil {
-		return nil, err
+		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
+			return nil, err
+		}
+	} else {
+		files = append(files, viper.ConfigFileUsed())
 	}
 
 	//Loading additionals config files
@@ -123,24 +919,32 @@ func Load() (*Config, error) {
 			if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
 				return nil, err
 			}
+		} else {
+			files = append(files, "/config/config.yaml")
+		}
+	}
+
+	// Render ${ENV_VAR} references in the files, in the order they were read
+	for _, file := range files {
+		if err := mergeExpandedConfig(file); err != nil {
+			return nil, err
 		}
 	}
 
+	if err := bindEnvKeys(reflect.TypeOf(Config{}), ""); err != nil {
+		return nil, err
+	}
+
 	var config Config
 	if err := viper.Unmarshal(&config); err != nil {
 		return nil, err
 	}
-	// Set server mode from flag
-	if mode := viper.GetString("mode"); mode != "" {
-		switch ServerMode(mode) {
-		case ServerModeSSE, ServerModeSTDIO, ServerModeHTTP:
-			config.Server.Mode = ServerMode(mode)
-		}
-	}
-
-	// Set swagger file from flag or environment
-	if swaggerFile := viper.GetString("swagger-file"); swaggerFile != "" {
-		config.SwaggerFile = swaggerFile
+	config.Files = files
+	switch config.Server.Mode {
+	case ServerModeSSE, ServerModeSTDIO, ServerModeHTTP:
+	default:
+		return nil, fmt.Errorf("server.mode: unknown mode %q, expected %s, %s or %s",
+			config.Server.Mode, ServerModeSTDIO, ServerModeSSE, ServerModeHTTP)
 	}
 
 	// validate swagger file
@@ -148,9 +952,160 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("swagger file is required, please adjust the config or pass --swagger-file or AUTO_MCP_SWAGGER_FILE environment variable")
 	}
 
-	// Set adjustments file from flag or environment
-	if adjustmentsFile := viper.GetString("adjustments-file"); adjustmentsFile != "" {
-		config.AdjustmentsFile = adjustmentsFile
+	record, replay := cliValue("record"), cliValue("replay")
+	switch {
+	case record != "" && replay != "":
+		return nil, fmt.Errorf("--record and --replay cannot be used together")
+	case record != "":
+		config.EndpointConfig.Cassette = CassetteConfig{Mode: CassetteModeRecord, Dir: record}
+	case replay != "":
+		config.EndpointConfig.Cassette = CassetteConfig{Mode: CassetteModeReplay, Dir: replay}
+	}
+	if record != "" || replay != "" {
+		// Keep config show in line with the flags
+		viper.Set("endpoint.cassette.mode", string(config.EndpointConfig.Cassette.Mode))
+		viper.Set("endpoint.cassette.dir", config.EndpointConfig.Cassette.Dir)
+	}
+
+	// ${ENV_VAR} references and "file://" values in config.yaml were expanded
+	// when it was read. Secrets set through AUTO_MCP_* variables are expanded
+	// here, so every value is expanded exactly once.
+
+	// Map keys are unknown to viper, so credentials set only through
+	// AUTO_MCP_ENDPOINT_AUTH_CONFIG_* variables are collected here
+	authConfig, err := mergeEnvMap(config.EndpointConfig.AuthConfig, "AUTO_MCP_ENDPOINT_AUTH_CONFIG_")
+	if err != nil {
+		return nil, err
+	}
+	config.EndpointConfig.AuthConfig = authConfig
+	// Credentials may also come from mounted secret files
+	if err := resolveFileKeys(config.EndpointConfig.AuthConfig, "endpoint.auth_config"); err != nil {
+		return nil, err
+	}
+	for i := range config.EndpointConfig.Tenants {
+		tenant := &config.EndpointConfig.Tenants[i]
+		if err := resolveFileKeys(tenant.AuthConfig, fmt.Sprintf("endpoint.tenants[%d].auth_config", i)); err != nil {
+			return nil, err
+		}
+	}
+	if err := validateTenants(config.EndpointConfig.Tenants); err != nil {
+		return nil, err
+	}
+	if err := validatePlugins(config.Plugins); err != nil {
+		return nil, err
+	}
+	for _, secret := range secretValues(&config) {
+		expanded, err := expandEnvSecret(secret.key, *secret.value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", secret.key, err)
+		}
+		*secret.value = expanded
+	}
+
+	switch config.EndpointConfig.AuthType {
+	case "":
+		config.EndpointConfig.AuthType = AuthTypeNone
+	case AuthTypeNone, AuthTypeBasic, AuthTypeBearer, AuthTypeAPIKey, AuthTypeOAuth2, AuthTypeSession:
+	default:
+		return nil, fmt.Errorf("endpoint.auth_type: unknown type %q, expected %s, %s, %s, %s, %s or %s",
+			config.EndpointConfig.AuthType, AuthTypeNone, AuthTypeBasic, AuthTypeBearer, AuthTypeAPIKey, AuthTypeOAuth2, AuthTypeSession)
+	}
+
+	switch config.EndpointConfig.Idempotency.Strategy {
+	case "", IdempotencyStrategyUUID, IdempotencyStrategyHash:
+	default:
+		return nil, fmt.Errorf("endpoint.idempotency.strategy: unknown strategy %q, expected %s or %s",
+			config.EndpointConfig.Idempotency.Strategy, IdempotencyStrategyUUID, IdempotencyStrategyHash)
+	}
+
+	for _, allowed := range config.EndpointConfig.BaseURLOverride.Allowed {
+		if _, ok := parseBaseURL(allowed); !ok {
+			return nil, fmt.Errorf("endpoint.base_url_override.allowed: %q must be an absolute http(s) URL without query or fragment", allowed)
+		}
+	}
+
+	switch cassette := config.EndpointConfig.Cassette; cassette.Mode {
+	case "":
+	case CassetteModeRecord, CassetteModeReplay:
+		if cassette.Dir == "" {
+			return nil, fmt.Errorf("endpoint.cassette: dir is required in %s mode", cassette.Mode)
+		}
+	default:
+		return nil, fmt.Errorf("endpoint.cassette.mode: unknown mode %q, expected %s or %s",
+			cassette.Mode, CassetteModeRecord, CassetteModeReplay)
+	}
+
+	if err := validateCompletions(config.EndpointConfig.Completions); err != nil {
+		return nil, err
+	}
+
+	switch config.EndpointConfig.HTMLResponses {
+	case "", HTMLResponsesRaw, HTMLResponsesMarkdown, HTMLResponsesText:
+	default:
+		return nil, fmt.Errorf("endpoint.html_responses: unknown mode %q, expected %s, %s or %s",
+			config.EndpointConfig.HTMLResponses, HTMLResponsesRaw, HTMLResponsesMarkdown, HTMLResponsesText)
+	}
+
+	tlsCfg := config.Server.TLS
+	if (tlsCfg.CertFile == "") != (tlsCfg.KeyFile == "") {
+		return nil, fmt.Errorf("server.tls: cert_file and key_file must be set together")
+	}
+	if !tlsCfg.Enabled() && (tlsCfg.ClientCAFile != "" || tlsCfg.RequireClientCert) {
+		return nil, fmt.Errorf("server.tls: client certificate verification requires cert_file and key_file")
+	}
+	if tlsCfg.RequireClientCert && tlsCfg.ClientCAFile == "" {
+		return nil, fmt.Errorf("server.tls: require_client_cert requires client_ca_file")
+	}
+
+	if publicURL := config.Server.PublicURL; publicURL != "" {
+		u, err := url.Parse(publicURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.RawQuery != "" || u.Fragment != "" {
+			return nil, fmt.Errorf("server.public_url: %q must be an absolute http(s) URL without query or fragment", publicURL)
+		}
+	}
+
+	if _, err := config.Server.TrustedProxyNets(); err != nil {
+		return nil, fmt.Errorf("server.trusted_proxies: %w", err)
+	}
+
+	if rl := config.Server.RateLimit; rl.PerIP < 0 || rl.PerUser < 0 || rl.PerSession < 0 || rl.Burst < 0 {
+		return nil, fmt.Errorf("server.rate_limit: limits must not be negative")
+	}
+
+	if c := config.Server.Concurrency; c.MaxInFlight < 0 || c.PerTool < 0 {
+		return nil, fmt.Errorf("server.concurrency: limits must not be negative")
+	}
+
+	if h := config.Server.StreamableHTTP; h.SessionIdleTimeout < 0 || h.HeartbeatInterval < 0 {
+		return nil, fmt.Errorf("server.streamable_http: durations must not be negative")
+	} else if h.Stateless && h.SessionIdleTimeout > 0 {
+		return nil, fmt.Errorf("server.streamable_http: session_idle_timeout requires stateful sessions")
+	}
+
+	if c := config.Server.SSE; c.KeepAliveInterval < 0 || c.PingInterval < 0 || c.RetryDelay < 0 || c.ResumeWindow < 0 {
+		return nil, fmt.Errorf("server.sse: durations must not be negative")
+	}
+
+	if l := config.Server.Limits; l.MaxBodyBytes < 0 || l.ReadHeaderTimeout < 0 || l.ReadTimeout < 0 || l.WriteTimeout < 0 || l.IdleTimeout < 0 {
+		return nil, fmt.Errorf("server.limits: limits must not be negative")
+	}
+
+	if config.Logging.MaxSizeMB < 0 || config.Logging.MaxAgeDays < 0 {
+		return nil, fmt.Errorf("logging: max_size_mb and max_age_days must not be negative")
+	}
+
+	if !toolPrefixPattern.MatchString(config.Server.ToolPrefix) {
+		return nil, fmt.Errorf("server.tool_prefix: %q may only contain letters, digits, '_', '-' and '.'", config.Server.ToolPrefix)
+	}
+
+	for _, pattern := range config.EndpointConfig.RawRequest.AllowedPaths {
+		if _, err := path.Match(strings.TrimSuffix(pattern, "/**"), "/"); err != nil {
+			return nil, fmt.Errorf("endpoint.raw_request.allowed_paths: invalid pattern %q: %w", pattern, err)
+		}
+	}
+
+	if p := config.EndpointConfig.ProbePath; p != "" && !strings.HasPrefix(p, "/") {
+		return nil, fmt.Errorf("endpoint.probe_path: %q must start with /", p)
 	}
 
 	if config.OAuth != nil && len(config.OAuth.Scopes) == 1 {
@@ -161,3 +1116,43 @@ func Load() (*Config, error) {
 
 	return &config, nil
 }
+
+// validateTenants checks tenant names and settings; match subjects are
+// validated when the authorization policy is compiled
+func validateTenants(tenants []TenantConfig) error {
+	names := make(map[string]bool, len(tenants))
+	for i, tenant := range tenants {
+		if tenant.Name == "" {
+			return fmt.Errorf("endpoint.tenants[%d]: name is required", i)
+		}
+		if names[tenant.Name] {
+			return fmt.Errorf("endpoint.tenants[%d]: duplicate name %q", i, tenant.Name)
+		}
+		names[tenant.Name] = true
+		if len(tenant.Match) == 0 {
+			return fmt.Errorf("endpoint.tenants[%d]: match is required", i)
+		}
+		if tenant.AuthType == AuthTypeSession {
+			return fmt.Errorf("endpoint.tenants[%d]: session auth is not supported per tenant", i)
+		}
+	}
+	return nil
+}
+
+// validatePlugins checks that every plugin has a unique name and a path
+func validatePlugins(plugins []PluginConfig) error {
+	names := make(map[string]bool, len(plugins))
+	for i, plugin := range plugins {
+		if plugin.Name == "" {
+			return fmt.Errorf("plugins[%d]: name is required", i)
+		}
+		if names[plugin.Name] {
+			return fmt.Errorf("plugins[%d]: duplicate name %q", i, plugin.Name)
+		}
+		names[plugin.Name] = true
+		if plugin.Path == "" {
+			return fmt.Errorf("plugins[%d]: path is required", i)
+		}
+	}
+	return nil
+}
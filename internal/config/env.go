@@ -0,0 +1,216 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// envPattern matches ${NAME} and ${NAME:-fallback} references
+var envPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// ExpandEnv replaces ${NAME} references in value with the environment variable
+// NAME. ${NAME:-fallback} uses fallback when NAME is unset or empty. Referencing
+// an unset variable without a fallback is an error.
+func ExpandEnv(value string) (string, error) {
+	var missing []string
+	expanded := envPattern.ReplaceAllStringFunc(value, func(match string) string {
+		groups := envPattern.FindStringSubmatch(match)
+		if env, ok := os.LookupEnv(groups[1]); ok && env != "" {
+			return env
+		}
+		if groups[2] != "" {
+			return groups[3]
+		}
+		missing = append(missing, groups[1])
+		return match
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("environment variable %s is not set", strings.Join(missing, ", "))
+	}
+	return expanded, nil
+}
+
+// ExpandEnvMap expands ${NAME} references in every value of m in place.
+// field names the config section in error messages.
+func ExpandEnvMap(m map[string]string, field string) error {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		expanded, err := ExpandEnv(m[k])
+		if err != nil {
+			return fmt.Errorf("%s.%s: %w", field, k, err)
+		}
+		m[k] = expanded
+	}
+	return nil
+}
+
+// mergeExpandedConfig merges the config file at path into viper again, with
+// ${NAME} references in its values expanded and "file://" credentials replaced
+// by the file contents. Values keep file precedence, so AUTO_MCP_* variables and
+// flags still override them.
+func mergeExpandedConfig(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var settings map[string]any
+	if err := yaml.Unmarshal(data, &settings); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if _, err := expandValues(settings, ""); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return viper.MergeConfigMap(settings)
+}
+
+// expandValues expands ${NAME} references in every string of a decoded YAML
+// document and resolves "file://" values of credentials, modifying maps and
+// slices in place. path names the value in error messages and isSecretSetting.
+func expandValues(value any, path string) (any, error) {
+	switch v := value.(type) {
+	case string:
+		expanded, err := ExpandEnv(v)
+		if err == nil && isSecretSetting(path) {
+			expanded, err = ResolveSecret(expanded)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return expanded, nil
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			child := k
+			if path != "" {
+				child = path + "." + k
+			}
+			expanded, err := expandValues(v[k], child)
+			if err != nil {
+				return nil, err
+			}
+			v[k] = expanded
+		}
+	case []any:
+		for i, item := range v {
+			expanded, err := expandValues(item, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+			v[i] = expanded
+		}
+	}
+	return value, nil
+}
+
+// mergeEnvMap adds the variables starting with prefix to m, keyed by the
+// rest of their name in lower case, e.g. AUTO_MCP_ENDPOINT_AUTH_CONFIG_TOKEN
+// sets token. Variables take precedence over values already in m, and their
+// ${NAME} references and "file://" values are expanded.
+func mergeEnvMap(m map[string]string, prefix string) (map[string]string, error) {
+	for _, env := range os.Environ() {
+		name, value, _ := strings.Cut(env, "=")
+		key, ok := strings.CutPrefix(name, prefix)
+		if !ok || key == "" {
+			continue
+		}
+		expanded, err := expandSecret(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if m == nil {
+			m = make(map[string]string)
+		}
+		m[strings.ToLower(key)] = expanded
+	}
+	return m, nil
+}
+
+// envKeyReplacer maps configuration keys to the names of their AUTO_MCP_*
+// variables
+var envKeyReplacer = strings.NewReplacer(".", "_", "-", "_")
+
+// expandEnvSecret expands ${NAME} references and resolves "file://" in value
+// when the AUTO_MCP_* variable of key set it. Values from config.yaml were
+// already expanded when the file was read.
+func expandEnvSecret(key, value string) (string, error) {
+	if env, ok := os.LookupEnv("AUTO_MCP_" + envKeyReplacer.Replace(strings.ToUpper(key))); !ok || env == "" {
+		return value, nil
+	}
+	return expandSecret(value)
+}
+
+// bindEnvKeys binds the AUTO_MCP_* variable of every setting of t, a struct
+// decoded at prefix. AutomaticEnv only reads the variables of keys viper
+// already knows from a file, a default or a flag, so without a config file
+// settings with no default would ignore their variables. Map entries are
+// bound from the variables that are set, e.g. AUTO_MCP_ENDPOINT_HEADERS_X_CUSTOM
+// sets endpoint.headers.x_custom unless a file already sets that header.
+func bindEnvKeys(t reflect.Type, prefix string) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		var err error
+		switch {
+		case fieldType.Kind() == reflect.Struct:
+			err = bindEnvKeys(fieldType, key)
+		case fieldType.Kind() == reflect.Map:
+			err = bindEnvMap(key)
+		case fieldType.Kind() == reflect.Slice && fieldType.Elem().Kind() == reflect.Struct:
+			// Lists of objects can only be set in a file
+		default:
+			err = viper.BindEnv(key)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bindEnvMap binds the set AUTO_MCP_* variables below the map setting key
+func bindEnvMap(key string) error {
+	prefix := "AUTO_MCP_" + strings.ToUpper(envKeyReplacer.Replace(key)) + "_"
+	inFile := make(map[string]bool)
+	for entry := range viper.GetStringMap(key) {
+		inFile[prefix+strings.ToUpper(envKeyReplacer.Replace(entry))] = true
+	}
+	for _, env := range os.Environ() {
+		name, _, _ := strings.Cut(env, "=")
+		entry, ok := strings.CutPrefix(name, prefix)
+		if !ok || entry == "" || inFile[name] {
+			continue
+		}
+		if err := viper.BindEnv(key+"."+strings.ToLower(entry), name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandEnv(t *testing.T) {
+	t.Setenv("AUTO_MCP_TEST_TOKEN", "s3cr3t")
+	t.Setenv("AUTO_MCP_TEST_EMPTY", "")
+
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr string
+	}{
+		{name: "no references", value: "plain", want: "plain"},
+		{name: "single reference", value: "${AUTO_MCP_TEST_TOKEN}", want: "s3cr3t"},
+		{name: "embedded reference", value: "Bearer ${AUTO_MCP_TEST_TOKEN}", want: "Bearer s3cr3t"},
+		{name: "fallback for unset", value: "${AUTO_MCP_TEST_UNSET:-fallback}", want: "fallback"},
+		{name: "fallback for empty", value: "${AUTO_MCP_TEST_EMPTY:-fallback}", want: "fallback"},
+		{name: "missing variable", value: "${AUTO_MCP_TEST_UNSET}", wantErr: "AUTO_MCP_TEST_UNSET is not set"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExpandEnv(tt.value)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestExpandEnvMap(t *testing.T) {
+	t.Setenv("AUTO_MCP_TEST_KEY", "abc")
+
+	headers := map[string]string{"X-Api-Key": "${AUTO_MCP_TEST_KEY}"}
+	require.NoError(t, ExpandEnvMap(headers, "endpoint.headers"))
+	assert.Equal(t, "abc", headers["X-Api-Key"])
+
+	err := ExpandEnvMap(map[string]string{"token": "${AUTO_MCP_TEST_UNSET}"}, "endpoint.auth_config")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "endpoint.auth_config.token")
+}
+
+func TestMergeExpandedConfig(t *testing.T) {
+	t.Setenv("AUTO_MCP_TEST_HOST", "api.internal")
+	t.Setenv("AUTO_MCP_TEST_PORT", "9090")
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+server:
+  port: ${AUTO_MCP_TEST_PORT}
+  # comments may mention ${AUTO_MCP_TEST_UNSET}
+  trusted_proxies: ["${AUTO_MCP_TEST_PROXY:-10.0.0.0/8}"]
+endpoint:
+  base_url: https://${AUTO_MCP_TEST_HOST}/v1
+`), 0o600))
+
+	viper.Reset()
+	defer viper.Reset()
+	viper.SetConfigFile(path)
+	require.NoError(t, viper.ReadInConfig())
+	require.NoError(t, mergeExpandedConfig(path))
+
+	var cfg Config
+	require.NoError(t, viper.Unmarshal(&cfg))
+	assert.Equal(t, 9090, cfg.Server.Port)
+	assert.Equal(t, []string{"10.0.0.0/8"}, cfg.Server.TrustedProxies)
+	assert.Equal(t, "https://api.internal/v1", cfg.EndpointConfig.BaseURL)
+
+	require.NoError(t, os.WriteFile(path, []byte("oauth:\n  scopes: [openid, \"${AUTO_MCP_TEST_UNSET}\"]\n"), 0o600))
+	err := mergeExpandedConfig(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "oauth.scopes[1]: environment variable AUTO_MCP_TEST_UNSET is not set")
+}
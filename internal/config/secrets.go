@@ -0,0 +1,123 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// secretFileScheme marks a value read from a file, such as a Docker or
+	// Kubernetes secret mounted under /run/secrets
+	secretFileScheme = "file://"
+	// secretFileSuffix marks an auth_config key holding the path of the file
+	// with the credential, e.g. token_file sets token
+	secretFileSuffix = "_file"
+)
+
+// ResolveSecret returns the contents of the file for "file://<path>" values,
+// without trailing newlines. Other values are returned unchanged.
+func ResolveSecret(value string) (string, error) {
+	path, ok := strings.CutPrefix(value, secretFileScheme)
+	if !ok {
+		return value, nil
+	}
+	return readSecretFile(path)
+}
+
+// secretValue is a credential setting and the field of a Config holding it
+type secretValue struct {
+	key   string
+	value *string
+}
+
+// secretValues returns the credential settings of config outside the
+// auth_config maps. Like auth_config entries, their values may be "file://"
+// references and ${NAME} references in their AUTO_MCP_* variables are expanded.
+func secretValues(config *Config) []secretValue {
+	secrets := []secretValue{
+		{"endpoint.on_behalf_of.signing_secret", &config.EndpointConfig.OnBehalfOf.SigningSecret},
+		{"server.admin.token", &config.Server.Admin.Token},
+		{"secrets.vault.token", &config.Secrets.Vault.Token},
+		{"secrets.vault.secret_id", &config.Secrets.Vault.SecretID},
+	}
+	if config.OAuth != nil {
+		secrets = append(secrets, []secretValue{
+			{"oauth.client_secret", &config.OAuth.ClientSecret},
+			{"oauth.store.redis.password", &config.OAuth.Store.Redis.Password},
+			{"oauth.internal.signing_key", &config.OAuth.Internal.SigningKey},
+		}...)
+	}
+	return secrets
+}
+
+// listIndex matches list indexes in setting paths, e.g. [0] in endpoint.tenants[0]
+var listIndex = regexp.MustCompile(`\[\d+\]`)
+
+// isSecretSetting reports whether the config file setting at path, e.g.
+// endpoint.tenants[0].auth_config.token, holds a credential
+func isSecretSetting(path string) bool {
+	path = strings.ToLower(path)
+	if i := strings.LastIndex(path, "."); i >= 0 {
+		switch listIndex.ReplaceAllString(path[:i], "[]") {
+		case "endpoint.auth_config", "endpoint.tenants[].auth_config":
+			return true
+		}
+	}
+	for _, secret := range secretValues(&Config{OAuth: &OAuthConfig{}}) {
+		if secret.key == path {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveFileKeys replaces keys ending in _file with the contents of the file
+// they point at, in place. field names the config section in error messages.
+func resolveFileKeys(m map[string]string, field string) error {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		name, isPath := strings.CutSuffix(k, secretFileSuffix)
+		if !isPath || name == "" {
+			continue
+		}
+
+		if _, ok := m[name]; ok {
+			return fmt.Errorf("%s: set either %s or %s, not both", field, name, k)
+		}
+		secret, err := readSecretFile(m[k])
+		if err != nil {
+			return fmt.Errorf("%s.%s: %w", field, k, err)
+		}
+		m[name] = secret
+		delete(m, k)
+	}
+	return nil
+}
+
+// expandSecret expands ${NAME} references in value and resolves "file://" values
+func expandSecret(value string) (string, error) {
+	expanded, err := ExpandEnv(value)
+	if err != nil {
+		return "", err
+	}
+	return ResolveSecret(expanded)
+}
+
+func readSecretFile(path string) (string, error) {
+	if path == "" {
+		return "", fmt.Errorf("secret file path is empty")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret file: %w", err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveSecret(t *testing.T) {
+	dir := t.TempDir()
+	tokenPath := filepath.Join(dir, "api_token")
+	require.NoError(t, os.WriteFile(tokenPath, []byte("s3cr3t\n"), 0o600))
+
+	value, err := ResolveSecret("file://" + tokenPath)
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", value)
+	value, err = ResolveSecret("plain")
+	require.NoError(t, err)
+	assert.Equal(t, "plain", value)
+
+	// Other values were resolved when config.yaml was read
+	authConfig := map[string]string{
+		"token_file": tokenPath,
+		"password":   "${NOT_EXPANDED}",
+		"username":   "bot",
+	}
+	require.NoError(t, resolveFileKeys(authConfig, "endpoint.auth_config"))
+	assert.Equal(t, map[string]string{"token": "s3cr3t", "password": "${NOT_EXPANDED}", "username": "bot"}, authConfig)
+
+	err = resolveFileKeys(map[string]string{"token": "x", "token_file": tokenPath}, "endpoint.auth_config")
+	assert.ErrorContains(t, err, "set either token or token_file")
+
+	err = resolveFileKeys(map[string]string{"token_file": filepath.Join(dir, "missing")}, "endpoint.auth_config")
+	assert.ErrorContains(t, err, "endpoint.auth_config.token_file: failed to read secret file")
+}
@@ -0,0 +1,191 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// Configuration value sources, in order of precedence
+const (
+	SourceFlag    = "flag"
+	SourceEnv     = "env"
+	SourceFile    = "file"
+	SourceDefault = "default"
+)
+
+// redactedValue replaces secret values in printed configuration
+const redactedValue = "********"
+
+// secretKeyMarkers are substrings that mark a configuration key as sensitive
+var secretKeyMarkers = []string{"secret", "token", "password", "passwd", "authorization", "cookie", "credential"}
+
+// Setting is a single effective configuration value and where it came from
+type Setting struct {
+	Key    string
+	Value  string
+	Source string
+}
+
+// EffectiveSettings returns every configuration key set after Load, by a
+// flag, a variable, a file or a default, with secrets redacted and the source
+// that supplied each value
+func EffectiveSettings() []Setting {
+	keys := viper.AllKeys()
+	sort.Strings(keys)
+
+	settings := make([]Setting, 0, len(keys))
+	for _, key := range keys {
+		value := viper.Get(key)
+		if value == nil {
+			// Bound to an AUTO_MCP_* variable that is not set
+			continue
+		}
+		if !plainSetting(key) {
+			value = redact(key, value)
+		}
+		settings = append(settings, Setting{
+			Key:    key,
+			Value:  fmt.Sprintf("%v", value),
+			Source: settingSource(key),
+		})
+	}
+	return settings
+}
+
+// WriteEffectiveSettings prints the effective configuration to w
+func WriteEffectiveSettings(w io.Writer) error {
+	if file := viper.ConfigFileUsed(); file != "" {
+		if _, err := fmt.Fprintf(w, "# config file: %s\n", file); err != nil {
+			return err
+		}
+	}
+	for _, s := range EffectiveSettings() {
+		if _, err := fmt.Fprintf(w, "%s = %s (%s)\n", s.Key, s.Value, s.Source); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// redact returns a copy of value with secrets replaced, including those
+// nested in lists and maps such as endpoint.tenants[].auth_config
+func redact(key string, value any) any {
+	switch value.(type) {
+	case bool, int, int64, float64:
+		// Flags and counts such as forward_auth_token are never secret
+		return value
+	}
+	if IsSecretKey(key) && fmt.Sprintf("%v", value) != "" {
+		return redactedValue
+	}
+	switch v := value.(type) {
+	case map[string]any:
+		redacted := make(map[string]any, len(v))
+		for k, item := range v {
+			redacted[k] = redact(k, item)
+		}
+		return redacted
+	case map[string]string:
+		redacted := make(map[string]any, len(v))
+		for k, item := range v {
+			redacted[k] = redact(k, item)
+		}
+		return redacted
+	case []any:
+		redacted := make([]any, len(v))
+		for i, item := range v {
+			// List items are checked by their own keys
+			redacted[i] = redact("", item)
+		}
+		return redacted
+	}
+	return value
+}
+
+// IsSecretKey reports whether a configuration key holds a sensitive value
+func IsSecretKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, marker := range secretKeyMarkers {
+		if strings.Contains(key, marker) {
+			return true
+		}
+	}
+	last := key[strings.LastIndexAny(key, "._-")+1:]
+	return last == "key" || strings.HasSuffix(key, "api_key") || strings.HasSuffix(key, "api-key")
+}
+
+// plainSetting reports whether key is a boolean or numeric field of Config.
+// Such settings are never secret, even when their name looks like one and
+// an AUTO_MCP_* variable set them as a string.
+func plainSetting(key string) bool {
+	t := reflect.TypeOf(Config{})
+	for _, name := range strings.Split(key, ".") {
+		if t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			return false
+		}
+		field, ok := fieldByTag(t, name)
+		if !ok {
+			return false
+		}
+		t = field.Type
+	}
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// fieldByTag returns the field of t decoded from the mapstructure key name
+func fieldByTag(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if tag, _, _ := strings.Cut(field.Tag.Get("mapstructure"), ","); tag == name {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// settingSource determines which layer supplied the value for key
+func settingSource(key string) string {
+	name := key
+	if flag, ok := flagKeys[key]; ok {
+		name = flag
+	}
+	if flag := pflag.CommandLine.Lookup(name); flag != nil && flag.Changed {
+		return SourceFlag
+	}
+	if strings.HasPrefix(key, "endpoint.cassette.") {
+		for _, name := range cassetteFlags {
+			if flag := pflag.CommandLine.Lookup(name); flag != nil && flag.Changed {
+				return SourceFlag
+			}
+		}
+		for _, name := range cassetteFlags {
+			if cliValue(name) != "" {
+				return SourceEnv
+			}
+		}
+	}
+	envKey := "AUTO_MCP_" + strings.ToUpper(envKeyReplacer.Replace(key))
+	if _, ok := os.LookupEnv(envKey); ok {
+		return SourceEnv
+	}
+	if viper.InConfig(key) {
+		return SourceFile
+	}
+	return SourceDefault
+}
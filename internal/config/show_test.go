@@ -0,0 +1,127 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteEffectiveSettings_RedactsNestedSecrets(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+endpoint:
+  forward_auth_token: true
+  auth_config:
+    username: bot
+    password: top-level-secret
+  tenants:
+    - name: acme
+      auth_config:
+        token: tenant-secret
+      headers:
+        X-Api-Key: header-secret
+oauth:
+  internal:
+    users:
+      - username: alice
+        password_hash: hash-secret
+plugins:
+  - name: audit
+    config:
+      webhook_token: plugin-secret
+`), 0o600))
+
+	viper.Reset()
+	defer viper.Reset()
+	viper.SetConfigFile(path)
+	require.NoError(t, viper.ReadInConfig())
+
+	var out bytes.Buffer
+	require.NoError(t, WriteEffectiveSettings(&out))
+	for _, secret := range []string{"top-level-secret", "tenant-secret", "header-secret", "hash-secret", "plugin-secret"} {
+		assert.NotContains(t, out.String(), secret)
+	}
+	assert.Contains(t, out.String(), "endpoint.auth_config.username = bot (file)")
+	assert.Contains(t, out.String(), "name:acme")
+	assert.Contains(t, out.String(), "username:alice")
+	assert.Contains(t, out.String(), "token:"+redactedValue)
+	assert.Contains(t, out.String(), "endpoint.forward_auth_token = true (file)")
+}
+
+func TestEffectiveSettings_KeepsPlainSettings(t *testing.T) {
+	t.Setenv("AUTO_MCP_SWAGGER_FILE", "/server/swagger.json")
+	t.Setenv("AUTO_MCP_ENDPOINT_FORWARD_AUTH_TOKEN", "true")
+	t.Setenv("AUTO_MCP_OAUTH_CLIENT_SECRET", "env-secret")
+	defer viper.Reset()
+	_, err := Load()
+	require.NoError(t, err)
+
+	settings := make(map[string]Setting)
+	for _, setting := range EffectiveSettings() {
+		settings[setting.Key] = setting
+	}
+	assert.Equal(t, Setting{Key: "endpoint.forward_auth_token", Value: "true", Source: SourceEnv}, settings["endpoint.forward_auth_token"])
+	assert.Equal(t, redactedValue, settings["oauth.client_secret"].Value)
+}
+
+func TestSettingSource_Flags(t *testing.T) {
+	if pflag.CommandLine.Lookup("port") == nil {
+		InitFlags()
+	}
+	port := pflag.CommandLine.Lookup("port")
+	defer func(value string) {
+		_ = port.Value.Set(value)
+		port.Changed = false
+	}(port.Value.String())
+	require.NoError(t, pflag.CommandLine.Set("port", "9090"))
+	t.Setenv("AUTO_MCP_SERVER_PORT", "7070")
+	t.Setenv("AUTO_MCP_SERVER_HOST", "127.0.0.1")
+
+	viper.Reset()
+	defer viper.Reset()
+
+	// Flags named differently from their key take precedence over the environment
+	assert.Equal(t, SourceFlag, settingSource("server.port"))
+	assert.Equal(t, SourceEnv, settingSource("server.host"))
+	assert.Equal(t, SourceDefault, settingSource("endpoint.base_url"))
+}
+
+func TestEffectiveSettings_FlagsAndEnvironment(t *testing.T) {
+	if pflag.CommandLine.Lookup("port") == nil {
+		InitFlags()
+	}
+	for name, value := range map[string]string{"base-url": "https://flag.example.com", "swagger-file": "/flag/swagger.json", "record": "/tmp/cassette"} {
+		flag := pflag.CommandLine.Lookup(name)
+		defer func(value string) {
+			_ = flag.Value.Set(value)
+			flag.Changed = false
+		}(flag.Value.String())
+		require.NoError(t, pflag.CommandLine.Set(name, value))
+	}
+	t.Setenv("AUTO_MCP_SERVER_COMPRESSION", "true")
+	t.Setenv("AUTO_MCP_ENDPOINT_HEADERS_X_CUSTOM", "custom")
+	defer viper.Reset()
+	_, err := Load()
+	require.NoError(t, err)
+
+	settings := make(map[string]Setting)
+	for _, setting := range EffectiveSettings() {
+		settings[setting.Key] = setting
+	}
+	for _, flag := range []string{"base-url", "base_url", "swagger-file", "adjustments-file", "mode", "port", "record", "replay", "watch-config", "watch_config"} {
+		assert.NotContains(t, settings, flag, "flags are listed under their configuration key")
+	}
+	assert.Equal(t, Setting{Key: "endpoint.base_url", Value: "https://flag.example.com", Source: SourceFlag}, settings["endpoint.base_url"])
+	assert.Equal(t, Setting{Key: "swagger_file", Value: "/flag/swagger.json", Source: SourceFlag}, settings["swagger_file"])
+	assert.Equal(t, Setting{Key: "server.mode", Value: "stdio", Source: SourceDefault}, settings["server.mode"])
+	assert.Equal(t, Setting{Key: "endpoint.cassette.dir", Value: "/tmp/cassette", Source: SourceFlag}, settings["endpoint.cassette.dir"])
+	assert.Equal(t, Setting{Key: "server.compression", Value: "true", Source: SourceEnv}, settings["server.compression"])
+	assert.Equal(t, Setting{Key: "endpoint.headers.x_custom", Value: "custom", Source: SourceEnv}, settings["endpoint.headers.x_custom"])
+	assert.NotContains(t, settings, "server.tool_prefix", "unset settings are left out")
+}
@@ -0,0 +1,235 @@
+// Package describer rewrites terse or missing operation descriptions into
+// tool descriptions with an LLM, through an OpenAI or Anthropic compatible
+// endpoint configured by the user.
+package describer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brizzai/auto-mcp/internal/parser"
+)
+
+// Providers are the API flavors a Describer speaks
+const (
+	ProviderOpenAI    = "openai"
+	ProviderAnthropic = "anthropic"
+)
+
+// defaultURLs are the endpoints used when none is configured
+var defaultURLs = map[string]string{
+	ProviderOpenAI:    "https://api.openai.com/v1",
+	ProviderAnthropic: "https://api.anthropic.com",
+}
+
+// terseWords is the word count under which a description is rewritten
+const terseWords = 6
+
+// maxSchemaBytes caps the input schema sent with a route
+const maxSchemaBytes = 4000
+
+// requestTimeout bounds a rewrite
+const requestTimeout = 60 * time.Second
+
+const systemPrompt = `You write the descriptions of the tools an AI assistant calls, each tool
+sending one HTTP API operation. Reply with the description only, without
+markdown: one to three sentences saying what the operation does, when to use
+it and what it returns. Do not invent behavior the operation does not show.`
+
+// Config selects the endpoint and model descriptions are written with
+type Config struct {
+	// Provider is openai (chat completions) or anthropic (messages)
+	Provider string
+	// URL defaults to the provider's public API
+	URL    string
+	Model  string
+	APIKey string
+}
+
+// Describer writes tool descriptions with an LLM
+type Describer struct {
+	cfg    Config
+	client *http.Client
+}
+
+// New creates a Describer for cfg
+func New(cfg Config) (*Describer, error) {
+	if _, ok := defaultURLs[cfg.Provider]; !ok {
+		return nil, fmt.Errorf("unknown LLM provider %q, use %s or %s", cfg.Provider, ProviderOpenAI, ProviderAnthropic)
+	}
+	if cfg.Model == "" {
+		return nil, fmt.Errorf("an LLM model is required")
+	}
+	if cfg.URL == "" {
+		cfg.URL = defaultURLs[cfg.Provider]
+	}
+	cfg.URL = strings.TrimSuffix(cfg.URL, "/")
+	return &Describer{cfg: cfg, client: &http.Client{Timeout: requestTimeout}}, nil
+}
+
+// Terse reports whether description is missing or too short to tell a model
+// when to call the tool
+func Terse(description string) bool {
+	return len(strings.Fields(description)) < terseWords
+}
+
+// Describe returns a tool description written for route
+func (d *Describer) Describe(ctx context.Context, route *parser.RouteTool) (string, error) {
+	prompt := routePrompt(route)
+	var text string
+	var err error
+	if d.cfg.Provider == ProviderAnthropic {
+		text, err = d.anthropic(ctx, prompt)
+	} else {
+		text, err = d.openAI(ctx, prompt)
+	}
+	if err != nil {
+		return "", err
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", fmt.Errorf("the LLM returned an empty description")
+	}
+	return text, nil
+}
+
+// DescribeAll writes descriptions for routes, sending up to concurrency requests at once. done is called after every
+// route when not nil, from any goroutine. Routes failing are left out and
+// their errors returned.
+func (d *Describer) DescribeAll(ctx context.Context, routes []*parser.RouteTool, concurrency int, done func()) (map[*parser.RouteTool]string, []error) {
+	var (
+		mu           sync.Mutex
+		wg           sync.WaitGroup
+		descriptions = make(map[*parser.RouteTool]string)
+		errs         []error
+		slots        = make(chan struct{}, max(concurrency, 1))
+	)
+	for _, route := range routes {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func() {
+			defer func() {
+				<-slots
+				if done != nil {
+					done()
+				}
+				wg.Done()
+			}()
+			description, err := d.Describe(ctx, route)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s %s: %w", route.RouteConfig.Method, route.RouteConfig.Path, err))
+				return
+			}
+			descriptions[route] = description
+		}()
+	}
+	wg.Wait()
+	return descriptions, errs
+}
+
+// routePrompt describes the operation of route to the LLM
+func routePrompt(route *parser.RouteTool) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Operation: %s %s\n", route.RouteConfig.Method, route.RouteConfig.Path)
+	if len(route.RouteConfig.Tags) > 0 {
+		fmt.Fprintf(&b, "Tags: %s\n", strings.Join(route.RouteConfig.Tags, ", "))
+	}
+	if description := strings.TrimSpace(route.RouteConfig.Description); description != "" {
+		fmt.Fprintf(&b, "Current description: %s\n", description)
+	}
+	if schema, err := json.Marshal(route.Tool.InputSchema); err == nil {
+		if len(schema) > maxSchemaBytes {
+			schema = append(schema[:maxSchemaBytes], "..."...)
+		}
+		fmt.Fprintf(&b, "Input schema: %s\n", schema)
+	}
+	return b.String()
+}
+
+// openAI sends prompt to a chat completions endpoint
+func (d *Describer) openAI(ctx context.Context, prompt string) (string, error) {
+	body := map[string]any{
+		"model": d.cfg.Model,
+		"messages": []map[string]string{
+			{"role": "system", "content": systemPrompt},
+			{"role": "user", "content": prompt},
+		},
+	}
+	var response struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+	header := http.Header{"Authorization": {"Bearer " + d.cfg.APIKey}}
+	if err := d.post(ctx, d.cfg.URL+"/chat/completions", header, body, &response); err != nil {
+		return "", err
+	}
+	if len(response.Choices) == 0 {
+		return "", fmt.Errorf("the LLM returned no choices")
+	}
+	return response.Choices[0].Message.Content, nil
+}
+
+// anthropic sends prompt to a messages endpoint
+func (d *Describer) anthropic(ctx context.Context, prompt string) (string, error) {
+	body := map[string]any{
+		"model":      d.cfg.Model,
+		"max_tokens": 300,
+		"system":     systemPrompt,
+		"messages": []map[string]string{
+			{"role": "user", "content": prompt},
+		},
+	}
+	var response struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+	}
+	header := http.Header{"X-Api-Key": {d.cfg.APIKey}, "Anthropic-Version": {"2023-06-01"}}
+	if err := d.post(ctx, d.cfg.URL+"/v1/messages", header, body, &response); err != nil {
+		return "", err
+	}
+	var text strings.Builder
+	for _, block := range response.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	return text.String(), nil
+}
+
+// post sends body as JSON to url and decodes the JSON response into result
+func (d *Describer) post(ctx context.Context, url string, header http.Header, body, result any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header = header
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call the LLM: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("the LLM returned %s: %s", resp.Status, strings.TrimSpace(string(message)))
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
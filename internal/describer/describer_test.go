@@ -0,0 +1,106 @@
+package describer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/brizzai/auto-mcp/internal/parser"
+	"github.com/brizzai/auto-mcp/internal/requester"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func route(method, path, description string) *parser.RouteTool {
+	return &parser.RouteTool{
+		RouteConfig: &requester.RouteConfig{Method: method, Path: path, Description: description, Tags: []string{"pet"}},
+		Tool:        mcp.NewTool("tool", mcp.WithString("petId", mcp.Required())),
+	}
+}
+
+func TestDescriber_OpenAI(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/chat/completions", r.URL.Path)
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		var body struct {
+			Model    string `json:"model"`
+			Messages []struct {
+				Role    string `json:"role"`
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "gpt-test", body.Model)
+		require.Len(t, body.Messages, 2)
+		assert.Equal(t, "Operation: GET /pets/{petId}\nTags: pet\nCurrent description: Get pet\nInput schema: {\"properties\":{\"petId\":{\"type\":\"string\"}},\"required\":[\"petId\"],\"type\":\"object\"}\n", body.Messages[1].Content)
+		_, _ = w.Write([]byte(`{"choices": [{"message": {"content": " Returns the pet with the given ID. \n"}}]}`))
+	}))
+	defer server.Close()
+
+	d, err := New(Config{Provider: ProviderOpenAI, URL: server.URL + "/v1/", Model: "gpt-test", APIKey: "secret"})
+	require.NoError(t, err)
+	description, err := d.Describe(context.Background(), route("GET", "/pets/{petId}", "Get pet"))
+	require.NoError(t, err)
+	assert.Equal(t, "Returns the pet with the given ID.", description)
+}
+
+func TestDescriber_Anthropic(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/messages", r.URL.Path)
+		assert.Equal(t, "secret", r.Header.Get("X-Api-Key"))
+		assert.NotEmpty(t, r.Header.Get("Anthropic-Version"))
+		_, _ = w.Write([]byte(`{"content": [{"type": "text", "text": "Deletes the pet."}]}`))
+	}))
+	defer server.Close()
+
+	d, err := New(Config{Provider: ProviderAnthropic, URL: server.URL, Model: "claude-test", APIKey: "secret"})
+	require.NoError(t, err)
+	description, err := d.Describe(context.Background(), route("DELETE", "/pets/{petId}", ""))
+	require.NoError(t, err)
+	assert.Equal(t, "Deletes the pet.", description)
+}
+
+func TestDescriber_DescribeAll(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if strings.Contains(body.Messages[1].Content, "DELETE") {
+			http.Error(w, "rate limited", http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(`{"choices": [{"message": {"content": "Lists pets."}}]}`))
+	}))
+	defer server.Close()
+
+	d, err := New(Config{Provider: ProviderOpenAI, URL: server.URL, Model: "gpt-test"})
+	require.NoError(t, err)
+	list, remove := route("GET", "/pets", ""), route("DELETE", "/pets", "")
+	var done atomic.Int32
+	descriptions, errs := d.DescribeAll(context.Background(), []*parser.RouteTool{list, remove}, 2, func() { done.Add(1) })
+	assert.Equal(t, map[*parser.RouteTool]string{list: "Lists pets."}, descriptions)
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "DELETE /pets: the LLM returned 429 Too Many Requests: rate limited")
+	assert.Equal(t, int32(2), done.Load())
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(Config{Provider: "mistral", Model: "m"})
+	assert.EqualError(t, err, `unknown LLM provider "mistral", use openai or anthropic`)
+	_, err = New(Config{Provider: ProviderOpenAI})
+	assert.EqualError(t, err, "an LLM model is required")
+}
+
+func TestTerse(t *testing.T) {
+	assert.True(t, Terse(""))
+	assert.True(t, Terse("Get a pet by ID"))
+	assert.False(t, Terse("Returns a single pet by its ID, with its tags"))
+}
@@ -0,0 +1,355 @@
+// Package htmltext converts HTML documents into Markdown or plain text that is
+// cheap for a model to read. It is a lenient, dependency free converter meant
+// for documentation pages and reports, not a spec compliant HTML parser.
+package htmltext
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Format selects the output of Convert
+type Format string
+
+const (
+	FormatMarkdown Format = "markdown"
+	FormatText     Format = "text"
+)
+
+// rawTextElements have content that must not be parsed as markup
+var rawTextElements = map[string]bool{"script": true, "style": true}
+
+// hiddenElements are dropped together with everything they contain
+var hiddenElements = map[string]bool{"head": true, "noscript": true, "template": true, "svg": true}
+
+// blockElements start and end on their own paragraph
+var blockElements = map[string]bool{
+	"p": true, "div": true, "section": true, "article": true, "header": true, "footer": true,
+	"main": true, "nav": true, "aside": true, "table": true, "ul": true, "ol": true,
+	"blockquote": true, "pre": true, "dl": true, "form": true, "figure": true, "address": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+}
+
+var (
+	attrPattern      = regexp.MustCompile(`([a-zA-Z_:][-a-zA-Z0-9_:.]*)\s*(?:=\s*("[^"]*"|'[^']*'|[^\s"'>]+))?`)
+	spacePattern     = regexp.MustCompile(`[ \t\r\n\f]+`)
+	blankLinePattern = regexp.MustCompile(`\n{3,}`)
+)
+
+// IsHTML reports whether a Content-Type header value denotes an HTML document
+func IsHTML(contentType string) bool {
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	return mediaType == "text/html" || mediaType == "application/xhtml+xml"
+}
+
+// Convert renders src in the requested format
+func Convert(src string, format Format) string {
+	c := &converter{markdown: format != FormatText}
+	c.run(src)
+	return c.result()
+}
+
+type listState struct {
+	ordered bool
+	index   int
+}
+
+type converter struct {
+	markdown bool
+	out      strings.Builder
+
+	hidden    string // name of the hidden element being skipped
+	hiddenLvl int
+	preDepth  int
+	lists     []listState
+	links     []string // hrefs of the open <a> elements
+	cell      int
+}
+
+func (c *converter) run(src string) {
+	for len(src) > 0 {
+		lt := strings.IndexByte(src, '<')
+		if lt == -1 {
+			c.text(src)
+			return
+		}
+		if lt > 0 {
+			c.text(src[:lt])
+			src = src[lt:]
+		}
+
+		switch {
+		case strings.HasPrefix(src, "<!--"):
+			src = skipPast(src, "-->")
+		case strings.HasPrefix(src, "<!"), strings.HasPrefix(src, "<?"):
+			src = skipPast(src, ">")
+		case len(src) > 1 && (isLetter(src[1]) || src[1] == '/'):
+			end := tagEnd(src)
+			name, attrs, closing := parseTag(src[1:end])
+			src = src[min(end+1, len(src)):]
+			if !closing && rawTextElements[name] {
+				src = skipPast(src, "</"+name)
+				src = skipPast(src, ">")
+				continue
+			}
+			c.tag(name, attrs, closing)
+		default:
+			c.text("<")
+			src = src[1:]
+		}
+	}
+}
+
+func (c *converter) tag(name, attrs string, closing bool) {
+	// Skip hidden subtrees, counting nested elements of the same name
+	if c.hidden != "" {
+		if name == c.hidden {
+			if closing {
+				c.hiddenLvl--
+			} else {
+				c.hiddenLvl++
+			}
+			if c.hiddenLvl == 0 {
+				c.hidden = ""
+			}
+		}
+		return
+	}
+	if hiddenElements[name] {
+		if !closing {
+			c.hidden, c.hiddenLvl = name, 1
+		}
+		return
+	}
+
+	switch name {
+	case "br":
+		c.write("\n")
+	case "hr":
+		c.paragraph()
+		if c.markdown {
+			c.write("---")
+		}
+		c.paragraph()
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		c.paragraph()
+		if !closing && c.markdown {
+			level, _ := strconv.Atoi(name[1:])
+			c.write(strings.Repeat("#", level) + " ")
+		}
+	case "pre":
+		if !closing {
+			c.paragraph()
+			if c.markdown {
+				c.write("```\n")
+			}
+			c.preDepth++
+			return
+		}
+		c.preDepth = max(c.preDepth-1, 0)
+		if c.markdown {
+			c.newline()
+			c.write("```")
+		}
+		c.paragraph()
+	case "ul", "ol":
+		if closing {
+			if len(c.lists) > 0 {
+				c.lists = c.lists[:len(c.lists)-1]
+			}
+		} else {
+			c.lists = append(c.lists, listState{ordered: name == "ol"})
+		}
+		if len(c.lists) == 0 {
+			c.paragraph()
+		} else {
+			c.newline()
+		}
+	case "li":
+		c.newline()
+		if !closing {
+			c.listItem()
+		}
+	case "tr":
+		c.newline()
+		c.cell = 0
+	case "td", "th":
+		if !closing {
+			if c.cell > 0 {
+				c.write(" | ")
+			}
+			c.cell++
+		}
+	case "dt", "dd":
+		c.newline()
+	case "a":
+		c.link(attrs, closing)
+	case "img":
+		alt := attr(attrs, "alt")
+		if c.markdown {
+			if src := attr(attrs, "src"); src != "" {
+				c.write("![" + alt + "](" + src + ")")
+			}
+		} else if alt != "" {
+			c.write(alt)
+		}
+	case "strong", "b":
+		c.emphasis("**")
+	case "em", "i":
+		c.emphasis("_")
+	case "code":
+		if c.preDepth == 0 {
+			c.emphasis("`")
+		}
+	default:
+		if blockElements[name] {
+			c.paragraph()
+		}
+	}
+}
+
+func (c *converter) listItem() {
+	indent := strings.Repeat("  ", max(len(c.lists)-1, 0))
+	if len(c.lists) > 0 && c.lists[len(c.lists)-1].ordered {
+		c.lists[len(c.lists)-1].index++
+		c.write(indent + strconv.Itoa(c.lists[len(c.lists)-1].index) + ". ")
+		return
+	}
+	if c.markdown {
+		c.write(indent + "- ")
+	} else {
+		c.write(indent + "• ")
+	}
+}
+
+func (c *converter) link(attrs string, closing bool) {
+	if !closing {
+		href := attr(attrs, "href")
+		c.links = append(c.links, href)
+		if c.markdown && href != "" {
+			c.write("[")
+		}
+		return
+	}
+	if len(c.links) == 0 {
+		return
+	}
+	href := c.links[len(c.links)-1]
+	c.links = c.links[:len(c.links)-1]
+	if c.markdown && href != "" {
+		c.write("](" + href + ")")
+	}
+}
+
+func (c *converter) emphasis(marker string) {
+	if c.markdown {
+		c.write(marker)
+	}
+}
+
+func (c *converter) text(raw string) {
+	if c.hidden != "" {
+		return
+	}
+	text := html.UnescapeString(raw)
+	if c.preDepth > 0 {
+		c.write(text)
+		return
+	}
+	text = spacePattern.ReplaceAllString(text, " ")
+	if c.atLineStart() || strings.HasSuffix(c.out.String(), " ") {
+		text = strings.TrimLeft(text, " ")
+	}
+	c.write(text)
+}
+
+func (c *converter) write(s string) {
+	c.out.WriteString(s)
+}
+
+func (c *converter) atLineStart() bool {
+	s := c.out.String()
+	return s == "" || strings.HasSuffix(s, "\n")
+}
+
+// newline ends the current line unless it is already empty
+func (c *converter) newline() {
+	if !c.atLineStart() {
+		c.write("\n")
+	}
+}
+
+// paragraph ensures the next output starts after a blank line
+func (c *converter) paragraph() {
+	c.newline()
+	if s := c.out.String(); s != "" && !strings.HasSuffix(s, "\n\n") {
+		c.write("\n")
+	}
+}
+
+func (c *converter) result() string {
+	lines := strings.Split(c.out.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	out := blankLinePattern.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+	return strings.TrimSpace(out)
+}
+
+// parseTag splits the inside of a tag into its lower-cased name and attributes
+func parseTag(inner string) (name, attrs string, closing bool) {
+	if strings.HasPrefix(inner, "/") {
+		closing = true
+		inner = inner[1:]
+	}
+	inner = strings.TrimSuffix(inner, "/")
+	end := strings.IndexAny(inner, " \t\r\n/")
+	if end == -1 {
+		return strings.ToLower(inner), "", closing
+	}
+	return strings.ToLower(inner[:end]), inner[end:], closing
+}
+
+// attr returns the unescaped value of the named attribute
+func attr(attrs, name string) string {
+	for _, m := range attrPattern.FindAllStringSubmatch(attrs, -1) {
+		if strings.EqualFold(m[1], name) {
+			return html.UnescapeString(strings.Trim(m[2], `"'`))
+		}
+	}
+	return ""
+}
+
+// tagEnd returns the index of the '>' closing the tag at the start of src,
+// ignoring '>' inside quoted attribute values
+func tagEnd(src string) int {
+	var quote byte
+	for i := 1; i < len(src); i++ {
+		switch ch := src[i]; {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '"' || ch == '\'':
+			quote = ch
+		case ch == '>':
+			return i
+		}
+	}
+	return len(src)
+}
+
+// skipPast returns src after the first case-insensitive occurrence of marker
+func skipPast(src, marker string) string {
+	idx := strings.Index(strings.ToLower(src), marker)
+	if idx == -1 {
+		return ""
+	}
+	return src[idx+len(marker):]
+}
+
+func isLetter(ch byte) bool {
+	return ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z'
+}
@@ -0,0 +1,63 @@
+package htmltext
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const page = `<!DOCTYPE html>
+<html>
+<head><title>Report</title><style>body { color: red; }</style></head>
+<body>
+  <script>if (a < b) { alert("x"); }</script>
+  <h1>Monthly   report</h1>
+  <!-- generated -->
+  <p>Revenue is <strong>up</strong> &amp; costs are <em>down</em>.
+  See <a href="https://example.com/details?a=1&amp;b=2">details</a>.</p>
+  <ul>
+    <li>First</li>
+    <li>Second
+      <ol><li>Nested</li></ol>
+    </li>
+  </ul>
+  <pre>line 1
+  line 2</pre>
+  <table><tr><th>Name</th><th>Total</th></tr><tr><td>A</td><td>1</td></tr></table>
+</body>
+</html>`
+
+func TestConvertMarkdown(t *testing.T) {
+	expected := "# Monthly report\n\n" +
+		"Revenue is **up** & costs are _down_. See [details](https://example.com/details?a=1&b=2).\n\n" +
+		"- First\n" +
+		"- Second\n" +
+		"  1. Nested\n\n" +
+		"```\nline 1\n  line 2\n```\n\n" +
+		"Name | Total\n" +
+		"A | 1"
+	assert.Equal(t, expected, Convert(page, FormatMarkdown))
+}
+
+func TestConvertText(t *testing.T) {
+	expected := "Monthly report\n\n" +
+		"Revenue is up & costs are down. See details.\n\n" +
+		"• First\n" +
+		"• Second\n" +
+		"  1. Nested\n\n" +
+		"line 1\n  line 2\n\n" +
+		"Name | Total\n" +
+		"A | 1"
+	assert.Equal(t, expected, Convert(page, FormatText))
+}
+
+func TestConvertKeepsStrayAngleBrackets(t *testing.T) {
+	assert.Equal(t, "1 < 2 and 3 > 2", Convert("<p>1 < 2 and 3 &gt; 2</p>", FormatText))
+}
+
+func TestIsHTML(t *testing.T) {
+	assert.True(t, IsHTML("text/html; charset=utf-8"))
+	assert.True(t, IsHTML("application/xhtml+xml"))
+	assert.False(t, IsHTML("application/json"))
+	assert.False(t, IsHTML(""))
+}
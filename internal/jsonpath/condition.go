@@ -0,0 +1,127 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Condition is a compiled boolean expression over a JSON document. Supported forms:
+//
+//	$.status == "ok"
+//	$.error != null
+//	$.ok          (value exists and is truthy)
+//	!$.error      (value is missing or falsy)
+type Condition struct {
+	raw      string
+	path     *Path
+	operator string
+	literal  interface{}
+	negate   bool
+}
+
+// ParseCondition compiles a condition expression
+func ParseCondition(raw string) (*Condition, error) {
+	expr := strings.TrimSpace(raw)
+	if expr == "" {
+		return nil, fmt.Errorf("empty condition")
+	}
+	c := &Condition{raw: raw}
+
+	for _, op := range []string{"==", "!="} {
+		if idx := strings.Index(expr, op); idx != -1 {
+			path, err := Compile(strings.TrimSpace(expr[:idx]))
+			if err != nil {
+				return nil, err
+			}
+			literal, err := parseLiteral(strings.TrimSpace(expr[idx+len(op):]))
+			if err != nil {
+				return nil, fmt.Errorf("invalid condition %q: %w", raw, err)
+			}
+			c.path, c.operator, c.literal = path, op, literal
+			return c, nil
+		}
+	}
+
+	if strings.HasPrefix(expr, "!") {
+		c.negate = true
+		expr = strings.TrimSpace(expr[1:])
+	}
+	path, err := Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	c.path = path
+	return c, nil
+}
+
+// String returns the original expression
+func (c *Condition) String() string {
+	return c.raw
+}
+
+// Eval evaluates the condition against a decoded JSON document
+func (c *Condition) Eval(doc interface{}) bool {
+	value, found := c.path.Lookup(doc)
+	switch c.operator {
+	case "==":
+		return found && equal(value, c.literal)
+	case "!=":
+		return !found || !equal(value, c.literal)
+	default:
+		truthy := found && isTruthy(value)
+		return truthy != c.negate
+	}
+}
+
+// parseLiteral decodes the right-hand side of a comparison. Single-quoted
+// strings are accepted for convenience in YAML.
+func parseLiteral(s string) (interface{}, error) {
+	if unquoted, ok := unquote(s); ok && s[0] == '\'' {
+		return unquoted, nil
+	}
+	value, err := Decode([]byte(s))
+	if err != nil {
+		return nil, fmt.Errorf("bad literal %q", s)
+	}
+	return value, nil
+}
+
+func equal(a, b interface{}) bool {
+	an, aIsNum := a.(json.Number)
+	bn, bIsNum := b.(json.Number)
+	if aIsNum && bIsNum {
+		af, errA := an.Float64()
+		bf, errB := bn.Float64()
+		if errA == nil && errB == nil {
+			return af == bf
+		}
+		return an.String() == bn.String()
+	}
+	switch av := a.(type) {
+	case string, bool, nil:
+		return av == b
+	default:
+		return false
+	}
+}
+
+func isTruthy(v interface{}) bool {
+	switch val := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return val
+	case string:
+		return val != ""
+	case json.Number:
+		f, err := val.Float64()
+		return err != nil || f != 0
+	case []interface{}:
+		return len(val) > 0
+	case map[string]interface{}:
+		return len(val) > 0
+	default:
+		return true
+	}
+}
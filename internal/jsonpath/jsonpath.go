@@ -0,0 +1,179 @@
+// Package jsonpath implements the small subset of JSONPath used by adjustments:
+// dotted member access ($.a.b), bracketed members ($["a-b"]) and array
+// indexes ($.items[0]), plus simple comparison conditions on those paths.
+package jsonpath
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// segment is a single step in a path: either an object member or an array index
+type segment struct {
+	key   string
+	index int
+	isIdx bool
+}
+
+// Path is a compiled JSONPath expression
+type Path struct {
+	raw      string
+	segments []segment
+}
+
+// Compile parses a path such as "$.data.items[0].id". The leading "$" is optional.
+func Compile(raw string) (*Path, error) {
+	s := strings.TrimSpace(raw)
+	s = strings.TrimPrefix(s, "$")
+	p := &Path{raw: raw}
+
+	for len(s) > 0 {
+		switch s[0] {
+		case '.':
+			s = s[1:]
+			end := strings.IndexAny(s, ".[")
+			if end == -1 {
+				end = len(s)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("invalid path %q: empty member name", raw)
+			}
+			p.segments = append(p.segments, segment{key: s[:end]})
+			s = s[end:]
+		case '[':
+			end := strings.IndexByte(s, ']')
+			if end == -1 {
+				return nil, fmt.Errorf("invalid path %q: missing ]", raw)
+			}
+			inner := strings.TrimSpace(s[1:end])
+			s = s[end+1:]
+			if unquoted, ok := unquote(inner); ok {
+				p.segments = append(p.segments, segment{key: unquoted})
+				continue
+			}
+			idx, err := strconv.Atoi(inner)
+			if err != nil {
+				return nil, fmt.Errorf("invalid path %q: bad index %q", raw, inner)
+			}
+			p.segments = append(p.segments, segment{index: idx, isIdx: true})
+		default:
+			// Allow a bare leading member name, e.g. "data.items"
+			if len(p.segments) == 0 && raw != "" && !strings.HasPrefix(strings.TrimSpace(raw), "$") {
+				s = "." + s
+				continue
+			}
+			return nil, fmt.Errorf("invalid path %q: unexpected %q", raw, s[0])
+		}
+	}
+	return p, nil
+}
+
+// String returns the original path expression
+func (p *Path) String() string {
+	return p.raw
+}
+
+// Lookup returns the value at the path within doc, which must be a decoded
+// JSON value (maps, slices and scalars)
+func (p *Path) Lookup(doc interface{}) (interface{}, bool) {
+	current := doc
+	for _, seg := range p.segments {
+		if seg.isIdx {
+			arr, ok := current.([]interface{})
+			if !ok {
+				return nil, false
+			}
+			idx := seg.index
+			if idx < 0 {
+				idx += len(arr)
+			}
+			if idx < 0 || idx >= len(arr) {
+				return nil, false
+			}
+			current = arr[idx]
+			continue
+		}
+		obj, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = obj[seg.key]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// Without returns doc without the object member at the path, removed from
+// its parent object. Objects and arrays along the path are copied and the
+// rest is shared with doc, which is left unchanged. Objects emptied by the
+// removal are removed as well. Paths ending in an array index, or missing
+// from doc, return doc as is.
+func (p *Path) Without(doc interface{}) interface{} {
+	return without(doc, p.segments)
+}
+
+func without(current interface{}, segments []segment) interface{} {
+	if len(segments) == 0 {
+		return current
+	}
+	seg := segments[0]
+	if seg.isIdx {
+		arr, ok := current.([]interface{})
+		idx := seg.index
+		if ok && idx < 0 {
+			idx += len(arr)
+		}
+		if !ok || idx < 0 || idx >= len(arr) || len(segments) == 1 {
+			return current
+		}
+		out := append([]interface{}(nil), arr...)
+		out[idx] = without(arr[idx], segments[1:])
+		return out
+	}
+
+	obj, ok := current.(map[string]interface{})
+	if !ok {
+		return current
+	}
+	child, ok := obj[seg.key]
+	if !ok {
+		return current
+	}
+	out := make(map[string]interface{}, len(obj))
+	for k, v := range obj {
+		out[k] = v
+	}
+	if len(segments) == 1 {
+		delete(out, seg.key)
+		return out
+	}
+	rest := without(child, segments[1:])
+	if m, isObj := rest.(map[string]interface{}); isObj && len(m) == 0 {
+		delete(out, seg.key)
+	} else {
+		out[seg.key] = rest
+	}
+	return out
+}
+
+// Decode parses JSON data preserving number precision
+func Decode(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+func unquote(s string) (string, bool) {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1], true
+	}
+	return "", false
+}
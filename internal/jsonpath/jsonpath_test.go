@@ -0,0 +1,123 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sampleDoc = `{
+	"status": "ok",
+	"code": 0,
+	"data": {"items": [{"id": 9007199254740993}, {"id": 2}], "my-key": true},
+	"error": null
+}`
+
+func TestPath_Lookup(t *testing.T) {
+	doc, err := Decode([]byte(sampleDoc))
+	require.NoError(t, err)
+
+	tests := []struct {
+		path  string
+		want  interface{}
+		found bool
+	}{
+		{path: "$.status", want: "ok", found: true},
+		{path: "status", want: "ok", found: true},
+		{path: "$.data.items[0].id", want: "9007199254740993", found: true},
+		{path: "$.data.items[-1].id", want: "2", found: true},
+		{path: `$.data["my-key"]`, want: true, found: true},
+		{path: "$.data.items[5]", found: false},
+		{path: "$.missing", found: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			p, err := Compile(tt.path)
+			require.NoError(t, err)
+			got, found := p.Lookup(doc)
+			assert.Equal(t, tt.found, found)
+			if tt.found {
+				assert.Equal(t, tt.want, toComparable(got))
+			}
+		})
+	}
+}
+
+func TestPath_Without(t *testing.T) {
+	tests := []struct {
+		path string
+		doc  string
+		want string
+	}{
+		{path: "$.items", doc: `{"items": [1], "next": "abc"}`, want: `{"next": "abc"}`},
+		{path: "$.result.items", doc: `{"result": {"items": [1], "total": 1}, "items": "kept"}`,
+			want: `{"result": {"total": 1}, "items": "kept"}`},
+		{path: "$.result.items", doc: `{"result": {"items": [1]}, "status": "ok"}`, want: `{"status": "ok"}`},
+		{path: "$.pages[1].items", doc: `{"pages": [{"items": 1}, {"items": 2, "n": 2}]}`,
+			want: `{"pages": [{"items": 1}, {"n": 2}]}`},
+		{path: "$.items[0]", doc: `{"items": [1, 2]}`, want: `{"items": [1, 2]}`},
+		{path: "$.missing.items", doc: `{"items": [1]}`, want: `{"items": [1]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			doc, err := Decode([]byte(tt.doc))
+			require.NoError(t, err)
+			p, err := Compile(tt.path)
+			require.NoError(t, err)
+
+			got, err := json.Marshal(p.Without(doc))
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(got))
+			unchanged, err := json.Marshal(doc)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.doc, string(unchanged), "doc must not be modified")
+		})
+	}
+}
+
+func TestCondition_Eval(t *testing.T) {
+	doc, err := Decode([]byte(sampleDoc))
+	require.NoError(t, err)
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{expr: `$.status == "ok"`, want: true},
+		{expr: `$.status == 'ok'`, want: true},
+		{expr: `$.status != "error"`, want: true},
+		{expr: `$.code == 0`, want: true},
+		{expr: `$.code == 0.0`, want: true},
+		{expr: `$.error == null`, want: true},
+		{expr: `$.data.items`, want: true},
+		{expr: `!$.error`, want: true},
+		{expr: `$.code`, want: false},
+		{expr: `$.missing == "x"`, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			c, err := ParseCondition(tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, c.Eval(doc))
+		})
+	}
+}
+
+func TestParseCondition_Invalid(t *testing.T) {
+	for _, expr := range []string{"", "$.a == ", "$.a[", "$.a == nope"} {
+		_, err := ParseCondition(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+func toComparable(v interface{}) interface{} {
+	if n, ok := v.(interface{ String() string }); ok {
+		return n.String()
+	}
+	return v
+}
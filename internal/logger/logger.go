@@ -8,9 +8,21 @@ import (
 	"github.com/brizzai/auto-mcp/internal/config"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
-var globalLogger = zap.NewNop()
+// Rotation defaults for logging.output_path
+const (
+	defaultMaxSizeMB  = 100
+	defaultMaxBackups = 5
+)
+
+var (
+	globalLogger = zap.NewNop()
+	// globalLevel is shared by every logger built by NewLogger, so the level
+	// can be changed at runtime
+	globalLevel = zap.NewAtomicLevel()
+)
 
 // getConsoleEncoder returns a console encoder with optional color support
 func getConsoleEncoder(cfg *config.LoggingConfig) zapcore.EncoderConfig {
@@ -73,7 +85,9 @@ func NewLogger(cfg *config.LoggingConfig) (*zap.Logger, error) {
 		errorOutputPaths = append(errorOutputPaths, "stderr")
 	}
 
-	// Handle file output if path is specified
+	// Handle file output if path is specified. The file is written through a
+	// rotating writer instead of a zap output path so it cannot grow unbounded.
+	var file *lumberjack.Logger
 	if cfg.OutputPath != "" {
 		// Ensure the directory exists
 		dir := filepath.Dir(cfg.OutputPath)
@@ -86,20 +100,20 @@ func NewLogger(cfg *config.LoggingConfig) (*zap.Logger, error) {
 		if !cfg.AppendToFile {
 			_ = os.Remove(cfg.OutputPath)
 		}
-		outputPaths = append(outputPaths, cfg.OutputPath)
-		errorOutputPaths = append(errorOutputPaths, cfg.OutputPath)
+		file = newRotatingFile(cfg)
 	}
 
-	// Ensure we have at least one output path
-	if len(outputPaths) == 0 {
+	// Ensure we have at least one output
+	if len(outputPaths) == 0 && file == nil {
 		outputPaths = append(outputPaths, "stdout")
 	}
 	if len(errorOutputPaths) == 0 {
 		errorOutputPaths = append(errorOutputPaths, "stderr")
 	}
 
+	globalLevel.SetLevel(level)
 	zapConfig := zap.Config{
-		Level:            zap.NewAtomicLevelAt(level),
+		Level:            globalLevel,
 		Development:      encoding == "console",
 		Encoding:         encoding,
 		OutputPaths:      outputPaths,
@@ -108,16 +122,25 @@ func NewLogger(cfg *config.LoggingConfig) (*zap.Logger, error) {
 	}
 
 	// Build with or without stacktrace based on configuration
-	var logger *zap.Logger
-	if cfg.DisableStacktrace {
-		logger, err = zapConfig.Build(zap.AddCallerSkip(1))
-	} else {
-		logger, err = zapConfig.Build(
-			zap.AddCallerSkip(1),
-			zap.AddStacktrace(zapcore.ErrorLevel),
+	opts := []zap.Option{zap.AddCallerSkip(1)}
+	if !cfg.DisableStacktrace {
+		opts = append(opts, zap.AddStacktrace(zapcore.ErrorLevel))
+	}
+	if file != nil {
+		encoder := zapcore.NewConsoleEncoder(encoderConfig)
+		if encoding == "json" {
+			encoder = zapcore.NewJSONEncoder(encoderConfig)
+		}
+		fileCore := zapcore.NewCore(encoder, zapcore.AddSync(file), globalLevel)
+		opts = append(opts,
+			zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+				return zapcore.NewTee(core, fileCore)
+			}),
+			zap.ErrorOutput(errorOutput(cfg, file)),
 		)
 	}
 
+	logger, err := zapConfig.Build(opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build logger: %v", err)
 	}
@@ -125,6 +148,50 @@ func NewLogger(cfg *config.LoggingConfig) (*zap.Logger, error) {
 	return logger, nil
 }
 
+// errorOutput returns the writer for zap's internal errors when logging to a
+// file: the rotating file, and stderr unless the console is disabled
+func errorOutput(cfg *config.LoggingConfig, file *lumberjack.Logger) zapcore.WriteSyncer {
+	output := zapcore.AddSync(file)
+	if cfg.DisableConsole {
+		return output
+	}
+	return zapcore.NewMultiWriteSyncer(zapcore.Lock(os.Stderr), output)
+}
+
+// newRotatingFile returns a writer for cfg.OutputPath that rotates by size
+// and removes old files according to the retention settings
+func newRotatingFile(cfg *config.LoggingConfig) *lumberjack.Logger {
+	maxSize := cfg.MaxSizeMB
+	if maxSize == 0 {
+		maxSize = defaultMaxSizeMB
+	}
+	maxBackups := cfg.MaxBackups
+	switch {
+	case maxBackups == 0:
+		maxBackups = defaultMaxBackups
+	case maxBackups < 0:
+		// lumberjack keeps every file when MaxBackups is 0
+		maxBackups = 0
+	}
+	return &lumberjack.Logger{
+		Filename:   cfg.OutputPath,
+		MaxSize:    maxSize,
+		MaxBackups: maxBackups,
+		MaxAge:     cfg.MaxAgeDays,
+		Compress:   cfg.Compress,
+	}
+}
+
+// SetLevel changes the level of the global logger
+func SetLevel(level string) error {
+	parsed, err := zapcore.ParseLevel(level)
+	if err != nil {
+		return fmt.Errorf("invalid log level: %v", err)
+	}
+	globalLevel.SetLevel(parsed)
+	return nil
+}
+
 // GetLogger returns the global logger instance
 func GetLogger() *zap.Logger {
 	return globalLogger
@@ -0,0 +1,57 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/brizzai/auto-mcp/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLogger_OutputPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "auto-mcp.log")
+	cfg := &config.LoggingConfig{Level: "info", Format: "json", OutputPath: path, DisableConsole: true}
+
+	logger, err := NewLogger(cfg)
+	require.NoError(t, err)
+	logger.Info("to the file")
+	require.NoError(t, logger.Sync())
+
+	// zap's internal errors go to the same rotating file
+	file := newRotatingFile(cfg)
+	defer func() { _ = file.Close() }()
+	_, err = errorOutput(cfg, file).Write([]byte("internal error\n"))
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"msg":"to the file"`)
+	assert.Contains(t, string(data), "internal error")
+}
+
+func TestNewRotatingFile(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.LoggingConfig{OutputPath: filepath.Join(dir, "auto-mcp.log"), MaxSizeMB: 1}
+
+	file := newRotatingFile(cfg)
+	defer func() { _ = file.Close() }()
+	assert.Equal(t, defaultMaxBackups, file.MaxBackups)
+
+	line := strings.Repeat("x", 600<<10)
+	for range 2 {
+		_, err := file.Write([]byte(line))
+		require.NoError(t, err)
+	}
+
+	// The second write exceeds 1 MB and starts a new file
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	// Negative retention keeps every file, which lumberjack spells 0
+	assert.Equal(t, 0, newRotatingFile(&config.LoggingConfig{MaxBackups: -1}).MaxBackups)
+	assert.Equal(t, defaultMaxSizeMB, newRotatingFile(&config.LoggingConfig{}).MaxSize)
+}
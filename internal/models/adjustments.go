@@ -2,7 +2,11 @@ package models
 
 type RouteFieldUpdate struct {
 	Method         string `yaml:"method"`
-	NewDescription string `yaml:"new_description"`
+	NewDescription string `yaml:"new_description,omitempty"`
+	// NewName replaces the generated tool name, e.g. find_pets_by_status
+	NewName string `yaml:"new_name,omitempty"`
+	// Template renders the tool description, overriding description_template
+	Template string `yaml:"template,omitempty"`
 }
 
 type RouteDescription struct {
@@ -15,7 +19,237 @@ type RouteSelection struct {
 	Methods []string `yaml:"methods"`
 }
 
+// RouteDefaultUpdate maps argument names to default values for one method.
+// Values may reference the authenticated user, e.g. "{{user.email}}".
+type RouteDefaultUpdate struct {
+	Method    string            `yaml:"method"`
+	Arguments map[string]string `yaml:"arguments,omitempty"`
+	// Constants are always sent, overriding the caller, and are hidden from
+	// the tool schema; "body.tenant_id" pins a field of the body
+	Constants map[string]string `yaml:"constants,omitempty"`
+}
+
+type RouteDefaults struct {
+	Path    string               `yaml:"path"`
+	Updates []RouteDefaultUpdate `yaml:"updates"`
+}
+
+// RouteSuccessUpdate defines when a 2xx response for one method counts as a success,
+// e.g. `$.status == "ok"`
+type RouteSuccessUpdate struct {
+	Method     string `yaml:"method"`
+	Expression string `yaml:"expression"`
+}
+
+type RouteSuccessCriteria struct {
+	Path    string               `yaml:"path"`
+	Updates []RouteSuccessUpdate `yaml:"updates"`
+}
+
+// RouteUnwrapUpdate selects the payload of an envelope response for one method.
+// Meta is optional; without it the remaining top-level members become metadata.
+type RouteUnwrapUpdate struct {
+	Method string `yaml:"method"`
+	Data   string `yaml:"data"`
+	Meta   string `yaml:"meta,omitempty"`
+}
+
+type RouteUnwrap struct {
+	Path    string              `yaml:"path"`
+	Updates []RouteUnwrapUpdate `yaml:"updates"`
+}
+
+// RouteCacheUpdate sets how long a GET response may be served from the cache,
+// as a Go duration such as "30s" or "5m"
+type RouteCacheUpdate struct {
+	Method string `yaml:"method"`
+	MaxAge string `yaml:"max_age"`
+}
+
+type RouteCache struct {
+	Path    string             `yaml:"path"`
+	Updates []RouteCacheUpdate `yaml:"updates"`
+}
+
+// RouteResponseUpdate sets how the responses of one method are returned to
+// the model. HTML is raw, markdown or text and overrides endpoint.html_responses.
+type RouteResponseUpdate struct {
+	Method string `yaml:"method"`
+	HTML   string `yaml:"html,omitempty"`
+}
+
+type RouteResponses struct {
+	Path    string                `yaml:"path"`
+	Updates []RouteResponseUpdate `yaml:"updates"`
+}
+
+// RouteAnnotationUpdate overrides the MCP tool annotations derived from the
+// HTTP method. Unset hints keep the derived value.
+type RouteAnnotationUpdate struct {
+	Method      string `yaml:"method"`
+	Title       string `yaml:"title,omitempty"`
+	ReadOnly    *bool  `yaml:"read_only,omitempty"`
+	Destructive *bool  `yaml:"destructive,omitempty"`
+	Idempotent  *bool  `yaml:"idempotent,omitempty"`
+	OpenWorld   *bool  `yaml:"open_world,omitempty"`
+}
+
+type RouteAnnotations struct {
+	Path    string                  `yaml:"path"`
+	Updates []RouteAnnotationUpdate `yaml:"updates"`
+}
+
+// RouteTransformUpdate reshapes the requests and responses of one method
+// with Go templates, see package transform
+type RouteTransformUpdate struct {
+	Method string `yaml:"method"`
+	// Arguments add or override tool arguments; "body.status" sets a field of the body
+	Arguments map[string]string `yaml:"arguments,omitempty"`
+	// Headers are sent with the upstream request
+	Headers map[string]string `yaml:"headers,omitempty"`
+	// Response renders the tool result from a successful upstream response
+	Response string `yaml:"response,omitempty"`
+}
+
+type RouteTransforms struct {
+	Path    string                 `yaml:"path"`
+	Updates []RouteTransformUpdate `yaml:"updates"`
+}
+
+// RouteParameterUpdate renames, hides and describes arguments of one
+// method. Names like "body.petName" refer to a field of the request body.
+type RouteParameterUpdate struct {
+	Method string `yaml:"method"`
+	// Rename maps real parameter names to the names the model sees
+	Rename map[string]string `yaml:"rename,omitempty"`
+	// Hide removes parameters from the tool; the model cannot set them
+	Hide []string `yaml:"hide,omitempty"`
+	// Descriptions replace the descriptions of parameters, by real name
+	Descriptions map[string]string `yaml:"descriptions,omitempty"`
+}
+
+type RouteParameters struct {
+	Path    string                 `yaml:"path"`
+	Updates []RouteParameterUpdate `yaml:"updates"`
+}
+
+// RoutePolicyUpdate changes how requests for one method are sent. Durations
+// use Go syntax, e.g. 2m or 500ms.
+type RoutePolicyUpdate struct {
+	Method string `yaml:"method"`
+	// Timeout bounds each attempt, replacing the default of 30s
+	Timeout string `yaml:"timeout,omitempty"`
+	// Retries repeats attempts that fail with a network error, 429, 502, 503 or 504
+	Retries int `yaml:"retries,omitempty"`
+	// RateLimit caps the requests per minute; calls over the limit wait their turn
+	RateLimit int `yaml:"rate_limit,omitempty"`
+	// MaxConcurrency caps the calls running at once, overriding server.concurrency.per_tool
+	MaxConcurrency int `yaml:"max_concurrency,omitempty"`
+	// CacheTTL caches GET responses, like max_age in the cache section
+	CacheTTL string `yaml:"cache_ttl,omitempty"`
+}
+
+type RoutePolicies struct {
+	Path    string              `yaml:"path"`
+	Updates []RoutePolicyUpdate `yaml:"updates"`
+}
+
+// PromptArgument is a value the user supplies when requesting a prompt
+type PromptArgument struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description,omitempty"`
+	Required    bool   `yaml:"required,omitempty"`
+}
+
+// Prompt is a curated entry point for a multi-step API workflow. Template may
+// reference arguments as {{name}}; Tools lists the tool names the workflow uses.
+type Prompt struct {
+	Name        string           `yaml:"name"`
+	Description string           `yaml:"description,omitempty"`
+	Arguments   []PromptArgument `yaml:"arguments,omitempty"`
+	Tools       []string         `yaml:"tools,omitempty"`
+	Template    string           `yaml:"template,omitempty"`
+}
+
+// CompositeArgument is an input of a composite tool. Type is a JSON schema
+// type and defaults to string.
+type CompositeArgument struct {
+	Name        string `yaml:"name"`
+	Type        string `yaml:"type,omitempty"`
+	Description string `yaml:"description,omitempty"`
+	Required    bool   `yaml:"required,omitempty"`
+}
+
+// CompositeStep calls one tool. Arguments are templates, like those of
+// transforms, that may read the composite's arguments and earlier results.
+type CompositeStep struct {
+	// Name identifies the step's result in later templates, defaults to the tool name
+	Name      string            `yaml:"name,omitempty"`
+	Tool      string            `yaml:"tool"`
+	Arguments map[string]string `yaml:"arguments,omitempty"`
+}
+
+// CompositeTool chains several tools into one, calling them in order and
+// stopping at the first failure
+type CompositeTool struct {
+	Name        string              `yaml:"name"`
+	Description string              `yaml:"description,omitempty"`
+	Arguments   []CompositeArgument `yaml:"arguments,omitempty"`
+	Steps       []CompositeStep     `yaml:"steps"`
+	// Result renders the tool result, defaults to the last step's result
+	Result string `yaml:"result,omitempty"`
+}
+
+// CustomTool is a tool for an operation the spec does not describe. Path
+// parameters and, for GET, the other arguments are sent like those of
+// generated tools; Body renders the request body of other methods.
+type CustomTool struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description,omitempty"`
+	Method      string `yaml:"method"`
+	Path        string `yaml:"path"`
+	// InputSchema is the JSON schema of the arguments, an object schema
+	InputSchema map[string]any `yaml:"input_schema,omitempty"`
+	// HeaderParams are arguments sent as request headers
+	HeaderParams []string `yaml:"header_params,omitempty"`
+	// Headers are sent with every request
+	Headers map[string]string `yaml:"headers,omitempty"`
+	// Body is a template rendering the request body, see transforms
+	Body string `yaml:"body,omitempty"`
+}
+
 type MCPAdjustments struct {
-	Descriptions []RouteDescription `yaml:"descriptions,omitempty"`
-	Routes       []RouteSelection   `yaml:"routes,omitempty"`
+	// DescriptionTemplate renders the description of every tool from the
+	// operation's metadata, see the descriptions section for per-route templates
+	DescriptionTemplate string             `yaml:"description_template,omitempty"`
+	Descriptions        []RouteDescription `yaml:"descriptions,omitempty"`
+	// Locales holds description sets by locale, e.g. "de" or "pt-BR". The set
+	// server.locale selects replaces the new_description and template of descriptions
+	Locales  map[string][]RouteDescription `yaml:"locales,omitempty"`
+	Routes   []RouteSelection              `yaml:"routes,omitempty"`
+	Defaults []RouteDefaults               `yaml:"defaults,omitempty"`
+	// OnBehalfOf selects routes that receive the caller's identity header
+	OnBehalfOf []RouteSelection `yaml:"on_behalf_of,omitempty"`
+	// SuccessCriteria marks 2xx responses that report failure in their body as tool errors
+	SuccessCriteria []RouteSuccessCriteria `yaml:"success_criteria,omitempty"`
+	// Unwrap returns only the payload of envelope responses
+	Unwrap []RouteUnwrap `yaml:"unwrap,omitempty"`
+	// Cache serves repeated GET calls from memory for up to max_age
+	Cache []RouteCache `yaml:"cache,omitempty"`
+	// Responses convert the responses of routes before they reach the model
+	Responses []RouteResponses `yaml:"responses,omitempty"`
+	// Annotations override the tool hints derived from the HTTP method
+	Annotations []RouteAnnotations `yaml:"annotations,omitempty"`
+	// Transforms add arguments and headers and reshape responses with templates
+	Transforms []RouteTransforms `yaml:"transforms,omitempty"`
+	// Parameters rename, hide and describe tool arguments
+	Parameters []RouteParameters `yaml:"parameters,omitempty"`
+	// Policies override timeouts, retries, rate limits and caching per route
+	Policies []RoutePolicies `yaml:"policies,omitempty"`
+	// Prompts are registered as MCP prompts
+	Prompts []Prompt `yaml:"prompts,omitempty"`
+	// CompositeTools chain several tools into one
+	CompositeTools []CompositeTool `yaml:"composite_tools,omitempty"`
+	// CustomTools add tools for operations missing from the spec
+	CustomTools []CustomTool `yaml:"custom_tools,omitempty"`
 }
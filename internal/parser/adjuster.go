@@ -1,17 +1,28 @@
 package parser
 
 import (
+	"errors"
+	"fmt"
+	"maps"
 	"os"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/brizzai/auto-mcp/internal/config"
+	"github.com/brizzai/auto-mcp/internal/jsonpath"
 	"github.com/brizzai/auto-mcp/internal/logger"
 	"github.com/brizzai/auto-mcp/internal/models"
+	"github.com/brizzai/auto-mcp/internal/requester"
+	"github.com/brizzai/auto-mcp/internal/transform"
 	"go.uber.org/zap"
-	"gopkg.in/yaml.v3"
 )
 
 // Adjuster provides filtering and description overrides based on YAML configuration
 type Adjuster struct {
 	adjustments *models.MCPAdjustments
+	// locale selects the description set of the locales section
+	locale string
 }
 
 // NewAdjuster creates a new Adjuster instance
@@ -24,6 +35,13 @@ func NewAdjuster() *Adjuster {
 	}
 }
 
+// SetLocale selects the description set of the locales section used for
+// tool descriptions, falling back to the language of locale ("de" for
+// "de-AT") and then to the descriptions section
+func (a *Adjuster) SetLocale(locale string) {
+	a.locale = locale
+}
+
 // Load loads adjustments from a YAML file
 func (a *Adjuster) Load(filePath string) error {
 	if filePath == "" {
@@ -32,24 +50,242 @@ func (a *Adjuster) Load(filePath string) error {
 	}
 
 	logger.Info("Loading adjustments from file", zap.String("file", filePath))
-	// Check if file exists first
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		logger.Error("Adjustments file not found")
-		return nil // Return nil if file doesn't exist
-	}
-
 	data, err := os.ReadFile(filePath)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("adjustments file %s does not exist", filePath)
+	}
 	if err != nil {
 		return err
 	}
 
-	var adjustments models.MCPAdjustments
-	err = yaml.Unmarshal(data, &adjustments)
+	adjustments, err := decodeAdjustments(data)
 	if err != nil {
 		return err
 	}
 
-	a.adjustments = &adjustments
+	for _, ref := range references(adjustments) {
+		if !slices.Contains(operationMethods, ref.Method) {
+			return fmt.Errorf("%s[%s %s]: method must be one of %s", ref.Section, ref.Method, ref.Path, strings.Join(operationMethods, ", "))
+		}
+		if !strings.HasPrefix(ref.Path, "/") {
+			return fmt.Errorf("%s[%s %s]: path must start with /", ref.Section, ref.Method, ref.Path)
+		}
+	}
+
+	// Render ${ENV_VAR} references in argument defaults and constants
+	for _, defaults := range adjustments.Defaults {
+		for _, update := range defaults.Updates {
+			field := fmt.Sprintf("defaults[%s %s].arguments", update.Method, defaults.Path)
+			if err := config.ExpandEnvMap(update.Arguments, field); err != nil {
+				return err
+			}
+			field = fmt.Sprintf("defaults[%s %s].constants", update.Method, defaults.Path)
+			if err := config.ExpandEnvMap(update.Constants, field); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Fail fast on expressions that would otherwise only break at call time
+	for _, criteria := range adjustments.SuccessCriteria {
+		for _, update := range criteria.Updates {
+			if _, err := jsonpath.ParseCondition(update.Expression); err != nil {
+				return fmt.Errorf("success_criteria[%s %s]: %w", update.Method, criteria.Path, err)
+			}
+		}
+	}
+
+	for _, unwrap := range adjustments.Unwrap {
+		for _, update := range unwrap.Updates {
+			for _, path := range []string{update.Data, update.Meta} {
+				if path == "" {
+					continue
+				}
+				if _, err := jsonpath.Compile(path); err != nil {
+					return fmt.Errorf("unwrap[%s %s]: %w", update.Method, unwrap.Path, err)
+				}
+			}
+		}
+	}
+
+	for _, cache := range adjustments.Cache {
+		for _, update := range cache.Updates {
+			if update.Method != "GET" {
+				return fmt.Errorf("cache[%s %s]: only GET responses can be cached", update.Method, cache.Path)
+			}
+			if maxAge, err := time.ParseDuration(update.MaxAge); err != nil || maxAge <= 0 {
+				return fmt.Errorf("cache[%s %s]: max_age must be a positive duration such as 30s, got %q", update.Method, cache.Path, update.MaxAge)
+			}
+		}
+	}
+
+	for _, responses := range adjustments.Responses {
+		for _, update := range responses.Updates {
+			switch update.HTML {
+			case "", config.HTMLResponsesRaw, config.HTMLResponsesMarkdown, config.HTMLResponsesText:
+			default:
+				return fmt.Errorf("responses[%s %s]: html must be %s, %s or %s, got %q", update.Method, responses.Path,
+					config.HTMLResponsesRaw, config.HTMLResponsesMarkdown, config.HTMLResponsesText, update.HTML)
+			}
+		}
+	}
+
+	cached := make(map[string]bool)
+	for _, cache := range adjustments.Cache {
+		for _, update := range cache.Updates {
+			cached[update.Method+" "+cache.Path] = true
+		}
+	}
+	for _, policies := range adjustments.Policies {
+		for _, update := range policies.Updates {
+			if err := validatePolicyUpdate(update, cached[update.Method+" "+policies.Path]); err != nil {
+				return fmt.Errorf("policies[%s %s]: %w", update.Method, policies.Path, err)
+			}
+		}
+	}
+
+	if adjustments.DescriptionTemplate != "" {
+		if _, err := transform.Parse("description_template", adjustments.DescriptionTemplate); err != nil {
+			return fmt.Errorf("description_template: %w", err)
+		}
+	}
+	for _, locale := range slices.Sorted(maps.Keys(adjustments.Locales)) {
+		for _, desc := range adjustments.Locales[locale] {
+			for _, update := range desc.Updates {
+				if update.NewName != "" {
+					return fmt.Errorf("locales.%s[%s %s]: new_name is not localized, set it in descriptions", locale, update.Method, desc.Path)
+				}
+				if update.Template == "" {
+					continue
+				}
+				if _, err := transform.Parse("description", update.Template); err != nil {
+					return fmt.Errorf("locales.%s[%s %s]: %w", locale, update.Method, desc.Path, err)
+				}
+			}
+		}
+	}
+	newNames := make(map[string]string)
+	for _, desc := range adjustments.Descriptions {
+		for _, update := range desc.Updates {
+			if update.NewName != "" {
+				if err := ValidToolName(update.NewName); err != nil {
+					return fmt.Errorf("descriptions[%s %s]: %w", update.Method, desc.Path, err)
+				}
+				if other, ok := newNames[update.NewName]; ok {
+					return fmt.Errorf("descriptions[%s %s]: new_name %q is already used by %s", update.Method, desc.Path, update.NewName, other)
+				}
+				newNames[update.NewName] = update.Method + " " + desc.Path
+			}
+			if update.Template == "" {
+				continue
+			}
+			if _, err := transform.Parse("description", update.Template); err != nil {
+				return fmt.Errorf("descriptions[%s %s]: %w", update.Method, desc.Path, err)
+			}
+		}
+	}
+
+	for _, parameters := range adjustments.Parameters {
+		for _, update := range parameters.Updates {
+			if err := validateParameterUpdate(update); err != nil {
+				return fmt.Errorf("parameters[%s %s]: %w", update.Method, parameters.Path, err)
+			}
+		}
+	}
+
+	for _, transforms := range adjustments.Transforms {
+		for _, update := range transforms.Updates {
+			if _, err := transform.Compile(update.Arguments, update.Headers, update.Response); err != nil {
+				return fmt.Errorf("transforms[%s %s]: %w", update.Method, transforms.Path, err)
+			}
+		}
+	}
+
+	promptNames := make(map[string]bool)
+	for i, prompt := range adjustments.Prompts {
+		if prompt.Name == "" {
+			return fmt.Errorf("prompts[%d]: name is required", i)
+		}
+		if promptNames[prompt.Name] {
+			return fmt.Errorf("prompts[%d]: duplicate prompt name %q", i, prompt.Name)
+		}
+		promptNames[prompt.Name] = true
+	}
+
+	if err := validateCompositeTools(adjustments.CompositeTools); err != nil {
+		return err
+	}
+	if err := validateCustomTools(adjustments.CustomTools); err != nil {
+		return err
+	}
+
+	a.adjustments = adjustments
+	if a.locale != "" && a.localeDescriptions() == nil {
+		logger.Warn("The adjustments file has no descriptions for the locale, the default descriptions are used", zap.String("locale", a.locale))
+	}
+	return nil
+}
+
+// localeDescriptions returns the description set of the locale, nil when
+// there is none
+func (a *Adjuster) localeDescriptions() []models.RouteDescription {
+	if a.locale == "" || a.adjustments == nil {
+		return nil
+	}
+	if descriptions, ok := a.adjustments.Locales[a.locale]; ok {
+		return descriptions
+	}
+	language, _, _ := strings.Cut(strings.ReplaceAll(a.locale, "_", "-"), "-")
+	return a.adjustments.Locales[language]
+}
+
+// findUpdate returns the update of route/method in descriptions whose field
+// is set, or ""
+func findUpdate(descriptions []models.RouteDescription, route, method string, field func(models.RouteFieldUpdate) string) string {
+	for _, desc := range descriptions {
+		if desc.Path == route {
+			for _, update := range desc.Updates {
+				if update.Method == method && field(update) != "" {
+					return field(update)
+				}
+			}
+			break
+		}
+	}
+	return ""
+}
+
+// maxRetries bounds the retries of a route policy
+const maxRetries = 10
+
+// validatePolicyUpdate checks one policy; cached reports whether the cache
+// section already sets a max age for the same route
+func validatePolicyUpdate(update models.RoutePolicyUpdate, cached bool) error {
+	if update.Timeout != "" {
+		if timeout, err := time.ParseDuration(update.Timeout); err != nil || timeout <= 0 {
+			return fmt.Errorf("timeout must be a positive duration such as 2m, got %q", update.Timeout)
+		}
+	}
+	if update.Retries < 0 || update.Retries > maxRetries {
+		return fmt.Errorf("retries must be between 0 and %d, got %d", maxRetries, update.Retries)
+	}
+	if update.RateLimit < 0 {
+		return fmt.Errorf("rate_limit must be a number of requests per minute, got %d", update.RateLimit)
+	}
+	if update.MaxConcurrency < 0 {
+		return fmt.Errorf("max_concurrency must not be negative, got %d", update.MaxConcurrency)
+	}
+	if update.CacheTTL != "" {
+		if update.Method != "GET" {
+			return errors.New("only GET responses can be cached")
+		}
+		if cached {
+			return errors.New("cache_ttl and the cache section both set a max age")
+		}
+		if ttl, err := time.ParseDuration(update.CacheTTL); err != nil || ttl <= 0 {
+			return fmt.Errorf("cache_ttl must be a positive duration such as 30s, got %q", update.CacheTTL)
+		}
+	}
 	return nil
 }
 
@@ -76,24 +312,433 @@ func (a *Adjuster) ExistsInMCP(route, method string) bool {
 	return false // Path not found
 }
 
-// GetDescription returns the updated description for a route/method if it exists
+// GetDescription returns the updated description for a route/method, from the
+// description set of the locale first, if it exists
 func (a *Adjuster) GetDescription(route, method, originalDesc string) string {
-	if a.adjustments == nil || len(a.adjustments.Descriptions) == 0 {
+	if a.adjustments == nil {
 		return originalDesc // Return original if no adjustments
 	}
 
-	// Look through all route descriptions
+	newDescription := func(update models.RouteFieldUpdate) string { return update.NewDescription }
+	if description := findUpdate(a.localeDescriptions(), route, method, newDescription); description != "" {
+		return description
+	}
+	if description := findUpdate(a.adjustments.Descriptions, route, method, newDescription); description != "" {
+		return description
+	}
+	return originalDesc
+}
+
+// GetToolName returns the tool name replacing the generated one for a
+// route/method, or ""
+func (a *Adjuster) GetToolName(route, method string) string {
+	if a == nil || a.adjustments == nil {
+		return ""
+	}
+
 	for _, desc := range a.adjustments.Descriptions {
 		if desc.Path == route {
-			// Look through all updates for this route
 			for _, update := range desc.Updates {
+				if update.Method == method && update.NewName != "" {
+					return update.NewName
+				}
+			}
+			break
+		}
+	}
+	return ""
+}
+
+// GetDescriptionTemplate returns the template rendering the description of a
+// route/method: its template of the locale, else its own template, else the
+// global one, else ""
+func (a *Adjuster) GetDescriptionTemplate(route, method string) string {
+	if a == nil || a.adjustments == nil {
+		return ""
+	}
+
+	template := func(update models.RouteFieldUpdate) string { return update.Template }
+	if text := findUpdate(a.localeDescriptions(), route, method, template); text != "" {
+		return text
+	}
+	if text := findUpdate(a.adjustments.Descriptions, route, method, template); text != "" {
+		return text
+	}
+	return a.adjustments.DescriptionTemplate
+}
+
+// GetArgumentDefaults returns the default argument values for a route/method, if any
+func (a *Adjuster) GetArgumentDefaults(route, method string) map[string]string {
+	if a.adjustments == nil || len(a.adjustments.Defaults) == 0 {
+		return nil
+	}
+
+	for _, defaults := range a.adjustments.Defaults {
+		if defaults.Path == route {
+			for _, update := range defaults.Updates {
+				if update.Method == method {
+					return update.Arguments
+				}
+			}
+			break
+		}
+	}
+	return nil
+}
+
+// GetArgumentConstants returns the constant argument values for a route/method, if any
+func (a *Adjuster) GetArgumentConstants(route, method string) map[string]string {
+	if a.adjustments == nil || len(a.adjustments.Defaults) == 0 {
+		return nil
+	}
+
+	for _, defaults := range a.adjustments.Defaults {
+		if defaults.Path == route {
+			for _, update := range defaults.Updates {
 				if update.Method == method {
-					return update.NewDescription
+					return update.Constants
 				}
 			}
-			break // Found the route but no matching method
+			break
 		}
 	}
+	return nil
+}
 
-	return originalDesc
+// UsesOnBehalfOf reports whether a route/method should carry the caller's identity header
+func (a *Adjuster) UsesOnBehalfOf(route, method string) bool {
+	if a.adjustments == nil {
+		return false
+	}
+
+	for _, selection := range a.adjustments.OnBehalfOf {
+		if selection.Path == route {
+			for _, m := range selection.Methods {
+				if m == method {
+					return true
+				}
+			}
+			return false
+		}
+	}
+	return false
+}
+
+// GetSuccessCriteria returns the success expression for a route/method, or "" if none
+func (a *Adjuster) GetSuccessCriteria(route, method string) string {
+	if a.adjustments == nil {
+		return ""
+	}
+
+	for _, criteria := range a.adjustments.SuccessCriteria {
+		if criteria.Path == route {
+			for _, update := range criteria.Updates {
+				if update.Method == method {
+					return update.Expression
+				}
+			}
+			break
+		}
+	}
+	return ""
+}
+
+// GetUnwrap returns the envelope data and meta paths for a route/method, if configured
+func (a *Adjuster) GetUnwrap(route, method string) (data, meta string) {
+	if a.adjustments == nil {
+		return "", ""
+	}
+
+	for _, unwrap := range a.adjustments.Unwrap {
+		if unwrap.Path == route {
+			for _, update := range unwrap.Updates {
+				if update.Method == method {
+					return update.Data, update.Meta
+				}
+			}
+			break
+		}
+	}
+	return "", ""
+}
+
+// GetCacheMaxAge returns how long responses for a route/method may be cached, 0 if not cached
+func (a *Adjuster) GetCacheMaxAge(route, method string) time.Duration {
+	if a.adjustments == nil {
+		return 0
+	}
+
+	for _, cache := range a.adjustments.Cache {
+		if cache.Path == route {
+			for _, update := range cache.Updates {
+				if update.Method == method {
+					// Validated in Load
+					maxAge, _ := time.ParseDuration(update.MaxAge)
+					return maxAge
+				}
+			}
+			break
+		}
+	}
+	if update := a.policy(route, method); update != nil && update.CacheTTL != "" {
+		// Validated in Load
+		ttl, _ := time.ParseDuration(update.CacheTTL)
+		return ttl
+	}
+	return 0
+}
+
+// GetHTMLResponses returns how HTML responses of a route/method are
+// converted, "" to use endpoint.html_responses
+func (a *Adjuster) GetHTMLResponses(route, method string) string {
+	if a.adjustments == nil {
+		return ""
+	}
+
+	for _, responses := range a.adjustments.Responses {
+		if responses.Path == route {
+			for _, update := range responses.Updates {
+				if update.Method == method {
+					return update.HTML
+				}
+			}
+			break
+		}
+	}
+	return ""
+}
+
+// GetPolicy returns the timeout, retries, rate limit and concurrency limit of a route/method
+func (a *Adjuster) GetPolicy(route, method string) requester.RoutePolicy {
+	update := a.policy(route, method)
+	if update == nil {
+		return requester.RoutePolicy{}
+	}
+	// Validated in Load
+	timeout, _ := time.ParseDuration(update.Timeout)
+	return requester.RoutePolicy{Timeout: timeout, Retries: update.Retries, RateLimit: update.RateLimit, MaxConcurrency: update.MaxConcurrency}
+}
+
+func (a *Adjuster) policy(route, method string) *models.RoutePolicyUpdate {
+	if a == nil || a.adjustments == nil {
+		return nil
+	}
+
+	for _, policies := range a.adjustments.Policies {
+		if policies.Path == route {
+			for i := range policies.Updates {
+				if policies.Updates[i].Method == method {
+					return &policies.Updates[i]
+				}
+			}
+			break
+		}
+	}
+	return nil
+}
+
+// GetAnnotations returns the tool annotation overrides for a route/method, or nil if none
+func (a *Adjuster) GetAnnotations(route, method string) *models.RouteAnnotationUpdate {
+	if a == nil || a.adjustments == nil {
+		return nil
+	}
+
+	for _, annotations := range a.adjustments.Annotations {
+		if annotations.Path == route {
+			for i := range annotations.Updates {
+				if annotations.Updates[i].Method == method {
+					return &annotations.Updates[i]
+				}
+			}
+			break
+		}
+	}
+	return nil
+}
+
+// GetParameters returns the argument renames and hidden parameters for a route/method
+func (a *Adjuster) GetParameters(route, method string) (renames map[string]string, hidden []string) {
+	if a == nil || a.adjustments == nil {
+		return nil, nil
+	}
+
+	for _, parameters := range a.adjustments.Parameters {
+		if parameters.Path == route {
+			for _, update := range parameters.Updates {
+				if update.Method == method {
+					return update.Rename, update.Hide
+				}
+			}
+			break
+		}
+	}
+	return nil, nil
+}
+
+// GetParameterDescriptions returns the parameter descriptions for a route/method
+func (a *Adjuster) GetParameterDescriptions(route, method string) map[string]string {
+	if a == nil || a.adjustments == nil {
+		return nil
+	}
+
+	for _, parameters := range a.adjustments.Parameters {
+		if parameters.Path == route {
+			for _, update := range parameters.Updates {
+				if update.Method == method {
+					return update.Descriptions
+				}
+			}
+			break
+		}
+	}
+	return nil
+}
+
+// GetTransform returns the request and response templates for a route/method
+func (a *Adjuster) GetTransform(route, method string) requester.TransformConfig {
+	if a.adjustments == nil {
+		return requester.TransformConfig{}
+	}
+
+	for _, transforms := range a.adjustments.Transforms {
+		if transforms.Path == route {
+			for _, update := range transforms.Updates {
+				if update.Method == method {
+					return requester.TransformConfig{
+						Arguments: update.Arguments,
+						Headers:   update.Headers,
+						Response:  update.Response,
+					}
+				}
+			}
+			break
+		}
+	}
+	return requester.TransformConfig{}
+}
+
+// GetCompositeTools returns the composite tools defined in the adjustments file
+func (a *Adjuster) GetCompositeTools() []models.CompositeTool {
+	if a.adjustments == nil {
+		return nil
+	}
+	return a.adjustments.CompositeTools
+}
+
+// GetCustomTools returns the custom tools defined in the adjustments file
+func (a *Adjuster) GetCustomTools() []models.CustomTool {
+	if a == nil || a.adjustments == nil {
+		return nil
+	}
+	return a.adjustments.CustomTools
+}
+
+// GetPrompts returns the workflow prompts defined in the adjustments file
+func (a *Adjuster) GetPrompts() []models.Prompt {
+	if a.adjustments == nil {
+		return nil
+	}
+	return a.adjustments.Prompts
+}
+
+// AdjustmentReference is one operation an adjustments entry applies to
+type AdjustmentReference struct {
+	Section string `json:"section"`
+	Method  string `json:"method"`
+	Path    string `json:"path"`
+}
+
+// References lists the operations referenced by every route-based section
+func (a *Adjuster) References() []AdjustmentReference {
+	if a.adjustments == nil {
+		return nil
+	}
+	return references(a.adjustments)
+}
+
+// Unmatched returns the references to operations missing from operations,
+// which holds "METHOD /path" keys
+func (a *Adjuster) Unmatched(operations map[string]bool) []AdjustmentReference {
+	var unmatched []AdjustmentReference
+	for _, ref := range a.References() {
+		if !operations[ref.Method+" "+ref.Path] {
+			unmatched = append(unmatched, ref)
+		}
+	}
+	return unmatched
+}
+
+func references(adjustments *models.MCPAdjustments) []AdjustmentReference {
+	var refs []AdjustmentReference
+	add := func(section, path, method string) {
+		refs = append(refs, AdjustmentReference{Section: section, Method: method, Path: path})
+	}
+	for _, route := range adjustments.Routes {
+		for _, method := range route.Methods {
+			add("routes", route.Path, method)
+		}
+	}
+	for _, route := range adjustments.OnBehalfOf {
+		for _, method := range route.Methods {
+			add("on_behalf_of", route.Path, method)
+		}
+	}
+	for _, route := range adjustments.Descriptions {
+		for _, update := range route.Updates {
+			add("descriptions", route.Path, update.Method)
+		}
+	}
+	for _, locale := range slices.Sorted(maps.Keys(adjustments.Locales)) {
+		for _, route := range adjustments.Locales[locale] {
+			for _, update := range route.Updates {
+				add("locales."+locale, route.Path, update.Method)
+			}
+		}
+	}
+	for _, route := range adjustments.Defaults {
+		for _, update := range route.Updates {
+			add("defaults", route.Path, update.Method)
+		}
+	}
+	for _, route := range adjustments.SuccessCriteria {
+		for _, update := range route.Updates {
+			add("success_criteria", route.Path, update.Method)
+		}
+	}
+	for _, route := range adjustments.Unwrap {
+		for _, update := range route.Updates {
+			add("unwrap", route.Path, update.Method)
+		}
+	}
+	for _, route := range adjustments.Cache {
+		for _, update := range route.Updates {
+			add("cache", route.Path, update.Method)
+		}
+	}
+	for _, route := range adjustments.Responses {
+		for _, update := range route.Updates {
+			add("responses", route.Path, update.Method)
+		}
+	}
+	for _, route := range adjustments.Annotations {
+		for _, update := range route.Updates {
+			add("annotations", route.Path, update.Method)
+		}
+	}
+	for _, route := range adjustments.Parameters {
+		for _, update := range route.Updates {
+			add("parameters", route.Path, update.Method)
+		}
+	}
+	for _, route := range adjustments.Transforms {
+		for _, update := range route.Updates {
+			add("transforms", route.Path, update.Method)
+		}
+	}
+	for _, route := range adjustments.Policies {
+		for _, update := range route.Updates {
+			add("policies", route.Path, update.Method)
+		}
+	}
+	return refs
 }
@@ -1,10 +1,15 @@
 package parser
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/brizzai/auto-mcp/internal/models"
+	"github.com/brizzai/auto-mcp/internal/requester"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestAdjuster_ExistsInMCP(t *testing.T) {
@@ -171,6 +176,28 @@ func TestAdjuster_GetDescription(t *testing.T) {
 			origDesc: originalDesc,
 			want:     originalDesc,
 		},
+		{
+			name: "Route and method only have a description template",
+			adjuster: &Adjuster{
+				adjustments: &models.MCPAdjustments{
+					Descriptions: []models.RouteDescription{
+						{
+							Path: "/api/users",
+							Updates: []models.RouteFieldUpdate{
+								{
+									Method:   "GET",
+									Template: "{{ .summary }}",
+								},
+							},
+						},
+					},
+				},
+			},
+			route:    "/api/users",
+			method:   "GET",
+			origDesc: originalDesc,
+			want:     originalDesc,
+		},
 		{
 			name: "UpdateDescriptions is empty",
 			adjuster: &Adjuster{
@@ -202,3 +229,319 @@ func TestAdjuster_GetDescription(t *testing.T) {
 		})
 	}
 }
+
+func TestAdjuster_GetArgumentDefaults(t *testing.T) {
+	adjuster := &Adjuster{
+		adjustments: &models.MCPAdjustments{
+			Defaults: []models.RouteDefaults{
+				{
+					Path: "/orders",
+					Updates: []models.RouteDefaultUpdate{
+						{
+							Method:    "GET",
+							Arguments: map[string]string{"owner_email": "{{user.email}}"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		route  string
+		method string
+		want   map[string]string
+	}{
+		{
+			name:   "Route and method have defaults",
+			route:  "/orders",
+			method: "GET",
+			want:   map[string]string{"owner_email": "{{user.email}}"},
+		},
+		{
+			name:   "Method without defaults",
+			route:  "/orders",
+			method: "POST",
+			want:   nil,
+		},
+		{
+			name:   "Route without defaults",
+			route:  "/users",
+			method: "GET",
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, adjuster.GetArgumentDefaults(tt.route, tt.method))
+		})
+	}
+}
+
+func TestAdjuster_GetCacheMaxAge(t *testing.T) {
+	adjuster := &Adjuster{
+		adjustments: &models.MCPAdjustments{
+			Cache: []models.RouteCache{
+				{
+					Path:    "/stats",
+					Updates: []models.RouteCacheUpdate{{Method: "GET", MaxAge: "90s"}},
+				},
+			},
+		},
+	}
+
+	assert.Equal(t, 90*time.Second, adjuster.GetCacheMaxAge("/stats", "GET"))
+	assert.Zero(t, adjuster.GetCacheMaxAge("/stats", "POST"))
+	assert.Zero(t, adjuster.GetCacheMaxAge("/users", "GET"))
+}
+
+func TestAdjuster_LoadToolNames(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr string
+	}{
+		{
+			name: "valid",
+			yaml: `
+descriptions:
+  - path: /pets
+    updates:
+      - method: GET
+        new_name: list-pets`,
+		},
+		{
+			name: "invalid characters",
+			yaml: `
+descriptions:
+  - path: /pets
+    updates:
+      - method: GET
+        new_name: list pets`,
+			wantErr: `descriptions[GET /pets]: tool name "list pets" must be 1 to 64 letters, digits, underscores or hyphens`,
+		},
+		{
+			name: "duplicate",
+			yaml: `
+descriptions:
+  - path: /pets
+    updates:
+      - method: GET
+        new_name: pets
+      - method: POST
+        new_name: pets`,
+			wantErr: `descriptions[POST /pets]: new_name "pets" is already used by GET /pets`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "adjustments.yaml")
+			require.NoError(t, os.WriteFile(file, []byte(tt.yaml), 0o600))
+			err := NewAdjuster().Load(file)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAdjuster_Locale(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "adjustments.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(`
+description_template: "{{ .description }}"
+descriptions:
+  - path: /pets
+    updates:
+      - method: GET
+        new_description: List the pets
+      - method: POST
+        new_description: Add a pet
+locales:
+  de:
+    - path: /pets
+      updates:
+        - method: GET
+          new_description: Listet die Haustiere
+        - method: DELETE
+          template: "Löscht {{ .path }}"
+`), 0o600))
+
+	tests := []struct {
+		locale   string
+		get      string
+		post     string
+		template string
+	}{
+		{locale: "", get: "List the pets", post: "Add a pet", template: "{{ .description }}"},
+		{locale: "de", get: "Listet die Haustiere", post: "Add a pet", template: "Löscht {{ .path }}"},
+		{locale: "de_AT", get: "Listet die Haustiere", post: "Add a pet", template: "Löscht {{ .path }}"},
+		{locale: "fr", get: "List the pets", post: "Add a pet", template: "{{ .description }}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			adjuster := NewAdjuster()
+			adjuster.SetLocale(tt.locale)
+			require.NoError(t, adjuster.Load(file))
+			assert.Equal(t, tt.get, adjuster.GetDescription("/pets", "GET", "original"))
+			assert.Equal(t, tt.post, adjuster.GetDescription("/pets", "POST", "original"))
+			assert.Equal(t, tt.template, adjuster.GetDescriptionTemplate("/pets", "DELETE"))
+		})
+	}
+}
+
+func TestAdjuster_LoadLocales(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr string
+	}{
+		{
+			name: "new name",
+			yaml: `
+locales:
+  de:
+    - path: /pets
+      updates:
+        - method: GET
+          new_name: haustiere`,
+			wantErr: "locales.de[GET /pets]: new_name is not localized, set it in descriptions",
+		},
+		{
+			name: "invalid method",
+			yaml: `
+locales:
+  de:
+    - path: /pets
+      updates:
+        - method: FETCH
+          new_description: Listet die Haustiere`,
+			wantErr: "locales.de[FETCH /pets]: method must be one of GET, POST, PUT, PATCH, DELETE",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "adjustments.yaml")
+			require.NoError(t, os.WriteFile(file, []byte(tt.yaml), 0o600))
+			assert.EqualError(t, NewAdjuster().Load(file), tt.wantErr)
+		})
+	}
+}
+
+func TestAdjuster_LoadStrict(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr string
+	}{
+		{
+			name:    "empty file",
+			yaml:    "",
+			wantErr: "",
+		},
+		{
+			name: "misspelled section",
+			yaml: `
+desciptions:
+  - path: /pets
+    updates: []`,
+			wantErr: `line 2: unknown field "desciptions" in top level, did you mean "descriptions"?`,
+		},
+		{
+			name: "nested field",
+			yaml: `
+descriptions:
+  - path: /pets
+    updates:
+      - method: GET
+        new_descripton: List pets`,
+			wantErr: `line 6: unknown field "new_descripton" in descriptions[0].updates[0], did you mean "new_description"?`,
+		},
+		{
+			name: "unrelated field",
+			yaml: `
+cache:
+  - path: /pets
+    updates:
+      - method: GET
+        ttl: 30s`,
+			wantErr: `line 6: unknown field "ttl" in cache[0].updates[0], expected one of max_age, method`,
+		},
+		{
+			name: "unknown html mode",
+			yaml: `
+responses:
+  - path: /docs
+    updates:
+      - method: GET
+        html: pdf`,
+			wantErr: `responses[GET /docs]: html must be raw, markdown or text, got "pdf"`,
+		},
+		{
+			name: "lowercase method",
+			yaml: `
+routes:
+  - path: /pets
+    methods: [get]`,
+			wantErr: "routes[get /pets]: method must be one of GET, POST, PUT, PATCH, DELETE",
+		},
+		{
+			name: "relative path",
+			yaml: `
+routes:
+  - path: pets
+    methods: [GET]`,
+			wantErr: "routes[GET pets]: path must start with /",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "adjustments.yaml")
+			require.NoError(t, os.WriteFile(file, []byte(tt.yaml), 0o600))
+			err := NewAdjuster().Load(file)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+
+	err := NewAdjuster().Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.ErrorContains(t, err, "missing.yaml does not exist")
+	assert.NoError(t, NewAdjuster().Load(""))
+}
+
+func TestAdjuster_GetPolicy(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "adjustments.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(`
+policies:
+  - path: /reports
+    updates:
+      - method: GET
+        timeout: 2m
+        retries: 3
+        rate_limit: 10
+        cache_ttl: 30s
+`), 0o600))
+	adjuster := NewAdjuster()
+	require.NoError(t, adjuster.Load(file))
+
+	assert.Equal(t, requester.RoutePolicy{Timeout: 2 * time.Minute, Retries: 3, RateLimit: 10}, adjuster.GetPolicy("/reports", "GET"))
+	assert.Equal(t, 30*time.Second, adjuster.GetCacheMaxAge("/reports", "GET"))
+	assert.Zero(t, adjuster.GetPolicy("/reports", "POST"))
+}
+
+func TestValidatePolicyUpdate(t *testing.T) {
+	assert.NoError(t, validatePolicyUpdate(models.RoutePolicyUpdate{Method: "POST", Timeout: "500ms", Retries: 2, RateLimit: 60}, false))
+	assert.ErrorContains(t, validatePolicyUpdate(models.RoutePolicyUpdate{Method: "GET", Timeout: "soon"}, false), "timeout must be a positive duration")
+	assert.ErrorContains(t, validatePolicyUpdate(models.RoutePolicyUpdate{Method: "GET", Retries: 11}, false), "retries must be between 0 and 10")
+	assert.ErrorContains(t, validatePolicyUpdate(models.RoutePolicyUpdate{Method: "GET", RateLimit: -1}, false), "rate_limit")
+	assert.ErrorContains(t, validatePolicyUpdate(models.RoutePolicyUpdate{Method: "POST", CacheTTL: "30s"}, false), "only GET responses can be cached")
+	assert.ErrorContains(t, validatePolicyUpdate(models.RoutePolicyUpdate{Method: "GET", CacheTTL: "30s"}, true), "cache_ttl and the cache section")
+}
@@ -0,0 +1,56 @@
+package parser
+
+import (
+	"github.com/brizzai/auto-mcp/internal/models"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// methodAnnotations derives MCP tool hints from HTTP method semantics. Every
+// tool calls an external API, so the open world hint is always set.
+func methodAnnotations(method string) mcp.ToolAnnotation {
+	annotation := mcp.ToolAnnotation{
+		ReadOnlyHint:    mcp.ToBoolPtr(false),
+		DestructiveHint: mcp.ToBoolPtr(false),
+		IdempotentHint:  mcp.ToBoolPtr(false),
+		OpenWorldHint:   mcp.ToBoolPtr(true),
+	}
+
+	switch method {
+	case "GET", "HEAD", "OPTIONS":
+		annotation.ReadOnlyHint = mcp.ToBoolPtr(true)
+		annotation.IdempotentHint = mcp.ToBoolPtr(true)
+	case "PUT":
+		// Replaces the target resource
+		annotation.DestructiveHint = mcp.ToBoolPtr(true)
+		annotation.IdempotentHint = mcp.ToBoolPtr(true)
+	case "DELETE":
+		annotation.DestructiveHint = mcp.ToBoolPtr(true)
+		annotation.IdempotentHint = mcp.ToBoolPtr(true)
+	case "PATCH":
+		annotation.DestructiveHint = mcp.ToBoolPtr(true)
+	}
+	return annotation
+}
+
+// applyAnnotationOverrides replaces derived hints with those set in the adjustments file
+func applyAnnotationOverrides(annotation mcp.ToolAnnotation, override *models.RouteAnnotationUpdate) mcp.ToolAnnotation {
+	if override == nil {
+		return annotation
+	}
+	if override.Title != "" {
+		annotation.Title = override.Title
+	}
+	if override.ReadOnly != nil {
+		annotation.ReadOnlyHint = override.ReadOnly
+	}
+	if override.Destructive != nil {
+		annotation.DestructiveHint = override.Destructive
+	}
+	if override.Idempotent != nil {
+		annotation.IdempotentHint = override.Idempotent
+	}
+	if override.OpenWorld != nil {
+		annotation.OpenWorldHint = override.OpenWorld
+	}
+	return annotation
+}
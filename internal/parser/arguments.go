@@ -0,0 +1,172 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/brizzai/auto-mcp/internal/logger"
+	"github.com/brizzai/auto-mcp/internal/models"
+	"github.com/brizzai/auto-mcp/internal/requester"
+	"github.com/mark3labs/mcp-go/mcp"
+	"go.uber.org/zap"
+)
+
+// validateParameterUpdate checks the renames, hidden parameters and
+// descriptions of one method
+func validateParameterUpdate(update models.RouteParameterUpdate) error {
+	names := make(map[string]string, len(update.Rename))
+	for real, name := range update.Rename {
+		if name == "" || strings.Contains(name, ".") {
+			return fmt.Errorf("rename %s: new name %q must be non-empty and without dots", real, name)
+		}
+		// Fields of the same object may not end up with the same name
+		key := parentName(real) + name
+		if other, ok := names[key]; ok {
+			return fmt.Errorf("rename %s and %s: both renamed to %q", other, real, name)
+		}
+		names[key] = real
+	}
+	for _, hidden := range update.Hide {
+		if _, ok := update.Rename[hidden]; ok {
+			return fmt.Errorf("%s is both renamed and hidden", hidden)
+		}
+		if _, ok := update.Descriptions[hidden]; ok {
+			return fmt.Errorf("%s is both described and hidden", hidden)
+		}
+	}
+	return nil
+}
+
+// parentName returns the dotted prefix of a name, e.g. "body." for "body.name"
+func parentName(name string) string {
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		return name[:i+1]
+	}
+	return ""
+}
+
+// adjustArguments describes, hides and renames the tool's arguments as
+// configured for the route. Parameters missing from the schema are logged
+// and skipped.
+func adjustArguments(tool *mcp.Tool, route *requester.RouteConfig) error {
+	for name, description := range route.ArgumentDescriptions {
+		if !describeArgument(tool, name, description) {
+			logger.Warn("Described parameter not found", zap.String("tool", tool.Name), zap.String("parameter", name))
+		}
+	}
+	for _, name := range route.Hidden {
+		if !removeArgument(tool, name) {
+			logger.Warn("Hidden parameter not found", zap.String("tool", tool.Name), zap.String("parameter", name))
+		}
+	}
+	for real, name := range route.Renames {
+		found, err := renameArgument(tool, real, name)
+		if err != nil {
+			return fmt.Errorf("tool %s: %w", tool.Name, err)
+		}
+		if !found {
+			logger.Warn("Renamed parameter not found", zap.String("tool", tool.Name), zap.String("parameter", real))
+		}
+	}
+	return nil
+}
+
+// errArgumentExists is returned when a rename would replace another argument
+var errArgumentExists = errors.New("argument already exists")
+
+// lookupProperties resolves a dotted argument name to the properties map
+// holding it and the object schema owning that map, nil at the top level
+func lookupProperties(tool *mcp.Tool, name string) (properties, parent map[string]any, leaf string, ok bool) {
+	parts := strings.Split(name, ".")
+	properties = tool.InputSchema.Properties
+	for _, part := range parts[:len(parts)-1] {
+		schema, ok := properties[part].(map[string]any)
+		if !ok {
+			return nil, nil, "", false
+		}
+		parent = schema
+		if properties, ok = schema["properties"].(map[string]any); !ok {
+			return nil, nil, "", false
+		}
+	}
+	leaf = parts[len(parts)-1]
+	_, ok = properties[leaf]
+	return properties, parent, leaf, ok
+}
+
+// removeArgument removes an argument from a tool's input schema. Dotted
+// names such as "body.tenant_id" remove a property of an object argument.
+func removeArgument(tool *mcp.Tool, name string) bool {
+	properties, parent, leaf, ok := lookupProperties(tool, name)
+	if !ok {
+		return false
+	}
+	delete(properties, leaf)
+	setRequired(tool, parent, func(required []string) []string {
+		return without(required, leaf)
+	})
+	return true
+}
+
+// describeArgument replaces the description of an argument
+func describeArgument(tool *mcp.Tool, name, description string) bool {
+	properties, _, leaf, ok := lookupProperties(tool, name)
+	if !ok {
+		return false
+	}
+	schema, ok := properties[leaf].(map[string]any)
+	if !ok {
+		return false
+	}
+	schema["description"] = description
+	return true
+}
+
+// renameArgument gives an argument of a tool's input schema a new name
+func renameArgument(tool *mcp.Tool, real, name string) (bool, error) {
+	properties, parent, leaf, ok := lookupProperties(tool, real)
+	if !ok {
+		return false, nil
+	}
+	if _, exists := properties[name]; exists {
+		return true, fmt.Errorf("cannot rename %s to %s: %w", real, name, errArgumentExists)
+	}
+	properties[name] = properties[leaf]
+	delete(properties, leaf)
+	setRequired(tool, parent, func(required []string) []string {
+		renamed := make([]string, 0, len(required))
+		for _, n := range required {
+			if n == leaf {
+				n = name
+			}
+			renamed = append(renamed, n)
+		}
+		return renamed
+	})
+	return true, nil
+}
+
+// setRequired replaces the required list of the tool, or of parent when it
+// is set. Required lists may be shared with the spec, so update must return
+// a new slice rather than modify the one it gets.
+func setRequired(tool *mcp.Tool, parent map[string]any, update func([]string) []string) {
+	if parent == nil {
+		tool.InputSchema.Required = update(tool.InputSchema.Required)
+		return
+	}
+	if required := requiredNames(parent); required != nil {
+		parent["required"] = update(required)
+	}
+}
+
+// without returns a copy of names without name
+func without(names []string, name string) []string {
+	kept := make([]string, 0, len(names))
+	for _, n := range names {
+		if n != name {
+			kept = append(kept, n)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,158 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// Tool catalog formats
+const (
+	CatalogFormatTable    = "table"
+	CatalogFormatJSON     = "json"
+	CatalogFormatMarkdown = "markdown"
+)
+
+// maxTableDescription truncates descriptions in the table format
+const maxTableDescription = 60
+
+// catalogArgument is a tool argument as listed in the catalog
+type catalogArgument struct {
+	Name        string
+	Type        string
+	Required    bool
+	Description string
+}
+
+// WriteToolCatalog writes the tools sorted by name as an aligned table, a
+// JSON array of tool schemas or a Markdown document, e.g. to review the
+// generated MCP surface in a pull request
+func WriteToolCatalog(w io.Writer, tools []*RouteTool, format string) error {
+	sorted := make([]*RouteTool, len(tools))
+	copy(sorted, tools)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Tool.Name < sorted[j].Tool.Name })
+
+	switch format {
+	case "", CatalogFormatTable:
+		return writeCatalogTable(w, sorted)
+	case CatalogFormatJSON:
+		schemas := make([]ToolSchema, len(sorted))
+		for i, tool := range sorted {
+			schemas[i] = newToolSchema(tool)
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(schemas)
+	case CatalogFormatMarkdown:
+		return writeCatalogMarkdown(w, sorted)
+	default:
+		return fmt.Errorf("unknown format %q, expected %s, %s or %s",
+			format, CatalogFormatTable, CatalogFormatJSON, CatalogFormatMarkdown)
+	}
+}
+
+func writeCatalogTable(w io.Writer, tools []*RouteTool) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "NAME\tMETHOD\tPATH\tARGUMENTS\tDESCRIPTION")
+	for _, tool := range tools {
+		var args []string
+		for _, arg := range toolArguments(tool) {
+			if arg.Required {
+				args = append(args, arg.Name+"*")
+			} else {
+				args = append(args, arg.Name)
+			}
+		}
+		description := firstLine(toolDescription(tool))
+		if runes := []rune(description); len(runes) > maxTableDescription {
+			description = string(runes[:maxTableDescription-3]) + "..."
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n",
+			tool.Tool.Name, tool.RouteConfig.Method, tool.RouteConfig.Path, strings.Join(args, ","), description)
+	}
+	return tw.Flush()
+}
+
+func writeCatalogMarkdown(w io.Writer, tools []*RouteTool) error {
+	var b strings.Builder
+	b.WriteString("# Tools\n\n| Tool | Operation | Description |\n| --- | --- | --- |\n")
+	for _, tool := range tools {
+		fmt.Fprintf(&b, "| `%s` | `%s %s` | %s |\n",
+			tool.Tool.Name, tool.RouteConfig.Method, tool.RouteConfig.Path, markdownCell(firstLine(toolDescription(tool))))
+	}
+
+	for _, tool := range tools {
+		fmt.Fprintf(&b, "\n## `%s`\n\n`%s %s`\n", tool.Tool.Name, tool.RouteConfig.Method, tool.RouteConfig.Path)
+		if description := toolDescription(tool); description != "" {
+			fmt.Fprintf(&b, "\n%s\n", description)
+		}
+		args := toolArguments(tool)
+		if len(args) == 0 {
+			b.WriteString("\nNo arguments.\n")
+			continue
+		}
+		b.WriteString("\n| Argument | Type | Required | Description |\n| --- | --- | --- | --- |\n")
+		for _, arg := range args {
+			required := "no"
+			if arg.Required {
+				required = "yes"
+			}
+			fmt.Fprintf(&b, "| `%s` | %s | %s | %s |\n", arg.Name, arg.Type, required, markdownCell(arg.Description))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// toolArguments lists the input schema properties, required ones first
+func toolArguments(tool *RouteTool) []catalogArgument {
+	required := make(map[string]bool, len(tool.Tool.InputSchema.Required))
+	for _, name := range tool.Tool.InputSchema.Required {
+		required[name] = true
+	}
+
+	args := make([]catalogArgument, 0, len(tool.Tool.InputSchema.Properties))
+	for name, property := range tool.Tool.InputSchema.Properties {
+		arg := catalogArgument{Name: name, Required: required[name]}
+		if schema, ok := property.(map[string]any); ok {
+			arg.Type, _ = schema["type"].(string)
+			arg.Description, _ = schema["description"].(string)
+		}
+		args = append(args, arg)
+	}
+	sort.Slice(args, func(i, j int) bool {
+		if args[i].Required != args[j].Required {
+			return args[i].Required
+		}
+		return args[i].Name < args[j].Name
+	})
+	return args
+}
+
+// toolDescription returns the description without the leading
+// "METHOD /path" line the parser adds, which the catalog shows separately
+func toolDescription(tool *RouteTool) string {
+	description := strings.TrimSpace(tool.Tool.Description)
+	operation := tool.RouteConfig.Method + " " + tool.RouteConfig.Path
+	if rest, ok := strings.CutPrefix(description, operation); ok && (rest == "" || rest[0] == ' ' || rest[0] == '\n') {
+		description = strings.TrimSpace(rest)
+	}
+	return description
+}
+
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return strings.TrimSpace(s[:i])
+	}
+	return s
+}
+
+// markdownCell keeps a value on one table row
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,66 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/brizzai/auto-mcp/internal/models"
+	"github.com/brizzai/auto-mcp/internal/transform"
+)
+
+// compositeArgumentTypes are the JSON schema types a composite argument may have
+var compositeArgumentTypes = map[string]bool{
+	"": true, "string": true, "number": true, "integer": true, "boolean": true, "object": true, "array": true,
+}
+
+// validateCompositeTools checks names, argument types and templates
+func validateCompositeTools(tools []models.CompositeTool) error {
+	names := make(map[string]bool, len(tools))
+	for i, tool := range tools {
+		if tool.Name == "" {
+			return fmt.Errorf("composite_tools[%d]: name is required", i)
+		}
+		if names[tool.Name] {
+			return fmt.Errorf("composite_tools[%d]: duplicate tool name %q", i, tool.Name)
+		}
+		names[tool.Name] = true
+
+		for _, arg := range tool.Arguments {
+			if arg.Name == "" {
+				return fmt.Errorf("composite_tools[%s]: argument name is required", tool.Name)
+			}
+			if !compositeArgumentTypes[arg.Type] {
+				return fmt.Errorf("composite_tools[%s]: argument %s: unknown type %q", tool.Name, arg.Name, arg.Type)
+			}
+		}
+
+		if len(tool.Steps) == 0 {
+			return fmt.Errorf("composite_tools[%s]: at least one step is required", tool.Name)
+		}
+		steps := make(map[string]bool, len(tool.Steps))
+		for j, step := range tool.Steps {
+			if step.Tool == "" {
+				return fmt.Errorf("composite_tools[%s].steps[%d]: tool is required", tool.Name, j)
+			}
+			if step.Tool == tool.Name {
+				return fmt.Errorf("composite_tools[%s].steps[%d]: a composite tool cannot call itself", tool.Name, j)
+			}
+			name := step.Name
+			if name == "" {
+				name = step.Tool
+			}
+			if steps[name] {
+				return fmt.Errorf("composite_tools[%s].steps[%d]: duplicate step name %q, set name to tell the steps apart", tool.Name, j, name)
+			}
+			steps[name] = true
+			if _, err := transform.Compile(step.Arguments, nil, ""); err != nil {
+				return fmt.Errorf("composite_tools[%s].steps[%d]: %w", tool.Name, j, err)
+			}
+		}
+		if tool.Result != "" {
+			if _, err := transform.Parse("result", tool.Result); err != nil {
+				return fmt.Errorf("composite_tools[%s]: result: %w", tool.Name, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/brizzai/auto-mcp/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateCompositeTools(t *testing.T) {
+	step := models.CompositeStep{Tool: "post_orders"}
+	tests := []struct {
+		name  string
+		tools []models.CompositeTool
+		err   string
+	}{
+		{name: "valid", tools: []models.CompositeTool{{Name: "a", Steps: []models.CompositeStep{step, {Name: "again", Tool: "post_orders"}}}}},
+		{name: "missing name", tools: []models.CompositeTool{{Steps: []models.CompositeStep{step}}}, err: "composite_tools[0]: name is required"},
+		{name: "duplicate name", tools: []models.CompositeTool{
+			{Name: "a", Steps: []models.CompositeStep{step}},
+			{Name: "a", Steps: []models.CompositeStep{step}},
+		}, err: `composite_tools[1]: duplicate tool name "a"`},
+		{name: "no steps", tools: []models.CompositeTool{{Name: "a"}}, err: "composite_tools[a]: at least one step is required"},
+		{name: "unknown type", tools: []models.CompositeTool{{
+			Name: "a", Arguments: []models.CompositeArgument{{Name: "x", Type: "date"}}, Steps: []models.CompositeStep{step},
+		}}, err: `composite_tools[a]: argument x: unknown type "date"`},
+		{name: "duplicate step", tools: []models.CompositeTool{{Name: "a", Steps: []models.CompositeStep{step, step}}},
+			err: `composite_tools[a].steps[1]: duplicate step name "post_orders", set name to tell the steps apart`},
+		{name: "calls itself", tools: []models.CompositeTool{{Name: "a", Steps: []models.CompositeStep{{Tool: "a"}}}},
+			err: "composite_tools[a].steps[0]: a composite tool cannot call itself"},
+		{name: "invalid template", tools: []models.CompositeTool{{
+			Name: "a", Steps: []models.CompositeStep{{Tool: "b", Arguments: map[string]string{"id": "{{ .steps.x"}}},
+		}}, err: "composite_tools[a].steps[0]: invalid template"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateCompositeTools(tt.tools)
+			if tt.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package parser
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/brizzai/auto-mcp/internal/models"
+	"github.com/brizzai/auto-mcp/internal/requester"
+	"github.com/brizzai/auto-mcp/internal/transform"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// validateCustomTools checks the custom tool definitions on their own;
+// conflicts with generated tools are reported when the spec is parsed
+func validateCustomTools(tools []models.CustomTool) error {
+	names := make(map[string]bool, len(tools))
+	for i, tool := range tools {
+		if tool.Name == "" {
+			return fmt.Errorf("custom_tools[%d]: name is required", i)
+		}
+		if names[tool.Name] {
+			return fmt.Errorf("custom_tools[%d]: duplicate tool name %q", i, tool.Name)
+		}
+		names[tool.Name] = true
+
+		if !slices.Contains(operationMethods, tool.Method) {
+			return fmt.Errorf("custom_tools[%s]: method must be one of %s, got %q", tool.Name, strings.Join(operationMethods, ", "), tool.Method)
+		}
+		if !strings.HasPrefix(tool.Path, "/") {
+			return fmt.Errorf("custom_tools[%s]: path %q must start with /", tool.Name, tool.Path)
+		}
+		if tool.InputSchema != nil {
+			if schemaType, ok := tool.InputSchema["type"]; ok && schemaType != "object" {
+				return fmt.Errorf("custom_tools[%s]: input_schema must be an object schema", tool.Name)
+			}
+			if _, ok := tool.InputSchema["properties"].(map[string]any); !ok && tool.InputSchema["properties"] != nil {
+				return fmt.Errorf("custom_tools[%s]: input_schema.properties must be a map", tool.Name)
+			}
+		}
+		if tool.Body != "" {
+			if tool.Method == "GET" {
+				return fmt.Errorf("custom_tools[%s]: GET requests have no body", tool.Name)
+			}
+			if _, err := transform.Parse("body", tool.Body); err != nil {
+				return fmt.Errorf("custom_tools[%s]: body: %w", tool.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// addCustomTools appends the custom tools of the adjustments file to the
+// generated ones. Other per-route adjustments apply to them by method and path.
+func (p *SwaggerParser) addCustomTools() error {
+	names := make(map[string]bool, len(p.routeTools))
+	for _, routeTool := range p.routeTools {
+		names[routeTool.Tool.Name] = true
+	}
+
+	for _, def := range p.adjuster.GetCustomTools() {
+		if names[def.Name] {
+			return fmt.Errorf("custom tool %s conflicts with a tool generated from the spec", def.Name)
+		}
+		names[def.Name] = true
+
+		routeConfig := &requester.RouteConfig{
+			Path:        def.Path,
+			Method:      def.Method,
+			Description: def.Description,
+			Headers:     map[string]string{"Content-Type": "application/json"},
+			MethodConfig: requester.MethodConfig{
+				QueryParams:  make([]string, 0),
+				HeaderParams: def.HeaderParams,
+			},
+		}
+		maps.Copy(routeConfig.Headers, def.Headers)
+		p.applyRouteAdjustments(routeConfig)
+		if def.Body != "" {
+			// The body is rendered like a transformed argument, before any
+			// transform of the route itself
+			arguments := map[string]string{"body": def.Body}
+			maps.Copy(arguments, routeConfig.Transform.Arguments)
+			routeConfig.Transform.Arguments = arguments
+		}
+
+		tool := p.customTool(def)
+		for name := range routeConfig.Constants {
+			removeArgument(&tool, name)
+		}
+		if err := adjustArguments(&tool, routeConfig); err != nil {
+			return err
+		}
+		p.routeTools = append(p.routeTools, &RouteTool{
+			RouteConfig: routeConfig,
+			Tool:        tool,
+		})
+	}
+	return nil
+}
+
+// customTool builds the MCP tool of a custom tool definition. Path
+// parameters missing from the input schema are added as required strings.
+func (p *SwaggerParser) customTool(def models.CustomTool) mcp.Tool {
+	tool := mcp.NewTool(def.Name,
+		mcp.WithDescription(def.Description),
+		mcp.WithToolAnnotation(applyAnnotationOverrides(
+			methodAnnotations(def.Method),
+			p.adjuster.GetAnnotations(def.Path, def.Method),
+		)),
+	)
+
+	properties, _ := def.InputSchema["properties"].(map[string]any)
+	tool.InputSchema.Properties = maps.Clone(properties)
+	if tool.InputSchema.Properties == nil {
+		tool.InputSchema.Properties = make(map[string]any)
+	}
+	tool.InputSchema.Required = requiredNames(def.InputSchema)
+	for _, param := range extractPathParams(def.Path) {
+		if _, ok := tool.InputSchema.Properties[param]; !ok {
+			tool.InputSchema.Properties[param] = map[string]any{
+				"type":        "string",
+				"description": fmt.Sprintf("Path parameter: %s", param),
+			}
+		}
+		if !slices.Contains(tool.InputSchema.Required, param) {
+			tool.InputSchema.Required = append(tool.InputSchema.Required, param)
+		}
+	}
+	return tool
+}
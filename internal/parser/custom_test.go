@@ -0,0 +1,93 @@
+package parser
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/brizzai/auto-mcp/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateCustomTools(t *testing.T) {
+	tests := []struct {
+		name string
+		tool models.CustomTool
+		err  string
+	}{
+		{name: "valid", tool: models.CustomTool{Name: "a", Method: "POST", Path: "/a", Body: `{"x": {{ json .args.x }}}`}},
+		{name: "missing name", tool: models.CustomTool{Method: "GET", Path: "/a"}, err: "custom_tools[0]: name is required"},
+		{name: "bad method", tool: models.CustomTool{Name: "a", Method: "get", Path: "/a"}, err: `custom_tools[a]: method must be one of GET, POST, PUT, PATCH, DELETE, got "get"`},
+		{name: "relative path", tool: models.CustomTool{Name: "a", Method: "GET", Path: "a"}, err: `custom_tools[a]: path "a" must start with /`},
+		{name: "array schema", tool: models.CustomTool{Name: "a", Method: "GET", Path: "/a", InputSchema: map[string]any{"type": "array"}},
+			err: "custom_tools[a]: input_schema must be an object schema"},
+		{name: "GET body", tool: models.CustomTool{Name: "a", Method: "GET", Path: "/a", Body: "{}"}, err: "custom_tools[a]: GET requests have no body"},
+		{name: "invalid body", tool: models.CustomTool{Name: "a", Method: "POST", Path: "/a", Body: "{{ .args"}, err: "custom_tools[a]: body: invalid template"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateCustomTools([]models.CustomTool{tt.tool})
+			if tt.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+
+	err := validateCustomTools([]models.CustomTool{{Name: "a", Method: "GET", Path: "/a"}, {Name: "a", Method: "GET", Path: "/b"}})
+	assert.EqualError(t, err, `custom_tools[1]: duplicate tool name "a"`)
+}
+
+func TestSwaggerParser_CustomTools(t *testing.T) {
+	openapiSpec := []byte(`{
+		"openapi": "3.0.0",
+		"info": {"title": "Test API", "version": "1.0.0"},
+		"paths": {"/pets": {"get": {"summary": "List pets"}}}
+	}`)
+
+	adjuster := NewAdjuster()
+	adjuster.adjustments.Routes = []models.RouteSelection{{Path: "/pets", Methods: []string{"GET"}}}
+	adjuster.adjustments.CustomTools = []models.CustomTool{{
+		Name:        "reindex_region",
+		Description: "Rebuild the search index of a region",
+		Method:      "POST",
+		Path:        "/internal/regions/{region}/reindex",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"full": map[string]any{"type": "boolean", "description": "Rebuild from scratch"},
+			},
+			"required": []any{"full"},
+		},
+		HeaderParams: []string{"X-Request-Reason"},
+		Headers:      map[string]string{"X-Internal": "1"},
+		Body:         `{"full": {{ .args.full }}}`,
+	}}
+	adjuster.adjustments.SuccessCriteria = []models.RouteSuccessCriteria{
+		{Path: "/internal/regions/{region}/reindex", Updates: []models.RouteSuccessUpdate{{Method: "POST", Expression: "$.ok"}}},
+	}
+	parser := NewSwaggerParser(adjuster)
+	require.NoError(t, parser.ParseReader(bytes.NewReader(openapiSpec)))
+
+	tools := parser.GetRouteTools()
+	require.Len(t, tools, 2)
+	custom := tools[1]
+	assert.Equal(t, "reindex_region", custom.Tool.Name)
+	assert.Equal(t, "Rebuild the search index of a region", custom.Tool.Description)
+	assert.ElementsMatch(t, []string{"full", "region"}, custom.Tool.InputSchema.Required)
+	assert.Contains(t, custom.Tool.InputSchema.Properties, "region")
+	assert.False(t, *custom.Tool.Annotations.ReadOnlyHint)
+
+	assert.Equal(t, "POST", custom.RouteConfig.Method)
+	assert.Equal(t, "1", custom.RouteConfig.Headers["X-Internal"])
+	assert.Equal(t, []string{"X-Request-Reason"}, custom.RouteConfig.MethodConfig.HeaderParams)
+	assert.Equal(t, `{"full": {{ .args.full }}}`, custom.RouteConfig.Transform.Arguments["body"])
+	assert.Equal(t, "$.ok", custom.RouteConfig.SuccessCriteria)
+
+	// Custom tools may not shadow generated tools
+	adjuster.adjustments.CustomTools = []models.CustomTool{{Name: "get_pets", Method: "GET", Path: "/other"}}
+	parser = NewSwaggerParser(adjuster)
+	assert.EqualError(t, parser.ParseReader(bytes.NewReader(openapiSpec)), "custom tool get_pets conflicts with a tool generated from the spec")
+}
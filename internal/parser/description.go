@@ -0,0 +1,124 @@
+package parser
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/brizzai/auto-mcp/internal/requester"
+	"github.com/brizzai/auto-mcp/internal/transform"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// defaultDescription is the tool description used without a template
+func defaultDescription(route *requester.RouteConfig) string {
+	return fmt.Sprintf("%s %s \n %s", route.Method, route.Path, route.Description)
+}
+
+// ToolEdits are changes to a generated tool, as made in the config builder
+type ToolEdits struct {
+	// Name and Description replace the tool name and route description when set
+	Name        string
+	Description string
+	// Hidden parameters are removed from the tool; ArgumentDescriptions
+	// replace the descriptions of parameters, by real name
+	Hidden               []string
+	ArgumentDescriptions map[string]string
+}
+
+// PreviewTool returns the tool routeTool generates with edits. The input
+// schema of routeTool is left unchanged.
+func PreviewTool(routeTool *RouteTool, edits ToolEdits) mcp.Tool {
+	tool := routeTool.Tool
+	if edits.Name != "" {
+		tool.Name = edits.Name
+	}
+	if edits.Description != "" {
+		route := *routeTool.RouteConfig
+		route.Description = edits.Description
+		tool.Description = defaultDescription(&route)
+	}
+
+	tool.InputSchema.Properties = cloneSchema(tool.InputSchema.Properties)
+	for name, description := range edits.ArgumentDescriptions {
+		describeArgument(&tool, name, description)
+	}
+	for _, name := range edits.Hidden {
+		removeArgument(&tool, name)
+	}
+	return tool
+}
+
+// cloneSchema copies schema and the schemas nested in it
+func cloneSchema(schema map[string]any) map[string]any {
+	if schema == nil {
+		return nil
+	}
+	cloned := make(map[string]any, len(schema))
+	for key, value := range schema {
+		if nested, ok := value.(map[string]any); ok {
+			value = cloneSchema(nested)
+		}
+		cloned[key] = value
+	}
+	return cloned
+}
+
+// describeTool renders the tool description from the route's description
+// template, if the adjustments define one
+func (p *SwaggerParser) describeTool(tool *mcp.Tool, route *requester.RouteConfig, operation *openapi3.Operation) error {
+	text := p.adjuster.GetDescriptionTemplate(route.Path, route.Method)
+	if text == "" {
+		return nil
+	}
+	tmpl, err := transform.Parse("description", text)
+	if err != nil {
+		return fmt.Errorf("description template of %s %s: %w", route.Method, route.Path, err)
+	}
+
+	description, err := transform.Render(tmpl, descriptionData(tool, route, operation))
+	if err != nil {
+		return fmt.Errorf("description template of %s %s: %w", route.Method, route.Path, err)
+	}
+	tool.Description = description
+	return nil
+}
+
+// descriptionData exposes the operation's metadata and the tool's arguments
+// to description templates
+func descriptionData(tool *mcp.Tool, route *requester.RouteConfig, operation *openapi3.Operation) map[string]any {
+	names := make([]string, 0, len(tool.InputSchema.Properties))
+	for name := range tool.InputSchema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	params := make([]map[string]any, 0, len(names))
+	for _, name := range names {
+		schema, _ := tool.InputSchema.Properties[name].(map[string]any)
+		description, _ := schema["description"].(string)
+		params = append(params, map[string]any{
+			"name":        name,
+			"type":        schemaType(schema),
+			"required":    slices.Contains(tool.InputSchema.Required, name),
+			"description": description,
+		})
+	}
+
+	tags := route.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return map[string]any{
+		"tool":         tool.Name,
+		"method":       route.Method,
+		"path":         route.Path,
+		"description":  route.Description,
+		"summary":      operation.Summary,
+		"operation_id": operation.OperationID,
+		"deprecated":   operation.Deprecated,
+		"tags":         tags,
+		"params":       params,
+	}
+}
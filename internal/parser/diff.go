@@ -0,0 +1,328 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Spec diff formats
+const (
+	DiffFormatText = "text"
+	DiffFormatJSON = "json"
+)
+
+// SpecDiff is the tool-level difference between two versions of a spec
+type SpecDiff struct {
+	Added   []string     `json:"added"`
+	Removed []string     `json:"removed"`
+	Changed []ToolChange `json:"changed"`
+	// StaleAdjustments are adjustments entries that match no operation of
+	// the new spec, or prompts and composite tools referencing a tool it no
+	// longer generates
+	StaleAdjustments []string `json:"stale_adjustments"`
+}
+
+// ToolChange lists what changed in a tool present in both versions
+type ToolChange struct {
+	Name    string   `json:"name"`
+	Changes []string `json:"changes"`
+	// Breaking is set when calls valid against the old tool may fail
+	// against the new one: removed or retyped arguments, or new required ones
+	Breaking bool `json:"breaking"`
+}
+
+// Empty reports whether the versions generate the same tools
+func (d *SpecDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0 && len(d.StaleAdjustments) == 0
+}
+
+// DiffSpecs parses both spec files with the same adjustments file, which
+// may be empty, and compares the generated tools
+func DiffSpecs(oldSpec, newSpec, adjustmentsFile string) (*SpecDiff, error) {
+	if adjustmentsFile != "" {
+		// The parser ignores a missing adjustments file
+		if _, err := os.Stat(adjustmentsFile); err != nil {
+			return nil, err
+		}
+	}
+
+	oldParser := NewSwaggerParser(NewAdjuster())
+	if err := oldParser.Init(oldSpec, adjustmentsFile); err != nil {
+		return nil, fmt.Errorf("%s: %w", oldSpec, err)
+	}
+	adjuster := NewAdjuster()
+	newParser := NewSwaggerParser(adjuster)
+	if err := newParser.Init(newSpec, adjustmentsFile); err != nil {
+		return nil, fmt.Errorf("%s: %w", newSpec, err)
+	}
+
+	diff := DiffTools(oldParser.GetRouteTools(), newParser.GetRouteTools())
+	if adjustmentsFile == "" {
+		return diff, nil
+	}
+
+	for _, ref := range adjuster.Unmatched(newParser.operations()) {
+		diff.StaleAdjustments = append(diff.StaleAdjustments, fmt.Sprintf("%s: %s %s", ref.Section, ref.Method, ref.Path))
+	}
+
+	toolNames := make(map[string]bool)
+	for _, tool := range newParser.GetRouteTools() {
+		toolNames[tool.Tool.Name] = true
+	}
+	for _, composite := range newParser.GetCompositeTools() {
+		toolNames[composite.Name] = true
+	}
+	for _, composite := range newParser.GetCompositeTools() {
+		for _, step := range composite.Steps {
+			if !toolNames[step.Tool] {
+				diff.StaleAdjustments = append(diff.StaleAdjustments, fmt.Sprintf("composite_tools: %s uses unknown tool %s", composite.Name, step.Tool))
+			}
+		}
+	}
+	for _, prompt := range newParser.GetPrompts() {
+		for _, name := range prompt.Tools {
+			if !toolNames[name] {
+				diff.StaleAdjustments = append(diff.StaleAdjustments, fmt.Sprintf("prompts: %s uses unknown tool %s", prompt.Name, name))
+			}
+		}
+	}
+	return diff, nil
+}
+
+// DiffTools compares tools by name
+func DiffTools(oldTools, newTools []*RouteTool) *SpecDiff {
+	oldByName := make(map[string]*RouteTool, len(oldTools))
+	for _, tool := range oldTools {
+		oldByName[tool.Tool.Name] = tool
+	}
+	newByName := make(map[string]*RouteTool, len(newTools))
+	for _, tool := range newTools {
+		newByName[tool.Tool.Name] = tool
+	}
+
+	diff := &SpecDiff{Added: []string{}, Removed: []string{}, Changed: []ToolChange{}, StaleAdjustments: []string{}}
+	for name, newTool := range newByName {
+		oldTool, ok := oldByName[name]
+		if !ok {
+			diff.Added = append(diff.Added, name)
+			continue
+		}
+		if change := diffTool(oldTool, newTool); len(change.Changes) > 0 {
+			diff.Changed = append(diff.Changed, change)
+		}
+	}
+	for name := range oldByName {
+		if _, ok := newByName[name]; !ok {
+			diff.Removed = append(diff.Removed, name)
+		}
+	}
+
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	sort.Slice(diff.Changed, func(i, j int) bool { return diff.Changed[i].Name < diff.Changed[j].Name })
+	return diff
+}
+
+// changeFunc records one change of a tool
+type changeFunc func(breaking bool, format string, args ...any)
+
+func diffTool(oldTool, newTool *RouteTool) ToolChange {
+	change := ToolChange{Name: newTool.Tool.Name}
+	add := func(breaking bool, format string, args ...any) {
+		description := fmt.Sprintf(format, args...)
+		if breaking {
+			description += " (breaking)"
+			change.Breaking = true
+		}
+		change.Changes = append(change.Changes, description)
+	}
+
+	oldOperation := oldTool.RouteConfig.Method + " " + oldTool.RouteConfig.Path
+	newOperation := newTool.RouteConfig.Method + " " + newTool.RouteConfig.Path
+	if oldOperation != newOperation {
+		add(false, "operation: %s -> %s", oldOperation, newOperation)
+	}
+	if toolDescription(oldTool) != toolDescription(newTool) {
+		add(false, "description changed")
+	}
+
+	diffProperties(add, "",
+		oldTool.Tool.InputSchema.Properties, oldTool.Tool.InputSchema.Required,
+		newTool.Tool.InputSchema.Properties, newTool.Tool.InputSchema.Required)
+
+	if !sameSchema(oldTool, newTool) {
+		add(false, "response schema changed")
+	}
+	return change
+}
+
+// diffProperties compares the properties of two object schemas; nested
+// properties are named by their dotted path, e.g. "body.owner.name"
+func diffProperties(add changeFunc, prefix string, oldProps map[string]any, oldRequired []string, newProps map[string]any, newRequired []string) {
+	names := make([]string, 0, len(oldProps)+len(newProps))
+	for name := range newProps {
+		names = append(names, name)
+	}
+	for name := range oldProps {
+		if _, ok := newProps[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		oldSchema, inOld := oldProps[name]
+		newSchema, inNew := newProps[name]
+		wasRequired, required := slices.Contains(oldRequired, name), slices.Contains(newRequired, name)
+		argument := prefix + name
+		switch {
+		case !inOld && required:
+			add(true, "argument added: %s (%s, required)", argument, schemaType(newSchema))
+		case !inOld:
+			add(false, "argument added: %s (%s)", argument, schemaType(newSchema))
+		case !inNew:
+			add(true, "argument removed: %s", argument)
+		default:
+			diffSchema(add, argument, oldSchema, newSchema)
+			if !wasRequired && required {
+				add(true, "argument %s: now required", argument)
+			} else if wasRequired && !required {
+				add(false, "argument %s: no longer required", argument)
+			}
+		}
+	}
+}
+
+// diffSchema compares the schemas of an argument, descending into object
+// properties and array items
+func diffSchema(add changeFunc, argument string, oldSchema, newSchema any) {
+	oldMap, _ := oldSchema.(map[string]any)
+	newMap, _ := newSchema.(map[string]any)
+	if oldType, newType := schemaType(oldMap), schemaType(newMap); oldType != newType {
+		add(true, "argument %s: type %s -> %s", argument, oldType, newType)
+		return
+	}
+	if oldMap["description"] != newMap["description"] {
+		add(false, "argument %s: description changed", argument)
+	}
+
+	ignored := []string{"description"}
+	if _, ok := newMap["properties"]; ok {
+		oldProps, _ := oldMap["properties"].(map[string]any)
+		newProps, _ := newMap["properties"].(map[string]any)
+		diffProperties(add, argument+".", oldProps, requiredNames(oldMap), newProps, requiredNames(newMap))
+		ignored = append(ignored, "properties", "required")
+	}
+	if _, ok := newMap["items"]; ok {
+		diffSchema(add, argument+"[]", oldMap["items"], newMap["items"])
+		ignored = append(ignored, "items")
+	}
+	if !reflect.DeepEqual(withoutKeys(oldMap, ignored), withoutKeys(newMap, ignored)) {
+		add(true, "argument %s: schema changed", argument)
+	}
+}
+
+func schemaType(schema any) string {
+	if m, ok := schema.(map[string]any); ok {
+		if t, ok := m["type"].(string); ok {
+			return t
+		}
+	}
+	return "any"
+}
+
+// requiredNames reads the required list of a schema decoded from JSON or
+// built by mcp-go
+func requiredNames(schema map[string]any) []string {
+	switch required := schema["required"].(type) {
+	case []string:
+		return required
+	case []any:
+		names := make([]string, 0, len(required))
+		for _, name := range required {
+			if s, ok := name.(string); ok {
+				names = append(names, s)
+			}
+		}
+		return names
+	}
+	return nil
+}
+
+func withoutKeys(schema map[string]any, keys []string) map[string]any {
+	copied := make(map[string]any, len(schema))
+	for key, value := range schema {
+		if !slices.Contains(keys, key) {
+			copied[key] = value
+		}
+	}
+	return copied
+}
+
+// sameSchema compares the success response schemas
+func sameSchema(oldTool, newTool *RouteTool) bool {
+	oldSchema, _ := json.Marshal(oldTool.OutputSchema)
+	newSchema, _ := json.Marshal(newTool.OutputSchema)
+	return string(oldSchema) == string(newSchema)
+}
+
+// Write prints the diff as text or JSON
+func (d *SpecDiff) Write(w io.Writer, format string) error {
+	switch format {
+	case "", DiffFormatText:
+	case DiffFormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(d)
+	default:
+		return fmt.Errorf("unknown format %q, expected %s or %s", format, DiffFormatText, DiffFormatJSON)
+	}
+
+	if d.Empty() {
+		_, err := io.WriteString(w, "No tool changes\n")
+		return err
+	}
+
+	var b strings.Builder
+	section := func(title string, count int) {
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "%s (%d):\n", title, count)
+	}
+	if len(d.Added) > 0 {
+		section("Added tools", len(d.Added))
+		for _, name := range d.Added {
+			fmt.Fprintf(&b, "  + %s\n", name)
+		}
+	}
+	if len(d.Removed) > 0 {
+		section("Removed tools", len(d.Removed))
+		for _, name := range d.Removed {
+			fmt.Fprintf(&b, "  - %s\n", name)
+		}
+	}
+	if len(d.Changed) > 0 {
+		section("Changed tools", len(d.Changed))
+		for _, change := range d.Changed {
+			fmt.Fprintf(&b, "  ~ %s\n", change.Name)
+			for _, description := range change.Changes {
+				fmt.Fprintf(&b, "      %s\n", description)
+			}
+		}
+	}
+	if len(d.StaleAdjustments) > 0 {
+		section("Adjustments matching nothing", len(d.StaleAdjustments))
+		for _, entry := range d.StaleAdjustments {
+			fmt.Fprintf(&b, "  ! %s\n", entry)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,135 @@
+package parser
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const diffOldSpec = `{
+  "openapi": "3.0.0",
+  "info": {"title": "Pets", "version": "1"},
+  "paths": {
+    "/pets": {
+      "get": {
+                "summary": "List pets",
+        "parameters": [{"name": "limit", "in": "query", "schema": {"type": "integer"}}],
+        "responses": {"200": {"description": "ok"}}
+      },
+      "post": {
+        "summary": "Add a pet",
+        "requestBody": {"required": true, "content": {"application/json": {"schema": {
+          "type": "object", "required": ["name"],
+          "properties": {"name": {"type": "string"}, "age": {"type": "integer"}}
+        }}}},
+        "responses": {"201": {"description": "created"}}
+      }
+    },
+    "/pets/{id}": {
+      "get": {
+                "summary": "Get a pet",
+        "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "integer"}}],
+        "responses": {"200": {"description": "ok"}}
+      },
+      "delete": {
+                "summary": "Delete a pet",
+        "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "integer"}}],
+        "responses": {"204": {"description": "deleted"}}
+      }
+    }
+  }
+}`
+
+const diffNewSpec = `{
+  "openapi": "3.0.0",
+  "info": {"title": "Pets", "version": "2"},
+  "paths": {
+    "/pets": {
+      "get": {
+                "summary": "List pets",
+        "parameters": [
+          {"name": "limit", "in": "query", "schema": {"type": "integer"}},
+          {"name": "owner", "in": "query", "schema": {"type": "string"}}
+        ],
+        "responses": {"200": {"description": "ok"}}
+      },
+      "post": {
+        "summary": "Add a pet",
+        "requestBody": {"required": true, "content": {"application/json": {"schema": {
+          "type": "object", "required": ["name"],
+          "properties": {"name": {"type": "string"}, "age": {"type": "string"}}
+        }}}},
+        "responses": {"201": {"description": "created"}}
+      }
+    },
+    "/pets/{id}": {
+      "get": {
+                "summary": "Get a pet by ID",
+        "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}],
+        "responses": {"200": {"description": "ok"}}
+      }
+    },
+    "/owners": {
+      "get": {
+                "summary": "List owners",
+        "responses": {"200": {"description": "ok"}}
+      }
+    }
+  }
+}`
+
+func TestDiffSpecs(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		file := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+		return file
+	}
+	oldSpec := write("old.json", diffOldSpec)
+	newSpec := write("new.json", diffNewSpec)
+	adjustments := write("adjustments.yaml", `
+descriptions:
+  - path: /pets/{id}
+    updates:
+      - method: DELETE
+        new_description: Remove a pet for good
+cache:
+  - path: /pets
+    updates:
+      - method: GET
+        max_age: 30s
+prompts:
+  - name: cleanup
+    tools: [delete_pets_id]
+`)
+
+	diff, err := DiffSpecs(oldSpec, newSpec, adjustments)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"get_owners"}, diff.Added)
+	assert.Equal(t, []string{"delete_pets_id"}, diff.Removed)
+	assert.Equal(t, []ToolChange{
+		{Name: "get_pets", Changes: []string{"argument added: owner (string)"}},
+		{Name: "get_pets_id", Changes: []string{"description changed"}},
+		{Name: "post_pets", Breaking: true, Changes: []string{"argument body.age: type integer -> string (breaking)"}},
+	}, diff.Changed)
+	assert.Equal(t, []string{
+		"descriptions: DELETE /pets/{id}",
+		"prompts: cleanup uses unknown tool delete_pets_id",
+	}, diff.StaleAdjustments)
+
+	var out bytes.Buffer
+	require.NoError(t, diff.Write(&out, DiffFormatText))
+	assert.Contains(t, out.String(), "Removed tools (1):\n  - delete_pets_id\n")
+	assert.Contains(t, out.String(), "  ~ get_pets_id\n      description changed\n")
+
+	same, err := DiffSpecs(oldSpec, oldSpec, "")
+	require.NoError(t, err)
+	assert.True(t, same.Empty())
+
+	_, err = DiffSpecs(oldSpec, newSpec, filepath.Join(dir, "missing.yaml"))
+	assert.Error(t, err)
+}
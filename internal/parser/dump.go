@@ -0,0 +1,110 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// maxInlineDepth stops inlining recursive schemas, leaving a $ref in place
+const maxInlineDepth = 10
+
+// ToolSchema is the exported description of a generated tool
+type ToolSchema struct {
+	Name         string                 `json:"name"`
+	Description  string                 `json:"description"`
+	InputSchema  mcp.ToolInputSchema    `json:"inputSchema"`
+	OutputSchema map[string]interface{} `json:"outputSchema,omitempty"`
+	Source       ToolSource             `json:"source"`
+}
+
+// ToolSource identifies the OpenAPI operation a tool was generated from
+type ToolSource struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+}
+
+// WriteToolSchemas writes one <tool name>.json file per tool into dir
+func WriteToolSchemas(dir string, tools []*RouteTool) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	for _, tool := range tools {
+		data, err := json.MarshalIndent(newToolSchema(tool), "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode tool %s: %w", tool.Tool.Name, err)
+		}
+		path := filepath.Join(dir, tool.Tool.Name+".json")
+		if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+func newToolSchema(tool *RouteTool) ToolSchema {
+	schema := ToolSchema{
+		Name:        tool.Tool.Name,
+		Description: tool.Tool.Description,
+		InputSchema: tool.Tool.InputSchema,
+		Source: ToolSource{
+			Method: tool.RouteConfig.Method,
+			Path:   tool.RouteConfig.Path,
+		},
+	}
+	if tool.OutputSchema != nil {
+		schema.OutputSchema = inlineSchema(tool.OutputSchema, 0)
+	}
+	return schema
+}
+
+// inlineSchema converts a schema to plain JSON, resolving $refs so the file is
+// self contained
+func inlineSchema(ref *openapi3.SchemaRef, depth int) map[string]interface{} {
+	if ref == nil || ref.Value == nil {
+		return map[string]interface{}{}
+	}
+	if depth > maxInlineDepth && ref.Ref != "" {
+		return map[string]interface{}{"$ref": ref.Ref}
+	}
+
+	schema := ref.Value
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return map[string]interface{}{}
+	}
+	var out map[string]interface{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return map[string]interface{}{}
+	}
+
+	if len(schema.Properties) > 0 {
+		properties := make(map[string]interface{}, len(schema.Properties))
+		for name, property := range schema.Properties {
+			properties[name] = inlineSchema(property, depth+1)
+		}
+		out["properties"] = properties
+	}
+	if schema.Items != nil {
+		out["items"] = inlineSchema(schema.Items, depth+1)
+	}
+	if schema.AdditionalProperties.Schema != nil {
+		out["additionalProperties"] = inlineSchema(schema.AdditionalProperties.Schema, depth+1)
+	}
+	for key, refs := range map[string]openapi3.SchemaRefs{"allOf": schema.AllOf, "oneOf": schema.OneOf, "anyOf": schema.AnyOf} {
+		if len(refs) == 0 {
+			continue
+		}
+		inlined := make([]interface{}, len(refs))
+		for i, item := range refs {
+			inlined[i] = inlineSchema(item, depth+1)
+		}
+		out[key] = inlined
+	}
+	return out
+}
@@ -1,6 +1,9 @@
 package parser
 
-import "go.uber.org/fx"
+import (
+	"github.com/brizzai/auto-mcp/internal/config"
+	"go.uber.org/fx"
+)
 
 // Module provides the parser dependencies
 var Module = fx.Module("parser",
@@ -9,6 +12,13 @@ var Module = fx.Module("parser",
 			NewSwaggerParser,
 			fx.As(new(Parser)),
 		),
-		NewAdjuster,
+		newConfiguredAdjuster,
 	),
 )
+
+// newConfiguredAdjuster creates an Adjuster for the locale of cfg
+func newConfiguredAdjuster(cfg *config.Config) *Adjuster {
+	adjuster := NewAdjuster()
+	adjuster.SetLocale(cfg.Server.Locale)
+	return adjuster
+}
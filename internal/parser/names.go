@@ -0,0 +1,50 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/brizzai/auto-mcp/internal/logger"
+	"go.uber.org/zap"
+)
+
+// toolNamePattern is the tool name format MCP clients accept
+var toolNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+// ValidToolName reports why name can't be used as a tool name, or nil
+func ValidToolName(name string) error {
+	if !toolNamePattern.MatchString(name) {
+		return fmt.Errorf("tool name %q must be 1 to 64 letters, digits, underscores or hyphens", name)
+	}
+	return nil
+}
+
+// checkToolNames reports tools sharing a name. Renamed tools must be unique;
+// generated names that collide, e.g. /pets/{id} and /pets/id, only warn so
+// existing specs keep loading.
+func (p *SwaggerParser) checkToolNames() error {
+	operations := make(map[string][]string)
+	renamed := make(map[string]bool)
+	for _, routeTool := range p.routeTools {
+		route := routeTool.RouteConfig
+		operations[routeTool.Tool.Name] = append(operations[routeTool.Tool.Name], route.Method+" "+route.Path)
+		if p.adjuster.GetToolName(route.Path, route.Method) != "" {
+			renamed[routeTool.Tool.Name] = true
+		}
+	}
+
+	for name, ops := range operations {
+		if len(ops) < 2 {
+			continue
+		}
+		sort.Strings(ops)
+		if renamed[name] {
+			return fmt.Errorf("descriptions: tool name %s is used by %s", name, strings.Join(ops, " and "))
+		}
+		logger.Warn("Operations generate the same tool name, rename one with new_name",
+			zap.String("tool", name), zap.Strings("operations", ops))
+	}
+	return nil
+}
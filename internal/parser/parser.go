@@ -4,10 +4,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"maps"
 	"os"
+	"slices"
 	"strings"
 
 	"github.com/brizzai/auto-mcp/internal/logger"
+	"github.com/brizzai/auto-mcp/internal/models"
 	"github.com/brizzai/auto-mcp/internal/requester"
 	"github.com/getkin/kin-openapi/openapi2"
 	"github.com/getkin/kin-openapi/openapi2conv"
@@ -32,6 +35,16 @@ func (p *SwaggerParser) GetRouteTools() []*RouteTool {
 	return p.routeTools
 }
 
+// GetPrompts returns the workflow prompts from the adjustments file
+func (p *SwaggerParser) GetPrompts() []models.Prompt {
+	return p.adjuster.GetPrompts()
+}
+
+// GetCompositeTools returns the composite tools from the adjustments file
+func (p *SwaggerParser) GetCompositeTools() []models.CompositeTool {
+	return p.adjuster.GetCompositeTools()
+}
+
 // generateTool creates an MCP tool from a route configuration
 func (p *SwaggerParser) generateTool(route *requester.RouteConfig) mcp.Tool {
 	// Create a tool name from the path and method
@@ -40,15 +53,29 @@ func (p *SwaggerParser) generateTool(route *requester.RouteConfig) mcp.Tool {
 	path = strings.ReplaceAll(path, "{", "")
 	path = strings.ReplaceAll(path, "}", "")
 	toolName := strings.ToLower(fmt.Sprintf("%s_%s", route.Method, path))
+	if name := p.adjuster.GetToolName(route.Path, route.Method); name != "" {
+		toolName = name
+	}
 
 	// Create tool options
 	opts := []mcp.ToolOption{
-		mcp.WithDescription(fmt.Sprintf("%s %s \n %s", route.Method, route.Path, route.Description)),
+		mcp.WithDescription(defaultDescription(route)),
+		mcp.WithToolAnnotation(applyAnnotationOverrides(
+			methodAnnotations(route.Method),
+			p.adjuster.GetAnnotations(route.Path, route.Method),
+		)),
 	}
 
 	// Add path parameters
 	pathParams := extractPathParams(route.Path)
 	for _, param := range pathParams {
+		// Parameters with a configured default may be omitted by the caller
+		if def, ok := route.Defaults[param]; ok {
+			opts = append(opts, mcp.WithString(param,
+				mcp.Description(fmt.Sprintf("Path parameter: %s (defaults to %s)", param, def)),
+			))
+			continue
+		}
 		opts = append(opts, mcp.WithString(param,
 			mcp.Required(),
 			mcp.Description(fmt.Sprintf("Path parameter: %s", param)),
@@ -58,12 +85,27 @@ func (p *SwaggerParser) generateTool(route *requester.RouteConfig) mcp.Tool {
 	// Add query parameters
 	if route.MethodConfig.QueryParams != nil {
 		for _, param := range route.MethodConfig.QueryParams {
+			desc := fmt.Sprintf("Query parameter: %s", param)
+			if def, ok := route.Defaults[param]; ok {
+				desc = fmt.Sprintf("%s (defaults to %s)", desc, def)
+			}
 			opts = append(opts, mcp.WithString(param,
-				mcp.Description(fmt.Sprintf("Query parameter: %s", param)),
+				mcp.Description(desc),
 			))
 		}
 	}
 
+	// Add header parameters
+	for _, param := range route.MethodConfig.HeaderParams {
+		desc := fmt.Sprintf("Header parameter: %s", param)
+		if def, ok := route.Defaults[param]; ok {
+			desc = fmt.Sprintf("%s (defaults to %s)", desc, def)
+		}
+		opts = append(opts, mcp.WithString(param,
+			mcp.Description(desc),
+		))
+	}
+
 	// Add form fields
 	if route.MethodConfig.FormFields != nil {
 		for _, field := range route.MethodConfig.FormFields {
@@ -81,13 +123,24 @@ func (p *SwaggerParser) generateTool(route *requester.RouteConfig) mcp.Tool {
 		))
 	}
 
+	// Cached routes let the caller demand live data
+	if route.CacheMaxAge > 0 {
+		opts = append(opts, mcp.WithBoolean(requester.FreshArgument,
+			mcp.Description(fmt.Sprintf("Set to true to bypass the response cache (results may otherwise be up to %s old)", route.CacheMaxAge)),
+		))
+	}
+
 	// Add body parameter if it's a POST/PUT/PATCH request
 	if route.Method == "POST" || route.Method == "PUT" || route.Method == "PATCH" {
 		p.addBodyParameter(route, &opts)
 	}
 
-	// Create and return the tool
-	return mcp.NewTool(toolName, opts...)
+	// Create the tool, hiding the arguments pinned to constants
+	tool := mcp.NewTool(toolName, opts...)
+	for name := range route.Constants {
+		removeArgument(&tool, name)
+	}
+	return tool
 }
 
 // addBodyParameter adds body parameters to the tool options
@@ -115,6 +168,17 @@ func (p *SwaggerParser) addBodyParameter(route *requester.RouteConfig, opts *[]m
 		return
 	}
 
+	// JSON Patch and Merge Patch bodies have their own shape
+	switch contentType := route.MethodConfig.BodyContentType; contentType {
+	case contentTypeJSONPatch:
+		*opts = append(*opts, jsonPatchBodyOption(operation.RequestBody.Value.Required))
+		return
+	case contentTypeMergePatch:
+		mediaType := operation.RequestBody.Value.Content.Get(contentType)
+		*opts = append(*opts, mergePatchBodyOption(mediaType.Schema, operation.RequestBody.Value.Required, p.doc))
+		return
+	}
+
 	// Find the request body
 	schema, required := getFirstBodySchema(operation)
 	if schema != nil {
@@ -123,6 +187,43 @@ func (p *SwaggerParser) addBodyParameter(route *requester.RouteConfig, opts *[]m
 	}
 }
 
+// dateFormats maps path, query and body arguments to their date or date-time format
+func dateFormats(operation *openapi3.Operation) map[string]string {
+	formats := make(map[string]string)
+	for _, param := range operation.Parameters {
+		if param.Value != nil && (param.Value.In == "path" || param.Value.In == "query") {
+			collectDateFormats(param.Value.Schema, param.Value.Name, formats, 0)
+		}
+	}
+	if schema, _ := getFirstBodySchema(operation); schema != nil {
+		collectDateFormats(schema, "body", formats, 0)
+	}
+
+	if len(formats) == 0 {
+		return nil
+	}
+	return formats
+}
+
+// getSuccessResponseSchema returns the JSON schema of the first documented 2xx response
+func getSuccessResponseSchema(operation *openapi3.Operation) *openapi3.SchemaRef {
+	if operation.Responses == nil {
+		return nil
+	}
+	for _, status := range []string{"200", "201", "202", "203", "206", "2XX", "default"} {
+		response := operation.Responses.Value(status)
+		if response == nil || response.Value == nil {
+			continue
+		}
+		for contentType, mediaType := range response.Value.Content {
+			if strings.Contains(contentType, "json") && mediaType.Schema != nil {
+				return mediaType.Schema
+			}
+		}
+	}
+	return nil
+}
+
 func getFirstBodySchema(operation *openapi3.Operation) (*openapi3.SchemaRef, bool) {
 	if operation.RequestBody != nil && operation.RequestBody.Value != nil {
 		content := operation.RequestBody.Value.Content
@@ -279,9 +380,13 @@ func (p *SwaggerParser) ParseReader(reader io.Reader) error {
 	return p.processOperations()
 }
 
-// processOperations iterates through paths and operations in the spec
+// processOperations iterates through paths and operations in the spec.
+// Paths are sorted and methods taken in a fixed order, so tools are listed
+// the same way on every run.
 func (p *SwaggerParser) processOperations() error {
-	for path, pathItem := range p.doc.Paths.Map() {
+	paths := p.doc.Paths.Map()
+	for _, path := range slices.Sorted(maps.Keys(paths)) {
+		pathItem := paths[path]
 		httpMethods := []struct {
 			Method    string
 			Operation *openapi3.Operation
@@ -298,23 +403,97 @@ func (p *SwaggerParser) processOperations() error {
 				routeConfig := p.createRouteConfig(path, httpMethod.Method, httpMethod.Operation)
 				if p.adjuster.ExistsInMCP(routeConfig.Path, routeConfig.Method) {
 					tool := p.generateTool(routeConfig)
+					if err := adjustArguments(&tool, routeConfig); err != nil {
+						return err
+					}
+					if err := p.describeTool(&tool, routeConfig, httpMethod.Operation); err != nil {
+						return err
+					}
 					p.routeTools = append(p.routeTools, &RouteTool{
-						RouteConfig: routeConfig,
-						Tool:        tool,
+						RouteConfig:  routeConfig,
+						Tool:         tool,
+						OutputSchema: getSuccessResponseSchema(httpMethod.Operation),
+						Deprecated:   httpMethod.Operation.Deprecated,
+						Auth:         p.securitySchemes(httpMethod.Operation),
 					})
 				}
 			}
 		}
 	}
 
+	if err := p.checkToolNames(); err != nil {
+		return err
+	}
+	if err := p.addCustomTools(); err != nil {
+		return err
+	}
+
+	for _, ref := range p.adjuster.Unmatched(p.operations()) {
+		logger.Warn("Adjustments entry matches no operation of the spec",
+			zap.String("section", ref.Section), zap.String("operation", ref.Method+" "+ref.Path))
+	}
 	return nil
 }
 
+// securitySchemes returns the names of the security schemes operation
+// requires, from its own security requirements or else the spec's. An empty
+// requirement makes credentials optional, so none are required.
+func (p *SwaggerParser) securitySchemes(operation *openapi3.Operation) []string {
+	requirements := p.doc.Security
+	if operation.Security != nil {
+		requirements = *operation.Security
+	}
+	var schemes []string
+	for _, requirement := range requirements {
+		if len(requirement) == 0 {
+			return nil
+		}
+		for name := range requirement {
+			if !slices.Contains(schemes, name) {
+				schemes = append(schemes, name)
+			}
+		}
+	}
+	slices.Sort(schemes)
+	return schemes
+}
+
+// operations returns every operation of the spec, selected or not, and of
+// the custom tools as "METHOD /path"
+func (p *SwaggerParser) operations() map[string]bool {
+	operations := make(map[string]bool)
+	for path, pathItem := range p.doc.Paths.Map() {
+		for method := range pathItem.Operations() {
+			operations[method+" "+path] = true
+		}
+	}
+	for _, custom := range p.adjuster.GetCustomTools() {
+		operations[custom.Method+" "+custom.Path] = true
+	}
+	return operations
+}
+
+// applyRouteAdjustments sets the per-route settings of the adjustments file
+func (p *SwaggerParser) applyRouteAdjustments(routeConfig *requester.RouteConfig) {
+	routeConfig.Defaults = p.adjuster.GetArgumentDefaults(routeConfig.Path, routeConfig.Method)
+	routeConfig.Constants = p.adjuster.GetArgumentConstants(routeConfig.Path, routeConfig.Method)
+	routeConfig.OnBehalfOf = p.adjuster.UsesOnBehalfOf(routeConfig.Path, routeConfig.Method)
+	routeConfig.SuccessCriteria = p.adjuster.GetSuccessCriteria(routeConfig.Path, routeConfig.Method)
+	routeConfig.UnwrapData, routeConfig.UnwrapMeta = p.adjuster.GetUnwrap(routeConfig.Path, routeConfig.Method)
+	routeConfig.CacheMaxAge = p.adjuster.GetCacheMaxAge(routeConfig.Path, routeConfig.Method)
+	routeConfig.HTMLResponses = p.adjuster.GetHTMLResponses(routeConfig.Path, routeConfig.Method)
+	routeConfig.Transform = p.adjuster.GetTransform(routeConfig.Path, routeConfig.Method)
+	routeConfig.Policy = p.adjuster.GetPolicy(routeConfig.Path, routeConfig.Method)
+	routeConfig.Renames, routeConfig.Hidden = p.adjuster.GetParameters(routeConfig.Path, routeConfig.Method)
+	routeConfig.ArgumentDescriptions = p.adjuster.GetParameterDescriptions(routeConfig.Path, routeConfig.Method)
+}
+
 // createRouteConfig creates a route configuration from a path and operation
 func (p *SwaggerParser) createRouteConfig(path, method string, operation *openapi3.Operation) *requester.RouteConfig {
 	routeConfig := &requester.RouteConfig{
 		Path:   path,
 		Method: method,
+		Tags:   operation.Tags,
 		Headers: map[string]string{
 			"Content-Type": "application/json",
 		},
@@ -328,6 +507,7 @@ func (p *SwaggerParser) createRouteConfig(path, method string, operation *openap
 		desc = operation.Summary
 	}
 	routeConfig.Description = p.adjuster.GetDescription(routeConfig.Path, routeConfig.Method, desc)
+	p.applyRouteAdjustments(routeConfig)
 
 	// Add operation-specific headers
 	if operation.Responses != nil {
@@ -348,12 +528,26 @@ func (p *SwaggerParser) createRouteConfig(path, method string, operation *openap
 		QueryParams: make([]string, 0),
 	}
 
-	// Add query parameters
+	// Add query and header parameters
 	for _, param := range operation.Parameters {
-		if param.Value != nil && param.Value.In == "query" {
+		if param.Value == nil {
+			continue
+		}
+		switch param.Value.In {
+		case "query":
 			routeConfig.MethodConfig.QueryParams = append(routeConfig.MethodConfig.QueryParams, param.Value.Name)
+		case "header":
+			// OpenAPI ignores header parameters named Accept, Content-Type and Authorization
+			switch strings.ToLower(param.Value.Name) {
+			case "accept", "content-type", "authorization":
+				continue
+			}
+			routeConfig.MethodConfig.HeaderParams = append(routeConfig.MethodConfig.HeaderParams, param.Value.Name)
 		}
 	}
 
+	routeConfig.MethodConfig.BodyContentType = patchContentType(operation)
+	routeConfig.DateFormats = dateFormats(operation)
+
 	return routeConfig
 }
@@ -2,8 +2,12 @@ package parser
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
+	"maps"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 
@@ -12,6 +16,7 @@ import (
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestExtractPathParams(t *testing.T) {
@@ -1050,3 +1055,533 @@ func TestSwaggerParserWithAdjustments(t *testing.T) {
 		assert.Equal(t, "Custom description for GET users", tool.RouteConfig.Description)
 	})
 }
+
+func TestSwaggerParser_DateFormats(t *testing.T) {
+	openapiSpec := []byte(`{
+		"openapi": "3.0.0",
+		"info": {"title": "Test API", "version": "1.0.0"},
+		"paths": {
+			"/events": {
+				"post": {
+					"parameters": [
+						{"name": "since", "in": "query", "schema": {"type": "string", "format": "date"}},
+						{"name": "limit", "in": "query", "schema": {"type": "integer"}}
+					],
+					"requestBody": {
+						"content": {
+							"application/json": {
+								"schema": {
+									"type": "object",
+									"properties": {
+										"startsAt": {"type": "string", "format": "date-time"},
+										"owner": {
+											"type": "object",
+											"properties": {"birthday": {"type": "string", "format": "date"}}
+										}
+									}
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+	}`)
+
+	parser := NewSwaggerParser(NewAdjuster())
+	require.NoError(t, parser.ParseReader(bytes.NewReader(openapiSpec)))
+
+	tools := parser.GetRouteTools()
+	require.Len(t, tools, 1)
+	assert.Equal(t, map[string]string{
+		"since":               "date",
+		"body.startsAt":       "date-time",
+		"body.owner.birthday": "date",
+	}, tools[0].RouteConfig.DateFormats)
+}
+
+func TestWriteToolSchemas(t *testing.T) {
+	openapiSpec := []byte(`{
+		"openapi": "3.0.0",
+		"info": {"title": "Test API", "version": "1.0.0"},
+		"paths": {
+			"/pets/{id}": {
+				"get": {
+					"summary": "Get pet",
+					"parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}],
+					"responses": {
+						"200": {
+							"description": "A pet",
+							"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}
+						}
+					}
+				}
+			}
+		},
+		"components": {
+			"schemas": {
+				"Pet": {
+					"type": "object",
+					"properties": {
+						"name": {"type": "string"},
+						"owner": {"$ref": "#/components/schemas/Owner"}
+					}
+				},
+				"Owner": {"type": "object", "properties": {"email": {"type": "string"}}}
+			}
+		}
+	}`)
+
+	parser := NewSwaggerParser(NewAdjuster())
+	require.NoError(t, parser.ParseReader(bytes.NewReader(openapiSpec)))
+
+	dir := t.TempDir()
+	require.NoError(t, WriteToolSchemas(dir, parser.GetRouteTools()))
+
+	data, err := os.ReadFile(dir + "/get_pets_id.json")
+	require.NoError(t, err)
+	var schema map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &schema))
+
+	assert.Equal(t, "get_pets_id", schema["name"])
+	assert.Equal(t, map[string]interface{}{"method": "GET", "path": "/pets/{id}"}, schema["source"])
+	assert.Contains(t, schema["inputSchema"].(map[string]interface{})["required"], "id")
+	owner := schema["outputSchema"].(map[string]interface{})["properties"].(map[string]interface{})["owner"].(map[string]interface{})
+	assert.Contains(t, owner["properties"], "email")
+}
+
+func TestWriteToolCatalog(t *testing.T) {
+	openapiSpec := []byte(`{
+		"openapi": "3.0.0",
+		"info": {"title": "Test API", "version": "1.0.0"},
+		"paths": {
+			"/pets/{id}": {
+				"get": {
+					"summary": "Get pet | by ID",
+					"parameters": [
+						{"name": "id", "in": "path", "required": true, "description": "Pet ID", "schema": {"type": "string"}},
+						{"name": "fields", "in": "query", "schema": {"type": "string"}}
+					],
+					"responses": {"200": {"description": "A pet"}}
+				}
+			},
+			"/pets": {
+				"post": {"summary": "Create pet", "responses": {"201": {"description": "Created"}}}
+			}
+		}
+	}`)
+
+	parser := NewSwaggerParser(NewAdjuster())
+	require.NoError(t, parser.ParseReader(bytes.NewReader(openapiSpec)))
+	tools := parser.GetRouteTools()
+
+	var table bytes.Buffer
+	require.NoError(t, WriteToolCatalog(&table, tools, CatalogFormatTable))
+	lines := strings.Split(strings.TrimSpace(table.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Regexp(t, `^get_pets_id\s+GET\s+/pets/\{id\}\s+id\*,fields\s+Get pet`, lines[1])
+
+	var markdown bytes.Buffer
+	require.NoError(t, WriteToolCatalog(&markdown, tools, CatalogFormatMarkdown))
+	assert.Contains(t, markdown.String(), "| `get_pets_id` | `GET /pets/{id}` | Get pet \\| by ID |")
+	assert.Contains(t, markdown.String(), "| `id` | string | yes | Path parameter: id |")
+
+	var encoded bytes.Buffer
+	require.NoError(t, WriteToolCatalog(&encoded, tools, CatalogFormatJSON))
+	var schemas []ToolSchema
+	require.NoError(t, json.Unmarshal(encoded.Bytes(), &schemas))
+	require.Len(t, schemas, 2)
+	assert.Equal(t, "get_pets_id", schemas[0].Name)
+	assert.Equal(t, ToolSource{Method: "POST", Path: "/pets"}, schemas[1].Source)
+
+	assert.Error(t, WriteToolCatalog(&encoded, tools, "yaml"))
+}
+
+func TestSwaggerParser_PatchContentTypes(t *testing.T) {
+	openapiSpec := []byte(`{
+		"openapi": "3.0.0",
+		"info": {"title": "Test API", "version": "1.0.0"},
+		"paths": {
+			"/pets/{id}": {
+				"patch": {
+					"parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}],
+					"requestBody": {
+						"required": true,
+						"content": {
+							"application/json-patch+json": {
+								"schema": {"type": "array", "items": {"type": "object"}}
+							}
+						}
+					}
+				},
+				"put": {
+					"parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}],
+					"requestBody": {
+						"content": {
+							"application/merge-patch+json": {
+								"schema": {
+									"type": "object",
+									"properties": {"name": {"type": "string"}, "tag": {"type": "string"}},
+									"required": ["name"]
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+	}`)
+
+	parser := NewSwaggerParser(NewAdjuster())
+	require.NoError(t, parser.ParseReader(bytes.NewReader(openapiSpec)))
+
+	tools := make(map[string]*RouteTool)
+	for _, tool := range parser.GetRouteTools() {
+		tools[tool.RouteConfig.Method] = tool
+	}
+
+	jsonPatch := tools["PATCH"]
+	require.NotNil(t, jsonPatch)
+	assert.Equal(t, "application/json-patch+json", jsonPatch.RouteConfig.MethodConfig.BodyContentType)
+	body := jsonPatch.Tool.InputSchema.Properties["body"].(map[string]interface{})
+	assert.Equal(t, "array", body["type"])
+	assert.Equal(t, []string{"op", "path"}, body["items"].(map[string]interface{})["required"])
+	assert.Contains(t, jsonPatch.Tool.InputSchema.Required, "body")
+
+	mergePatch := tools["PUT"]
+	require.NotNil(t, mergePatch)
+	assert.Equal(t, "application/merge-patch+json", mergePatch.RouteConfig.MethodConfig.BodyContentType)
+	body = mergePatch.Tool.InputSchema.Properties["body"].(map[string]interface{})
+	assert.Equal(t, "object", body["type"])
+	assert.NotContains(t, body, "required")
+	assert.Contains(t, body["description"], "Merge Patch")
+}
+
+func TestSwaggerParser_HeaderParams(t *testing.T) {
+	openapiSpec := []byte(`{
+		"openapi": "3.0.0",
+		"info": {"title": "Test API", "version": "1.0.0"},
+		"paths": {
+			"/reports": {
+				"get": {
+					"parameters": [
+						{"name": "X-Api-Version", "in": "header", "schema": {"type": "string"}},
+						{"name": "Authorization", "in": "header", "schema": {"type": "string"}}
+					]
+				}
+			}
+		}
+	}`)
+
+	parser := NewSwaggerParser(NewAdjuster())
+	require.NoError(t, parser.ParseReader(bytes.NewReader(openapiSpec)))
+
+	tools := parser.GetRouteTools()
+	require.Len(t, tools, 1)
+	assert.Equal(t, []string{"X-Api-Version"}, tools[0].RouteConfig.MethodConfig.HeaderParams)
+	assert.Contains(t, tools[0].Tool.InputSchema.Properties, "X-Api-Version")
+	assert.NotContains(t, tools[0].Tool.InputSchema.Properties, "Authorization")
+}
+
+func TestSwaggerParser_ToolAnnotations(t *testing.T) {
+	openapiSpec := []byte(`{
+		"openapi": "3.0.0",
+		"info": {"title": "Test API", "version": "1.0.0"},
+		"paths": {
+			"/items": {
+				"get": {},
+				"post": {}
+			},
+			"/items/{id}": {
+				"put": {},
+				"delete": {}
+			}
+		}
+	}`)
+
+	adjuster := NewAdjuster()
+	readOnly := true
+	adjuster.adjustments.Annotations = []models.RouteAnnotations{
+		{Path: "/items", Updates: []models.RouteAnnotationUpdate{{Method: "POST", Title: "Search items", ReadOnly: &readOnly}}},
+	}
+	parser := NewSwaggerParser(adjuster)
+	require.NoError(t, parser.ParseReader(bytes.NewReader(openapiSpec)))
+
+	annotations := make(map[string]mcp.ToolAnnotation)
+	for _, tool := range parser.GetRouteTools() {
+		annotations[tool.RouteConfig.Method] = tool.Tool.Annotations
+	}
+	require.Len(t, annotations, 4)
+
+	assert.True(t, *annotations["GET"].ReadOnlyHint)
+	assert.False(t, *annotations["GET"].DestructiveHint)
+	assert.True(t, *annotations["DELETE"].DestructiveHint)
+	assert.True(t, *annotations["PUT"].IdempotentHint)
+	assert.True(t, *annotations["PUT"].OpenWorldHint)
+
+	// Overridden from the adjustments file, other hints stay derived
+	assert.Equal(t, "Search items", annotations["POST"].Title)
+	assert.True(t, *annotations["POST"].ReadOnlyHint)
+	assert.False(t, *annotations["POST"].IdempotentHint)
+}
+
+func TestSwaggerParser_DescriptionTemplates(t *testing.T) {
+	openapiSpec := []byte(`{
+		"openapi": "3.0.0",
+		"info": {"title": "Test API", "version": "1.0.0"},
+		"paths": {
+			"/pets": {
+				"get": {
+					"summary": "List pets",
+					"tags": ["pets", "public"],
+					"parameters": [{"name": "limit", "in": "query", "schema": {"type": "integer"}}]
+				}
+			},
+			"/pets/{id}": {
+				"get": {"summary": "Get a pet", "description": "Returns one pet"},
+				"delete": {"summary": "Delete a pet", "deprecated": true}
+			}
+		}
+	}`)
+
+	adjuster := NewAdjuster()
+	adjuster.adjustments.DescriptionTemplate = `{{ .summary }} [{{ join ", " .tags }}]{{ range .params }} {{ .name }}{{ if .required }}*{{ end }}{{ end }}`
+	adjuster.adjustments.Descriptions = []models.RouteDescription{
+		{Path: "/pets/{id}", Updates: []models.RouteFieldUpdate{
+			{Method: "GET", NewDescription: "Fetch a pet by ID"},
+			{Method: "DELETE", Template: `{{ if .deprecated }}Deprecated: {{ end }}{{ .method }} {{ .path }}`},
+		}},
+	}
+	parser := NewSwaggerParser(adjuster)
+	require.NoError(t, parser.ParseReader(bytes.NewReader(openapiSpec)))
+
+	descriptions := make(map[string]string)
+	for _, tool := range parser.GetRouteTools() {
+		descriptions[tool.Tool.Name] = tool.Tool.Description
+	}
+	assert.Equal(t, map[string]string{
+		"get_pets":       "List pets [pets, public] limit",
+		"get_pets_id":    "Get a pet [] id*",
+		"delete_pets_id": "Deprecated: DELETE /pets/{id}",
+	}, descriptions)
+
+	// Without a template the description keeps the operation prefix
+	parser = NewSwaggerParser(NewAdjuster())
+	require.NoError(t, parser.ParseReader(bytes.NewReader(openapiSpec)))
+	for _, tool := range parser.GetRouteTools() {
+		if tool.Tool.Name == "get_pets_id" {
+			assert.Equal(t, "GET /pets/{id} \n Returns one pet", tool.Tool.Description)
+		}
+	}
+}
+
+func TestSwaggerParser_ArgumentConstants(t *testing.T) {
+	openapiSpec := []byte(`{
+		"openapi": "3.0.0",
+		"info": {"title": "Test API", "version": "1.0.0"},
+		"paths": {
+			"/tenants/{tenant_id}/reports": {
+				"post": {
+					"parameters": [
+						{"name": "tenant_id", "in": "path", "required": true, "schema": {"type": "string"}},
+						{"name": "api_version", "in": "header", "schema": {"type": "string"}}
+					],
+					"requestBody": {
+						"required": true,
+						"content": {"application/json": {"schema": {
+							"type": "object",
+							"required": ["name", "owner"],
+							"properties": {"name": {"type": "string"}, "owner": {"type": "string"}}
+						}}}
+					}
+				}
+			}
+		}
+	}`)
+
+	t.Setenv("TEST_TENANT", "acme")
+	dir := t.TempDir()
+	adjustments := filepath.Join(dir, "adjustments.yaml")
+	require.NoError(t, os.WriteFile(adjustments, []byte(`
+defaults:
+  - path: /tenants/{tenant_id}/reports
+    updates:
+      - method: POST
+        constants:
+          tenant_id: "${TEST_TENANT}"
+          api_version: "2024-01"
+          body.owner: "{{user.email}}"
+`), 0o600))
+	adjuster := NewAdjuster()
+	require.NoError(t, adjuster.Load(adjustments))
+	parser := NewSwaggerParser(adjuster)
+	require.NoError(t, parser.ParseReader(bytes.NewReader(openapiSpec)))
+
+	tools := parser.GetRouteTools()
+	require.Len(t, tools, 1)
+	tool := tools[0]
+	assert.Equal(t, map[string]string{"tenant_id": "acme", "api_version": "2024-01", "body.owner": "{{user.email}}"}, tool.RouteConfig.Constants)
+
+	// Constants are hidden from the schema, including body fields
+	assert.NotContains(t, tool.Tool.InputSchema.Properties, "tenant_id")
+	assert.NotContains(t, tool.Tool.InputSchema.Properties, "api_version")
+	assert.NotContains(t, tool.Tool.InputSchema.Required, "tenant_id")
+	body := tool.Tool.InputSchema.Properties["body"].(map[string]any)
+	assert.NotContains(t, body["properties"], "owner")
+	assert.Equal(t, []string{"name"}, body["required"])
+
+	// The spec itself is left alone
+	operation := parser.doc.Paths.Find("/tenants/{tenant_id}/reports").Post
+	assert.Equal(t, []string{"name", "owner"}, operation.RequestBody.Value.Content.Get("application/json").Schema.Value.Required)
+}
+
+func TestSwaggerParser_Parameters(t *testing.T) {
+	openapiSpec := []byte(`{
+		"openapi": "3.0.0",
+		"info": {"title": "Test API", "version": "1.0.0"},
+		"paths": {
+			"/pets/{id}": {
+				"put": {
+					"parameters": [
+						{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}},
+						{"name": "X-Debug", "in": "header", "schema": {"type": "string"}}
+					],
+					"requestBody": {
+						"required": true,
+						"content": {"application/json": {"schema": {
+							"type": "object",
+							"required": ["petName"],
+							"properties": {"petName": {"type": "string"}, "internalId": {"type": "integer"}}
+						}}}
+					}
+				}
+			}
+		}
+	}`)
+
+	adjuster := NewAdjuster()
+	adjuster.adjustments.Parameters = []models.RouteParameters{{Path: "/pets/{id}", Updates: []models.RouteParameterUpdate{{
+		Method:       "PUT",
+		Rename:       map[string]string{"id": "pet_id", "body.petName": "name", "missing": "other"},
+		Hide:         []string{"X-Debug", "body.internalId"},
+		Descriptions: map[string]string{"id": "The pet's ID", "body.petName": "The pet's name"},
+	}}}}
+	parser := NewSwaggerParser(adjuster)
+	require.NoError(t, parser.ParseReader(bytes.NewReader(openapiSpec)))
+
+	tool := parser.GetRouteTools()[0].Tool
+	assert.Contains(t, tool.InputSchema.Properties, "pet_id")
+	assert.NotContains(t, tool.InputSchema.Properties, "id")
+	assert.NotContains(t, tool.InputSchema.Properties, "X-Debug")
+	assert.Contains(t, tool.InputSchema.Required, "pet_id")
+	body := tool.InputSchema.Properties["body"].(map[string]any)
+	assert.Equal(t, []string{"name"}, slices.Sorted(maps.Keys(body["properties"].(map[string]any))))
+	assert.Equal(t, []string{"name"}, body["required"])
+	assert.Equal(t, "The pet's ID", tool.InputSchema.Properties["pet_id"].(map[string]any)["description"])
+	assert.Equal(t, "The pet's name", body["properties"].(map[string]any)["name"].(map[string]any)["description"])
+
+	// Renaming onto an existing argument is an error
+	adjuster.adjustments.Parameters[0].Updates[0].Rename = map[string]string{"id": "body"}
+	parser = NewSwaggerParser(adjuster)
+	assert.ErrorContains(t, parser.ParseReader(bytes.NewReader(openapiSpec)), "cannot rename id to body: argument already exists")
+}
+
+func TestValidateParameterUpdate(t *testing.T) {
+	assert.NoError(t, validateParameterUpdate(models.RouteParameterUpdate{Rename: map[string]string{"a": "b", "body.a": "b"}}))
+	assert.ErrorContains(t, validateParameterUpdate(models.RouteParameterUpdate{Rename: map[string]string{"a": "x.y"}}), "without dots")
+	assert.ErrorContains(t, validateParameterUpdate(models.RouteParameterUpdate{Rename: map[string]string{"a": "c", "b": "c"}}), `both renamed to "c"`)
+	assert.ErrorContains(t, validateParameterUpdate(models.RouteParameterUpdate{Rename: map[string]string{"a": "b"}, Hide: []string{"a"}}), "a is both renamed and hidden")
+	assert.ErrorContains(t, validateParameterUpdate(models.RouteParameterUpdate{Descriptions: map[string]string{"a": "A"}, Hide: []string{"a"}}), "a is both described and hidden")
+}
+
+func TestSwaggerParser_ToolNames(t *testing.T) {
+	openapiSpec := []byte(`{
+		"openapi": "3.0.0",
+		"info": {"title": "Test API", "version": "1.0.0"},
+		"paths": {
+			"/pet/findByStatus": {"get": {"responses": {"200": {"description": "OK"}}}},
+			"/pets": {"get": {"responses": {"200": {"description": "OK"}}}}
+		}
+	}`)
+
+	adjuster := NewAdjuster()
+	adjuster.adjustments.Descriptions = []models.RouteDescription{{Path: "/pet/findByStatus", Updates: []models.RouteFieldUpdate{{
+		Method:  "GET",
+		NewName: "find_pets_by_status",
+	}}}}
+	parser := NewSwaggerParser(adjuster)
+	require.NoError(t, parser.ParseReader(bytes.NewReader(openapiSpec)))
+
+	names := make([]string, 0, 2)
+	for _, tool := range parser.GetRouteTools() {
+		names = append(names, tool.Tool.Name)
+	}
+	assert.ElementsMatch(t, []string{"find_pets_by_status", "get_pets"}, names)
+
+	// A new name taken by another tool is an error
+	adjuster.adjustments.Descriptions[0].Updates[0].NewName = "get_pets"
+	parser = NewSwaggerParser(adjuster)
+	assert.EqualError(t, parser.ParseReader(bytes.NewReader(openapiSpec)), "descriptions: tool name get_pets is used by GET /pet/findByStatus and GET /pets")
+}
+
+func TestSwaggerParser_RouteOrder(t *testing.T) {
+	openapiSpec := []byte(`{
+		"openapi": "3.0.0",
+		"info": {"title": "Test API", "version": "1.0.0"},
+		"paths": {
+			"/users": {"post": {"responses": {"201": {"description": "Created"}}}, "get": {"responses": {"200": {"description": "OK"}}}},
+			"/pets/{id}": {"delete": {"responses": {"204": {"description": "Deleted"}}}, "put": {"responses": {"200": {"description": "OK"}}}},
+			"/health": {"get": {"responses": {"200": {"description": "OK"}}}},
+			"/pets": {"patch": {"responses": {"200": {"description": "OK"}}}, "get": {"responses": {"200": {"description": "OK"}}}}
+		}
+	}`)
+
+	// Paths are sorted and methods taken in a fixed order on every run
+	for range 5 {
+		parser := NewSwaggerParser(NewAdjuster())
+		require.NoError(t, parser.ParseReader(bytes.NewReader(openapiSpec)))
+		var operations []string
+		for _, tool := range parser.GetRouteTools() {
+			operations = append(operations, tool.RouteConfig.Method+" "+tool.RouteConfig.Path)
+		}
+		assert.Equal(t, []string{"GET /health", "GET /pets", "PATCH /pets", "PUT /pets/{id}", "DELETE /pets/{id}", "GET /users", "POST /users"}, operations)
+	}
+}
+
+func TestSwaggerParser_DeprecatedAndAuth(t *testing.T) {
+	openapiSpec := []byte(`{
+		"openapi": "3.0.0",
+		"info": {"title": "Test API", "version": "1.0.0"},
+		"security": [{"api_key": []}],
+		"components": {"securitySchemes": {
+			"api_key": {"type": "apiKey", "in": "header", "name": "X-API-Key"},
+			"oauth": {"type": "oauth2", "flows": {"clientCredentials": {"tokenUrl": "https://example.com/token", "scopes": {}}}}
+		}},
+		"paths": {
+			"/pets": {
+				"get": {"deprecated": true, "responses": {"200": {"description": "OK"}}},
+				"post": {"security": [{"oauth": ["write"]}, {"api_key": []}], "responses": {"201": {"description": "Created"}}}
+			},
+			"/health": {"get": {"security": [], "responses": {"200": {"description": "OK"}}}},
+			"/status": {"get": {"security": [{}, {"oauth": []}], "responses": {"200": {"description": "OK"}}}}
+		}
+	}`)
+
+	parser := NewSwaggerParser(NewAdjuster())
+	require.NoError(t, parser.ParseReader(bytes.NewReader(openapiSpec)))
+	deprecated := make(map[string]bool)
+	auth := make(map[string][]string)
+	for _, tool := range parser.GetRouteTools() {
+		operation := tool.RouteConfig.Method + " " + tool.RouteConfig.Path
+		deprecated[operation] = tool.Deprecated
+		auth[operation] = tool.Auth
+	}
+
+	assert.Equal(t, map[string]bool{"GET /pets": true, "POST /pets": false, "GET /health": false, "GET /status": false}, deprecated)
+	assert.Equal(t, map[string][]string{
+		"GET /pets":   {"api_key"},          // The spec's requirement
+		"POST /pets":  {"api_key", "oauth"}, // Either scheme
+		"GET /health": nil,                  // Overridden with none
+		"GET /status": nil,                  // Credentials optional
+	}, auth)
+}
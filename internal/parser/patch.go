@@ -0,0 +1,86 @@
+package parser
+
+import (
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	contentTypeJSON       = "application/json"
+	contentTypeJSONPatch  = "application/json-patch+json"
+	contentTypeMergePatch = "application/merge-patch+json"
+)
+
+// jsonPatchOperationSchema describes a single RFC 6902 operation
+var jsonPatchOperationSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"op": map[string]interface{}{
+			"type": "string",
+			"enum": []string{"add", "remove", "replace", "move", "copy", "test"},
+		},
+		"path": map[string]interface{}{
+			"type":        "string",
+			"description": "JSON Pointer to the target location, e.g. /name or /tags/0",
+		},
+		"from": map[string]interface{}{
+			"type":        "string",
+			"description": "JSON Pointer to the source location (move and copy only)",
+		},
+		"value": map[string]interface{}{
+			"description": "Value to add, replace or test",
+		},
+	},
+	"required": []string{"op", "path"},
+}
+
+// patchContentType returns the JSON Patch or Merge Patch media type of a
+// request body that does not also accept plain JSON, or "" otherwise
+func patchContentType(operation *openapi3.Operation) string {
+	if operation.RequestBody == nil || operation.RequestBody.Value == nil {
+		return ""
+	}
+	content := operation.RequestBody.Value.Content
+	if content.Get(contentTypeJSON) != nil {
+		return ""
+	}
+	for _, contentType := range []string{contentTypeJSONPatch, contentTypeMergePatch} {
+		if content.Get(contentType) != nil {
+			return contentType
+		}
+	}
+	return ""
+}
+
+// jsonPatchBodyOption exposes the body as an array of RFC 6902 operations
+func jsonPatchBodyOption(required bool) mcp.ToolOption {
+	opts := []mcp.PropertyOption{
+		mcp.Description("JSON Patch (RFC 6902) operations applied in order"),
+		mcp.Items(jsonPatchOperationSchema),
+	}
+	if required {
+		opts = append(opts, mcp.Required())
+	}
+	return mcp.WithArray("body", opts...)
+}
+
+// mergePatchBodyOption exposes the body as a partial object per RFC 7396:
+// every property is optional and null removes a field
+func mergePatchBodyOption(schema *openapi3.SchemaRef, required bool, doc *openapi3.T) mcp.ToolOption {
+	partial := &openapi3.SchemaRef{Value: &openapi3.Schema{Type: &openapi3.Types{openapi3.TypeObject}}}
+	if schema != nil && schema.Value != nil {
+		value := *schema.Value
+		value.Required = nil
+		partial.Value = &value
+	}
+	partial.Value.Description = joinDescription(partial.Value.Description,
+		"JSON Merge Patch (RFC 7396): include only the fields to change, set a field to null to remove it")
+	return schemaToMCPOptions(partial, "body", required, doc)
+}
+
+func joinDescription(description, note string) string {
+	if description == "" {
+		return note
+	}
+	return description + ". " + note
+}
@@ -180,3 +180,23 @@ func createNumberOption(schema *openapi3.SchemaRef, name string, baseOpts []mcp.
 	}
 	return mcp.WithNumber(name, numberOpts...)
 }
+
+// maxDateFormatDepth bounds the walk through (possibly recursive) body schemas
+const maxDateFormatDepth = 6
+
+// collectDateFormats records the date/date-time format of string properties in
+// schema under dotted argument paths, e.g. "body.dueDate"
+func collectDateFormats(schema *openapi3.SchemaRef, path string, formats map[string]string, depth int) {
+	if schema == nil || schema.Value == nil || depth > maxDateFormatDepth {
+		return
+	}
+
+	switch format := schema.Value.Format; format {
+	case "date", "date-time":
+		formats[path] = format
+		return
+	}
+	for name, property := range schema.Value.Properties {
+		collectDateFormats(property, path+"."+name, formats, depth+1)
+	}
+}
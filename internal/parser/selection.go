@@ -0,0 +1,116 @@
+package parser
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/brizzai/auto-mcp/internal/models"
+)
+
+// RouteFilter selects operations by tag, HTTP method and path, e.g. to
+// generate the routes section of an adjustments file without the TUI.
+// Empty include lists match everything; excludes win over includes.
+type RouteFilter struct {
+	IncludeTags    []string
+	ExcludeTags    []string
+	IncludeMethods []string
+	ExcludeMethods []string
+	// IncludePaths and ExcludePaths are path.Match patterns, a trailing
+	// "/**" also matches everything below
+	IncludePaths []string
+	ExcludePaths []string
+}
+
+// Match reports whether the filter selects the operation
+func (f RouteFilter) Match(tool *RouteTool) bool {
+	route := tool.RouteConfig
+	if len(f.IncludeTags) > 0 && !containsAny(f.IncludeTags, route.Tags) {
+		return false
+	}
+	if containsAny(f.ExcludeTags, route.Tags) {
+		return false
+	}
+	if len(f.IncludeMethods) > 0 && !containsAny(f.IncludeMethods, []string{route.Method}) {
+		return false
+	}
+	if containsAny(f.ExcludeMethods, []string{route.Method}) {
+		return false
+	}
+	if len(f.IncludePaths) > 0 && !matchesAnyPath(f.IncludePaths, route.Path) {
+		return false
+	}
+	return !matchesAnyPath(f.ExcludePaths, route.Path)
+}
+
+// SelectRoutes returns the tools the filter selects
+func SelectRoutes(tools []*RouteTool, filter RouteFilter) []*RouteTool {
+	var selected []*RouteTool
+	for _, tool := range tools {
+		if filter.Match(tool) {
+			selected = append(selected, tool)
+		}
+	}
+	return selected
+}
+
+// RouteSelections groups tools by path into the routes section of an
+// adjustments file, sorted so regenerated files diff cleanly
+func RouteSelections(tools []*RouteTool) []models.RouteSelection {
+	methodsByPath := make(map[string][]string)
+	for _, tool := range tools {
+		methodsByPath[tool.RouteConfig.Path] = append(methodsByPath[tool.RouteConfig.Path], tool.RouteConfig.Method)
+	}
+
+	selections := make([]models.RouteSelection, 0, len(methodsByPath))
+	for p, methods := range methodsByPath {
+		sort.Strings(methods)
+		selections = append(selections, models.RouteSelection{Path: p, Methods: methods})
+	}
+	sort.Slice(selections, func(i, j int) bool { return selections[i].Path < selections[j].Path })
+	return selections
+}
+
+func containsAny(want, have []string) bool {
+	for _, w := range want {
+		for _, h := range have {
+			if strings.EqualFold(w, h) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func matchesAnyPath(patterns []string, p string) bool {
+	for _, pattern := range patterns {
+		if prefix, ok := strings.CutSuffix(pattern, "/**"); ok {
+			if matchPathPrefix(prefix, p) {
+				return true
+			}
+			continue
+		}
+		if matched, _ := path.Match(pattern, p); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPathPrefix reports whether the leading segments of p match pattern
+func matchPathPrefix(pattern, p string) bool {
+	patternSegments := strings.Split(strings.Trim(pattern, "/"), "/")
+	pathSegments := strings.Split(strings.Trim(p, "/"), "/")
+	if pattern == "" || pattern == "/" {
+		return true
+	}
+	if len(pathSegments) < len(patternSegments) {
+		return false
+	}
+	for i, segment := range patternSegments {
+		if matched, _ := path.Match(segment, pathSegments[i]); !matched {
+			return false
+		}
+	}
+	return true
+}
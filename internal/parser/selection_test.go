@@ -0,0 +1,64 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/brizzai/auto-mcp/internal/models"
+	"github.com/brizzai/auto-mcp/internal/requester"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouteFilter(t *testing.T) {
+	route := func(method, path string, tags ...string) *RouteTool {
+		return &RouteTool{RouteConfig: &requester.RouteConfig{Method: method, Path: path, Tags: tags}}
+	}
+	tools := []*RouteTool{
+		route("GET", "/pet/{petId}", "pet"),
+		route("DELETE", "/pet/{petId}", "pet"),
+		route("POST", "/pet", "pet"),
+		route("GET", "/store/inventory", "store"),
+		route("GET", "/admin/users/{id}/keys", "admin"),
+	}
+
+	tests := []struct {
+		name   string
+		filter RouteFilter
+		want   []models.RouteSelection
+	}{
+		{
+			name:   "include tag and exclude method",
+			filter: RouteFilter{IncludeTags: []string{"Pet"}, ExcludeMethods: []string{"delete"}},
+			want: []models.RouteSelection{
+				{Path: "/pet", Methods: []string{"POST"}},
+				{Path: "/pet/{petId}", Methods: []string{"GET"}},
+			},
+		},
+		{
+			name:   "include methods and exclude path prefix",
+			filter: RouteFilter{IncludeMethods: []string{"GET"}, ExcludePaths: []string{"/admin/**"}},
+			want: []models.RouteSelection{
+				{Path: "/pet/{petId}", Methods: []string{"GET"}},
+				{Path: "/store/inventory", Methods: []string{"GET"}},
+			},
+		},
+		{
+			name:   "include path pattern",
+			filter: RouteFilter{IncludePaths: []string{"/pet/*"}},
+			want: []models.RouteSelection{
+				{Path: "/pet/{petId}", Methods: []string{"DELETE", "GET"}},
+			},
+		},
+		{
+			name:   "exclude tag",
+			filter: RouteFilter{ExcludeTags: []string{"pet", "admin"}},
+			want: []models.RouteSelection{
+				{Path: "/store/inventory", Methods: []string{"GET"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, RouteSelections(SelectRoutes(tools, tt.filter)))
+		})
+	}
+}
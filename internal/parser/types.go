@@ -3,6 +3,7 @@ package parser
 import (
 	"io"
 
+	"github.com/brizzai/auto-mcp/internal/models"
 	"github.com/brizzai/auto-mcp/internal/requester"
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -12,6 +13,13 @@ import (
 type RouteTool struct {
 	RouteConfig *requester.RouteConfig
 	Tool        mcp.Tool
+	// OutputSchema is the JSON schema of the success response, nil if the spec has none
+	OutputSchema *openapi3.SchemaRef
+	// Deprecated is set when the spec marks the operation deprecated
+	Deprecated bool
+	// Auth lists the security schemes the operation requires, sorted; empty
+	// when it can be called without credentials
+	Auth []string
 }
 
 // Parser handles parsing of Swagger/OpenAPI specifications
@@ -22,6 +30,10 @@ type Parser interface {
 	ParseReader(reader io.Reader) error
 	// GetRouteTools returns the parsed route tools
 	GetRouteTools() []*RouteTool
+	// GetPrompts returns the workflow prompts from the adjustments file
+	GetPrompts() []models.Prompt
+	// GetCompositeTools returns the composite tools from the adjustments file
+	GetCompositeTools() []models.CompositeTool
 }
 
 // SwaggerParser parses Swagger specifications and generates route configurations
@@ -0,0 +1,162 @@
+package parser
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/brizzai/auto-mcp/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+// operationMethods are the HTTP methods tools are generated for
+var operationMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE"}
+
+// decodeAdjustments decodes an adjustments file, rejecting fields the
+// adjustments schema doesn't define so typos such as "desciptions" fail
+// instead of being ignored
+func decodeAdjustments(data []byte) (*models.MCPAdjustments, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	var unknown []error
+	checkFields(&root, reflect.TypeOf(models.MCPAdjustments{}), "", &unknown)
+	if len(unknown) > 0 {
+		return nil, errors.Join(unknown...)
+	}
+
+	var adjustments models.MCPAdjustments
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&adjustments); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	return &adjustments, nil
+}
+
+// checkFields walks node alongside the Go type it decodes into and reports
+// every mapping key that is not a field of a struct, by its path in the file
+func checkFields(node *yaml.Node, t reflect.Type, path string, unknown *[]error) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			checkFields(child, t, path, unknown)
+		}
+	case yaml.SequenceNode:
+		if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+			return
+		}
+		for i, child := range node.Content {
+			checkFields(child, t.Elem(), fmt.Sprintf("%s[%d]", path, i), unknown)
+		}
+	case yaml.MappingNode:
+		switch t.Kind() {
+		case reflect.Map:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				checkFields(node.Content[i+1], t.Elem(), joinPath(path, node.Content[i].Value), unknown)
+			}
+		case reflect.Struct:
+			fields := yamlFields(t)
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				key := node.Content[i]
+				if key.Value == "<<" {
+					continue
+				}
+				field, ok := fields[key.Value]
+				if !ok {
+					*unknown = append(*unknown, unknownFieldError(key, path, fields))
+					continue
+				}
+				checkFields(node.Content[i+1], field, joinPath(path, key.Value), unknown)
+			}
+		}
+	}
+}
+
+// yamlFields maps the YAML names of the fields of a struct to their types
+func yamlFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		fields[name] = field.Type
+	}
+	return fields
+}
+
+func unknownFieldError(key *yaml.Node, path string, fields map[string]reflect.Type) error {
+	location := "top level"
+	if path != "" {
+		location = path
+	}
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	if suggestion := closestName(key.Value, names); suggestion != "" {
+		return fmt.Errorf("line %d: unknown field %q in %s, did you mean %q?", key.Line, key.Value, location, suggestion)
+	}
+	return fmt.Errorf("line %d: unknown field %q in %s, expected one of %s", key.Line, key.Value, location, strings.Join(names, ", "))
+}
+
+// closestName returns the name within a typo's distance of s, or ""
+func closestName(s string, names []string) string {
+	best, bestDistance := "", max(2, len(s)/3)+1
+	for _, name := range names {
+		if d := editDistance(strings.ToLower(s), name); d < bestDistance {
+			best, bestDistance = name, d
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,27 @@
+//go:build (linux || darwin || freebsd) && cgo
+
+package plugins
+
+import (
+	"fmt"
+	"plugin"
+
+	"github.com/brizzai/auto-mcp/pkg/hooks"
+)
+
+// open loads a Go plugin and returns its New function
+func open(path string) (hooks.NewFunc, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	symbol, err := p.Lookup(hooks.NewSymbol)
+	if err != nil {
+		return nil, err
+	}
+	newFunc, ok := symbol.(hooks.NewFunc)
+	if !ok {
+		return nil, fmt.Errorf("%s is %T, expected func(map[string]string) (any, error)", hooks.NewSymbol, symbol)
+	}
+	return newFunc, nil
+}
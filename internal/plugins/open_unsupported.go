@@ -0,0 +1,10 @@
+//go:build !((linux || darwin || freebsd) && cgo)
+
+package plugins
+
+import "github.com/brizzai/auto-mcp/pkg/hooks"
+
+// open fails, as the plugin package needs cgo
+func open(path string) (hooks.NewFunc, error) {
+	return nil, errUnsupported
+}
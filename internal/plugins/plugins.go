@@ -0,0 +1,128 @@
+// Package plugins loads the Go plugins configured in config.yaml and runs
+// their hooks in configuration order.
+package plugins
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/brizzai/auto-mcp/internal/config"
+	"github.com/brizzai/auto-mcp/internal/logger"
+	"github.com/brizzai/auto-mcp/pkg/hooks"
+	"go.uber.org/zap"
+)
+
+// errUnsupported is returned by open in builds without plugin support
+var errUnsupported = errors.New("plugins are not supported by this build, rebuild auto-mcp with CGO_ENABLED=1 on Linux, macOS or FreeBSD")
+
+// Plugin is a loaded plugin instance
+type Plugin struct {
+	Name string
+	// Impl implements any of the pkg/hooks interfaces
+	Impl any
+}
+
+// Hooks runs the hooks of the loaded plugins. A nil *Hooks runs none.
+type Hooks struct {
+	preRequest   []namedHook[hooks.PreRequestHook]
+	postResponse []namedHook[hooks.PostResponseHook]
+	toolFilters  []namedHook[hooks.ToolFilterHook]
+}
+
+type namedHook[T any] struct {
+	name string
+	hook T
+}
+
+// Load opens the configured plugins and creates an instance of each.
+// It returns nil when no plugins are configured.
+func Load(cfgs []config.PluginConfig) (*Hooks, error) {
+	if len(cfgs) == 0 {
+		return nil, nil
+	}
+
+	loaded := make([]Plugin, 0, len(cfgs))
+	for _, cfg := range cfgs {
+		newFunc, err := open(cfg.Path)
+		if err != nil {
+			return nil, fmt.Errorf("plugin %s: %w", cfg.Name, err)
+		}
+		impl, err := newFunc(cfg.Config)
+		if err != nil {
+			return nil, fmt.Errorf("plugin %s: %w", cfg.Name, err)
+		}
+		loaded = append(loaded, Plugin{Name: cfg.Name, Impl: impl})
+	}
+	return New(loaded...)
+}
+
+// New collects the hooks implemented by already created plugin instances
+func New(loaded ...Plugin) (*Hooks, error) {
+	h := &Hooks{}
+	for _, p := range loaded {
+		implemented := false
+		if hook, ok := p.Impl.(hooks.PreRequestHook); ok {
+			h.preRequest = append(h.preRequest, namedHook[hooks.PreRequestHook]{p.Name, hook})
+			implemented = true
+		}
+		if hook, ok := p.Impl.(hooks.PostResponseHook); ok {
+			h.postResponse = append(h.postResponse, namedHook[hooks.PostResponseHook]{p.Name, hook})
+			implemented = true
+		}
+		if hook, ok := p.Impl.(hooks.ToolFilterHook); ok {
+			h.toolFilters = append(h.toolFilters, namedHook[hooks.ToolFilterHook]{p.Name, hook})
+			implemented = true
+		}
+		if !implemented {
+			return nil, fmt.Errorf("plugin %s: %T implements no hook", p.Name, p.Impl)
+		}
+		logger.Info("Loaded plugin", zap.String("plugin", p.Name))
+	}
+	return h, nil
+}
+
+// PreRequest runs the pre-request hooks in order, stopping at the first error
+func (h *Hooks) PreRequest(ctx context.Context, req *hooks.Request) error {
+	if h == nil {
+		return nil
+	}
+	for _, p := range h.preRequest {
+		if err := p.hook.PreRequest(ctx, req); err != nil {
+			return fmt.Errorf("plugin %s: %w", p.name, err)
+		}
+	}
+	return nil
+}
+
+// PostResponse runs the post-response hooks in order, stopping at the first error
+func (h *Hooks) PostResponse(ctx context.Context, resp *hooks.Response) error {
+	if h == nil {
+		return nil
+	}
+	for _, p := range h.postResponse {
+		if err := p.hook.PostResponse(ctx, resp); err != nil {
+			return fmt.Errorf("plugin %s: %w", p.name, err)
+		}
+	}
+	return nil
+}
+
+// IncludeTool reports whether every tool filter keeps the tool
+func (h *Hooks) IncludeTool(tool hooks.Tool) bool {
+	if h == nil {
+		return true
+	}
+	for _, p := range h.toolFilters {
+		if !p.hook.IncludeTool(tool) {
+			logger.Debug("Tool excluded by plugin", zap.String("plugin", p.name), zap.String("tool", tool.Name))
+			return false
+		}
+	}
+	return true
+}
+
+// HasRequestHooks reports whether any pre-request or post-response hook is loaded
+func (h *Hooks) HasRequestHooks() bool {
+	return h != nil && (len(h.preRequest) > 0 || len(h.postResponse) > 0)
+}
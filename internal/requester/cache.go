@@ -0,0 +1,93 @@
+package requester
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// FreshArgument is the tool argument that bypasses the response cache
+const FreshArgument = "_fresh"
+
+// maxCacheEntries bounds the memory used by cached responses
+const maxCacheEntries = 1000
+
+type cacheEntry struct {
+	resp      *Response
+	expiresAt time.Time
+}
+
+// responseCache keeps successful GET responses in memory. Entries are keyed by
+// URL and the credentials the request was sent with, so users never see each
+// other's data.
+type responseCache struct {
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	now     func() time.Time
+}
+
+func newResponseCache() *responseCache {
+	return &responseCache{
+		entries: make(map[string]cacheEntry),
+		now:     time.Now,
+	}
+}
+
+// get returns a copy of the cached response if it has not expired
+func (c *responseCache) get(key string) (*Response, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if c.now().After(entry.expiresAt) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	resp := *entry.resp
+	return &resp, true
+}
+
+func (c *responseCache) put(key string, resp *Response, maxAge time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.entries) >= maxCacheEntries {
+		c.evict()
+	}
+	stored := *resp
+	c.entries[key] = cacheEntry{resp: &stored, expiresAt: c.now().Add(maxAge)}
+}
+
+// evict drops expired entries, or the one closest to expiry if none has expired
+func (c *responseCache) evict() {
+	now := c.now()
+	var oldestKey string
+	var oldest time.Time
+	for key, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, key)
+			continue
+		}
+		if oldestKey == "" || entry.expiresAt.Before(oldest) {
+			oldestKey, oldest = key, entry.expiresAt
+		}
+	}
+	if len(c.entries) >= maxCacheEntries {
+		delete(c.entries, oldestKey)
+	}
+}
+
+// cacheKey identifies a request by method, URL and the headers that select whose data is returned
+func cacheKey(req *http.Request, identityHeaders ...string) string {
+	hash := sha256.New()
+	hash.Write([]byte(req.Method + " " + req.URL.String()))
+	for _, name := range append([]string{"Authorization", "Cookie"}, identityHeaders...) {
+		hash.Write([]byte("\n" + name + ": " + req.Header.Get(name)))
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
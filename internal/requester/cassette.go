@@ -0,0 +1,204 @@
+package requester
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/brizzai/auto-mcp/internal/config"
+	"github.com/brizzai/auto-mcp/internal/logger"
+	"go.uber.org/zap"
+)
+
+// unsafeFileChars are replaced in the readable part of cassette file names
+var unsafeFileChars = regexp.MustCompile(`[^a-zA-Z0-9]+`)
+
+// maxFileNamePath bounds the readable part of cassette file names
+const maxFileNamePath = 60
+
+// interaction is one recorded request/response pair, stored as JSON
+type interaction struct {
+	Request  recordedRequest  `json:"request"`
+	Response recordedResponse `json:"response"`
+}
+
+// recordedRequest identifies a request. Its headers are not recorded, so
+// credentials never end up in a cassette.
+type recordedRequest struct {
+	Method     string `json:"method"`
+	URL        string `json:"url"`
+	BodySHA256 string `json:"body_sha256,omitempty"`
+}
+
+// recordedResponse holds a response as the upstream sent it. Body is set
+// for UTF-8 bodies, BodyBase64 for the others, e.g. compressed ones.
+type recordedResponse struct {
+	Status     int         `json:"status"`
+	Headers    http.Header `json:"headers,omitempty"`
+	Body       string      `json:"body,omitempty"`
+	BodyBase64 string      `json:"body_base64,omitempty"`
+}
+
+// cassette is an http.RoundTripper recording every upstream exchange into
+// dir, or answering requests from the recordings without network access.
+// Requests match by method, URL with sorted query parameters and body hash.
+type cassette struct {
+	mode string
+	dir  string
+	next http.RoundTripper // nil uses http.DefaultTransport
+}
+
+// newCassette returns the cassette of cfg, nil when cassettes are disabled
+func newCassette(cfg config.CassetteConfig) *cassette {
+	if cfg.Mode == "" {
+		return nil
+	}
+	return &cassette{mode: cfg.Mode, dir: cfg.Dir}
+}
+
+// RoundTrip records or replays req
+func (c *cassette) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	recorded := recordedRequest{Method: req.Method, URL: canonicalURL(req)}
+	if len(body) > 0 {
+		sum := sha256.Sum256(body)
+		recorded.BodySHA256 = hex.EncodeToString(sum[:])
+	}
+	file := filepath.Join(c.dir, cassetteFileName(recorded))
+
+	if c.mode == config.CassetteModeReplay {
+		return replay(req, recorded, file)
+	}
+
+	next := c.next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	if err := record(file, recorded, resp, respBody); err != nil {
+		logger.Warn("Failed to record upstream response", zap.String("file", file), zap.Error(err))
+	}
+	return resp, nil
+}
+
+// readRequestBody returns the body of req and replaces it with a fresh
+// reader for the next transport
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// canonicalURL returns the URL of req with its query parameters sorted
+func canonicalURL(req *http.Request) string {
+	u := *req.URL
+	u.RawQuery = u.Query().Encode()
+	return u.String()
+}
+
+// cassetteFileName names the file of a request: its method and a readable
+// form of its path, then a hash of the whole request
+func cassetteFileName(req recordedRequest) string {
+	sum := sha256.Sum256([]byte(req.Method + " " + req.URL + "\n" + req.BodySHA256))
+	path := req.URL
+	if i := strings.Index(path, "://"); i >= 0 {
+		path = path[i+3:]
+	}
+	if i := strings.Index(path, "/"); i >= 0 {
+		path = path[i:]
+	}
+	path = strings.Trim(unsafeFileChars.ReplaceAllString(path, "-"), "-")
+	if len(path) > maxFileNamePath {
+		path = path[:maxFileNamePath]
+	}
+	return fmt.Sprintf("%s-%s-%s.json", req.Method, path, hex.EncodeToString(sum[:6]))
+}
+
+// record writes the exchange to file. Set-Cookie headers are left out.
+func record(file string, req recordedRequest, resp *http.Response, body []byte) error {
+	headers := resp.Header.Clone()
+	headers.Del("Set-Cookie")
+	recorded := interaction{
+		Request:  req,
+		Response: recordedResponse{Status: resp.StatusCode, Headers: headers},
+	}
+	if utf8.Valid(body) {
+		recorded.Response.Body = string(body)
+	} else {
+		recorded.Response.BodyBase64 = base64.StdEncoding.EncodeToString(body)
+	}
+	data, err := json.MarshalIndent(recorded, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(file, data, 0o600)
+}
+
+// replay answers req with the response recorded in file
+func replay(req *http.Request, recorded recordedRequest, file string) (*http.Response, error) {
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("no recorded response for %s %s in the cassette, record it with --record", recorded.Method, recorded.URL)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var stored interaction
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("invalid cassette file %s: %w", file, err)
+	}
+	body := []byte(stored.Response.Body)
+	if stored.Response.BodyBase64 != "" {
+		if body, err = base64.StdEncoding.DecodeString(stored.Response.BodyBase64); err != nil {
+			return nil, fmt.Errorf("invalid cassette file %s: %w", file, err)
+		}
+	}
+	headers := stored.Response.Headers
+	if headers == nil {
+		headers = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", stored.Response.Status, http.StatusText(stored.Response.Status)),
+		StatusCode:    stored.Response.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        headers,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
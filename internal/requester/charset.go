@@ -0,0 +1,84 @@
+package requester
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/brizzai/auto-mcp/internal/logger"
+	"go.uber.org/zap"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// Byte order marks of the Unicode encodings
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// decodeCharset transcodes a text response body to UTF-8. The charset is
+// taken from a byte order mark, which wins as in the WHATWG encoding spec,
+// or else from the Content-Type charset parameter. Bodies without either
+// are taken to be UTF-8, and byte order marks are stripped. The charset of
+// the Content-Type is set to utf-8 once the body has been transcoded, and
+// bodies in unsupported charsets are returned unchanged.
+func decodeCharset(header http.Header, body []byte) ([]byte, error) {
+	contentType := header.Get("Content-Type")
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || len(body) == 0 {
+		return body, nil
+	}
+	label, declared := params["charset"]
+	if !declared && !isText(mediaType) {
+		return body, nil
+	}
+
+	var enc encoding.Encoding
+	switch {
+	case bytes.HasPrefix(body, bomUTF8):
+		body, enc = body[len(bomUTF8):], unicode.UTF8
+	case bytes.HasPrefix(body, bomUTF16LE):
+		body, enc = body[len(bomUTF16LE):], unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM)
+	case bytes.HasPrefix(body, bomUTF16BE):
+		body, enc = body[len(bomUTF16BE):], unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM)
+	case declared:
+		if enc, err = htmlindex.Get(label); err != nil {
+			logger.Warn("Returning response with unsupported charset unchanged", zap.String("charset", label))
+			return body, nil
+		}
+	default:
+		return body, nil
+	}
+
+	if enc != unicode.UTF8 {
+		if body, err = enc.NewDecoder().Bytes(body); err != nil {
+			return nil, fmt.Errorf("failed to decode the response charset: %w", err)
+		}
+	}
+	if declared && !strings.EqualFold(label, "utf-8") {
+		params["charset"] = "utf-8"
+		header.Set("Content-Type", mime.FormatMediaType(mediaType, params))
+	}
+	header.Del("Content-Length")
+	return body, nil
+}
+
+// isText reports whether mediaType is textual: text/*, JSON, XML, NDJSON,
+// form or JavaScript bodies
+func isText(mediaType string) bool {
+	if strings.HasPrefix(mediaType, "text/") ||
+		strings.HasSuffix(mediaType, "+json") || strings.HasSuffix(mediaType, "+xml") {
+		return true
+	}
+	switch mediaType {
+	case "application/json", "application/xml", "application/x-ndjson", "application/ndjson",
+		"application/jsonl", "application/javascript", "application/x-www-form-urlencoded":
+		return true
+	}
+	return false
+}
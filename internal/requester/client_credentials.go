@@ -0,0 +1,61 @@
+package requester
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/brizzai/auto-mcp/internal/logger"
+	"go.uber.org/zap"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+// clientCredentials fetches and caches the upstream access token of the
+// oauth2 client-credentials grant
+type clientCredentials struct {
+	cfg clientcredentials.Config
+
+	mu    sync.Mutex
+	token *oauth2.Token
+}
+
+// newClientCredentials returns the client-credentials grant configured in
+// authConfig, or nil when no token_url is set and the static token is used
+func newClientCredentials(authConfig map[string]string) *clientCredentials {
+	if authConfig["token_url"] == "" {
+		return nil
+	}
+	return &clientCredentials{cfg: clientcredentials.Config{
+		ClientID:     authConfig["client_id"],
+		ClientSecret: authConfig["client_secret"],
+		TokenURL:     authConfig["token_url"],
+		Scopes:       strings.Fields(authConfig["scopes"]),
+	}}
+}
+
+// accessToken returns the cached token, fetching a new one when there is
+// none or it has expired
+func (c *clientCredentials) accessToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token.Valid() {
+		return c.token.AccessToken, nil
+	}
+	logger.Info("Fetching upstream access token", zap.String("token_url", c.cfg.TokenURL))
+	token, err := c.cfg.Token(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch the client-credentials token: %w", err)
+	}
+	c.token = token
+	return token.AccessToken, nil
+}
+
+// invalidate drops the cached token so the next request fetches a new one
+func (c *clientCredentials) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.token = nil
+}
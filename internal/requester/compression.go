@@ -0,0 +1,79 @@
+package requester
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// decodeContentEncoding decompresses a response body. Go only decompresses
+// transparently when it added Accept-Encoding itself, so a custom
+// Accept-Encoding header in the config leaves gzip/deflate bodies untouched.
+func decodeContentEncoding(header http.Header, body []byte) ([]byte, error) {
+	encoding := strings.ToLower(strings.TrimSpace(header.Get("Content-Encoding")))
+	if encoding == "" || encoding == "identity" || len(body) == 0 {
+		return body, nil
+	}
+
+	var reader io.Reader
+	switch encoding {
+	case "gzip", "x-gzip":
+		gz, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip response: %w", err)
+		}
+		defer gz.Close()
+		reader = gz
+	case "deflate":
+		// "deflate" is zlib wrapped per RFC 9110, but some servers send raw deflate
+		zr, err := zlib.NewReader(bytes.NewReader(body))
+		if err != nil {
+			reader = flate.NewReader(bytes.NewReader(body))
+		} else {
+			defer zr.Close()
+			reader = zr
+		}
+	default:
+		// Unsupported encodings (e.g. br) are returned as-is
+		return body, nil
+	}
+
+	decoded, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress %s response: %w", encoding, err)
+	}
+	header.Del("Content-Encoding")
+	header.Del("Content-Length")
+	return decoded, nil
+}
+
+// gzipBody compresses body when it is at least threshold bytes long and
+// returns the new reader together with whether compression was applied
+func gzipBody(body io.Reader, threshold int) (io.Reader, bool, error) {
+	if body == nil || threshold <= 0 {
+		return body, false, nil
+	}
+
+	payload, err := io.ReadAll(body)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(payload) < threshold {
+		return bytes.NewReader(payload), false, nil
+	}
+
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	if _, err := gz.Write(payload); err != nil {
+		return nil, false, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, false, err
+	}
+	return &compressed, true, nil
+}
@@ -0,0 +1,98 @@
+package requester
+
+import "context"
+
+type contextKey string
+
+const (
+	upstreamTokenKey  contextKey = "upstream_token"
+	callerIdentityKey contextKey = "caller_identity"
+	freshResultKey    contextKey = "fresh_result"
+	tenantKey         contextKey = "tenant"
+	toolNameKey       contextKey = "tool_name"
+	extraHeadersKey   contextKey = "extra_headers"
+	baseURLKey        contextKey = "base_url"
+)
+
+// WithUpstreamToken returns a copy of ctx carrying the caller's bearer token so
+// it can be forwarded to the upstream API
+func WithUpstreamToken(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, upstreamTokenKey, token)
+}
+
+// UpstreamTokenFromContext returns the caller's bearer token stored in ctx, if any
+func UpstreamTokenFromContext(ctx context.Context) (string, bool) {
+	token, ok := ctx.Value(upstreamTokenKey).(string)
+	return token, ok && token != ""
+}
+
+// WithCallerIdentity returns a copy of ctx carrying the identity sent in the
+// on-behalf-of header
+func WithCallerIdentity(ctx context.Context, identity string) context.Context {
+	return context.WithValue(ctx, callerIdentityKey, identity)
+}
+
+// CallerIdentityFromContext returns the on-behalf-of identity stored in ctx, if any
+func CallerIdentityFromContext(ctx context.Context) (string, bool) {
+	identity, ok := ctx.Value(callerIdentityKey).(string)
+	return identity, ok && identity != ""
+}
+
+// WithFreshResult returns a copy of ctx that bypasses the response cache
+func WithFreshResult(ctx context.Context) context.Context {
+	return context.WithValue(ctx, freshResultKey, true)
+}
+
+// wantsFreshResult reports whether ctx asks for live data
+func wantsFreshResult(ctx context.Context) bool {
+	fresh, _ := ctx.Value(freshResultKey).(bool)
+	return fresh
+}
+
+// WithTenant returns a copy of ctx whose requests go to the named tenant's endpoint
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey, tenant)
+}
+
+// TenantFromContext returns the tenant stored in ctx, if any
+func TenantFromContext(ctx context.Context) (string, bool) {
+	tenant, ok := ctx.Value(tenantKey).(string)
+	return tenant, ok && tenant != ""
+}
+
+// WithToolName returns a copy of ctx naming the tool whose request is built,
+// e.g. for plugin hooks
+func WithToolName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, toolNameKey, name)
+}
+
+// ToolNameFromContext returns the tool name stored in ctx, if any
+func ToolNameFromContext(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(toolNameKey).(string)
+	return name, ok && name != ""
+}
+
+// WithHeaders returns a copy of ctx whose request carries the headers, e.g.
+// rendered by a route transform
+func WithHeaders(ctx context.Context, headers map[string]string) context.Context {
+	return context.WithValue(ctx, extraHeadersKey, headers)
+}
+
+// headersFromContext returns the extra headers stored in ctx, if any
+func headersFromContext(ctx context.Context) map[string]string {
+	headers, _ := ctx.Value(extraHeadersKey).(map[string]string)
+	return headers
+}
+
+// WithBaseURL returns a copy of ctx whose requests go to baseURL instead of
+// the configured one. The requester rejects base URLs that
+// endpoint.base_url_override does not allow.
+func WithBaseURL(ctx context.Context, baseURL string) context.Context {
+	return context.WithValue(ctx, baseURLKey, baseURL)
+}
+
+// BaseURLFromContext returns the base URL override stored in ctx, if any
+func BaseURLFromContext(ctx context.Context) (string, bool) {
+	baseURL, ok := ctx.Value(baseURLKey).(string)
+	return baseURL, ok && baseURL != ""
+}
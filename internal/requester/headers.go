@@ -0,0 +1,48 @@
+package requester
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// maxHeaderValueLength rejects values large enough to hit upstream header limits
+const maxHeaderValueLength = 8 * 1024
+
+// validateHeader rejects header names and values that could split the request
+// (CR/LF), smuggle control characters or exceed upstream size limits. Values
+// may come from tool arguments or user claims, so they are never trusted.
+func validateHeader(name, value string) error {
+	if name == "" {
+		return fmt.Errorf("empty header name")
+	}
+	for i := 0; i < len(name); i++ {
+		if !isTokenChar(name[i]) {
+			return fmt.Errorf("invalid character %q in header name %q", name[i], name)
+		}
+	}
+	if len(value) > maxHeaderValueLength {
+		return fmt.Errorf("header %s is %d bytes, the limit is %d", name, len(value), maxHeaderValueLength)
+	}
+	for i := 0; i < len(value); i++ {
+		if c := value[i]; c == 0x7f || (c < 0x20 && c != '\t') {
+			return fmt.Errorf("invalid control character in header %s", name)
+		}
+	}
+	return nil
+}
+
+// setHeader validates and sets a request header
+func setHeader(h http.Header, name, value string) error {
+	if err := validateHeader(name, value); err != nil {
+		return err
+	}
+	h.Set(name, value)
+	return nil
+}
+
+// isTokenChar reports whether c may appear in a header name (RFC 9110 token)
+func isTokenChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		strings.IndexByte("!#$%&'*+-.^_`|~", c) != -1
+}
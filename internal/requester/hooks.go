@@ -0,0 +1,77 @@
+package requester
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/brizzai/auto-mcp/internal/plugins"
+	"github.com/brizzai/auto-mcp/pkg/hooks"
+)
+
+// SetHooks runs the plugins' request and response hooks on every upstream call
+func (r *HTTPRequester) SetHooks(h *plugins.Hooks) {
+	r.hooks = h
+}
+
+// applyPreRequest lets plugins rewrite a built request and rebuilds it
+// from their changes
+func (r *HTTPRequester) applyPreRequest(ctx context.Context, req *Request) error {
+	httpReq := req.HttpRequest
+	var body []byte
+	if httpReq.Body != nil {
+		var err error
+		body, err = io.ReadAll(httpReq.Body)
+		_ = httpReq.Body.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read request body: %w", err)
+		}
+	}
+
+	tool, _ := ToolNameFromContext(ctx)
+	hookReq := &hooks.Request{
+		Tool:   tool,
+		Method: httpReq.Method,
+		URL:    httpReq.URL.String(),
+		Header: httpReq.Header.Clone(),
+		Body:   body,
+	}
+	if err := r.hooks.PreRequest(ctx, hookReq); err != nil {
+		return err
+	}
+
+	rebuilt, err := http.NewRequestWithContext(httpReq.Context(), hookReq.Method, hookReq.URL, bytes.NewReader(hookReq.Body))
+	if err != nil {
+		return fmt.Errorf("invalid request from plugin: %w", err)
+	}
+	if hookReq.Header != nil {
+		rebuilt.Header = hookReq.Header
+	}
+	req.HttpRequest = rebuilt
+	req.Method = hookReq.Method
+	req.URL = hookReq.URL
+	return nil
+}
+
+// applyPostResponse lets plugins rewrite a response before it is cached
+// and turned into a tool result
+func (r *HTTPRequester) applyPostResponse(ctx context.Context, req *Request, resp *Response) error {
+	tool, _ := ToolNameFromContext(ctx)
+	hookResp := &hooks.Response{
+		Tool:       tool,
+		Method:     req.Method,
+		URL:        req.URL,
+		StatusCode: resp.StatusCode,
+		Header:     resp.Headers,
+		Body:       resp.Body,
+	}
+	if err := r.hooks.PostResponse(ctx, hookResp); err != nil {
+		return err
+	}
+	resp.StatusCode = hookResp.StatusCode
+	resp.Headers = hookResp.Header
+	resp.Body = hookResp.Body
+	return nil
+}
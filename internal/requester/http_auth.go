@@ -3,6 +3,7 @@ package requester
 import (
 	"fmt"
 	"net/http"
+	"sync"
 
 	"github.com/brizzai/auto-mcp/internal/config"
 )
@@ -14,40 +15,105 @@ type AuthManager interface {
 
 // HTTPAuthManager implements the AuthManager interface
 type HTTPAuthManager struct {
-	authType   config.AuthType
+	authType     config.AuthType
+	forwardToken bool
+
+	// authConfig can be replaced when credentials rotate
+	mu         sync.RWMutex
 	authConfig map[string]string
+	// credentials is nil unless oauth2 uses the client-credentials grant
+	credentials *clientCredentials
 }
 
 // NewHTTPAuthManager creates a new HTTPAuthManager
 func NewHTTPAuthManager(serviceConfig *config.EndpointConfig) *HTTPAuthManager {
-	return &HTTPAuthManager{
-		authType:   serviceConfig.AuthType,
-		authConfig: serviceConfig.AuthConfig,
+	a := &HTTPAuthManager{
+		authType:     serviceConfig.AuthType,
+		authConfig:   serviceConfig.AuthConfig,
+		forwardToken: serviceConfig.ForwardAuthToken,
+	}
+	if a.authType == config.AuthTypeOAuth2 {
+		a.credentials = newClientCredentials(serviceConfig.AuthConfig)
+	}
+	return a
+}
+
+// SetAuthConfig replaces the credentials used for new requests
+func (a *HTTPAuthManager) SetAuthConfig(authConfig map[string]string) {
+	a.mu.Lock()
+	a.authConfig = authConfig
+	if a.authType == config.AuthTypeOAuth2 {
+		a.credentials = newClientCredentials(authConfig)
 	}
+	a.mu.Unlock()
+}
+
+// RefreshToken drops the cached client-credentials token after the upstream
+// rejected it. It reports whether a new token will be fetched, which is
+// only the case for the oauth2 client-credentials grant.
+func (a *HTTPAuthManager) RefreshToken() bool {
+	a.mu.RLock()
+	credentials := a.credentials
+	a.mu.RUnlock()
+
+	if credentials == nil {
+		return false
+	}
+	credentials.invalidate()
+	return true
 }
 
 // ApplyAuth adds authentication to the request
 func (a *HTTPAuthManager) ApplyAuth(req *http.Request) error {
+	if err := a.applyConfiguredAuth(req); err != nil {
+		return err
+	}
+
+	// The caller's own token takes precedence over the shared credential
+	if a.forwardToken {
+		if token, ok := UpstreamTokenFromContext(req.Context()); ok {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+	return nil
+}
+
+// applyConfiguredAuth applies the shared credential from the endpoint config
+func (a *HTTPAuthManager) applyConfiguredAuth(req *http.Request) error {
+	a.mu.RLock()
+	authConfig := a.authConfig
+	credentials := a.credentials
+	a.mu.RUnlock()
+
 	switch a.authType {
 	case config.AuthTypeNone:
 		return nil
 	case config.AuthTypeBasic:
-		username := a.authConfig["username"]
-		password := a.authConfig["password"]
+		username := authConfig["username"]
+		password := authConfig["password"]
 		req.SetBasicAuth(username, password)
 	case config.AuthTypeBearer:
-		token := a.authConfig["token"]
+		token := authConfig["token"]
 		req.Header.Set("Authorization", "Bearer "+token)
 	case config.AuthTypeAPIKey:
-		key := a.authConfig["key"]
-		header := a.authConfig["header"]
+		key := authConfig["key"]
+		header := authConfig["header"]
 		if header == "" {
 			header = "X-API-Key"
 		}
 		req.Header.Set(header, key)
 	case config.AuthTypeOAuth2:
-		token := a.authConfig["token"]
+		token := authConfig["token"]
+		if credentials != nil {
+			var err error
+			if token, err = credentials.accessToken(req.Context()); err != nil {
+				return err
+			}
+		}
 		req.Header.Set("Authorization", "Bearer "+token)
+	case config.AuthTypeSession:
+		// Session cookies are attached by the HTTP client's cookie jar
+		return nil
 	default:
 		return fmt.Errorf("unsupported auth type: %s", a.authType)
 	}
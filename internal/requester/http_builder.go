@@ -9,7 +9,9 @@ import (
 	"mime/multipart"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/brizzai/auto-mcp/internal/config"
 
@@ -29,6 +31,7 @@ type HTTPRequestBuilder struct {
 	serviceCfg  *config.EndpointConfig
 	authMgr     AuthManager
 	routeConfig *RouteConfig
+	tenants     map[string]*tenantEndpoint
 }
 
 // NewHTTPRequestBuilder creates a new HTTPRequestBuilder
@@ -45,6 +48,26 @@ func (b *HTTPRequestBuilder) BuildRequest(ctx context.Context, params map[string
 	if b.routeConfig == nil {
 		return nil, fmt.Errorf("route config is nil")
 	}
+	if name, ok := TenantFromContext(ctx); ok {
+		tenant, ok := b.tenants[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown tenant %q", name)
+		}
+		tenantBuilder := *b
+		tenantBuilder.serviceCfg = tenant.cfg
+		tenantBuilder.authMgr = tenant.authMgr
+		b = &tenantBuilder
+	}
+	if baseURL, ok := BaseURLFromContext(ctx); ok {
+		if !b.serviceCfg.BaseURLOverride.Allows(baseURL) {
+			return nil, fmt.Errorf("base URL %q is not allowed by endpoint.base_url_override", baseURL)
+		}
+		cfg := *b.serviceCfg
+		cfg.BaseURL = strings.TrimSuffix(baseURL, "/")
+		overridden := *b
+		overridden.serviceCfg = &cfg
+		b = &overridden
+	}
 	// Build URL
 	url := b.buildURL(b.routeConfig.Path, params)
 
@@ -59,14 +82,40 @@ func (b *HTTPRequestBuilder) BuildRequest(ctx context.Context, params map[string
 		return nil, fmt.Errorf("failed to create request body: %w", err)
 	}
 
-	// Merge headers
+	// Buffer the body so a content-hash idempotency key can be derived from it
+	var idempotencyKey string
+	if b.serviceCfg.Idempotency.Enabled && isUnsafeMethod(b.routeConfig.Method) {
+		body, idempotencyKey, err = idempotencyKeyFor(b.serviceCfg.Idempotency, b.routeConfig.Method, url, body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate idempotency key: %w", err)
+		}
+	}
+
+	// Compress large bodies after hashing so idempotency keys do not depend on it
+	compressed := false
+	if b.serviceCfg.RequestCompressionThreshold > 0 && b.routeConfig.MethodConfig.FileUpload == nil {
+		body, compressed, err = gzipBody(body, b.serviceCfg.RequestCompressionThreshold)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compress request body: %w", err)
+		}
+	}
+
+	// Merge headers: arguments first, so configured headers always win
 	headers := make(map[string]string)
+	for _, name := range b.routeConfig.MethodConfig.HeaderParams {
+		if value, ok := params[name]; ok {
+			headers[name] = formatParamValue(value)
+		}
+	}
 	for k, v := range b.serviceCfg.Headers {
 		headers[k] = v
 	}
 	for k, v := range b.routeConfig.Headers {
 		headers[k] = v
 	}
+	for k, v := range headersFromContext(ctx) {
+		headers[k] = v
+	}
 
 	// Create the HTTP request
 	httpReq, err := http.NewRequestWithContext(ctx, b.routeConfig.Method, url, body)
@@ -74,19 +123,43 @@ func (b *HTTPRequestBuilder) BuildRequest(ctx context.Context, params map[string
 		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
 	}
 
-	// Add headers
+	// Add headers, rejecting values that could inject additional headers
 	for key, value := range headers {
-		httpReq.Header.Set(key, value)
+		if err := setHeader(httpReq.Header, key, value); err != nil {
+			return nil, fmt.Errorf("invalid header: %w", err)
+		}
 	}
 	if contentType != "" {
 		httpReq.Header.Set("Content-Type", contentType)
 	}
+	if compressed {
+		httpReq.Header.Set("Content-Encoding", "gzip")
+	}
 
 	// Apply authentication
 	if err := b.authMgr.ApplyAuth(httpReq); err != nil {
 		return nil, fmt.Errorf("failed to apply authentication: %w", err)
 	}
 
+	// Explicitly configured headers take precedence over the generated key
+	if idempotencyKey != "" {
+		header := b.serviceCfg.Idempotency.HeaderName()
+		if httpReq.Header.Get(header) == "" {
+			httpReq.Header.Set(header, idempotencyKey)
+		}
+	}
+
+	// Send the caller's identity last so no other header source can override it
+	if b.routeConfig.OnBehalfOf {
+		identity, ok := CallerIdentityFromContext(ctx)
+		if !ok {
+			return nil, fmt.Errorf("route requires an on-behalf-of identity but none is available")
+		}
+		if err := applyOnBehalfOf(httpReq, b.serviceCfg.OnBehalfOf, identity, time.Now()); err != nil {
+			return nil, fmt.Errorf("invalid on-behalf-of identity: %w", err)
+		}
+	}
+
 	return &Request{
 		URL:         url,
 		Method:      b.routeConfig.Method,
@@ -103,7 +176,7 @@ func (b *HTTPRequestBuilder) buildURL(path string, params map[string]interface{}
 	// Replace path parameters
 	for key, value := range params {
 		placeholder := fmt.Sprintf("{%s}", key)
-		url = strings.ReplaceAll(url, placeholder, fmt.Sprintf("%v", value))
+		url = strings.ReplaceAll(url, placeholder, formatParamValue(value))
 	}
 
 	return url
@@ -117,17 +190,26 @@ func (b *HTTPRequestBuilder) addQueryParams(baseURL string, params map[string]in
 
 	q := u.Query()
 	for key, value := range params {
-		// Skip body and file parameters
-		if key == "body" || key == "file" {
+		// Skip body, file and header parameters
+		if key == "body" || key == "file" || b.isHeaderParam(key) {
 			continue
 		}
-		q.Set(key, fmt.Sprintf("%v", value))
+		q.Set(key, formatParamValue(value))
 	}
 	u.RawQuery = q.Encode()
 
 	return u.String()
 }
 
+func (b *HTTPRequestBuilder) isHeaderParam(name string) bool {
+	for _, header := range b.routeConfig.MethodConfig.HeaderParams {
+		if header == name {
+			return true
+		}
+	}
+	return false
+}
+
 func (b *HTTPRequestBuilder) createRequestBody(routeConfig *RouteConfig, params map[string]interface{}) (io.Reader, string, error) {
 	switch routeConfig.Method {
 	case "GET":
@@ -145,7 +227,11 @@ func (b *HTTPRequestBuilder) createRequestBody(routeConfig *RouteConfig, params
 			if err != nil {
 				return nil, "", fmt.Errorf("failed to marshal request body: %w", err)
 			}
-			return bytes.NewBuffer(jsonData), "application/json", nil
+			contentType := "application/json"
+			if routeConfig.MethodConfig.BodyContentType != "" {
+				contentType = routeConfig.MethodConfig.BodyContentType
+			}
+			return bytes.NewBuffer(jsonData), contentType, nil
 		}
 		return nil, "", nil
 
@@ -180,7 +266,7 @@ func (b *HTTPRequestBuilder) createMultipartBody(routeConfig *RouteConfig, param
 	// Add other form fields
 	for _, field := range routeConfig.MethodConfig.FormFields {
 		if value, exists := params[field]; exists {
-			if err := writer.WriteField(field, fmt.Sprintf("%v", value)); err != nil {
+			if err := writer.WriteField(field, formatParamValue(value)); err != nil {
 				return nil, "", fmt.Errorf("failed to write form field: %w", err)
 			}
 		}
@@ -192,3 +278,21 @@ func (b *HTTPRequestBuilder) createMultipartBody(routeConfig *RouteConfig, param
 
 	return body, writer.FormDataContentType(), nil
 }
+
+// formatParamValue renders a parameter value for use in a path, query string or
+// form field without losing numeric precision. Whole floats are written without
+// an exponent and json.Number values are passed through verbatim.
+func formatParamValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
@@ -5,11 +5,19 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/cookiejar"
 	"time"
 
 	"github.com/brizzai/auto-mcp/internal/config"
 
 	"github.com/brizzai/auto-mcp/internal/logger"
+	"github.com/brizzai/auto-mcp/internal/plugins"
+	"github.com/brizzai/auto-mcp/internal/telemetry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/fx"
 	"go.uber.org/zap"
 )
@@ -19,6 +27,11 @@ type HTTPRequester struct {
 	client     *http.Client
 	serviceCfg *config.EndpointConfig
 	authMgr    AuthManager
+	session    *sessionLogin // nil unless session auth is configured
+	cache      *responseCache
+	tenants    map[string]*tenantEndpoint
+	hooks      *plugins.Hooks // nil unless plugins are configured
+	cassette   *cassette      // nil unless recording or replaying
 }
 
 type HTTPRequesterParams struct {
@@ -30,13 +43,63 @@ type HTTPRequesterParams struct {
 
 // NewHTTPRequester creates a new HTTPRequester with default configuration
 func NewHTTPRequester(params HTTPRequesterParams) *HTTPRequester {
-	return &HTTPRequester{
+	r := &HTTPRequester{
 		client: &http.Client{
 			Timeout: 30 * time.Second,
 		},
 		serviceCfg: params.ServiceConfig,
 		authMgr:    params.AuthManager,
+		cache:      newResponseCache(),
 	}
+	if params.ServiceConfig != nil {
+		r.tenants = newTenantEndpoints(params.ServiceConfig)
+	}
+
+	if params.ServiceConfig != nil && params.ServiceConfig.AuthType == config.AuthTypeSession {
+		// cookiejar.New never returns an error without options
+		jar, _ := cookiejar.New(nil)
+		r.client.Jar = jar
+		r.session = newSessionLogin(params.ServiceConfig)
+	}
+	if params.ServiceConfig != nil {
+		if r.cassette = newCassette(params.ServiceConfig.Cassette); r.cassette != nil {
+			r.client.Transport = r.cassette
+		}
+	}
+	return r
+}
+
+// Start logs in to the upstream API when session auth is configured, so the
+// first tool call does not wait for it. A failed login is retried by the
+// first call; expired sessions are renewed when the API answers 401.
+func (r *HTTPRequester) Start(ctx context.Context) error {
+	if r.session == nil {
+		return nil
+	}
+	if err := r.session.ensure(ctx, r.client); err != nil {
+		logger.Warn("Upstream session login failed, retrying on the first call", zap.Error(err))
+	}
+	return nil
+}
+
+// UpdateAuthConfig replaces the upstream credentials of the endpoint, or of
+// a tenant when tenant is not empty. Session logins keep the credentials
+// they started with.
+func (r *HTTPRequester) UpdateAuthConfig(tenant string, authConfig map[string]string) error {
+	authMgr := r.authMgr
+	if tenant != "" {
+		endpoint, ok := r.tenants[tenant]
+		if !ok {
+			return fmt.Errorf("unknown tenant %q", tenant)
+		}
+		authMgr = endpoint.authMgr
+	}
+	setter, ok := authMgr.(interface{ SetAuthConfig(map[string]string) })
+	if !ok {
+		return fmt.Errorf("auth manager does not support updating credentials")
+	}
+	setter.SetAuthConfig(authConfig)
+	return nil
 }
 
 // SetTimeout sets the timeout for the HTTP client
@@ -44,50 +107,166 @@ func (r *HTTPRequester) SetTimeout(timeout time.Duration) {
 	r.client.Timeout = timeout
 }
 
+// SetTransport replaces the transport of the HTTP client, e.g. to trace
+// upstream requests. nil restores http.DefaultTransport. When recording or
+// replaying, the cassette stays outermost and wraps transport.
+func (r *HTTPRequester) SetTransport(transport http.RoundTripper) {
+	if r.cassette != nil {
+		r.cassette.next = transport
+		return
+	}
+	r.client.Transport = transport
+}
+
 // BuildRouteExecutor creates a function that can execute requests for a specific route
 func (r *HTTPRequester) BuildRouteExecutor(config *RouteConfig) (RouteExecutor, error) {
 	builder := &HTTPRequestBuilder{
 		serviceCfg:  r.serviceCfg,
 		authMgr:     r.authMgr,
 		routeConfig: config,
+		tenants:     r.tenants,
 	}
+	policy := newRoutePolicy(config.Policy)
 
 	// Return a function that builds and executes the request
 	return func(ctx context.Context, params map[string]interface{}) (*Response, error) {
-		// Build request
-		req, err := builder.BuildRequest(ctx, params)
+		if r.session != nil {
+			if err := r.session.ensure(ctx, r.client); err != nil {
+				return nil, fmt.Errorf("session login failed: %w", err)
+			}
+		}
+
+		resp, err := r.buildAndExecute(ctx, builder, policy, params)
 		if err != nil {
 			return nil, err
 		}
-		logger.Info("request route", zap.Any("request", req.URL))
 
-		// CR if u pass the context to BuildRequest, u dont need this
-		// Update the context of the HTTP request
-		if ctx != nil && req.HttpRequest != nil {
-			req.HttpRequest = req.HttpRequest.WithContext(ctx)
+		// An expired session shows up as 401: log in again and retry once
+		if r.session != nil && resp.StatusCode == http.StatusUnauthorized {
+			logger.Info("Upstream session expired, logging in again")
+			r.session.invalidate()
+			if err := r.session.ensure(ctx, r.client); err != nil {
+				return nil, fmt.Errorf("session login failed: %w", err)
+			}
+			return r.buildAndExecute(ctx, builder, policy, params)
 		}
 
-		// Execute request
-		resp, err := r.execute(req)
-		if err != nil {
-			logger.Error("failed to execute request", zap.Error(err))
-			return nil, err
+		// An expired client-credentials token too: fetch a new one and retry once
+		if resp.StatusCode == http.StatusUnauthorized && r.refreshToken(ctx) {
+			logger.Info("Upstream rejected the access token, fetching a new one")
+			return r.buildAndExecute(ctx, builder, policy, params)
 		}
 
 		return resp, nil
 	}, nil
 }
 
+// refreshToken drops the client-credentials token of the endpoint ctx
+// calls, reporting whether a new token will be fetched. Forwarded caller
+// tokens are the caller's to refresh.
+func (r *HTTPRequester) refreshToken(ctx context.Context) bool {
+	if _, ok := UpstreamTokenFromContext(ctx); ok && r.serviceCfg != nil && r.serviceCfg.ForwardAuthToken {
+		return false
+	}
+	authMgr := r.authMgr
+	if tenant, ok := TenantFromContext(ctx); ok {
+		endpoint, ok := r.tenants[tenant]
+		if !ok {
+			return false
+		}
+		authMgr = endpoint.authMgr
+	}
+	refresher, ok := authMgr.(interface{ RefreshToken() bool })
+	return ok && refresher.RefreshToken()
+}
+
+// buildAndExecute builds a fresh request for params and executes it
+func (r *HTTPRequester) buildAndExecute(ctx context.Context, builder *HTTPRequestBuilder, policy *routePolicy, params map[string]interface{}) (*Response, error) {
+	// Build request
+	req, err := builder.BuildRequest(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("request route", zap.Any("request", req.URL))
+
+	// CR if u pass the context to BuildRequest, u dont need this
+	// Update the context of the HTTP request
+	if ctx != nil && req.HttpRequest != nil {
+		req.HttpRequest = req.HttpRequest.WithContext(ctx)
+	}
+
+	// Plugins see the final request, so the cache key covers their changes
+	if r.hooks.HasRequestHooks() {
+		if err := r.applyPreRequest(ctx, req); err != nil {
+			return nil, err
+		}
+	}
+
+	// Serve recent responses from the cache unless the caller demands live data
+	var key string
+	if builder.routeConfig.CacheMaxAge > 0 && req.Method == http.MethodGet {
+		key = cacheKey(req.HttpRequest, r.serviceCfg.OnBehalfOf.HeaderName())
+		// Tenants may share a base URL with different credentials
+		if tenant, ok := TenantFromContext(ctx); ok {
+			key = tenant + " " + key
+		}
+		if !wantsFreshResult(ctx) {
+			if cached, ok := r.cache.get(key); ok {
+				logger.Debug("Serving response from cache", zap.String("url", req.URL))
+				return cached, nil
+			}
+		}
+	}
+
+	// Execute request
+	resp, err := r.executeWithPolicy(req, policy)
+	if err != nil {
+		logger.Error("failed to execute request", zap.Error(err))
+		return nil, err
+	}
+
+	if r.hooks.HasRequestHooks() {
+		if err := r.applyPostResponse(ctx, req, resp); err != nil {
+			return nil, err
+		}
+	}
+
+	if key != "" && resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		r.cache.put(key, resp, builder.routeConfig.CacheMaxAge)
+	}
+	return resp, nil
+}
+
 // execute performs the actual HTTP request execution
-func (r *HTTPRequester) execute(req *Request) (*Response, error) {
+func (r *HTTPRequester) execute(client *http.Client, req *Request) (*Response, error) {
 	// Use the pre-built HTTP request
 	httpReq := req.HttpRequest
 
+	ctx, span := telemetry.Tracer().Start(httpReq.Context(), "HTTP "+httpReq.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", httpReq.Method),
+			attribute.String("url.full", httpReq.URL.Redacted()),
+			attribute.String("server.address", httpReq.URL.Hostname()),
+		),
+	)
+	defer span.End()
+
+	// Propagate traceparent so the upstream API joins the trace
+	httpReq = httpReq.WithContext(ctx)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(httpReq.Header))
+
 	// Execute request
-	resp, err := r.client.Do(httpReq)
+	resp, err := client.Do(httpReq)
 	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	if resp.StatusCode >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, resp.Status)
+	}
 	defer func() {
 		// CR: Its importnat to read the whole body
 		if closeErr := resp.Body.Close(); closeErr != nil {
@@ -100,6 +279,14 @@ func (r *HTTPRequester) execute(req *Request) (*Response, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
+	bodyBytes, err = decodeContentEncoding(resp.Header, bodyBytes)
+	if err != nil {
+		return nil, err
+	}
+	bodyBytes, err = decodeCharset(resp.Header, bodyBytes)
+	if err != nil {
+		return nil, err
+	}
 
 	return &Response{
 		StatusCode: resp.StatusCode,
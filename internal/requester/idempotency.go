@@ -0,0 +1,52 @@
+package requester
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"github.com/brizzai/auto-mcp/internal/config"
+)
+
+// isUnsafeMethod reports whether retrying method may create duplicate resources
+func isUnsafeMethod(method string) bool {
+	return method == "POST" || method == "PATCH"
+}
+
+// idempotencyKeyFor returns the idempotency key for a request. The body is
+// buffered and returned as a fresh reader, since hashing consumes it.
+func idempotencyKeyFor(cfg config.IdempotencyConfig, method, url string, body io.Reader) (io.Reader, string, error) {
+	if cfg.Strategy != config.IdempotencyStrategyHash {
+		key, err := newUUID()
+		return body, key, err
+	}
+
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = io.ReadAll(body); err != nil {
+			return nil, "", err
+		}
+		body = bytes.NewReader(payload)
+	}
+
+	// Identical retries of the same call map to the same key
+	hash := sha256.New()
+	hash.Write([]byte(method + "\n" + url + "\n"))
+	hash.Write(payload)
+	return body, hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// newUUID returns a random RFC 4122 version 4 UUID
+func newUUID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
@@ -4,7 +4,8 @@ import (
 	"go.uber.org/fx"
 )
 
-// Module provides the requester module dependencies
+// Module provides the requester module dependencies and logs in to session
+// auth upstreams at startup
 var Module = fx.Options(
 	fx.Provide(
 		NewHTTPRequester,
@@ -14,4 +15,7 @@ var Module = fx.Options(
 		),
 		NewHTTPRequestBuilder,
 	),
+	fx.Invoke(func(lc fx.Lifecycle, r *HTTPRequester) {
+		lc.Append(fx.Hook{OnStart: r.Start})
+	}),
 )
@@ -0,0 +1,38 @@
+package requester
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/brizzai/auto-mcp/internal/config"
+)
+
+// applyOnBehalfOf sets the delegation header for identity. When a signing
+// secret is configured, "<header>-Timestamp" and "<header>-Signature" are added
+// with an HMAC-SHA256 over "<identity>\n<timestamp>" so the backend can verify
+// the identity was asserted by this server.
+func applyOnBehalfOf(req *http.Request, cfg config.OnBehalfOfConfig, identity string, now time.Time) error {
+	header := cfg.HeaderName()
+	if err := setHeader(req.Header, header, identity); err != nil {
+		return err
+	}
+
+	if cfg.SigningSecret == "" {
+		return nil
+	}
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	req.Header.Set(header+"-Timestamp", timestamp)
+	req.Header.Set(header+"-Signature", SignOnBehalfOf(cfg.SigningSecret, identity, timestamp))
+	return nil
+}
+
+// SignOnBehalfOf returns the hex encoded signature for an identity and timestamp
+func SignOnBehalfOf(secret, identity, timestamp string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(identity + "\n" + timestamp))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,137 @@
+package requester
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/brizzai/auto-mcp/internal/logger"
+	"go.uber.org/zap"
+)
+
+// Retry backoff of route policies: the first retry waits retryBaseDelay and
+// every further one twice as long, up to retryMaxDelay. A Retry-After
+// header longer than retryMaxDelay ends the retries.
+const (
+	retryBaseDelay = 500 * time.Millisecond
+	retryMaxDelay  = 10 * time.Second
+)
+
+// rateLimiter spaces requests evenly so no more than perMinute are sent per minute
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func newRateLimiter(perMinute int) *rateLimiter {
+	if perMinute <= 0 {
+		return nil
+	}
+	return &rateLimiter{interval: time.Minute / time.Duration(perMinute)}
+}
+
+// wait blocks until the next request may be sent or ctx is done. A nil
+// limiter never waits.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	now := time.Now()
+	at := l.next
+	if at.Before(now) {
+		at = now
+	}
+	l.next = at.Add(l.interval)
+	l.mu.Unlock()
+
+	return sleep(ctx, time.Until(at))
+}
+
+// routePolicy is the RoutePolicy of one route executor with its limiter
+type routePolicy struct {
+	RoutePolicy
+	limiter *rateLimiter
+}
+
+func newRoutePolicy(policy RoutePolicy) *routePolicy {
+	return &routePolicy{RoutePolicy: policy, limiter: newRateLimiter(policy.RateLimit)}
+}
+
+// executeWithPolicy sends req, waiting for the rate limit before every
+// attempt and retrying failed attempts as the policy allows
+func (r *HTTPRequester) executeWithPolicy(req *Request, policy *routePolicy) (*Response, error) {
+	ctx := req.HttpRequest.Context()
+	client := r.client
+	if policy.Timeout > 0 {
+		withTimeout := *r.client
+		withTimeout.Timeout = policy.Timeout
+		client = &withTimeout
+	}
+
+	for attempt := 0; ; attempt++ {
+		if err := policy.limiter.wait(ctx); err != nil {
+			return nil, err
+		}
+		resp, err := r.execute(client, req)
+
+		delay, retry := retryDelay(attempt, resp, err)
+		if !retry || attempt >= policy.Retries || ctx.Err() != nil {
+			return resp, err
+		}
+		// Requests whose body can't be read again are sent once
+		if req.HttpRequest.Body != nil && req.HttpRequest.GetBody == nil {
+			return resp, err
+		}
+		logger.Info("Retrying upstream request", zap.String("url", req.URL), zap.Int("attempt", attempt+1), zap.Duration("delay", delay))
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+		if req.HttpRequest.GetBody != nil {
+			body, err := req.HttpRequest.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.HttpRequest.Body = body
+		}
+	}
+}
+
+// retryDelay reports whether an attempt should be retried and after how
+// long: network errors, 429, 502, 503 and 504 are retried
+func retryDelay(attempt int, resp *Response, err error) (time.Duration, bool) {
+	delay := min(retryBaseDelay<<attempt, retryMaxDelay)
+	if err != nil {
+		return delay, !errors.Is(err, context.Canceled)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		if seconds, err := strconv.Atoi(resp.Headers.Get("Retry-After")); err == nil && seconds >= 0 {
+			after := time.Duration(seconds) * time.Second
+			return after, after <= retryMaxDelay
+		}
+		return delay, true
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return delay, true
+	}
+	return 0, false
+}
+
+// sleep waits for d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,122 @@
+package requester
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/brizzai/auto-mcp/internal/config"
+	"github.com/brizzai/auto-mcp/internal/logger"
+	"go.uber.org/zap"
+)
+
+// sessionLogin performs the configured login request for cookie-based
+// session authentication. Session cookies end up in the HTTP client's jar.
+type sessionLogin struct {
+	url         string
+	method      string
+	body        string
+	contentType string
+
+	mu       sync.Mutex
+	loggedIn bool
+}
+
+// newSessionLogin creates a session login from the endpoint auth config
+func newSessionLogin(cfg *config.EndpointConfig) *sessionLogin {
+	authCfg := cfg.AuthConfig
+	loginURL := authCfg["login_url"]
+	if strings.HasPrefix(loginURL, "/") {
+		loginURL = strings.TrimSuffix(cfg.BaseURL, "/") + loginURL
+	}
+
+	method := strings.ToUpper(authCfg["login_method"])
+	if method == "" {
+		method = http.MethodPost
+	}
+
+	body := authCfg["login_body"]
+	contentType := authCfg["login_content_type"]
+	if body == "" && authCfg["username"] != "" {
+		// Fall back to a classic form login
+		form := url.Values{}
+		form.Set("username", authCfg["username"])
+		form.Set("password", authCfg["password"])
+		body = form.Encode()
+		if contentType == "" {
+			contentType = "application/x-www-form-urlencoded"
+		}
+	}
+	if contentType == "" && body != "" {
+		contentType = "application/json"
+	}
+
+	return &sessionLogin{
+		url:         loginURL,
+		method:      method,
+		body:        body,
+		contentType: contentType,
+	}
+}
+
+// ensure logs in unless a session has already been established
+func (s *sessionLogin) ensure(ctx context.Context, client *http.Client) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.loggedIn {
+		return nil
+	}
+	if err := s.login(ctx, client); err != nil {
+		return err
+	}
+	s.loggedIn = true
+	return nil
+}
+
+// invalidate forces a fresh login on the next request
+func (s *sessionLogin) invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loggedIn = false
+}
+
+// login performs the login request; cookies are captured by the client's jar
+func (s *sessionLogin) login(ctx context.Context, client *http.Client) error {
+	if s.url == "" {
+		return fmt.Errorf("session auth requires auth_config.login_url")
+	}
+
+	var body io.Reader
+	if s.body != "" {
+		body = strings.NewReader(s.body)
+	}
+	req, err := http.NewRequestWithContext(ctx, s.method, s.url, body)
+	if err != nil {
+		return fmt.Errorf("failed to create login request: %w", err)
+	}
+	if s.contentType != "" {
+		req.Header.Set("Content-Type", s.contentType)
+	}
+
+	logger.Info("Logging in to upstream API", zap.String("url", s.url))
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("login request failed: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			logger.Error("Failed to close login response body", zap.Error(err))
+		}
+	}()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("login request failed with status %d", resp.StatusCode)
+	}
+	return nil
+}
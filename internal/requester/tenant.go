@@ -0,0 +1,43 @@
+package requester
+
+import (
+	"maps"
+
+	"github.com/brizzai/auto-mcp/internal/config"
+)
+
+// tenantEndpoint is the upstream endpoint and credentials of one tenant
+type tenantEndpoint struct {
+	cfg     *config.EndpointConfig
+	authMgr AuthManager
+}
+
+// newTenantEndpoints merges each tenant's overrides onto the endpoint config
+func newTenantEndpoints(endpoint *config.EndpointConfig) map[string]*tenantEndpoint {
+	if len(endpoint.Tenants) == 0 {
+		return nil
+	}
+
+	tenants := make(map[string]*tenantEndpoint, len(endpoint.Tenants))
+	for _, tenant := range endpoint.Tenants {
+		cfg := *endpoint
+		cfg.Tenants = nil
+		if tenant.BaseURL != "" {
+			cfg.BaseURL = tenant.BaseURL
+		}
+		if tenant.AuthType != "" {
+			cfg.AuthType = tenant.AuthType
+		}
+		if tenant.AuthConfig != nil {
+			cfg.AuthConfig = tenant.AuthConfig
+		}
+		cfg.Headers = maps.Clone(endpoint.Headers)
+		if cfg.Headers == nil {
+			cfg.Headers = make(map[string]string, len(tenant.Headers))
+		}
+		maps.Copy(cfg.Headers, tenant.Headers)
+
+		tenants[tenant.Name] = &tenantEndpoint{cfg: &cfg, authMgr: NewHTTPAuthManager(&cfg)}
+	}
+	return tenants
+}
@@ -1,6 +1,7 @@
 package tests
 
 import (
+	"context"
 	"net/http"
 	"testing"
 
@@ -16,6 +17,7 @@ func TestHTTPAuthManager_ApplyAuth(t *testing.T) {
 		name       string
 		authType   config.AuthType
 		authConfig map[string]string
+		forward    bool
 		req        *http.Request
 		wantErr    bool
 		checkAuth  func(t *testing.T, req *http.Request)
@@ -83,6 +85,34 @@ func TestHTTPAuthManager_ApplyAuth(t *testing.T) {
 				assert.Equal(t, "Bearer oauth-token", req.Header.Get("Authorization"))
 			},
 		},
+		{
+			name:     "Forwarded Caller Token",
+			authType: config.AuthTypeBearer,
+			authConfig: map[string]string{
+				"token": "shared-token",
+			},
+			forward: true,
+			req: (&http.Request{Header: make(http.Header)}).WithContext(
+				requester.WithUpstreamToken(context.Background(), "caller-token"),
+			),
+			wantErr: false,
+			checkAuth: func(t *testing.T, req *http.Request) {
+				assert.Equal(t, "Bearer caller-token", req.Header.Get("Authorization"))
+			},
+		},
+		{
+			name:     "Forwarding Without Caller Token",
+			authType: config.AuthTypeBearer,
+			authConfig: map[string]string{
+				"token": "shared-token",
+			},
+			forward: true,
+			req:     &http.Request{Header: make(http.Header)},
+			wantErr: false,
+			checkAuth: func(t *testing.T, req *http.Request) {
+				assert.Equal(t, "Bearer shared-token", req.Header.Get("Authorization"))
+			},
+		},
 		{
 			name:       "Invalid Auth Type",
 			authType:   "invalid",
@@ -96,8 +126,9 @@ func TestHTTPAuthManager_ApplyAuth(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			manager := requester.NewHTTPAuthManager(&config.EndpointConfig{
-				AuthType:   tt.authType,
-				AuthConfig: tt.authConfig,
+				AuthType:         tt.authType,
+				AuthConfig:       tt.authConfig,
+				ForwardAuthToken: tt.forward,
 			})
 
 			err := manager.ApplyAuth(tt.req)
@@ -2,7 +2,10 @@ package tests
 
 import (
 	"context"
+	"encoding/json"
+	"io"
 	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/brizzai/auto-mcp/internal/config"
@@ -91,6 +94,68 @@ func TestHTTPRequestBuilder_BuildRequest(t *testing.T) {
 				assert.Equal(t, "application/json", req.HttpRequest.Header.Get("Content-Type"))
 			},
 		},
+		{
+			name:  "Numeric Params Keep Precision",
+			route: "orders",
+			params: map[string]interface{}{
+				"orderId": float64(12345678901),
+				"body": map[string]interface{}{
+					"id": json.Number("9007199254740993"),
+				},
+			},
+			config: &config.EndpointConfig{
+				BaseURL: "http://api.example.com",
+			},
+			routeConfig: &requester.RouteConfig{
+				Method: "PUT",
+				Path:   "/orders/{orderId}",
+			},
+			authManager: &mockAuthManager{
+				applyAuthFunc: func(req *http.Request) error {
+					return nil
+				},
+			},
+			wantErr: false,
+			checkRequest: func(t *testing.T, req *requester.Request) {
+				assert.Equal(t, "http://api.example.com/orders/12345678901", req.HttpRequest.URL.String())
+				body, err := io.ReadAll(req.HttpRequest.Body)
+				require.NoError(t, err)
+				assert.JSONEq(t, `{"id": 9007199254740993}`, string(body))
+				assert.Contains(t, string(body), "9007199254740993")
+			},
+		},
+		{
+			name:  "JSON Patch Body Content Type",
+			route: "pets",
+			params: map[string]interface{}{
+				"body": []interface{}{
+					map[string]interface{}{"op": "replace", "path": "/name", "value": "Rex"},
+				},
+			},
+			config: &config.EndpointConfig{
+				BaseURL: "http://api.example.com",
+			},
+			routeConfig: &requester.RouteConfig{
+				Method:  "PATCH",
+				Path:    "/pets/1",
+				Headers: map[string]string{"Content-Type": "application/json"},
+				MethodConfig: requester.MethodConfig{
+					BodyContentType: "application/json-patch+json",
+				},
+			},
+			authManager: &mockAuthManager{
+				applyAuthFunc: func(req *http.Request) error {
+					return nil
+				},
+			},
+			wantErr: false,
+			checkRequest: func(t *testing.T, req *requester.Request) {
+				assert.Equal(t, "application/json-patch+json", req.HttpRequest.Header.Get("Content-Type"))
+				body, err := io.ReadAll(req.HttpRequest.Body)
+				require.NoError(t, err)
+				assert.JSONEq(t, `[{"op": "replace", "path": "/name", "value": "Rex"}]`, string(body))
+			},
+		},
 		{
 			name:   "Invalid Route",
 			route:  "invalid-route",
@@ -128,3 +193,144 @@ func TestHTTPRequestBuilder_BuildRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestHTTPRequestBuilder_OnBehalfOf(t *testing.T) {
+	endpointCfg := &config.EndpointConfig{
+		BaseURL: "http://api.example.com",
+		OnBehalfOf: config.OnBehalfOfConfig{
+			Header:        "X-Acting-User",
+			SigningSecret: "shh",
+		},
+	}
+	builder := requester.NewHTTPRequestBuilder(requester.HTTPRequestBuilderParams{
+		EndpointConfig: endpointCfg,
+		AuthManager: &mockAuthManager{
+			applyAuthFunc: func(req *http.Request) error {
+				return nil
+			},
+		},
+		RouteConfig: &requester.RouteConfig{
+			Method:     "GET",
+			Path:       "/orders",
+			Headers:    map[string]string{"X-Acting-User": "someone-else"},
+			OnBehalfOf: true,
+		},
+	})
+
+	t.Run("identity header is signed and wins over route headers", func(t *testing.T) {
+		ctx := requester.WithCallerIdentity(context.Background(), "alice@example.com")
+		req, err := builder.BuildRequest(ctx, map[string]interface{}{})
+		require.NoError(t, err)
+
+		header := req.HttpRequest.Header
+		assert.Equal(t, "alice@example.com", header.Get("X-Acting-User"))
+		timestamp := header.Get("X-Acting-User-Timestamp")
+		require.NotEmpty(t, timestamp)
+		assert.Equal(t, requester.SignOnBehalfOf("shh", "alice@example.com", timestamp), header.Get("X-Acting-User-Signature"))
+	})
+
+	t.Run("missing identity fails closed", func(t *testing.T) {
+		_, err := builder.BuildRequest(context.Background(), map[string]interface{}{})
+		assert.Error(t, err)
+	})
+}
+
+func TestHTTPRequestBuilder_IdempotencyKey(t *testing.T) {
+	newBuilder := func(cfg config.IdempotencyConfig, method string) *requester.HTTPRequestBuilder {
+		return requester.NewHTTPRequestBuilder(requester.HTTPRequestBuilderParams{
+			EndpointConfig: &config.EndpointConfig{
+				BaseURL:     "http://api.example.com",
+				Idempotency: cfg,
+			},
+			AuthManager: &mockAuthManager{
+				applyAuthFunc: func(req *http.Request) error {
+					return nil
+				},
+			},
+			RouteConfig: &requester.RouteConfig{
+				Method: method,
+				Path:   "/orders",
+			},
+		})
+	}
+	params := func() map[string]interface{} {
+		return map[string]interface{}{"body": map[string]interface{}{"item": "book"}}
+	}
+
+	t.Run("hash keys are stable and the body is preserved", func(t *testing.T) {
+		builder := newBuilder(config.IdempotencyConfig{Enabled: true, Strategy: config.IdempotencyStrategyHash}, "POST")
+		first, err := builder.BuildRequest(context.Background(), params())
+		require.NoError(t, err)
+		second, err := builder.BuildRequest(context.Background(), params())
+		require.NoError(t, err)
+
+		key := first.HttpRequest.Header.Get("Idempotency-Key")
+		assert.Len(t, key, 64)
+		assert.Equal(t, key, second.HttpRequest.Header.Get("Idempotency-Key"))
+
+		body, err := io.ReadAll(first.HttpRequest.Body)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"item": "book"}`, string(body))
+	})
+
+	t.Run("uuid keys use the configured header", func(t *testing.T) {
+		builder := newBuilder(config.IdempotencyConfig{Enabled: true, Header: "X-Request-Key"}, "PATCH")
+		req, err := builder.BuildRequest(context.Background(), params())
+		require.NoError(t, err)
+		assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, req.HttpRequest.Header.Get("X-Request-Key"))
+	})
+
+	t.Run("safe methods are left alone", func(t *testing.T) {
+		builder := newBuilder(config.IdempotencyConfig{Enabled: true}, "PUT")
+		req, err := builder.BuildRequest(context.Background(), params())
+		require.NoError(t, err)
+		assert.Empty(t, req.HttpRequest.Header.Get("Idempotency-Key"))
+	})
+}
+
+func TestHTTPRequestBuilder_HeaderParams(t *testing.T) {
+	builder := requester.NewHTTPRequestBuilder(requester.HTTPRequestBuilderParams{
+		EndpointConfig: &config.EndpointConfig{
+			BaseURL: "http://api.example.com",
+			Headers: map[string]string{"X-Tenant": "acme"},
+		},
+		AuthManager: &mockAuthManager{
+			applyAuthFunc: func(req *http.Request) error {
+				return nil
+			},
+		},
+		RouteConfig: &requester.RouteConfig{
+			Method: "GET",
+			Path:   "/reports",
+			MethodConfig: requester.MethodConfig{
+				QueryParams:  []string{"year"},
+				HeaderParams: []string{"X-Api-Version", "X-Tenant"},
+			},
+		},
+	})
+
+	t.Run("header arguments are sent as headers", func(t *testing.T) {
+		req, err := builder.BuildRequest(context.Background(), map[string]interface{}{
+			"year":          "2024",
+			"X-Api-Version": "2024-06-01",
+			"X-Tenant":      "other",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "http://api.example.com/reports?year=2024", req.HttpRequest.URL.String())
+		assert.Equal(t, "2024-06-01", req.HttpRequest.Header.Get("X-Api-Version"))
+		// Configured headers cannot be overridden by arguments
+		assert.Equal(t, "acme", req.HttpRequest.Header.Get("X-Tenant"))
+	})
+
+	for name, value := range map[string]string{
+		"CRLF injection": "1\r\nX-Admin: true",
+		"LF injection":   "1\nX-Admin: true",
+		"NUL byte":       "1\x00",
+		"oversized":      strings.Repeat("a", 9000),
+	} {
+		t.Run("rejects "+name, func(t *testing.T) {
+			_, err := builder.BuildRequest(context.Background(), map[string]interface{}{"X-Api-Version": value})
+			assert.ErrorContains(t, err, "invalid header")
+		})
+	}
+}
@@ -1,18 +1,30 @@
 package tests
 
 import (
+	"compress/gzip"
 	"context"
 	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/brizzai/auto-mcp/internal/config"
+	"github.com/brizzai/auto-mcp/internal/plugins"
 	"github.com/brizzai/auto-mcp/internal/requester"
+	"github.com/brizzai/auto-mcp/pkg/hooks"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 // MockAuthManager implements the AuthManager interface for testing
@@ -191,3 +203,585 @@ func TestHTTPRequester(t *testing.T) {
 		})
 	}
 }
+
+// sessionBackend is an upstream API with cookie session auth. Requests to
+// /data need the session cookie of the latest login.
+type sessionBackend struct {
+	*httptest.Server
+	logins       int
+	validSession string
+}
+
+func newSessionBackend(t *testing.T) *sessionBackend {
+	b := &sessionBackend{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "alice", r.PostForm.Get("username"))
+		assert.Equal(t, "secret", r.PostForm.Get("password"))
+
+		b.logins++
+		b.validSession = fmt.Sprintf("session-%d", b.logins)
+		http.SetCookie(w, &http.Cookie{Name: "sid", Value: b.validSession, Path: "/"})
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("/data", func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie("sid")
+		if err != nil || cookie.Value != b.validSession {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	b.Server = httptest.NewServer(mux)
+	t.Cleanup(b.Close)
+	return b
+}
+
+// requester returns a session auth requester for the backend and an executor of GET /data
+func (b *sessionBackend) requester(t *testing.T) (*requester.HTTPRequester, requester.RouteExecutor) {
+	serviceConfig := &config.EndpointConfig{
+		BaseURL:  b.URL,
+		AuthType: config.AuthTypeSession,
+		AuthConfig: map[string]string{
+			"login_url": "/login",
+			"username":  "alice",
+			"password":  "secret",
+		},
+	}
+	r := requester.NewHTTPRequester(requester.HTTPRequesterParams{
+		ServiceConfig: serviceConfig,
+		AuthManager:   requester.NewHTTPAuthManager(serviceConfig),
+	})
+	executor, err := r.BuildRouteExecutor(&requester.RouteConfig{Path: "/data", Method: "GET"})
+	require.NoError(t, err)
+	return r, executor
+}
+
+func TestHTTPRequester_SessionAuth(t *testing.T) {
+	backend := newSessionBackend(t)
+	_, executor := backend.requester(t)
+
+	// Without Start, the first call logs in before the request
+	resp, err := executor(context.Background(), map[string]interface{}{})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 1, backend.logins)
+
+	// Expire the session on the server side: the requester logs in again and retries
+	backend.validSession = "expired"
+	resp, err = executor(context.Background(), map[string]interface{}{})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 2, backend.logins)
+}
+
+func TestHTTPRequester_SessionAuthAtStart(t *testing.T) {
+	backend := newSessionBackend(t)
+	r, executor := backend.requester(t)
+
+	require.NoError(t, r.Start(context.Background()))
+	assert.Equal(t, 1, backend.logins, "Start logs in")
+
+	resp, err := executor(context.Background(), map[string]interface{}{})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 1, backend.logins, "calls reuse the session")
+
+	backend.validSession = "expired"
+	resp, err = executor(context.Background(), map[string]interface{}{})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 2, backend.logins)
+}
+
+func TestHTTPRequester_SessionAuthStartFailure(t *testing.T) {
+	backend := newSessionBackend(t)
+	r, executor := backend.requester(t)
+	backend.Close()
+
+	// An unreachable upstream does not stop the server from starting
+	require.NoError(t, r.Start(context.Background()))
+	_, err := executor(context.Background(), map[string]interface{}{})
+	assert.ErrorContains(t, err, "session login failed")
+}
+
+func TestHTTPRequester_ClientCredentials(t *testing.T) {
+	tokens := 0
+	validToken := ""
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+		assert.Equal(t, "orders:read", r.PostForm.Get("scope"))
+		clientID, clientSecret, _ := r.BasicAuth()
+		assert.Equal(t, "svc", clientID)
+		assert.Equal(t, "secret", clientSecret)
+
+		tokens++
+		validToken = fmt.Sprintf("token-%d", tokens)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"access_token": %q, "token_type": "bearer", "expires_in": 3600}`, validToken)
+	})
+	mux.HandleFunc("/data", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+validToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	serviceConfig := &config.EndpointConfig{
+		BaseURL:  server.URL,
+		AuthType: config.AuthTypeOAuth2,
+		AuthConfig: map[string]string{
+			"token_url":     server.URL + "/token",
+			"client_id":     "svc",
+			"client_secret": "secret",
+			"scopes":        "orders:read",
+		},
+	}
+	r := requester.NewHTTPRequester(requester.HTTPRequesterParams{
+		ServiceConfig: serviceConfig,
+		AuthManager:   requester.NewHTTPAuthManager(serviceConfig),
+	})
+	executor, err := r.BuildRouteExecutor(&requester.RouteConfig{Path: "/data", Method: "GET"})
+	require.NoError(t, err)
+
+	// The token is fetched once and reused
+	for range 2 {
+		resp, err := executor(context.Background(), map[string]interface{}{})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+	assert.Equal(t, 1, tokens)
+
+	// A revoked token is replaced and the call retried once
+	validToken = "revoked"
+	resp, err := executor(context.Background(), map[string]interface{}{})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 2, tokens)
+}
+
+func TestHTTPRequester_PropagatesTraceContext(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	}()
+
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	serviceConfig := &config.EndpointConfig{BaseURL: server.URL}
+	r := requester.NewHTTPRequester(requester.HTTPRequesterParams{
+		ServiceConfig: serviceConfig,
+		AuthManager:   &MockAuthManager{},
+	})
+	executor, err := r.BuildRouteExecutor(&requester.RouteConfig{Path: "/traced", Method: "GET"})
+	require.NoError(t, err)
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "tools/call")
+	_, err = executor(ctx, map[string]interface{}{})
+	parent.End()
+	require.NoError(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	client := spans[0]
+	assert.Equal(t, "HTTP GET", client.Name())
+	assert.Equal(t, parent.SpanContext().TraceID(), client.SpanContext().TraceID())
+	assert.Contains(t, traceparent, client.SpanContext().SpanID().String())
+}
+
+func TestHTTPRequester_ResponseCache(t *testing.T) {
+	hits := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		_, _ = fmt.Fprintf(w, `{"hits": %d}`, hits)
+	}))
+	defer server.Close()
+
+	serviceConfig := &config.EndpointConfig{BaseURL: server.URL}
+	r := requester.NewHTTPRequester(requester.HTTPRequesterParams{
+		ServiceConfig: serviceConfig,
+		AuthManager:   &MockAuthManager{},
+	})
+	executor, err := r.BuildRouteExecutor(&requester.RouteConfig{Path: "/stats", Method: "GET", CacheMaxAge: time.Minute})
+	require.NoError(t, err)
+
+	resp, err := executor(context.Background(), map[string]interface{}{})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"hits": 1}`, string(resp.Body))
+
+	// Repeated calls are served from the cache
+	resp, err = executor(context.Background(), map[string]interface{}{})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"hits": 1}`, string(resp.Body))
+
+	// Different arguments are cached separately
+	resp, err = executor(context.Background(), map[string]interface{}{"day": "monday"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"hits": 2}`, string(resp.Body))
+
+	// A fresh request bypasses the cache and refreshes it
+	resp, err = executor(requester.WithFreshResult(context.Background()), map[string]interface{}{})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"hits": 3}`, string(resp.Body))
+	resp, err = executor(context.Background(), map[string]interface{}{})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"hits": 3}`, string(resp.Body))
+}
+
+func TestHTTPRequester_Compression(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Echo the decompressed request body back, gzip encoded
+		assert.Equal(t, "gzip", r.Header.Get("Content-Encoding"))
+		gr, err := gzip.NewReader(r.Body)
+		require.NoError(t, err)
+		payload, err := io.ReadAll(gr)
+		require.NoError(t, err)
+
+		w.Header().Set("Content-Encoding", "gzip")
+		gw := gzip.NewWriter(w)
+		_, _ = gw.Write(payload)
+		_ = gw.Close()
+	}))
+	defer server.Close()
+
+	// A custom Accept-Encoding disables Go's transparent decompression
+	serviceConfig := &config.EndpointConfig{
+		BaseURL:                     server.URL,
+		Headers:                     map[string]string{"Accept-Encoding": "gzip"},
+		RequestCompressionThreshold: 16,
+	}
+	r := requester.NewHTTPRequester(requester.HTTPRequesterParams{
+		ServiceConfig: serviceConfig,
+		AuthManager:   &MockAuthManager{},
+	})
+	executor, err := r.BuildRouteExecutor(&requester.RouteConfig{Path: "/echo", Method: "POST"})
+	require.NoError(t, err)
+
+	resp, err := executor(context.Background(), map[string]interface{}{
+		"body": map[string]interface{}{"message": "a body long enough to be compressed"},
+	})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"message": "a body long enough to be compressed"}`, string(resp.Body))
+	assert.Empty(t, resp.Headers.Get("Content-Encoding"))
+}
+
+func TestHTTPRequester_Charset(t *testing.T) {
+	tests := []struct {
+		name            string
+		contentType     string
+		body            []byte
+		wantBody        string
+		wantContentType string
+	}{
+		{
+			name:            "latin-1",
+			contentType:     "text/plain; charset=ISO-8859-1",
+			body:            []byte("Caf\xe9 cr\xe8me"),
+			wantBody:        "Café crème",
+			wantContentType: "text/plain; charset=utf-8",
+		},
+		{
+			name:            "shift_jis json",
+			contentType:     "application/json; charset=Shift_JIS",
+			body:            []byte("{\"name\": \"\x93\x8c\x8b\x9e\"}"),
+			wantBody:        `{"name": "東京"}`,
+			wantContentType: "application/json; charset=utf-8",
+		},
+		{
+			name:            "utf-16 byte order mark",
+			contentType:     "application/json",
+			body:            []byte{0xFF, 0xFE, '{', 0, '}', 0},
+			wantBody:        "{}",
+			wantContentType: "application/json",
+		},
+		{
+			name:            "utf-8 byte order mark is stripped",
+			contentType:     "application/json; charset=utf-8",
+			body:            []byte("\xEF\xBB\xBF[1]"),
+			wantBody:        "[1]",
+			wantContentType: "application/json; charset=utf-8",
+		},
+		{
+			name:            "binary bodies are left alone",
+			contentType:     "application/octet-stream",
+			body:            []byte{0xFF, 0xFE, 0x00},
+			wantBody:        "\xFF\xFE\x00",
+			wantContentType: "application/octet-stream",
+		},
+		{
+			name:            "unknown charset",
+			contentType:     "text/plain; charset=x-unknown",
+			body:            []byte("Caf\xe9"),
+			wantBody:        "Caf\xe9",
+			wantContentType: "text/plain; charset=x-unknown",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				_, _ = w.Write(tt.body)
+			}))
+			defer server.Close()
+
+			serviceConfig := &config.EndpointConfig{BaseURL: server.URL}
+			r := requester.NewHTTPRequester(requester.HTTPRequesterParams{
+				ServiceConfig: serviceConfig,
+				AuthManager:   &MockAuthManager{},
+			})
+			executor, err := r.BuildRouteExecutor(&requester.RouteConfig{Path: "/text", Method: "GET"})
+			require.NoError(t, err)
+
+			resp, err := executor(context.Background(), map[string]interface{}{})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantBody, string(resp.Body))
+			assert.Equal(t, tt.wantContentType, resp.Headers.Get("Content-Type"))
+		})
+	}
+}
+
+func TestHTTPRequester_Cassette(t *testing.T) {
+	dir := t.TempDir()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=secret")
+		_, _ = fmt.Fprintf(w, `{"body": %q}`, body)
+	}))
+
+	newExecutor := func(mode, baseURL string) requester.RouteExecutor {
+		serviceConfig := &config.EndpointConfig{
+			BaseURL:  baseURL,
+			Cassette: config.CassetteConfig{Mode: mode, Dir: dir},
+		}
+		r := requester.NewHTTPRequester(requester.HTTPRequesterParams{
+			ServiceConfig: serviceConfig,
+			AuthManager:   &MockAuthManager{},
+		})
+		executor, err := r.BuildRouteExecutor(&requester.RouteConfig{Path: "/search", Method: "POST"})
+		require.NoError(t, err)
+		return executor
+	}
+	args := map[string]interface{}{"body": map[string]interface{}{"page": 1}}
+
+	recorded, err := newExecutor(config.CassetteModeRecord, server.URL)(context.Background(), args)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, recorded.StatusCode)
+	files, err := filepath.Glob(filepath.Join(dir, "POST-search-*.json"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	data, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "secret")
+
+	// Replaying needs no upstream
+	server.Close()
+	replay := newExecutor(config.CassetteModeReplay, server.URL)
+	replayed, err := replay(context.Background(), args)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, replayed.StatusCode)
+	assert.Equal(t, "application/json", replayed.Headers.Get("Content-Type"))
+	assert.JSONEq(t, string(recorded.Body), string(replayed.Body))
+
+	// Requests that were not recorded fail instead of reaching the network
+	_, err = replay(context.Background(), map[string]interface{}{"body": map[string]interface{}{"page": 2}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no recorded response for POST")
+}
+
+func TestHTTPRequester_Tenants(t *testing.T) {
+	record := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = fmt.Fprintf(w, `{"backend": %q, "auth": %q, "tenant": %q, "client": %q}`,
+				name, r.Header.Get("Authorization"), r.Header.Get("X-Tenant"), r.Header.Get("X-Client"))
+		}))
+	}
+	shared := record("shared")
+	defer shared.Close()
+	acme := record("acme")
+	defer acme.Close()
+
+	serviceConfig := &config.EndpointConfig{
+		BaseURL:    shared.URL,
+		AuthType:   config.AuthTypeBearer,
+		AuthConfig: map[string]string{"token": "shared-token"},
+		Headers:    map[string]string{"X-Client": "auto-mcp"},
+		Tenants: []config.TenantConfig{
+			{Name: "acme", BaseURL: acme.URL, AuthConfig: map[string]string{"token": "acme-token"}, Headers: map[string]string{"X-Tenant": "acme"}},
+			{Name: "globex", Headers: map[string]string{"X-Tenant": "globex"}},
+		},
+	}
+	r := requester.NewHTTPRequester(requester.HTTPRequesterParams{
+		ServiceConfig: serviceConfig,
+		AuthManager:   requester.NewHTTPAuthManager(serviceConfig),
+	})
+	executor, err := r.BuildRouteExecutor(&requester.RouteConfig{Path: "/orders", Method: "GET", CacheMaxAge: time.Minute})
+	require.NoError(t, err)
+
+	resp, err := executor(context.Background(), map[string]interface{}{})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"backend": "shared", "auth": "Bearer shared-token", "tenant": "", "client": "auto-mcp"}`, string(resp.Body))
+
+	resp, err = executor(requester.WithTenant(context.Background(), "acme"), map[string]interface{}{})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"backend": "acme", "auth": "Bearer acme-token", "tenant": "acme", "client": "auto-mcp"}`, string(resp.Body))
+
+	// Same base URL as the default endpoint, but not served from its cache entry
+	resp, err = executor(requester.WithTenant(context.Background(), "globex"), map[string]interface{}{})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"backend": "shared", "auth": "Bearer shared-token", "tenant": "globex", "client": "auto-mcp"}`, string(resp.Body))
+
+	_, err = executor(requester.WithTenant(context.Background(), "initech"), map[string]interface{}{})
+	assert.ErrorContains(t, err, "unknown tenant")
+}
+
+// tenantPlugin injects a tenant field into request bodies and scrubs SSNs
+// from responses
+type tenantPlugin struct {
+	tools []string
+}
+
+func (p *tenantPlugin) PreRequest(_ context.Context, req *hooks.Request) error {
+	p.tools = append(p.tools, req.Tool)
+	var body map[string]any
+	if err := json.Unmarshal(req.Body, &body); err != nil {
+		return err
+	}
+	body["tenant"] = "acme"
+	req.Body, _ = json.Marshal(body)
+	req.Header.Set("X-Plugin", "tenant")
+	return nil
+}
+
+func (p *tenantPlugin) PostResponse(_ context.Context, resp *hooks.Response) error {
+	resp.Body = []byte(strings.ReplaceAll(string(resp.Body), "123-45-6789", "***"))
+	return nil
+}
+
+func TestHTTPRequester_Hooks(t *testing.T) {
+	var received map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "tenant", r.Header.Get("X-Plugin"))
+		_ = json.NewDecoder(r.Body).Decode(&received)
+		_, _ = w.Write([]byte(`{"name":"Ann","ssn":"123-45-6789"}`))
+	}))
+	defer server.Close()
+
+	plugin := &tenantPlugin{}
+	h, err := plugins.New(plugins.Plugin{Name: "tenant", Impl: plugin})
+	require.NoError(t, err)
+	r := requester.NewHTTPRequester(requester.HTTPRequesterParams{
+		ServiceConfig: &config.EndpointConfig{BaseURL: server.URL},
+		AuthManager:   &MockAuthManager{},
+	})
+	r.SetHooks(h)
+	executor, err := r.BuildRouteExecutor(&requester.RouteConfig{Path: "/people", Method: "POST"})
+	require.NoError(t, err)
+
+	ctx := requester.WithToolName(context.Background(), "post_people")
+	resp, err := executor(ctx, map[string]interface{}{"body": map[string]any{"name": "Ann"}})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"name": "Ann", "tenant": "acme"}, received)
+	assert.JSONEq(t, `{"name":"Ann","ssn":"***"}`, string(resp.Body))
+	assert.Equal(t, []string{"post_people"}, plugin.tools)
+
+	_, err = plugins.New(plugins.Plugin{Name: "empty", Impl: struct{}{}})
+	assert.ErrorContains(t, err, "plugin empty: struct {} implements no hook")
+}
+
+func TestHTTPRequester_Policy(t *testing.T) {
+	var hits []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		hits = append(hits, string(body))
+		switch r.URL.Path {
+		case "/flaky":
+			if len(hits) < 3 {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok": true}`))
+	}))
+	defer server.Close()
+
+	r := requester.NewHTTPRequester(requester.HTTPRequesterParams{
+		ServiceConfig: &config.EndpointConfig{BaseURL: server.URL},
+		AuthManager:   &MockAuthManager{},
+	})
+	newExecutor := func(path, method string, policy requester.RoutePolicy) requester.RouteExecutor {
+		executor, err := r.BuildRouteExecutor(&requester.RouteConfig{Path: path, Method: method, Policy: policy})
+		require.NoError(t, err)
+		return executor
+	}
+
+	t.Run("retries resend the body", func(t *testing.T) {
+		hits = nil
+		executor := newExecutor("/flaky", "POST", requester.RoutePolicy{Retries: 2})
+		resp, err := executor(context.Background(), map[string]interface{}{"body": map[string]interface{}{"name": "rex"}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, []string{`{"name":"rex"}`, `{"name":"rex"}`, `{"name":"rex"}`}, hits)
+	})
+
+	t.Run("retries run out", func(t *testing.T) {
+		hits = nil
+		executor := newExecutor("/flaky", "GET", requester.RoutePolicy{Retries: 1})
+		resp, err := executor(context.Background(), map[string]interface{}{})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		assert.Len(t, hits, 2)
+	})
+
+	t.Run("client errors are not retried", func(t *testing.T) {
+		hits = nil
+		executor := newExecutor("/missing", "GET", requester.RoutePolicy{Retries: 3})
+		resp, err := executor(context.Background(), map[string]interface{}{})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		assert.Len(t, hits, 1)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		executor := newExecutor("/slow", "GET", requester.RoutePolicy{Timeout: 50 * time.Millisecond})
+		_, err := executor(context.Background(), map[string]interface{}{})
+		assert.ErrorContains(t, err, "Timeout")
+	})
+
+	t.Run("rate limit", func(t *testing.T) {
+		hits = nil
+		// One request every 100ms
+		executor := newExecutor("/fast", "GET", requester.RoutePolicy{RateLimit: 600})
+		start := time.Now()
+		for range 3 {
+			_, err := executor(context.Background(), map[string]interface{}{})
+			require.NoError(t, err)
+		}
+		assert.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+		assert.Len(t, hits, 3)
+	})
+}
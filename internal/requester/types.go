@@ -2,6 +2,7 @@ package requester
 
 import (
 	"net/http"
+	"time"
 )
 
 // RouteConfig holds the configuration for a specific route
@@ -9,22 +10,76 @@ type RouteConfig struct {
 	Path        string            `json:"path"`
 	Method      string            `json:"method"`
 	Description string            `json:"description,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
 	Headers     map[string]string `json:"headers"`
 	Parameters  map[string]string `json:"parameters"`
+	// Defaults holds argument values applied when the caller omits them
+	Defaults map[string]string `json:"defaults,omitempty"`
+	// Constants holds argument values that replace whatever the caller sends
+	Constants map[string]string `json:"constants,omitempty"`
+	// Renames maps real parameter names, dotted for body fields, to the argument names of the tool
+	Renames map[string]string `json:"renames,omitempty"`
+	// Hidden lists parameters removed from the tool, which are never forwarded
+	Hidden []string `json:"hidden,omitempty"`
+	// ArgumentDescriptions replace the descriptions of parameters, by real name
+	ArgumentDescriptions map[string]string `json:"argument_descriptions,omitempty"`
+	// OnBehalfOf sends the authenticated user's identity in the delegation header
+	OnBehalfOf bool `json:"on_behalf_of,omitempty"`
+	// SuccessCriteria is an expression a 2xx JSON body must satisfy to count as success
+	SuccessCriteria string `json:"success_criteria,omitempty"`
+	// UnwrapData and UnwrapMeta select the payload and metadata of envelope responses
+	UnwrapData string `json:"unwrap_data,omitempty"`
+	UnwrapMeta string `json:"unwrap_meta,omitempty"`
+	// HTMLResponses converts HTML responses to markdown or text, overriding endpoint.html_responses
+	HTMLResponses string `json:"html_responses,omitempty"`
+	// DateFormats maps dotted argument paths (e.g. "since", "body.dueDate") to "date" or "date-time"
+	DateFormats map[string]string `json:"date_formats,omitempty"`
+	// CacheMaxAge is how long a successful GET response may be served from the cache, 0 disables caching
+	CacheMaxAge time.Duration `json:"cache_max_age,omitempty"`
+	// Transform adds arguments and headers and reshapes the response with templates
+	Transform TransformConfig `json:"transform,omitempty"`
+	// Policy overrides the timeout, retries and rate limit of upstream requests
+	Policy RoutePolicy `json:"policy,omitempty"`
 	// Method specific configurations
 	MethodConfig MethodConfig `json:"method_config"`
 }
 
+// TransformConfig holds the Go templates of a route's transform
+type TransformConfig struct {
+	Arguments map[string]string `json:"arguments,omitempty"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	Response  string            `json:"response,omitempty"`
+}
+
+// RoutePolicy overrides how requests for a route are sent
+type RoutePolicy struct {
+	// Timeout bounds each attempt, 0 keeps the client timeout
+	Timeout time.Duration `json:"timeout,omitempty"`
+	// Retries is how often a failed attempt is repeated
+	Retries int `json:"retries,omitempty"`
+	// RateLimit caps the requests per minute, 0 is unlimited
+	RateLimit int `json:"rate_limit,omitempty"`
+	// MaxConcurrency caps the tool calls running at once, 0 uses server.concurrency.per_tool
+	MaxConcurrency int `json:"max_concurrency,omitempty"`
+}
+
 // MethodConfig holds method-specific configurations
 type MethodConfig struct {
 	// For GET requests
 	QueryParams []string `json:"query_params,omitempty"`
 
+	// HeaderParams are arguments sent as request headers
+	HeaderParams []string `json:"header_params,omitempty"`
+
 	// For multipart/form-data
 	FormFields []string `json:"form_fields,omitempty"`
 
 	// For file uploads
 	FileUpload *FileUploadConfig `json:"file_upload,omitempty"`
+
+	// BodyContentType overrides the default application/json request body type,
+	// e.g. application/json-patch+json or application/merge-patch+json
+	BodyContentType string `json:"body_content_type,omitempty"`
 }
 
 // FileUploadConfig holds configuration for file uploads
@@ -0,0 +1,229 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"reflect"
+	"time"
+
+	"github.com/brizzai/auto-mcp/internal/config"
+	"github.com/brizzai/auto-mcp/internal/logger"
+	"go.uber.org/zap"
+)
+
+// vaultScheme marks a value read from Vault as vault://<path>#<key>
+const vaultScheme = "vault://"
+
+const defaultRefreshInterval = 5 * time.Minute
+
+// Credentials are the resolved values of the configuration fields that may
+// reference a secret store
+type Credentials struct {
+	AuthConfig map[string]string
+	// TenantAuthConfig holds the tenants with their own auth_config, by name
+	TenantAuthConfig map[string]map[string]string
+	ClientSecret     string
+}
+
+// Resolver replaces vault:// references in the configuration with the
+// secrets they point at and keeps them up to date
+type Resolver struct {
+	vault    *Vault
+	interval time.Duration
+	// refs holds the configured values, still containing the references
+	refs    Credentials
+	current Credentials
+}
+
+// NewResolver resolves the vault:// references in cfg in place. It returns
+// nil when cfg has none.
+func NewResolver(ctx context.Context, cfg *config.Config) (*Resolver, error) {
+	refs := references(cfg)
+	if !hasReferences(refs) {
+		return nil, nil
+	}
+
+	vault, err := NewVault(cfg.Secrets.Vault)
+	if err != nil {
+		return nil, err
+	}
+	interval := cfg.Secrets.Vault.RefreshInterval
+	if interval <= 0 {
+		interval = defaultRefreshInterval
+	}
+
+	r := &Resolver{vault: vault, interval: interval, refs: refs}
+	creds, err := r.resolve(ctx, refs)
+	if err != nil {
+		return nil, err
+	}
+	r.current = creds
+	apply(cfg, creds)
+	return r, nil
+}
+
+// Resolve replaces the vault:// references in a reloaded configuration
+func (r *Resolver) Resolve(ctx context.Context, cfg *config.Config) error {
+	creds, err := r.resolve(ctx, references(cfg))
+	if err != nil {
+		return err
+	}
+	apply(cfg, creds)
+	return nil
+}
+
+// Run reads the secrets again every refresh interval, and before the Vault
+// token has to be renewed, calling update when they changed. It returns when
+// ctx is done.
+func (r *Resolver) Run(ctx context.Context, update func(Credentials)) {
+	for {
+		wait := r.interval
+		if renew := r.vault.RenewIn(); renew > 0 && renew < wait {
+			wait = renew
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		creds, changed, err := r.refresh(ctx)
+		if err != nil {
+			logger.Warn("Failed to refresh Vault secrets, keeping the current ones", zap.Error(err))
+			continue
+		}
+		if changed {
+			logger.Info("Vault secrets changed, updating credentials")
+			update(creds)
+		}
+	}
+}
+
+// refresh reads the secrets again and reports whether they changed
+func (r *Resolver) refresh(ctx context.Context) (Credentials, bool, error) {
+	creds, err := r.resolve(ctx, r.refs)
+	if err != nil {
+		return Credentials{}, false, err
+	}
+	if reflect.DeepEqual(creds, r.current) {
+		return creds, false, nil
+	}
+	r.current = creds
+	return creds, true, nil
+}
+
+// resolve reads every referenced secret once and substitutes its values
+func (r *Resolver) resolve(ctx context.Context, refs Credentials) (Credentials, error) {
+	secrets := make(map[string]map[string]string)
+	resolveValue := func(value, field string) (string, error) {
+		path, key, ok, err := parseReference(value)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", field, err)
+		}
+		if !ok {
+			return value, nil
+		}
+		secret, read := secrets[path]
+		if !read {
+			if secret, err = r.vault.Read(ctx, path); err != nil {
+				return "", fmt.Errorf("%s: %w", field, err)
+			}
+			secrets[path] = secret
+		}
+		resolved, found := secret[key]
+		if !found {
+			return "", fmt.Errorf("%s: vault secret %s has no key %q", field, path, key)
+		}
+		return resolved, nil
+	}
+	resolveMap := func(m map[string]string, field string) (map[string]string, error) {
+		if m == nil {
+			return nil, nil
+		}
+		resolved := make(map[string]string, len(m))
+		for k, v := range m {
+			value, err := resolveValue(v, field+"."+k)
+			if err != nil {
+				return nil, err
+			}
+			resolved[k] = value
+		}
+		return resolved, nil
+	}
+
+	var creds Credentials
+	var err error
+	if creds.AuthConfig, err = resolveMap(refs.AuthConfig, "endpoint.auth_config"); err != nil {
+		return Credentials{}, err
+	}
+	if len(refs.TenantAuthConfig) > 0 {
+		creds.TenantAuthConfig = make(map[string]map[string]string, len(refs.TenantAuthConfig))
+		for name, authConfig := range refs.TenantAuthConfig {
+			field := fmt.Sprintf("endpoint.tenants[%s].auth_config", name)
+			if creds.TenantAuthConfig[name], err = resolveMap(authConfig, field); err != nil {
+				return Credentials{}, err
+			}
+		}
+	}
+	if creds.ClientSecret, err = resolveValue(refs.ClientSecret, "oauth.client_secret"); err != nil {
+		return Credentials{}, err
+	}
+	return creds, nil
+}
+
+// references returns the configured values of the fields that may hold
+// vault:// references
+func references(cfg *config.Config) Credentials {
+	refs := Credentials{AuthConfig: maps.Clone(cfg.EndpointConfig.AuthConfig)}
+	for _, tenant := range cfg.EndpointConfig.Tenants {
+		if tenant.AuthConfig == nil {
+			continue
+		}
+		if refs.TenantAuthConfig == nil {
+			refs.TenantAuthConfig = make(map[string]map[string]string)
+		}
+		refs.TenantAuthConfig[tenant.Name] = maps.Clone(tenant.AuthConfig)
+	}
+	if cfg.OAuth != nil {
+		refs.ClientSecret = cfg.OAuth.ClientSecret
+	}
+	return refs
+}
+
+func hasReferences(refs Credentials) bool {
+	isRef := func(value string) bool {
+		_, _, ok, _ := parseReference(value)
+		return ok
+	}
+	for _, value := range refs.AuthConfig {
+		if isRef(value) {
+			return true
+		}
+	}
+	for _, authConfig := range refs.TenantAuthConfig {
+		for _, value := range authConfig {
+			if isRef(value) {
+				return true
+			}
+		}
+	}
+	return isRef(refs.ClientSecret)
+}
+
+// apply writes resolved credentials back into cfg
+func apply(cfg *config.Config, creds Credentials) {
+	cfg.EndpointConfig.AuthConfig = creds.AuthConfig
+	for i := range cfg.EndpointConfig.Tenants {
+		tenant := &cfg.EndpointConfig.Tenants[i]
+		if authConfig, ok := creds.TenantAuthConfig[tenant.Name]; ok {
+			tenant.AuthConfig = authConfig
+		}
+	}
+	if cfg.OAuth != nil {
+		cfg.OAuth.ClientSecret = creds.ClientSecret
+	}
+}
@@ -0,0 +1,314 @@
+// Package secrets resolves credentials kept in external secret stores.
+// Configuration values such as vault://mcp/petstore#token are read from
+// HashiCorp Vault at startup and refreshed periodically, so long-lived API
+// keys never have to be written to config files.
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brizzai/auto-mcp/internal/config"
+	"github.com/brizzai/auto-mcp/internal/logger"
+	"go.uber.org/zap"
+)
+
+// Vault auth methods
+const (
+	AuthMethodToken      = "token"
+	AuthMethodAppRole    = "approle"
+	AuthMethodKubernetes = "kubernetes"
+)
+
+const (
+	defaultVaultMount = "secret"
+	// defaultJWTFile is the service account token Kubernetes mounts into pods
+	defaultJWTFile   = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	vaultHTTPTimeout = 10 * time.Second
+)
+
+// Vault reads KV v2 secrets. It logs in with the configured auth method and
+// renews its token before the lease runs out, logging in again when the
+// token can no longer be renewed.
+type Vault struct {
+	cfg    config.VaultConfig
+	client *http.Client
+	now    func() time.Time
+
+	mu        sync.Mutex
+	token     string
+	renewable bool
+	// renewAt is when two thirds of the token lease have passed, zero for
+	// tokens that do not expire
+	renewAt time.Time
+	expires time.Time
+}
+
+// vaultAuth is the auth section of login and renewal responses
+type vaultAuth struct {
+	ClientToken   string `json:"client_token"`
+	LeaseDuration int    `json:"lease_duration"`
+	Renewable     bool   `json:"renewable"`
+}
+
+// NewVault creates a Vault client, filling unset settings from the standard
+// VAULT_ADDR, VAULT_NAMESPACE and VAULT_TOKEN variables
+func NewVault(cfg config.VaultConfig) (*Vault, error) {
+	if cfg.Address == "" {
+		cfg.Address = os.Getenv("VAULT_ADDR")
+	}
+	if cfg.Address == "" {
+		return nil, fmt.Errorf("secrets.vault.address or VAULT_ADDR is required to resolve vault:// references")
+	}
+	if cfg.Namespace == "" {
+		cfg.Namespace = os.Getenv("VAULT_NAMESPACE")
+	}
+	if cfg.Mount == "" {
+		cfg.Mount = defaultVaultMount
+	}
+	if cfg.AuthMethod == "" {
+		cfg.AuthMethod = AuthMethodToken
+	}
+
+	switch cfg.AuthMethod {
+	case AuthMethodToken:
+		if cfg.Token == "" {
+			cfg.Token = os.Getenv("VAULT_TOKEN")
+		}
+		if cfg.Token == "" {
+			return nil, fmt.Errorf("secrets.vault.token or VAULT_TOKEN is required for the token auth method")
+		}
+	case AuthMethodAppRole:
+		if cfg.RoleID == "" || cfg.SecretID == "" {
+			return nil, fmt.Errorf("secrets.vault.role_id and secret_id are required for the approle auth method")
+		}
+	case AuthMethodKubernetes:
+		if cfg.Role == "" {
+			return nil, fmt.Errorf("secrets.vault.role is required for the kubernetes auth method")
+		}
+		if cfg.JWTFile == "" {
+			cfg.JWTFile = defaultJWTFile
+		}
+	default:
+		return nil, fmt.Errorf("secrets.vault.auth_method: unknown method %q, expected %s, %s or %s",
+			cfg.AuthMethod, AuthMethodToken, AuthMethodAppRole, AuthMethodKubernetes)
+	}
+	if cfg.AuthMount == "" {
+		cfg.AuthMount = cfg.AuthMethod
+	}
+	cfg.Address = strings.TrimSuffix(cfg.Address, "/")
+
+	return &Vault{
+		cfg:    cfg,
+		client: &http.Client{Timeout: vaultHTTPTimeout},
+		now:    time.Now,
+	}, nil
+}
+
+// Read returns the latest version of the KV v2 secret at path
+func (v *Vault) Read(ctx context.Context, path string) (map[string]string, error) {
+	token, err := v.ensureToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Data struct {
+			Data map[string]any `json:"data"`
+		} `json:"data"`
+	}
+	secretPath := "/v1/" + strings.Trim(v.cfg.Mount, "/") + "/data/" + strings.Trim(path, "/")
+	if err := v.do(ctx, http.MethodGet, secretPath, token, nil, &resp); err != nil {
+		return nil, fmt.Errorf("failed to read vault secret %s: %w", path, err)
+	}
+
+	values := make(map[string]string, len(resp.Data.Data))
+	for key, value := range resp.Data.Data {
+		if s, ok := value.(string); ok {
+			values[key] = s
+			continue
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("vault secret %s: key %s: %w", path, key, err)
+		}
+		values[key] = string(encoded)
+	}
+	return values, nil
+}
+
+// RenewIn returns how long until the token should be renewed, or zero when
+// it does not expire
+func (v *Vault) RenewIn() time.Duration {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.renewAt.IsZero() {
+		return 0
+	}
+	return max(v.renewAt.Sub(v.now()), time.Second)
+}
+
+// ensureToken returns a valid token, logging in or renewing it first when needed
+func (v *Vault) ensureToken(ctx context.Context) (string, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	now := v.now()
+	if v.token != "" && (v.renewAt.IsZero() || now.Before(v.renewAt)) {
+		return v.token, nil
+	}
+
+	if v.token != "" && v.renewable && now.Before(v.expires) {
+		var resp struct {
+			Auth vaultAuth `json:"auth"`
+		}
+		err := v.do(ctx, http.MethodPost, "/v1/auth/token/renew-self", v.token, struct{}{}, &resp)
+		if err == nil {
+			v.setLease(resp.Auth.ClientToken, resp.Auth.LeaseDuration, resp.Auth.Renewable)
+			logger.Debug("Renewed Vault token", zap.Int("lease_seconds", resp.Auth.LeaseDuration))
+			return v.token, nil
+		}
+		logger.Warn("Failed to renew Vault token, logging in again", zap.Error(err))
+	}
+
+	if err := v.login(ctx); err != nil {
+		return "", err
+	}
+	return v.token, nil
+}
+
+// login obtains a new token. Static tokens cannot log in again, so their
+// lease is only looked up.
+func (v *Vault) login(ctx context.Context) error {
+	if v.cfg.AuthMethod == AuthMethodToken {
+		var resp struct {
+			Data struct {
+				TTL       int  `json:"ttl"`
+				Renewable bool `json:"renewable"`
+			} `json:"data"`
+		}
+		if err := v.do(ctx, http.MethodGet, "/v1/auth/token/lookup-self", v.cfg.Token, nil, &resp); err != nil {
+			return fmt.Errorf("failed to look up vault token: %w", err)
+		}
+		v.setLease(v.cfg.Token, resp.Data.TTL, resp.Data.Renewable)
+		return nil
+	}
+
+	body := map[string]string{}
+	switch v.cfg.AuthMethod {
+	case AuthMethodAppRole:
+		body["role_id"] = v.cfg.RoleID
+		body["secret_id"] = v.cfg.SecretID
+	case AuthMethodKubernetes:
+		jwt, err := os.ReadFile(v.cfg.JWTFile)
+		if err != nil {
+			return fmt.Errorf("failed to read kubernetes service account token: %w", err)
+		}
+		body["role"] = v.cfg.Role
+		body["jwt"] = strings.TrimSpace(string(jwt))
+	}
+
+	var resp struct {
+		Auth vaultAuth `json:"auth"`
+	}
+	loginPath := "/v1/auth/" + strings.Trim(v.cfg.AuthMount, "/") + "/login"
+	if err := v.do(ctx, http.MethodPost, loginPath, "", body, &resp); err != nil {
+		return fmt.Errorf("failed to log in to vault with %s: %w", v.cfg.AuthMethod, err)
+	}
+	if resp.Auth.ClientToken == "" {
+		return fmt.Errorf("failed to log in to vault with %s: no token returned", v.cfg.AuthMethod)
+	}
+	v.setLease(resp.Auth.ClientToken, resp.Auth.LeaseDuration, resp.Auth.Renewable)
+	logger.Info("Logged in to Vault",
+		zap.String("auth_method", v.cfg.AuthMethod),
+		zap.Int("lease_seconds", resp.Auth.LeaseDuration),
+	)
+	return nil
+}
+
+func (v *Vault) setLease(token string, leaseSeconds int, renewable bool) {
+	if token != "" {
+		v.token = token
+	}
+	v.renewable = renewable
+	if leaseSeconds <= 0 {
+		v.renewAt, v.expires = time.Time{}, time.Time{}
+		return
+	}
+	lease := time.Duration(leaseSeconds) * time.Second
+	now := v.now()
+	v.expires = now.Add(lease)
+	v.renewAt = now.Add(lease * 2 / 3)
+}
+
+// do sends a request to the Vault API and decodes the JSON response into out
+func (v *Vault) do(ctx context.Context, method, path, token string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, v.cfg.Address+path, reader)
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if v.cfg.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.cfg.Namespace)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode/100 != 2 {
+		var errResp struct {
+			Errors []string `json:"errors"`
+		}
+		if json.Unmarshal(data, &errResp) == nil && len(errResp.Errors) > 0 {
+			return fmt.Errorf("vault returned %d: %s", resp.StatusCode, strings.Join(errResp.Errors, "; "))
+		}
+		return fmt.Errorf("vault returned %d", resp.StatusCode)
+	}
+	return json.Unmarshal(data, out)
+}
+
+// parseReference splits vault://<path>#<key> into the secret path and key
+func parseReference(value string) (path, key string, ok bool, err error) {
+	rest, ok := strings.CutPrefix(value, vaultScheme)
+	if !ok {
+		return "", "", false, nil
+	}
+	path, key, found := strings.Cut(rest, "#")
+	if !found || path == "" || key == "" {
+		return "", "", true, fmt.Errorf("invalid vault reference %q, expected vault://<path>#<key>", value)
+	}
+	if unescaped, err := url.PathUnescape(path); err == nil {
+		path = unescaped
+	}
+	return path, key, true, nil
+}
@@ -0,0 +1,144 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/brizzai/auto-mcp/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeVault serves the approle login, token renewal and KV v2 read endpoints
+type fakeVault struct {
+	mu      sync.Mutex
+	secrets map[string]map[string]any
+	logins  int
+	renewed int
+}
+
+func (f *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	auth := map[string]any{"client_token": "s.token", "lease_duration": 30, "renewable": true}
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/auth/approle/login":
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["role_id"] != "role" || body["secret_id"] != "secret" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"errors":["invalid role or secret ID"]}`))
+			return
+		}
+		f.logins++
+		_ = json.NewEncoder(w).Encode(map[string]any{"auth": auth})
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/auth/token/renew-self":
+		f.renewed++
+		_ = json.NewEncoder(w).Encode(map[string]any{"auth": auth})
+	case r.Method == http.MethodGet && r.Header.Get("X-Vault-Token") == "s.token":
+		secret, ok := f.secrets[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors":[]}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"data": secret}})
+	default:
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+	}
+}
+
+func (f *fakeVault) set(path, key string, value any) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.secrets[path][key] = value
+}
+
+func TestResolver(t *testing.T) {
+	fake := &fakeVault{secrets: map[string]map[string]any{
+		"/v1/kv/data/mcp/petstore": {"token": "t1", "port": 8080},
+		"/v1/kv/data/mcp/oauth":    {"client_secret": "c1"},
+	}}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	cfg := &config.Config{
+		EndpointConfig: config.EndpointConfig{
+			AuthConfig: map[string]string{"token": "vault://mcp/petstore#token", "header": "X-Key"},
+			Tenants: []config.TenantConfig{
+				{Name: "acme", AuthConfig: map[string]string{"port": "vault://mcp/petstore#port"}},
+				{Name: "globex"},
+			},
+		},
+		OAuth: &config.OAuthConfig{ClientSecret: "vault://mcp/oauth#client_secret"},
+		Secrets: config.SecretsConfig{Vault: config.VaultConfig{
+			Address:    srv.URL,
+			Mount:      "kv",
+			AuthMethod: AuthMethodAppRole,
+			RoleID:     "role",
+			SecretID:   "secret",
+		}},
+	}
+
+	r, err := NewResolver(context.Background(), cfg)
+	require.NoError(t, err)
+	require.NotNil(t, r)
+	assert.Equal(t, map[string]string{"token": "t1", "header": "X-Key"}, cfg.EndpointConfig.AuthConfig)
+	assert.Equal(t, map[string]string{"port": "8080"}, cfg.EndpointConfig.Tenants[0].AuthConfig)
+	assert.Nil(t, cfg.EndpointConfig.Tenants[1].AuthConfig)
+	assert.Equal(t, "c1", cfg.OAuth.ClientSecret)
+	assert.Equal(t, 1, fake.logins)
+
+	_, changed, err := r.refresh(context.Background())
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	// Rotated secrets are picked up, and the token is renewed once two
+	// thirds of its lease have passed
+	fake.set("/v1/kv/data/mcp/petstore", "token", "t2")
+	r.vault.now = func() time.Time { return time.Now().Add(25 * time.Second) }
+	creds, changed, err := r.refresh(context.Background())
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "t2", creds.AuthConfig["token"])
+	assert.Equal(t, "c1", creds.ClientSecret)
+	assert.Equal(t, 1, fake.renewed)
+	assert.Equal(t, 1, fake.logins)
+
+	// Once the lease has run out the client logs in again
+	r.vault.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	_, _, err = r.refresh(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, fake.logins)
+
+	fake.mu.Lock()
+	delete(fake.secrets["/v1/kv/data/mcp/oauth"], "client_secret")
+	fake.mu.Unlock()
+	_, _, err = r.refresh(context.Background())
+	assert.ErrorContains(t, err, `oauth.client_secret: vault secret mcp/oauth has no key "client_secret"`)
+}
+
+func TestNewResolver(t *testing.T) {
+	cfg := &config.Config{EndpointConfig: config.EndpointConfig{AuthConfig: map[string]string{"token": "plain"}}}
+	r, err := NewResolver(context.Background(), cfg)
+	require.NoError(t, err)
+	assert.Nil(t, r)
+
+	t.Setenv("VAULT_ADDR", "")
+	cfg.EndpointConfig.AuthConfig["token"] = "vault://mcp/petstore#token"
+	_, err = NewResolver(context.Background(), cfg)
+	assert.ErrorContains(t, err, "VAULT_ADDR is required")
+
+	cfg.Secrets.Vault.Address = "http://127.0.0.1:8200"
+	cfg.EndpointConfig.AuthConfig["token"] = "vault://mcp/petstore"
+	cfg.Secrets.Vault.Token = "s.token"
+	_, err = NewResolver(context.Background(), cfg)
+	assert.ErrorContains(t, err, "expected vault://<path>#<key>")
+}
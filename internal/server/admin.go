@@ -0,0 +1,65 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/brizzai/auto-mcp/internal/logger"
+	"github.com/mark3labs/mcp-go/mcp"
+	"go.uber.org/zap"
+)
+
+// adminHandler serves the admin API. The HTTP handler checks the admin
+// token before requests get here.
+//
+//	GET /admin/analytics  per-tool call counts, error rates and latencies
+func (s *Server) adminHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /admin/analytics", s.handleAnalytics)
+	return mux
+}
+
+// handleAnalytics returns the usage report, including the registered tools
+// that were never called
+func (s *Server) handleAnalytics(w http.ResponseWriter, _ *http.Request) {
+	if s.analytics == nil {
+		writeAdminJSON(w, http.StatusNotFound, map[string]string{"error": "analytics are disabled, set server.analytics.enabled"})
+		return
+	}
+	writeAdminJSON(w, http.StatusOK, s.analytics.Report(s.usageNames()))
+}
+
+// writeAdminJSON writes v as the JSON response of an admin request
+func writeAdminJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.Warn("Failed to write admin response", zap.Error(err))
+	}
+}
+
+// usageName is the tool a call is recorded under. Lazy mode calls go through
+// call_operation and are recorded under the operation they call.
+func (s *Server) usageName(request mcp.CallToolRequest) string {
+	name := request.Params.Name
+	if s.config.Server.LazyTools && name == s.toolName(CallOperationTool) {
+		if operation := request.GetString("name", ""); s.operations()[operation] != nil {
+			return operation
+		}
+	}
+	return name
+}
+
+// usageNames returns the names calls can be recorded under: the registered
+// tools and, in lazy mode, the operations of the catalog
+func (s *Server) usageNames() []string {
+	tools := s.Tools()
+	names := make([]string, 0, len(tools))
+	for _, tool := range tools {
+		names = append(names, tool.Name)
+	}
+	for name := range s.operations() {
+		names = append(names, name)
+	}
+	return names
+}
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+// preciseArguments decodes tools/call arguments with UseNumber before mcp-go
+// unmarshals them to float64, so large integers and long decimals reach the
+// upstream API unchanged. mcp-go passes the same context to the request hook
+// and the call tool hooks of a message, which pairs the two up.
+type preciseArguments struct {
+	pending sync.Map // context.Context -> map[string]any
+}
+
+// register adds the hooks that replace the arguments of every tool call
+func (p *preciseArguments) register(hooks *mcpserver.Hooks) {
+	hooks.AddOnRequestInitialization(p.decode)
+	hooks.AddBeforeCallTool(p.apply)
+	hooks.AddOnError(p.discard)
+}
+
+// decode keeps the arguments of a tools/call message. Malformed messages are
+// left to mcp-go to reject.
+func (p *preciseArguments) decode(ctx context.Context, _ any, message any) error {
+	raw, ok := message.(json.RawMessage)
+	if !ok {
+		return nil
+	}
+	var request struct {
+		Method mcp.MCPMethod `json:"method"`
+		Params struct {
+			Arguments json.RawMessage `json:"arguments"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal(raw, &request); err != nil || request.Method != mcp.MethodToolsCall {
+		return nil
+	}
+	if len(request.Params.Arguments) == 0 {
+		return nil
+	}
+
+	var arguments map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(request.Params.Arguments))
+	decoder.UseNumber()
+	if err := decoder.Decode(&arguments); err != nil || arguments == nil {
+		return nil
+	}
+	p.pending.Store(ctx, arguments)
+	return nil
+}
+
+// apply replaces the arguments mcp-go decoded with the precise ones
+func (p *preciseArguments) apply(ctx context.Context, _ any, request *mcp.CallToolRequest) {
+	if arguments, ok := p.pending.LoadAndDelete(ctx); ok {
+		request.Params.Arguments = arguments
+	}
+}
+
+// discard drops the arguments of a tools/call message mcp-go failed to unmarshal
+func (p *preciseArguments) discard(ctx context.Context, _ any, method mcp.MCPMethod, _ any, _ error) {
+	if method == mcp.MethodToolsCall {
+		p.pending.Delete(ctx)
+	}
+}
+
+// intArgument returns an integer argument. Arguments decoded by
+// preciseArguments hold json.Number, which mcp-go's GetInt does not read.
+func intArgument(request mcp.CallToolRequest, key string, defaultValue int) int {
+	number, ok := request.GetArguments()[key].(json.Number)
+	if !ok {
+		return request.GetInt(key, defaultValue)
+	}
+	if value, err := number.Int64(); err == nil {
+		return int(value)
+	}
+	if value, err := number.Float64(); err == nil {
+		return int(value)
+	}
+	return defaultValue
+}
@@ -0,0 +1,142 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/brizzai/auto-mcp/internal/logger"
+	"github.com/brizzai/auto-mcp/internal/requester"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+	"go.uber.org/zap"
+)
+
+// baseURLCapability is the experimental client capability carrying a base
+// URL override at initialization: {"auto-mcp": {"base_url": "https://..."}}
+const baseURLCapability = "auto-mcp"
+
+// Streamable HTTP sessions are never unregistered, so overrides of sessions
+// idle for sessionBaseURLIdle are dropped every sessionBaseURLSweep
+const (
+	sessionBaseURLIdle  = 24 * time.Hour
+	sessionBaseURLSweep = time.Hour
+)
+
+// sessionBaseURL is the base URL override of a session
+type sessionBaseURL struct {
+	baseURL  string
+	lastUsed time.Time
+}
+
+// sessionBaseURLs remembers the base URL override of each MCP session, so
+// clients only need to send it once
+type sessionBaseURLs struct {
+	mu        sync.Mutex
+	sessions  map[string]*sessionBaseURL
+	lastSweep time.Time
+}
+
+// set remembers baseURL for the session
+func (s *sessionBaseURLs) set(sessionID, baseURL string, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sessions == nil {
+		s.sessions = make(map[string]*sessionBaseURL)
+	}
+	if now.Sub(s.lastSweep) > sessionBaseURLSweep {
+		for id, entry := range s.sessions {
+			if now.Sub(entry.lastUsed) > sessionBaseURLIdle {
+				delete(s.sessions, id)
+			}
+		}
+		s.lastSweep = now
+	}
+	s.sessions[sessionID] = &sessionBaseURL{baseURL: baseURL, lastUsed: now}
+}
+
+// get returns the override of the session, if any
+func (s *sessionBaseURLs) get(sessionID string, now time.Time) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.sessions[sessionID]
+	if !ok {
+		return "", false
+	}
+	entry.lastUsed = now
+	return entry.baseURL, true
+}
+
+// remove forgets the override of the session
+func (s *sessionBaseURLs) remove(sessionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, sessionID)
+}
+
+// setupBaseURLOverride lets sessions target another upstream base URL when
+// endpoint.base_url_override is configured
+func (s *Server) setupBaseURLOverride(hooks *mcpserver.Hooks) []mcpserver.ServerOption {
+	if !s.config.EndpointConfig.BaseURLOverride.Enabled() {
+		return nil
+	}
+	hooks.AddAfterInitialize(s.rememberInitBaseURL)
+	hooks.AddOnUnregisterSession(func(_ context.Context, session mcpserver.ClientSession) {
+		s.baseURLs.remove(session.SessionID())
+	})
+	logger.Info("Enabled base URL overrides",
+		zap.String("header", s.config.EndpointConfig.BaseURLOverride.HeaderName()),
+		zap.Strings("allowed", s.config.EndpointConfig.BaseURLOverride.Allowed),
+	)
+	return []mcpserver.ServerOption{mcpserver.WithToolHandlerMiddleware(s.applyBaseURL)}
+}
+
+// baseURLContext reads the override header of an SSE or streamable HTTP
+// message into the context of its tool calls and remembers it for the session
+func (s *Server) baseURLContext(ctx context.Context, r *http.Request) context.Context {
+	override := s.config.EndpointConfig.BaseURLOverride
+	if !override.Enabled() {
+		return ctx
+	}
+	baseURL := r.Header.Get(override.HeaderName())
+	if baseURL == "" {
+		return ctx
+	}
+	if session := mcpserver.ClientSessionFromContext(ctx); session != nil && session.SessionID() != "" {
+		s.baseURLs.set(session.SessionID(), baseURL, time.Now())
+	}
+	return requester.WithBaseURL(ctx, baseURL)
+}
+
+// rememberInitBaseURL remembers the base URL a client sent in its
+// experimental capabilities at initialization
+func (s *Server) rememberInitBaseURL(ctx context.Context, _ any, request *mcp.InitializeRequest, _ *mcp.InitializeResult) {
+	options, _ := request.Params.Capabilities.Experimental[baseURLCapability].(map[string]any)
+	baseURL, _ := options["base_url"].(string)
+	session := mcpserver.ClientSessionFromContext(ctx)
+	if baseURL == "" || session == nil {
+		return
+	}
+	if !s.config.EndpointConfig.BaseURLOverride.Allows(baseURL) {
+		// Kept anyway so the session's tool calls fail instead of silently
+		// reaching the default upstream
+		logger.Warn("Session requested a base URL that is not allowed", zap.String("base_url", baseURL))
+	}
+	s.baseURLs.set(session.SessionID(), baseURL, time.Now())
+}
+
+// applyBaseURL sends tool calls to the override of their session, unless
+// the request carrying them set one
+func (s *Server) applyBaseURL(next mcpserver.ToolHandlerFunc) mcpserver.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if _, ok := requester.BaseURLFromContext(ctx); !ok {
+			if session := mcpserver.ClientSessionFromContext(ctx); session != nil {
+				if baseURL, ok := s.baseURLs.get(session.SessionID(), time.Now()); ok {
+					ctx = requester.WithBaseURL(ctx, baseURL)
+				}
+			}
+		}
+		return next(ctx, request)
+	}
+}
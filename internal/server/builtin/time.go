@@ -0,0 +1,140 @@
+// Package builtin provides generic helper tools that are not derived from the
+// OpenAPI specification, such as time and UUID utilities.
+package builtin
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+// Helper tool names
+const (
+	CurrentTimeTool  = "current_time"
+	ConvertEpochTool = "convert_epoch"
+	GenerateUUIDTool = "generate_uuid"
+)
+
+// Tools returns the helper tools with their handlers
+func Tools() []mcpserver.ServerTool {
+	return []mcpserver.ServerTool{
+		{
+			Tool: mcp.NewTool(CurrentTimeTool,
+				mcp.WithDescription("Returns the current date and time, optionally in a given IANA timezone. Use it instead of guessing timestamps."),
+				mcp.WithString("timezone",
+					mcp.Description("IANA timezone name, e.g. Europe/Berlin. Defaults to UTC."),
+				),
+			),
+			Handler: handleCurrentTime,
+		},
+		{
+			Tool: mcp.NewTool(ConvertEpochTool,
+				mcp.WithDescription("Converts a Unix epoch (seconds or milliseconds) to RFC 3339, or an RFC 3339 timestamp to Unix epoch seconds."),
+				mcp.WithString("value",
+					mcp.Required(),
+					mcp.Description("Unix epoch in seconds/milliseconds, or an RFC 3339 timestamp"),
+				),
+				mcp.WithString("timezone",
+					mcp.Description("IANA timezone for the RFC 3339 output. Defaults to UTC."),
+				),
+			),
+			Handler: handleConvertEpoch,
+		},
+		{
+			Tool: mcp.NewTool(GenerateUUIDTool,
+				mcp.WithDescription("Generates a random (version 4) UUID."),
+			),
+			Handler: handleGenerateUUID,
+		},
+	}
+}
+
+func handleCurrentTime(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	loc, err := loadLocation(request.GetString("timezone", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	now := time.Now().In(loc)
+	return jsonResult(map[string]interface{}{
+		"rfc3339":  now.Format(time.RFC3339),
+		"epoch":    now.Unix(),
+		"timezone": loc.String(),
+		"weekday":  now.Weekday().String(),
+	})
+}
+
+func handleConvertEpoch(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	value, err := request.RequireString("value")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	loc, err := loadLocation(request.GetString("timezone", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var t time.Time
+	if epoch, parseErr := strconv.ParseInt(value, 10, 64); parseErr == nil {
+		// Values beyond year 5138 in seconds are treated as milliseconds
+		if epoch > 1e11 || epoch < -1e11 {
+			t = time.UnixMilli(epoch)
+		} else {
+			t = time.Unix(epoch, 0)
+		}
+	} else if t, err = time.Parse(time.RFC3339, value); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("value %q is neither a Unix epoch nor an RFC 3339 timestamp", value)), nil
+	}
+
+	t = t.In(loc)
+	return jsonResult(map[string]interface{}{
+		"rfc3339":      t.Format(time.RFC3339),
+		"epoch":        t.Unix(),
+		"epoch_millis": t.UnixMilli(),
+		"timezone":     loc.String(),
+	})
+}
+
+func handleGenerateUUID(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	id, err := newUUID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate UUID: %w", err)
+	}
+	return mcp.NewToolResultText(id), nil
+}
+
+// newUUID returns a random RFC 4122 version 4 UUID
+func newUUID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
+func loadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %q", name)
+	}
+	return loc, nil
+}
+
+func jsonResult(data interface{}) (*mcp.CallToolResult, error) {
+	out, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode result: %w", err)
+	}
+	return mcp.NewToolResultText(string(out)), nil
+}
@@ -0,0 +1,77 @@
+package builtin
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func callTool(t *testing.T, handler func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error), args map[string]interface{}) *mcp.CallToolResult {
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = args
+	result, err := handler(context.Background(), request)
+	require.NoError(t, err)
+	return result
+}
+
+func TestConvertEpoch(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    map[string]interface{}
+		want    map[string]interface{}
+		isError bool
+	}{
+		{
+			name: "epoch seconds",
+			args: map[string]interface{}{"value": "1700000000"},
+			want: map[string]interface{}{"rfc3339": "2023-11-14T22:13:20Z", "epoch": float64(1700000000)},
+		},
+		{
+			name: "epoch milliseconds",
+			args: map[string]interface{}{"value": "1700000000000"},
+			want: map[string]interface{}{"rfc3339": "2023-11-14T22:13:20Z", "epoch": float64(1700000000)},
+		},
+		{
+			name: "rfc3339 with timezone",
+			args: map[string]interface{}{"value": "2023-11-14T22:13:20Z", "timezone": "Asia/Tokyo"},
+			want: map[string]interface{}{"rfc3339": "2023-11-15T07:13:20+09:00", "epoch": float64(1700000000)},
+		},
+		{
+			name:    "invalid value",
+			args:    map[string]interface{}{"value": "yesterday"},
+			isError: true,
+		},
+		{
+			name:    "invalid timezone",
+			args:    map[string]interface{}{"value": "1700000000", "timezone": "Mars/Olympus"},
+			isError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := callTool(t, handleConvertEpoch, tt.args)
+			assert.Equal(t, tt.isError, result.IsError)
+			if tt.isError {
+				return
+			}
+
+			var got map[string]interface{}
+			require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &got))
+			for key, value := range tt.want {
+				assert.Equal(t, value, got[key], key)
+			}
+		})
+	}
+}
+
+func TestGenerateUUID(t *testing.T) {
+	result := callTool(t, handleGenerateUUID, nil)
+	id := result.Content[0].(mcp.TextContent).Text
+	assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), id)
+}
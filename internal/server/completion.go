@@ -0,0 +1,346 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brizzai/auto-mcp/internal/auth/middleware"
+	"github.com/brizzai/auto-mcp/internal/config"
+	"github.com/brizzai/auto-mcp/internal/jsonpath"
+	"github.com/brizzai/auto-mcp/internal/logger"
+	"github.com/brizzai/auto-mcp/internal/models"
+	"github.com/brizzai/auto-mcp/internal/parser"
+	"github.com/brizzai/auto-mcp/internal/requester"
+	"github.com/mark3labs/mcp-go/mcp"
+	"go.uber.org/zap"
+)
+
+// completeMethod is the MCP request for argument completions. mcp-go does not
+// route it, so the transports answer it before passing messages on.
+const completeMethod = "completion/complete"
+
+// maxCompletionValues is the most values a completion may return
+const maxCompletionValues = 100
+
+// defaultCompletionCacheTTL is how long listed values are reused by default
+const defaultCompletionCacheTTL = 5 * time.Minute
+
+// Completion reference types. Tools are not references in MCP; completing
+// their arguments is an auto-mcp extension.
+const (
+	promptRef   = "ref/prompt"
+	resourceRef = "ref/resource"
+	toolRef     = "ref/tool"
+)
+
+// errUnknownRef rejects completions for references the server doesn't have
+var errUnknownRef = errors.New("unknown completion reference")
+
+// completionSource suggests the values of one argument
+type completionSource struct {
+	cfg   config.CompletionConfig
+	items *jsonpath.Path // nil selects the whole list response
+	value *jsonpath.Path // nil uses each item itself
+	// list fetches ListPath, nil without one
+	list requester.RouteExecutor
+}
+
+// listedValues are the values a list endpoint returned
+type listedValues struct {
+	values  []string
+	expires time.Time
+}
+
+// completer suggests argument values from tool enums and endpoint.completions
+type completer struct {
+	sources map[string]*completionSource
+	now     func() time.Time
+
+	mu          sync.Mutex
+	headerNames []string
+	listed      map[string]listedValues // by argument and user
+}
+
+// newCompleter compiles the configured completions, building an executor for
+// each list endpoint
+func newCompleter(completions []config.CompletionConfig, r *requester.HTTPRequester) (*completer, error) {
+	c := &completer{
+		sources: make(map[string]*completionSource, len(completions)),
+		now:     time.Now,
+		listed:  make(map[string]listedValues),
+	}
+	for i, cfg := range completions {
+		source := &completionSource{cfg: cfg}
+		var err error
+		if cfg.ItemsPath != "" {
+			if source.items, err = jsonpath.Compile(cfg.ItemsPath); err != nil {
+				return nil, fmt.Errorf("endpoint.completions[%d].items_path: %w", i, err)
+			}
+		}
+		if cfg.ValuePath != "" {
+			if source.value, err = jsonpath.Compile(cfg.ValuePath); err != nil {
+				return nil, fmt.Errorf("endpoint.completions[%d].value_path: %w", i, err)
+			}
+		}
+		if cfg.ListPath != "" {
+			route := &requester.RouteConfig{Method: http.MethodGet, Path: cfg.ListPath}
+			if source.list, err = r.BuildRouteExecutor(route); err != nil {
+				return nil, fmt.Errorf("endpoint.completions[%d].list_path: %w", i, err)
+			}
+		}
+		c.sources[cfg.Argument] = source
+	}
+	return c, nil
+}
+
+// setRoutes records the header names of the API: header parameters, headers
+// set by the adjustments file and endpoint.headers
+func (c *completer) setRoutes(routes []*parser.RouteTool, endpointHeaders map[string]string) {
+	seen := make(map[string]bool)
+	var names []string
+	add := func(name string) {
+		key := http.CanonicalHeaderKey(name)
+		if name != "" && !seen[key] {
+			seen[key] = true
+			names = append(names, name)
+		}
+	}
+	for name := range endpointHeaders {
+		add(name)
+	}
+	for _, route := range routes {
+		for name := range route.RouteConfig.Headers {
+			add(name)
+		}
+		for _, name := range route.RouteConfig.MethodConfig.HeaderParams {
+			add(name)
+		}
+	}
+	sort.Strings(names)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.headerNames = names
+}
+
+// values returns the configured values of argument, listing them with ctx
+// when the completion has a list endpoint. user keeps listed values apart
+// per caller, as tenants and forwarded tokens may see different ones.
+func (c *completer) values(ctx context.Context, argument, user string) []string {
+	source, ok := c.sources[argument]
+	if !ok {
+		return nil
+	}
+	values := slices.Clone(source.cfg.Values)
+	if source.cfg.HeaderNames {
+		c.mu.Lock()
+		values = append(values, c.headerNames...)
+		c.mu.Unlock()
+	}
+	if source.list != nil {
+		values = append(values, c.list(ctx, source, user)...)
+	}
+	return values
+}
+
+// list returns the values of the source's list endpoint, cached for its TTL.
+// Failures are logged and complete nothing.
+func (c *completer) list(ctx context.Context, source *completionSource, user string) []string {
+	key := source.cfg.Argument + "\x00" + user
+	now := c.now()
+	c.mu.Lock()
+	cached, ok := c.listed[key]
+	c.mu.Unlock()
+	if ok && now.Before(cached.expires) {
+		return cached.values
+	}
+
+	resp, err := source.list(ctx, nil)
+	if err == nil && (resp.StatusCode < 200 || resp.StatusCode >= 300) {
+		err = fmt.Errorf("unexpected HTTP %d", resp.StatusCode)
+	}
+	var values []string
+	if err == nil {
+		values, err = listValues(resp.Body, source.items, source.value)
+	}
+	if err != nil {
+		logger.Warn("Failed to list completion values",
+			zap.String("argument", source.cfg.Argument),
+			zap.String("path", source.cfg.ListPath),
+			zap.Error(err),
+		)
+		return nil
+	}
+
+	ttl := source.cfg.CacheTTL
+	if ttl == 0 {
+		ttl = defaultCompletionCacheTTL
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k, entry := range c.listed {
+		if !now.Before(entry.expires) {
+			delete(c.listed, k)
+		}
+	}
+	c.listed[key] = listedValues{values: values, expires: now.Add(ttl)}
+	return values
+}
+
+// listValues returns the values of the items of a list response
+func listValues(body []byte, itemsPath, valuePath *jsonpath.Path) ([]string, error) {
+	doc, err := jsonpath.Decode(body)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON response: %w", err)
+	}
+	if itemsPath != nil {
+		var ok bool
+		if doc, ok = itemsPath.Lookup(doc); !ok {
+			return nil, fmt.Errorf("%s not found in the response", itemsPath)
+		}
+	}
+	items, ok := doc.([]interface{})
+	if !ok {
+		return nil, errors.New("the response is not an array of items")
+	}
+
+	values := make([]string, 0, len(items))
+	for _, item := range items {
+		if valuePath != nil {
+			if item, ok = valuePath.Lookup(item); !ok {
+				continue
+			}
+		}
+		switch item.(type) {
+		case map[string]interface{}, []interface{}, nil:
+			continue
+		}
+		values = append(values, fmt.Sprint(item))
+	}
+	return values, nil
+}
+
+// complete suggests values for an argument of a prompt, or of a tool. Prompt
+// arguments complete like the same-named arguments of the prompt's tools.
+func (s *Server) complete(ctx context.Context, params mcp.CompleteParams) (*mcp.CompleteResult, error) {
+	ref, _ := params.Ref.(map[string]any)
+	refType, _ := ref["type"].(string)
+	name, _ := ref["name"].(string)
+	argument := params.Argument.Name
+
+	var tools []string
+	switch refType {
+	case promptRef:
+		s.toolsMu.Lock()
+		def, ok := s.prompts[name]
+		s.toolsMu.Unlock()
+		if !ok {
+			return nil, fmt.Errorf("%w: prompt %q", errUnknownRef, name)
+		}
+		if !slices.ContainsFunc(def.Arguments, func(arg models.PromptArgument) bool { return arg.Name == argument }) {
+			return nil, fmt.Errorf("prompt %q has no argument %q", name, argument)
+		}
+		tools = def.Tools
+	case toolRef:
+		if _, ok := s.completionTool(ctx, name); !ok {
+			return nil, fmt.Errorf("%w: tool %q", errUnknownRef, name)
+		}
+		tools = []string{name}
+	case resourceRef:
+		// Resources have no arguments to complete
+		return completionResult(nil, params.Argument.Value), nil
+	default:
+		return nil, fmt.Errorf("%w: type %q", errUnknownRef, refType)
+	}
+
+	ctx, user := s.completionContext(ctx)
+	values := s.completions.values(ctx, argument, user)
+	for _, toolName := range tools {
+		if tool, ok := s.completionTool(ctx, toolName); ok {
+			values = append(values, enumValues(tool, argument)...)
+		}
+	}
+	return completionResult(values, params.Argument.Value), nil
+}
+
+// completionTool returns a tool the user in ctx may call, including lazy
+// mode operations that are not enabled yet
+func (s *Server) completionTool(ctx context.Context, name string) (mcp.Tool, bool) {
+	s.toolsMu.Lock()
+	tool, registered := s.tools[name]
+	registered = registered && !s.disabled[name]
+	method := s.methods[name]
+	route := s.catalog[name]
+	s.toolsMu.Unlock()
+
+	switch {
+	case registered:
+		return tool.Tool, s.toolVisible(ctx, name, method)
+	case route != nil:
+		return route.Tool, s.toolVisible(ctx, name, route.RouteConfig.Method)
+	}
+	return mcp.Tool{}, false
+}
+
+// completionContext prepares ctx for list endpoint requests like tool calls
+// are, returning the user to cache the listed values for
+func (s *Server) completionContext(ctx context.Context) (context.Context, string) {
+	authInfo, ok := ctx.Value(middleware.AuthContextKey).(*middleware.AuthInfo)
+	if !ok || authInfo == nil {
+		return ctx, ""
+	}
+	ctx = requester.WithUpstreamToken(ctx, authInfo.Token)
+	if tenant, ok := s.tenants.Resolve(authInfo); ok {
+		ctx = requester.WithTenant(ctx, tenant)
+	}
+	return ctx, authInfo.UserID
+}
+
+// enumValues returns the enum values of a tool argument, or of its items
+// when it is an array
+func enumValues(tool mcp.Tool, argument string) []string {
+	schema, _ := tool.InputSchema.Properties[argument].(map[string]any)
+	if items, ok := schema["items"].(map[string]any); ok && schema["enum"] == nil {
+		schema = items
+	}
+	var values []string
+	switch enum := schema["enum"].(type) {
+	case []string:
+		values = append(values, enum...)
+	case []interface{}:
+		for _, value := range enum {
+			values = append(values, fmt.Sprint(value))
+		}
+	}
+	return values
+}
+
+// completionResult returns the values starting with prefix, ignoring case,
+// without duplicates and at most maxCompletionValues of them
+func completionResult(values []string, prefix string) *mcp.CompleteResult {
+	prefix = strings.ToLower(prefix)
+	seen := make(map[string]bool, len(values))
+	matches := make([]string, 0, len(values))
+	for _, value := range values {
+		if !seen[value] && strings.HasPrefix(strings.ToLower(value), prefix) {
+			seen[value] = true
+			matches = append(matches, value)
+		}
+	}
+
+	result := &mcp.CompleteResult{}
+	result.Completion.Values = matches
+	if len(matches) > maxCompletionValues {
+		result.Completion.Values = matches[:maxCompletionValues]
+		result.Completion.Total = len(matches)
+		result.Completion.HasMore = true
+	}
+	return result
+}
@@ -0,0 +1,227 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brizzai/auto-mcp/internal/config"
+	"github.com/brizzai/auto-mcp/internal/models"
+	"github.com/brizzai/auto-mcp/internal/parser"
+	"github.com/brizzai/auto-mcp/internal/requester"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCompletionServer serves pets from a list endpoint counting its calls
+func newCompletionServer(t *testing.T, listCalls *int) *Server {
+	t.Helper()
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*listCalls++
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"data": [{"id": 7, "name": "Rex"}, {"id": 42}, {"name": "no id"}]}`)
+	}))
+	t.Cleanup(backend.Close)
+
+	srvCfg := &config.Config{
+		EndpointConfig: config.EndpointConfig{
+			BaseURL:  backend.URL,
+			AuthType: config.AuthTypeNone,
+			Headers:  map[string]string{"X-Client": "auto-mcp"},
+			Completions: []config.CompletionConfig{
+				{Argument: "pet_id", ListPath: "/pets", ItemsPath: "$.data", ValuePath: "id", CacheTTL: time.Minute},
+				{Argument: "region", Values: []string{"eu-west", "us-east"}},
+				{Argument: "header", HeaderNames: true},
+			},
+		},
+		Server: config.ServerConfig{Mode: config.ServerModeSTDIO},
+	}
+	httpRequester := requester.NewHTTPRequester(requester.HTTPRequesterParams{
+		ServiceConfig: &srvCfg.EndpointConfig,
+		AuthManager:   requester.NewHTTPAuthManager(&srvCfg.EndpointConfig),
+	})
+	route := &parser.RouteTool{
+		RouteConfig: &requester.RouteConfig{
+			Method:       "GET",
+			Path:         "/pets/{pet_id}",
+			MethodConfig: requester.MethodConfig{HeaderParams: []string{"X-Trace-Id"}},
+		},
+		Tool: mcp.NewTool("get_pet",
+			mcp.WithString("pet_id"),
+			mcp.WithString("status", mcp.Enum("available", "pending", "sold")),
+			mcp.WithString("region"),
+		),
+	}
+	return NewServer(srvCfg, &mockParser{
+		tools: []*parser.RouteTool{route},
+		prompts: []models.Prompt{{
+			Name:      "adopt",
+			Arguments: []models.PromptArgument{{Name: "status"}, {Name: "pet_id"}},
+			Tools:     []string{"get_pet"},
+		}},
+	}, httpRequester)
+}
+
+func completeParams(refType, name, argument, value string) mcp.CompleteParams {
+	params := mcp.CompleteParams{Ref: map[string]any{"type": refType, "name": name}}
+	params.Argument.Name = argument
+	params.Argument.Value = value
+	return params
+}
+
+func TestServer_Complete(t *testing.T) {
+	var listCalls int
+	srv := newCompletionServer(t, &listCalls)
+	complete := func(params mcp.CompleteParams) []string {
+		t.Helper()
+		result, err := srv.complete(context.Background(), params)
+		require.NoError(t, err)
+		return result.Completion.Values
+	}
+
+	// Enum values, matched by prefix ignoring case
+	assert.Equal(t, []string{"available", "pending", "sold"}, complete(completeParams(toolRef, "get_pet", "status", "")))
+	assert.Equal(t, []string{"pending"}, complete(completeParams(toolRef, "get_pet", "status", "P")))
+	assert.Equal(t, []string{"eu-west"}, complete(completeParams(toolRef, "get_pet", "region", "eu")))
+	assert.Equal(t, []string{"X-Client", "X-Trace-Id"}, complete(completeParams(toolRef, "get_pet", "header", "x-")))
+
+	// Listed values are cached
+	assert.Equal(t, []string{"7", "42"}, complete(completeParams(toolRef, "get_pet", "pet_id", "")))
+	assert.Equal(t, []string{"42"}, complete(completeParams(toolRef, "get_pet", "pet_id", "4")))
+	assert.Equal(t, 1, listCalls)
+	now := time.Now().Add(2 * time.Minute)
+	srv.completions.now = func() time.Time { return now }
+	complete(completeParams(toolRef, "get_pet", "pet_id", ""))
+	assert.Equal(t, 2, listCalls)
+
+	// Prompt arguments complete like the arguments of the prompt's tools
+	assert.Equal(t, []string{"sold"}, complete(completeParams(promptRef, "adopt", "status", "s")))
+	assert.Empty(t, complete(completeParams(resourceRef, "", "id", "")))
+
+	for _, params := range []mcp.CompleteParams{
+		completeParams(toolRef, "missing", "status", ""),
+		completeParams(promptRef, "missing", "status", ""),
+		completeParams(promptRef, "adopt", "region", ""),
+		completeParams("ref/unknown", "adopt", "status", ""),
+	} {
+		_, err := srv.complete(context.Background(), params)
+		assert.Error(t, err, "%v", params.Ref)
+	}
+}
+
+func TestCompletionResult_Limit(t *testing.T) {
+	values := make([]string, 150)
+	for i := range values {
+		values[i] = strings.Repeat("a", i+1)
+	}
+	result := completionResult(append(values, "a"), "")
+	assert.Len(t, result.Completion.Values, maxCompletionValues)
+	assert.Equal(t, 150, result.Completion.Total)
+	assert.True(t, result.Completion.HasMore)
+}
+
+func TestServer_AdvertisesCompletions(t *testing.T) {
+	var listCalls int
+	srv := newCompletionServer(t, &listCalls)
+	response := srv.MCPServer().HandleMessage(context.Background(), []byte(
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`))
+	data, err := json.Marshal(response)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"experimental":{"completions":{}}`)
+}
+
+func TestServer_CompletionHTTP(t *testing.T) {
+	var listCalls int
+	srv := newCompletionServer(t, &listCalls)
+	ts := httptest.NewServer(srv.completionHTTP(mcpserver.NewStreamableHTTPServer(srv.mcp)))
+	defer ts.Close()
+
+	post := func(body string) (*http.Response, map[string]any) {
+		t.Helper()
+		resp, err := http.Post(ts.URL, "application/json", strings.NewReader(body))
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+		var message map[string]any
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&message))
+		return resp, message
+	}
+
+	resp, message := post(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`)
+	assert.NotEmpty(t, resp.Header.Get("Mcp-Session-Id"))
+	capabilities := message["result"].(map[string]any)["capabilities"].(map[string]any)
+	assert.Contains(t, capabilities["experimental"], "completions")
+
+	_, message = post(`{"jsonrpc":"2.0","id":2,"method":"completion/complete","params":{"ref":{"type":"ref/prompt","name":"adopt"},"argument":{"name":"status","value":"a"}}}`)
+	assert.Equal(t, float64(2), message["id"])
+	assert.Equal(t, []any{"available"}, message["result"].(map[string]any)["completion"].(map[string]any)["values"])
+
+	_, message = post(`{"jsonrpc":"2.0","id":3,"method":"completion/complete","params":{"ref":{"type":"ref/prompt","name":"missing"},"argument":{"name":"status","value":""}}}`)
+	assert.Equal(t, float64(mcp.INVALID_PARAMS), message["error"].(map[string]any)["code"])
+}
+
+func TestServer_CompletionSSE(t *testing.T) {
+	var listCalls int
+	srv := newCompletionServer(t, &listCalls)
+	sseServer := mcpserver.NewSSEServer(srv.mcp)
+	ts := httptest.NewServer(srv.completionMessages(sseServer, sseServer))
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"/sse", nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	events := bufio.NewScanner(resp.Body)
+	next := func() string {
+		t.Helper()
+		for events.Scan() {
+			if data, ok := strings.CutPrefix(events.Text(), "data: "); ok {
+				return data
+			}
+		}
+		t.Fatalf("stream ended: %v", events.Err())
+		return ""
+	}
+	endpoint := next()
+	send := func(body string) {
+		t.Helper()
+		resp, err := http.Post(ts.URL+endpoint, "application/json", strings.NewReader(body))
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		require.Equal(t, http.StatusAccepted, resp.StatusCode)
+	}
+
+	send(`{"jsonrpc":"2.0","id":1,"method":"completion/complete","params":{"ref":{"type":"ref/tool","name":"get_pet"},"argument":{"name":"status","value":"s"}}}`)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":1,"result":{"completion":{"values":["sold"]}}}`, next())
+
+	resp, err = http.Post(ts.URL+"/message?sessionId=unknown", "application/json",
+		strings.NewReader(`{"jsonrpc":"2.0","id":3,"method":"completion/complete","params":{"ref":{"type":"ref/tool","name":"get_pet"},"argument":{"name":"status","value":""}}}`))
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestServer_CompletionStdio(t *testing.T) {
+	var listCalls int
+	srv := newCompletionServer(t, &listCalls)
+	var out strings.Builder
+	in, _ := srv.completionStdio(context.Background(), strings.NewReader(
+		`{"jsonrpc":"2.0","id":1,"method":"completion/complete","params":{"ref":{"type":"ref/tool","name":"get_pet"},"argument":{"name":"region","value":"us"}}}`+"\n"+
+			`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`+"\n"), &out)
+
+	forwarded, err := io.ReadAll(in)
+	require.NoError(t, err)
+	assert.Equal(t, `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`+"\n", string(forwarded))
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":1,"result":{"completion":{"values":["us-east"]}}}`, out.String())
+}
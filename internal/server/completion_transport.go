@@ -0,0 +1,165 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/brizzai/auto-mcp/internal/logger"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+	"go.uber.org/zap"
+)
+
+// mcp-go neither routes completion requests nor knows the completions
+// capability. The capability is advertised by an initialize hook, and the
+// transports answer completion requests before passing the other messages
+// on to mcp-go unchanged.
+
+// completionsCapability is the experimental capability advertising
+// completion/complete
+const completionsCapability = "completions"
+
+// advertiseCompletions adds the completions capability to initialize results
+func advertiseCompletions(_ context.Context, _ any, _ *mcp.InitializeRequest, result *mcp.InitializeResult) {
+	if result.Capabilities.Experimental == nil {
+		result.Capabilities.Experimental = make(map[string]any)
+	}
+	result.Capabilities.Experimental[completionsCapability] = struct{}{}
+}
+
+// completionRequest is a JSON-RPC request as far as completions need it
+type completionRequest struct {
+	ID     mcp.RequestId      `json:"id"`
+	Method string             `json:"method"`
+	Params mcp.CompleteParams `json:"params"`
+}
+
+// answerCompletion returns the response to message when it is a completion
+// request, false for every other message
+func (s *Server) answerCompletion(ctx context.Context, message []byte) (mcp.JSONRPCMessage, bool) {
+	var request completionRequest
+	if err := json.Unmarshal(message, &request); err != nil || request.ID.IsNil() || request.Method != completeMethod {
+		return nil, false
+	}
+	result, err := s.complete(ctx, request.Params)
+	if err != nil {
+		return mcp.NewJSONRPCError(request.ID, mcp.INVALID_PARAMS, err.Error(), nil), true
+	}
+	return mcp.JSONRPCResponse{JSONRPC: mcp.JSONRPC_VERSION, ID: request.ID, Result: result}, true
+}
+
+// completionHTTP answers completion requests of the streamable HTTP transport
+func (s *Server) completionHTTP(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			next.ServeHTTP(w, r)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "Failed to read request body", http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		response, ok := s.answerCompletion(r.Context(), body)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			logger.Debug("Failed to write completion", zap.Error(err))
+		}
+	})
+}
+
+// completionMessages answers completion requests of the SSE transport on the
+// session's stream, like mcp-go answers other requests
+func (s *Server) completionMessages(sseServer *mcpserver.SSEServer, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sessionID := r.URL.Query().Get("sessionId")
+		if r.Method != http.MethodPost || sessionID == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "Failed to read request body", http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		response, ok := s.answerCompletion(r.Context(), body)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if err := sseServer.SendEventToSession(sessionID, response); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	})
+}
+
+// completionStdio answers the completion requests read from in on out and
+// returns the input and output for the stdio transport: the other messages
+// of in, and out with writes serialized against the completion responses.
+func (s *Server) completionStdio(ctx context.Context, in io.Reader, out io.Writer) (io.Reader, io.Writer) {
+	writer := &lineWriter{w: out}
+	reader, forward := io.Pipe()
+	go func() {
+		lines := bufio.NewReader(in)
+		for {
+			line, err := lines.ReadBytes('\n')
+			if len(line) > 0 {
+				if response, ok := s.answerCompletion(ctx, line); ok {
+					if err := writer.writeMessage(response); err != nil {
+						logger.Debug("Failed to write completion", zap.Error(err))
+					}
+				} else if _, err := forward.Write(line); err != nil {
+					return
+				}
+			}
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					err = nil
+				}
+				forward.CloseWithError(err)
+				return
+			}
+		}
+	}()
+	return reader, writer
+}
+
+// lineWriter serializes the messages written to the stdio output. mcp-go
+// writes every message with a single Write.
+type lineWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// Write writes a message
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.w.Write(p)
+}
+
+// writeMessage writes a JSON-RPC message followed by a newline
+func (w *lineWriter) writeMessage(message mcp.JSONRPCMessage) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
@@ -0,0 +1,150 @@
+// Package composite turns composite tool definitions from the adjustments
+// file into MCP tools that call other tools in order.
+package composite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/brizzai/auto-mcp/internal/logger"
+	"github.com/brizzai/auto-mcp/internal/models"
+	"github.com/brizzai/auto-mcp/internal/transform"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"go.uber.org/zap"
+)
+
+// maxDepth limits composite tools calling composite tools
+const maxDepth = 5
+
+// errTooDeep stops composite tools that call each other in a cycle
+var errTooDeep = errors.New("composite tools are nested too deeply")
+
+type depthKey struct{}
+
+// Lookup returns the handler of the tool with the given name
+type Lookup func(name string) (server.ToolHandlerFunc, error)
+
+type step struct {
+	models.CompositeStep
+	arguments *transform.Transform
+}
+
+// New creates the MCP tool and handler for a composite tool definition.
+// Steps name the tools they call and their results; lookup resolves the
+// tools when the composite tool is called, so it sees tools added later.
+func New(def models.CompositeTool, lookup Lookup) server.ServerTool {
+	steps := make([]step, 0, len(def.Steps))
+	for _, s := range def.Steps {
+		// Templates are validated when the adjustments file is loaded
+		arguments, err := transform.Compile(s.Arguments, nil, "")
+		if err != nil {
+			logger.Error("Invalid composite step arguments, ignoring them", zap.String("tool", def.Name), zap.String("step", s.Name), zap.Error(err))
+		}
+		steps = append(steps, step{CompositeStep: s, arguments: arguments})
+	}
+	var result *transform.Transform
+	if def.Result != "" {
+		var err error
+		if result, err = transform.Compile(nil, nil, def.Result); err != nil {
+			logger.Error("Invalid composite result template, ignoring it", zap.String("tool", def.Name), zap.Error(err))
+		}
+	}
+
+	return server.ServerTool{
+		Tool: newTool(def),
+		Handler: func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			depth, _ := ctx.Value(depthKey{}).(int)
+			if depth >= maxDepth {
+				return mcp.NewToolResultError(errTooDeep.Error()), nil
+			}
+			ctx = context.WithValue(ctx, depthKey{}, depth+1)
+
+			args := request.GetArguments()
+			for _, arg := range def.Arguments {
+				if _, ok := args[arg.Name]; arg.Required && !ok {
+					return mcp.NewToolResultError(fmt.Sprintf("Missing required argument %s", arg.Name)), nil
+				}
+			}
+
+			results := make(map[string]any, len(steps))
+			data := map[string]any{"args": args, "steps": results}
+			var last *mcp.CallToolResult
+			for i, s := range steps {
+				handler, err := lookup(s.Tool)
+				if err != nil {
+					return mcp.NewToolResultError(fmt.Sprintf("Step %d (%s): %v", i+1, s.Name, err)), nil
+				}
+				stepArgs := make(map[string]any)
+				if _, err := s.arguments.Request(stepArgs, data); err != nil {
+					return mcp.NewToolResultError(fmt.Sprintf("Step %d (%s): arguments: %v", i+1, s.Name, err)), nil
+				}
+
+				call := mcp.CallToolRequest{}
+				call.Params.Name = s.Tool
+				call.Params.Arguments = stepArgs
+				last, err = handler(ctx, call)
+				if err != nil {
+					return nil, fmt.Errorf("step %d (%s): %w", i+1, s.Name, err)
+				}
+				if last.IsError {
+					return mcp.NewToolResultError(fmt.Sprintf("Step %d (%s) failed: %s", i+1, s.Name, resultText(last))), nil
+				}
+				results[s.Name] = transform.DecodeBody([]byte(resultText(last)))
+			}
+
+			if !result.HasResponse() {
+				return last, nil
+			}
+			text, err := result.Response(data)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Result template failed: %v", err)), nil
+			}
+			return mcp.NewToolResultText(text), nil
+		},
+	}
+}
+
+// newTool builds the tool and its input schema from the argument definitions
+func newTool(def models.CompositeTool) mcp.Tool {
+	opts := []mcp.ToolOption{mcp.WithDescription(def.Description)}
+	for _, arg := range def.Arguments {
+		propOpts := []mcp.PropertyOption{mcp.Description(arg.Description)}
+		if arg.Required {
+			propOpts = append(propOpts, mcp.Required())
+		}
+		switch arg.Type {
+		case "number", "integer":
+			opts = append(opts, mcp.WithNumber(arg.Name, propOpts...))
+		case "boolean":
+			opts = append(opts, mcp.WithBoolean(arg.Name, propOpts...))
+		case "object":
+			opts = append(opts, mcp.WithObject(arg.Name, propOpts...))
+		case "array":
+			opts = append(opts, mcp.WithArray(arg.Name, propOpts...))
+		default:
+			opts = append(opts, mcp.WithString(arg.Name, propOpts...))
+		}
+	}
+
+	tool := mcp.NewTool(def.Name, opts...)
+	for _, arg := range def.Arguments {
+		if arg.Type == "integer" {
+			tool.InputSchema.Properties[arg.Name].(map[string]any)["type"] = "integer"
+		}
+	}
+	return tool
+}
+
+// resultText joins the text content of a tool result
+func resultText(result *mcp.CallToolResult) string {
+	texts := make([]string, 0, len(result.Content))
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			texts = append(texts, text.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
@@ -0,0 +1,126 @@
+package composite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/brizzai/auto-mcp/internal/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var orderAndPay = models.CompositeTool{
+	Name:        "create_order_and_pay",
+	Description: "Create an order and pay for it",
+	Arguments: []models.CompositeArgument{
+		{Name: "pet_id", Type: "integer", Required: true},
+		{Name: "card", Description: "Card token"},
+	},
+	Steps: []models.CompositeStep{
+		{Name: "order", Tool: "post_orders", Arguments: map[string]string{
+			"body.petId":    "{{ .args.pet_id }}",
+			"body.quantity": "1",
+		}},
+		{Name: "payment", Tool: "post_payments", Arguments: map[string]string{
+			"body.order_id": "{{ .steps.order.id }}",
+			"body.card":     `{{ default "default-card" .args.card }}`,
+		}},
+	},
+}
+
+// recorder returns tool handlers echoing a fixed result and records the calls
+type recorder struct {
+	calls   map[string]map[string]any
+	results map[string]*mcp.CallToolResult
+}
+
+func (r *recorder) lookup(name string) (server.ToolHandlerFunc, error) {
+	result, ok := r.results[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown tool: %s", name)
+	}
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		r.calls[name] = request.GetArguments()
+		return result, nil
+	}, nil
+}
+
+func call(t *testing.T, tool server.ServerTool, args map[string]any) *mcp.CallToolResult {
+	t.Helper()
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = args
+	result, err := tool.Handler(context.Background(), request)
+	require.NoError(t, err)
+	return result
+}
+
+func TestNew(t *testing.T) {
+	r := &recorder{calls: map[string]map[string]any{}, results: map[string]*mcp.CallToolResult{
+		"post_orders":   mcp.NewToolResultText(`{"id": 17, "status": "placed"}`),
+		"post_payments": mcp.NewToolResultText(`{"paid": true}`),
+	}}
+	tool := New(orderAndPay, r.lookup)
+
+	assert.Equal(t, "create_order_and_pay", tool.Tool.Name)
+	assert.Equal(t, []string{"pet_id"}, tool.Tool.InputSchema.Required)
+	assert.Equal(t, "integer", tool.Tool.InputSchema.Properties["pet_id"].(map[string]any)["type"])
+
+	result := call(t, tool, map[string]any{"pet_id": 3})
+	require.False(t, result.IsError)
+	assert.Equal(t, `{"paid": true}`, result.Content[0].(mcp.TextContent).Text)
+	assert.Equal(t, map[string]any{"body": map[string]any{"petId": json.Number("3"), "quantity": json.Number("1")}}, r.calls["post_orders"])
+	assert.Equal(t, map[string]any{"body": map[string]any{"order_id": json.Number("17"), "card": "default-card"}}, r.calls["post_payments"])
+
+	result = call(t, tool, map[string]any{})
+	assert.True(t, result.IsError)
+	assert.Equal(t, "Missing required argument pet_id", result.Content[0].(mcp.TextContent).Text)
+}
+
+func TestNew_StepFailure(t *testing.T) {
+	r := &recorder{calls: map[string]map[string]any{}, results: map[string]*mcp.CallToolResult{
+		"post_orders": mcp.NewToolResultError("HTTP Error 400: out of stock"),
+	}}
+	result := call(t, New(orderAndPay, r.lookup), map[string]any{"pet_id": 3})
+
+	require.True(t, result.IsError)
+	assert.Equal(t, "Step 1 (order) failed: HTTP Error 400: out of stock", result.Content[0].(mcp.TextContent).Text)
+	assert.NotContains(t, r.calls, "post_payments")
+
+	// Unknown tools fail when the composite tool is called
+	r.results = map[string]*mcp.CallToolResult{"post_orders": mcp.NewToolResultText(`{"id": 1}`)}
+	result = call(t, New(orderAndPay, r.lookup), map[string]any{"pet_id": 3})
+	require.True(t, result.IsError)
+	assert.Equal(t, "Step 2 (payment): unknown tool: post_payments", result.Content[0].(mcp.TextContent).Text)
+}
+
+func TestNew_Result(t *testing.T) {
+	r := &recorder{calls: map[string]map[string]any{}, results: map[string]*mcp.CallToolResult{
+		"post_orders":   mcp.NewToolResultText(`{"id": 17}`),
+		"post_payments": mcp.NewToolResultText(`{"paid": true}`),
+	}}
+	def := orderAndPay
+	def.Result = "Order {{ .steps.order.id }} paid: {{ .steps.payment.paid }}"
+
+	result := call(t, New(def, r.lookup), map[string]any{"pet_id": 3})
+	require.False(t, result.IsError)
+	assert.Equal(t, "Order 17 paid: true", result.Content[0].(mcp.TextContent).Text)
+}
+
+func TestNew_Cycle(t *testing.T) {
+	var tools map[string]server.ServerTool
+	lookup := func(name string) (server.ToolHandlerFunc, error) {
+		return tools[name].Handler, nil
+	}
+	tools = map[string]server.ServerTool{
+		"a": New(models.CompositeTool{Name: "a", Steps: []models.CompositeStep{{Name: "b", Tool: "b"}}}, lookup),
+		"b": New(models.CompositeTool{Name: "b", Steps: []models.CompositeStep{{Name: "a", Tool: "a"}}}, lookup),
+	}
+
+	result := call(t, tools["a"], nil)
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, errTooDeep.Error())
+}
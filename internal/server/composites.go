@@ -0,0 +1,92 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/brizzai/auto-mcp/internal/logger"
+	"github.com/brizzai/auto-mcp/internal/models"
+	"github.com/brizzai/auto-mcp/internal/parser"
+	"github.com/brizzai/auto-mcp/internal/server/composite"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+	"go.uber.org/zap"
+)
+
+// setupCompositeTools registers the composite tools from the adjustments
+// file and removes those a previous load registered that are gone
+func (s *Server) setupCompositeTools(routes []*parser.RouteTool) {
+	defs := s.parser.GetCompositeTools()
+
+	known := make(map[string]bool, len(routes)+len(defs))
+	for _, route := range routes {
+		known[route.Tool.Name] = true
+	}
+	for _, def := range defs {
+		known[s.toolName(def.Name)] = true
+	}
+
+	tools := make([]mcpserver.ServerTool, 0, len(defs))
+	names := make(map[string]bool, len(defs))
+	for _, def := range defs {
+		// Composite tools reference tools by their unprefixed names
+		def.Name = s.toolName(def.Name)
+		steps := make([]models.CompositeStep, 0, len(def.Steps))
+		for _, step := range def.Steps {
+			if step.Name == "" {
+				step.Name = step.Tool
+			}
+			step.Tool = s.toolName(step.Tool)
+			if !known[step.Tool] {
+				logger.Warn("Composite tool references an unknown tool",
+					zap.String("composite", def.Name),
+					zap.String("tool", step.Tool),
+				)
+			}
+			steps = append(steps, step)
+		}
+		def.Steps = steps
+		tools = append(tools, composite.New(def, s.toolHandler))
+		names[def.Name] = true
+	}
+
+	s.toolsMu.Lock()
+	previous := s.composites
+	s.composites = names
+	s.toolsMu.Unlock()
+	for name := range previous {
+		if !names[name] {
+			if err := s.RemoveTool(name); err != nil {
+				logger.Error("Failed to remove tool", zap.String("tool", name), zap.Error(err))
+			}
+		}
+	}
+
+	if len(tools) > 0 {
+		s.AddTools(tools...)
+		logger.Info("Registered composite tools", zap.Int("count", len(tools)))
+	}
+}
+
+// toolHandler returns the handler of a registered tool or, in lazy mode, of
+// an operation in the catalog
+func (s *Server) toolHandler(name string) (mcpserver.ToolHandlerFunc, error) {
+	s.toolsMu.Lock()
+	tool, registered := s.tools[name]
+	disabled := s.disabled[name]
+	route := s.catalog[name]
+	s.toolsMu.Unlock()
+
+	switch {
+	case registered && disabled:
+		return nil, fmt.Errorf("tool %s is disabled", name)
+	case registered:
+		return tool.Handler, nil
+	case route != nil:
+		executor, err := s.requester.BuildRouteExecutor(route.RouteConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build route executor for %s: %w", name, err)
+		}
+		routeTool := route.Tool
+		return s.tool.CreateHandler(&routeTool, route.RouteConfig, executor), nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownTool, name)
+}
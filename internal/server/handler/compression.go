@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/brizzai/auto-mcp/internal/logger"
+	"go.uber.org/zap"
+)
+
+// gzipResponseWriter compresses the response body on the fly. The decision to
+// compress is deferred until the first byte (or flush) so bodiless responses
+// such as 202 Accepted are sent untouched.
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	writer      *gzip.Writer
+	status      int
+	wroteHeader bool
+}
+
+// WriteHeader records the status code; headers are sent with the first write
+func (w *gzipResponseWriter) WriteHeader(status int) {
+	if w.wroteHeader || w.status != 0 {
+		return
+	}
+	w.status = status
+}
+
+// Write compresses b into the underlying response
+func (w *gzipResponseWriter) Write(b []byte) (int, error) {
+	w.startBody()
+	if w.writer == nil {
+		return w.ResponseWriter.Write(b)
+	}
+	return w.writer.Write(b)
+}
+
+// Flush flushes the compressed stream so partial responses reach the client
+// promptly
+func (w *gzipResponseWriter) Flush() {
+	w.startBody()
+	if w.writer != nil {
+		if err := w.writer.Flush(); err != nil {
+			logger.Debug("Failed to flush gzip writer", zap.Error(err))
+		}
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// startBody sends the headers, switching to gzip when the response carries a
+// body. Event streams are sent uncompressed: proxies and clients buffer
+// gzipped streams, which would hold back events and keep-alives.
+func (w *gzipResponseWriter) startBody() {
+	if w.wroteHeader {
+		return
+	}
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if bodyAllowed(w.status) && w.Header().Get("Content-Encoding") == "" &&
+		!strings.HasPrefix(w.Header().Get("Content-Type"), "text/event-stream") {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Header().Del("Content-Length")
+		w.writer = gzip.NewWriter(w.ResponseWriter)
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	w.wroteHeader = true
+}
+
+// close finishes the gzip stream, or sends the pending headers if nothing was written
+func (w *gzipResponseWriter) close() {
+	if !w.wroteHeader {
+		if w.status != 0 {
+			w.ResponseWriter.WriteHeader(w.status)
+		}
+		return
+	}
+	if w.writer != nil {
+		if err := w.writer.Close(); err != nil {
+			logger.Debug("Failed to close gzip writer", zap.Error(err))
+		}
+	}
+}
+
+// Gzip compresses responses for clients that advertise gzip support
+func Gzip(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Caches must not serve either variant to every client
+		w.Header().Add("Vary", "Accept-Encoding")
+		if !acceptsGzip(r.Header.Get("Accept-Encoding")) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		gw := &gzipResponseWriter{ResponseWriter: w}
+		defer gw.close()
+		next.ServeHTTP(gw, r)
+	})
+}
+
+// acceptsGzip reports whether an Accept-Encoding header allows gzip, which
+// "gzip;q=0" explicitly does not
+func acceptsGzip(header string) bool {
+	for _, coding := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(coding, ";")
+		if !strings.EqualFold(strings.TrimSpace(name), "gzip") {
+			continue
+		}
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if weight, err := strconv.ParseFloat(q, 64); err == nil && weight == 0 {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func bodyAllowed(status int) bool {
+	return status >= http.StatusOK && status != http.StatusNoContent && status != http.StatusNotModified
+}
@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcceptsGzip(t *testing.T) {
+	tests := []struct {
+		header   string
+		expected bool
+	}{
+		{"gzip", true},
+		{"gzip, deflate, br", true},
+		{"br;q=1.0, GZIP;q=0.5", true},
+		{"", false},
+		{"deflate, br", false},
+		{"gzip;q=0", false},
+		{"gzip; q=0.0, deflate", false},
+		{"x-gzip-like", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, acceptsGzip(tt.header), tt.header)
+	}
+}
+
+func TestGzip(t *testing.T) {
+	const body = `{"jsonrpc":"2.0","id":1,"result":{}}`
+	tests := []struct {
+		name           string
+		acceptEncoding string
+		contentType    string
+		encoding       string // Content-Encoding set by the handler
+		status         int
+		compressed     bool
+	}{
+		{name: "accepted", acceptEncoding: "gzip, deflate", contentType: "application/json", status: http.StatusOK, compressed: true},
+		{name: "not accepted", acceptEncoding: "br", contentType: "application/json", status: http.StatusOK},
+		{name: "refused", acceptEncoding: "gzip;q=0", contentType: "application/json", status: http.StatusOK},
+		{name: "already encoded", acceptEncoding: "gzip", contentType: "application/json", encoding: "br", status: http.StatusOK},
+		{name: "event stream", acceptEncoding: "gzip", contentType: "text/event-stream", status: http.StatusOK},
+		{name: "error", acceptEncoding: "gzip", contentType: "application/json", status: http.StatusBadRequest, compressed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := Gzip(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				if tt.encoding != "" {
+					w.Header().Set("Content-Encoding", tt.encoding)
+				}
+				w.WriteHeader(tt.status)
+				_, _ = io.WriteString(w, body)
+				w.(http.Flusher).Flush()
+			}))
+			req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+			req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.status, rec.Code)
+			assert.Equal(t, []string{"Accept-Encoding"}, rec.Header().Values("Vary"))
+			if !tt.compressed {
+				assert.Equal(t, tt.encoding, rec.Header().Get("Content-Encoding"))
+				assert.Equal(t, body, rec.Body.String())
+				return
+			}
+			assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+			reader, err := gzip.NewReader(rec.Body)
+			require.NoError(t, err)
+			decoded, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			assert.Equal(t, body, string(decoded))
+		})
+	}
+}
+
+func TestGzip_NoBody(t *testing.T) {
+	handler := Gzip(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Empty(t, rec.Header().Get("Content-Encoding"))
+	assert.Zero(t, rec.Body.Len())
+}
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Forwarded applies X-Forwarded-For, X-Forwarded-Proto and X-Forwarded-Host
+// from trusted proxies: the client IP replaces RemoteAddr, the scheme is set
+// on the request URL and the host replaces Host. Headers from other peers are
+// ignored, since any client can send them. A scheme sent by the client in an
+// absolute request URI is cleared for the same reason.
+func Forwarded(trusted []*net.IPNet) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r = r.Clone(r.Context())
+			r.URL.Scheme = ""
+			if !isTrusted(trusted, ClientIP(r)) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if clientIP := forwardedClientIP(trusted, r.Header.Values("X-Forwarded-For")); clientIP != "" {
+				r.RemoteAddr = clientIP
+			}
+			switch proto := strings.ToLower(firstValue(r.Header.Get("X-Forwarded-Proto"))); proto {
+			case "http", "https":
+				r.URL.Scheme = proto
+			}
+			if host := firstValue(r.Header.Get("X-Forwarded-Host")); host != "" {
+				r.Host = host
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ClientIP returns the IP of the request's peer, or of the client when
+// Forwarded rewrote RemoteAddr
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// forwardedClientIP walks X-Forwarded-For from the right and returns the
+// first address that is not a trusted proxy, i.e. the one the nearest
+// trusted proxy saw. Left-most entries are client controlled.
+func forwardedClientIP(trusted []*net.IPNet, values []string) string {
+	var hops []string
+	for _, value := range values {
+		for _, hop := range strings.Split(value, ",") {
+			hops = append(hops, strings.TrimSpace(hop))
+		}
+	}
+
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := net.ParseIP(hops[i])
+		if ip == nil {
+			return ""
+		}
+		if !isTrusted(trusted, hops[i]) || i == 0 {
+			return ip.String()
+		}
+	}
+	return ""
+}
+
+func isTrusted(trusted []*net.IPNet, addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, ipNet := range trusted {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// firstValue returns the first entry of a comma separated header
+func firstValue(value string) string {
+	first, _, _ := strings.Cut(value, ",")
+	return strings.TrimSpace(first)
+}
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/brizzai/auto-mcp/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForwarded(t *testing.T) {
+	cfg := &config.ServerConfig{TrustedProxies: []string{"10.0.0.0/8", "192.168.1.1"}}
+	trusted, err := cfg.TrustedProxyNets()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		remoteAddr     string
+		forwardedFor   string
+		expectedIP     string
+		expectedScheme string
+		expectedHost   string
+	}{
+		{
+			name:           "trusted proxy",
+			remoteAddr:     "10.1.2.3:4000",
+			forwardedFor:   "203.0.113.7",
+			expectedIP:     "203.0.113.7",
+			expectedScheme: "https",
+			expectedHost:   "mcp.example.com",
+		},
+		{
+			name:           "spoofed left-most entry is ignored",
+			remoteAddr:     "192.168.1.1:4000",
+			forwardedFor:   "1.1.1.1, 203.0.113.7, 10.0.0.5",
+			expectedIP:     "203.0.113.7",
+			expectedScheme: "https",
+			expectedHost:   "mcp.example.com",
+		},
+		{
+			name:         "untrusted peer",
+			remoteAddr:   "203.0.113.9:4000",
+			forwardedFor: "1.1.1.1",
+			expectedIP:   "203.0.113.9",
+			expectedHost: "internal:8080",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *http.Request
+			handler := Forwarded(trusted)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "http://internal:8080/sse", nil)
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set("X-Forwarded-For", tt.forwardedFor)
+			req.Header.Set("X-Forwarded-Proto", "https")
+			req.Header.Set("X-Forwarded-Host", "mcp.example.com")
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			require.NotNil(t, got)
+			assert.Equal(t, tt.expectedIP, ClientIP(got))
+			assert.Equal(t, tt.expectedScheme, got.URL.Scheme)
+			assert.Equal(t, tt.expectedHost, got.Host)
+		})
+	}
+}
+
+func TestExternalURL_ForwardedProto(t *testing.T) {
+	cfg := &config.ServerConfig{TrustedProxies: []string{"10.0.0.1"}, BasePath: "mcp"}
+	trusted, err := cfg.TrustedProxyNets()
+	require.NoError(t, err)
+
+	var externalURL string
+	handler := Forwarded(trusted)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		externalURL = cfg.ExternalURL(r)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "http://internal:8080/.well-known/oauth-authorization-server", nil)
+	req.RemoteAddr = "10.0.0.1:5000"
+	req.Header.Set("X-Forwarded-Proto", "https")
+	req.Header.Set("X-Forwarded-Host", "mcp.example.com")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, "https://mcp.example.com/mcp", externalURL)
+}
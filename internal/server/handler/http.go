@@ -2,39 +2,145 @@
 package handler
 
 import (
+	"crypto/subtle"
+	"net"
 	"net/http"
+	"sync/atomic"
 
 	"github.com/brizzai/auto-mcp/internal/auth"
+	"github.com/brizzai/auto-mcp/internal/config"
 	"github.com/brizzai/auto-mcp/internal/logger"
+	"go.uber.org/zap"
 )
 
 // Handler manages HTTP request handling and middleware configuration.
 type Handler struct {
-	auth *auth.Service
+	auth       *auth.Service
+	cfg        *config.ServerConfig
+	rateLimits *RateLimits
+	// admin serves /admin/ behind the admin token, nil when not configured
+	admin http.Handler
+	// draining rejects new MCP requests while the server shuts down
+	draining atomic.Bool
 }
 
 // NewHandler creates a new HTTP handler.
-func NewHandler(auth *auth.Service) *Handler {
+func NewHandler(auth *auth.Service, cfg *config.ServerConfig) *Handler {
+	var rateLimits config.RateLimitConfig
+	if cfg != nil {
+		rateLimits = cfg.RateLimit
+	}
 	return &Handler{
-		auth: auth,
+		auth:       auth,
+		cfg:        cfg,
+		rateLimits: NewRateLimits(rateLimits),
 	}
 }
 
+// UpdateRateLimits applies new rate limits to the running handler
+func (h *Handler) UpdateRateLimits(cfg config.RateLimitConfig) {
+	h.rateLimits.Update(cfg)
+}
+
+// SetAdminHandler serves admin under /admin/ for requests bearing
+// server.admin.token. It must be called before CreateHTTPHandler.
+func (h *Handler) SetAdminHandler(admin http.Handler) {
+	h.admin = admin
+}
+
+// requireAdminToken rejects requests without the admin bearer token
+func (h *Handler) requireAdminToken(next http.Handler) http.Handler {
+	expected := []byte("Bearer " + h.cfg.Admin.Token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Drain makes the MCP endpoints answer 503 to new requests, so clients
+// reconnect elsewhere while running requests finish
+func (h *Handler) Drain() {
+	h.draining.Store(true)
+}
+
+// rejectWhileDraining answers 503 once Drain has been called
+func (h *Handler) rejectWhileDraining(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h.draining.Load() {
+			w.Header().Set("Connection", "close")
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "Server is shutting down", http.StatusServiceUnavailable)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
 // CreateHTTPHandler creates an HTTP handler with the appropriate middleware stack.
 // If authentication is enabled, it adds authentication middleware to protected routes.
 func (h *Handler) CreateHTTPHandler(mcpHandler http.Handler) http.Handler {
+	mcpHandler = TraceContext(mcpHandler)
+	if h.cfg != nil && h.cfg.Compression {
+		mcpHandler = Gzip(mcpHandler)
+		logger.Info("Enabled gzip compression for MCP responses")
+	}
+
+	mcpHandler = h.rateLimits.BySession()(mcpHandler)
+	mcpHandler = h.rejectWhileDraining(mcpHandler)
+
 	mux := http.NewServeMux()
+	if h.admin != nil && h.cfg != nil && h.cfg.Admin.Token != "" {
+		// The admin API has its own token instead of MCP client authentication
+		mux.Handle("/admin/", h.requireAdminToken(h.admin))
+		logger.Info("Enabled the admin API")
+	}
 
 	// Set up authentication routes and middleware if enabled
+	var handler http.Handler
 	if h.auth != nil {
 		h.auth.RegisterRoutes(mux)
 		logger.Info("Registered authentication routes")
 		mux.Handle("/", h.auth.Authenticate()(mcpHandler))
 		logger.Info("Enabled authentication for all routes")
-		return h.auth.WrapWithCors(mux)
+		handler = h.auth.WrapWithCors(mux)
 	} else {
 		mux.Handle("/", mcpHandler)
 		logger.Info("Running without authentication")
-		return mux
+		handler = mux
+	}
+
+	handler = h.mount(handler)
+
+	var trusted []*net.IPNet
+	if h.cfg != nil && len(h.cfg.TrustedProxies) > 0 {
+		// Validated when the configuration is loaded
+		var err error
+		if trusted, err = h.cfg.TrustedProxyNets(); err != nil {
+			logger.Error("Ignoring invalid trusted proxies", zap.Error(err))
+		} else {
+			logger.Info("Honoring forwarded headers from trusted proxies", zap.Strings("trusted_proxies", h.cfg.TrustedProxies))
+		}
 	}
+	var maxBodyBytes int64
+	if h.cfg != nil {
+		maxBodyBytes = h.cfg.Limits.MaxBodyBytes
+	}
+	return Limits(maxBodyBytes)(Forwarded(trusted)(h.rateLimits.ByIP()(handler)))
+}
+
+// mount serves handler under the configured base path
+func (h *Handler) mount(handler http.Handler) http.Handler {
+	if h.cfg == nil || h.cfg.MountPath() == "" {
+		return handler
+	}
+	basePath := h.cfg.MountPath()
+	logger.Info("Serving under base path", zap.String("base_path", basePath))
+
+	root := http.NewServeMux()
+	root.Handle(basePath+"/", http.StripPrefix(basePath, handler))
+	return root
 }
@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/brizzai/auto-mcp/internal/logger"
+	"go.uber.org/zap"
+)
+
+// Limits caps request bodies at maxBodyBytes, answering 413 when a request
+// declares a larger body, 0 disables the cap. Event streams are exempt from
+// the server read and write timeouts, which would otherwise end them.
+func Limits(maxBodyBytes int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isEventStream(r) {
+				rc := http.NewResponseController(w)
+				// Not supported by every writer, e.g. in tests
+				_ = rc.SetReadDeadline(time.Time{})
+				_ = rc.SetWriteDeadline(time.Time{})
+			}
+			if maxBodyBytes > 0 {
+				if r.ContentLength > maxBodyBytes {
+					logger.Debug("Rejected oversized request body",
+						zap.String("path", r.URL.Path),
+						zap.Int64("content_length", r.ContentLength),
+					)
+					w.Header().Set("Connection", "close")
+					http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+					return
+				}
+				// Chunked bodies fail to read once they exceed the cap
+				r.Body = http.MaxBytesReader(w, r.Body, maxBodyBytes)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// isEventStream reports whether r opens a long-lived SSE stream, the GET
+// requests of the sse and streamable HTTP transports
+func isEventStream(r *http.Request) bool {
+	return r.Method == http.MethodGet && strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/brizzai/auto-mcp/internal/auth/middleware"
+	"github.com/brizzai/auto-mcp/internal/config"
+)
+
+// limiterSweepInterval is how often idle buckets are dropped
+const limiterSweepInterval = time.Minute
+
+// tokenBucket allows bursts up to its capacity and refills continuously
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// limiter keeps one token bucket per key
+type limiter struct {
+	rate  float64 // tokens per second
+	burst float64
+
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+func newLimiter(perMinute, burst int) *limiter {
+	if burst <= 0 {
+		burst = perMinute
+	}
+	return &limiter{
+		rate:    float64(perMinute) / 60,
+		burst:   float64(burst),
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// allow takes a token for key, or returns how long to wait for the next one
+func (l *limiter) allow(key string, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) > limiterSweepInterval {
+		l.sweep(now)
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	wait := time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	return false, wait
+}
+
+// sweep drops buckets that have refilled completely, they behave like new ones
+func (l *limiter) sweep(now time.Time) {
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+// RateLimits holds the per-IP, per-user and per-session limiters. They can be
+// replaced at runtime; a nil limiter means the scope is not limited.
+type RateLimits struct {
+	mu      sync.Mutex
+	cfg     config.RateLimitConfig
+	ip      atomic.Pointer[limiter]
+	user    atomic.Pointer[limiter]
+	session atomic.Pointer[limiter]
+}
+
+// NewRateLimits creates the limiters for cfg
+func NewRateLimits(cfg config.RateLimitConfig) *RateLimits {
+	rl := &RateLimits{cfg: cfg}
+	rl.ip.Store(limiterFor(cfg.PerIP, cfg.Burst))
+	rl.user.Store(limiterFor(cfg.PerUser, cfg.Burst))
+	rl.session.Store(limiterFor(cfg.PerSession, cfg.Burst))
+	return rl
+}
+
+// Update applies new limits. Limiters whose settings are unchanged keep their
+// buckets, so clients are not handed a fresh burst on every reload.
+func (rl *RateLimits) Update(cfg config.RateLimitConfig) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	burstChanged := cfg.Burst != rl.cfg.Burst
+	if burstChanged || cfg.PerIP != rl.cfg.PerIP {
+		rl.ip.Store(limiterFor(cfg.PerIP, cfg.Burst))
+	}
+	if burstChanged || cfg.PerUser != rl.cfg.PerUser {
+		rl.user.Store(limiterFor(cfg.PerUser, cfg.Burst))
+	}
+	if burstChanged || cfg.PerSession != rl.cfg.PerSession {
+		rl.session.Store(limiterFor(cfg.PerSession, cfg.Burst))
+	}
+	rl.cfg = cfg
+}
+
+// ByIP rejects clients exceeding the per-IP limit with 429
+func (rl *RateLimits) ByIP() func(http.Handler) http.Handler {
+	return rateLimit("ip", func(r *http.Request) string { return ClientIP(r) }, rl.ip.Load)
+}
+
+// BySession rejects users and sessions exceeding their limits with 429.
+// It must run after authentication so the user is known.
+func (rl *RateLimits) BySession() func(http.Handler) http.Handler {
+	byUser := rateLimit("user", requestUser, rl.user.Load)
+	bySession := rateLimit("session", requestSession, rl.session.Load)
+	return func(next http.Handler) http.Handler {
+		return byUser(bySession(next))
+	}
+}
+
+// limiterFor returns nil when perMinute disables the limit
+func limiterFor(perMinute, burst int) *limiter {
+	if perMinute <= 0 {
+		return nil
+	}
+	return newLimiter(perMinute, burst)
+}
+
+// rateLimit applies the limiter returned by current to the key returned by
+// keyFunc; requests without a key are not limited
+func rateLimit(scope string, keyFunc func(*http.Request) string, current func() *limiter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			l := current()
+			if l == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			key := keyFunc(r)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if ok, wait := l.allow(key, time.Now()); !ok {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				http.Error(w, fmt.Sprintf("Rate limit exceeded (per %s), retry later", scope), http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func requestUser(r *http.Request) string {
+	if authInfo, ok := r.Context().Value(middleware.AuthContextKey).(*middleware.AuthInfo); ok {
+		return authInfo.UserID
+	}
+	return ""
+}
+
+// requestSession returns the streamable HTTP or SSE session ID
+func requestSession(r *http.Request) string {
+	if id := r.Header.Get("Mcp-Session-Id"); id != "" {
+		return id
+	}
+	return r.URL.Query().Get("sessionId")
+}
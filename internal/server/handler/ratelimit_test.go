@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/brizzai/auto-mcp/internal/auth/middleware"
+	"github.com/brizzai/auto-mcp/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiter(t *testing.T) {
+	l := newLimiter(60, 2)
+	now := time.Now()
+
+	ok, _ := l.allow("a", now)
+	assert.True(t, ok)
+	ok, _ = l.allow("a", now)
+	assert.True(t, ok)
+	ok, wait := l.allow("a", now)
+	assert.False(t, ok)
+	assert.Equal(t, time.Second, wait)
+
+	// Keys are limited independently
+	ok, _ = l.allow("b", now)
+	assert.True(t, ok)
+
+	ok, _ = l.allow("a", now.Add(time.Second))
+	assert.True(t, ok)
+
+	// Full buckets are dropped when sweeping
+	l.allow("c", now.Add(time.Hour))
+	assert.Len(t, l.buckets, 1)
+}
+
+func TestRateLimit(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	cfg := config.RateLimitConfig{PerIP: 1, PerUser: 1, PerSession: 1}
+
+	serve := func(h http.Handler, r *http.Request) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		return rec
+	}
+
+	t.Run("per IP", func(t *testing.T) {
+		h := NewRateLimits(cfg).ByIP()(ok)
+		r := httptest.NewRequest(http.MethodPost, "/", nil)
+		r.RemoteAddr = "203.0.113.7:1234"
+		assert.Equal(t, http.StatusOK, serve(h, r).Code)
+
+		rec := serve(h, r)
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.Equal(t, "60", rec.Header().Get("Retry-After"))
+
+		r.RemoteAddr = "203.0.113.8:1234"
+		assert.Equal(t, http.StatusOK, serve(h, r).Code)
+	})
+
+	t.Run("per user", func(t *testing.T) {
+		h := NewRateLimits(config.RateLimitConfig{PerUser: 1}).BySession()(ok)
+		r := httptest.NewRequest(http.MethodPost, "/", nil)
+		r = r.WithContext(context.WithValue(r.Context(), middleware.AuthContextKey, &middleware.AuthInfo{UserID: "alice"}))
+		assert.Equal(t, http.StatusOK, serve(h, r).Code)
+		assert.Equal(t, http.StatusTooManyRequests, serve(h, r).Code)
+
+		// Anonymous requests have no user to limit
+		anonymous := httptest.NewRequest(http.MethodPost, "/", nil)
+		assert.Equal(t, http.StatusOK, serve(h, anonymous).Code)
+		assert.Equal(t, http.StatusOK, serve(h, anonymous).Code)
+	})
+
+	t.Run("per session", func(t *testing.T) {
+		h := NewRateLimits(cfg).BySession()(ok)
+		r := httptest.NewRequest(http.MethodPost, "/message?sessionId=s1", nil)
+		assert.Equal(t, http.StatusOK, serve(h, r).Code)
+		assert.Equal(t, http.StatusTooManyRequests, serve(h, r).Code)
+
+		r = httptest.NewRequest(http.MethodPost, "/", nil)
+		r.Header.Set("Mcp-Session-Id", "s2")
+		assert.Equal(t, http.StatusOK, serve(h, r).Code)
+		assert.Equal(t, http.StatusTooManyRequests, serve(h, r).Code)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		rl := NewRateLimits(config.RateLimitConfig{})
+		h := rl.ByIP()(rl.BySession()(ok))
+		r := httptest.NewRequest(http.MethodPost, "/", nil)
+		for range 5 {
+			assert.Equal(t, http.StatusOK, serve(h, r).Code)
+		}
+	})
+}
+
+func TestRateLimits_Update(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	serve := func(h http.Handler) int {
+		rec := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/", nil)
+		r.RemoteAddr = "203.0.113.7:1234"
+		h.ServeHTTP(rec, r)
+		return rec.Code
+	}
+
+	rl := NewRateLimits(config.RateLimitConfig{})
+	h := rl.ByIP()(ok)
+	assert.Equal(t, http.StatusOK, serve(h))
+	assert.Equal(t, http.StatusOK, serve(h))
+
+	rl.Update(config.RateLimitConfig{PerIP: 1})
+	assert.Equal(t, http.StatusOK, serve(h))
+	assert.Equal(t, http.StatusTooManyRequests, serve(h))
+
+	// Unchanged limits keep their buckets
+	rl.Update(config.RateLimitConfig{PerIP: 1, PerSession: 5})
+	assert.Equal(t, http.StatusTooManyRequests, serve(h))
+
+	rl.Update(config.RateLimitConfig{})
+	assert.Equal(t, http.StatusOK, serve(h))
+}
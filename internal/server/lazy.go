@@ -0,0 +1,217 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/brizzai/auto-mcp/internal/logger"
+	"github.com/brizzai/auto-mcp/internal/parser"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+	"go.uber.org/zap"
+)
+
+// Meta-tool names registered in lazy mode
+const (
+	SearchToolsTool   = "search_tools"
+	EnableToolTool    = "enable_tool"
+	CallOperationTool = "call_operation"
+)
+
+const (
+	defaultSearchLimit = 10
+	maxSearchLimit     = 50
+)
+
+// operationMatch is a search_tools result
+type operationMatch struct {
+	Name        string              `json:"name"`
+	Method      string              `json:"method"`
+	Path        string              `json:"path"`
+	Description string              `json:"description,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	Enabled     bool                `json:"enabled"`
+	InputSchema mcp.ToolInputSchema `json:"input_schema"`
+}
+
+// setupLazyTools keeps the parsed operations in a catalog and registers only
+// the meta-tools that search, enable and invoke them
+func (s *Server) setupLazyTools(routes []*parser.RouteTool) {
+	catalog := make(map[string]*parser.RouteTool, len(routes))
+	for _, route := range routes {
+		catalog[route.Tool.Name] = route
+	}
+	// The catalog is replaced, never modified, when the spec is reloaded
+	s.toolsMu.Lock()
+	s.catalog = catalog
+	s.toolsMu.Unlock()
+
+	s.AddTools(
+		mcpserver.ServerTool{
+			Tool: mcp.NewTool(s.toolName(SearchToolsTool),
+				mcp.WithDescription(fmt.Sprintf("Searches the %d available API operations by keyword or tag. Returns matching operations with their input schema; use %s to add one as a tool or %s to invoke it directly.", len(routes), s.toolName(EnableToolTool), s.toolName(CallOperationTool))),
+				mcp.WithString("query", mcp.Description("Keywords matched against the operation name, path, description and tags")),
+				mcp.WithString("tag", mcp.Description("Only return operations with this tag")),
+				mcp.WithNumber("limit", mcp.Description(fmt.Sprintf("Maximum number of results, defaults to %d", defaultSearchLimit))),
+				mcp.WithReadOnlyHintAnnotation(true),
+				mcp.WithDestructiveHintAnnotation(false),
+			),
+			Handler: s.handleSearchTools,
+		},
+		mcpserver.ServerTool{
+			Tool: mcp.NewTool(s.toolName(EnableToolTool),
+				mcp.WithDescription(fmt.Sprintf("Registers an operation found with %s as a regular tool.", s.toolName(SearchToolsTool))),
+				mcp.WithString("name", mcp.Required(), mcp.Description("Operation name returned by "+s.toolName(SearchToolsTool))),
+				mcp.WithDestructiveHintAnnotation(false),
+				mcp.WithIdempotentHintAnnotation(true),
+			),
+			Handler: s.handleEnableTool,
+		},
+		mcpserver.ServerTool{
+			Tool: mcp.NewTool(s.toolName(CallOperationTool),
+				mcp.WithDescription(fmt.Sprintf("Invokes an operation found with %s without registering it.", s.toolName(SearchToolsTool))),
+				mcp.WithString("name", mcp.Required(), mcp.Description("Operation name returned by "+s.toolName(SearchToolsTool))),
+				mcp.WithObject("arguments", mcp.Description("Arguments matching the operation's input schema")),
+			),
+			Handler: s.handleCallOperation,
+		},
+	)
+	logger.Info("Registered lazy tool loading meta-tools", zap.Int("operations", len(routes)))
+}
+
+// operations returns the current lazy mode catalog
+func (s *Server) operations() map[string]*parser.RouteTool {
+	s.toolsMu.Lock()
+	defer s.toolsMu.Unlock()
+	return s.catalog
+}
+
+func (s *Server) handleSearchTools(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	limit := intArgument(request, "limit", defaultSearchLimit)
+	if limit <= 0 || limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+	routes := searchRoutes(s.visibleCatalog(ctx), request.GetString("query", ""), request.GetString("tag", ""), limit)
+
+	s.toolsMu.Lock()
+	matches := make([]operationMatch, 0, len(routes))
+	for _, route := range routes {
+		_, registered := s.tools[route.Tool.Name]
+		matches = append(matches, operationMatch{
+			Name:        route.Tool.Name,
+			Method:      route.RouteConfig.Method,
+			Path:        route.RouteConfig.Path,
+			Description: route.RouteConfig.Description,
+			Tags:        route.RouteConfig.Tags,
+			Enabled:     registered && !s.disabled[route.Tool.Name],
+			InputSchema: route.Tool.InputSchema,
+		})
+	}
+	s.toolsMu.Unlock()
+
+	data, err := json.Marshal(matches)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal search results: %w", err)
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+func (s *Server) handleEnableTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	name := request.GetString("name", "")
+	route, ok := s.operations()[name]
+	if !ok || !s.toolVisible(ctx, name, route.RouteConfig.Method) {
+		return mcp.NewToolResultError(fmt.Sprintf("Unknown operation %q, use %s to find operations", name, s.toolName(SearchToolsTool))), nil
+	}
+	if err := s.AddRouteTool(route); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Tool %s is now available", name)), nil
+}
+
+func (s *Server) handleCallOperation(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	name := request.GetString("name", "")
+	route, ok := s.operations()[name]
+	if !ok {
+		return mcp.NewToolResultError(fmt.Sprintf("Unknown operation %q, use %s to find operations", name, s.toolName(SearchToolsTool))), nil
+	}
+
+	executor, err := s.requester.BuildRouteExecutor(route.RouteConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build route executor for %s: %w", name, err)
+	}
+	tool := route.Tool
+	handler := s.tool.CreateHandler(&tool, route.RouteConfig, executor)
+
+	call := mcp.CallToolRequest{}
+	call.Params.Name = name
+	call.Params.Arguments = request.GetArguments()["arguments"]
+	return handler(ctx, call)
+}
+
+// searchRoutes returns the routes matching every query term and the tag,
+// best matches first. Name and tag hits rank above path and description hits.
+func searchRoutes(catalog map[string]*parser.RouteTool, query, tag string, limit int) []*parser.RouteTool {
+	terms := strings.Fields(strings.ToLower(query))
+
+	type scored struct {
+		route *parser.RouteTool
+		score int
+	}
+	var results []scored
+	for _, route := range catalog {
+		tags := strings.ToLower(strings.Join(route.RouteConfig.Tags, " "))
+		if tag != "" && !hasTag(route.RouteConfig.Tags, tag) {
+			continue
+		}
+
+		name := strings.ToLower(route.Tool.Name)
+		text := strings.ToLower(route.RouteConfig.Path + " " + route.RouteConfig.Description)
+		score := 0
+		for _, term := range terms {
+			switch {
+			case strings.Contains(name, term):
+				score += 3
+			case strings.Contains(tags, term):
+				score += 2
+			case strings.Contains(text, term):
+				score++
+			default:
+				score = -1
+			}
+			if score < 0 {
+				break
+			}
+		}
+		if score >= 0 {
+			results = append(results, scored{route: route, score: score})
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].score != results[j].score {
+			return results[i].score > results[j].score
+		}
+		return results[i].route.Tool.Name < results[j].route.Tool.Name
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
+	routes := make([]*parser.RouteTool, 0, len(results))
+	for _, result := range results {
+		routes = append(routes, result.route)
+	}
+	return routes
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/brizzai/auto-mcp/internal/workspace"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// payloadURIPrefix is the URI prefix of stored response payloads
+const payloadURIPrefix = "auto-mcp://payloads/"
+
+// payloadFilePrefix prefixes the workspace files holding payloads
+const payloadFilePrefix = "payload-"
+
+// payloadMIMETypes are the MIME types payloads are served with, by format
+var payloadMIMETypes = map[string]string{
+	"csv":    "text/csv",
+	"tsv":    "text/tab-separated-values",
+	"ndjson": "application/x-ndjson",
+}
+
+// payloadStore keeps response payloads as workspace files, which expire
+// with the workspace TTL
+type payloadStore struct {
+	ws *workspace.Workspace
+}
+
+// Save writes data to a new workspace file and returns its resource URI.
+// File names carry a random token so URIs cannot be guessed.
+func (p payloadStore) Save(data []byte, format string) (string, error) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	file, err := p.ws.CreateFile(payloadFilePrefix + hex.EncodeToString(token) + "-*." + format)
+	if err != nil {
+		return "", err
+	}
+	if _, err := file.Write(data); err != nil {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+	return payloadURIPrefix + filepath.Base(file.Name()), nil
+}
+
+// read returns the payload of uri and its MIME type
+func (p payloadStore) read(uri string) ([]byte, string, error) {
+	name := strings.TrimPrefix(uri, payloadURIPrefix)
+	if name == uri || name != filepath.Base(name) || !strings.HasPrefix(name, payloadFilePrefix) {
+		return nil, "", fmt.Errorf("unknown payload %s", uri)
+	}
+	data, err := os.ReadFile(filepath.Join(p.ws.Dir(), name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, "", fmt.Errorf("payload %s has expired", uri)
+		}
+		return nil, "", err
+	}
+	return data, payloadMIMETypes[strings.TrimPrefix(filepath.Ext(name), ".")], nil
+}
+
+// UseWorkspace keeps the full payload of truncated tabular responses in ws
+// and serves them as MCP resources, when endpoint.tabular_responses.resources
+// is set. It must be called before Start.
+func (s *Server) UseWorkspace(ws *workspace.Workspace) {
+	if !s.config.EndpointConfig.TabularResponses.Resources {
+		return
+	}
+	store := payloadStore{ws: ws}
+	s.tool.SetPayloadStore(store)
+	s.mcp.AddResourceTemplate(
+		mcp.NewResourceTemplate(payloadURIPrefix+"{name}", "Response payloads",
+			mcp.WithTemplateDescription("Full payloads of tool results that were truncated, kept for a limited time"),
+		),
+		func(_ context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			data, mimeType, err := store.read(request.Params.URI)
+			if err != nil {
+				return nil, err
+			}
+			return []mcp.ResourceContents{mcp.TextResourceContents{
+				URI:      request.Params.URI,
+				MIMEType: mimeType,
+				Text:     string(data),
+			}}, nil
+		},
+	)
+}
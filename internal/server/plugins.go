@@ -0,0 +1,26 @@
+package server
+
+import (
+	"github.com/brizzai/auto-mcp/internal/parser"
+	"github.com/brizzai/auto-mcp/pkg/hooks"
+)
+
+// filterPluginTools drops the routes a plugin tool filter excludes
+func (s *Server) filterPluginTools(routes []*parser.RouteTool) []*parser.RouteTool {
+	if s.plugins == nil {
+		return routes
+	}
+	kept := routes[:0]
+	for _, route := range routes {
+		if s.plugins.IncludeTool(hooks.Tool{
+			Name:        route.Tool.Name,
+			Method:      route.RouteConfig.Method,
+			Path:        route.RouteConfig.Path,
+			Description: route.Tool.Description,
+			Tags:        route.RouteConfig.Tags,
+		}) {
+			kept = append(kept, route)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,70 @@
+// Package prompt turns workflow definitions from the adjustments file into MCP prompts.
+package prompt
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/brizzai/auto-mcp/internal/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// placeholder matches {{argument}} references in a prompt template
+var placeholder = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_.-]+)\s*\}\}`)
+
+// New creates the MCP prompt and handler for a workflow definition
+func New(def models.Prompt) server.ServerPrompt {
+	opts := []mcp.PromptOption{mcp.WithPromptDescription(def.Description)}
+	for _, arg := range def.Arguments {
+		argOpts := []mcp.ArgumentOption{mcp.ArgumentDescription(arg.Description)}
+		if arg.Required {
+			argOpts = append(argOpts, mcp.RequiredArgument())
+		}
+		opts = append(opts, mcp.WithArgument(arg.Name, argOpts...))
+	}
+
+	return server.ServerPrompt{
+		Prompt: mcp.NewPrompt(def.Name, opts...),
+		Handler: func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			text, err := Render(def, request.Params.Arguments)
+			if err != nil {
+				return nil, err
+			}
+			return mcp.NewGetPromptResult(def.Description, []mcp.PromptMessage{
+				mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(text)),
+			}), nil
+		},
+	}
+}
+
+// Render builds the prompt text for the given argument values
+func Render(def models.Prompt, args map[string]string) (string, error) {
+	for _, arg := range def.Arguments {
+		if arg.Required && args[arg.Name] == "" {
+			return "", fmt.Errorf("missing required argument %q", arg.Name)
+		}
+	}
+
+	var b strings.Builder
+	if def.Template != "" {
+		b.WriteString(placeholder.ReplaceAllStringFunc(def.Template, func(match string) string {
+			return args[placeholder.FindStringSubmatch(match)[1]]
+		}))
+	} else {
+		// Without a template, describe the goal and the inputs provided
+		b.WriteString(def.Description)
+		for _, arg := range def.Arguments {
+			if value := args[arg.Name]; value != "" {
+				fmt.Fprintf(&b, "\n- %s: %s", arg.Name, value)
+			}
+		}
+	}
+
+	if len(def.Tools) > 0 {
+		fmt.Fprintf(&b, "\n\nUse these tools, in this order where it applies: %s.", strings.Join(def.Tools, ", "))
+	}
+	return strings.TrimSpace(b.String()), nil
+}
@@ -0,0 +1,55 @@
+package prompt
+
+import (
+	"context"
+	"testing"
+
+	"github.com/brizzai/auto-mcp/internal/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var createOrder = models.Prompt{
+	Name:        "create_order",
+	Description: "Create an order end-to-end",
+	Arguments: []models.PromptArgument{
+		{Name: "customer_email", Description: "Customer placing the order", Required: true},
+		{Name: "item", Description: "What to order"},
+	},
+	Tools:    []string{"get_users", "post_orders"},
+	Template: "Find the customer {{customer_email}} and order {{ item }} for them.",
+}
+
+func TestRender(t *testing.T) {
+	text, err := Render(createOrder, map[string]string{"customer_email": "a@example.com", "item": "a book"})
+	require.NoError(t, err)
+	assert.Equal(t, "Find the customer a@example.com and order a book for them.\n\n"+
+		"Use these tools, in this order where it applies: get_users, post_orders.", text)
+
+	_, err = Render(createOrder, map[string]string{"item": "a book"})
+	assert.ErrorContains(t, err, "customer_email")
+}
+
+func TestRenderWithoutTemplate(t *testing.T) {
+	def := createOrder
+	def.Template = ""
+	text, err := Render(def, map[string]string{"customer_email": "a@example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, "Create an order end-to-end\n- customer_email: a@example.com\n\n"+
+		"Use these tools, in this order where it applies: get_users, post_orders.", text)
+}
+
+func TestNew(t *testing.T) {
+	entry := New(createOrder)
+	assert.Equal(t, "create_order", entry.Prompt.Name)
+	require.Len(t, entry.Prompt.Arguments, 2)
+	assert.True(t, entry.Prompt.Arguments[0].Required)
+
+	request := mcp.GetPromptRequest{}
+	request.Params.Arguments = map[string]string{"customer_email": "a@example.com", "item": "a book"}
+	result, err := entry.Handler(context.Background(), request)
+	require.NoError(t, err)
+	require.Len(t, result.Messages, 1)
+	assert.Equal(t, mcp.RoleUser, result.Messages[0].Role)
+}
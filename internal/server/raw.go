@@ -0,0 +1,176 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/brizzai/auto-mcp/internal/config"
+	"github.com/brizzai/auto-mcp/internal/logger"
+	"github.com/brizzai/auto-mcp/internal/requester"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+// RawRequestTool is the escape-hatch tool for operations missing from the spec
+const RawRequestTool = "http_request"
+
+var rawRequestMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE"}
+
+// setupRawRequestTool registers http_request, which calls any allowed path
+// under the base URL with the configured authentication
+func (s *Server) setupRawRequestTool() {
+	cfg := s.config.EndpointConfig.RawRequest
+	methods := rawRequestMethods
+	if len(cfg.AllowedMethods) > 0 {
+		methods = make([]string, 0, len(cfg.AllowedMethods))
+		for _, method := range cfg.AllowedMethods {
+			methods = append(methods, strings.ToUpper(method))
+		}
+	}
+
+	description := "Sends an HTTP request to any path of the API, for operations that have no dedicated tool. Prefer the dedicated tools when they exist."
+	if len(cfg.AllowedPaths) > 0 {
+		description += " Allowed paths: " + strings.Join(cfg.AllowedPaths, ", ")
+	}
+
+	s.AddTools(mcpserver.ServerTool{
+		Tool: mcp.NewTool(s.toolName(RawRequestTool),
+			mcp.WithDescription(description),
+			mcp.WithString("method", mcp.Required(), mcp.Enum(methods...), mcp.Description("HTTP method")),
+			mcp.WithString("path", mcp.Required(), mcp.Description("Path relative to the API base URL, e.g. /v2/orders/42")),
+			mcp.WithObject("query", mcp.Description("Query parameters")),
+			mcp.WithObject("body", mcp.Description("JSON request body")),
+		),
+		Handler: s.handleRawRequest,
+	})
+	logger.Info("Registered raw request tool")
+}
+
+func (s *Server) handleRawRequest(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	cfg := s.config.EndpointConfig.RawRequest
+	method := strings.ToUpper(request.GetString("method", ""))
+	requestPath := request.GetString("path", "")
+
+	if !rawMethodAllowed(cfg, method) {
+		return mcp.NewToolResultError(fmt.Sprintf("Method %s is not allowed", method)), nil
+	}
+	if err := validateRawPath(requestPath); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	// Checked once decoded, so %2F cannot widen a * in the allowed paths.
+	// validateRawPath rejected invalid escapes.
+	decodedPath, _ := url.PathUnescape(requestPath)
+	if !rawPathAllowed(cfg, decodedPath) {
+		return mcp.NewToolResultError(fmt.Sprintf("Path %s is not in the allowed paths", requestPath)), nil
+	}
+
+	args := request.GetArguments()
+	if query, ok := args["query"].(map[string]interface{}); ok && len(query) > 0 {
+		values := url.Values{}
+		for key, value := range query {
+			values.Set(key, fmt.Sprint(value))
+		}
+		requestPath += "?" + values.Encode()
+	}
+
+	// Placeholders in the path are not expanded, so braces cannot smuggle in arguments
+	route := &requester.RouteConfig{
+		Path:    strings.NewReplacer("{", "%7B", "}", "%7D").Replace(requestPath),
+		Method:  method,
+		Headers: map[string]string{"Content-Type": "application/json"},
+	}
+	executor, err := s.requester.BuildRouteExecutor(route)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build raw request executor: %w", err)
+	}
+
+	params := map[string]interface{}{}
+	if body, ok := args["body"]; ok && body != nil {
+		params["body"] = body
+		if method == "DELETE" {
+			// Other methods send the parameters themselves as the body
+			params, _ = body.(map[string]interface{})
+		}
+	} else if method != "GET" {
+		params = nil
+	}
+
+	tool := mcp.NewTool(s.toolName(RawRequestTool))
+	call := mcp.CallToolRequest{}
+	call.Params.Name = tool.Name
+	call.Params.Arguments = params
+	return s.tool.CreateHandler(&tool, route, executor)(ctx, call)
+}
+
+// validateRawPath rejects paths that could escape the base URL, as sent and
+// percent-decoded, since the upstream may decode e.g. %2e%2e/ to ../
+func validateRawPath(p string) error {
+	if err := checkRawPath(p); err != nil {
+		return err
+	}
+	decoded, err := url.PathUnescape(p)
+	if err != nil {
+		return fmt.Errorf("path has invalid percent-encoding: %w", err)
+	}
+	return checkRawPath(decoded)
+}
+
+// checkRawPath rejects paths that are not a single clean absolute path
+func checkRawPath(p string) error {
+	if !strings.HasPrefix(p, "/") || strings.HasPrefix(p, "//") {
+		return fmt.Errorf("path must be relative to the base URL and start with a single /")
+	}
+	if strings.ContainsAny(p, "?#\\") || strings.ContainsFunc(p, func(r rune) bool { return r < 0x20 || r == 0x7f }) {
+		return fmt.Errorf("path must not contain query strings, fragments, backslashes or control characters; use the query argument")
+	}
+	if cleaned := path.Clean(p); cleaned != p && cleaned+"/" != p {
+		return fmt.Errorf("path must not contain . or .. segments")
+	}
+	return nil
+}
+
+func rawMethodAllowed(cfg config.RawRequestConfig, method string) bool {
+	allowed := cfg.AllowedMethods
+	if len(allowed) == 0 {
+		allowed = rawRequestMethods
+	}
+	for _, m := range allowed {
+		if strings.EqualFold(m, method) {
+			return true
+		}
+	}
+	return false
+}
+
+func rawPathAllowed(cfg config.RawRequestConfig, p string) bool {
+	if len(cfg.AllowedPaths) == 0 {
+		return true
+	}
+	for _, pattern := range cfg.AllowedPaths {
+		if prefix, ok := strings.CutSuffix(pattern, "/**"); ok {
+			if matchPrefix(prefix, p) {
+				return true
+			}
+			continue
+		}
+		if matched, _ := path.Match(pattern, p); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPrefix reports whether the leading segments of p match pattern
+func matchPrefix(pattern, p string) bool {
+	segments := strings.Count(pattern, "/")
+	parts := strings.SplitAfterN(p, "/", segments+2)
+	if len(parts) <= segments {
+		return false
+	}
+	head := strings.TrimSuffix(strings.Join(parts[:segments+1], ""), "/")
+	matched, _ := path.Match(pattern, head)
+	return matched
+}
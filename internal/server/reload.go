@@ -0,0 +1,207 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"os/signal"
+	"reflect"
+	"time"
+
+	"github.com/brizzai/auto-mcp/internal/config"
+	"github.com/brizzai/auto-mcp/internal/logger"
+	"github.com/brizzai/auto-mcp/internal/parser"
+	"go.uber.org/zap"
+)
+
+// watchInterval is how often watched files are checked for changes
+const watchInterval = 2 * time.Second
+
+// fileStamp identifies a version of a watched file
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// Reload re-reads the configuration, the spec and the adjustments file and
+// applies them without dropping sessions: tools are added, replaced and
+// removed, prompts are re-registered, and the log level and rate limits are
+// updated. Other changed settings are reported and need a restart. If
+// anything fails to load the running server is left unchanged.
+func (s *Server) Reload() error {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	cfg, err := s.loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if s.secrets != nil {
+		if err := s.secrets.Resolve(context.Background(), cfg); err != nil {
+			return fmt.Errorf("failed to resolve secrets: %w", err)
+		}
+	}
+	// The parser accumulates operations, so every reload needs a fresh one
+	p := s.newParser(cfg)
+	if err := p.Init(cfg.SwaggerFile, cfg.AdjustmentsFile); err != nil {
+		return fmt.Errorf("failed to initialize parser: %w", err)
+	}
+	if cfg.Logging.Level != s.config.Logging.Level {
+		if err := logger.SetLevel(cfg.Logging.Level); err != nil {
+			return err
+		}
+	}
+
+	routes := p.GetRouteTools()
+	for _, route := range routes {
+		route.Tool.Name = s.toolName(route.Tool.Name)
+	}
+	routes = s.filterPluginTools(routes)
+	s.completions.setRoutes(routes, s.config.EndpointConfig.Headers)
+	if s.config.Server.LazyTools {
+		s.setupLazyTools(routes)
+		s.reloadRouteTools(routes, true)
+	} else {
+		s.reloadRouteTools(routes, false)
+	}
+	s.parser = p
+	s.setupCompositeTools(routes)
+	s.setupPrompts(routes)
+
+	s.handler.UpdateRateLimits(cfg.Server.RateLimit)
+	for _, section := range restartRequired(s.config, cfg) {
+		logger.Warn("Configuration change requires a restart", zap.String("section", section))
+	}
+
+	s.config.SwaggerFile = cfg.SwaggerFile
+	s.config.AdjustmentsFile = cfg.AdjustmentsFile
+	s.config.Logging.Level = cfg.Logging.Level
+	s.config.Server.RateLimit = cfg.Server.RateLimit
+	s.config.Files = cfg.Files
+	logger.Info("Reloaded configuration", zap.Int("operations", len(routes)))
+	return nil
+}
+
+// reloadRouteTools registers the reloaded routes and removes route tools whose
+// operation no longer exists. In lazy mode only tools that were already enabled
+// are replaced.
+func (s *Server) reloadRouteTools(routes []*parser.RouteTool, onlyEnabled bool) {
+	s.toolsMu.Lock()
+	current := make(map[string]bool, len(s.methods))
+	for name := range s.methods {
+		current[name] = true
+	}
+	s.toolsMu.Unlock()
+
+	for _, route := range routes {
+		if onlyEnabled && !current[route.Tool.Name] {
+			continue
+		}
+		delete(current, route.Tool.Name)
+		if err := s.AddRouteTool(route); err != nil {
+			logger.Error("Failed to register tool", zap.String("tool", route.Tool.Name), zap.Error(err))
+		}
+	}
+	for name := range current {
+		if err := s.RemoveTool(name); err != nil {
+			logger.Error("Failed to remove tool", zap.String("tool", name), zap.Error(err))
+		}
+	}
+}
+
+// restartRequired returns the changed configuration sections that are only
+// read at startup
+func restartRequired(old, updated *config.Config) []string {
+	oldServer, updatedServer := old.Server, updated.Server
+	oldServer.RateLimit, updatedServer.RateLimit = config.RateLimitConfig{}, config.RateLimitConfig{}
+	oldServer.WatchConfig, updatedServer.WatchConfig = false, false
+
+	// disable_console is forced in stdio mode after loading
+	oldLogging, updatedLogging := old.Logging, updated.Logging
+	oldLogging.Level, updatedLogging.Level = "", ""
+	oldLogging.DisableConsole, updatedLogging.DisableConsole = false, false
+
+	sections := []struct {
+		name         string
+		old, updated any
+	}{
+		{"server", oldServer, updatedServer},
+		{"logging", oldLogging, updatedLogging},
+		{"endpoint", old.EndpointConfig, updated.EndpointConfig},
+		{"oauth", old.OAuth, updated.OAuth},
+		{"telemetry", old.Telemetry, updated.Telemetry},
+		{"workspace", old.Workspace, updated.Workspace},
+		{"secrets", old.Secrets, updated.Secrets},
+		{"plugins", old.Plugins, updated.Plugins},
+	}
+	var changed []string
+	for _, section := range sections {
+		if !reflect.DeepEqual(section.old, section.updated) {
+			changed = append(changed, section.name)
+		}
+	}
+	return changed
+}
+
+// watchReload reloads on SIGHUP and, with server.watch_config, when one of the
+// configuration files changes
+func (s *Server) watchReload(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	notifyReload(hup)
+	defer signal.Stop(hup)
+
+	var tick <-chan time.Time
+	var stamps map[string]fileStamp
+	if s.config.Server.WatchConfig {
+		ticker := time.NewTicker(watchInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+		stamps = s.watchedFiles()
+		logger.Info("Watching configuration files for changes", zap.Int("files", len(stamps)))
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			logger.Info("Received SIGHUP, reloading configuration")
+		case <-tick:
+			current := s.watchedFiles()
+			if maps.Equal(current, stamps) {
+				continue
+			}
+			stamps = current
+			logger.Info("Configuration files changed, reloading")
+		}
+		if err := s.Reload(); err != nil {
+			logger.Error("Failed to reload configuration, keeping the previous one", zap.Error(err))
+		}
+		if tick != nil {
+			// The reloaded configuration may point at other files
+			stamps = s.watchedFiles()
+		}
+	}
+}
+
+// watchedFiles stats the configuration, spec and adjustments files; missing
+// files get a zero stamp so their reappearance is noticed
+func (s *Server) watchedFiles() map[string]fileStamp {
+	s.reloadMu.Lock()
+	files := append([]string{s.config.SwaggerFile, s.config.AdjustmentsFile}, s.config.Files...)
+	s.reloadMu.Unlock()
+
+	stamps := make(map[string]fileStamp, len(files))
+	for _, file := range files {
+		if file == "" {
+			continue
+		}
+		var stamp fileStamp
+		if info, err := os.Stat(file); err == nil {
+			stamp = fileStamp{modTime: info.ModTime(), size: info.Size()}
+		}
+		stamps[file] = stamp
+	}
+	return stamps
+}
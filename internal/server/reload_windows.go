@@ -0,0 +1,6 @@
+package server
+
+import "os"
+
+// notifyReload is a no-op, Windows has no SIGHUP. Use server.watch_config instead.
+func notifyReload(chan<- os.Signal) {}
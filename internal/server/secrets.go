@@ -0,0 +1,46 @@
+package server
+
+import (
+	"github.com/brizzai/auto-mcp/internal/logger"
+	"github.com/brizzai/auto-mcp/internal/secrets"
+	"go.uber.org/zap"
+)
+
+// UseSecrets keeps the credentials resolved by r up to date while the server
+// runs and resolves them again on reload. It must be called before Start.
+func (s *Server) UseSecrets(r *secrets.Resolver) {
+	s.secrets = r
+}
+
+// updateCredentials applies refreshed credentials to the upstream requests
+// and the OAuth provider. Session logins keep the credentials they started
+// with until a restart.
+func (s *Server) updateCredentials(creds secrets.Credentials) {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	if err := s.requester.UpdateAuthConfig("", creds.AuthConfig); err != nil {
+		logger.Warn("Failed to update endpoint credentials", zap.Error(err))
+	}
+	s.config.EndpointConfig.AuthConfig = creds.AuthConfig
+
+	for i := range s.config.EndpointConfig.Tenants {
+		tenant := &s.config.EndpointConfig.Tenants[i]
+		authConfig, ok := creds.TenantAuthConfig[tenant.Name]
+		if ok {
+			tenant.AuthConfig = authConfig
+		} else {
+			// Tenants without their own auth_config use the endpoint's
+			authConfig = creds.AuthConfig
+		}
+		if err := s.requester.UpdateAuthConfig(tenant.Name, authConfig); err != nil {
+			logger.Warn("Failed to update tenant credentials", zap.String("tenant", tenant.Name), zap.Error(err))
+		}
+	}
+
+	if s.auth != nil && creds.ClientSecret != s.config.OAuth.ClientSecret {
+		if s.auth.SetClientSecret(creds.ClientSecret) {
+			s.config.OAuth.ClientSecret = creds.ClientSecret
+		}
+	}
+}
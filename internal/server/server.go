@@ -4,27 +4,37 @@ package server
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/brizzai/auto-mcp/internal/analytics"
 	"github.com/brizzai/auto-mcp/internal/auth"
+	"github.com/brizzai/auto-mcp/internal/auth/policy"
 	"github.com/brizzai/auto-mcp/internal/auth/providers"
 	"github.com/brizzai/auto-mcp/internal/config"
 	"github.com/brizzai/auto-mcp/internal/logger"
+	"github.com/brizzai/auto-mcp/internal/models"
 	"github.com/brizzai/auto-mcp/internal/parser"
+	"github.com/brizzai/auto-mcp/internal/plugins"
 	"github.com/brizzai/auto-mcp/internal/requester"
+	"github.com/brizzai/auto-mcp/internal/secrets"
+	"github.com/brizzai/auto-mcp/internal/server/builtin"
 	"github.com/brizzai/auto-mcp/internal/server/handler"
+	"github.com/brizzai/auto-mcp/internal/server/prompt"
 	"github.com/brizzai/auto-mcp/internal/server/tool"
+	"github.com/mark3labs/mcp-go/mcp"
 	mcpserver "github.com/mark3labs/mcp-go/server"
 	"go.uber.org/fx"
 	"go.uber.org/zap"
 )
 
-const (
-	// shutdownTimeout is the maximum time to wait for server shutdown
-	shutdownTimeout = 5 * time.Second
-)
+// drainPollInterval is how often shutdown checks for finished tool calls
+const drainPollInterval = 50 * time.Millisecond
 
 // ErrInvalidOAuthProvider indicates an unsupported OAuth provider was specified
 var ErrInvalidOAuthProvider = fmt.Errorf("unsupported OAuth provider")
@@ -40,6 +50,51 @@ type Server struct {
 	auth      *auth.Service
 	handler   *handler.Handler
 	tool      *tool.Handler
+	policy    *policy.Policy
+	tenants   *policy.Tenants
+
+	// tools holds every registered tool, including disabled ones, so they
+	// can be toggled at runtime
+	toolsMu  sync.Mutex
+	tools    map[string]mcpserver.ServerTool
+	disabled map[string]bool
+	// methods holds the upstream HTTP method of route tools for authorization
+	methods map[string]string
+
+	// catalog holds every parsed operation in lazy mode, by tool name
+	catalog map[string]*parser.RouteTool
+	// composites holds the names of the registered composite tools
+	composites map[string]bool
+
+	// reloadMu serializes Reload, which uses loadConfig and newParser to
+	// read the configuration and spec again
+	reloadMu   sync.Mutex
+	loadConfig func() (*config.Config, error)
+	newParser  func(cfg *config.Config) parser.Parser
+
+	// activeCalls counts running tool calls so shutdown can wait for them
+	activeCalls atomic.Int64
+
+	// secrets resolves vault:// credentials, nil when none are configured
+	secrets *secrets.Resolver
+
+	// plugins runs the configured plugin hooks, nil when none are configured
+	plugins *plugins.Hooks
+
+	// analytics records per-tool usage, nil unless server.analytics is enabled
+	analytics *analytics.Recorder
+
+	// baseURLs holds the upstream base URL override of each session
+	baseURLs sessionBaseURLs
+
+	// httpSessions ends idle streamable HTTP sessions, nil unless
+	// server.streamable_http.session_idle_timeout is set
+	httpSessions *idleSessions
+
+	// completions suggests argument values to clients
+	completions *completer
+	// prompts holds the registered prompts by name, with prefixed tool names
+	prompts map[string]models.Prompt
 }
 
 // NewServer creates a new MCP server instance with the provided configuration.
@@ -56,17 +111,50 @@ func NewServer(cfg *config.Config, p parser.Parser, requester *requester.HTTPReq
 		logger.Fatal("Requester cannot be nil")
 	}
 
-	mcpServer := mcpserver.NewMCPServer(
-		cfg.Server.Name,
-		cfg.Server.Version,
-	)
-
 	srv := &Server{
-		config:    cfg,
-		parser:    p,
-		mcp:       mcpServer,
-		requester: requester,
+		config:     cfg,
+		parser:     p,
+		requester:  requester,
+		tools:      make(map[string]mcpserver.ServerTool),
+		disabled:   make(map[string]bool),
+		methods:    make(map[string]string),
+		loadConfig: config.Load,
+		newParser: func(cfg *config.Config) parser.Parser {
+			adjuster := parser.NewAdjuster()
+			adjuster.SetLocale(cfg.Server.Locale)
+			return parser.NewSwaggerParser(adjuster)
+		},
+	}
+
+	hooks := &mcpserver.Hooks{}
+	hooks.AddAfterInitialize(advertiseCompletions)
+	hooks.AddAfterListTools(srv.filterListedTools)
+	(&preciseArguments{}).register(hooks)
+	opts := []mcpserver.ServerOption{
+		mcpserver.WithToolCapabilities(true),
+		mcpserver.WithHooks(hooks),
+		mcpserver.WithToolHandlerMiddleware(srv.trackCalls),
+	}
+	opts = append(opts, srv.setupBaseURLOverride(hooks)...)
+	if h := cfg.Server.StreamableHTTP; !h.Stateless && h.SessionIdleTimeout > 0 {
+		srv.httpSessions = newIdleSessions(h.SessionIdleTimeout)
+		hooks.AddOnRegisterSession(srv.httpSessions.streamOpened)
+		hooks.AddOnUnregisterSession(srv.httpSessions.streamClosed)
+	}
+	srv.mcp = mcpserver.NewMCPServer(cfg.Server.Name, cfg.Server.Version, opts...)
+
+	pluginHooks, err := plugins.Load(cfg.Plugins)
+	if err != nil {
+		logger.Fatal("Failed to load plugins", zap.Error(err))
+	}
+	srv.plugins = pluginHooks
+	requester.SetHooks(pluginHooks)
+
+	recorder, err := analytics.New(cfg.Server.Analytics)
+	if err != nil {
+		logger.Fatal("Failed to load tool analytics", zap.Error(err))
 	}
+	srv.analytics = recorder
 
 	if cfg.OAuth != nil && cfg.OAuth.Enabled {
 		if err := srv.setupAuth(); err != nil {
@@ -75,8 +163,20 @@ func NewServer(cfg *config.Config, p parser.Parser, requester *requester.HTTPReq
 	}
 
 	// Initialize handlers
-	srv.handler = handler.NewHandler(srv.auth)
-	srv.tool = tool.NewHandler(srv.auth != nil)
+	srv.handler = handler.NewHandler(srv.auth, &cfg.Server)
+	if cfg.Server.Admin.Token != "" {
+		srv.handler.SetAdminHandler(srv.adminHandler())
+	}
+	if srv.auth == nil && len(cfg.EndpointConfig.Tenants) > 0 {
+		logger.Warn("Ignoring endpoint.tenants: tenants are resolved from the authenticated user and oauth is disabled")
+	}
+	srv.tool = tool.NewHandler(cfg, srv.auth != nil, srv.policy, srv.tenants)
+
+	completions, err := newCompleter(cfg.EndpointConfig.Completions, srv.requester)
+	if err != nil {
+		logger.Fatal("Failed to setup completions", zap.Error(err))
+	}
+	srv.completions = completions
 
 	if err := srv.setupTools(); err != nil {
 		logger.Fatal("Failed to setup tools", zap.Error(err))
@@ -94,6 +194,10 @@ func (s *Server) setupAuth() error {
 		provider, err = providers.NewGoogleProvider(s.config.OAuth)
 	case "github":
 		provider = providers.NewGitHubProvider(s.config.OAuth)
+	case "oidc":
+		provider, err = providers.NewOIDCProvider(context.Background(), s.config.OAuth)
+	case "internal":
+		provider, err = providers.NewInternalProvider(s.config.OAuth)
 	default:
 		return fmt.Errorf("%w: %s", ErrInvalidOAuthProvider, s.config.OAuth.Provider)
 	}
@@ -102,12 +206,27 @@ func (s *Server) setupAuth() error {
 		return fmt.Errorf("failed to initialize provider %s: %w", s.config.OAuth.Provider, err)
 	}
 
-	authService, err := auth.NewService(s.config.OAuth, provider)
+	authService, err := auth.NewService(s.config.OAuth, provider, &s.config.Server)
 	if err != nil {
 		return fmt.Errorf("failed to create auth service: %w", err)
 	}
 
+	authPolicy, err := policy.New(s.config.OAuth.Policy)
+	if err != nil {
+		return fmt.Errorf("invalid oauth.policy: %w", err)
+	}
+	if authPolicy != nil {
+		logger.Info("Enabled tool authorization policy", zap.Int("rules", len(s.config.OAuth.Policy.Rules)))
+	}
+
+	tenants, err := policy.NewTenants(s.config.EndpointConfig.Tenants)
+	if err != nil {
+		return fmt.Errorf("invalid endpoint.tenants: %w", err)
+	}
+
 	s.auth = authService
+	s.policy = authPolicy
+	s.tenants = tenants
 	return nil
 }
 
@@ -118,37 +237,176 @@ func (s *Server) setupTools() error {
 
 	routes := s.parser.GetRouteTools()
 	for _, route := range routes {
-		tool := route.Tool
-		executor, err := s.requester.BuildRouteExecutor(route.RouteConfig)
-		if err != nil {
-			logger.Error("Failed to build route executor", zap.String("tool", tool.Name), zap.Error(err))
-			continue
+		route.Tool.Name = s.toolName(route.Tool.Name)
+	}
+	routes = s.filterPluginTools(routes)
+
+	s.completions.setRoutes(routes, s.config.EndpointConfig.Headers)
+	if s.config.Server.LazyTools {
+		s.setupLazyTools(routes)
+	} else {
+		for _, route := range routes {
+			if err := s.AddRouteTool(route); err != nil {
+				logger.Error("Failed to register tool", zap.String("tool", route.Tool.Name), zap.Error(err))
+			}
 		}
+	}
 
-		s.mcp.AddTool(tool, s.tool.CreateHandler(&tool, executor))
+	if s.config.EndpointConfig.RawRequest.Enabled {
+		s.setupRawRequestTool()
 	}
+
+	if s.config.Server.HelperTools {
+		helpers := builtin.Tools()
+		for i := range helpers {
+			helpers[i].Tool.Name = s.toolName(helpers[i].Tool.Name)
+		}
+		s.AddTools(helpers...)
+		logger.Info("Registered built-in helper tools")
+	}
+
+	s.setupCompositeTools(routes)
+	s.setupPrompts(routes)
 	return nil
 }
 
+// toolName applies the configured tool prefix
+func (s *Server) toolName(name string) string {
+	return s.config.Server.ToolPrefix + name
+}
+
+// setupPrompts registers the workflow prompts from the adjustments file
+func (s *Server) setupPrompts(routes []*parser.RouteTool) {
+	defs := s.parser.GetPrompts()
+	registered := make(map[string]models.Prompt, len(defs))
+	defer func() {
+		s.toolsMu.Lock()
+		s.prompts = registered
+		s.toolsMu.Unlock()
+	}()
+	if len(defs) == 0 {
+		return
+	}
+
+	toolNames := make(map[string]bool, len(routes))
+	for _, route := range routes {
+		toolNames[route.Tool.Name] = true
+	}
+	s.toolsMu.Lock()
+	for name := range s.composites {
+		toolNames[name] = true
+	}
+	s.toolsMu.Unlock()
+
+	prompts := make([]mcpserver.ServerPrompt, 0, len(defs))
+	for _, def := range defs {
+		// Prompts reference tools by their unprefixed names
+		tools := make([]string, 0, len(def.Tools))
+		for _, name := range def.Tools {
+			name = s.toolName(name)
+			tools = append(tools, name)
+			if !toolNames[name] {
+				logger.Warn("Prompt references an unknown tool",
+					zap.String("prompt", def.Name),
+					zap.String("tool", name),
+				)
+			}
+		}
+		def.Tools = tools
+		registered[def.Name] = def
+		prompts = append(prompts, prompt.New(def))
+	}
+	s.mcp.AddPrompts(prompts...)
+	logger.Info("Registered prompts", zap.Int("count", len(prompts)))
+}
+
 func (s *Server) ServeSSE(ctx context.Context) error {
-	logger.Info("Starting SSE server")
+	sse := s.config.Server.SSE
+	logger.Info("Starting SSE server",
+		zap.Duration("keep_alive_interval", sse.KeepAliveInterval),
+		zap.Duration("ping_interval", sse.PingInterval),
+		zap.Duration("resume_window", sse.ResumeWindow),
+	)
+
+	return s.serveHTTP(ctx, s.sseHandler(), "SSE")
+}
 
-	sseServer := mcpserver.NewSSEServer(s.mcp)
+// sseHandler serves /sse and /message. Routes are mounted under the base path
+// by the HTTP handler, so the prefix only needs to be advertised to clients.
+func (s *Server) sseHandler() http.Handler {
+	advertisedPath := s.config.Server.MountPath()
+	var opts []mcpserver.SSEOption
+	if publicURL := s.config.Server.PublicURL; publicURL != "" {
+		// The public URL already includes any prefix the client must use
+		advertisedPath = ""
+		opts = append(opts, mcpserver.WithBaseURL(strings.TrimSuffix(publicURL, "/")))
+	}
+	opts = append(opts, mcpserver.WithDynamicBasePath(func(*http.Request, string) string {
+		return advertisedPath
+	}))
+	opts = append(opts, mcpserver.WithSSEContextFunc(s.baseURLContext))
+	if interval := s.config.Server.SSE.PingInterval; interval > 0 {
+		opts = append(opts, mcpserver.WithKeepAliveInterval(interval))
+	}
+	sseServer := mcpserver.NewSSEServer(s.mcp, opts...)
 
-	return s.serveHTTP(ctx, sseServer, "SSE")
+	mux := http.NewServeMux()
+	mux.Handle("/sse", newSSEStreams(s.config.Server.SSE).wrap(sseServer.SSEHandler()))
+	mux.Handle("/message", s.completionMessages(sseServer, sseServer.MessageHandler()))
+	return mux
 }
 
 func (s *Server) ServeHTTP(ctx context.Context) error {
-	logger.Info("Starting HTTP server")
-	httpServer := mcpserver.NewStreamableHTTPServer(s.mcp)
-	return s.serveHTTP(ctx, httpServer, "HTTP")
+	h := s.config.Server.StreamableHTTP
+	logger.Info("Starting HTTP server",
+		zap.Bool("stateless", h.Stateless),
+		zap.Duration("session_idle_timeout", h.SessionIdleTimeout),
+		zap.Duration("heartbeat_interval", h.HeartbeatInterval),
+	)
+	return s.serveHTTP(ctx, s.httpHandler(), "HTTP")
+}
+
+// httpHandler serves the streamable HTTP transport
+func (s *Server) httpHandler() http.Handler {
+	h := s.config.Server.StreamableHTTP
+	opts := []mcpserver.StreamableHTTPOption{mcpserver.WithHTTPContextFunc(s.baseURLContext)}
+	switch {
+	case h.Stateless:
+		// WithStateLess ignores its argument, so it is only passed when set
+		opts = append(opts, mcpserver.WithStateLess(true))
+	case s.httpSessions != nil:
+		opts = append(opts, mcpserver.WithSessionIdManager(s.httpSessions))
+	}
+	if h.HeartbeatInterval > 0 {
+		opts = append(opts, mcpserver.WithHeartbeatInterval(h.HeartbeatInterval))
+	}
+	return s.completionHTTP(mcpserver.NewStreamableHTTPServer(s.mcp, opts...))
 }
 
 func (s *Server) serveHTTP(ctx context.Context, handler http.Handler, mode string) error {
 	addr := fmt.Sprintf("%s:%d", s.config.Server.Host, s.config.Server.Port)
+	// Cancelling the base context ends long-lived SSE streams, which
+	// http.Server.Shutdown would otherwise wait for until the timeout
+	streamCtx, closeStreams := context.WithCancel(context.Background())
+	defer closeStreams()
+	limits := s.config.Server.Limits
 	server := &http.Server{
-		Addr:    addr,
-		Handler: s.handler.CreateHTTPHandler(handler),
+		Addr:              addr,
+		Handler:           s.handler.CreateHTTPHandler(handler),
+		BaseContext:       func(net.Listener) context.Context { return streamCtx },
+		ReadHeaderTimeout: limits.ReadHeaderTimeout,
+		ReadTimeout:       limits.ReadTimeout,
+		WriteTimeout:      limits.WriteTimeout,
+		IdleTimeout:       limits.IdleTimeout,
+	}
+
+	tlsEnabled := s.config.Server.TLS.Enabled()
+	if tlsEnabled {
+		tlsConfig, err := newTLSConfig(s.config.Server.TLS)
+		if err != nil {
+			return fmt.Errorf("failed to configure TLS: %w", err)
+		}
+		server.TLSConfig = tlsConfig
 	}
 
 	// Channel for server errors
@@ -159,9 +417,20 @@ func (s *Server) serveHTTP(ctx context.Context, handler http.Handler, mode strin
 		logger.Info("Starting server",
 			zap.String("mode", mode),
 			zap.String("address", addr),
+			zap.Bool("tls", tlsEnabled),
+			zap.Int64("max_body_bytes", limits.MaxBodyBytes),
+			zap.Duration("read_timeout", limits.ReadTimeout),
+			zap.Duration("write_timeout", limits.WriteTimeout),
 		)
 
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		var err error
+		if tlsEnabled {
+			// The certificate comes from TLSConfig.GetCertificate
+			err = server.ListenAndServeTLS("", "")
+		} else {
+			err = server.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			errChan <- fmt.Errorf("server error: %w", err)
 		}
 	}()
@@ -169,13 +438,17 @@ func (s *Server) serveHTTP(ctx context.Context, handler http.Handler, mode strin
 	// Wait for context cancellation or server error
 	select {
 	case <-ctx.Done():
+		timeout := s.config.Server.ShutdownGracePeriod()
 		logger.Info("Shutting down server",
 			zap.String("mode", mode),
-			zap.Duration("timeout", shutdownTimeout),
+			zap.Duration("timeout", timeout),
 		)
-		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
 		defer cancel()
 
+		s.handler.Drain()
+		s.drain(shutdownCtx)
+		closeStreams()
 		if err := server.Shutdown(shutdownCtx); err != nil {
 			return fmt.Errorf("server shutdown error: %w", err)
 		}
@@ -189,7 +462,70 @@ func (s *Server) serveHTTP(ctx context.Context, handler http.Handler, mode strin
 func (s *Server) ServeSTDIO(ctx context.Context) error {
 	logger.Info("Starting STDIO server")
 	stdioServer := mcpserver.NewStdioServer(s.mcp)
-	return stdioServer.Listen(ctx, os.Stdin, os.Stdout)
+
+	// Keep serving after ctx is cancelled until running calls have answered
+	listenCtx, stop := context.WithCancel(context.Background())
+	defer stop()
+	errChan := make(chan error, 1)
+	go func() {
+		in, out := s.completionStdio(listenCtx, os.Stdin, os.Stdout)
+		errChan <- stdioServer.Listen(listenCtx, in, out)
+	}()
+
+	select {
+	case <-ctx.Done():
+		timeout := s.config.Server.ShutdownGracePeriod()
+		logger.Info("Shutting down server", zap.String("mode", "STDIO"), zap.Duration("timeout", timeout))
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		s.drain(shutdownCtx)
+		return nil
+	case err := <-errChan:
+		return err
+	}
+}
+
+// trackCalls counts running tool calls and records their usage when
+// analytics are enabled
+func (s *Server) trackCalls(next mcpserver.ToolHandlerFunc) mcpserver.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		s.activeCalls.Add(1)
+		defer s.activeCalls.Add(-1)
+		if s.analytics == nil {
+			return next(ctx, request)
+		}
+
+		start := time.Now()
+		result, err := next(ctx, request)
+		s.analytics.Record(s.usageName(request), time.Since(start), err != nil || (result != nil && result.IsError))
+		return result, err
+	}
+}
+
+// drain tells connected clients that the server is going away and waits for
+// running tool calls until ctx expires
+func (s *Server) drain(ctx context.Context) {
+	s.mcp.SendNotificationToAllClients("notifications/message", map[string]any{
+		"level":  "warning",
+		"logger": "auto-mcp",
+		"data":   "Server is shutting down, reconnect to continue",
+	})
+
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+	for s.activeCalls.Load() > 0 {
+		select {
+		case <-ctx.Done():
+			logger.Warn("Shutdown timeout reached with tool calls still running", zap.Int64("calls", s.activeCalls.Load()))
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// MCPServer returns the underlying mcp-go server, e.g. to attach an in-process client
+func (s *Server) MCPServer() *mcpserver.MCPServer {
+	return s.mcp
 }
 
 // Start starts the server in the configured mode (SSE, HTTP, or STDIO).
@@ -200,6 +536,26 @@ func (s *Server) Start(ctx context.Context) error {
 		zap.String("mode", string(s.config.Server.Mode)),
 		zap.String("version", s.config.Server.Version),
 	)
+	if s.auth != nil {
+		defer func() {
+			if err := s.auth.Close(); err != nil {
+				logger.Error("Failed to close auth store", zap.Error(err))
+			}
+		}()
+	}
+
+	go s.watchReload(ctx)
+	if s.secrets != nil {
+		go s.secrets.Run(ctx, s.updateCredentials)
+	}
+	if s.analytics != nil {
+		go s.analytics.Run(ctx)
+		defer func() {
+			if err := s.analytics.Save(); err != nil {
+				logger.Error("Failed to save tool analytics", zap.Error(err))
+			}
+		}()
+	}
 
 	switch s.config.Server.Mode {
 	case config.ServerModeSSE:
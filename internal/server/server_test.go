@@ -2,19 +2,30 @@ package server
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/brizzai/auto-mcp/internal/auth/middleware"
+	"github.com/brizzai/auto-mcp/internal/auth/policy"
 	"github.com/brizzai/auto-mcp/internal/config"
+	"github.com/brizzai/auto-mcp/internal/models"
 	"github.com/brizzai/auto-mcp/internal/parser"
 	"github.com/brizzai/auto-mcp/internal/requester"
+	"github.com/brizzai/auto-mcp/internal/server/tool"
+	"github.com/brizzai/auto-mcp/internal/workspace"
 	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
 	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -470,15 +481,770 @@ func TestMCPServer_ToolRegistration(t *testing.T) {
 	assert.True(t, mockParser.initCalled, "Parser Init method should have been called")
 }
 
+func TestMCPServer_RuntimeToolToggling(t *testing.T) {
+	mockParser := &mockParser{
+		tools: []*parser.RouteTool{
+			{
+				RouteConfig: &requester.RouteConfig{Path: "/a", Method: "GET"},
+				Tool:        mcp.NewTool("get_a", mcp.WithDescription("A")),
+			},
+		},
+	}
+	srvCfg := &config.Config{
+		EndpointConfig: config.EndpointConfig{BaseURL: "http://example.com"},
+		Server:         config.ServerConfig{Mode: config.ServerModeSTDIO},
+	}
+	endpointCfg := &srvCfg.EndpointConfig
+	httpRequester := requester.NewHTTPRequester(requester.HTTPRequesterParams{
+		ServiceConfig: endpointCfg,
+		AuthManager:   requester.NewHTTPAuthManager(endpointCfg),
+	})
+	mcpSrv := NewServer(srvCfg, mockParser, httpRequester)
+
+	ctx := context.Background()
+	mcpClient, err := client.NewInProcessClient(mcpSrv.MCPServer())
+	require.NoError(t, err)
+	defer func() { _ = mcpClient.Close() }()
+	require.NoError(t, mcpClient.Start(ctx))
+
+	initReq := mcp.InitializeRequest{}
+	initReq.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initResult, err := mcpClient.Initialize(ctx, initReq)
+	require.NoError(t, err)
+	require.NotNil(t, initResult.Capabilities.Tools)
+	assert.True(t, initResult.Capabilities.Tools.ListChanged)
+
+	// Observe notifications through a registered session
+	session := &testSession{notifications: make(chan mcp.JSONRPCNotification, 10)}
+	require.NoError(t, mcpSrv.MCPServer().RegisterSession(ctx, session))
+
+	listToolNames := func() []string {
+		result, err := mcpClient.ListTools(ctx, mcp.ListToolsRequest{})
+		require.NoError(t, err)
+		names := make([]string, 0, len(result.Tools))
+		for _, tool := range result.Tools {
+			names = append(names, tool.Name)
+		}
+		return names
+	}
+	expectListChanged := func() {
+		select {
+		case notification := <-session.notifications:
+			assert.Equal(t, mcp.MethodNotificationToolsListChanged, notification.Method)
+		case <-time.After(2 * time.Second):
+			t.Fatal("expected a tools/list_changed notification")
+		}
+	}
+
+	// Add a tool at runtime
+	require.NoError(t, mcpSrv.AddRouteTool(&parser.RouteTool{
+		RouteConfig: &requester.RouteConfig{Path: "/b", Method: "GET"},
+		Tool:        mcp.NewTool("get_b", mcp.WithDescription("B")),
+	}))
+	expectListChanged()
+	assert.ElementsMatch(t, []string{"get_a", "get_b"}, listToolNames())
+
+	// Disabled tools stay registered but are hidden from clients
+	require.NoError(t, mcpSrv.DisableTool("get_a"))
+	expectListChanged()
+	assert.Equal(t, []string{"get_b"}, listToolNames())
+	assert.Equal(t, []ToolStatus{{Name: "get_a", Enabled: false}, {Name: "get_b", Enabled: true}}, mcpSrv.Tools())
+
+	require.NoError(t, mcpSrv.EnableTool("get_a"))
+	expectListChanged()
+	assert.ElementsMatch(t, []string{"get_a", "get_b"}, listToolNames())
+
+	require.NoError(t, mcpSrv.RemoveTool("get_b"))
+	expectListChanged()
+	assert.Equal(t, []string{"get_a"}, listToolNames())
+
+	assert.ErrorIs(t, mcpSrv.DisableTool("get_b"), ErrUnknownTool)
+}
+
+func TestMCPServer_LazyTools(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"path": "` + r.URL.Path + `"}`))
+	}))
+	defer backend.Close()
+
+	newRoute := func(method, path, name, description string, tags ...string) *parser.RouteTool {
+		return &parser.RouteTool{
+			RouteConfig: &requester.RouteConfig{Method: method, Path: path, Description: description, Tags: tags},
+			Tool:        mcp.NewTool(name, mcp.WithDescription(description), mcp.WithString("id")),
+		}
+	}
+	mockParser := &mockParser{
+		tools: []*parser.RouteTool{
+			newRoute("GET", "/invoices/{id}", "get_invoices_id", "Fetch an invoice", "billing"),
+			newRoute("GET", "/customers", "get_customers", "List customers", "crm"),
+			newRoute("POST", "/invoices", "post_invoices", "Create an invoice", "billing"),
+		},
+	}
+	srvCfg := &config.Config{
+		EndpointConfig: config.EndpointConfig{BaseURL: backend.URL, AuthType: config.AuthTypeNone},
+		Server:         config.ServerConfig{Mode: config.ServerModeSTDIO, LazyTools: true},
+	}
+	endpointCfg := &srvCfg.EndpointConfig
+	httpRequester := requester.NewHTTPRequester(requester.HTTPRequesterParams{
+		ServiceConfig: endpointCfg,
+		AuthManager:   requester.NewHTTPAuthManager(endpointCfg),
+	})
+	mcpSrv := NewServer(srvCfg, mockParser, httpRequester)
+
+	ctx := context.Background()
+	mcpClient, err := client.NewInProcessClient(mcpSrv.MCPServer())
+	require.NoError(t, err)
+	defer func() { _ = mcpClient.Close() }()
+	require.NoError(t, mcpClient.Start(ctx))
+	initReq := mcp.InitializeRequest{}
+	initReq.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	_, err = mcpClient.Initialize(ctx, initReq)
+	require.NoError(t, err)
+
+	callTool := func(name string, args map[string]interface{}) string {
+		request := mcp.CallToolRequest{}
+		request.Params.Name = name
+		request.Params.Arguments = args
+		result, err := mcpClient.CallTool(ctx, request)
+		require.NoError(t, err)
+		require.False(t, result.IsError, "tool %s failed: %v", name, result.Content)
+		return result.Content[0].(mcp.TextContent).Text
+	}
+
+	// Only the meta-tools are registered up front
+	tools, err := mcpClient.ListTools(ctx, mcp.ListToolsRequest{})
+	require.NoError(t, err)
+	require.Len(t, tools.Tools, 3)
+
+	var matches []operationMatch
+	require.NoError(t, json.Unmarshal([]byte(callTool(SearchToolsTool, map[string]interface{}{"query": "invoice", "tag": "billing"})), &matches))
+	require.Len(t, matches, 2)
+	assert.Equal(t, "get_invoices_id", matches[0].Name)
+	assert.False(t, matches[0].Enabled)
+
+	assert.JSONEq(t, `{"path": "/invoices/42"}`, callTool(CallOperationTool, map[string]interface{}{
+		"name":      "get_invoices_id",
+		"arguments": map[string]interface{}{"id": "42"},
+	}))
+
+	callTool(EnableToolTool, map[string]interface{}{"name": "get_customers"})
+	tools, err = mcpClient.ListTools(ctx, mcp.ListToolsRequest{})
+	require.NoError(t, err)
+	assert.Len(t, tools.Tools, 4)
+	assert.JSONEq(t, `{"path": "/customers"}`, callTool("get_customers", nil))
+}
+
+func TestMCPServer_AdminAnalytics(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer backend.Close()
+
+	newRoute := func(path, name string) *parser.RouteTool {
+		return &parser.RouteTool{
+			RouteConfig: &requester.RouteConfig{Method: "GET", Path: path},
+			Tool:        mcp.NewTool(name),
+		}
+	}
+	mockParser := &mockParser{
+		tools: []*parser.RouteTool{newRoute("/ok", "get_ok"), newRoute("/fail", "get_fail"), newRoute("/unused", "get_unused")},
+	}
+	srvCfg := &config.Config{
+		EndpointConfig: config.EndpointConfig{BaseURL: backend.URL, AuthType: config.AuthTypeNone},
+		Server: config.ServerConfig{
+			Mode:      config.ServerModeHTTP,
+			Analytics: config.AnalyticsConfig{Enabled: true},
+			Admin:     config.AdminConfig{Token: "s3cret"},
+		},
+	}
+	endpointCfg := &srvCfg.EndpointConfig
+	httpRequester := requester.NewHTTPRequester(requester.HTTPRequesterParams{
+		ServiceConfig: endpointCfg,
+		AuthManager:   requester.NewHTTPAuthManager(endpointCfg),
+	})
+	mcpSrv := NewServer(srvCfg, mockParser, httpRequester)
+
+	ctx := context.Background()
+	mcpClient, err := client.NewInProcessClient(mcpSrv.MCPServer())
+	require.NoError(t, err)
+	defer func() { _ = mcpClient.Close() }()
+	require.NoError(t, mcpClient.Start(ctx))
+	initReq := mcp.InitializeRequest{}
+	initReq.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	_, err = mcpClient.Initialize(ctx, initReq)
+	require.NoError(t, err)
+	for _, name := range []string{"get_ok", "get_ok", "get_fail"} {
+		request := mcp.CallToolRequest{}
+		request.Params.Name = name
+		_, err := mcpClient.CallTool(ctx, request)
+		require.NoError(t, err)
+	}
+
+	httpHandler := mcpSrv.handler.CreateHTTPHandler(http.NotFoundHandler())
+	rec := httptest.NewRecorder()
+	httpHandler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/analytics", nil))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/analytics", nil)
+	req.Header.Set("Authorization", "Bearer s3cret")
+	rec = httptest.NewRecorder()
+	httpHandler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var report struct {
+		Tools []struct {
+			Tool      string  `json:"tool"`
+			Calls     int     `json:"calls"`
+			Errors    int     `json:"errors"`
+			ErrorRate float64 `json:"error_rate"`
+		} `json:"tools"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	require.Len(t, report.Tools, 3)
+	assert.Equal(t, "get_ok", report.Tools[0].Tool)
+	assert.Equal(t, 2, report.Tools[0].Calls)
+	assert.Zero(t, report.Tools[0].Errors)
+	assert.Equal(t, "get_fail", report.Tools[1].Tool)
+	assert.Equal(t, 1.0, report.Tools[1].ErrorRate)
+	assert.Equal(t, "get_unused", report.Tools[2].Tool)
+	assert.Zero(t, report.Tools[2].Calls)
+}
+
+func TestMCPServer_BaseURLOverride(t *testing.T) {
+	newBackend := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"backend": "` + name + `", "path": "` + r.URL.Path + `"}`))
+		}))
+	}
+	defaultBackend, customerBackend := newBackend("default"), newBackend("customer")
+	defer defaultBackend.Close()
+	defer customerBackend.Close()
+
+	mockParser := &mockParser{
+		tools: []*parser.RouteTool{{
+			RouteConfig: &requester.RouteConfig{Method: "GET", Path: "/invoices"},
+			Tool:        mcp.NewTool("get_invoices"),
+		}},
+	}
+	srvCfg := &config.Config{
+		EndpointConfig: config.EndpointConfig{
+			BaseURL:         defaultBackend.URL,
+			AuthType:        config.AuthTypeNone,
+			BaseURLOverride: config.BaseURLOverrideConfig{Allowed: []string{customerBackend.URL + "/v1"}},
+		},
+		Server: config.ServerConfig{Mode: config.ServerModeHTTP},
+	}
+	endpointCfg := &srvCfg.EndpointConfig
+	httpRequester := requester.NewHTTPRequester(requester.HTTPRequesterParams{
+		ServiceConfig: endpointCfg,
+		AuthManager:   requester.NewHTTPAuthManager(endpointCfg),
+	})
+	mcpSrv := NewServer(srvCfg, mockParser, httpRequester)
+	ts := httptest.NewServer(mcpSrv.handler.CreateHTTPHandler(mcpSrv.httpHandler()))
+	defer ts.Close()
+
+	ctx := context.Background()
+	callTool := func(headers map[string]string, experimental map[string]any) (string, error) {
+		mcpClient, err := client.NewStreamableHttpClient(ts.URL+"/mcp", transport.WithHTTPHeaders(headers))
+		require.NoError(t, err)
+		defer func() { _ = mcpClient.Close() }()
+		require.NoError(t, mcpClient.Start(ctx))
+		initReq := mcp.InitializeRequest{}
+		initReq.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+		initReq.Params.Capabilities.Experimental = experimental
+		_, err = mcpClient.Initialize(ctx, initReq)
+		require.NoError(t, err)
+
+		request := mcp.CallToolRequest{}
+		request.Params.Name = "get_invoices"
+		result, err := mcpClient.CallTool(ctx, request)
+		if err != nil {
+			return "", err
+		}
+		return result.Content[0].(mcp.TextContent).Text, nil
+	}
+
+	text, err := callTool(nil, nil)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"backend": "default", "path": "/invoices"}`, text)
+
+	text, err = callTool(map[string]string{"X-Auto-MCP-Base-URL": customerBackend.URL + "/v1"}, nil)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"backend": "customer", "path": "/v1/invoices"}`, text)
+
+	// The initialization option is remembered for the session
+	text, err = callTool(nil, map[string]any{"auto-mcp": map[string]any{"base_url": customerBackend.URL + "/v1/"}})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"backend": "customer", "path": "/v1/invoices"}`, text)
+
+	// Base URLs outside the allow-list never reach the network
+	_, err = callTool(map[string]string{"X-Auto-MCP-Base-URL": defaultBackend.URL}, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not allowed")
+}
+
+func TestMCPServer_Reload(t *testing.T) {
+	newRoute := func(name, path string) *parser.RouteTool {
+		return &parser.RouteTool{
+			RouteConfig: &requester.RouteConfig{Path: path, Method: "GET"},
+			Tool:        mcp.NewTool(name, mcp.WithDescription(path)),
+		}
+	}
+	srvCfg := &config.Config{
+		EndpointConfig: config.EndpointConfig{BaseURL: "http://example.com"},
+		Server:         config.ServerConfig{Mode: config.ServerModeSTDIO},
+		Logging:        config.LoggingConfig{Level: "info"},
+	}
+	endpointCfg := &srvCfg.EndpointConfig
+	httpRequester := requester.NewHTTPRequester(requester.HTTPRequesterParams{
+		ServiceConfig: endpointCfg,
+		AuthManager:   requester.NewHTTPAuthManager(endpointCfg),
+	})
+	mcpSrv := NewServer(srvCfg, &mockParser{tools: []*parser.RouteTool{newRoute("get_a", "/a"), newRoute("get_b", "/b")}}, httpRequester)
+	require.NoError(t, mcpSrv.DisableTool("get_b"))
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) })
+	httpHandler := mcpSrv.handler.CreateHTTPHandler(ok)
+	status := func() int {
+		rec := httptest.NewRecorder()
+		httpHandler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		return rec.Code
+	}
+
+	reloaded := *srvCfg
+	reloaded.Server.RateLimit = config.RateLimitConfig{PerIP: 1}
+	reloaded.Logging.Level = "debug"
+	mcpSrv.loadConfig = func() (*config.Config, error) {
+		cfg := reloaded
+		return &cfg, nil
+	}
+	next := &mockParser{tools: []*parser.RouteTool{newRoute("get_b", "/b2"), newRoute("get_c", "/c")}}
+	mcpSrv.newParser = func(*config.Config) parser.Parser { return next }
+
+	require.NoError(t, mcpSrv.Reload())
+	assert.Equal(t, []ToolStatus{{Name: "get_b", Enabled: false}, {Name: "get_c", Enabled: true}}, mcpSrv.Tools())
+	assert.Equal(t, "/b2", mcpSrv.tools["get_b"].Tool.Description)
+	assert.Equal(t, "debug", srvCfg.Logging.Level)
+	assert.Equal(t, http.StatusOK, status())
+	assert.Equal(t, http.StatusTooManyRequests, status())
+
+	// A spec that fails to load leaves the running tools alone
+	next = &mockParser{initErr: fmt.Errorf("broken spec")}
+	assert.ErrorContains(t, mcpSrv.Reload(), "broken spec")
+	assert.Len(t, mcpSrv.Tools(), 2)
+
+	changed := reloaded
+	changed.Server.Port = 9090
+	changed.EndpointConfig.BaseURL = "http://other.example.com"
+	assert.Equal(t, []string{"server", "endpoint"}, restartRequired(&reloaded, &changed))
+}
+
+func TestMCPServer_Drain(t *testing.T) {
+	srvCfg := &config.Config{
+		EndpointConfig: config.EndpointConfig{BaseURL: "http://example.com"},
+		Server:         config.ServerConfig{Mode: config.ServerModeSTDIO},
+	}
+	endpointCfg := &srvCfg.EndpointConfig
+	httpRequester := requester.NewHTTPRequester(requester.HTTPRequesterParams{
+		ServiceConfig: endpointCfg,
+		AuthManager:   requester.NewHTTPAuthManager(endpointCfg),
+	})
+	mcpSrv := NewServer(srvCfg, &mockParser{}, httpRequester)
+
+	release := make(chan struct{})
+	mcpSrv.AddTools(mcpserver.ServerTool{
+		Tool: mcp.NewTool("slow"),
+		Handler: func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			<-release
+			return mcp.NewToolResultText("done"), nil
+		},
+	})
+
+	ctx := context.Background()
+	mcpClient, err := client.NewInProcessClient(mcpSrv.MCPServer())
+	require.NoError(t, err)
+	defer func() { _ = mcpClient.Close() }()
+	require.NoError(t, mcpClient.Start(ctx))
+	initReq := mcp.InitializeRequest{}
+	initReq.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	_, err = mcpClient.Initialize(ctx, initReq)
+	require.NoError(t, err)
+	session := &testSession{notifications: make(chan mcp.JSONRPCNotification, 10)}
+	require.NoError(t, mcpSrv.MCPServer().RegisterSession(ctx, session))
+
+	callDone := make(chan *mcp.CallToolResult, 1)
+	go func() {
+		request := mcp.CallToolRequest{}
+		request.Params.Name = "slow"
+		result, _ := mcpClient.CallTool(ctx, request)
+		callDone <- result
+	}()
+	require.Eventually(t, func() bool { return mcpSrv.activeCalls.Load() == 1 }, 2*time.Second, 10*time.Millisecond)
+
+	drained := make(chan struct{})
+	go func() {
+		mcpSrv.drain(ctx)
+		close(drained)
+	}()
+	select {
+	case notification := <-session.notifications:
+		assert.Equal(t, "notifications/message", notification.Method)
+		assert.Equal(t, "warning", notification.Params.AdditionalFields["level"])
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected a shutdown notification")
+	}
+	select {
+	case <-drained:
+		t.Fatal("drain returned while a tool call was running")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(release)
+	<-drained
+	result := <-callDone
+	require.NotNil(t, result)
+	assert.Equal(t, "done", result.Content[0].(mcp.TextContent).Text)
+
+	// The drain is bounded by the context
+	timeoutCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	mcpSrv.activeCalls.Add(1)
+	mcpSrv.drain(timeoutCtx)
+	assert.ErrorIs(t, timeoutCtx.Err(), context.DeadlineExceeded)
+
+	// New HTTP requests are refused once draining
+	httpHandler := mcpSrv.handler.CreateHTTPHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	rec := httptest.NewRecorder()
+	httpHandler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/mcp", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mcpSrv.handler.Drain()
+	rec = httptest.NewRecorder()
+	httpHandler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/mcp", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "close", rec.Header().Get("Connection"))
+}
+
+func TestSearchRoutes(t *testing.T) {
+	catalog := map[string]*parser.RouteTool{}
+	for _, route := range []*parser.RouteTool{
+		{RouteConfig: &requester.RouteConfig{Path: "/pets", Description: "List pets in the store"}, Tool: mcp.Tool{Name: "get_pets"}},
+		{RouteConfig: &requester.RouteConfig{Path: "/store/inventory", Description: "Pet counts by status", Tags: []string{"store"}}, Tool: mcp.Tool{Name: "get_store_inventory"}},
+		{RouteConfig: &requester.RouteConfig{Path: "/users", Description: "List users"}, Tool: mcp.Tool{Name: "get_users"}},
+	} {
+		catalog[route.Tool.Name] = route
+	}
+
+	names := func(routes []*parser.RouteTool) []string {
+		result := make([]string, 0, len(routes))
+		for _, route := range routes {
+			result = append(result, route.Tool.Name)
+		}
+		return result
+	}
+
+	// Name matches rank above description matches
+	assert.Equal(t, []string{"get_pets", "get_store_inventory"}, names(searchRoutes(catalog, "pet", "", 10)))
+	// Every term must match
+	assert.Equal(t, []string{"get_store_inventory"}, names(searchRoutes(catalog, "pet status", "", 10)))
+	assert.Equal(t, []string{"get_store_inventory"}, names(searchRoutes(catalog, "", "STORE", 10)))
+	assert.Len(t, searchRoutes(catalog, "", "", 2), 2)
+}
+
+func TestMCPServer_RawRequestTool(t *testing.T) {
+	var received *http.Request
+	var receivedBody []byte
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		receivedBody, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ok": true}`))
+	}))
+	defer backend.Close()
+
+	srvCfg := &config.Config{
+		EndpointConfig: config.EndpointConfig{
+			BaseURL:  backend.URL,
+			AuthType: config.AuthTypeBearer,
+			AuthConfig: map[string]string{
+				"token": "secret",
+			},
+			RawRequest: config.RawRequestConfig{
+				Enabled:        true,
+				AllowedPaths:   []string{"/v2/orders/**"},
+				AllowedMethods: []string{"GET", "POST"},
+			},
+		},
+		Server: config.ServerConfig{Mode: config.ServerModeSTDIO},
+	}
+	endpointCfg := &srvCfg.EndpointConfig
+	httpRequester := requester.NewHTTPRequester(requester.HTTPRequesterParams{
+		ServiceConfig: endpointCfg,
+		AuthManager:   requester.NewHTTPAuthManager(endpointCfg),
+	})
+	mcpSrv := NewServer(srvCfg, &mockParser{}, httpRequester)
+
+	call := func(args map[string]interface{}) *mcp.CallToolResult {
+		request := mcp.CallToolRequest{}
+		request.Params.Name = RawRequestTool
+		request.Params.Arguments = args
+		result, err := mcpSrv.handleRawRequest(context.Background(), request)
+		require.NoError(t, err)
+		return result
+	}
+
+	result := call(map[string]interface{}{
+		"method": "POST",
+		"path":   "/v2/orders/42/notes",
+		"query":  map[string]interface{}{"notify": true},
+		"body":   map[string]interface{}{"text": "hello"},
+	})
+	require.False(t, result.IsError)
+	require.NotNil(t, received)
+	assert.Equal(t, "/v2/orders/42/notes", received.URL.Path)
+	assert.Equal(t, "true", received.URL.Query().Get("notify"))
+	assert.Equal(t, "Bearer secret", received.Header.Get("Authorization"))
+	assert.JSONEq(t, `{"text": "hello"}`, string(receivedBody))
+
+	received = nil
+	for _, args := range []map[string]interface{}{
+		{"method": "DELETE", "path": "/v2/orders/42"},
+		{"method": "GET", "path": "/v2/users"},
+		{"method": "GET", "path": "/v2/orders/../users"},
+		{"method": "GET", "path": "/v2/orders/%2e%2e/users"},
+		{"method": "GET", "path": "@evil.example.com/"},
+	} {
+		assert.True(t, call(args).IsError, "expected %v to be rejected", args)
+	}
+	assert.Nil(t, received, "rejected requests must not reach the backend")
+}
+
+func TestRawPathAllowed(t *testing.T) {
+	cfg := config.RawRequestConfig{AllowedPaths: []string{"/v2/orders/**", "/v2/users/*/profile"}}
+
+	assert.True(t, rawPathAllowed(cfg, "/v2/orders"))
+	assert.True(t, rawPathAllowed(cfg, "/v2/orders/42/items"))
+	assert.True(t, rawPathAllowed(cfg, "/v2/users/7/profile"))
+	assert.False(t, rawPathAllowed(cfg, "/v2/ordersx"))
+	assert.False(t, rawPathAllowed(cfg, "/v2/users/7"))
+	assert.True(t, rawPathAllowed(config.RawRequestConfig{}, "/anything"))
+
+	assert.NoError(t, validateRawPath("/v2/orders/"))
+	assert.Error(t, validateRawPath("//evil.example.com"))
+	assert.Error(t, validateRawPath("/v2/./orders"))
+	assert.Error(t, validateRawPath("/v2/orders?admin=true"))
+
+	// Percent-encoded traversal is checked once decoded
+	assert.NoError(t, validateRawPath("/v2/orders/a%20b"))
+	assert.Error(t, validateRawPath("/v2/orders/%2e%2e/admin"))
+	assert.Error(t, validateRawPath("/v2/orders/%2E%2E%2Fadmin"))
+	assert.Error(t, validateRawPath("/v2/orders/..%2fadmin"))
+	assert.Error(t, validateRawPath("/%2F/evil.example.com"))
+	assert.Error(t, validateRawPath("/v2/orders/%zz"))
+}
+
+func TestMCPServer_ToolPrefix(t *testing.T) {
+	mockParser := &mockParser{
+		tools: []*parser.RouteTool{
+			{
+				RouteConfig: &requester.RouteConfig{Path: "/a", Method: "GET"},
+				Tool:        mcp.NewTool("get_a"),
+			},
+		},
+	}
+	srvCfg := &config.Config{
+		EndpointConfig: config.EndpointConfig{BaseURL: "http://example.com"},
+		Server:         config.ServerConfig{Mode: config.ServerModeSTDIO, ToolPrefix: "billing_", HelperTools: true},
+	}
+	endpointCfg := &srvCfg.EndpointConfig
+	httpRequester := requester.NewHTTPRequester(requester.HTTPRequesterParams{
+		ServiceConfig: endpointCfg,
+		AuthManager:   requester.NewHTTPAuthManager(endpointCfg),
+	})
+	mcpSrv := NewServer(srvCfg, mockParser, httpRequester)
+
+	names := make([]string, 0)
+	for _, status := range mcpSrv.Tools() {
+		names = append(names, status.Name)
+	}
+	assert.Equal(t, []string{"billing_convert_epoch", "billing_current_time", "billing_generate_uuid", "billing_get_a"}, names)
+}
+
+func TestMCPServer_SSEEndpointPaths(t *testing.T) {
+	tests := []struct {
+		name      string
+		publicURL string
+		basePath  string
+		expected  string
+	}{
+		{name: "default", expected: "/message?sessionId="},
+		{name: "base path", basePath: "/tools/billing/", expected: "/tools/billing/message?sessionId="},
+		{name: "public url", publicURL: "https://gw.example.com/billing/", basePath: "/billing", expected: "https://gw.example.com/billing/message?sessionId="},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srvCfg := &config.Config{
+				EndpointConfig: config.EndpointConfig{BaseURL: "http://example.com"},
+				Server: config.ServerConfig{
+					Mode:      config.ServerModeSSE,
+					PublicURL: tt.publicURL,
+					BasePath:  tt.basePath,
+				},
+			}
+			endpointCfg := &srvCfg.EndpointConfig
+			httpRequester := requester.NewHTTPRequester(requester.HTTPRequesterParams{
+				ServiceConfig: endpointCfg,
+				AuthManager:   requester.NewHTTPAuthManager(endpointCfg),
+			})
+			mcpSrv := NewServer(srvCfg, &mockParser{}, httpRequester)
+
+			ts := httptest.NewServer(mcpSrv.handler.CreateHTTPHandler(mcpSrv.sseHandler()))
+			defer ts.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+srvCfg.Server.MountPath()+"/sse", nil)
+			require.NoError(t, err)
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+
+			// The first event announces the message endpoint
+			buf := make([]byte, 512)
+			n, err := resp.Body.Read(buf)
+			require.NoError(t, err)
+			assert.Contains(t, string(buf[:n]), "data: "+tt.expected)
+		})
+	}
+}
+
+func TestMCPServer_ToolVisibility(t *testing.T) {
+	mockParser := &mockParser{
+		tools: []*parser.RouteTool{
+			{
+				RouteConfig: &requester.RouteConfig{Path: "/users", Method: "GET"},
+				Tool:        mcp.NewTool("get_users"),
+			},
+			{
+				RouteConfig: &requester.RouteConfig{Path: "/users/{id}", Method: "DELETE"},
+				Tool:        mcp.NewTool("delete_user"),
+			},
+		},
+	}
+	srvCfg := &config.Config{
+		EndpointConfig: config.EndpointConfig{BaseURL: "http://example.com"},
+		Server:         config.ServerConfig{Mode: config.ServerModeHTTP},
+		OAuth: &config.OAuthConfig{Policy: config.PolicyConfig{
+			Rules: []config.PolicyRule{{Methods: []string{"DELETE"}, Allow: []string{"role:admin"}}},
+		}},
+	}
+	endpointCfg := &srvCfg.EndpointConfig
+	httpRequester := requester.NewHTTPRequester(requester.HTTPRequesterParams{
+		ServiceConfig: endpointCfg,
+		AuthManager:   requester.NewHTTPAuthManager(endpointCfg),
+	})
+	mcpSrv := NewServer(srvCfg, mockParser, httpRequester)
+
+	// Stand in for setupAuth, which needs a reachable identity provider
+	authPolicy, err := policy.New(srvCfg.OAuth.Policy)
+	require.NoError(t, err)
+	mcpSrv.policy = authPolicy
+	mcpSrv.tool = tool.NewHandler(srvCfg, true, authPolicy, nil)
+
+	listTools := func(authInfo *middleware.AuthInfo) []string {
+		ctx := context.WithValue(context.Background(), middleware.AuthContextKey, authInfo)
+		response := mcpSrv.MCPServer().HandleMessage(ctx, json.RawMessage(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+		rpcResponse, ok := response.(mcp.JSONRPCResponse)
+		require.True(t, ok, "unexpected response %#v", response)
+		result, ok := rpcResponse.Result.(mcp.ListToolsResult)
+		require.True(t, ok)
+
+		names := make([]string, 0, len(result.Tools))
+		for _, tool := range result.Tools {
+			names = append(names, tool.Name)
+		}
+		return names
+	}
+
+	assert.ElementsMatch(t, []string{"get_users"}, listTools(&middleware.AuthInfo{UserID: "1"}))
+	admin := &middleware.AuthInfo{UserID: "2", Claims: map[string]interface{}{"roles": []interface{}{"admin"}}}
+	assert.ElementsMatch(t, []string{"get_users", "delete_user"}, listTools(admin))
+}
+
+func TestMCPServer_PreciseArguments(t *testing.T) {
+	var receivedPath string
+	var receivedBody []byte
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedPath = r.URL.Path
+		receivedBody, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ok": true}`))
+	}))
+	defer backend.Close()
+
+	route := &requester.RouteConfig{Path: "/accounts/{accountId}/orders", Method: "POST"}
+	mockParser := &mockParser{
+		tools: []*parser.RouteTool{{RouteConfig: route, Tool: mcp.NewTool("create_order")}},
+	}
+	srvCfg := &config.Config{
+		EndpointConfig: config.EndpointConfig{BaseURL: backend.URL, AuthType: config.AuthTypeNone},
+		Server:         config.ServerConfig{Mode: config.ServerModeSTDIO},
+	}
+	endpointCfg := &srvCfg.EndpointConfig
+	httpRequester := requester.NewHTTPRequester(requester.HTTPRequesterParams{
+		ServiceConfig: endpointCfg,
+		AuthManager:   requester.NewHTTPAuthManager(endpointCfg),
+	})
+	mcpSrv := NewServer(srvCfg, mockParser, httpRequester)
+
+	response := mcpSrv.MCPServer().HandleMessage(context.Background(), json.RawMessage(`{
+		"jsonrpc": "2.0", "id": 1, "method": "tools/call",
+		"params": {"name": "create_order", "arguments": {
+			"accountId": 9007199254740993,
+			"body": {"id": 9007199254740993, "amount": 0.1000000000000000055511151231257827}
+		}}
+	}`))
+	rpcResponse, ok := response.(mcp.JSONRPCResponse)
+	require.True(t, ok, "unexpected response %#v", response)
+	result, ok := rpcResponse.Result.(mcp.CallToolResult)
+	require.True(t, ok)
+	require.False(t, result.IsError)
+
+	assert.Equal(t, "/accounts/9007199254740993/orders", receivedPath)
+	assert.Equal(t, `{"amount":0.1000000000000000055511151231257827,"id":9007199254740993}`, strings.TrimSpace(string(receivedBody)))
+}
+
+// testSession is an initialized client session that buffers notifications
+type testSession struct {
+	notifications chan mcp.JSONRPCNotification
+}
+
+func (s *testSession) Initialize()       {}
+func (s *testSession) Initialized() bool { return true }
+func (s *testSession) SessionID() string { return "test-session" }
+func (s *testSession) NotificationChannel() chan<- mcp.JSONRPCNotification {
+	return s.notifications
+}
+
 // mockParser implements the parser.Parser interface for testing
 type mockParser struct {
 	tools      []*parser.RouteTool
+	prompts    []models.Prompt
+	composites []models.CompositeTool
 	initCalled bool
+	initErr    error
 }
 
 func (m *mockParser) Init(openAPISpec string, adjustmentsFile string) error {
 	m.initCalled = true
-	return nil
+	return m.initErr
 }
 
 func (m *mockParser) ParseReader(reader io.Reader) error {
@@ -488,3 +1254,89 @@ func (m *mockParser) ParseReader(reader io.Reader) error {
 func (m *mockParser) GetRouteTools() []*parser.RouteTool {
 	return m.tools
 }
+
+func (m *mockParser) GetPrompts() []models.Prompt {
+	return m.prompts
+}
+
+func (m *mockParser) GetCompositeTools() []models.CompositeTool {
+	return m.composites
+}
+
+func TestMCPServer_CompositeTools(t *testing.T) {
+	var paths []string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id": 7}`))
+	}))
+	defer backend.Close()
+
+	srvCfg := &config.Config{
+		EndpointConfig: config.EndpointConfig{BaseURL: backend.URL, AuthType: config.AuthTypeNone},
+		Server:         config.ServerConfig{Mode: config.ServerModeSTDIO, ToolPrefix: "shop_"},
+	}
+	endpointCfg := &srvCfg.EndpointConfig
+	httpRequester := requester.NewHTTPRequester(requester.HTTPRequesterParams{
+		ServiceConfig: endpointCfg,
+		AuthManager:   requester.NewHTTPAuthManager(endpointCfg),
+	})
+	mcpSrv := NewServer(srvCfg, &mockParser{
+		tools: []*parser.RouteTool{
+			{RouteConfig: &requester.RouteConfig{Path: "/orders", Method: "POST"}, Tool: mcp.NewTool("post_orders")},
+			{RouteConfig: &requester.RouteConfig{Path: "/orders/{id}/pay", Method: "POST"}, Tool: mcp.NewTool("post_orders_id_pay")},
+		},
+		composites: []models.CompositeTool{{
+			Name: "order_and_pay",
+			Steps: []models.CompositeStep{
+				{Tool: "post_orders"},
+				{Tool: "post_orders_id_pay", Arguments: map[string]string{"id": "{{ .steps.post_orders.id }}"}},
+			},
+		}},
+	}, httpRequester)
+
+	tool, ok := mcpSrv.tools["shop_order_and_pay"]
+	require.True(t, ok, "composite tool should be registered with the tool prefix")
+	result, err := tool.Handler(context.Background(), mcp.CallToolRequest{})
+	require.NoError(t, err)
+	require.False(t, result.IsError, "unexpected error: %v", result.Content)
+	assert.Equal(t, []string{"/orders", "/orders/7/pay"}, paths)
+
+	// Disabled tools are not called through composite tools either
+	require.NoError(t, mcpSrv.DisableTool("shop_post_orders_id_pay"))
+	result, err = tool.Handler(context.Background(), mcp.CallToolRequest{})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+
+	// Composite tools no longer defined are removed on reload
+	mcpSrv.loadConfig = func() (*config.Config, error) {
+		cfg := *srvCfg
+		return &cfg, nil
+	}
+	mcpSrv.newParser = func(*config.Config) parser.Parser { return &mockParser{} }
+	require.NoError(t, mcpSrv.Reload())
+	assert.Empty(t, mcpSrv.Tools())
+}
+
+func TestPayloadStore(t *testing.T) {
+	ws, err := workspace.New(config.WorkspaceConfig{Dir: t.TempDir()})
+	require.NoError(t, err)
+	store := payloadStore{ws: ws}
+
+	uri, err := store.Save([]byte("id\n1\n"), "csv")
+	require.NoError(t, err)
+	assert.Regexp(t, `^auto-mcp://payloads/payload-[0-9a-f]{32}-\d+\.csv$`, uri)
+
+	data, mimeType, err := store.read(uri)
+	require.NoError(t, err)
+	assert.Equal(t, "id\n1\n", string(data))
+	assert.Equal(t, "text/csv", mimeType)
+
+	// Only payload files of the workspace can be read
+	for _, uri := range []string{"auto-mcp://payloads/../secret.csv", "auto-mcp://payloads/config.yaml", "file:///etc/passwd"} {
+		_, _, err := store.read(uri)
+		assert.ErrorContains(t, err, "unknown payload", uri)
+	}
+	_, _, err = store.read("auto-mcp://payloads/payload-gone.csv")
+	assert.ErrorContains(t, err, "has expired")
+}
@@ -0,0 +1,117 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/brizzai/auto-mcp/internal/logger"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+	"go.uber.org/zap"
+)
+
+// sessionIDPrefix prefixes streamable HTTP session IDs
+const sessionIDPrefix = "mcp-session-"
+
+// sessionSweepInterval is how often expired sessions are dropped
+const sessionSweepInterval = time.Minute
+
+// sessionActivity is what an idle session manager knows about a session
+type sessionActivity struct {
+	lastSeen time.Time
+	streams  int // open GET streams, which keep the session alive
+}
+
+// idleSessions is a streamable HTTP session ID manager ending sessions that
+// had no request and no open stream for the idle timeout. Ended and unknown
+// sessions, e.g. after a restart, are reported as terminated, so clients get
+// 404 and start a new session as the MCP specification requires.
+type idleSessions struct {
+	timeout time.Duration
+	now     func() time.Time
+
+	mu        sync.Mutex
+	sessions  map[string]*sessionActivity
+	lastSweep time.Time
+}
+
+var _ mcpserver.SessionIdManager = (*idleSessions)(nil)
+
+func newIdleSessions(timeout time.Duration) *idleSessions {
+	return &idleSessions{timeout: timeout, now: time.Now, sessions: make(map[string]*sessionActivity)}
+}
+
+// Generate starts a new session
+func (m *idleSessions) Generate() string {
+	token := make([]byte, 16)
+	// crypto/rand.Read never returns an error
+	_, _ = rand.Read(token)
+	id := sessionIDPrefix + hex.EncodeToString(token)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	if now.Sub(m.lastSweep) > sessionSweepInterval {
+		for sessionID, session := range m.sessions {
+			if m.expired(session, now) {
+				delete(m.sessions, sessionID)
+			}
+		}
+		m.lastSweep = now
+	}
+	m.sessions[id] = &sessionActivity{lastSeen: now}
+	return id
+}
+
+// Validate reports whether the session has ended and records its activity
+func (m *idleSessions) Validate(sessionID string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	session, ok := m.sessions[sessionID]
+	if !ok {
+		return true, nil
+	}
+	now := m.now()
+	if m.expired(session, now) {
+		delete(m.sessions, sessionID)
+		logger.Debug("Ended idle session", zap.String("session", sessionID))
+		return true, nil
+	}
+	session.lastSeen = now
+	return false, nil
+}
+
+// Terminate ends the session at the client's request
+func (m *idleSessions) Terminate(sessionID string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sessions, sessionID)
+	return false, nil
+}
+
+// expired reports whether session has been idle for the timeout
+func (m *idleSessions) expired(session *sessionActivity, now time.Time) bool {
+	return session.streams == 0 && now.Sub(session.lastSeen) > m.timeout
+}
+
+// streamOpened keeps the session of a GET stream alive while it is open
+func (m *idleSessions) streamOpened(_ context.Context, session mcpserver.ClientSession) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if activity, ok := m.sessions[session.SessionID()]; ok {
+		activity.streams++
+	}
+}
+
+// streamClosed starts the idle timeout of the session once its last GET
+// stream has closed
+func (m *idleSessions) streamClosed(_ context.Context, session mcpserver.ClientSession) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if activity, ok := m.sessions[session.SessionID()]; ok && activity.streams > 0 {
+		activity.streams--
+		activity.lastSeen = m.now()
+	}
+}
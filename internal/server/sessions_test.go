@@ -0,0 +1,141 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/brizzai/auto-mcp/internal/config"
+	"github.com/brizzai/auto-mcp/internal/requester"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const initializeMessage = `{"jsonrpc": "2.0", "id": 1, "method": "initialize", "params": {"protocolVersion": "2025-03-26", "capabilities": {}, "clientInfo": {"name": "test", "version": "1.0"}}}`
+
+const pingMessage = `{"jsonrpc": "2.0", "id": 2, "method": "ping"}`
+
+// sessionStub is a client session with the given ID
+type sessionStub struct {
+	testSession
+	id string
+}
+
+func (s *sessionStub) SessionID() string { return s.id }
+
+func TestIdleSessions(t *testing.T) {
+	now := time.Now()
+	sessions := newIdleSessions(time.Minute)
+	sessions.now = func() time.Time { return now }
+
+	id := sessions.Generate()
+	assert.True(t, strings.HasPrefix(id, sessionIDPrefix))
+	assert.NotEqual(t, id, sessions.Generate())
+
+	// Requests keep the session alive
+	now = now.Add(50 * time.Second)
+	terminated, err := sessions.Validate(id)
+	require.NoError(t, err)
+	assert.False(t, terminated)
+	now = now.Add(50 * time.Second)
+	terminated, _ = sessions.Validate(id)
+	assert.False(t, terminated)
+
+	// So does an open stream, until it closes
+	stream := &sessionStub{id: id}
+	sessions.streamOpened(context.Background(), stream)
+	now = now.Add(time.Hour)
+	terminated, _ = sessions.Validate(id)
+	assert.False(t, terminated)
+	sessions.streamClosed(context.Background(), stream)
+	now = now.Add(2 * time.Minute)
+	terminated, _ = sessions.Validate(id)
+	assert.True(t, terminated)
+
+	// Unknown and client-terminated sessions are reported as terminated
+	terminated, _ = sessions.Validate(sessionIDPrefix + "unknown")
+	assert.True(t, terminated)
+	other := sessions.Generate()
+	_, err = sessions.Terminate(other)
+	require.NoError(t, err)
+	terminated, _ = sessions.Validate(other)
+	assert.True(t, terminated)
+}
+
+func TestMCPServer_StreamableHTTPSessions(t *testing.T) {
+	newHTTPServer := func(h config.StreamableHTTPConfig) (*Server, *httptest.Server) {
+		srvCfg := &config.Config{
+			EndpointConfig: config.EndpointConfig{AuthType: config.AuthTypeNone},
+			Server:         config.ServerConfig{Mode: config.ServerModeHTTP, StreamableHTTP: h},
+		}
+		httpRequester := requester.NewHTTPRequester(requester.HTTPRequesterParams{
+			ServiceConfig: &srvCfg.EndpointConfig,
+			AuthManager:   requester.NewHTTPAuthManager(&srvCfg.EndpointConfig),
+		})
+		mcpSrv := NewServer(srvCfg, &mockParser{}, httpRequester)
+		ts := httptest.NewServer(mcpSrv.handler.CreateHTTPHandler(mcpSrv.httpHandler()))
+		t.Cleanup(ts.Close)
+		return mcpSrv, ts
+	}
+	post := func(ts *httptest.Server, sessionID, message string) *http.Response {
+		req, err := http.NewRequest(http.MethodPost, ts.URL+"/mcp", strings.NewReader(message))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		if sessionID != "" {
+			req.Header.Set("Mcp-Session-Id", sessionID)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		return resp
+	}
+
+	t.Run("stateless", func(t *testing.T) {
+		_, ts := newHTTPServer(config.StreamableHTTPConfig{Stateless: true})
+		resp := post(ts, "", initializeMessage)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Empty(t, resp.Header.Get("Mcp-Session-Id"))
+	})
+
+	t.Run("idle timeout", func(t *testing.T) {
+		mcpSrv, ts := newHTTPServer(config.StreamableHTTPConfig{SessionIdleTimeout: time.Minute})
+		// The clock is read by the handler goroutines
+		start := time.Now()
+		var elapsed atomic.Int64
+		mcpSrv.httpSessions.now = func() time.Time { return start.Add(time.Duration(elapsed.Load())) }
+
+		sessionID := post(ts, "", initializeMessage).Header.Get("Mcp-Session-Id")
+		require.NotEmpty(t, sessionID)
+		assert.Equal(t, http.StatusOK, post(ts, sessionID, pingMessage).StatusCode)
+
+		elapsed.Store(int64(2 * time.Minute))
+		assert.Equal(t, http.StatusNotFound, post(ts, sessionID, pingMessage).StatusCode)
+	})
+
+	t.Run("heartbeat", func(t *testing.T) {
+		_, ts := newHTTPServer(config.StreamableHTTPConfig{HeartbeatInterval: 20 * time.Millisecond})
+		sessionID := post(ts, "", initializeMessage).Header.Get("Mcp-Session-Id")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"/mcp", nil)
+		require.NoError(t, err)
+		req.Header.Set("Mcp-Session-Id", sessionID)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			if strings.Contains(scanner.Text(), `"method":"ping"`) {
+				return
+			}
+		}
+		t.Fatalf("no heartbeat received: %v", scanner.Err())
+	})
+}
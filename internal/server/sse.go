@@ -0,0 +1,253 @@
+package server
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brizzai/auto-mcp/internal/config"
+	"github.com/brizzai/auto-mcp/internal/logger"
+	"go.uber.org/zap"
+)
+
+// sseEventBuffer is the number of recent events kept per stream for
+// resumption, the size of the event queue of an SSE session
+const sseEventBuffer = 100
+
+// sseKeepAlive is the comment written on idle streams. Clients ignore
+// comments, so it only keeps proxies from closing the connection.
+const sseKeepAlive = ": keep-alive\n\n"
+
+// sseEvent is an event written on a stream, without its ID
+type sseEvent struct {
+	seq  uint64
+	data []byte
+}
+
+// sseStream holds the recent events of a stream
+type sseStream struct {
+	events   []sseEvent
+	seq      uint64
+	closedAt time.Time // zero while the stream is open
+}
+
+// sseStreams adds keep-alive comments, a reconnection delay and event IDs to
+// the streams of the SSE transport. mcp-go starts a new session for every
+// stream, so a reconnecting client cannot keep its session, but with a resume
+// window the events written to its previous stream after Last-Event-ID, e.g.
+// tool results lost when a proxy dropped the connection, are sent again on
+// the new one.
+type sseStreams struct {
+	cfg config.SSEConfig
+	now func() time.Time
+
+	mu      sync.Mutex
+	streams map[string]*sseStream
+}
+
+func newSSEStreams(cfg config.SSEConfig) *sseStreams {
+	return &sseStreams{cfg: cfg, now: time.Now, streams: make(map[string]*sseStream)}
+}
+
+// wrap serves the SSE stream handler next with the configured extensions
+func (s *sseStreams) wrap(next http.Handler) http.Handler {
+	if s.cfg.KeepAliveInterval <= 0 && s.cfg.RetryDelay <= 0 && s.cfg.ResumeWindow <= 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if r.Method != http.MethodGet || !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		sw := &sseWriter{ResponseWriter: w, flusher: flusher, streams: s}
+		if s.cfg.ResumeWindow > 0 {
+			sw.id, sw.stream, sw.missed = s.open(r.Header.Get("Last-Event-ID"))
+			defer s.close(sw.stream)
+			if len(sw.missed) > 0 {
+				logger.Debug("Resuming SSE stream",
+					zap.String("last_event_id", r.Header.Get("Last-Event-ID")),
+					zap.Int("missed_events", len(sw.missed)),
+				)
+			}
+		}
+		if s.cfg.KeepAliveInterval > 0 {
+			done := make(chan struct{})
+			defer close(done)
+			go sw.keepAlive(done, s.cfg.KeepAliveInterval)
+		}
+		// Runs first, so nothing is written once the handler returned
+		defer sw.finish()
+
+		next.ServeHTTP(sw, r)
+	})
+}
+
+// open starts a stream, returning its ID and the events of the stream
+// lastEventID belongs to that came after it
+func (s *sseStreams) open(lastEventID string) (string, *sseStream, [][]byte) {
+	token := make([]byte, 8)
+	// crypto/rand.Read never returns an error
+	_, _ = rand.Read(token)
+	id := hex.EncodeToString(token)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	for streamID, stream := range s.streams {
+		if !stream.closedAt.IsZero() && now.Sub(stream.closedAt) > s.cfg.ResumeWindow {
+			delete(s.streams, streamID)
+		}
+	}
+
+	var missed [][]byte
+	if prevID, seq, ok := parseSSEEventID(lastEventID); ok {
+		if prev, ok := s.streams[prevID]; ok {
+			for _, event := range prev.events {
+				if event.seq > seq {
+					missed = append(missed, event.data)
+				}
+			}
+			// The missed events move to the new stream
+			delete(s.streams, prevID)
+		}
+	}
+
+	stream := &sseStream{}
+	s.streams[id] = stream
+	return id, stream, missed
+}
+
+// close starts the resume window of the stream
+func (s *sseStreams) close(stream *sseStream) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stream.closedAt = s.now()
+}
+
+// record keeps the event and returns it with its ID
+func (s *sseStreams) record(id string, stream *sseStream, data []byte) []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stream.seq++
+	stream.events = append(stream.events, sseEvent{seq: stream.seq, data: bytes.Clone(data)})
+	if len(stream.events) > sseEventBuffer {
+		stream.events = stream.events[len(stream.events)-sseEventBuffer:]
+	}
+	return append([]byte(fmt.Sprintf("id: %s-%d\n", id, stream.seq)), data...)
+}
+
+// parseSSEEventID splits an event ID into its stream ID and sequence number
+func parseSSEEventID(eventID string) (string, uint64, bool) {
+	i := strings.LastIndexByte(eventID, '-')
+	if i <= 0 {
+		return "", 0, false
+	}
+	seq, err := strconv.ParseUint(eventID[i+1:], 10, 64)
+	if err != nil {
+		return "", 0, false
+	}
+	return eventID[:i], seq, true
+}
+
+// sseWriter writes the events of a stream. mcp-go writes each event with a
+// single Write, so keep-alive comments and IDs never split an event.
+type sseWriter struct {
+	http.ResponseWriter
+	flusher http.Flusher
+	streams *sseStreams
+	id      string
+	stream  *sseStream // nil without a resume window
+	missed  [][]byte
+
+	mu       sync.Mutex
+	started  bool // the handler wrote the headers and endpoint event
+	finished bool
+}
+
+// Write writes an event. The first one, the endpoint event, is preceded by
+// the reconnection delay and followed by the events the client missed.
+func (w *sseWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.started {
+		if err := w.writeEvent(p); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+
+	w.started = true
+	if delay := w.streams.cfg.RetryDelay; delay > 0 {
+		if _, err := fmt.Fprintf(w.ResponseWriter, "retry: %d\n\n", delay.Milliseconds()); err != nil {
+			return 0, err
+		}
+	}
+	if _, err := w.ResponseWriter.Write(p); err != nil {
+		return 0, err
+	}
+	for _, event := range w.missed {
+		if err := w.writeEvent(event); err != nil {
+			return 0, err
+		}
+	}
+	w.missed = nil
+	return len(p), nil
+}
+
+// writeEvent writes p, numbering message events when events are kept
+func (w *sseWriter) writeEvent(p []byte) error {
+	if w.stream != nil && bytes.HasPrefix(p, []byte("event: message\n")) {
+		p = w.streams.record(w.id, w.stream, p)
+	}
+	_, err := w.ResponseWriter.Write(p)
+	return err
+}
+
+// Flush sends the written events to the client
+func (w *sseWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.flusher.Flush()
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (w *sseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// keepAlive writes a comment every interval until done is closed
+func (w *sseWriter) keepAlive(done <-chan struct{}, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			w.mu.Lock()
+			// Comments before the handler wrote the headers would send them early
+			if w.started && !w.finished {
+				if _, err := io.WriteString(w.ResponseWriter, sseKeepAlive); err == nil {
+					w.flusher.Flush()
+				}
+			}
+			w.mu.Unlock()
+		case <-done:
+			return
+		}
+	}
+}
+
+// finish stops writes once the handler returned
+func (w *sseWriter) finish() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.finished = true
+}
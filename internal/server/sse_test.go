@@ -0,0 +1,117 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brizzai/auto-mcp/internal/config"
+	"github.com/brizzai/auto-mcp/internal/requester"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSSEStreams_Resume(t *testing.T) {
+	streams := newSSEStreams(config.SSEConfig{ResumeWindow: time.Minute, RetryDelay: 3 * time.Second})
+	now := time.Now()
+	streams.now = func() time.Time { return now }
+
+	// Writes the endpoint event and the given message events, like mcp-go
+	events := 0
+	handler := streams.wrap(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprint(w, "event: endpoint\ndata: /message?sessionId=1\r\n\r\n")
+		for i := 0; i < events; i++ {
+			_, _ = fmt.Fprintf(w, "event: message\ndata: {\"id\":%d}\n\n", i)
+		}
+		w.(http.Flusher).Flush()
+	}))
+	connect := func(lastEventID string) string {
+		req := httptest.NewRequest(http.MethodGet, "/sse", nil)
+		if lastEventID != "" {
+			req.Header.Set("Last-Event-ID", lastEventID)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Body.String()
+	}
+
+	events = 3
+	first := connect("")
+	require.True(t, strings.HasPrefix(first, "retry: 3000\n\nevent: endpoint\n"), first)
+	ids := sseEventIDs(first)
+	require.Len(t, ids, 3)
+
+	// The client received the first event before the stream dropped
+	events = 0
+	resumed := connect(ids[0])
+	assert.NotContains(t, resumed, `{"id":0}`)
+	assert.Contains(t, resumed, `{"id":1}`)
+	assert.Contains(t, resumed, `{"id":2}`)
+	assert.Less(t, strings.Index(resumed, "event: endpoint"), strings.Index(resumed, `{"id":1}`))
+
+	// Events move to the new stream, and unknown streams resume nothing
+	assert.NotContains(t, connect(ids[0]), "event: message")
+	assert.NotContains(t, connect("unknown-1"), "event: message")
+
+	// Closed streams are dropped after the resume window
+	events = 1
+	ids = sseEventIDs(connect(""))
+	require.Len(t, ids, 1)
+	id, _, ok := parseSSEEventID(ids[0])
+	require.True(t, ok)
+	now = now.Add(2 * time.Minute)
+	events = 0
+	assert.NotContains(t, connect(id+"-0"), "event: message")
+}
+
+// sseEventIDs returns the IDs of the events in an SSE stream body
+func sseEventIDs(body string) []string {
+	var ids []string
+	for _, line := range strings.Split(body, "\n") {
+		if id, ok := strings.CutPrefix(line, "id: "); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func TestMCPServer_SSEKeepAlive(t *testing.T) {
+	srvCfg := &config.Config{
+		EndpointConfig: config.EndpointConfig{AuthType: config.AuthTypeNone},
+		Server: config.ServerConfig{
+			Mode: config.ServerModeSSE,
+			SSE:  config.SSEConfig{KeepAliveInterval: 20 * time.Millisecond, RetryDelay: time.Second},
+		},
+	}
+	httpRequester := requester.NewHTTPRequester(requester.HTTPRequesterParams{
+		ServiceConfig: &srvCfg.EndpointConfig,
+		AuthManager:   requester.NewHTTPAuthManager(&srvCfg.EndpointConfig),
+	})
+	mcpSrv := NewServer(srvCfg, &mockParser{}, httpRequester)
+	ts := httptest.NewServer(mcpSrv.handler.CreateHTTPHandler(mcpSrv.sseHandler()))
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"/sse", nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	scanner := bufio.NewScanner(resp.Body)
+	require.True(t, scanner.Scan())
+	assert.Equal(t, "retry: 1000", scanner.Text())
+	for scanner.Scan() {
+		if scanner.Text() == strings.TrimSpace(sseKeepAlive) {
+			return
+		}
+	}
+	t.Fatalf("no keep-alive received: %v", scanner.Err())
+}
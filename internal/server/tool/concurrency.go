@@ -0,0 +1,83 @@
+package tool
+
+import (
+	"fmt"
+
+	"github.com/brizzai/auto-mcp/internal/logger"
+	"github.com/brizzai/auto-mcp/internal/requester"
+	"go.uber.org/zap"
+)
+
+// semaphore holds one slot per running call, nil is unlimited
+type semaphore chan struct{}
+
+// newSemaphore returns a semaphore of limit slots, nil when limit is 0
+func newSemaphore(limit int) semaphore {
+	if limit <= 0 {
+		return nil
+	}
+	return make(semaphore, limit)
+}
+
+// tryAcquire takes a slot without waiting, reporting whether one was free
+func (s semaphore) tryAcquire() bool {
+	if s == nil {
+		return true
+	}
+	select {
+	case s <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// release frees a slot taken by tryAcquire
+func (s semaphore) release() {
+	if s != nil {
+		<-s
+	}
+}
+
+// toolSemaphore returns the semaphore shared by the calls of a tool. Tools
+// built for every call, as in lazy mode, share theirs by name.
+func (h *Handler) toolSemaphore(name string, route *requester.RouteConfig) semaphore {
+	limit := 0
+	if h.cfg != nil {
+		limit = h.cfg.Server.Concurrency.PerTool
+	}
+	if route != nil && route.Policy.MaxConcurrency > 0 {
+		limit = route.Policy.MaxConcurrency
+	}
+	if limit <= 0 {
+		return nil
+	}
+
+	h.slotsMu.Lock()
+	defer h.slotsMu.Unlock()
+	if s, ok := h.toolSlots[name]; ok && cap(s) == limit {
+		return s
+	}
+	s := newSemaphore(limit)
+	h.toolSlots[name] = s
+	return s
+}
+
+// acquire takes a slot of the tool and of the server. It returns the
+// function releasing both, or the error message for the model when either
+// limit is reached.
+func (h *Handler) acquire(name string, slots semaphore) (func(), string) {
+	if !slots.tryAcquire() {
+		logger.Warn("Rejected tool call over the concurrency limit", zap.String("tool", name), zap.Int("limit", cap(slots)))
+		return nil, fmt.Sprintf("Concurrency limit reached: %d calls of this tool are already running. Retry once one of them has completed.", cap(slots))
+	}
+	if !h.inFlight.tryAcquire() {
+		slots.release()
+		logger.Warn("Rejected tool call over the server concurrency limit", zap.String("tool", name), zap.Int("limit", cap(h.inFlight)))
+		return nil, fmt.Sprintf("Concurrency limit reached: the server is already running %d tool calls. Retry in a few seconds.", cap(h.inFlight))
+	}
+	return func() {
+		h.inFlight.release()
+		slots.release()
+	}, ""
+}
@@ -0,0 +1,124 @@
+package tool
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dateLayouts are the date and time spellings models commonly produce.
+// Layouts without a zone are interpreted as UTC.
+var dateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/01/02",
+	"2006.01.02",
+	"20060102",
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC850,
+	time.ANSIC,
+	"January 2 2006 15:04",
+	"January 2 2006",
+	"Jan 2 2006 15:04",
+	"Jan 2 2006",
+	"2 January 2006",
+	"2 Jan 2006",
+	"Monday January 2 2006",
+	"Mon Jan 2 2006",
+	"January 2006",
+	"Jan 2006",
+}
+
+var (
+	ordinalSuffix = regexp.MustCompile(`(\d)(st|nd|rd|th)\b`)
+	dateNoise     = regexp.MustCompile(`[,\s]+`)
+	epochPattern  = regexp.MustCompile(`^\d{9,13}$`)
+)
+
+// normalizeDates rewrites arguments with a date or date-time format to the
+// exact spelling the spec requires. Values that cannot be parsed are left
+// untouched so the upstream API can report them.
+func normalizeDates(params map[string]interface{}, formats map[string]string) {
+	for path, format := range formats {
+		container, key, ok := lookupArgument(params, path)
+		if !ok {
+			continue
+		}
+		if normalized, ok := normalizeDate(container[key], format); ok {
+			container[key] = normalized
+		}
+	}
+}
+
+// lookupArgument resolves a dotted argument path to its parent map and key
+func lookupArgument(params map[string]interface{}, path string) (map[string]interface{}, string, bool) {
+	parts := strings.Split(path, ".")
+	container := params
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := container[part].(map[string]interface{})
+		if !ok {
+			return nil, "", false
+		}
+		container = next
+	}
+	key := parts[len(parts)-1]
+	if _, ok := container[key]; !ok {
+		return nil, "", false
+	}
+	return container, key, true
+}
+
+// normalizeDate formats value as an RFC 3339 full-date or date-time
+func normalizeDate(value interface{}, format string) (string, bool) {
+	t, ok := parseDate(value)
+	if !ok {
+		return "", false
+	}
+	if format == "date" {
+		return t.Format(time.DateOnly), true
+	}
+	return t.Format(time.RFC3339), true
+}
+
+func parseDate(value interface{}) (time.Time, bool) {
+	var text string
+	switch v := value.(type) {
+	case string:
+		text = strings.TrimSpace(v)
+	case json.Number:
+		text = v.String()
+	case float64:
+		text = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return time.Time{}, false
+	}
+
+	// Unix timestamps in seconds or milliseconds
+	if epochPattern.MatchString(text) {
+		n, err := strconv.ParseInt(text, 10, 64)
+		if err != nil {
+			return time.Time{}, false
+		}
+		if len(text) > 10 {
+			return time.UnixMilli(n).UTC(), true
+		}
+		return time.Unix(n, 0).UTC(), true
+	}
+
+	for _, candidate := range []string{text, dateNoise.ReplaceAllString(ordinalSuffix.ReplaceAllString(text, "$1"), " ")} {
+		for _, layout := range dateLayouts {
+			if t, err := time.Parse(layout, candidate); err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
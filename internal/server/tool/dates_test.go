@@ -0,0 +1,59 @@
+package tool
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeDate(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		format   string
+		expected string
+		ok       bool
+	}{
+		{"2024-06-01", "date", "2024-06-01", true},
+		{"June 1 2024", "date", "2024-06-01", true},
+		{"June 1st, 2024", "date", "2024-06-01", true},
+		{"1 Jun 2024", "date", "2024-06-01", true},
+		{"2024/06/01", "date", "2024-06-01", true},
+		{"2024-06-01T23:30:00-05:00", "date", "2024-06-01", true},
+		{json.Number("1717200000"), "date-time", "2024-06-01T00:00:00Z", true},
+		{float64(1717200000000), "date-time", "2024-06-01T00:00:00Z", true},
+		{"1717200000", "date", "2024-06-01", true},
+		{"2024-06-01", "date-time", "2024-06-01T00:00:00Z", true},
+		{"2024-06-01 10:15", "date-time", "2024-06-01T10:15:00Z", true},
+		{"2024-06-01T10:15:00+02:00", "date-time", "2024-06-01T10:15:00+02:00", true},
+		{"next tuesday", "date", "", false},
+		{true, "date", "", false},
+	}
+
+	for _, tt := range tests {
+		actual, ok := normalizeDate(tt.value, tt.format)
+		assert.Equal(t, tt.ok, ok, "%v", tt.value)
+		assert.Equal(t, tt.expected, actual, "%v", tt.value)
+	}
+}
+
+func TestNormalizeDates(t *testing.T) {
+	params := map[string]interface{}{
+		"since": "June 1 2024",
+		"body": map[string]interface{}{
+			"owner": map[string]interface{}{"birthday": "not a date"},
+		},
+	}
+	normalizeDates(params, map[string]string{
+		"since":               "date",
+		"until":               "date",
+		"body.owner.birthday": "date",
+	})
+
+	assert.Equal(t, map[string]interface{}{
+		"since": "2024-06-01",
+		"body": map[string]interface{}{
+			"owner": map[string]interface{}{"birthday": "not a date"},
+		},
+	}, params)
+}
@@ -0,0 +1,86 @@
+package tool
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/brizzai/auto-mcp/internal/auth/middleware"
+	"github.com/brizzai/auto-mcp/internal/logger"
+	"github.com/brizzai/auto-mcp/internal/transform"
+	"go.uber.org/zap"
+)
+
+// claimPattern matches user claim references such as {{user.email}}
+var claimPattern = regexp.MustCompile(`\{\{\s*user\.([A-Za-z0-9_]+)\s*\}\}`)
+
+// applyDefaults fills in arguments the caller omitted using the route's
+// configured defaults. Defaults that reference a user claim are only applied
+// when the claim is available.
+func applyDefaults(params map[string]interface{}, defaults map[string]string, authInfo *middleware.AuthInfo) {
+	for name, template := range defaults {
+		if _, ok := params[name]; ok {
+			continue
+		}
+		value, err := renderClaims(template, authInfo)
+		if err != nil {
+			logger.Debug("Skipping argument default", zap.String("argument", name), zap.Error(err))
+			continue
+		}
+		params[name] = value
+	}
+}
+
+// applyConstants sets the route's constant arguments, replacing any value
+// the caller sent. Dotted names such as "body.tenant_id" set a field of an
+// object argument.
+func applyConstants(params map[string]interface{}, constants map[string]string, authInfo *middleware.AuthInfo) error {
+	for name, template := range constants {
+		value, err := renderClaims(template, authInfo)
+		if err != nil {
+			return fmt.Errorf("argument %s: %w", name, err)
+		}
+		if err := transform.SetArgument(params, name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderClaims replaces {{user.<claim>}} references with the authenticated user's values
+func renderClaims(template string, authInfo *middleware.AuthInfo) (string, error) {
+	var renderErr error
+	rendered := claimPattern.ReplaceAllStringFunc(template, func(match string) string {
+		claim := claimPattern.FindStringSubmatch(match)[1]
+		value, ok := userClaim(authInfo, claim)
+		if !ok {
+			renderErr = fmt.Errorf("user claim %q is not available", claim)
+			return match
+		}
+		return value
+	})
+	return rendered, renderErr
+}
+
+// userClaim looks up a claim on the authenticated user
+func userClaim(authInfo *middleware.AuthInfo, claim string) (string, bool) {
+	if authInfo == nil {
+		return "", false
+	}
+
+	var value string
+	switch claim {
+	case "id":
+		value = authInfo.UserID
+	case "email":
+		value = authInfo.Email
+	case "name":
+		value = authInfo.Name
+	default:
+		raw, ok := authInfo.Claims[claim]
+		if !ok || raw == nil {
+			return "", false
+		}
+		value = fmt.Sprintf("%v", raw)
+	}
+	return value, value != ""
+}
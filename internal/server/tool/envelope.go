@@ -0,0 +1,154 @@
+package tool
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/brizzai/auto-mcp/internal/jsonpath"
+	"github.com/brizzai/auto-mcp/internal/logger"
+	"github.com/brizzai/auto-mcp/internal/requester"
+	"go.uber.org/zap"
+)
+
+// envelopeDataKey is the member holding the payload in common envelope responses
+const envelopeDataKey = "data"
+
+// envelopeMetaKeys are the sibling members the heuristic accepts next to "data"
+var envelopeMetaKeys = map[string]bool{
+	"meta":       true,
+	"links":      true,
+	"pagination": true,
+	"paging":     true,
+	"page":       true,
+}
+
+// envelopePaths are the compiled unwrap paths of a route
+type envelopePaths struct {
+	data     *jsonpath.Path
+	meta     *jsonpath.Path
+	metaName string
+	// restIsMeta makes the rest of the body the metadata, without a meta path
+	restIsMeta bool
+}
+
+// compileEnvelope compiles the route's unwrap paths once, returning nil when
+// the route has none
+func compileEnvelope(toolName string, route *requester.RouteConfig) *envelopePaths {
+	if route == nil || route.UnwrapData == "" {
+		return nil
+	}
+	data, err := jsonpath.Compile(route.UnwrapData)
+	if err != nil {
+		logger.Error("Invalid unwrap data path, ignoring",
+			zap.String("tool", toolName),
+			zap.String("path", route.UnwrapData),
+			zap.Error(err))
+		return nil
+	}
+	if route.UnwrapMeta == "" {
+		return &envelopePaths{data: data, restIsMeta: true}
+	}
+	meta, err := jsonpath.Compile(route.UnwrapMeta)
+	if err != nil {
+		logger.Error("Invalid unwrap meta path, ignoring",
+			zap.String("tool", toolName),
+			zap.String("path", route.UnwrapMeta),
+			zap.Error(err))
+		return &envelopePaths{data: data}
+	}
+	return &envelopePaths{data: data, meta: meta, metaName: metaMemberName(route.UnwrapMeta)}
+}
+
+// unwrapEnvelope returns the payload of an envelope response together with the
+// remaining envelope members. Routes with configured unwrap paths use them;
+// otherwise the global heuristic applies when enabled. The body is returned
+// unchanged when it is not an envelope.
+func (h *Handler) unwrapEnvelope(paths *envelopePaths, body []byte) ([]byte, map[string]interface{}) {
+	if paths == nil && (h.cfg == nil || !h.cfg.EndpointConfig.UnwrapEnvelopes) {
+		return body, nil
+	}
+
+	doc, err := jsonpath.Decode(body)
+	if err != nil {
+		return body, nil
+	}
+
+	var data interface{}
+	var meta map[string]interface{}
+	if paths != nil {
+		var ok bool
+		if data, meta, ok = paths.unwrap(doc); !ok {
+			return body, nil
+		}
+	} else {
+		obj, ok := doc.(map[string]interface{})
+		if !ok || !isEnvelope(obj) {
+			return body, nil
+		}
+		data = obj[envelopeDataKey]
+		meta = siblings(obj, envelopeDataKey)
+	}
+
+	out, err := json.Marshal(data)
+	if err != nil {
+		logger.Debug("Failed to encode unwrapped payload", zap.Error(err))
+		return body, nil
+	}
+	return out, meta
+}
+
+// unwrap extracts data and meta using the route's paths. Without a meta path,
+// the rest of the body becomes the metadata: the data member is removed from
+// its parent, so nested payloads leave their siblings in place.
+func (p *envelopePaths) unwrap(doc interface{}) (interface{}, map[string]interface{}, bool) {
+	data, found := p.data.Lookup(doc)
+	if !found {
+		return nil, nil, false
+	}
+
+	if p.meta != nil {
+		if value, ok := p.meta.Lookup(doc); ok {
+			return data, map[string]interface{}{p.metaName: value}, true
+		}
+		return data, nil, true
+	}
+	if p.restIsMeta {
+		if rest, ok := p.data.Without(doc).(map[string]interface{}); ok {
+			return data, rest, true
+		}
+	}
+	return data, nil, true
+}
+
+// isEnvelope reports whether obj looks like {"data": ..., "meta": ...}
+func isEnvelope(obj map[string]interface{}) bool {
+	if _, ok := obj[envelopeDataKey]; !ok || len(obj) < 2 {
+		return false
+	}
+	for key := range obj {
+		if key != envelopeDataKey && !envelopeMetaKeys[key] {
+			return false
+		}
+	}
+	return true
+}
+
+// siblings returns the members of obj other than key
+func siblings(obj map[string]interface{}, key string) map[string]interface{} {
+	rest := make(map[string]interface{}, len(obj))
+	for k, v := range obj {
+		if k != key {
+			rest[k] = v
+		}
+	}
+	return rest
+}
+
+// metaMemberName returns the last member name of a simple path like "$.meta"
+func metaMemberName(path string) string {
+	path = strings.TrimPrefix(strings.TrimSpace(path), "$")
+	if idx := strings.LastIndexAny(path, ".["); idx != -1 {
+		path = path[idx+1:]
+	}
+	return strings.Trim(path, `"'] `)
+}
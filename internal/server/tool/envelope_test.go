@@ -0,0 +1,74 @@
+package tool
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/brizzai/auto-mcp/internal/config"
+	"github.com/brizzai/auto-mcp/internal/requester"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnwrapEnvelope(t *testing.T) {
+	tests := []struct {
+		name      string
+		heuristic bool
+		dataPath  string
+		metaPath  string
+		body      string
+		data      string
+		meta      string // empty when no metadata is returned
+	}{
+		{name: "heuristic hit", heuristic: true,
+			body: `{"data": [1, 2], "meta": {"total": 2}}`,
+			data: `[1, 2]`, meta: `{"meta": {"total": 2}}`},
+		{name: "heuristic disabled", body: `{"data": [1, 2], "meta": {"total": 2}}`,
+			data: `{"data": [1, 2], "meta": {"total": 2}}`},
+		{name: "heuristic data only", heuristic: true, body: `{"data": [1, 2]}`,
+			data: `{"data": [1, 2]}`},
+		{name: "heuristic unknown member", heuristic: true,
+			body: `{"data": [1, 2], "error": null}`,
+			data: `{"data": [1, 2], "error": null}`},
+		{name: "heuristic non-object body", heuristic: true, body: `[{"data": 1}]`,
+			data: `[{"data": 1}]`},
+		{name: "invalid JSON", heuristic: true, body: `data: 1`, data: `data: 1`},
+		{name: "configured hit", dataPath: "$.items",
+			body: `{"items": [{"id": 1}], "next": "abc"}`,
+			data: `[{"id": 1}]`, meta: `{"next": "abc"}`},
+		{name: "configured meta path", dataPath: "$.items", metaPath: "$.paging",
+			body: `{"items": [], "paging": {"next": "abc"}, "ignored": true}`,
+			data: `[]`, meta: `{"paging": {"next": "abc"}}`},
+		{name: "configured nested path", dataPath: "$.result.items",
+			body: `{"result": {"items": [1], "total": 1}, "items": "top", "status": "ok"}`,
+			data: `[1]`, meta: `{"result": {"total": 1}, "items": "top", "status": "ok"}`},
+		{name: "configured nested path only child", dataPath: "$.result.items",
+			body: `{"result": {"items": [1]}, "status": "ok"}`,
+			data: `[1]`, meta: `{"status": "ok"}`},
+		{name: "configured missing path", dataPath: "$.items",
+			body: `{"data": [1]}`, data: `{"data": [1]}`},
+		{name: "configured non-object body", dataPath: "$.items",
+			body: `[1, 2]`, data: `[1, 2]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Handler{cfg: &config.Config{EndpointConfig: config.EndpointConfig{UnwrapEnvelopes: tt.heuristic}}}
+			route := &requester.RouteConfig{UnwrapData: tt.dataPath, UnwrapMeta: tt.metaPath}
+
+			data, meta := h.unwrapEnvelope(compileEnvelope("test", route), []byte(tt.body))
+			if tt.data == tt.body {
+				assert.Equal(t, tt.body, string(data))
+			} else {
+				assert.JSONEq(t, tt.data, string(data))
+			}
+			if tt.meta == "" {
+				assert.Nil(t, meta)
+				return
+			}
+			encoded, err := json.Marshal(meta)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.meta, string(encoded))
+		})
+	}
+}
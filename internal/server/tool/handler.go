@@ -2,38 +2,72 @@
 package tool
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
+	"sync"
 
 	"github.com/brizzai/auto-mcp/internal/auth/middleware"
+	"github.com/brizzai/auto-mcp/internal/auth/policy"
+	"github.com/brizzai/auto-mcp/internal/config"
 	"github.com/brizzai/auto-mcp/internal/logger"
 	"github.com/brizzai/auto-mcp/internal/requester"
+	"github.com/brizzai/auto-mcp/internal/transform"
 	"github.com/mark3labs/mcp-go/mcp"
 	"go.uber.org/zap"
 )
 
 // Handler manages tool execution and authentication.
 type Handler struct {
-	auth *bool // nil if auth is disabled, non-nil if enabled
+	auth    *bool // nil if auth is disabled, non-nil if enabled
+	cfg     *config.Config
+	policy  *policy.Policy  // nil allows every authenticated user to call every tool
+	tenants *policy.Tenants // nil sends every user to the default endpoint
+	// payloads keeps full tabular payloads, nil when they are not kept
+	payloads PayloadStore
+
+	// inFlight caps the calls running across all tools, toolSlots those of
+	// each tool by name
+	inFlight  semaphore
+	slotsMu   sync.Mutex
+	toolSlots map[string]semaphore
 }
 
-// NewHandler creates a new tool handler.
-func NewHandler(authEnabled bool) *Handler {
+// NewHandler creates a new tool handler. authz restricts which tools
+// authenticated users may call and tenants picks their upstream endpoint;
+// both are only applied when auth is enabled.
+func NewHandler(cfg *config.Config, authEnabled bool, authz *policy.Policy, tenants *policy.Tenants) *Handler {
+	h := &Handler{cfg: cfg, toolSlots: make(map[string]semaphore)}
+	if cfg != nil {
+		h.inFlight = newSemaphore(cfg.Server.Concurrency.MaxInFlight)
+	}
 	if authEnabled {
 		enabled := true
-		return &Handler{auth: &enabled}
+		h.auth = &enabled
+		h.policy = authz
+		h.tenants = tenants
 	}
-	return &Handler{auth: nil}
+	return h
 }
 
 // CreateHandler creates a handler function for a specific tool.
 // It handles authentication validation and request execution.
-func (h *Handler) CreateHandler(tool *mcp.Tool, executor requester.RouteExecutor) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func (h *Handler) CreateHandler(tool *mcp.Tool, route *requester.RouteConfig, executor requester.RouteExecutor) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	successCriteria := compileSuccessCriteria(tool.Name, route)
+	routeTransform := compileTransform(tool.Name, route)
+	envelope := compileEnvelope(tool.Name, route)
+	slots := h.toolSemaphore(tool.Name, route)
+
+	return traced(tool.Name, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var authInfo *middleware.AuthInfo
+
 		// Validate authentication if enabled
 		if h.auth != nil {
-			authInfo, ok := ctx.Value(middleware.AuthContextKey).(*middleware.AuthInfo)
+			var ok bool
+			authInfo, ok = ctx.Value(middleware.AuthContextKey).(*middleware.AuthInfo)
 			if !ok {
 				logger.Error("Failed to get auth info from context",
 					zap.String("tool", tool.Name),
@@ -45,20 +79,173 @@ func (h *Handler) CreateHandler(tool *mcp.Tool, executor requester.RouteExecutor
 				zap.String("tool", tool.Name),
 				zap.String("user", authInfo.UserID),
 			)
+			var method string
+			if route != nil {
+				method = route.Method
+			}
+			if !h.Allowed(authInfo, tool.Name, method) {
+				logger.Warn("Denied tool call by authorization policy",
+					zap.String("tool", tool.Name),
+					zap.String("user", authInfo.UserID),
+				)
+				return mcp.NewToolResultError("Forbidden: you are not allowed to call this tool"), nil
+			}
+			ctx = requester.WithUpstreamToken(ctx, authInfo.Token)
+			if tenant, ok := h.tenants.Resolve(authInfo); ok {
+				ctx = requester.WithTenant(ctx, tenant)
+			}
+		}
+
+		params, err := decodeArguments(request)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Invalid arguments: %v", err)), nil
+		}
+		// _fresh is a control argument, never forwarded upstream
+		if fresh, ok := params[requester.FreshArgument].(bool); ok && fresh {
+			ctx = requester.WithFreshResult(ctx)
+		}
+		delete(params, requester.FreshArgument)
+
+		if route != nil {
+			mapArguments(params, route.Renames, route.Hidden)
+		}
+		if route != nil && len(route.Constants) > 0 {
+			if err := applyConstants(params, route.Constants, authInfo); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid arguments: %v", err)), nil
+			}
+		}
+		if route != nil {
+			applyDefaults(params, route.Defaults, authInfo)
+			if h.cfg == nil || !h.cfg.EndpointConfig.StrictDates {
+				normalizeDates(params, route.DateFormats)
+			}
+		}
+
+		// Transforms see the arguments after defaults, and run before the
+		// on-behalf-of check so they cannot set the identity header either
+		if routeTransform != nil {
+			headers, err := routeTransform.Request(params, map[string]any{
+				"tool":   tool.Name,
+				"method": route.Method,
+				"path":   route.Path,
+				"args":   params,
+				"user":   templateUser(authInfo),
+			})
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Request transform failed: %v", err)), nil
+			}
+			if len(headers) > 0 {
+				ctx = requester.WithHeaders(ctx, headers)
+			}
+		}
+
+		if route != nil && route.OnBehalfOf {
+			// Without a configuration the default header and claim apply
+			var obo config.OnBehalfOfConfig
+			if h.cfg != nil {
+				obo = h.cfg.EndpointConfig.OnBehalfOf
+			}
+			identity, ok := userClaim(authInfo, obo.ClaimName())
+			if !ok {
+				return mcp.NewToolResultError("Unauthorized: this tool acts on behalf of the signed-in user, but no user identity is available"), nil
+			}
+			// The model must not be able to impersonate someone else
+			for name := range params {
+				if strings.EqualFold(name, obo.HeaderName()) {
+					delete(params, name)
+				}
+			}
+			ctx = requester.WithCallerIdentity(ctx, identity)
+		}
+
+		release, message := h.acquire(tool.Name, slots)
+		if message != "" {
+			return mcp.NewToolResultError(message), nil
 		}
 
 		// Execute the tool request
-		params := request.GetArguments()
-		resp, err := executor(ctx, params)
+		resp, err := executor(requester.WithToolName(ctx, tool.Name), params)
+		release()
 		if err != nil {
-			return nil, fmt.Errorf("failed to execute request for tool %s: %w", tool.Name, err)
+			return nil, fmt.Errorf("failed to execute request for tool %s: %w%s", tool.Name, err, h.supportHint())
 		}
+		h.convertHTML(route, resp)
+		tabular := h.convertTabular(resp)
 
 		// Handle error responses
 		if resp.StatusCode >= http.StatusBadRequest {
-			return mcp.NewToolResultError(fmt.Sprintf("HTTP Error %d: %s", resp.StatusCode, string(resp.Body))), nil
+			return h.errorResult(resp), nil
+		}
+
+		// Some APIs report failures inside a 2xx body
+		if successCriteria != nil && !isSuccessful(successCriteria, resp.Body) {
+			body := h.limitMessageSize(string(resp.Body))
+			return mcp.NewToolResultError(fmt.Sprintf("Upstream reported failure (%s not satisfied): %s", successCriteria, body)), nil
+		}
+
+		// Strip {"data": ..., "meta": ...} envelopes, keeping meta out of the model's text
+		data, meta := h.unwrapEnvelope(envelope, resp.Body)
+		if routeTransform.HasResponse() {
+			rendered, err := routeTransform.Response(map[string]any{
+				"tool":    tool.Name,
+				"status":  resp.StatusCode,
+				"headers": resp.Headers,
+				"body":    transform.DecodeBody(data),
+				"args":    params,
+				"user":    templateUser(authInfo),
+			})
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Response transform failed: %v", err)), nil
+			}
+			data = []byte(rendered)
 		}
+		result := mcp.NewToolResultText(h.limitMessageSize(string(data)) + tabularNote(tabular))
+		if len(meta) > 0 {
+			result.Meta = meta
+		}
+		if tabular != nil {
+			if result.Meta == nil {
+				result.Meta = make(map[string]any, 1)
+			}
+			result.Meta[tabularMetaKey] = tabular
+		}
+		return result, nil
+	})
+}
+
+// Allowed reports whether the authorization policy lets the user call the
+// tool. method is the HTTP method of the tool's upstream request, if any.
+// Tools are matched by their name without the server tool prefix.
+func (h *Handler) Allowed(authInfo *middleware.AuthInfo, toolName, method string) bool {
+	if h.policy == nil {
+		return true
+	}
+	if h.cfg != nil {
+		toolName = strings.TrimPrefix(toolName, h.cfg.Server.ToolPrefix)
+	}
+	return h.policy.Allowed(authInfo, toolName, method)
+}
+
+// decodeArguments returns the tool call arguments as a map. The server
+// decodes numbers as json.Number, so large integers and precise decimals
+// reach the upstream API unchanged; raw JSON arguments are decoded the same way.
+func decodeArguments(request mcp.CallToolRequest) (map[string]interface{}, error) {
+	raw, ok := request.GetRawArguments().(json.RawMessage)
+	if !ok {
+		if args := request.GetArguments(); args != nil {
+			return args, nil
+		}
+		return make(map[string]interface{}), nil
+	}
 
-		return mcp.NewToolResultText(string(resp.Body)), nil
+	params := make(map[string]interface{})
+	if len(raw) == 0 {
+		return params, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&params); err != nil {
+		return nil, err
 	}
+	return params, nil
 }
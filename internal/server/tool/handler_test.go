@@ -0,0 +1,469 @@
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/brizzai/auto-mcp/internal/auth/middleware"
+	"github.com/brizzai/auto-mcp/internal/auth/policy"
+	"github.com/brizzai/auto-mcp/internal/config"
+	"github.com/brizzai/auto-mcp/internal/requester"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func callWithStatus(t *testing.T, cfg *config.Config, status int) *mcp.CallToolResult {
+	t.Helper()
+	tool := mcp.NewTool("get_orders")
+	executor := func(ctx context.Context, params map[string]interface{}) (*requester.Response, error) {
+		return &requester.Response{StatusCode: status, Body: []byte("upstream failure"), Headers: http.Header{}}, nil
+	}
+
+	handler := NewHandler(cfg, false, nil, nil).CreateHandler(&tool, &requester.RouteConfig{Path: "/orders", Method: "GET"}, executor)
+	result, err := handler(context.Background(), mcp.CallToolRequest{})
+	require.NoError(t, err)
+	require.True(t, result.IsError)
+	return result
+}
+
+func TestCreateHandler_SupportHint(t *testing.T) {
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			Support: config.SupportConfig{Contact: "#api-support", RunbookURL: "https://runbooks.example.com/orders"},
+		},
+	}
+
+	result := callWithStatus(t, cfg, http.StatusBadGateway)
+	assert.Equal(t, "HTTP Error 502: upstream failure\n\nIf this persists, see https://runbooks.example.com/orders or contact #api-support.",
+		result.Content[0].(mcp.TextContent).Text)
+
+	// Client errors are the caller's to fix, so no escalation hint
+	result = callWithStatus(t, cfg, http.StatusNotFound)
+	assert.Equal(t, "HTTP Error 404: upstream failure", result.Content[0].(mcp.TextContent).Text)
+
+	// Nothing is appended without support configuration
+	result = callWithStatus(t, &config.Config{}, http.StatusInternalServerError)
+	assert.Equal(t, "HTTP Error 500: upstream failure", result.Content[0].(mcp.TextContent).Text)
+}
+
+func TestCreateHandler_ProblemDetails(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		headers  http.Header
+		body     string
+		wantText string
+		wantMeta bool
+	}{
+		{
+			name:    "problem+json",
+			status:  http.StatusUnprocessableEntity,
+			headers: http.Header{"Content-Type": {"application/problem+json"}},
+			body: `{"type": "https://example.com/probs/out-of-stock", "title": "Out of stock", "status": 422,
+				"detail": "Item 12 has 0 units left", "instance": "/orders/7", "traceId": "abc-123",
+				"errors": [{"field": "quantity", "message": "must be at most 0"}]}`,
+			wantText: "HTTP Error 422: Out of stock\nDetail: Item 12 has 0 units left\nType: https://example.com/probs/out-of-stock\nInstance: /orders/7\nCorrelation ID: abc-123\nErrors: [{\"field\":\"quantity\",\"message\":\"must be at most 0\"}]",
+			wantMeta: true,
+		},
+		{
+			name:     "nested error object",
+			status:   http.StatusNotFound,
+			headers:  http.Header{"Content-Type": {"application/json"}, "X-Request-Id": {"req-9"}},
+			body:     `{"error": {"code": 404, "message": "Order 7 not found"}}`,
+			wantText: "HTTP Error 404: Not Found\nDetail: Order 7 not found\nCorrelation ID: req-9",
+			wantMeta: true,
+		},
+		{
+			name:     "oauth style error",
+			status:   http.StatusBadRequest,
+			headers:  http.Header{},
+			body:     `{"error": "invalid_request", "error_description": "limit must be positive"}`,
+			wantText: "HTTP Error 400: invalid_request\nDetail: limit must be positive",
+			wantMeta: true,
+		},
+		{
+			name:     "unknown JSON",
+			status:   http.StatusBadRequest,
+			headers:  http.Header{},
+			body:     `{"ok": false}`,
+			wantText: `HTTP Error 400: {"ok": false}`,
+		},
+		{
+			name:     "plain text",
+			status:   http.StatusBadRequest,
+			headers:  http.Header{"X-Request-Id": {"req-9"}},
+			body:     "bad request",
+			wantText: "HTTP Error 400: bad request",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tool := mcp.NewTool("create_order")
+			executor := func(ctx context.Context, params map[string]interface{}) (*requester.Response, error) {
+				return &requester.Response{StatusCode: tt.status, Body: []byte(tt.body), Headers: tt.headers}, nil
+			}
+			handler := NewHandler(&config.Config{}, false, nil, nil).CreateHandler(&tool, &requester.RouteConfig{Path: "/orders", Method: "POST"}, executor)
+			result, err := handler(context.Background(), mcp.CallToolRequest{})
+			require.NoError(t, err)
+			require.True(t, result.IsError)
+			assert.Equal(t, tt.wantText, result.Content[0].(mcp.TextContent).Text)
+			if !tt.wantMeta {
+				assert.Nil(t, result.Meta)
+				return
+			}
+			p, ok := result.Meta["error"].(*problem)
+			require.True(t, ok)
+			assert.Equal(t, tt.status, p.Status)
+		})
+	}
+}
+
+func TestCreateHandler_AuthErrors(t *testing.T) {
+	result := callWithStatus(t, &config.Config{}, http.StatusUnauthorized)
+	assert.Equal(t, "Authentication required: the upstream API rejected the credentials this MCP server is configured with. "+
+		"Retrying will not help; tell the user the server's upstream credentials are missing, invalid or expired.\n\nHTTP Error 401: upstream failure",
+		result.Content[0].(mcp.TextContent).Text)
+	assert.Equal(t, true, result.Meta["auth_required"])
+
+	// With forwarded tokens the caller signs in again
+	tool := mcp.NewTool("get_orders")
+	executor := func(ctx context.Context, params map[string]interface{}) (*requester.Response, error) {
+		return &requester.Response{StatusCode: http.StatusUnauthorized, Body: []byte("expired"), Headers: http.Header{}}, nil
+	}
+	cfg := &config.Config{EndpointConfig: config.EndpointConfig{ForwardAuthToken: true}}
+	handler := NewHandler(cfg, true, nil, nil).CreateHandler(&tool, &requester.RouteConfig{Path: "/orders", Method: "GET"}, executor)
+	ctx := context.WithValue(context.Background(), middleware.AuthContextKey, &middleware.AuthInfo{UserID: "alice", Token: "stale"})
+	result, err := handler(ctx, mcp.CallToolRequest{})
+	require.NoError(t, err)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "ask the user to sign in to this MCP server again")
+
+	result = callWithStatus(t, &config.Config{}, http.StatusForbidden)
+	assert.Equal(t, "Permission denied: the upstream API accepted the credentials but does not allow this operation. "+
+		"Retrying will not help; ask the user for access or use another operation.\n\nHTTP Error 403: upstream failure",
+		result.Content[0].(mcp.TextContent).Text)
+	assert.Nil(t, result.Meta)
+}
+
+func TestCreateHandler_HTMLResponses(t *testing.T) {
+	const page = "<!DOCTYPE html><html><body><h1>Report</h1><p>All <b>good</b></p></body></html>"
+	tests := []struct {
+		name        string
+		global      string
+		route       string
+		contentType string
+		want        string
+	}{
+		{name: "raw by default", contentType: "text/html", want: page},
+		{name: "global markdown", global: "markdown", contentType: "text/html; charset=utf-8", want: "# Report\n\nAll **good**"},
+		{name: "route overrides global", global: "markdown", route: "text", contentType: "text/html", want: "Report\n\nAll good"},
+		{name: "route keeps raw", global: "text", route: "raw", contentType: "text/html", want: page},
+		{name: "unlabelled html", route: "text", want: "Report\n\nAll good"},
+		{name: "json is left alone", route: "text", contentType: "application/json", want: page},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tool := mcp.NewTool("get_report")
+			executor := func(ctx context.Context, params map[string]interface{}) (*requester.Response, error) {
+				headers := http.Header{}
+				if tt.contentType != "" {
+					headers.Set("Content-Type", tt.contentType)
+				}
+				return &requester.Response{StatusCode: http.StatusOK, Body: []byte(page), Headers: headers}, nil
+			}
+			cfg := &config.Config{EndpointConfig: config.EndpointConfig{HTMLResponses: tt.global}}
+			route := &requester.RouteConfig{Path: "/report", Method: "GET", HTMLResponses: tt.route}
+			result, err := NewHandler(cfg, false, nil, nil).CreateHandler(&tool, route, executor)(context.Background(), mcp.CallToolRequest{})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, result.Content[0].(mcp.TextContent).Text)
+		})
+	}
+}
+
+func TestLimitMessageSize(t *testing.T) {
+	tests := []struct {
+		name    string
+		maxSize int
+		text    string
+		want    string
+	}{
+		{name: "no limit", text: "0123456789", want: "0123456789"},
+		{name: "within limit", maxSize: 10, text: "0123456789", want: "0123456789"},
+		{name: "note fits in the limit", maxSize: 64, text: strings.Repeat("a", 100),
+			want: "aaaaaaaaaa\n\n[truncated: result was 100 bytes, limit is 64 bytes]"},
+		{name: "rune boundary", maxSize: 64, text: strings.Repeat("é", 50),
+			want: "ééééé\n\n[truncated: result was 100 bytes, limit is 64 bytes]"},
+		{name: "limit smaller than the note", maxSize: 5, text: "0123456789", want: "01234"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler(&config.Config{Server: config.ServerConfig{MaxMessageSize: tt.maxSize}}, false, nil, nil)
+			got := h.limitMessageSize(tt.text)
+			assert.Equal(t, tt.want, got)
+			if tt.maxSize > 0 {
+				assert.LessOrEqual(t, len(got), tt.maxSize)
+			}
+		})
+	}
+}
+
+type memoryPayloads map[string][]byte
+
+func (m memoryPayloads) Save(data []byte, format string) (string, error) {
+	uri := "test://payload." + format
+	m[uri] = data
+	return uri, nil
+}
+
+func TestCreateHandler_TabularResponses(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		resources   bool
+		wantText    string
+		wantMeta    map[string]any
+	}{
+		{
+			name:        "csv",
+			contentType: "text/csv; charset=utf-8",
+			body:        "id,name\n1,Rex\n2,\"Tom, Jr\"\n",
+			wantText:    `[{"id":"1","name":"Rex"},{"id":"2","name":"Tom, Jr"}]`,
+			wantMeta:    map[string]any{"format": "csv", "rows": 2},
+		},
+		{
+			name:        "ndjson truncated",
+			contentType: "application/x-ndjson",
+			body:        "{\"id\": 1}\n\n{\"id\": 2}\n{\"id\": 3}\n",
+			wantText:    "[{\"id\":1},{\"id\":2}]\n\n[truncated: 2 of 3 rows returned]",
+			wantMeta:    map[string]any{"format": "ndjson", "rows": 3, "returned": 2},
+		},
+		{
+			name:        "tsv kept as resource",
+			contentType: "text/tab-separated-values",
+			body:        "id\n1\n2\n3\n",
+			resources:   true,
+			wantText:    "[{\"id\":\"1\"},{\"id\":\"2\"}]\n\n[truncated: 2 of 3 rows returned, read the resource test://payload.tsv for all rows]",
+			wantMeta:    map[string]any{"format": "tsv", "rows": 3, "returned": 2, "resource": "test://payload.tsv"},
+		},
+		{
+			name:        "invalid ndjson is left alone",
+			contentType: "application/x-ndjson",
+			body:        "{\"id\": 1}\nnot json\n",
+			wantText:    "{\"id\": 1}\nnot json\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tool := mcp.NewTool("export_pets")
+			executor := func(ctx context.Context, params map[string]interface{}) (*requester.Response, error) {
+				return &requester.Response{StatusCode: http.StatusOK, Body: []byte(tt.body), Headers: http.Header{"Content-Type": {tt.contentType}}}, nil
+			}
+			cfg := &config.Config{EndpointConfig: config.EndpointConfig{
+				TabularResponses: config.TabularResponsesConfig{Enabled: true, MaxRows: 2, Resources: tt.resources},
+			}}
+			handler := NewHandler(cfg, false, nil, nil)
+			payloads := memoryPayloads{}
+			handler.SetPayloadStore(payloads)
+			result, err := handler.CreateHandler(&tool, &requester.RouteConfig{Path: "/pets/export", Method: "GET"}, executor)(context.Background(), mcp.CallToolRequest{})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantText, result.Content[0].(mcp.TextContent).Text)
+			if tt.wantMeta == nil {
+				assert.Nil(t, result.Meta)
+				return
+			}
+			assert.Equal(t, tt.wantMeta, result.Meta["tabular"])
+			if tt.resources {
+				assert.Equal(t, tt.body, string(payloads["test://payload.tsv"]))
+			}
+		})
+	}
+}
+
+func TestCreateHandler_Concurrency(t *testing.T) {
+	cfg := &config.Config{Server: config.ServerConfig{Concurrency: config.ConcurrencyConfig{MaxInFlight: 2, PerTool: 1}}}
+	h := NewHandler(cfg, false, nil, nil)
+
+	started := make(chan struct{}, 3)
+	unblock := make(chan struct{})
+	blocking := func(ctx context.Context, params map[string]interface{}) (*requester.Response, error) {
+		started <- struct{}{}
+		<-unblock
+		return &requester.Response{StatusCode: http.StatusOK, Body: []byte("{}"), Headers: http.Header{}}, nil
+	}
+	handler := func(name string, route *requester.RouteConfig) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		tool := mcp.NewTool(name)
+		return h.CreateHandler(&tool, route, blocking)
+	}
+	call := func(handler func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error)) *mcp.CallToolResult {
+		result, err := handler(context.Background(), mcp.CallToolRequest{})
+		require.NoError(t, err)
+		return result
+	}
+
+	getOrders := handler("get_orders", &requester.RouteConfig{Path: "/orders", Method: "GET"})
+	exportOrders := handler("export_orders", &requester.RouteConfig{Path: "/orders/export", Method: "POST", Policy: requester.RoutePolicy{MaxConcurrency: 2}})
+	results := make(chan *mcp.CallToolResult, 2)
+	go func() { results <- call(getOrders) }()
+	<-started
+
+	// get_orders allows one call at a time
+	result := call(getOrders)
+	require.True(t, result.IsError)
+	assert.Equal(t, "Concurrency limit reached: 1 calls of this tool are already running. Retry once one of them has completed.",
+		result.Content[0].(mcp.TextContent).Text)
+
+	// export_orders allows two, but the server only one more
+	go func() { results <- call(exportOrders) }()
+	<-started
+	result = call(exportOrders)
+	require.True(t, result.IsError)
+	assert.Equal(t, "Concurrency limit reached: the server is already running 2 tool calls. Retry in a few seconds.",
+		result.Content[0].(mcp.TextContent).Text)
+
+	// Finished calls free their slots
+	close(unblock)
+	for range 2 {
+		assert.False(t, (<-results).IsError)
+	}
+	assert.False(t, call(getOrders).IsError)
+}
+
+func TestCreateHandler_OnBehalfOfWithoutConfig(t *testing.T) {
+	tool := mcp.NewTool("get_orders")
+	var identity string
+	executor := func(ctx context.Context, params map[string]interface{}) (*requester.Response, error) {
+		identity, _ = requester.CallerIdentityFromContext(ctx)
+		assert.NotContains(t, params, "x-on-behalf-of", "callers cannot choose the identity")
+		return &requester.Response{StatusCode: http.StatusOK, Body: []byte(`[]`), Headers: http.Header{}}, nil
+	}
+	route := &requester.RouteConfig{Path: "/orders", Method: "GET", OnBehalfOf: true}
+	handler := NewHandler(nil, true, nil, nil).CreateHandler(&tool, route, executor)
+
+	ctx := context.WithValue(context.Background(), middleware.AuthContextKey, &middleware.AuthInfo{UserID: "1", Email: "alice@example.com"})
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"x-on-behalf-of": "mallory@example.com"}
+	result, err := handler(ctx, request)
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+	assert.Equal(t, "alice@example.com", identity)
+}
+
+func TestCreateHandler_Policy(t *testing.T) {
+	authz, err := policy.New(config.PolicyConfig{
+		Rules: []config.PolicyRule{{Methods: []string{"DELETE"}, Allow: []string{"role:admin"}}},
+	})
+	require.NoError(t, err)
+	cfg := &config.Config{Server: config.ServerConfig{ToolPrefix: "shop_"}}
+
+	executor := func(ctx context.Context, params map[string]interface{}) (*requester.Response, error) {
+		return &requester.Response{StatusCode: http.StatusNoContent, Headers: http.Header{}}, nil
+	}
+	tool := mcp.NewTool("shop_delete_order")
+	handler := NewHandler(cfg, true, authz, nil).CreateHandler(&tool, &requester.RouteConfig{Path: "/orders/{id}", Method: "DELETE"}, executor)
+
+	call := func(authInfo *middleware.AuthInfo) *mcp.CallToolResult {
+		ctx := context.WithValue(context.Background(), middleware.AuthContextKey, authInfo)
+		result, err := handler(ctx, mcp.CallToolRequest{})
+		require.NoError(t, err)
+		return result
+	}
+
+	result := call(&middleware.AuthInfo{UserID: "1"})
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "Forbidden")
+
+	result = call(&middleware.AuthInfo{UserID: "2", Claims: map[string]interface{}{"roles": []interface{}{"admin"}}})
+	assert.False(t, result.IsError)
+}
+
+func TestCreateHandler_Transform(t *testing.T) {
+	var sent map[string]interface{}
+	executor := func(ctx context.Context, params map[string]interface{}) (*requester.Response, error) {
+		sent = params
+		return &requester.Response{
+			StatusCode: http.StatusOK,
+			Body:       []byte(`{"items": [{"id": 1, "name": "rex"}, {"id": 2, "name": "tom"}]}`),
+			Headers:    http.Header{},
+		}, nil
+	}
+	tool := mcp.NewTool("get_pets")
+	route := &requester.RouteConfig{Path: "/pets", Method: "GET", Transform: requester.TransformConfig{
+		Arguments: map[string]string{"limit": `{{ default 10 .args.limit }}`, "owner": `{{ .user.email }}`},
+		Response:  `{{ range .body.items }}- {{ .name }}{{ "\n" }}{{ end }}`,
+	}}
+	handler := NewHandler(&config.Config{}, true, nil, nil).CreateHandler(&tool, route, executor)
+
+	ctx := context.WithValue(context.Background(), middleware.AuthContextKey, &middleware.AuthInfo{UserID: "1", Email: "ann@example.com"})
+	result, err := handler(ctx, mcp.CallToolRequest{})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	assert.Equal(t, "- rex\n- tom\n", result.Content[0].(mcp.TextContent).Text)
+	assert.Equal(t, json.Number("10"), sent["limit"])
+	assert.Equal(t, "ann@example.com", sent["owner"])
+}
+
+func TestCreateHandler_Constants(t *testing.T) {
+	var sent map[string]interface{}
+	executor := func(ctx context.Context, params map[string]interface{}) (*requester.Response, error) {
+		sent = params
+		return &requester.Response{StatusCode: http.StatusOK, Body: []byte(`{}`), Headers: http.Header{}}, nil
+	}
+	tool := mcp.NewTool("post_reports")
+	route := &requester.RouteConfig{Path: "/reports", Method: "POST", Constants: map[string]string{
+		"api_version": "2024-01",
+		"body.owner":  "{{user.email}}",
+	}}
+	handler := NewHandler(&config.Config{}, true, nil, nil).CreateHandler(&tool, route, executor)
+
+	call := func(authInfo *middleware.AuthInfo) *mcp.CallToolResult {
+		ctx := context.WithValue(context.Background(), middleware.AuthContextKey, authInfo)
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]interface{}{
+			"api_version": "1999-01",
+			"body":        map[string]interface{}{"name": "weekly", "owner": "someone@else.com"},
+		}
+		result, err := handler(ctx, request)
+		require.NoError(t, err)
+		return result
+	}
+
+	// Constants replace what the model sent
+	result := call(&middleware.AuthInfo{UserID: "1", Email: "ann@example.com"})
+	require.False(t, result.IsError)
+	assert.Equal(t, "2024-01", sent["api_version"])
+	assert.Equal(t, map[string]interface{}{"name": "weekly", "owner": "ann@example.com"}, sent["body"])
+
+	// A constant whose claim is missing fails the call rather than passing the model's value
+	sent = nil
+	result = call(&middleware.AuthInfo{UserID: "1"})
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, `argument body.owner: user claim "email" is not available`)
+	assert.Nil(t, sent)
+}
+
+func TestMapArguments(t *testing.T) {
+	params := map[string]interface{}{
+		"pet_status": "sold",
+		"status":     "injected",
+		"debug":      true,
+		"a":          1,
+		"b":          2,
+		"body":       map[string]interface{}{"name": "rex", "internal_id": 7},
+	}
+	mapArguments(params,
+		map[string]string{"status": "pet_status", "a": "b", "b": "a", "body.petName": "name"},
+		[]string{"debug", "body.internal_id"},
+	)
+
+	assert.Equal(t, map[string]interface{}{
+		"status": "sold",
+		"a":      2,
+		"b":      1,
+		"body":   map[string]interface{}{"petName": "rex"},
+	}, params)
+}
@@ -0,0 +1,46 @@
+package tool
+
+import "strings"
+
+// mapArguments turns renamed arguments back into the route's parameter names
+// and drops hidden parameters. Values the caller sent under a renamed
+// parameter's real name are dropped too, as the tool does not offer it.
+func mapArguments(params map[string]interface{}, renames map[string]string, hidden []string) {
+	for _, name := range hidden {
+		if container, key, ok := lookupArgument(params, name); ok {
+			delete(container, key)
+		}
+	}
+
+	type move struct {
+		container map[string]interface{}
+		key       string
+		value     interface{}
+	}
+	// Collect every value before deleting any, so swapped names work
+	moves := make([]move, 0, len(renames))
+	for real, name := range renames {
+		prefix, key := splitArgument(real)
+		if container, argument, ok := lookupArgument(params, prefix+name); ok {
+			moves = append(moves, move{container: container, key: key, value: container[argument]})
+		}
+	}
+	for real, name := range renames {
+		prefix, _ := splitArgument(real)
+		for _, argument := range []string{real, prefix + name} {
+			if container, key, ok := lookupArgument(params, argument); ok {
+				delete(container, key)
+			}
+		}
+	}
+	for _, m := range moves {
+		m.container[m.key] = m.value
+	}
+}
+
+// splitArgument splits a dotted argument path into its prefix, including
+// the final dot, and the last segment
+func splitArgument(path string) (prefix, key string) {
+	i := strings.LastIndexByte(path, '.')
+	return path[:i+1], path[i+1:]
+}
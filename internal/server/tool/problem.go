@@ -0,0 +1,215 @@
+package tool
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/brizzai/auto-mcp/internal/jsonpath"
+	"github.com/brizzai/auto-mcp/internal/requester"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// problemMetaKey is the result metadata member holding the parsed error
+const problemMetaKey = "error"
+
+// authRequiredMetaKey flags results of upstream 401 responses in their metadata
+const authRequiredMetaKey = "auth_required"
+
+// Members of common JSON error bodies, checked in order
+var (
+	problemTitleKeys       = []string{"title", "error", "code"}
+	problemDetailKeys      = []string{"detail", "message", "error_description", "description"}
+	problemErrorsKeys      = []string{"errors", "invalid-params", "invalid_params", "violations", "details"}
+	problemCorrelationKeys = []string{"correlation_id", "correlationId", "request_id", "requestId", "trace_id", "traceId"}
+)
+
+// correlationHeaders carry the upstream's request or trace ID
+var correlationHeaders = []string{"X-Correlation-Id", "X-Request-Id", "X-Amzn-Requestid", "X-Trace-Id"}
+
+// problem is an upstream error parsed from an RFC 9457 problem+json body or
+// a JSON error body of a common shape
+type problem struct {
+	Status        int    `json:"status"`
+	Title         string `json:"title,omitempty"`
+	Detail        string `json:"detail,omitempty"`
+	Type          string `json:"type,omitempty"`
+	Instance      string `json:"instance,omitempty"`
+	CorrelationID string `json:"correlation_id,omitempty"`
+	// Errors lists field or validation errors as the upstream sent them
+	Errors any `json:"errors,omitempty"`
+}
+
+// parseProblem parses the error body of resp. ok is false when the body is
+// not a JSON object or holds none of the known error members, in which case
+// the raw body is reported.
+func parseProblem(resp *requester.Response) (*problem, bool) {
+	doc, err := jsonpath.Decode(resp.Body)
+	if err != nil {
+		return nil, false
+	}
+	obj, ok := doc.(map[string]any)
+	if !ok {
+		return nil, false
+	}
+
+	p := &problem{Status: resp.StatusCode}
+	if isProblemJSON(resp.Headers.Get("Content-Type")) {
+		p.Type = stringMember(obj, "type")
+		p.Instance = stringMember(obj, "instance")
+	}
+	// {"error": {"message": ..., "code": ...}} nests the error one level down
+	if nested, ok := obj["error"].(map[string]any); ok {
+		p.fill(nested)
+	}
+	p.fill(obj)
+	if p.Title == "" && p.Detail == "" && p.Errors == nil {
+		return nil, false
+	}
+	if p.CorrelationID == "" {
+		for _, header := range correlationHeaders {
+			if id := resp.Headers.Get(header); id != "" {
+				p.CorrelationID = id
+				break
+			}
+		}
+	}
+	return p, true
+}
+
+// fill sets the members of p still empty from obj
+func (p *problem) fill(obj map[string]any) {
+	// Numeric codes such as {"code": 404} repeat the status, so titles are strings only
+	for _, key := range problemTitleKeys {
+		if title, ok := obj[key].(string); ok && p.Title == "" {
+			p.Title = strings.TrimSpace(title)
+			break
+		}
+	}
+	if p.Detail == "" {
+		p.Detail = firstString(obj, problemDetailKeys)
+	}
+	if p.Detail == p.Title {
+		p.Detail = ""
+	}
+	if p.CorrelationID == "" {
+		p.CorrelationID = firstString(obj, problemCorrelationKeys)
+	}
+	if p.Errors == nil {
+		for _, key := range problemErrorsKeys {
+			if errs, ok := obj[key]; ok && errs != nil {
+				if _, isString := errs.(string); !isString {
+					p.Errors = errs
+					break
+				}
+			}
+		}
+	}
+}
+
+// text renders p for the model, one member per line
+func (p *problem) text() string {
+	title := p.Title
+	if title == "" {
+		title = http.StatusText(p.Status)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "HTTP Error %d: %s", p.Status, title)
+	if p.Detail != "" {
+		fmt.Fprintf(&b, "\nDetail: %s", p.Detail)
+	}
+	if p.Type != "" && p.Type != "about:blank" {
+		fmt.Fprintf(&b, "\nType: %s", p.Type)
+	}
+	if p.Instance != "" {
+		fmt.Fprintf(&b, "\nInstance: %s", p.Instance)
+	}
+	if p.CorrelationID != "" {
+		fmt.Fprintf(&b, "\nCorrelation ID: %s", p.CorrelationID)
+	}
+	if p.Errors != nil {
+		if errs, err := json.Marshal(p.Errors); err == nil {
+			fmt.Fprintf(&b, "\nErrors: %s", errs)
+		}
+	}
+	return b.String()
+}
+
+// errorResult returns the tool error result of an upstream error response.
+// Parsed error bodies are rendered member by member and attached to the
+// result metadata; other bodies are reported as they are.
+func (h *Handler) errorResult(resp *requester.Response) *mcp.CallToolResult {
+	p, ok := parseProblem(resp)
+	var message string
+	if ok {
+		message = h.limitMessageSize(p.text())
+	} else {
+		message = fmt.Sprintf("HTTP Error %d: %s", resp.StatusCode, h.limitMessageSize(string(resp.Body)))
+	}
+	if resp.StatusCode >= http.StatusInternalServerError {
+		message += h.supportHint()
+	}
+	if guidance := h.authGuidance(resp.StatusCode); guidance != "" {
+		message = guidance + "\n\n" + message
+	}
+	result := mcp.NewToolResultError(message)
+	if ok {
+		result.Meta = map[string]any{problemMetaKey: p}
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		if result.Meta == nil {
+			result.Meta = make(map[string]any, 1)
+		}
+		result.Meta[authRequiredMetaKey] = true
+	}
+	return result
+}
+
+// authGuidance explains a 401 or 403 upstream response so the model stops
+// retrying a call that cannot succeed with the credentials it runs with.
+// It returns "" for other statuses.
+func (h *Handler) authGuidance(status int) string {
+	switch status {
+	case http.StatusUnauthorized:
+		if h.auth != nil && h.cfg != nil && h.cfg.EndpointConfig.ForwardAuthToken {
+			return "Authentication required: the upstream API rejected your token. Retrying will not help; " +
+				"ask the user to sign in to this MCP server again, then retry."
+		}
+		return "Authentication required: the upstream API rejected the credentials this MCP server is configured with. " +
+			"Retrying will not help; tell the user the server's upstream credentials are missing, invalid or expired."
+	case http.StatusForbidden:
+		return "Permission denied: the upstream API accepted the credentials but does not allow this operation. " +
+			"Retrying will not help; ask the user for access or use another operation."
+	}
+	return ""
+}
+
+// isProblemJSON reports whether contentType is application/problem+json
+func isProblemJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == "application/problem+json"
+}
+
+// firstString returns the first of keys holding a non-empty string or
+// number in obj
+func firstString(obj map[string]any, keys []string) string {
+	for _, key := range keys {
+		if s := stringMember(obj, key); s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// stringMember returns the string or number member key of obj, or ""
+func stringMember(obj map[string]any, key string) string {
+	switch v := obj[key].(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case json.Number:
+		return v.String()
+	}
+	return ""
+}
@@ -0,0 +1,85 @@
+package tool
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/brizzai/auto-mcp/internal/config"
+	"github.com/brizzai/auto-mcp/internal/htmltext"
+	"github.com/brizzai/auto-mcp/internal/requester"
+)
+
+// convertHTML replaces an HTML response body with readable markdown or text
+// when the route's responses setting or endpoint.html_responses asks for it.
+// Bodies without a content type or labelled text/plain are converted when
+// they start like an HTML document.
+func (h *Handler) convertHTML(route *requester.RouteConfig, resp *requester.Response) {
+	mode := ""
+	if route != nil {
+		mode = route.HTMLResponses
+	}
+	if mode == "" && h.cfg != nil {
+		mode = h.cfg.EndpointConfig.HTMLResponses
+	}
+	if mode == "" || mode == config.HTMLResponsesRaw || !isHTMLResponse(resp) {
+		return
+	}
+
+	switch mode {
+	case config.HTMLResponsesMarkdown:
+		resp.Body = []byte(htmltext.Convert(string(resp.Body), htmltext.FormatMarkdown))
+	case config.HTMLResponsesText:
+		resp.Body = []byte(htmltext.Convert(string(resp.Body), htmltext.FormatText))
+	}
+}
+
+// isHTMLResponse reports whether resp is an HTML document, by its content
+// type or, for unlabelled and text/plain bodies, by its first tag
+func isHTMLResponse(resp *requester.Response) bool {
+	contentType := resp.Headers.Get("Content-Type")
+	if htmltext.IsHTML(contentType) {
+		return true
+	}
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	if mediaType != "" && mediaType != "text/plain" {
+		return false
+	}
+	start := bytes.TrimSpace(resp.Body[:min(len(resp.Body), 512)])
+	start = bytes.ToLower(start[:min(len(start), 14)])
+	return bytes.HasPrefix(start, []byte("<!doctype html")) || bytes.HasPrefix(start, []byte("<html"))
+}
+
+// limitMessageSize truncates text to the configured maximum MCP message size,
+// ending it with a note so the model knows the result is incomplete. The note
+// counts towards the limit; limits too small to hold it cut the text alone.
+func (h *Handler) limitMessageSize(text string) string {
+	if h.cfg == nil || h.cfg.Server.MaxMessageSize <= 0 || len(text) <= h.cfg.Server.MaxMessageSize {
+		return text
+	}
+
+	maxSize := h.cfg.Server.MaxMessageSize
+	note := fmt.Sprintf("\n\n[truncated: result was %d bytes, limit is %d bytes]", len(text), maxSize)
+	if len(note) > maxSize {
+		note = ""
+	}
+	limit := maxSize - len(note)
+	// Back off to a rune boundary so we never emit invalid UTF-8
+	for limit > 0 && !utf8.RuneStart(text[limit]) {
+		limit--
+	}
+	return text[:limit] + note
+}
+
+// supportHint returns the configured escalation hint for server-side failures,
+// separated from the preceding message, or "" if none is configured
+func (h *Handler) supportHint() string {
+	if h.cfg == nil {
+		return ""
+	}
+	if hint := h.cfg.Server.Support.Hint(); hint != "" {
+		return "\n\n" + hint
+	}
+	return ""
+}
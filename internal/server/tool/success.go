@@ -0,0 +1,35 @@
+package tool
+
+import (
+	"github.com/brizzai/auto-mcp/internal/jsonpath"
+	"github.com/brizzai/auto-mcp/internal/logger"
+	"github.com/brizzai/auto-mcp/internal/requester"
+	"go.uber.org/zap"
+)
+
+// compileSuccessCriteria compiles the route's success expression, if any
+func compileSuccessCriteria(toolName string, route *requester.RouteConfig) *jsonpath.Condition {
+	if route == nil || route.SuccessCriteria == "" {
+		return nil
+	}
+	condition, err := jsonpath.ParseCondition(route.SuccessCriteria)
+	if err != nil {
+		// Expressions are validated when adjustments are loaded, so this is unexpected
+		logger.Error("Invalid success criteria, ignoring",
+			zap.String("tool", toolName),
+			zap.String("expression", route.SuccessCriteria),
+			zap.Error(err))
+		return nil
+	}
+	return condition
+}
+
+// isSuccessful reports whether a response body satisfies the success condition.
+// Bodies that are not JSON never satisfy a condition.
+func isSuccessful(condition *jsonpath.Condition, body []byte) bool {
+	doc, err := jsonpath.Decode(body)
+	if err != nil {
+		return false
+	}
+	return condition.Eval(doc)
+}
@@ -0,0 +1,167 @@
+package tool
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+
+	"github.com/brizzai/auto-mcp/internal/logger"
+	"github.com/brizzai/auto-mcp/internal/requester"
+	"go.uber.org/zap"
+)
+
+// tabularMetaKey is the result metadata member describing a converted table
+const tabularMetaKey = "tabular"
+
+// Tabular formats, also the file extension of their stored payloads
+const (
+	formatCSV    = "csv"
+	formatTSV    = "tsv"
+	formatNDJSON = "ndjson"
+)
+
+// tabularMediaTypes maps the media types of tabular responses to their format
+var tabularMediaTypes = map[string]string{
+	"text/csv":                  formatCSV,
+	"application/csv":           formatCSV,
+	"text/tab-separated-values": formatTSV,
+	"application/x-ndjson":      formatNDJSON,
+	"application/ndjson":        formatNDJSON,
+	"application/jsonl":         formatNDJSON,
+	"application/x-jsonlines":   formatNDJSON,
+}
+
+// PayloadStore keeps the full payload of truncated responses so clients can
+// read it later as an MCP resource
+type PayloadStore interface {
+	// Save stores data and returns the URI of its resource. format is the
+	// file extension, e.g. csv.
+	Save(data []byte, format string) (string, error)
+}
+
+// SetPayloadStore stores the full payload of truncated tabular responses in
+// store. It must be called before the server starts.
+func (h *Handler) SetPayloadStore(store PayloadStore) {
+	h.payloads = store
+}
+
+// convertTabular replaces a CSV, TSV or NDJSON response body with a JSON
+// array of at most endpoint.tabular_responses.max_rows rows, when enabled.
+// CSV rows become objects keyed by the header row. It returns the metadata
+// describing the conversion, nil when the body was left unchanged.
+func (h *Handler) convertTabular(resp *requester.Response) map[string]any {
+	if h.cfg == nil || !h.cfg.EndpointConfig.TabularResponses.Enabled {
+		return nil
+	}
+	mediaType, _, err := mime.ParseMediaType(resp.Headers.Get("Content-Type"))
+	if err != nil {
+		return nil
+	}
+	format, ok := tabularMediaTypes[mediaType]
+	if !ok {
+		return nil
+	}
+
+	rows, err := decodeTabular(format, resp.Body)
+	if err != nil {
+		logger.Debug("Returning tabular response unchanged", zap.String("format", format), zap.Error(err))
+		return nil
+	}
+	limit := h.cfg.EndpointConfig.TabularResponses.Rows()
+	meta := map[string]any{"format": format, "rows": len(rows)}
+	if len(rows) > limit {
+		meta["returned"] = limit
+		if h.payloads != nil && h.cfg.EndpointConfig.TabularResponses.Resources {
+			if uri, err := h.payloads.Save(resp.Body, format); err != nil {
+				logger.Warn("Failed to store the full payload", zap.Error(err))
+			} else {
+				meta["resource"] = uri
+			}
+		}
+		rows = rows[:limit]
+	}
+	data, err := json.Marshal(rows)
+	if err != nil {
+		return nil
+	}
+	resp.Body = data
+	return meta
+}
+
+// tabularNote tells the model a converted table was truncated and where the
+// full payload is, or returns "" when every row was returned
+func tabularNote(meta map[string]any) string {
+	returned, ok := meta["returned"]
+	if !ok {
+		return ""
+	}
+	note := fmt.Sprintf("\n\n[truncated: %d of %d rows returned", returned, meta["rows"])
+	if uri, ok := meta["resource"]; ok {
+		note += fmt.Sprintf(", read the resource %s for all rows", uri)
+	}
+	return note + "]"
+}
+
+// decodeTabular decodes the rows of a CSV, TSV or NDJSON body
+func decodeTabular(format string, body []byte) ([]any, error) {
+	if format == formatNDJSON {
+		return decodeNDJSON(body)
+	}
+
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(body, []byte("\ufeff"))))
+	if format == formatTSV {
+		reader.Comma = '\t'
+	}
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return []any{}, nil
+		}
+		return nil, err
+	}
+	rows := []any{}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		row := make(map[string]string, len(header))
+		for i, name := range header {
+			if i < len(record) {
+				row[name] = record[i]
+			}
+		}
+		rows = append(rows, row)
+	}
+}
+
+// decodeNDJSON decodes one JSON value per non-blank line
+func decodeNDJSON(body []byte) ([]any, error) {
+	rows := []any{}
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	scanner.Buffer(nil, len(body)+1)
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		decoder := json.NewDecoder(bytes.NewReader(text))
+		decoder.UseNumber()
+		var row any
+		if err := decoder.Decode(&row); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		rows = append(rows, row)
+	}
+	return rows, scanner.Err()
+}
@@ -0,0 +1,34 @@
+package tool
+
+import (
+	"context"
+
+	"github.com/brizzai/auto-mcp/internal/telemetry"
+	"github.com/mark3labs/mcp-go/mcp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type toolFunc func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error)
+
+// traced wraps a tool handler in a span that parents the upstream request span
+func traced(name string, next toolFunc) toolFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx, span := telemetry.Tracer().Start(ctx, "tools/call "+name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(attribute.String("mcp.tool.name", name)),
+		)
+		defer span.End()
+
+		result, err := next(ctx, request)
+		switch {
+		case err != nil:
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		case result != nil && result.IsError:
+			span.SetStatus(codes.Error, "tool returned an error result")
+		}
+		return result, err
+	}
+}
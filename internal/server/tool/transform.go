@@ -0,0 +1,39 @@
+package tool
+
+import (
+	"github.com/brizzai/auto-mcp/internal/auth/middleware"
+	"github.com/brizzai/auto-mcp/internal/logger"
+	"github.com/brizzai/auto-mcp/internal/requester"
+	"github.com/brizzai/auto-mcp/internal/transform"
+	"go.uber.org/zap"
+)
+
+// compileTransform compiles the route's transform templates, if any
+func compileTransform(toolName string, route *requester.RouteConfig) *transform.Transform {
+	if route == nil {
+		return nil
+	}
+	t, err := transform.Compile(route.Transform.Arguments, route.Transform.Headers, route.Transform.Response)
+	if err != nil {
+		// Templates are validated when adjustments are loaded, so this is unexpected
+		logger.Error("Invalid transform, ignoring", zap.String("tool", toolName), zap.Error(err))
+		return nil
+	}
+	return t
+}
+
+// templateUser exposes the authenticated user to transform templates as
+// id, email, name and the token's other claims
+func templateUser(authInfo *middleware.AuthInfo) map[string]any {
+	if authInfo == nil {
+		return nil
+	}
+	user := make(map[string]any, len(authInfo.Claims)+3)
+	for claim, value := range authInfo.Claims {
+		user[claim] = value
+	}
+	user["id"] = authInfo.UserID
+	user["email"] = authInfo.Email
+	user["name"] = authInfo.Name
+	return user
+}
@@ -0,0 +1,127 @@
+package server
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/brizzai/auto-mcp/internal/logger"
+	"github.com/brizzai/auto-mcp/internal/parser"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+	"go.uber.org/zap"
+)
+
+// ErrUnknownTool indicates a tool name that was never registered
+var ErrUnknownTool = fmt.Errorf("unknown tool")
+
+// ToolStatus describes a registered tool
+type ToolStatus struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+}
+
+// AddRouteTool builds the executor for a route tool and registers it,
+// replacing any tool with the same name. Connected clients receive a
+// notifications/tools/list_changed.
+func (s *Server) AddRouteTool(route *parser.RouteTool) error {
+	executor, err := s.requester.BuildRouteExecutor(route.RouteConfig)
+	if err != nil {
+		return fmt.Errorf("failed to build route executor for %s: %w", route.Tool.Name, err)
+	}
+
+	tool := route.Tool
+	s.toolsMu.Lock()
+	s.methods[tool.Name] = route.RouteConfig.Method
+	s.toolsMu.Unlock()
+	s.AddTools(mcpserver.ServerTool{
+		Tool:    tool,
+		Handler: s.tool.CreateHandler(&tool, route.RouteConfig, executor),
+	})
+	return nil
+}
+
+// AddTools registers tools, replacing tools with the same name. Previously
+// disabled tools stay disabled until enabled again.
+func (s *Server) AddTools(tools ...mcpserver.ServerTool) {
+	s.toolsMu.Lock()
+	enabled := make([]mcpserver.ServerTool, 0, len(tools))
+	for _, tool := range tools {
+		s.tools[tool.Tool.Name] = tool
+		if !s.disabled[tool.Tool.Name] {
+			enabled = append(enabled, tool)
+		}
+	}
+	s.toolsMu.Unlock()
+
+	if len(enabled) > 0 {
+		s.mcp.AddTools(enabled...)
+	}
+}
+
+// RemoveTool unregisters a tool
+func (s *Server) RemoveTool(name string) error {
+	s.toolsMu.Lock()
+	if _, ok := s.tools[name]; !ok {
+		s.toolsMu.Unlock()
+		return fmt.Errorf("%w: %s", ErrUnknownTool, name)
+	}
+	delete(s.tools, name)
+	delete(s.disabled, name)
+	delete(s.methods, name)
+	s.toolsMu.Unlock()
+
+	s.mcp.DeleteTools(name)
+	logger.Info("Removed tool", zap.String("tool", name))
+	return nil
+}
+
+// DisableTool hides a tool from clients while keeping it registered
+func (s *Server) DisableTool(name string) error {
+	s.toolsMu.Lock()
+	if _, ok := s.tools[name]; !ok {
+		s.toolsMu.Unlock()
+		return fmt.Errorf("%w: %s", ErrUnknownTool, name)
+	}
+	if s.disabled[name] {
+		s.toolsMu.Unlock()
+		return nil
+	}
+	s.disabled[name] = true
+	s.toolsMu.Unlock()
+
+	s.mcp.DeleteTools(name)
+	logger.Info("Disabled tool", zap.String("tool", name))
+	return nil
+}
+
+// EnableTool makes a disabled tool available to clients again
+func (s *Server) EnableTool(name string) error {
+	s.toolsMu.Lock()
+	tool, ok := s.tools[name]
+	if !ok {
+		s.toolsMu.Unlock()
+		return fmt.Errorf("%w: %s", ErrUnknownTool, name)
+	}
+	if !s.disabled[name] {
+		s.toolsMu.Unlock()
+		return nil
+	}
+	delete(s.disabled, name)
+	s.toolsMu.Unlock()
+
+	s.mcp.AddTools(tool)
+	logger.Info("Enabled tool", zap.String("tool", name))
+	return nil
+}
+
+// Tools returns the registered tools sorted by name
+func (s *Server) Tools() []ToolStatus {
+	s.toolsMu.Lock()
+	defer s.toolsMu.Unlock()
+
+	statuses := make([]ToolStatus, 0, len(s.tools))
+	for name := range s.tools {
+		statuses = append(statuses, ToolStatus{Name: name, Enabled: !s.disabled[name]})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
@@ -0,0 +1,51 @@
+package server
+
+import (
+	"context"
+
+	"github.com/brizzai/auto-mcp/internal/auth/middleware"
+	"github.com/brizzai/auto-mcp/internal/parser"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// filterListedTools removes the tools the authorization policy does not let
+// the session's user call from tools/list, so they are not even advertised
+func (s *Server) filterListedTools(ctx context.Context, _ any, _ *mcp.ListToolsRequest, result *mcp.ListToolsResult) {
+	if s.policy == nil || result == nil {
+		return
+	}
+
+	s.toolsMu.Lock()
+	visible := result.Tools[:0]
+	for _, tool := range result.Tools {
+		if s.toolVisible(ctx, tool.Name, s.methods[tool.Name]) {
+			visible = append(visible, tool)
+		}
+	}
+	s.toolsMu.Unlock()
+	result.Tools = visible
+}
+
+// visibleCatalog returns the lazy mode operations the user may call
+func (s *Server) visibleCatalog(ctx context.Context) map[string]*parser.RouteTool {
+	catalog := s.operations()
+	if s.policy == nil {
+		return catalog
+	}
+	visible := make(map[string]*parser.RouteTool, len(catalog))
+	for name, route := range catalog {
+		if s.toolVisible(ctx, name, route.RouteConfig.Method) {
+			visible[name] = route
+		}
+	}
+	return visible
+}
+
+// toolVisible reports whether the authenticated user in ctx may call the tool
+func (s *Server) toolVisible(ctx context.Context, name, method string) bool {
+	if s.policy == nil {
+		return true
+	}
+	authInfo, _ := ctx.Value(middleware.AuthContextKey).(*middleware.AuthInfo)
+	return s.tool.Allowed(authInfo, name, method)
+}
@@ -0,0 +1,90 @@
+// Package telemetry configures OpenTelemetry tracing so tool calls can be
+// followed from the MCP client down to the upstream API.
+package telemetry
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/brizzai/auto-mcp/internal/config"
+	"github.com/brizzai/auto-mcp/internal/logger"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// instrumentationName identifies spans created by auto-mcp
+const instrumentationName = "github.com/brizzai/auto-mcp"
+
+// Module installs the global tracer provider when telemetry is enabled
+var Module = fx.Options(
+	fx.Invoke(register),
+)
+
+// Tracer returns the tracer used for auto-mcp spans. Until a provider is
+// installed it is a no-op, so instrumentation costs nothing when disabled.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+func register(lc fx.Lifecycle, cfg *config.Config) error {
+	if !cfg.Telemetry.Enabled {
+		return nil
+	}
+
+	provider, err := NewTracerProvider(context.Background(), &cfg.Telemetry, cfg.Server.Version)
+	if err != nil {
+		return err
+	}
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	logger.Info("OpenTelemetry tracing enabled",
+		zap.String("service", cfg.Telemetry.Service()),
+		zap.String("endpoint", cfg.Telemetry.Endpoint),
+	)
+
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			// Flush buffered spans before exiting
+			return provider.Shutdown(ctx)
+		},
+	})
+	return nil
+}
+
+// NewTracerProvider creates a tracer provider exporting spans over OTLP/HTTP.
+// Without an explicit endpoint the standard OTEL_EXPORTER_OTLP_* variables apply.
+func NewTracerProvider(ctx context.Context, cfg *config.TelemetryConfig, version string) (*sdktrace.TracerProvider, error) {
+	var opts []otlptracehttp.Option
+	if cfg.Endpoint != "" {
+		opts = append(opts, otlptracehttp.WithEndpointURL(cfg.Endpoint))
+	}
+	if len(cfg.Headers) > 0 {
+		opts = append(opts, otlptracehttp.WithHeaders(cfg.Headers))
+	}
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+
+	attrs := []attribute.KeyValue{attribute.String("service.name", cfg.Service())}
+	if version != "" {
+		attrs = append(attrs, attribute.String("service.version", version))
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(attrs...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create telemetry resource: %w", err)
+	}
+
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.Ratio()))),
+	), nil
+}
@@ -0,0 +1,92 @@
+package testutil
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+)
+
+// RecordedRequest is a request received by a Backend
+type RecordedRequest struct {
+	Method string
+	Path   string
+	Query  url.Values
+	Header http.Header
+	Body   []byte
+}
+
+// Backend is a fake upstream API. Routes are matched on method and exact
+// path; unmatched requests get a 404. Every request is recorded.
+type Backend struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	routes   map[string]http.HandlerFunc
+	requests []RecordedRequest
+}
+
+// NewBackend starts a fake upstream API that is closed when the test ends
+func NewBackend(t testing.TB) *Backend {
+	t.Helper()
+
+	b := &Backend{routes: make(map[string]http.HandlerFunc)}
+	b.Server = httptest.NewServer(http.HandlerFunc(b.serve))
+	t.Cleanup(b.Close)
+	return b
+}
+
+// Handle responds to method and path with a fixed status and JSON body
+func (b *Backend) Handle(method, path string, status int, body string) {
+	b.HandleFunc(method, path, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, body)
+	})
+}
+
+// HandleFunc responds to method and path with a custom handler
+func (b *Backend) HandleFunc(method, path string, handler http.HandlerFunc) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.routes[method+" "+path] = handler
+}
+
+// Requests returns the requests received so far
+func (b *Backend) Requests() []RecordedRequest {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]RecordedRequest(nil), b.requests...)
+}
+
+// LastRequest returns the most recent request, or nil if none was received
+func (b *Backend) LastRequest() *RecordedRequest {
+	requests := b.Requests()
+	if len(requests) == 0 {
+		return nil
+	}
+	return &requests[len(requests)-1]
+}
+
+func (b *Backend) serve(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	b.mu.Lock()
+	b.requests = append(b.requests, RecordedRequest{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Query:  r.URL.Query(),
+		Header: r.Header.Clone(),
+		Body:   body,
+	})
+	handler, ok := b.routes[r.Method+" "+r.URL.Path]
+	b.mu.Unlock()
+
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	handler(w, r)
+}
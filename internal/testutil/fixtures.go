@@ -0,0 +1,39 @@
+package testutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// LoadFixture reads a file relative to the repository root, e.g.
+// "examples/petshop/config/swagger.json", independent of the test's package.
+func LoadFixture(t testing.TB, path string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join(repoRoot(t), path))
+	if err != nil {
+		t.Fatalf("failed to load fixture %s: %v", path, err)
+	}
+	return string(data)
+}
+
+// repoRoot walks up from the working directory to the directory holding go.mod
+func repoRoot(t testing.TB) string {
+	t.Helper()
+
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get working directory: %v", err)
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			t.Fatalf("go.mod not found above the working directory")
+		}
+		dir = parent
+	}
+}
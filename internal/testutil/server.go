@@ -0,0 +1,137 @@
+// Package testutil provides helpers for integration tests of specs and
+// adjustments: a fake upstream API, an in-memory auto-mcp server built from
+// spec and adjustment strings, and an MCP client connected to it.
+package testutil
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/brizzai/auto-mcp/internal/config"
+	"github.com/brizzai/auto-mcp/internal/parser"
+	"github.com/brizzai/auto-mcp/internal/requester"
+	"github.com/brizzai/auto-mcp/internal/server"
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Options describes the server to start
+type Options struct {
+	// Spec is the OpenAPI/Swagger document, as JSON or YAML
+	Spec string
+	// Adjustments is the optional adjustments file content
+	Adjustments string
+	// BaseURL is the upstream API, usually Backend.URL
+	BaseURL string
+	// Configure may modify the configuration before the server is created
+	Configure func(cfg *config.Config)
+}
+
+// Server is an in-memory auto-mcp server with a connected MCP client
+type Server struct {
+	*server.Server
+	Config *config.Config
+	Client *client.Client
+	t      testing.TB
+}
+
+// NewServer builds an auto-mcp server from opts and connects an initialized
+// in-process client. Both are closed when the test ends.
+func NewServer(t testing.TB, opts Options) *Server {
+	t.Helper()
+
+	dir := t.TempDir()
+	cfg := &config.Config{
+		SwaggerFile: writeFile(t, dir, "swagger.json", opts.Spec),
+		EndpointConfig: config.EndpointConfig{
+			BaseURL:  opts.BaseURL,
+			AuthType: config.AuthTypeNone,
+		},
+		Server: config.ServerConfig{
+			Name:    "auto-mcp-test",
+			Version: "test",
+			Mode:    config.ServerModeSTDIO,
+		},
+	}
+	if opts.Adjustments != "" {
+		cfg.AdjustmentsFile = writeFile(t, dir, "adjustments.yaml", opts.Adjustments)
+	}
+	if opts.Configure != nil {
+		opts.Configure(cfg)
+	}
+
+	endpointCfg := &cfg.EndpointConfig
+	httpRequester := requester.NewHTTPRequester(requester.HTTPRequesterParams{
+		ServiceConfig: endpointCfg,
+		AuthManager:   requester.NewHTTPAuthManager(endpointCfg),
+	})
+	srv := server.NewServer(cfg, parser.NewSwaggerParser(parser.NewAdjuster()), httpRequester)
+
+	mcpClient, err := client.NewInProcessClient(srv.MCPServer())
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	t.Cleanup(func() { _ = mcpClient.Close() })
+
+	ctx := context.Background()
+	if err := mcpClient.Start(ctx); err != nil {
+		t.Fatalf("failed to start client: %v", err)
+	}
+	initReq := mcp.InitializeRequest{}
+	initReq.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initReq.Params.ClientInfo = mcp.Implementation{Name: "testutil", Version: "test"}
+	if _, err := mcpClient.Initialize(ctx, initReq); err != nil {
+		t.Fatalf("failed to initialize client: %v", err)
+	}
+
+	return &Server{Server: srv, Config: cfg, Client: mcpClient, t: t}
+}
+
+// ListTools returns the tools advertised by the server
+func (s *Server) ListTools() []mcp.Tool {
+	s.t.Helper()
+
+	result, err := s.Client.ListTools(context.Background(), mcp.ListToolsRequest{})
+	if err != nil {
+		s.t.Fatalf("failed to list tools: %v", err)
+	}
+	return result.Tools
+}
+
+// CallTool calls a tool and returns its result. Tool errors are reported in
+// the result (IsError); only protocol errors fail the test.
+func (s *Server) CallTool(name string, args map[string]interface{}) *mcp.CallToolResult {
+	s.t.Helper()
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = name
+	request.Params.Arguments = args
+	result, err := s.Client.CallTool(context.Background(), request)
+	if err != nil {
+		s.t.Fatalf("failed to call tool %s: %v", name, err)
+	}
+	return result
+}
+
+// ResultText concatenates the text content of a tool result
+func ResultText(result *mcp.CallToolResult) string {
+	var text string
+	for _, content := range result.Content {
+		if textContent, ok := content.(mcp.TextContent); ok {
+			text += textContent.Text
+		}
+	}
+	return text
+}
+
+func writeFile(t testing.TB, dir, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+	return path
+}
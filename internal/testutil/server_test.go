@@ -0,0 +1,62 @@
+package testutil
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const usersSpec = `{
+	"openapi": "3.0.0",
+	"info": {"title": "Users", "version": "1.0.0"},
+	"paths": {
+		"/users/{id}": {
+			"get": {"summary": "Get a user", "responses": {"200": {"description": "OK"}}}
+		},
+		"/users": {
+			"post": {"summary": "Create a user", "responses": {"201": {"description": "Created"}}}
+		}
+	}
+}`
+
+func TestNewServer(t *testing.T) {
+	backend := NewBackend(t)
+	backend.Handle(http.MethodGet, "/users/42", http.StatusOK, `{"id": 42, "name": "Ada"}`)
+
+	srv := NewServer(t, Options{
+		Spec:    usersSpec,
+		BaseURL: backend.URL,
+		Adjustments: `
+routes:
+  - path: /users/{id}
+    methods: [GET]
+descriptions:
+  - path: /users/{id}
+    updates:
+      - method: GET
+        new_description: Look up a user by ID
+`,
+	})
+
+	tools := srv.ListTools()
+	require.Len(t, tools, 1)
+	assert.Equal(t, "get_users_id", tools[0].Name)
+	assert.Contains(t, tools[0].Description, "Look up a user by ID")
+
+	result := srv.CallTool("get_users_id", map[string]interface{}{"id": "42"})
+	assert.False(t, result.IsError)
+	assert.JSONEq(t, `{"id": 42, "name": "Ada"}`, ResultText(result))
+
+	request := backend.LastRequest()
+	require.NotNil(t, request)
+	assert.Equal(t, "/users/42", request.Path)
+}
+
+func TestLoadFixture(t *testing.T) {
+	spec := LoadFixture(t, "examples/petshop/config/swagger.json")
+
+	srv := NewServer(t, Options{Spec: spec, BaseURL: "http://localhost"})
+	assert.NotEmpty(t, srv.ListTools())
+}
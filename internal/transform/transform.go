@@ -0,0 +1,194 @@
+// Package transform renders the Go templates of the adjustments file that
+// add request arguments and headers and reshape responses.
+package transform
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// funcs are available in every transform template
+var funcs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"default": func(fallback, v any) any {
+		if v == nil || v == "" {
+			return fallback
+		}
+		return v
+	},
+	"env":   os.Getenv,
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"trim":  strings.TrimSpace,
+	"join":  func(sep string, items []string) string { return strings.Join(items, sep) },
+	"now":   func() string { return time.Now().UTC().Format(time.RFC3339) },
+}
+
+// Transform holds the compiled templates of one route
+type Transform struct {
+	arguments map[string]*template.Template
+	headers   map[string]*template.Template
+	response  *template.Template
+}
+
+// Compile parses the argument, header and response templates. It returns
+// nil when there are none.
+func Compile(arguments, headers map[string]string, response string) (*Transform, error) {
+	if len(arguments) == 0 && len(headers) == 0 && response == "" {
+		return nil, nil
+	}
+
+	t := &Transform{
+		arguments: make(map[string]*template.Template, len(arguments)),
+		headers:   make(map[string]*template.Template, len(headers)),
+	}
+	for name, text := range arguments {
+		tmpl, err := Parse("arguments."+name, text)
+		if err != nil {
+			return nil, err
+		}
+		t.arguments[name] = tmpl
+	}
+	for name, text := range headers {
+		tmpl, err := Parse("headers."+name, text)
+		if err != nil {
+			return nil, err
+		}
+		t.headers[name] = tmpl
+	}
+	if response != "" {
+		tmpl, err := Parse("response", response)
+		if err != nil {
+			return nil, err
+		}
+		t.response = tmpl
+	}
+	return t, nil
+}
+
+// Parse parses a template with the transform functions available
+func Parse(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(funcs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// HasResponse reports whether the transform reshapes responses
+func (t *Transform) HasResponse() bool {
+	return t != nil && t.response != nil
+}
+
+// Request renders the argument and header templates with data, which holds
+// tool, method, path, args and user. Arguments named like "body.status" set a
+// field of an object argument. Rendered values that are valid JSON, such as
+// numbers, booleans and objects, are set as such; anything else as a string.
+func (t *Transform) Request(args map[string]any, data map[string]any) (map[string]string, error) {
+	if t == nil {
+		return nil, nil
+	}
+
+	// Render every value before setting any, so all templates see the
+	// arguments as the caller sent them
+	names := make([]string, 0, len(t.arguments))
+	for name := range t.arguments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	values := make([]any, len(names))
+	for i, name := range names {
+		rendered, err := Render(t.arguments[name], data)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = decodeValue(rendered)
+	}
+	for i, name := range names {
+		if err := SetArgument(args, name, values[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	headers := make(map[string]string, len(t.headers))
+	for name, tmpl := range t.headers {
+		rendered, err := Render(tmpl, data)
+		if err != nil {
+			return nil, err
+		}
+		headers[name] = rendered
+	}
+	return headers, nil
+}
+
+// Response renders the response template with data, which holds tool,
+// status, headers, body, args and user. body is the decoded JSON response,
+// or the raw text when it is not JSON.
+func (t *Transform) Response(data map[string]any) (string, error) {
+	return Render(t.response, data)
+}
+
+// DecodeBody returns the JSON response body as a value for templates, or
+// the text itself when it is not JSON
+func DecodeBody(body []byte) any {
+	var value any
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil || decoder.More() {
+		return string(body)
+	}
+	return value
+}
+
+// Render executes tmpl with data
+func Render(tmpl *template.Template, data map[string]any) (string, error) {
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// decodeValue keeps JSON values typed and everything else a string
+func decodeValue(rendered string) any {
+	trimmed := strings.TrimSpace(rendered)
+	if trimmed == "" || trimmed[0] == '"' {
+		return rendered
+	}
+	decoder := json.NewDecoder(strings.NewReader(trimmed))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil || decoder.More() {
+		return rendered
+	}
+	return value
+}
+
+// SetArgument sets args[name], descending into object arguments for
+// dotted names and creating them as needed
+func SetArgument(args map[string]any, name string, value any) error {
+	parts := strings.Split(name, ".")
+	current := args
+	for i, part := range parts[:len(parts)-1] {
+		next, ok := current[part].(map[string]any)
+		if !ok {
+			if existing, exists := current[part]; exists && existing != nil {
+				return fmt.Errorf("argument %s is not an object", strings.Join(parts[:i+1], "."))
+			}
+			next = make(map[string]any)
+			current[part] = next
+		}
+		current = next
+	}
+	current[parts[len(parts)-1]] = value
+	return nil
+}
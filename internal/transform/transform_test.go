@@ -0,0 +1,74 @@
+package transform
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompile_Empty(t *testing.T) {
+	tr, err := Compile(nil, nil, "")
+	require.NoError(t, err)
+	assert.Nil(t, tr)
+	assert.False(t, tr.HasResponse())
+
+	_, err = Compile(map[string]string{"limit": "{{ .args.limit"}, nil, "")
+	assert.Error(t, err)
+}
+
+func TestTransform_Request(t *testing.T) {
+	tr, err := Compile(map[string]string{
+		"limit":        `{{ default 20 .args.limit }}`,
+		"body.owner":   `{{ .user.email }}`,
+		"body.tags":    `["{{ .tool }}"]`,
+		"status":       `{{ lower .args.status }}`,
+		"quoted":       `"42"`,
+		"body.comment": `{{ .args.status }} by {{ .user.name }}`,
+	}, map[string]string{
+		"X-Tenant": `{{ .user.tenant }}`,
+	}, "")
+	require.NoError(t, err)
+
+	args := map[string]any{"status": "SOLD", "body": map[string]any{"name": "rex"}}
+	headers, err := tr.Request(args, map[string]any{
+		"tool": "post_pet",
+		"args": args,
+		"user": map[string]any{"email": "a@example.com", "name": "Ann", "tenant": "acme"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{"X-Tenant": "acme"}, headers)
+	assert.Equal(t, json.Number("20"), args["limit"])
+	assert.Equal(t, "sold", args["status"])
+	assert.Equal(t, `"42"`, args["quoted"])
+	assert.Equal(t, map[string]any{
+		"name":    "rex",
+		"owner":   "a@example.com",
+		"tags":    []any{"post_pet"},
+		"comment": "SOLD by Ann",
+	}, args["body"])
+}
+
+func TestTransform_RequestNotAnObject(t *testing.T) {
+	tr, err := Compile(map[string]string{"body.owner": "x"}, nil, "")
+	require.NoError(t, err)
+
+	_, err = tr.Request(map[string]any{"body": "text"}, map[string]any{})
+	assert.EqualError(t, err, "argument body is not an object")
+}
+
+func TestTransform_Response(t *testing.T) {
+	tr, err := Compile(nil, nil, `{{ range .body.items }}{{ .id }}: {{ .name }}
+{{ end }}total {{ .body.total }} ({{ .status }})`)
+	require.NoError(t, err)
+	require.True(t, tr.HasResponse())
+
+	body := DecodeBody([]byte(`{"items": [{"id": 9007199254740993, "name": "rex"}], "total": 1}`))
+	rendered, err := tr.Response(map[string]any{"status": 200, "body": body})
+	require.NoError(t, err)
+	assert.Equal(t, "9007199254740993: rex\ntotal 1 (200)", rendered)
+
+	assert.Equal(t, "plain text", DecodeBody([]byte("plain text")))
+}
@@ -1,6 +1,9 @@
 package tui
 
 import (
+	"fmt"
+	"os"
+
 	"github.com/brizzai/auto-mcp/internal/parser"
 	"github.com/brizzai/auto-mcp/internal/tui/models"
 	tea "github.com/charmbracelet/bubbletea"
@@ -11,7 +14,17 @@ type AppModel struct {
 	mainPage   MainPageModel
 	listView   ListItemModel
 	exportView ExportView
-	page       string // "main" or "list" or "export"
+	summary    SummaryView
+	sourcePage SourcePage
+	wizard     ConfigWizard
+	adjuster   *parser.Adjuster  // Adjustments of the routes, for a spec loaded on the source page
+	size       tea.WindowSizeMsg // The last window size, for the pages of a loaded spec
+	swagger    string            // The spec file, for the config.yaml written by the wizard
+	page       string            // "source", "main", "list", "summary", "export" or "config"
+
+	saveOnFinish bool  // Finishing saves into the adjustments file instead of exporting
+	saved        bool  // The edits were saved on finishing
+	err          error // Saving on finishing failed
 }
 
 // NewAppModel creates a new AppModel with the provided route tools
@@ -20,15 +33,68 @@ func NewAppModel(routeTools []*parser.RouteTool, adjuster *parser.Adjuster) AppM
 		mainPage:   NewMainPageModel(routeTools),
 		listView:   NewListItemModel(routeTools, adjuster),
 		exportView: ExportView{}, // Initialize with empty export view as we'll set it properly in DoneMsg
+		adjuster:   adjuster,
 		page:       "main",
 	}
 }
 
+// WithSpecPrompt returns the AppModel starting on a page that prompts for
+// the spec to edit, for when none was given on the command line
+func (m AppModel) WithSpecPrompt() AppModel {
+	m.sourcePage = NewSourcePage()
+	m.page = "source"
+	return m
+}
+
+// WithSwaggerFile returns the AppModel knowing the routes were loaded from
+// file, so the config wizard can refer to it
+func (m AppModel) WithSwaggerFile(file string) AppModel {
+	m.swagger = file
+	return m
+}
+
+// WithSaveOnFinish returns the AppModel saving the edits into the
+// adjustments file and quitting once the summary is confirmed, rather than
+// exporting them, for a server to start with the file
+func (m AppModel) WithSaveOnFinish() AppModel {
+	m.saveOnFinish = true
+	return m
+}
+
+// WithSuggestions returns the AppModel proposing edits that trim the routes
+// to target tools
+func (m AppModel) WithSuggestions(target int) AppModel {
+	m.listView = m.listView.WithSuggestions(target)
+	return m
+}
+
+// WithGeneratedDescriptions returns the AppModel proposing the
+// descriptions an LLM wrote, by route
+func (m AppModel) WithGeneratedDescriptions(descriptions map[*parser.RouteTool]string) AppModel {
+	m.listView = m.listView.WithGeneratedDescriptions(descriptions)
+	return m
+}
+
+// WithCall returns the AppModel letting users try routes against the
+// upstream API with call
+func (m AppModel) WithCall(call CallFunc) AppModel {
+	m.listView = m.listView.WithCall(call)
+	return m
+}
+
+// WithAdjustmentsFile returns the AppModel saving edits into file, the
+// adjustments file the routes were loaded with
+func (m AppModel) WithAdjustmentsFile(file string) AppModel {
+	m.listView = m.listView.WithAdjustmentsFile(file)
+	return m
+}
+
 // Init initializes the AppModel
 func (m AppModel) Init() tea.Cmd {
 	return tea.Batch(
 		m.mainPage.Init(),
 		m.listView.Init(),
+		m.listView.Autosave(),
 	)
 }
 
@@ -43,17 +109,59 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, cmd
 
 	case DoneMsg:
+		m.page = "summary"
+		m.summary = NewSummaryView(m.listView.GetRoutesUpdates(), m.size.Width, m.size.Height)
+		if m.saveOnFinish {
+			m.summary = m.summary.WithHelp("enter to save and serve, esc to go back")
+		}
+		return m, nil
+
+	case exportMsg:
+		if m.saveOnFinish {
+			return m.saveAndQuit()
+		}
 		m.page = "export"
 		m.exportView = NewExportView(m.listView.GetRoutesUpdates())
 		cmd := m.exportView.Init()
 		return m, cmd
 
+	case configWizardMsg:
+		m.page = "config"
+		m.wizard = NewConfigWizard(m.swagger, msg.adjustmentsFile)
+		if m.size.Width == 0 {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.wizard, cmd = m.wizard.Update(m.size)
+		return m, cmd
+
+	case specLoadedMsg:
+		if msg.err != nil || len(msg.routeTools) == 0 {
+			var cmd tea.Cmd
+			m.sourcePage, cmd = m.sourcePage.Update(msg)
+			return m, cmd
+		}
+		m.swagger = specFile(msg.source)
+		m.mainPage = NewMainPageModel(msg.routeTools)
+		m.listView = m.listView.withRoutes(msg.routeTools, m.adjuster)
+		m.page = "main"
+		if m.size.Width == 0 {
+			return m, nil
+		}
+		return m.Update(m.size)
+
+	case autosaveMsg:
+		// Edits are autosaved whichever page is shown
+		tempModel, cmd := m.listView.Update(msg)
+		m.listView = tempModel.(ListItemModel)
+		return m, cmd
+
 	case BackToMainMsg:
 		m.page = "list"
 		return m, nil
 
 	case tea.KeyMsg:
-		if msg.String() == "esc" && m.page == "list" {
+		if msg.String() == "esc" && m.page == "list" && !m.listView.Busy() {
 			m.page = "main"
 			return m, nil
 		}
@@ -61,6 +169,10 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		var cmd tea.Cmd
 		var tempModel tea.Model
+		m.size = msg
+
+		m.sourcePage, cmd = m.sourcePage.Update(msg)
+		cmds = append(cmds, cmd)
 
 		// Update all models with the window size
 		tempModel, cmd = m.mainPage.Update(msg)
@@ -75,6 +187,12 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.exportView = tempModel.(ExportView)
 		cmds = append(cmds, cmd)
 
+		m.summary, cmd = m.summary.Update(msg)
+		cmds = append(cmds, cmd)
+
+		m.wizard, cmd = m.wizard.Update(msg)
+		cmds = append(cmds, cmd)
+
 		return m, tea.Batch(cmds...)
 	}
 
@@ -82,6 +200,9 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	var tempModel tea.Model
 	switch m.page {
+	case "source":
+		m.sourcePage, cmd = m.sourcePage.Update(msg)
+		cmds = append(cmds, cmd)
 	case "main":
 		tempModel, cmd = m.mainPage.Update(msg)
 		m.mainPage = tempModel.(MainPageModel)
@@ -90,10 +211,16 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		tempModel, cmd = m.listView.Update(msg)
 		m.listView = tempModel.(ListItemModel)
 		cmds = append(cmds, cmd)
+	case "summary":
+		m.summary, cmd = m.summary.Update(msg)
+		cmds = append(cmds, cmd)
 	case "export":
 		tempModel, cmd = m.exportView.Update(msg)
 		m.exportView = tempModel.(ExportView)
 		cmds = append(cmds, cmd)
+	case "config":
+		m.wizard, cmd = m.wizard.Update(msg)
+		cmds = append(cmds, cmd)
 	}
 
 	return m, tea.Batch(cmds...)
@@ -102,10 +229,16 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 // View renders the active page
 func (m AppModel) View() string {
 	switch m.page {
+	case "source":
+		return m.sourcePage.View()
 	case "main":
 		return m.mainPage.View()
+	case "summary":
+		return m.summary.View()
 	case "export":
 		return m.exportView.View()
+	case "config":
+		return m.wizard.View()
 	default: // list
 		return m.listView.View()
 	}
@@ -116,8 +249,35 @@ func (m AppModel) GetRoutesUpdates() []*models.RouteToolItem {
 	return m.listView.GetRoutesUpdates()
 }
 
+// Output returns the adjustments exported to stdout, nil when they were
+// written to a file
+func (m AppModel) Output() []byte {
+	return m.exportView.Output
+}
+
 // IsFinished checks if the user has completed the TUI flow
-// by verifying they've reached the export page
+// by verifying they've reached the export page, or saved on finishing
 func (m AppModel) IsFinished() bool {
-	return m.exportView.Success
+	return m.exportView.Success || m.saved
+}
+
+// Err returns the error saving the edits on finishing
+func (m AppModel) Err() error {
+	return m.err
+}
+
+// saveAndQuit saves the edits into the adjustments file, removing the
+// recovery file, and quits
+func (m AppModel) saveAndQuit() (tea.Model, tea.Cmd) {
+	file := m.listView.file
+	if _, err := SaveAdjustments(m.listView.GetRoutesUpdates(), file); err != nil {
+		m.err = fmt.Errorf("failed to save %s: %w", file, err)
+		return m, tea.Quit
+	}
+	if err := os.Remove(RecoveryFile(file)); err != nil && !os.IsNotExist(err) {
+		m.err = fmt.Errorf("failed to remove the recovery file: %w", err)
+		return m, tea.Quit
+	}
+	m.saved = true
+	return m, tea.Quit
 }
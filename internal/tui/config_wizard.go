@@ -0,0 +1,362 @@
+package tui
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/brizzai/auto-mcp/internal/config"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"gopkg.in/yaml.v3"
+)
+
+// configWizardMsg opens the config wizard once the adjustments were exported
+// to adjustmentsFile
+type configWizardMsg struct {
+	adjustmentsFile string
+}
+
+// envNamePattern matches the variable names config.yaml can reference as ${NAME}
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// wizardAuthTypes are the auth types the wizard configures. session needs a
+// login request, which is configured by hand.
+var wizardAuthTypes = []string{
+	string(config.AuthTypeNone),
+	string(config.AuthTypeBearer),
+	string(config.AuthTypeAPIKey),
+	string(config.AuthTypeBasic),
+	string(config.AuthTypeOAuth2),
+}
+
+// wizardModes are the server modes, stdio first as clients launch it themselves
+var wizardModes = []string{
+	string(config.ServerModeSTDIO),
+	string(config.ServerModeSSE),
+	string(config.ServerModeHTTP),
+}
+
+// ConfigSettings are the settings of the config.yaml the wizard writes
+type ConfigSettings struct {
+	SwaggerFile     string
+	AdjustmentsFile string
+	BaseURL         string
+	AuthType        config.AuthType
+	// CredentialEnv names the environment variables of the credentials, by
+	// auth_config key
+	CredentialEnv map[string]string
+	// APIKeyHeader is the header api_key auth sends the key in
+	APIKeyHeader string
+	Mode         config.ServerMode
+}
+
+// Validate checks the settings are complete
+func (s ConfigSettings) Validate() error {
+	if s.SwaggerFile == "" {
+		return fmt.Errorf("the swagger file is required")
+	}
+	if u, err := url.Parse(s.BaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("base URL %q is not an http or https URL", s.BaseURL)
+	}
+	for _, key := range slices.Sorted(maps.Keys(s.CredentialEnv)) {
+		if !envNamePattern.MatchString(s.CredentialEnv[key]) {
+			return fmt.Errorf("%q is not a valid environment variable name for the %s", s.CredentialEnv[key], key)
+		}
+	}
+	return nil
+}
+
+// EncodeConfig encodes the settings as a config.yaml written to dir. Files
+// are referenced relative to dir, where auto-mcp reads config.yaml from, and
+// credentials as ${NAME} references to their environment variables.
+func EncodeConfig(s ConfigSettings, dir string) ([]byte, error) {
+	var file struct {
+		Server struct {
+			Mode config.ServerMode `yaml:"mode"`
+		} `yaml:"server"`
+		Endpoint struct {
+			BaseURL    string            `yaml:"base_url"`
+			AuthType   config.AuthType   `yaml:"auth_type"`
+			AuthConfig map[string]string `yaml:"auth_config,omitempty"`
+		} `yaml:"endpoint"`
+		SwaggerFile     string `yaml:"swagger_file"`
+		AdjustmentsFile string `yaml:"adjustments_file"`
+	}
+	file.Server.Mode = s.Mode
+	file.Endpoint.BaseURL = s.BaseURL
+	file.Endpoint.AuthType = s.AuthType
+	for key, env := range s.CredentialEnv {
+		if file.Endpoint.AuthConfig == nil {
+			file.Endpoint.AuthConfig = make(map[string]string)
+		}
+		file.Endpoint.AuthConfig[key] = "${" + env + "}"
+	}
+	if s.AuthType == config.AuthTypeAPIKey && s.APIKeyHeader != "" {
+		file.Endpoint.AuthConfig["header"] = s.APIKeyHeader
+	}
+	file.SwaggerFile = relativePath(dir, s.SwaggerFile)
+	file.AdjustmentsFile = relativePath(dir, s.AdjustmentsFile)
+	return yaml.Marshal(file)
+}
+
+// relativePath returns path relative to dir, or path when it has none
+func relativePath(dir, path string) string {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return path
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(absDir, absPath)
+	if err != nil {
+		return absPath
+	}
+	return filepath.ToSlash(rel)
+}
+
+// wizardField is an input of the wizard, a text input or a choice between
+// choices
+type wizardField struct {
+	key     string
+	label   string
+	input   textinput.Model
+	choices []string
+	choice  int
+	// authTypes are the auth types the field is asked for, every one when empty
+	authTypes []string
+}
+
+// value returns the entered or chosen value
+func (f wizardField) value() string {
+	if f.choices != nil {
+		return f.choices[f.choice]
+	}
+	return strings.TrimSpace(f.input.Value())
+}
+
+// ConfigWizard collects the endpoint, credentials and server mode of the API
+// and writes a config.yaml to serve the exported adjustments with.
+type ConfigWizard struct {
+	adjustmentsFile string
+	fields          []wizardField
+	focus           int
+	overwrite       bool // The existing config.yaml may be overwritten
+	status          string
+	// Written is the path of the config.yaml written
+	Written string
+}
+
+// NewConfigWizard creates a ConfigWizard for the adjustments exported to
+// adjustmentsFile, its swagger file input filled with swaggerFile.
+func NewConfigWizard(swaggerFile, adjustmentsFile string) ConfigWizard {
+	text := func(key, label, value, placeholder string, authTypes ...string) wizardField {
+		input := textinput.New()
+		input.Prompt = ""
+		input.Placeholder = placeholder
+		input.SetValue(value)
+		return wizardField{key: key, label: label, input: input, authTypes: authTypes}
+	}
+	choice := func(key, label string, choices []string) wizardField {
+		// The input only tracks the focus
+		return wizardField{key: key, label: label, input: textinput.New(), choices: choices}
+	}
+	m := ConfigWizard{
+		adjustmentsFile: adjustmentsFile,
+		fields: []wizardField{
+			text("swagger_file", "Swagger file", swaggerFile, "path to the Swagger/OpenAPI file"),
+			text("base_url", "Base URL", "", "https://api.example.com"),
+			choice("auth_type", "Auth type", wizardAuthTypes),
+			text("token", "Token variable", "API_TOKEN", "environment variable of the token", "bearer", "oauth2"),
+			text("key", "API key variable", "API_KEY", "environment variable of the key", "api_key"),
+			text("header", "API key header", "X-API-Key", "header the key is sent in", "api_key"),
+			text("username", "Username variable", "API_USERNAME", "environment variable of the username", "basic"),
+			text("password", "Password variable", "API_PASSWORD", "environment variable of the password", "basic"),
+			choice("mode", "Server mode", wizardModes),
+		},
+	}
+	// The swagger file is usually known, so the base URL comes first
+	m.focus = 1
+	if swaggerFile == "" {
+		m.focus = 0
+	}
+	m.fields[m.focus].input.Focus()
+	return m
+}
+
+// field returns the field of key
+func (m ConfigWizard) field(key string) wizardField {
+	for _, f := range m.fields {
+		if f.key == key {
+			return f
+		}
+	}
+	return wizardField{}
+}
+
+// asked reports whether the field at i is asked for the chosen auth type
+func (m ConfigWizard) asked(i int) bool {
+	authTypes := m.fields[i].authTypes
+	return len(authTypes) == 0 || slices.Contains(authTypes, m.field("auth_type").value())
+}
+
+// move focuses the next field asked for in direction step, reporting false
+// past the last or first one
+func (m *ConfigWizard) move(step int) bool {
+	for i := m.focus + step; i >= 0 && i < len(m.fields); i += step {
+		if m.asked(i) {
+			m.fields[m.focus].input.Blur()
+			m.focus = i
+			m.fields[m.focus].input.Focus()
+			return true
+		}
+	}
+	return false
+}
+
+// Settings returns the settings entered
+func (m ConfigWizard) Settings() ConfigSettings {
+	s := ConfigSettings{
+		SwaggerFile:     m.field("swagger_file").value(),
+		AdjustmentsFile: m.adjustmentsFile,
+		BaseURL:         m.field("base_url").value(),
+		AuthType:        config.AuthType(m.field("auth_type").value()),
+		Mode:            config.ServerMode(m.field("mode").value()),
+	}
+	for i, f := range m.fields {
+		if len(f.authTypes) == 0 || !m.asked(i) {
+			continue
+		}
+		if f.key == "header" {
+			s.APIKeyHeader = f.value()
+			continue
+		}
+		if s.CredentialEnv == nil {
+			s.CredentialEnv = make(map[string]string)
+		}
+		s.CredentialEnv[f.key] = f.value()
+	}
+	return s
+}
+
+// Update handles messages for the wizard.
+func (m ConfigWizard) Update(msg tea.Msg) (ConfigWizard, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "esc":
+			return m, tea.Quit
+		case "tab", "down":
+			m.move(1)
+			return m, nil
+		case "shift+tab", "up":
+			m.move(-1)
+			return m, nil
+		case "left", "right":
+			if f := &m.fields[m.focus]; f.choices != nil {
+				step := 1
+				if msg.String() == "left" {
+					step = len(f.choices) - 1
+				}
+				f.choice = (f.choice + step) % len(f.choices)
+				return m, nil
+			}
+		case "enter":
+			if m.move(1) {
+				return m, nil
+			}
+			return m.write()
+		}
+
+	case tea.WindowSizeMsg:
+		h, _ := docStyle.GetFrameSize()
+		for i := range m.fields {
+			m.fields[i].input.Width = msg.Width - h - wizardLabelWidth - 1
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.fields[m.focus].input, cmd = m.fields[m.focus].input.Update(msg)
+	return m, cmd
+}
+
+// write writes config.yaml next to the adjustments file, asking before
+// overwriting one
+func (m ConfigWizard) write() (ConfigWizard, tea.Cmd) {
+	settings := m.Settings()
+	if err := settings.Validate(); err != nil {
+		m.status = statusMessageStyle(err.Error())
+		return m, nil
+	}
+	dir := filepath.Dir(m.adjustmentsFile)
+	filename := filepath.Join(dir, "config.yaml")
+	if _, err := os.Stat(filename); err == nil && !m.overwrite {
+		m.overwrite = true
+		m.status = statusMessageStyle(fmt.Sprintf("%s already exists, press enter again to overwrite it", filename))
+		return m, nil
+	}
+	data, err := EncodeConfig(settings, dir)
+	if err == nil {
+		err = os.WriteFile(filename, data, 0o644)
+	}
+	if err != nil {
+		m.status = statusMessageStyle(fmt.Sprintf("Error writing config: %v", err))
+		return m, nil
+	}
+
+	m.Written = filename
+	next := fmt.Sprintf("Wrote %s, run auto-mcp from %s", filename, dir)
+	if len(settings.CredentialEnv) > 0 {
+		next = fmt.Sprintf("Wrote %s, set %s and run auto-mcp from %s", filename, strings.Join(slices.Sorted(maps.Values(settings.CredentialEnv)), ", "), dir)
+	}
+	m.status = completeMessageStyle(next)
+	return m, tea.Tick(time.Second*2, func(time.Time) tea.Msg {
+		return tea.Quit()
+	})
+}
+
+// wizardLabelWidth is the width of the field labels
+const wizardLabelWidth = 20
+
+// View renders the wizard UI.
+func (m ConfigWizard) View() string {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("Configure Auto MCP"))
+	fmt.Fprintf(&b, "\n\nExported to %s. Write a config.yaml to serve it?\nCredentials are read from the environment variables named below.\n\n", m.adjustmentsFile)
+
+	label := lipgloss.NewStyle().Width(wizardLabelWidth)
+	for i, f := range m.fields {
+		if !m.asked(i) {
+			continue
+		}
+		style := label
+		if i == m.focus {
+			style = style.Foreground(theme.Selected)
+		}
+		b.WriteString(style.Render(f.label))
+		if f.choices != nil {
+			fmt.Fprintf(&b, "< %s >", f.value())
+		} else {
+			b.WriteString(f.input.View())
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	if m.status != "" {
+		b.WriteString(m.status)
+		b.WriteString("\n")
+	}
+	b.WriteString("(enter to continue and write on the last field, tab to move, ←/→ to choose, esc to skip)")
+	return docStyle.Render(b.String())
+}
@@ -0,0 +1,107 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/brizzai/auto-mcp/internal/parser"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeConfig(t *testing.T) {
+	dir := t.TempDir()
+	data, err := EncodeConfig(ConfigSettings{
+		SwaggerFile:     filepath.Join(dir, "specs", "swagger.json"),
+		AdjustmentsFile: filepath.Join(dir, "adjustments.yaml"),
+		BaseURL:         "https://api.example.com",
+		AuthType:        "api_key",
+		CredentialEnv:   map[string]string{"key": "PETS_KEY"},
+		APIKeyHeader:    "X-Pets-Key",
+		Mode:            "sse",
+	}, dir)
+	require.NoError(t, err)
+	assert.Equal(t, `server:
+    mode: sse
+endpoint:
+    base_url: https://api.example.com
+    auth_type: api_key
+    auth_config:
+        header: X-Pets-Key
+        key: ${PETS_KEY}
+swagger_file: specs/swagger.json
+adjustments_file: adjustments.yaml
+`, string(data))
+}
+
+func TestConfigSettings_Validate(t *testing.T) {
+	valid := ConfigSettings{SwaggerFile: "swagger.json", BaseURL: "http://localhost:3000", CredentialEnv: map[string]string{"token": "API_TOKEN"}}
+	assert.NoError(t, valid.Validate())
+
+	missingURL := valid
+	missingURL.BaseURL = "localhost:3000"
+	assert.EqualError(t, missingURL.Validate(), `base URL "localhost:3000" is not an http or https URL`)
+
+	badEnv := valid
+	badEnv.CredentialEnv = map[string]string{"token": "API TOKEN"}
+	assert.EqualError(t, badEnv.Validate(), `"API TOKEN" is not a valid environment variable name for the token`)
+}
+
+func TestAppModel_ConfigWizard(t *testing.T) {
+	dir := t.TempDir()
+	adjustmentsFile := filepath.Join(dir, "adjustments.yaml")
+	routeTools := []*parser.RouteTool{summaryRoute("GET", "/pets", "pet", "pets", "List pets").Tool}
+	var m tea.Model = NewAppModel(routeTools, parser.NewAdjuster()).WithSwaggerFile(filepath.Join(dir, "swagger.json"))
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+
+	// Export, which opens the wizard
+	m, _ = m.Update(exportMsg{})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(adjustmentsFile)})
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	require.NotNil(t, cmd)
+	m, _ = m.Update(cmd())
+	require.Equal(t, "config", m.(AppModel).page)
+	assert.FileExists(t, adjustmentsFile)
+
+	// The base URL is focused, then basic auth asks for a username and password
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("https://pets.example.com")})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	for range 3 {
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRight})
+	}
+	assert.Contains(t, m.View(), "< basic >")
+	assert.Contains(t, m.View(), "API_PASSWORD")
+	assert.NotContains(t, m.View(), "API_TOKEN")
+	for range 3 {
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	assert.Contains(t, m.View(), "< http >")
+
+	// An existing config.yaml is only overwritten when confirmed
+	configFile := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte("server: {}\n"), 0o644))
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Nil(t, cmd)
+	assert.Contains(t, m.View(), "press enter again to overwrite it")
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.NotNil(t, cmd)
+	assert.Equal(t, configFile, m.(AppModel).wizard.Written)
+
+	data, err := os.ReadFile(configFile)
+	require.NoError(t, err)
+	assert.Equal(t, `server:
+    mode: http
+endpoint:
+    base_url: https://pets.example.com
+    auth_type: basic
+    auth_config:
+        password: ${API_PASSWORD}
+        username: ${API_USERNAME}
+swagger_file: swagger.json
+adjustments_file: adjustments.yaml
+`, string(data))
+	assert.True(t, m.(AppModel).IsFinished())
+}
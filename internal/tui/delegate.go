@@ -1,41 +1,16 @@
 package tui
 
 import (
-	"github.com/brizzai/auto-mcp/internal/tui/models"
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
-	tea "github.com/charmbracelet/bubbletea"
 )
 
-// newItemDelegate returns a list.DefaultDelegate with custom update and help functions.
+// newItemDelegate returns a list.DefaultDelegate with custom help functions.
+// The list model handles the remove key, as it may apply to a whole tag.
 func newItemDelegate(keys *delegateKeyMap) list.DefaultDelegate {
 	d := list.NewDefaultDelegate()
-
-	d.UpdateFunc = func(msg tea.Msg, m *list.Model) tea.Cmd {
-		var title string
-
-		item, ok := m.SelectedItem().(models.RouteToolItem)
-		if ok {
-			title = item.Title()
-		} else {
-			return nil
-		}
-
-		switch msg := msg.(type) {
-		case tea.KeyMsg:
-			switch {
-			case key.Matches(msg, keys.remove):
-				index := m.Index()
-				updatedItem := item.ToggleRemoved()
-				m.SetItem(index, updatedItem)
-				if updatedItem.IsRemoved {
-					return m.NewStatusMessage(statusMessageStyle("Removed " + title + " from the MCP list"))
-				}
-				return m.NewStatusMessage(statusMessageStyle("Added Back" + title + " to the MCP list"))
-			}
-		}
-		return nil
-	}
+	d.Styles.SelectedTitle = d.Styles.SelectedTitle.Foreground(theme.Selected).BorderForeground(theme.Selected)
+	d.Styles.SelectedDesc = d.Styles.SelectedDesc.Foreground(theme.Selected).BorderForeground(theme.Selected)
 
 	help := []key.Binding{keys.remove}
 
@@ -0,0 +1,148 @@
+package tui
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/brizzai/auto-mcp/internal/parser"
+	"github.com/brizzai/auto-mcp/internal/tui/models"
+)
+
+// staleAdjustments returns the adjustments entries that match none of the
+// routes of the spec, nor a custom tool
+func staleAdjustments(routeTools []*parser.RouteTool, adjuster *parser.Adjuster) []parser.AdjustmentReference {
+	operations := make(map[string]bool, len(routeTools))
+	for _, rt := range routeTools {
+		operations[rt.RouteConfig.Method+" "+rt.RouteConfig.Path] = true
+	}
+	for _, custom := range adjuster.GetCustomTools() {
+		operations[custom.Method+" "+custom.Path] = true
+	}
+	return adjuster.Unmatched(operations)
+}
+
+// editsDiff describes the edits not saved to the adjustments file yet,
+// the edits kept from it and its entries matching no route
+func editsDiff(routes []models.RouteToolItem, stale []parser.AdjustmentReference) string {
+	var changed, kept []models.RouteToolItem
+	for _, route := range routes {
+		switch {
+		case route.Edited():
+			changed = append(changed, route)
+		case route.Saved != models.RouteEdits{} || len(route.SavedParameters) > 0:
+			kept = append(kept, route)
+		}
+	}
+	if len(changed) == 0 && len(kept) == 0 && len(stale) == 0 {
+		return "No edits yet"
+	}
+
+	var b strings.Builder
+	section := func(title string, count int) {
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "%s (%d):\n", title, count)
+	}
+	if len(changed) > 0 {
+		section("Unsaved edits", len(changed))
+		for _, route := range changed {
+			fmt.Fprintf(&b, "  ~ %s %s\n", route.Tool.RouteConfig.Method, route.Tool.RouteConfig.Path)
+			changes := editChanges(route.Saved, route.Edits(), route.Tool.Tool.Name)
+			changes = append(changes, parameterChanges(route.SavedParameters, route.Parameters)...)
+			for _, change := range changes {
+				fmt.Fprintf(&b, "      %s\n", change)
+			}
+		}
+	}
+	if len(kept) > 0 {
+		section("Kept from the adjustments file", len(kept))
+		for _, route := range kept {
+			summary := editSummary(route.Saved)
+			if len(route.SavedParameters) > 0 {
+				summary = append(summary, fmt.Sprintf("%d parameters edited", len(route.SavedParameters)))
+			}
+			fmt.Fprintf(&b, "  = %s %s: %s\n", route.Tool.RouteConfig.Method, route.Tool.RouteConfig.Path, strings.Join(summary, ", "))
+		}
+	}
+	if len(stale) > 0 {
+		section("Adjustments matching no route of the spec, kept by ctrl+s but not exported", len(stale))
+		for _, ref := range stale {
+			fmt.Fprintf(&b, "  ! %s: %s %s\n", ref.Section, ref.Method, ref.Path)
+		}
+	}
+	return b.String()
+}
+
+// editChanges lists how the edits of a route changed; generated is the
+// tool name used without a rename
+func editChanges(saved, current models.RouteEdits, generated string) []string {
+	var changes []string
+	if saved.Removed != current.Removed {
+		changes = append(changes, fmt.Sprintf("%s -> %s", keptOrRemoved(saved.Removed), keptOrRemoved(current.Removed)))
+	}
+	if saved.Name != current.Name {
+		changes = append(changes, fmt.Sprintf("name: %s -> %s", cmp.Or(saved.Name, generated), cmp.Or(current.Name, generated)))
+	}
+	if saved.Description != current.Description {
+		changes = append(changes, "description changed")
+	}
+	return changes
+}
+
+// parameterChanges lists the parameters whose edits changed
+func parameterChanges(saved, current map[string]models.ParameterEdit) []string {
+	names := slices.Collect(maps.Keys(current))
+	for name := range saved {
+		if _, ok := current[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	var changes []string
+	for _, name := range names {
+		if saved[name] != current[name] {
+			changes = append(changes, fmt.Sprintf("parameter %s: %s -> %s", name, parameterState(saved[name]), parameterState(current[name])))
+		}
+	}
+	return changes
+}
+
+// parameterState describes the edit of a parameter
+func parameterState(edit models.ParameterEdit) string {
+	switch {
+	case edit.Hidden:
+		return "hidden"
+	case edit.Constant != "":
+		return fmt.Sprintf("constant %q", edit.Constant)
+	case edit.Description != "":
+		return "described"
+	}
+	return "unchanged"
+}
+
+// editSummary describes the edits of a route
+func editSummary(edits models.RouteEdits) []string {
+	var summary []string
+	if edits.Removed {
+		summary = append(summary, "removed")
+	}
+	if edits.Name != "" {
+		summary = append(summary, "renamed to "+edits.Name)
+	}
+	if edits.Description != "" {
+		summary = append(summary, "description edited")
+	}
+	return summary
+}
+
+func keptOrRemoved(removed bool) string {
+	if removed {
+		return "removed"
+	}
+	return "kept"
+}
@@ -1,10 +1,14 @@
 package tui
 
 import (
+	"cmp"
+	"encoding/json"
 	"fmt"
+	"maps"
 	"os"
+	"slices"
+	"sort"
 	"strings"
-	"time"
 
 	adjustments "github.com/brizzai/auto-mcp/internal/models"
 	"github.com/brizzai/auto-mcp/internal/tui/models"
@@ -22,12 +26,15 @@ type ExportView struct {
 	height       int
 	exportStatus string
 	Success      bool
+	// Output holds the adjustments exported to stdout ("-"), which are
+	// written once the TUI exits
+	Output []byte
 }
 
 // NewExportView creates a new export view
 func NewExportView(routeTools []*models.RouteToolItem) ExportView {
 	ti := textinput.New()
-	ti.Placeholder = "filename.yaml"
+	ti.Placeholder = "filename.yaml, filename.json or - for stdout"
 	ti.Focus()
 	ti.Width = 40
 
@@ -62,7 +69,18 @@ func (m ExportView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 
 			filename := m.textInput.Value()
-			if !strings.HasSuffix(filename, ".yaml") && !strings.HasSuffix(filename, ".yml") {
+			if filename == "-" {
+				output, err := EncodeAdjustments(BuildAdjustments(m.routeTools), filename)
+				if err != nil {
+					m.err = err
+					m.exportStatus = fmt.Sprintf("Error exporting: %v", err)
+					return m, nil
+				}
+				m.Success = true
+				m.Output = output
+				return m, tea.Quit
+			}
+			if !strings.HasSuffix(filename, ".yaml") && !strings.HasSuffix(filename, ".yml") && !strings.HasSuffix(filename, ".json") {
 				filename += ".yaml"
 			}
 
@@ -80,12 +98,8 @@ func (m ExportView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 			m.Success = true
 			m.exportStatus = completeMessageStyle(fmt.Sprintf("Successfully exported to %s", filename))
-			// Wait for 1 second, then exit the application
-			return m, tea.Sequence(
-				tea.Tick(time.Second*1, func(time.Time) tea.Msg {
-					return tea.Quit()
-				}),
-			)
+			// Offer to write a config.yaml serving the file
+			return m, func() tea.Msg { return configWizardMsg{adjustmentsFile: filename} }
 		}
 
 	case tea.WindowSizeMsg:
@@ -133,28 +147,76 @@ func (m ExportView) View() string {
 // BackToMainMsg signals to go back to the main page
 type BackToMainMsg struct{}
 
-// Helper function to export routes to a YAML file
+// Helper function to export routes to a YAML file, or a JSON file when
+// filename ends in .json
 func ExportRoutesToYamlFile(routes []*models.RouteToolItem, filename string) error {
+	// Convert to YAML
+	yamlData, err := EncodeAdjustments(BuildAdjustments(routes), filename)
+	if err != nil {
+		return err
+	}
+
+	// Write to file
+	return os.WriteFile(filename, yamlData, 0o644)
+}
+
+// EncodeAdjustments encodes the adjustments file as YAML, or as JSON when
+// filename ends in .json. auto-mcp reads both, JSON being YAML too.
+func EncodeAdjustments(file adjustments.MCPAdjustments, filename string) ([]byte, error) {
+	yamlData, err := yaml.Marshal(file)
+	if err != nil || !strings.HasSuffix(filename, ".json") {
+		return yamlData, err
+	}
+	// Decoding the YAML keeps the field names of the file
+	var document any
+	if err := yaml.Unmarshal(yamlData, &document); err != nil {
+		return nil, err
+	}
+	if document == nil {
+		document = map[string]any{}
+	}
+	jsonData, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(jsonData, '\n'), nil
+}
+
+// BuildAdjustments returns the adjustments file sections for the edits of routes
+func BuildAdjustments(routes []*models.RouteToolItem) adjustments.MCPAdjustments {
 	// Create the structure for YAML output
 	exportData := adjustments.MCPAdjustments{
 		Descriptions: []adjustments.RouteDescription{},
 		Routes:       []adjustments.RouteSelection{},
 	}
 
+	// Routes in path and method order keep the file the same whatever the
+	// order of the list
+	routes = slices.Clone(routes)
+	slices.SortStableFunc(routes, func(a, b *models.RouteToolItem) int {
+		return cmp.Or(
+			cmp.Compare(a.Tool.RouteConfig.Path, b.Tool.RouteConfig.Path),
+			cmp.Compare(a.Tool.RouteConfig.Method, b.Tool.RouteConfig.Method),
+		)
+	})
+
 	// Group routes by path for both descriptions and selections
 	descriptionsByPath := make(map[string][]adjustments.RouteFieldUpdate)
 	methodsByPath := make(map[string][]string)
+	parametersByPath := make(map[string][]adjustments.RouteParameterUpdate)
+	constantsByPath := make(map[string][]adjustments.RouteDefaultUpdate)
 
 	// Process each route
 	for _, route := range routes {
 		path := route.Tool.RouteConfig.Path
 		method := route.Tool.RouteConfig.Method
 
-		// If route has a new description, add to descriptions
-		if route.NewDescription != "" {
+		// If route has a new description or name, add to descriptions
+		if route.NewDescription != "" || route.NewName != "" {
 			descriptionsByPath[path] = append(descriptionsByPath[path], adjustments.RouteFieldUpdate{
 				Method:         method,
 				NewDescription: route.NewDescription,
+				NewName:        route.NewName,
 			})
 		}
 
@@ -162,6 +224,33 @@ func ExportRoutesToYamlFile(routes []*models.RouteToolItem, filename string) err
 		if !route.IsRemoved {
 			methodsByPath[path] = append(methodsByPath[path], method)
 		}
+
+		// Hidden parameters and descriptions go to parameters, constants to defaults
+		parameters := adjustments.RouteParameterUpdate{Method: method}
+		constants := adjustments.RouteDefaultUpdate{Method: method}
+		for _, name := range slices.Sorted(maps.Keys(route.Parameters)) {
+			edit := route.Parameters[name]
+			switch {
+			case edit.Hidden:
+				parameters.Hide = append(parameters.Hide, name)
+			case edit.Constant != "":
+				if constants.Constants == nil {
+					constants.Constants = make(map[string]string)
+				}
+				constants.Constants[name] = edit.Constant
+			case edit.Description != "":
+				if parameters.Descriptions == nil {
+					parameters.Descriptions = make(map[string]string)
+				}
+				parameters.Descriptions[name] = edit.Description
+			}
+		}
+		if len(parameters.Hide) > 0 || len(parameters.Descriptions) > 0 {
+			parametersByPath[path] = append(parametersByPath[path], parameters)
+		}
+		if len(constants.Constants) > 0 {
+			constantsByPath[path] = append(constantsByPath[path], constants)
+		}
 	}
 
 	// Convert grouped descriptions to RouteDescription slice
@@ -180,14 +269,25 @@ func ExportRoutesToYamlFile(routes []*models.RouteToolItem, filename string) err
 		})
 	}
 
-	// Convert to YAML
-	yamlData, err := yaml.Marshal(exportData)
-	if err != nil {
-		return err
+	for path, updates := range parametersByPath {
+		exportData.Parameters = append(exportData.Parameters, adjustments.RouteParameters{
+			Path:    path,
+			Updates: updates,
+		})
+	}
+	for path, updates := range constantsByPath {
+		exportData.Defaults = append(exportData.Defaults, adjustments.RouteDefaults{
+			Path:    path,
+			Updates: updates,
+		})
 	}
 
-	// Write to file
-	return os.WriteFile(filename, yamlData, 0o644)
+	// Sorted by path, so files saved again diff cleanly
+	sort.Slice(exportData.Descriptions, func(i, j int) bool { return exportData.Descriptions[i].Path < exportData.Descriptions[j].Path })
+	sort.Slice(exportData.Routes, func(i, j int) bool { return exportData.Routes[i].Path < exportData.Routes[j].Path })
+	sort.Slice(exportData.Parameters, func(i, j int) bool { return exportData.Parameters[i].Path < exportData.Parameters[j].Path })
+	sort.Slice(exportData.Defaults, func(i, j int) bool { return exportData.Defaults[i].Path < exportData.Defaults[j].Path })
+	return exportData
 }
 
 // Helper function to center text horizontally
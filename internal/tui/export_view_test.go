@@ -2,16 +2,20 @@ package tui
 
 import (
 	"os"
+	"path/filepath"
+	"slices"
 	"sort"
 	"testing"
 
 	"github.com/brizzai/auto-mcp/internal/parser"
 	"github.com/brizzai/auto-mcp/internal/requester"
 	"github.com/brizzai/auto-mcp/internal/tui/models"
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v3"
 )
 
@@ -68,11 +72,21 @@ func TestExportRoutesToYamlFile(t *testing.T) {
 			routes:   createRoutesWithUpdatedDescriptions(),
 			expected: expectedYamlForUpdatedDescriptions(),
 		},
+		{
+			name:     "Routes with renamed tools",
+			routes:   createRoutesWithRenamedTools(),
+			expected: expectedYamlForRenamedTools(),
+		},
 		{
 			name:     "Routes marked as removed",
 			routes:   createRemovedRoutes(),
 			expected: map[string]interface{}{},
 		},
+		{
+			name:     "Routes with parameter edits",
+			routes:   createRoutesWithParameterEdits(),
+			expected: expectedYamlForParameterEdits(),
+		},
 		{
 			name:     "Routes with both updates and removals",
 			routes:   createMixedUpdatesAndRemovals(),
@@ -134,6 +148,7 @@ type routeData struct {
 	method         string
 	description    string
 	newDescription string
+	newName        string
 }
 
 // createRoutesWithUpdatedDescriptions creates routes with updated descriptions
@@ -185,6 +200,98 @@ func expectedYamlForUpdatedDescriptions() map[string]interface{} {
 	}
 }
 
+// createRoutesWithRenamedTools creates routes with renamed tools
+func createRoutesWithRenamedTools() []*models.RouteToolItem {
+	return createRouteItems([]*routeData{
+		{path: "/pet/findByStatus", method: "GET", description: "Find pets", newName: "find_pets_by_status"},
+		{path: "/pet", method: "POST", description: "Add pet", newDescription: "Adds a pet", newName: "add_pet"},
+	}, []string{})
+}
+
+func createRoutesWithParameterEdits() []*models.RouteToolItem {
+	routes := createRouteItems([]*routeData{
+		{path: "/pet", method: "POST", description: "Add pet"},
+	}, []string{})
+	routes[0].Parameters = map[string]models.ParameterEdit{
+		"X-Debug":     {Hidden: true},
+		"body.status": {Constant: "available"},
+		"body.name":   {Description: "The pet's name"},
+	}
+	return routes
+}
+
+// expectedYamlForParameterEdits returns the expected YAML for the parameter edits test case
+func expectedYamlForParameterEdits() map[string]interface{} {
+	return map[string]interface{}{
+		"parameters": []interface{}{
+			map[string]interface{}{
+				"path": "/pet",
+				"updates": []interface{}{
+					map[string]interface{}{
+						"method":       "POST",
+						"hide":         []interface{}{"X-Debug"},
+						"descriptions": map[string]interface{}{"body.name": "The pet's name"},
+					},
+				},
+			},
+		},
+		"defaults": []interface{}{
+			map[string]interface{}{
+				"path": "/pet",
+				"updates": []interface{}{
+					map[string]interface{}{
+						"method":    "POST",
+						"constants": map[string]interface{}{"body.status": "available"},
+					},
+				},
+			},
+		},
+		"routes": []interface{}{
+			map[string]interface{}{
+				"path":    "/pet",
+				"methods": []interface{}{"POST"},
+			},
+		},
+	}
+}
+
+// expectedYamlForRenamedTools returns the expected YAML for the renamed tools test case
+func expectedYamlForRenamedTools() map[string]interface{} {
+	return map[string]interface{}{
+		"descriptions": []interface{}{
+			map[string]interface{}{
+				"path": "/pet/findByStatus",
+				"updates": []interface{}{
+					map[string]interface{}{
+						"method":   "GET",
+						"new_name": "find_pets_by_status",
+					},
+				},
+			},
+			map[string]interface{}{
+				"path": "/pet",
+				"updates": []interface{}{
+					map[string]interface{}{
+						"method":          "POST",
+						"new_description": "Adds a pet",
+						"new_name":        "add_pet",
+					},
+				},
+			},
+		},
+		"routes": []interface{}{
+			map[string]interface{}{
+				"path":    "/pet/findByStatus",
+				"methods": []interface{}{"GET"},
+			},
+			map[string]interface{}{
+				"path":    "/pet",
+				"methods": []interface{}{"POST"},
+			},
+		},
+	}
+}
+
 // createRemovedRoutes creates routes marked as removed
 func createRemovedRoutes() []*models.RouteToolItem {
 	return createRouteItems([]*routeData{
@@ -194,6 +301,64 @@ func createRemovedRoutes() []*models.RouteToolItem {
 }
 
 // createMixedUpdatesAndRemovals creates routes with both updates and removals
+func TestExportRoutesToJSONFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "adjustments.json")
+	require.NoError(t, ExportRoutesToYamlFile(createMixedUpdatesAndRemovals(), filename))
+
+	data, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"descriptions": [
+			{"path": "/api/items", "updates": [{"method": "POST", "new_description": "Updated item creation"}]},
+			{"path": "/api/users", "updates": [{"method": "GET", "new_description": "Updated users description"}]}
+		],
+		"routes": [
+			{"path": "/api/items", "methods": ["POST"]},
+			{"path": "/api/users", "methods": ["GET"]}
+		]
+	}`, string(data))
+
+	// auto-mcp loads JSON files like YAML ones
+	adjuster := parser.NewAdjuster()
+	require.NoError(t, adjuster.Load(filename))
+	assert.Equal(t, "Updated item creation", adjuster.GetDescription("/api/items", "POST", ""))
+	assert.False(t, adjuster.ExistsInMCP("/api/users", "DELETE"))
+}
+
+func TestExportView_Stdout(t *testing.T) {
+	m := NewExportView(createMixedUpdatesAndRemovals())
+	m.textInput.SetValue("-")
+	model, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = model.(ExportView)
+
+	// The adjustments are written once the TUI quits
+	require.NotNil(t, cmd)
+	assert.Equal(t, tea.Quit(), cmd())
+	assert.True(t, m.Success)
+	want, err := yaml.Marshal(BuildAdjustments(createMixedUpdatesAndRemovals()))
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(m.Output))
+}
+
+func TestBuildAdjustments_Order(t *testing.T) {
+	routes := createRouteItems([]*routeData{
+		{path: "/api/users", method: "POST", description: "Create user", newDescription: "Updated user creation"},
+		{path: "/api/items", method: "GET", description: "List items", newDescription: ""},
+		{path: "/api/users", method: "GET", description: "Get users", newDescription: "Updated users description"},
+		{path: "/api/items", method: "DELETE", description: "Delete item", newDescription: "Updated item deletion"},
+	}, nil)
+	reversed := slices.Clone(routes)
+	slices.Reverse(reversed)
+
+	// The list order, e.g. by tag or method, doesn't change the file
+	want, err := yaml.Marshal(BuildAdjustments(routes))
+	assert.NoError(t, err)
+	got, err := yaml.Marshal(BuildAdjustments(reversed))
+	assert.NoError(t, err)
+	assert.Equal(t, string(want), string(got))
+	assert.Equal(t, []string{"DELETE", "GET"}, BuildAdjustments(reversed).Routes[0].Methods)
+}
+
 func createMixedUpdatesAndRemovals() []*models.RouteToolItem {
 	return createRouteItems([]*routeData{
 		{path: "/api/users", method: "GET", description: "Get users", newDescription: "Updated users description"},
@@ -268,6 +433,11 @@ func createRouteItems(routes []*routeData, removedRoutes []string) []*models.Rou
 			item = item.UpdatedDescription(r.newDescription)
 		}
 
+		// Apply name update if provided
+		if r.newName != "" {
+			item = item.UpdatedName(r.newName)
+		}
+
 		// Mark as removed if in the removal list
 		routeKey := r.path + ":" + r.method
 		for _, removedRoute := range removedRoutes {
@@ -0,0 +1,95 @@
+package tui
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+)
+
+// routeQuery is a parsed list filter: ":method DELETE" keeps routes with one
+// of the comma-separated methods, ":path ^/admin/" keeps routes whose path
+// matches the regular expression, and the remaining text matches fuzzily
+type routeQuery struct {
+	methods []string
+	path    *regexp.Regexp
+	text    string
+}
+
+// parseRouteQuery parses a filter term. A path expression that isn't a valid
+// regular expression, e.g. while it is being typed, matches literally.
+func parseRouteQuery(term string) routeQuery {
+	var query routeQuery
+	var text []string
+	fields := strings.Fields(term)
+	for i := 0; i < len(fields); i++ {
+		switch field := fields[i]; {
+		case field == ":method" && i+1 < len(fields):
+			i++
+			for _, method := range strings.Split(fields[i], ",") {
+				if method != "" {
+					query.methods = append(query.methods, strings.ToUpper(method))
+				}
+			}
+		case field == ":path" && i+1 < len(fields):
+			i++
+			path, err := regexp.Compile(fields[i])
+			if err != nil {
+				path = regexp.MustCompile(regexp.QuoteMeta(fields[i]))
+			}
+			query.path = path
+		case field == ":method", field == ":path":
+			// The value is still being typed
+		default:
+			text = append(text, field)
+		}
+	}
+	query.text = strings.Join(text, " ")
+	return query
+}
+
+// matches reports whether a route's filter value, which starts with its
+// method and path, satisfies the method and path terms
+func (q routeQuery) matches(target string) bool {
+	if len(q.methods) == 0 && q.path == nil {
+		return true
+	}
+	parts := strings.SplitN(target, " ", 3)
+	if len(parts) < 2 {
+		// Tag headers have no method or path
+		return false
+	}
+	if len(q.methods) > 0 && !slices.Contains(q.methods, parts[0]) {
+		return false
+	}
+	return q.path == nil || q.path.MatchString(parts[1])
+}
+
+// filterRoutes is the list filter: routes failing the method and path terms
+// are dropped and the rest ranked fuzzily by the remaining text
+func filterRoutes(term string, targets []string) []list.Rank {
+	query := parseRouteQuery(term)
+
+	var candidates []int
+	var candidateTargets []string
+	for i, target := range targets {
+		if query.matches(target) {
+			candidates = append(candidates, i)
+			candidateTargets = append(candidateTargets, target)
+		}
+	}
+
+	if query.text == "" {
+		ranks := make([]list.Rank, len(candidates))
+		for i, index := range candidates {
+			ranks[i] = list.Rank{Index: index}
+		}
+		return ranks
+	}
+	ranks := list.DefaultFilter(query.text, candidateTargets)
+	for i := range ranks {
+		ranks[i].Index = candidates[ranks[i].Index]
+	}
+	return ranks
+}
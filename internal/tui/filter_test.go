@@ -0,0 +1,41 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterRoutes(t *testing.T) {
+	targets := []string{
+		"#admin",
+		"GET /admin/users get_admin_users List users",
+		"DELETE /admin/users/{id} delete_admin_users_id Delete a user",
+		"GET /pets get_pets List pets",
+		"DELETE /pets/{id} delete_pets_id Delete a pet",
+	}
+
+	tests := []struct {
+		name string
+		term string
+		want []int
+	}{
+		{name: "method", term: ":method DELETE", want: []int{2, 4}},
+		{name: "methods are case-insensitive and comma-separated", term: ":method get,delete", want: []int{1, 2, 3, 4}},
+		{name: "path regex", term: ":path ^/admin/", want: []int{1, 2}},
+		{name: "method and path", term: ":method DELETE :path ^/admin/", want: []int{2}},
+		{name: "invalid regex matches literally", term: ":path {id", want: []int{2, 4}},
+		{name: "fuzzy text within the method filter", term: ":method DELETE pet", want: []int{4}},
+		{name: "value still being typed", term: "pets :method", want: []int{3, 4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []int
+			for _, rank := range filterRoutes(tt.term, targets) {
+				got = append(got, rank.Index)
+			}
+			assert.ElementsMatch(t, tt.want, got)
+		})
+	}
+}
@@ -1,6 +1,13 @@
 package tui
 
 import (
+	"bytes"
+	"fmt"
+	"maps"
+	"os"
+	"strings"
+	"time"
+
 	"github.com/brizzai/auto-mcp/internal/parser"
 	"github.com/brizzai/auto-mcp/internal/tui/models"
 	"github.com/charmbracelet/bubbles/key"
@@ -12,6 +19,15 @@ import (
 // listKeyMap holds key bindings for the list actions.
 type listKeyMap struct {
 	editDescription key.Binding
+	rename          key.Binding
+	preview         key.Binding
+	try             key.Binding
+	diff            key.Binding
+	parameters      key.Binding
+	collapse        key.Binding
+	sort            key.Binding
+	mark            key.Binding
+	markAll         key.Binding
 	save            key.Binding
 	finish          key.Binding
 	quit            key.Binding
@@ -28,6 +44,42 @@ func newListKeyMap() *listKeyMap {
 			key.WithKeys("E", "e"),
 			key.WithHelp("E", "Edit Description"),
 		),
+		rename: key.NewBinding(
+			key.WithKeys("N", "n"),
+			key.WithHelp("N", "Rename Tool"),
+		),
+		preview: key.NewBinding(
+			key.WithKeys("P", "p"),
+			key.WithHelp("P", "Preview Tool"),
+		),
+		try: key.NewBinding(
+			key.WithKeys("T", "t"),
+			key.WithHelp("T", "Try Route"),
+		),
+		diff: key.NewBinding(
+			key.WithKeys("D", "d"),
+			key.WithHelp("D", "Show Edits"),
+		),
+		parameters: key.NewBinding(
+			key.WithKeys("O", "o"),
+			key.WithHelp("O", "Edit Parameters"),
+		),
+		collapse: key.NewBinding(
+			key.WithKeys("enter", "C", "c"),
+			key.WithHelp("C", "Collapse/Expand Tag"),
+		),
+		sort: key.NewBinding(
+			key.WithKeys("S", "s"),
+			key.WithHelp("S", "Sort by Tag/Path/Method"),
+		),
+		mark: key.NewBinding(
+			key.WithKeys(" "),
+			key.WithHelp("space", "Mark"),
+		),
+		markAll: key.NewBinding(
+			key.WithKeys("A", "a"),
+			key.WithHelp("A", "Mark All Shown"),
+		),
 		save: key.NewBinding(
 			key.WithKeys("ctrl+s"),
 			key.WithHelp("ctrl+s", "Save"),
@@ -43,25 +95,77 @@ func newListKeyMap() *listKeyMap {
 	}
 }
 
-// ListItemModel for the TUI
+// ListItemModel for the TUI. Routes are grouped by their first OpenAPI tag,
+// each group headed by a TagItem that collapses it, or sorted by path or
+// method without groups.
 type ListItemModel struct {
-	list      list.Model
-	keys      *listKeyMap
-	editing   bool
-	editIndex int
-	editModal DescriptionEditorModal // Holds the edit modal when editing
+	list         list.Model
+	keys         *listKeyMap
+	delegateKeys *delegateKeyMap
+	routes       []models.RouteToolItem // Every route, sorted in order
+	collapsed    map[string]bool        // Tags whose routes are hidden
+	order        routeOrder             // The order of routes, grouped by tag by default
+	editing      bool
+	editIndex    int                    // Index in routes of the route being edited
+	editModal    DescriptionEditorModal // Holds the edit modal when editing
+	renaming     bool
+	nameModal    NameEditorModal // Holds the name modal when renaming
+	previewing   bool
+	preview      ScrollPane // Holds the tool preview or the edits when previewing
+	trying       bool
+	try          TryView                      // Holds the try screen when trying a route
+	call         CallFunc                     // Sends the requests of the try screen, nil disables it
+	stale        []parser.AdjustmentReference // Adjustments entries matching no route
+	parameters   bool
+	paramPage    ParameterPage // Holds the parameter page when editing parameters
+	file         string        // The adjustments file ctrl+s saves to, empty when none was loaded
+	suggest      int           // The tool count edits were suggested for, 0 when none were
+	autosaved    []byte        // What was last written to the recovery file
 }
 
+// autosaveInterval is how often edits are written to the recovery file
+const autosaveInterval = 30 * time.Second
+
+// autosaveMsg triggers writing edits to the recovery file
+type autosaveMsg struct{}
+
 // Init returns the initial command for the list model.
 func (m ListItemModel) Init() tea.Cmd {
 	return nil
 }
 
+// Busy reports whether the list handles esc itself: a modal is open, a
+// filter is set or routes are marked
+func (m ListItemModel) Busy() bool {
+	return m.editing || m.renaming || m.previewing || m.trying || m.parameters || m.list.FilterState() != list.Unfiltered || m.markedCount() > 0
+}
+
+// Autosave returns the command writing edits to the recovery file every
+// autosaveInterval
+func (m ListItemModel) Autosave() tea.Cmd {
+	return tea.Tick(autosaveInterval, func(time.Time) tea.Msg { return autosaveMsg{} })
+}
+
 // Update handles messages for the list and modal, including editing logic.
 func (m ListItemModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if _, ok := msg.(autosaveMsg); ok {
+		return m, tea.Batch(m.autosave(), m.Autosave())
+	}
 	if m.editing {
 		return m.handleEditModeUpdate(msg)
 	}
+	if m.renaming {
+		return m.handleRenameModeUpdate(msg)
+	}
+	if m.previewing {
+		return m.handlePreviewModeUpdate(msg)
+	}
+	if m.trying {
+		return m.handleTryModeUpdate(msg)
+	}
+	if m.parameters {
+		return m.handleParameterModeUpdate(msg)
+	}
 	return m.handleListModeUpdate(msg)
 }
 
@@ -69,13 +173,17 @@ func (m ListItemModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 func (m ListItemModel) handleEditModeUpdate(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		if key.Matches(msg, m.keys.save) {
+		switch {
+		case msg.Type == tea.KeyEsc:
 			m.editing = false
-			item := m.list.SelectedItem().(models.RouteToolItem)
+			return m, nil
+		case key.Matches(msg, m.keys.save):
+			m.editing = false
+			item := m.routes[m.editIndex]
 			newDescription := m.editModal.Description()
 			if newDescription != item.Tool.RouteConfig.Description {
-				m.list.SetItem(m.editIndex, item.UpdatedDescription(newDescription))
-				m.list.NewStatusMessage(statusMessageStyle("Updated description for", m.list.SelectedItem().(models.RouteToolItem).Title()))
+				m.routes[m.editIndex] = item.UpdatedDescription(newDescription)
+				return m, tea.Batch(m.refresh(), m.list.NewStatusMessage(statusMessageStyle("Updated description for", item.Title())))
 			}
 			return m, nil
 		}
@@ -89,27 +197,224 @@ func (m ListItemModel) handleEditModeUpdate(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// handleRenameModeUpdate handles messages when renaming a tool
+func (m ListItemModel) handleRenameModeUpdate(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case msg.Type == tea.KeyEsc:
+			m.renaming = false
+			return m, nil
+		case key.Matches(msg, m.keys.save), msg.Type == tea.KeyEnter:
+			item := m.routes[m.editIndex]
+			newName := strings.TrimSpace(m.nameModal.Name())
+			if newName == item.Tool.Tool.Name {
+				newName = ""
+			}
+			if err := m.validateToolName(item, newName); err != nil {
+				m.nameModal = m.nameModal.WithError(err)
+				return m, nil
+			}
+			m.renaming = false
+			if newName != item.NewName {
+				item = item.UpdatedName(newName)
+				m.routes[m.editIndex] = item
+				return m, tea.Batch(m.refresh(), m.list.NewStatusMessage(statusMessageStyle("Renamed tool of", item.Title())))
+			}
+			return m, nil
+		}
+
+	case tea.WindowSizeMsg:
+		h, v := docStyle.GetFrameSize()
+		m.list.SetSize(msg.Width-h, msg.Height-v)
+	}
+	var cmd tea.Cmd
+	m.nameModal, cmd = m.nameModal.Update(msg)
+	return m, cmd
+}
+
+// handlePreviewModeUpdate handles messages when previewing a tool
+func (m ListItemModel) handlePreviewModeUpdate(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if msg.Type == tea.KeyEsc || key.Matches(msg, m.keys.preview, m.keys.diff) {
+			m.previewing = false
+			return m, nil
+		}
+
+	case tea.WindowSizeMsg:
+		h, v := docStyle.GetFrameSize()
+		m.list.SetSize(msg.Width-h, msg.Height-v)
+		var cmd tea.Cmd
+		m.preview, cmd = m.preview.Update(tea.WindowSizeMsg{Width: m.list.Width(), Height: m.list.Height()})
+		return m, cmd
+	}
+	var cmd tea.Cmd
+	m.preview, cmd = m.preview.Update(msg)
+	return m, cmd
+}
+
+// handleTryModeUpdate handles messages when trying a route
+func (m ListItemModel) handleTryModeUpdate(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if msg.Type == tea.KeyEsc {
+			m.trying = false
+			return m, nil
+		}
+
+	case tea.WindowSizeMsg:
+		h, v := docStyle.GetFrameSize()
+		m.list.SetSize(msg.Width-h, msg.Height-v)
+		var cmd tea.Cmd
+		m.try, cmd = m.try.Update(tea.WindowSizeMsg{Width: m.list.Width(), Height: m.list.Height()})
+		return m, cmd
+	}
+	var cmd tea.Cmd
+	m.try, cmd = m.try.Update(msg)
+	return m, cmd
+}
+
+// handleParameterModeUpdate handles messages when editing parameters
+func (m ListItemModel) handleParameterModeUpdate(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if msg.Type == tea.KeyEsc && !m.paramPage.Editing() {
+			m.parameters = false
+			item := m.paramPage.Item()
+			if !maps.Equal(item.Parameters, m.routes[m.editIndex].Parameters) {
+				m.routes[m.editIndex] = item
+				return m, tea.Batch(m.refresh(), m.list.NewStatusMessage(statusMessageStyle("Updated parameters of", item.Title())))
+			}
+			return m, nil
+		}
+
+	case tea.WindowSizeMsg:
+		h, v := docStyle.GetFrameSize()
+		m.list.SetSize(msg.Width-h, msg.Height-v)
+		var cmd tea.Cmd
+		m.paramPage, cmd = m.paramPage.Update(tea.WindowSizeMsg{Width: m.list.Width(), Height: m.list.Height()})
+		return m, cmd
+	}
+	var cmd tea.Cmd
+	m.paramPage, cmd = m.paramPage.Update(msg)
+	return m, cmd
+}
+
+// validateToolName checks a new name for item against the MCP name rules and
+// the names of the other kept tools. An empty name restores the generated one.
+func (m ListItemModel) validateToolName(item models.RouteToolItem, newName string) error {
+	name := newName
+	if name == "" {
+		name = item.Tool.Tool.Name
+	} else if err := parser.ValidToolName(name); err != nil {
+		return err
+	}
+
+	for _, other := range m.routes {
+		if other.Tool == item.Tool || other.IsRemoved {
+			continue
+		}
+		if other.ToolName() == name {
+			return fmt.Errorf("tool name %s is already used by %s %s", name, other.Tool.RouteConfig.Method, other.Tool.RouteConfig.Path)
+		}
+	}
+	return nil
+}
+
 // handleListModeUpdate handles messages when in list mode
 func (m ListItemModel) handleListModeUpdate(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		// Keys typed into the filter are not commands
+		if m.list.FilterState() == list.Filtering {
+			break
+		}
 		switch {
 		case key.Matches(msg, m.keys.quit):
 			return m, tea.Quit
+		case msg.Type == tea.KeyEsc && m.list.FilterState() == list.Unfiltered && m.markedCount() > 0:
+			m.setMarked(func(models.RouteToolItem) bool { return true }, false)
+			return m, tea.Batch(m.refresh(), m.list.NewStatusMessage(statusMessageStyle("Cleared the marks")))
+		case key.Matches(msg, m.delegateKeys.remove) && m.markedCount() > 0:
+			return m, m.toggleRemovedMarked()
+		case key.Matches(msg, m.delegateKeys.remove):
+			return m, m.toggleRemoved()
+		case key.Matches(msg, m.keys.mark):
+			return m, m.toggleMarked()
+		case key.Matches(msg, m.keys.markAll):
+			return m, m.markAllShown()
+		case key.Matches(msg, m.keys.collapse):
+			return m, m.toggleCollapsed()
+		case key.Matches(msg, m.keys.sort):
+			return m, m.cycleOrder()
 		case key.Matches(msg, m.keys.editDescription):
-			idx := m.list.Index()
-			item, ok := m.list.SelectedItem().(models.RouteToolItem)
-			if ok {
-				if item.IsRemoved {
-					m.list.NewStatusMessage(statusMessageStyle("Can't edit removed routes", ""))
-					return m, nil
-				}
-				m.editing = true
-				m.editIndex = idx
-				// Create the modal with the current description as initial value
-				m.editModal = NewEditModal(item.Description())
-				return m, nil
+			idx, item, ok := m.selectedRoute()
+			if !ok {
+				return m, m.list.NewStatusMessage(statusMessageStyle("Select a route to edit"))
+			}
+			if item.IsRemoved {
+				return m, m.list.NewStatusMessage(statusMessageStyle("Can't edit removed routes", ""))
+			}
+			m.editing = true
+			m.editIndex = idx
+			// Create the modal with the current description as initial value
+			m.editModal = NewEditModal(item.RouteDescription())
+			return m, nil
+		case key.Matches(msg, m.keys.rename):
+			idx, item, ok := m.selectedRoute()
+			if !ok {
+				return m, m.list.NewStatusMessage(statusMessageStyle("Select a route to rename"))
+			}
+			if item.IsRemoved {
+				return m, m.list.NewStatusMessage(statusMessageStyle("Can't rename removed routes", ""))
+			}
+			m.renaming = true
+			m.editIndex = idx
+			m.nameModal = NewNameModal(item.NewName, item.Tool.Tool.Name)
+			return m, nil
+		case key.Matches(msg, m.keys.preview):
+			idx, item, ok := m.selectedRoute()
+			if !ok {
+				return m, m.list.NewStatusMessage(statusMessageStyle("Select a route to preview"))
 			}
+			if item.IsRemoved {
+				return m, m.list.NewStatusMessage(statusMessageStyle("Removed routes generate no tool", ""))
+			}
+			m.previewing = true
+			m.editIndex = idx
+			m.preview = NewToolPreview(item, m.list.Width(), m.list.Height())
+			return m, nil
+		case key.Matches(msg, m.keys.save):
+			return m, m.save()
+		case key.Matches(msg, m.keys.parameters):
+			idx, item, ok := m.selectedRoute()
+			if !ok {
+				return m, m.list.NewStatusMessage(statusMessageStyle("Select a route to edit its parameters"))
+			}
+			if item.IsRemoved {
+				return m, m.list.NewStatusMessage(statusMessageStyle("Can't edit removed routes", ""))
+			}
+			m.parameters = true
+			m.editIndex = idx
+			m.paramPage = NewParameterPage(item, m.list.Width(), m.list.Height())
+			return m, nil
+		case key.Matches(msg, m.keys.diff):
+			m.previewing = true
+			m.preview = NewScrollPane("Edits", editsDiff(m.routes, m.stale), m.list.Width(), m.list.Height())
+			return m, nil
+		case key.Matches(msg, m.keys.try):
+			idx, item, ok := m.selectedRoute()
+			if !ok {
+				return m, m.list.NewStatusMessage(statusMessageStyle("Select a route to try"))
+			}
+			if m.call == nil {
+				return m, m.list.NewStatusMessage(statusMessageStyle("Trying routes is not available"))
+			}
+			m.trying = true
+			m.editIndex = idx
+			m.try = NewTryView(item, m.call, m.list.Width(), m.list.Height())
+			return m, nil
 		case key.Matches(msg, m.keys.finish):
 			return m, func() tea.Msg {
 				return DoneMsg{RouteTools: m.GetRoutesUpdates()}
@@ -125,61 +430,461 @@ func (m ListItemModel) handleListModeUpdate(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// edited reports whether a route was edited since loading or saving
+func (m ListItemModel) edited() bool {
+	for _, route := range m.routes {
+		if route.Edited() {
+			return true
+		}
+	}
+	return false
+}
+
+// save writes the edits into the loaded adjustments file, then removes the
+// recovery file and takes the edits as saved
+func (m *ListItemModel) save() tea.Cmd {
+	if m.file == "" {
+		return m.list.NewStatusMessage(statusMessageStyle("No adjustments file was loaded, F exports one"))
+	}
+	backup, err := SaveAdjustments(m.GetRoutesUpdates(), m.file)
+	if err != nil {
+		return m.list.NewStatusMessage(statusMessageStyle("Error saving: " + err.Error()))
+	}
+	if err := os.Remove(RecoveryFile(m.file)); err != nil && !os.IsNotExist(err) {
+		return m.list.NewStatusMessage(statusMessageStyle("Error removing the recovery file: " + err.Error()))
+	}
+	m.autosaved = nil
+
+	for i, route := range m.routes {
+		m.routes[i].Saved = route.Edits()
+		m.routes[i].SavedParameters = route.Parameters
+	}
+	status := "Saved " + m.file
+	if backup != "" {
+		status += ", backup in " + backup
+	}
+	return tea.Batch(m.refresh(), m.list.NewStatusMessage(completeMessageStyle(status)))
+}
+
+// autosave writes unsaved edits to the recovery file when they changed
+// since the last autosave
+func (m *ListItemModel) autosave() tea.Cmd {
+	if !m.edited() {
+		return nil
+	}
+	var data []byte
+	if m.file != "" {
+		var err error
+		if data, err = os.ReadFile(m.file); err != nil && !os.IsNotExist(err) {
+			return m.list.NewStatusMessage(statusMessageStyle("Error autosaving: " + err.Error()))
+		}
+	}
+	merged, err := MergeAdjustments(data, m.GetRoutesUpdates())
+	if err != nil {
+		return m.list.NewStatusMessage(statusMessageStyle("Error autosaving: " + err.Error()))
+	}
+	if bytes.Equal(merged, m.autosaved) {
+		return nil
+	}
+	if err := os.WriteFile(RecoveryFile(m.file), merged, 0o644); err != nil {
+		return m.list.NewStatusMessage(statusMessageStyle("Error autosaving: " + err.Error()))
+	}
+	m.autosaved = merged
+	return nil
+}
+
+// selectedRoute returns the selected route and its index in routes; ok is
+// false when a tag header is selected
+func (m ListItemModel) selectedRoute() (int, models.RouteToolItem, bool) {
+	item, ok := m.list.SelectedItem().(models.RouteToolItem)
+	if !ok {
+		return -1, models.RouteToolItem{}, false
+	}
+	for i, route := range m.routes {
+		if route.Tool == item.Tool {
+			return i, route, true
+		}
+	}
+	return -1, models.RouteToolItem{}, false
+}
+
+// toggleRemoved removes or restores the selected route. On a tag header it
+// removes every route of the tag, or restores them all when none is kept.
+func (m *ListItemModel) toggleRemoved() tea.Cmd {
+	if idx, item, ok := m.selectedRoute(); ok {
+		item = item.ToggleRemoved()
+		m.routes[idx] = item
+		status := "Removed " + item.Title() + " from the MCP list"
+		if !item.IsRemoved {
+			status = "Added back " + item.Title() + " to the MCP list"
+		}
+		return tea.Batch(m.refresh(), m.list.NewStatusMessage(statusMessageStyle(status)))
+	}
+
+	header, ok := m.list.SelectedItem().(models.TagItem)
+	if !ok {
+		return nil
+	}
+	remove := header.Removed < header.Routes
+	for i, route := range m.routes {
+		if route.Tag() == header.Tag && route.IsRemoved != remove {
+			m.routes[i] = route.ToggleRemoved()
+		}
+	}
+	status := fmt.Sprintf("Removed the %s routes from the MCP list", header.Tag)
+	if !remove {
+		status = fmt.Sprintf("Added back the %s routes to the MCP list", header.Tag)
+	}
+	return tea.Batch(m.refresh(), m.list.NewStatusMessage(statusMessageStyle(status)))
+}
+
+// markedCount returns the number of marked routes
+func (m ListItemModel) markedCount() int {
+	count := 0
+	for _, route := range m.routes {
+		if route.Marked {
+			count++
+		}
+	}
+	return count
+}
+
+// setMarked sets the mark of the routes matching match
+func (m *ListItemModel) setMarked(match func(models.RouteToolItem) bool, marked bool) {
+	for i, route := range m.routes {
+		if match(route) {
+			m.routes[i].Marked = marked
+		}
+	}
+}
+
+// toggleMarked marks or unmarks the selected route. On a tag header it marks
+// every route of the tag, or unmarks them when all are marked.
+func (m *ListItemModel) toggleMarked() tea.Cmd {
+	if idx, item, ok := m.selectedRoute(); ok {
+		m.routes[idx] = item.ToggleMarked()
+		return m.refresh()
+	}
+	header, ok := m.list.SelectedItem().(models.TagItem)
+	if !ok {
+		return nil
+	}
+	inTag := func(route models.RouteToolItem) bool { return route.Tag() == header.Tag }
+	m.setMarked(inTag, !m.allMarked(inTag))
+	return m.refresh()
+}
+
+// markAllShown marks the routes the list shows, e.g. every match of the
+// filter, or unmarks them when all are marked
+func (m *ListItemModel) markAllShown() tea.Cmd {
+	shown := make(map[*parser.RouteTool]bool)
+	for _, item := range m.list.VisibleItems() {
+		if route, ok := item.(models.RouteToolItem); ok {
+			shown[route.Tool] = true
+		}
+	}
+	isShown := func(route models.RouteToolItem) bool { return shown[route.Tool] }
+	marked := !m.allMarked(isShown)
+	m.setMarked(isShown, marked)
+
+	status := fmt.Sprintf("Marked %d routes", len(shown))
+	if !marked {
+		status = fmt.Sprintf("Unmarked %d routes", len(shown))
+	}
+	return tea.Batch(m.refresh(), m.list.NewStatusMessage(statusMessageStyle(status)))
+}
+
+// allMarked reports whether every route matching match is marked
+func (m ListItemModel) allMarked(match func(models.RouteToolItem) bool) bool {
+	for _, route := range m.routes {
+		if match(route) && !route.Marked {
+			return false
+		}
+	}
+	return true
+}
+
+// toggleRemovedMarked removes the marked routes, or restores them when all
+// are removed, and clears the marks
+func (m *ListItemModel) toggleRemovedMarked() tea.Cmd {
+	remove := false
+	for _, route := range m.routes {
+		if route.Marked && !route.IsRemoved {
+			remove = true
+			break
+		}
+	}
+	count := 0
+	for i, route := range m.routes {
+		if route.Marked {
+			m.routes[i].IsRemoved = remove
+			m.routes[i].Marked = false
+			count++
+		}
+	}
+	status := fmt.Sprintf("Removed %d routes from the MCP list", count)
+	if !remove {
+		status = fmt.Sprintf("Added back %d routes to the MCP list", count)
+	}
+	return tea.Batch(m.refresh(), m.list.NewStatusMessage(statusMessageStyle(status)))
+}
+
+// cycleOrder sorts the routes in the next order, keeping the selected
+// route selected
+func (m *ListItemModel) cycleOrder() tea.Cmd {
+	_, selected, ok := m.selectedRoute()
+	m.order = m.order.next()
+	orderRoutes(m.routes, m.order)
+	cmd := m.refresh()
+
+	if ok && m.list.FilterState() == list.Unfiltered {
+		for i, item := range m.list.Items() {
+			if route, isRoute := item.(models.RouteToolItem); isRoute && route.Tool == selected.Tool {
+				m.list.Select(i)
+				break
+			}
+		}
+	}
+	return tea.Batch(cmd, m.list.NewStatusMessage(statusMessageStyle("Sorted by "+m.order.String())))
+}
+
+// toggleCollapsed collapses or expands the tag of the selected item and
+// selects its header
+func (m *ListItemModel) toggleCollapsed() tea.Cmd {
+	if m.order != byTag {
+		return m.list.NewStatusMessage(statusMessageStyle("Routes are grouped by tag when sorted by tag, S sorts them"))
+	}
+	var tag string
+	switch item := m.list.SelectedItem().(type) {
+	case models.TagItem:
+		tag = item.Tag
+	case models.RouteToolItem:
+		tag = item.Tag()
+	default:
+		return nil
+	}
+	m.collapsed[tag] = !m.collapsed[tag]
+	cmd := m.refresh()
+
+	if m.list.FilterState() == list.Unfiltered {
+		for i, item := range m.list.Items() {
+			if header, ok := item.(models.TagItem); ok && header.Tag == tag {
+				m.list.Select(i)
+				break
+			}
+		}
+	}
+	return cmd
+}
+
+// refresh rebuilds the list items from routes
+func (m *ListItemModel) refresh() tea.Cmd {
+	return m.list.SetItems(m.items())
+}
+
+// items lists a header for every tag followed by its routes, unless the
+// tag is collapsed. Routes sorted by path or method are listed without
+// headers.
+func (m ListItemModel) items() []list.Item {
+	items := make([]list.Item, 0, len(m.routes))
+	if m.order != byTag {
+		for _, route := range m.routes {
+			items = append(items, route)
+		}
+		return items
+	}
+	for start := 0; start < len(m.routes); {
+		header := models.TagItem{Tag: m.routes[start].Tag()}
+		header.Collapsed = m.collapsed[header.Tag]
+		end := start
+		for ; end < len(m.routes) && m.routes[end].Tag() == header.Tag; end++ {
+			header.Routes++
+			if m.routes[end].IsRemoved {
+				header.Removed++
+			}
+		}
+		items = append(items, header)
+		if !header.Collapsed {
+			for _, route := range m.routes[start:end] {
+				items = append(items, route)
+			}
+		}
+		start = end
+	}
+	return items
+}
+
 // View renders either the list or the modal
 func (m ListItemModel) View() string {
 	if m.editing {
-		return docStyle.Render(m.editModal.View(m.list.SelectedItem().(models.RouteToolItem).Title()))
+		return docStyle.Render(m.editModal.View(m.routes[m.editIndex].Title()))
+	}
+	if m.renaming {
+		return docStyle.Render(m.nameModal.View(m.routes[m.editIndex].Title()))
+	}
+	if m.previewing {
+		return docStyle.Render(m.preview.View())
+	}
+	if m.trying {
+		return docStyle.Render(m.try.View(m.routes[m.editIndex].Title()))
+	}
+	if m.parameters {
+		return docStyle.Render(m.paramPage.View())
 	}
 	return docStyle.Render(m.list.View())
 }
 
+// WithCall returns the model sending the requests of the try screen with
+// call
+func (m ListItemModel) WithCall(call CallFunc) ListItemModel {
+	m.call = call
+	return m
+}
+
+// WithAdjustmentsFile returns the model saving edits into file, the
+// adjustments file it was loaded from
+func (m ListItemModel) WithAdjustmentsFile(file string) ListItemModel {
+	m.file = file
+	return m
+}
+
+// WithSuggestions returns the model with edits proposed to trim the routes
+// to target tools, for the user to review. The title sums them up.
+func (m ListItemModel) WithSuggestions(target int) ListItemModel {
+	m.suggest = target
+	if target <= 0 {
+		return m
+	}
+	m.list.Title += " " + statusMessageStyle(suggestEdits(m.routes, target))
+	m.refresh()
+	return m
+}
+
+// WithGeneratedDescriptions returns the model proposing the descriptions
+// an LLM wrote, by route, for the routes without an edited description.
+// They are flagged for review until edited.
+func (m ListItemModel) WithGeneratedDescriptions(descriptions map[*parser.RouteTool]string) ListItemModel {
+	for i, route := range m.routes {
+		if description, ok := descriptions[route.Tool]; ok && route.NewDescription == "" {
+			m.routes[i].NewDescription = description
+			m.routes[i].Generated = true
+		}
+	}
+	m.refresh()
+	return m
+}
+
+// withRoutes returns a model for routeTools keeping the options of m
+func (m ListItemModel) withRoutes(routeTools []*parser.RouteTool, adjuster *parser.Adjuster) ListItemModel {
+	return NewListItemModel(routeTools, adjuster).WithCall(m.call).WithAdjustmentsFile(m.file).WithSuggestions(m.suggest)
+}
+
 // NewModel creates a TUI model for a list of RouteTool
 func NewListItemModel(routeTools []*parser.RouteTool, adjuster *parser.Adjuster) ListItemModel {
 	listKeys := newListKeyMap()
 
-	items := make([]list.Item, len(routeTools))
+	routes := make([]models.RouteToolItem, len(routeTools))
 	for i, rt := range routeTools {
-		items[i] = models.RouteToolItem{
+		routes[i] = models.RouteToolItem{
 			Tool:           rt,
 			NewDescription: adjuster.GetDescription(rt.RouteConfig.Path, rt.RouteConfig.Method, ""),
+			NewName:        adjuster.GetToolName(rt.RouteConfig.Path, rt.RouteConfig.Method),
 			IsRemoved:      !adjuster.ExistsInMCP(rt.RouteConfig.Path, rt.RouteConfig.Method),
 		}
+		routes[i].Parameters = parameterEdits(rt, adjuster)
+		routes[i].Saved = routes[i].Edits()
+		routes[i].SavedParameters = routes[i].Parameters
 	}
+	orderRoutes(routes, byTag)
+
 	delegateKeyMap := newDelegateKeyMap()
 	delegate := newItemDelegate(delegateKeyMap)
 
-	l := list.New(items, delegate, 0, 0)
+	m := ListItemModel{
+		keys:         listKeys,
+		delegateKeys: delegateKeyMap,
+		routes:       routes,
+		collapsed:    make(map[string]bool),
+		editing:      false,
+		editIndex:    -1,
+		editModal:    DescriptionEditorModal{},
+		stale:        staleAdjustments(routeTools, adjuster),
+	}
+	l := list.New(m.items(), delegate, 0, 0)
 
 	l.Title = titleStyle.Render("MCP API Routes editor")
+	if len(m.stale) > 0 {
+		l.Title += " " + statusMessageStyle(fmt.Sprintf("%d adjustments match no route, D shows them", len(m.stale)))
+	}
 	l.SetShowFilter(true)
+	l.Filter = filterRoutes
+	l.FilterInput.Placeholder = "text, :method DELETE, :path ^/admin/"
 
 	l.AdditionalShortHelpKeys = func() []key.Binding {
 		return []key.Binding{
 			listKeys.editDescription,
+			listKeys.rename,
+			listKeys.parameters,
+			listKeys.preview,
+			listKeys.try,
+			listKeys.diff,
+			listKeys.collapse,
+			listKeys.sort,
+			listKeys.mark,
+			listKeys.markAll,
+			listKeys.save,
 			listKeys.finish,
 			listKeys.quit,
 		}
 	}
-	return ListItemModel{list: l, keys: listKeys, editing: false, editIndex: -1, editModal: DescriptionEditorModal{}}
+	m.list = l
+	return m
+}
+
+// parameterEdits returns the parameter edits the adjustments file makes to
+// a route
+func parameterEdits(rt *parser.RouteTool, adjuster *parser.Adjuster) map[string]models.ParameterEdit {
+	path, method := rt.RouteConfig.Path, rt.RouteConfig.Method
+	edits := make(map[string]models.ParameterEdit)
+	update := func(name string, change func(*models.ParameterEdit)) {
+		edit := edits[name]
+		change(&edit)
+		edits[name] = edit
+	}
+	_, hidden := adjuster.GetParameters(path, method)
+	for _, name := range hidden {
+		update(name, func(edit *models.ParameterEdit) { edit.Hidden = true })
+	}
+	for name, description := range adjuster.GetParameterDescriptions(path, method) {
+		update(name, func(edit *models.ParameterEdit) { edit.Description = description })
+	}
+	for name, value := range adjuster.GetArgumentConstants(path, method) {
+		update(name, func(edit *models.ParameterEdit) { edit.Constant = value })
+	}
+	if len(edits) == 0 {
+		return nil
+	}
+	return edits
 }
 
 // GetFilteredRoutes returns the currently visible (filtered) RouteTools
 func (m ListItemModel) GetFilteredRoutes() []*parser.RouteTool {
-	visible := m.list.VisibleItems()
-	result := make([]*parser.RouteTool, len(visible))
-	for i, item := range visible {
-		result[i] = item.(models.RouteToolItem).Tool
+	var result []*parser.RouteTool
+	for _, item := range m.list.VisibleItems() {
+		if route, ok := item.(models.RouteToolItem); ok {
+			result = append(result, route.Tool)
+		}
 	}
 	return result
 }
 
-// GetRoutesUpdates returns the currently visible RouteToolItems with their updates
+// GetRoutesUpdates returns every RouteToolItem with its updates, including
+// those of collapsed tags
 func (m ListItemModel) GetRoutesUpdates() []*models.RouteToolItem {
-	visible := m.list.VisibleItems()
-	result := make([]*models.RouteToolItem, len(visible))
-	for i, item := range visible {
+	result := make([]*models.RouteToolItem, len(m.routes))
+	for i := range m.routes {
 		// Create a pointer to a new RouteToolItem
-		routeToolItem := item.(models.RouteToolItem)
+		routeToolItem := m.routes[i]
 		result[i] = &routeToolItem
 	}
 	return result
@@ -0,0 +1,399 @@
+package tui
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/brizzai/auto-mcp/internal/parser"
+	"github.com/brizzai/auto-mcp/internal/requester"
+	"github.com/brizzai/auto-mcp/internal/tui/models"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestListModel creates a list model for routes given as method, path and tag
+func newTestListModel(t *testing.T, routes ...[3]string) ListItemModel {
+	t.Helper()
+	routeTools := make([]*parser.RouteTool, 0, len(routes))
+	for _, r := range routes {
+		routeConfig := &requester.RouteConfig{Method: r[0], Path: r[1]}
+		if r[2] != "" {
+			routeConfig.Tags = []string{r[2]}
+		}
+		routeTools = append(routeTools, &parser.RouteTool{RouteConfig: routeConfig, Tool: mcp.Tool{Name: r[0] + r[1]}})
+	}
+	m := NewListItemModel(routeTools, parser.NewAdjuster())
+	return update(t, m, tea.WindowSizeMsg{Width: 120, Height: 80})
+}
+
+// update sends msg to the list model
+func update(t *testing.T, m ListItemModel, msg tea.Msg) ListItemModel {
+	t.Helper()
+	model, _ := m.Update(msg)
+	updated, ok := model.(ListItemModel)
+	require.True(t, ok)
+	return updated
+}
+
+func keyPress(key string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+}
+
+// titles lists the titles of the list items
+func titles(m ListItemModel) []string {
+	var result []string
+	for _, item := range m.list.Items() {
+		switch item := item.(type) {
+		case models.TagItem:
+			result = append(result, item.Title())
+		case models.RouteToolItem:
+			result = append(result, "  "+item.Tool.RouteConfig.Method+" "+item.Tool.RouteConfig.Path)
+		}
+	}
+	return result
+}
+
+func TestListItemModel_GroupsByTag(t *testing.T) {
+	m := newTestListModel(t,
+		[3]string{"POST", "/users", "users"},
+		[3]string{"GET", "/pets", "pets"},
+		[3]string{"GET", "/health", ""},
+		[3]string{"DELETE", "/pets/{id}", "pets"},
+	)
+
+	assert.Equal(t, []string{
+		"▾ pets",
+		"  GET /pets",
+		"  DELETE /pets/{id}",
+		"▾ untagged",
+		"  GET /health",
+		"▾ users",
+		"  POST /users",
+	}, titles(m))
+
+	// Removing on a header removes the whole tag, a second time restores it
+	m = update(t, m, keyPress("x"))
+	removed := map[string]bool{}
+	for _, route := range m.GetRoutesUpdates() {
+		removed[route.Tool.RouteConfig.Method+" "+route.Tool.RouteConfig.Path] = route.IsRemoved
+	}
+	assert.Equal(t, map[string]bool{"GET /pets": true, "DELETE /pets/{id}": true, "GET /health": false, "POST /users": false}, removed)
+	assert.Equal(t, "2 routes, 0 kept", m.list.Items()[0].(models.TagItem).Description())
+
+	m = update(t, m, keyPress("x"))
+	assert.Equal(t, "2 routes, 2 kept", m.list.Items()[0].(models.TagItem).Description())
+
+	// Collapsing hides the routes of the tag but keeps them in the export
+	m = update(t, m, keyPress("c"))
+	assert.Equal(t, []string{"▸ pets", "▾ untagged", "  GET /health", "▾ users", "  POST /users"}, titles(m))
+	assert.Len(t, m.GetRoutesUpdates(), 4)
+
+	m = update(t, m, keyPress("c"))
+	assert.Len(t, titles(m), 7)
+}
+
+func TestListItemModel_Sort(t *testing.T) {
+	m := newTestListModel(t,
+		[3]string{"POST", "/users", "users"},
+		[3]string{"GET", "/pets", "pets"},
+		[3]string{"GET", "/health", ""},
+		[3]string{"DELETE", "/pets/{id}", "pets"},
+	)
+	m.list.Select(4) // GET /health
+
+	m = update(t, m, keyPress("S"))
+	assert.Equal(t, []string{"  GET /health", "  GET /pets", "  DELETE /pets/{id}", "  POST /users"}, titles(m))
+	_, selected, ok := m.selectedRoute()
+	require.True(t, ok)
+	assert.Equal(t, "/health", selected.Tool.RouteConfig.Path)
+
+	// Tags are only collapsed when grouped
+	m = update(t, m, keyPress("c"))
+	assert.Len(t, titles(m), 4)
+
+	m = update(t, m, keyPress("S"))
+	assert.Equal(t, []string{"  DELETE /pets/{id}", "  GET /health", "  GET /pets", "  POST /users"}, titles(m))
+
+	m = update(t, m, keyPress("S"))
+	assert.Equal(t, []string{"▾ pets", "  GET /pets", "  DELETE /pets/{id}", "▾ untagged", "  GET /health", "▾ users", "  POST /users"}, titles(m))
+	_, selected, _ = m.selectedRoute()
+	assert.Equal(t, "/health", selected.Tool.RouteConfig.Path)
+}
+
+func TestRouteToolItem_Badges(t *testing.T) {
+	route := models.RouteToolItem{Tool: &parser.RouteTool{
+		RouteConfig: &requester.RouteConfig{Method: "GET", Path: "/pets", Description: "List pets"},
+		Deprecated:  true,
+		Auth:        []string{"api_key", "oauth"},
+	}}
+	assert.Equal(t, "[deprecated] [auth: api_key, oauth] List pets", route.Description())
+	assert.Equal(t, "List pets", route.RouteDescription())
+
+	route = route.ToggleRemoved()
+	assert.Equal(t, "[Removed] [deprecated] [auth: api_key, oauth]", route.Description())
+
+	route.Tool.Deprecated, route.Tool.Auth = false, nil
+	route = route.ToggleRemoved()
+	assert.Equal(t, "List pets", route.Description())
+}
+
+func TestListItemModel_WithGeneratedDescriptions(t *testing.T) {
+	described := &parser.RouteTool{RouteConfig: &requester.RouteConfig{Method: "GET", Path: "/pets", Description: "List"}}
+	adjusted := &parser.RouteTool{RouteConfig: &requester.RouteConfig{Method: "GET", Path: "/owners", Description: "Owners"}}
+	file := filepath.Join(t.TempDir(), "adjustments.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(`descriptions:
+  - path: /owners
+    updates:
+      - method: GET
+        new_description: Lists the owners
+`), 0o600))
+	adjuster := parser.NewAdjuster()
+	require.NoError(t, adjuster.Load(file))
+	m := NewListItemModel([]*parser.RouteTool{described, adjusted}, adjuster).WithGeneratedDescriptions(map[*parser.RouteTool]string{
+		described: "Lists every pet of the store.",
+		adjusted:  "Lists every owner.",
+	})
+
+	routes := map[string]models.RouteToolItem{}
+	for _, route := range m.routes {
+		routes[route.Tool.RouteConfig.Path] = route
+	}
+	assert.Equal(t, "[generated] Lists every pet of the store.", routes["/pets"].Description())
+	assert.True(t, routes["/pets"].Edited())
+	// Adjusted descriptions are kept
+	assert.Equal(t, "Lists the owners", routes["/owners"].Description())
+
+	// Editing the description accepts it
+	assert.False(t, routes["/pets"].UpdatedDescription("Lists every pet of the store.").Generated)
+}
+
+func TestListItemModel_BulkSelection(t *testing.T) {
+	m := newTestListModel(t,
+		[3]string{"GET", "/admin/users", "admin"},
+		[3]string{"DELETE", "/admin/users/{id}", "admin"},
+		[3]string{"GET", "/pets", "pets"},
+		[3]string{"POST", "/pets", "pets"},
+	)
+	removed := func(m ListItemModel) []string {
+		var result []string
+		for _, route := range m.GetRoutesUpdates() {
+			if route.IsRemoved {
+				result = append(result, route.Tool.RouteConfig.Method+" "+route.Tool.RouteConfig.Path)
+			}
+		}
+		return result
+	}
+
+	// Mark every route matching the filter, then remove them at once
+	m.list.SetFilterText("users")
+	m = update(t, m, keyPress("a"))
+	assert.Equal(t, 2, m.markedCount())
+	m = update(t, m, keyPress("x"))
+	assert.Equal(t, []string{"GET /admin/users", "DELETE /admin/users/{id}"}, removed(m))
+	assert.Zero(t, m.markedCount())
+	m.list.ResetFilter()
+	_ = m.refresh()
+
+	// Marks made one by one restore the routes when all are removed
+	m.list.Select(1)
+	m = update(t, m, tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+	m.list.Select(2)
+	m = update(t, m, tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+	assert.Equal(t, 2, m.markedCount())
+	assert.True(t, m.Busy())
+	m = update(t, m, keyPress("x"))
+	assert.Empty(t, removed(m))
+
+	// Space on a header marks its tag, esc clears the marks
+	m.list.Select(3)
+	m = update(t, m, tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+	assert.Equal(t, 2, m.markedCount())
+	m = update(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	assert.Zero(t, m.markedCount())
+}
+
+func TestListItemModel_PreviewTool(t *testing.T) {
+	m := newTestListModel(t, [3]string{"GET", "/pets", "pets"})
+	m.routes[0].Tool.RouteConfig.Description = "List pets"
+
+	// Renaming shows in the preview together with the generated description
+	m.list.Select(1)
+	m = update(t, m, keyPress("n"))
+	m = update(t, m, keyPress("list_pets"))
+	m = update(t, m, tea.KeyMsg{Type: tea.KeyCtrlS})
+	m = update(t, m, keyPress("p"))
+	require.True(t, m.previewing)
+	assert.True(t, m.Busy())
+	assert.Contains(t, m.View(), `"name": "list_pets"`)
+
+	// Keys scroll the preview instead of acting on the list
+	m = update(t, m, keyPress("x"))
+	assert.False(t, m.routes[0].IsRemoved)
+
+	m = update(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	assert.False(t, m.previewing)
+
+	tool := m.routes[0].UpdatedDescription("Every pet").PreviewTool()
+	assert.Equal(t, "list_pets", tool.Name)
+	assert.Equal(t, "GET /pets \n Every pet", tool.Description)
+}
+
+func TestListItemModel_TryRoute(t *testing.T) {
+	m := newTestListModel(t, [3]string{"GET", "/pets/{id}", "pets"})
+	m.routes[0].Tool.Tool = mcp.NewTool("get_pet",
+		mcp.WithNumber("id", mcp.Required()),
+		mcp.WithBoolean("verbose"),
+	)
+	var sent map[string]any
+	m = m.WithCall(func(_ context.Context, route *requester.RouteConfig, args map[string]any) (*requester.Response, error) {
+		sent = args
+		return &requester.Response{
+			StatusCode: 200,
+			Headers:    http.Header{"Content-Type": []string{"application/json"}},
+			Body:       []byte(`{"id":7}`),
+		}, nil
+	})
+
+	m.list.Select(1)
+	m = update(t, m, keyPress("t"))
+	require.True(t, m.trying)
+	assert.True(t, m.Busy())
+
+	// Required arguments must be entered before sending
+	m = update(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Contains(t, m.View(), "id is required")
+
+	m = update(t, m, keyPress("7"))
+	m = update(t, m, tea.KeyMsg{Type: tea.KeyTab})
+	m = update(t, m, keyPress("yes"))
+	m = update(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Contains(t, m.View(), `verbose: "yes" is not a boolean`)
+
+	m.try.fields[1].input.SetValue("true")
+	model, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	require.NotNil(t, cmd)
+	m = update(t, model.(ListItemModel), cmd())
+	assert.Equal(t, map[string]any{"id": json.Number("7"), "verbose": true}, sent)
+	assert.Contains(t, m.View(), "200 OK")
+	assert.Contains(t, m.View(), `"id": 7`)
+
+	m = update(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	assert.False(t, m.trying)
+}
+
+func TestListItemModel_EditsDiff(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "adjustments.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(`
+routes:
+  - path: /pets
+    methods: [GET]
+  - path: /pets/{id}
+    methods: [GET]
+descriptions:
+  - path: /pets
+    updates:
+      - method: GET
+        new_name: list_pets
+  - path: /pets/{id}
+    updates:
+      - method: GET
+        new_description: One pet
+  - path: /owners
+    updates:
+      - method: GET
+        new_description: Owners
+`), 0o600))
+	adjuster := parser.NewAdjuster()
+	require.NoError(t, adjuster.Load(file))
+
+	routeTools := []*parser.RouteTool{
+		{RouteConfig: &requester.RouteConfig{Method: "GET", Path: "/pets"}, Tool: mcp.Tool{Name: "get_pets"}},
+		{RouteConfig: &requester.RouteConfig{Method: "GET", Path: "/pets/{id}"}, Tool: mcp.Tool{Name: "get_pets_id"}},
+		{RouteConfig: &requester.RouteConfig{Method: "DELETE", Path: "/pets/{id}"}, Tool: mcp.Tool{Name: "delete_pets_id"}},
+	}
+	m := update(t, NewListItemModel(routeTools, adjuster), tea.WindowSizeMsg{Width: 120, Height: 80})
+	assert.Contains(t, m.list.Title, "1 adjustments match no route")
+
+	// Restoring the removed route marks it as edited
+	m.list.Select(2)
+	m = update(t, m, keyPress("x"))
+	assert.Equal(t, "* DELETE /pets/{id} (delete_pets_id)", m.routes[1].Title())
+
+	m = update(t, m, keyPress("d"))
+	require.True(t, m.previewing)
+	assert.Equal(t, `Unsaved edits (1):
+  ~ DELETE /pets/{id}
+      removed -> kept
+
+Kept from the adjustments file (2):
+  = GET /pets: renamed to list_pets
+  = GET /pets/{id}: description edited
+
+Adjustments matching no route of the spec, kept by ctrl+s but not exported (1):
+  ! descriptions: GET /owners
+`, editsDiff(m.routes, m.stale))
+
+	m = update(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	assert.False(t, m.previewing)
+}
+
+func TestListItemModel_EditParameters(t *testing.T) {
+	m := newTestListModel(t, [3]string{"POST", "/pets", "pets"})
+	m.routes[0].Tool.Tool = mcp.NewTool("post_pets",
+		mcp.WithString("X-Debug"),
+		mcp.WithObject("body", mcp.Properties(map[string]any{
+			"name":   map[string]any{"type": "string", "description": "Name"},
+			"status": map[string]any{"type": "string"},
+		})),
+	)
+
+	m.list.Select(1)
+	m = update(t, m, keyPress("o"))
+	require.True(t, m.parameters)
+	assert.Equal(t, []string{"X-Debug", "body", "body.name", "body.status"}, m.paramPage.names)
+
+	// Hide X-Debug, describe body.name and pin body.status
+	m = update(t, m, keyPress("h"))
+	m = update(t, m, keyPress("j"))
+	m = update(t, m, keyPress("j"))
+	m = update(t, m, keyPress("e"))
+	m.paramPage.field.SetValue("The pet's name")
+	m = update(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	m = update(t, m, keyPress("j"))
+	m = update(t, m, keyPress("v"))
+	m = update(t, m, keyPress("sold"))
+	m = update(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+
+	// Parameters with a constant are not part of the tool, so have no description
+	m = update(t, m, keyPress("e"))
+	assert.Contains(t, m.View(), "body.status is not part of the tool")
+
+	// The first esc closes an open input, the next one the page
+	m = update(t, m, keyPress("k"))
+	m = update(t, m, keyPress("e"))
+	m = update(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	assert.True(t, m.parameters)
+	m = update(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	assert.False(t, m.parameters)
+
+	route := m.routes[0]
+	assert.Equal(t, map[string]models.ParameterEdit{
+		"X-Debug":     {Hidden: true},
+		"body.name":   {Description: "The pet's name"},
+		"body.status": {Constant: "sold"},
+	}, route.Parameters)
+	assert.True(t, route.Edited())
+
+	tool := route.PreviewTool()
+	assert.NotContains(t, tool.InputSchema.Properties, "X-Debug")
+	body := tool.InputSchema.Properties["body"].(map[string]any)["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{"name": map[string]any{"type": "string", "description": "The pet's name"}}, body)
+	// The parsed tool is left alone
+	assert.Contains(t, m.routes[0].Tool.Tool.InputSchema.Properties, "X-Debug")
+}
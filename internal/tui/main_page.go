@@ -93,14 +93,14 @@ func (m MainPageModel) View() string {
 
 	description := descStyle.Render(
 		"This application allows you to manage your MCP API routes.\n" +
-			"You can view, filter, edit descriptions, and mark routes for removal.\n\n" +
+			"You can browse routes by tag, filter them, edit descriptions, rename tools and mark routes for removal.\n\n" +
 			"The application currently manages " + pluralize(len(m.routeTools), "route") + ".",
 	)
 
 	// Route list preview style
 	routePreviewStyle := lipgloss.NewStyle().
 		BorderStyle(lipgloss.NormalBorder()).
-		BorderForeground(lipgloss.Color("#f56a96")).
+		BorderForeground(theme.Accent).
 		Padding(1, 1).
 		Width(m.width - 10).
 		Align(lipgloss.Left)
@@ -128,7 +128,7 @@ func (m MainPageModel) View() string {
 	routePreview := routePreviewStyle.Render(routePreviewContent.String())
 
 	instructionStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#f56a96")).
+		Foreground(theme.Accent).
 		Padding(1, 0).
 		Width(m.width - 4).
 		Align(lipgloss.Center)
@@ -136,7 +136,7 @@ func (m MainPageModel) View() string {
 	instruction := instructionStyle.Render("Press ENTER to open the routes editor")
 
 	helpStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.AdaptiveColor{Light: "#626262", Dark: "#A49FA5"}).
+		Foreground(theme.Muted).
 		Width(m.width - 4).
 		Align(lipgloss.Center)
 
@@ -2,9 +2,20 @@ package models
 
 import (
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
 
 	"github.com/brizzai/auto-mcp/internal/parser"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// RemovedStyle marks routes removed from the MCP list and BadgeStyle the
+// badges of routes; the TUI's theme sets them
+var (
+	RemovedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000"))
+	BadgeStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#f56a96"))
 )
 
 // RouteToolItem wraps a RouteTool for display in the list
@@ -12,27 +23,116 @@ import (
 type RouteToolItem struct {
 	Tool           *parser.RouteTool
 	NewDescription string
-	IsRemoved      bool
+	// NewName replaces the generated tool name when set
+	NewName   string
+	IsRemoved bool
+	// Marked routes are changed together by bulk actions
+	Marked bool
+	// Generated is set while NewDescription is an LLM's proposal, until the
+	// description is edited
+	Generated bool
+	// Parameters holds the edits of parameters by real name, dotted for
+	// body fields
+	Parameters map[string]ParameterEdit
+	// Saved and SavedParameters are the edits the route had in the
+	// adjustments file
+	Saved           RouteEdits
+	SavedParameters map[string]ParameterEdit
+}
+
+// ParameterEdit is the edit of one parameter. A constant is always sent and
+// hides the parameter like Hidden does.
+type ParameterEdit struct {
+	Description string
+	Hidden      bool
+	Constant    string
+}
+
+// RouteEdits are the edits of a route an adjustments file keeps
+type RouteEdits struct {
+	Name        string
+	Description string
+	Removed     bool
 }
 
 func (i RouteToolItem) Title() string {
-	return fmt.Sprintf("%s %s ", i.Tool.RouteConfig.Method, i.Tool.RouteConfig.Path)
+	title := fmt.Sprintf("%s %s (%s)", i.Tool.RouteConfig.Method, i.Tool.RouteConfig.Path, i.ToolName())
+	if i.Edited() {
+		title = "* " + title
+	}
+	if i.Marked {
+		return "[x] " + title
+	}
+	return title
+}
+
+// ToolName returns the name the tool is registered under
+func (i RouteToolItem) ToolName() string {
+	if i.NewName != "" {
+		return i.NewName
+	}
+	return i.Tool.Tool.Name
+}
+
+// Edits returns the current edits of the route
+func (i RouteToolItem) Edits() RouteEdits {
+	return RouteEdits{Name: i.NewName, Description: i.NewDescription, Removed: i.IsRemoved}
+}
+
+// Edited reports whether the route was edited since the adjustments file
+// was loaded or saved
+func (i RouteToolItem) Edited() bool {
+	return i.Edits() != i.Saved || !maps.Equal(i.Parameters, i.SavedParameters)
 }
 
 func (i RouteToolItem) Description() string {
 	if i.IsRemoved {
-		return lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FF0000")).
-			Render("[Removed]")
+		return strings.Join(append([]string{RemovedStyle.Render("[Removed]")}, i.Badges()...), " ")
 	}
+	return strings.Join(append(i.Badges(), i.RouteDescription()), " ")
+}
+
+// RouteDescription returns the description of the route, edited or from
+// the spec
+func (i RouteToolItem) RouteDescription() string {
 	if i.NewDescription != "" {
 		return i.NewDescription
 	}
 	return i.Tool.RouteConfig.Description
 }
 
+// Badges flags routes the spec marks deprecated, with a generated description
+// to review or that require credentials, naming their security schemes
+func (i RouteToolItem) Badges() []string {
+	var badges []string
+	if i.Tool.Deprecated {
+		badges = append(badges, BadgeStyle.Render("[deprecated]"))
+	}
+	if i.Generated {
+		badges = append(badges, BadgeStyle.Render("[generated]"))
+	}
+	if len(i.Tool.Auth) > 0 {
+		badges = append(badges, BadgeStyle.Render("[auth: "+strings.Join(i.Tool.Auth, ", ")+"]"))
+	}
+	return badges
+}
+
 func (i RouteToolItem) UpdatedDescription(newDescription string) RouteToolItem {
 	i.NewDescription = newDescription
+	i.Generated = false
+	return i
+}
+
+// Tag returns the tag the route is grouped under, its first one
+func (i RouteToolItem) Tag() string {
+	if len(i.Tool.RouteConfig.Tags) == 0 {
+		return Untagged
+	}
+	return i.Tool.RouteConfig.Tags[0]
+}
+
+func (i RouteToolItem) UpdatedName(newName string) RouteToolItem {
+	i.NewName = newName
 	return i
 }
 
@@ -41,6 +141,62 @@ func (i RouteToolItem) ToggleRemoved() RouteToolItem {
 	return i
 }
 
+func (i RouteToolItem) ToggleMarked() RouteToolItem {
+	i.Marked = !i.Marked
+	return i
+}
+
 func (i RouteToolItem) FilterValue() string {
-	return i.Tool.RouteConfig.Path + " " + i.Tool.RouteConfig.Description
+	// The list filter reads the method and path from the start
+	return i.Tool.RouteConfig.Method + " " + i.Tool.RouteConfig.Path + " " + i.ToolName() + " " + i.Tool.RouteConfig.Description
+}
+
+// UpdatedParameter returns the item with the edit of parameter name
+// replaced; an empty edit removes it
+func (i RouteToolItem) UpdatedParameter(name string, edit ParameterEdit) RouteToolItem {
+	parameters := maps.Clone(i.Parameters)
+	if parameters == nil {
+		parameters = make(map[string]ParameterEdit)
+	}
+	if edit == (ParameterEdit{}) {
+		delete(parameters, name)
+	} else {
+		parameters[name] = edit
+	}
+	i.Parameters = parameters
+	return i
+}
+
+// ParameterNames lists the parameters of the route's tool, followed by the
+// fields of object parameters such as "body.name"
+func (i RouteToolItem) ParameterNames() []string {
+	properties := i.Tool.Tool.InputSchema.Properties
+	names := slices.Sorted(maps.Keys(properties))
+	result := make([]string, 0, len(names))
+	for _, name := range names {
+		result = append(result, name)
+		schema, _ := properties[name].(map[string]any)
+		if fields, ok := schema["properties"].(map[string]any); ok {
+			for _, field := range slices.Sorted(maps.Keys(fields)) {
+				result = append(result, name+"."+field)
+			}
+		}
+	}
+	return result
+}
+
+// PreviewTool returns the MCP tool the route generates with its edits
+func (i RouteToolItem) PreviewTool() mcp.Tool {
+	edits := parser.ToolEdits{Name: i.NewName, Description: i.NewDescription}
+	for name, edit := range i.Parameters {
+		if edit.Hidden || edit.Constant != "" {
+			edits.Hidden = append(edits.Hidden, name)
+		} else if edit.Description != "" {
+			if edits.ArgumentDescriptions == nil {
+				edits.ArgumentDescriptions = make(map[string]string)
+			}
+			edits.ArgumentDescriptions[name] = edit.Description
+		}
+	}
+	return parser.PreviewTool(i.Tool, edits)
 }
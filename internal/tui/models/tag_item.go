@@ -0,0 +1,32 @@
+package models
+
+import "fmt"
+
+// Untagged groups the routes whose operation has no tag
+const Untagged = "untagged"
+
+// TagItem heads the routes of one OpenAPI tag in the list
+// Implements list.Item
+type TagItem struct {
+	Tag       string
+	Routes    int
+	Removed   int
+	Collapsed bool
+}
+
+func (i TagItem) Title() string {
+	marker := "▾"
+	if i.Collapsed {
+		marker = "▸"
+	}
+	return fmt.Sprintf("%s %s", marker, i.Tag)
+}
+
+func (i TagItem) Description() string {
+	return fmt.Sprintf("%d routes, %d kept", i.Routes, i.Routes-i.Removed)
+}
+
+// FilterValue never starts with a method, so method and path filters skip headers
+func (i TagItem) FilterValue() string {
+	return "#" + i.Tag
+}
@@ -0,0 +1,63 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// NameEditorModal provides a modal text input for renaming a tool.
+type NameEditorModal struct {
+	textInput textinput.Model
+	err       error
+}
+
+// NewNameModal creates a NameEditorModal showing the current tool name.
+// generated is shown as the placeholder, the name an empty input restores.
+func NewNameModal(current, generated string) NameEditorModal {
+	ti := textinput.New()
+	ti.Placeholder = generated
+	ti.CharLimit = 64
+	ti.Width = 64
+	ti.SetValue(current)
+	ti.Focus()
+
+	return NameEditorModal{textInput: ti}
+}
+
+// Update handles messages for the modal.
+func (m NameEditorModal) Update(msg tea.Msg) (NameEditorModal, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok && msg.Type == tea.KeyCtrlC {
+		return m, tea.Quit
+	}
+
+	var cmd tea.Cmd
+	m.textInput, cmd = m.textInput.Update(msg)
+	return m, cmd
+}
+
+// Name returns the current value of the input.
+func (m NameEditorModal) Name() string {
+	return m.textInput.Value()
+}
+
+// WithError returns the modal showing err below the input.
+func (m NameEditorModal) WithError(err error) NameEditorModal {
+	m.err = err
+	return m
+}
+
+// View renders the modal UI.
+func (m NameEditorModal) View(title string) string {
+	status := "(ctrl+s to save, esc to cancel, empty for the generated name)"
+	if m.err != nil {
+		status = statusMessageStyle(m.err.Error()) + "\n\n" + status
+	}
+	return fmt.Sprintf(
+		"%s\n\n%s\n\n%s",
+		editHeaderStyle.Render(title),
+		m.textInput.View(),
+		status,
+	) + "\n\n"
+}
@@ -0,0 +1,258 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/brizzai/auto-mcp/internal/tui/models"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// parameterKeyMap holds key bindings for the parameter page actions.
+type parameterKeyMap struct {
+	up              key.Binding
+	down            key.Binding
+	editDescription key.Binding
+	hide            key.Binding
+	constant        key.Binding
+}
+
+func newParameterKeyMap() parameterKeyMap {
+	return parameterKeyMap{
+		up:              key.NewBinding(key.WithKeys("up", "k")),
+		down:            key.NewBinding(key.WithKeys("down", "j")),
+		editDescription: key.NewBinding(key.WithKeys("E", "e")),
+		hide:            key.NewBinding(key.WithKeys("H", "h")),
+		constant:        key.NewBinding(key.WithKeys("V", "v")),
+	}
+}
+
+// Inputs of the parameter page
+const (
+	inputDescription = "description"
+	inputConstant    = "constant"
+)
+
+// parameterPageChrome is the height of the page's lines besides the
+// parameter rows
+const parameterPageChrome = 8
+
+// ParameterPage edits the descriptions, visibility and constant values of
+// the parameters of one route.
+type ParameterPage struct {
+	item   models.RouteToolItem
+	names  []string
+	keys   parameterKeyMap
+	cursor int
+	offset int    // Index of the first row shown
+	input  string // The value being edited, empty when no input is open
+	field  textinput.Model
+	err    error
+	height int
+}
+
+// NewParameterPage creates a ParameterPage for the parameters of item.
+func NewParameterPage(item models.RouteToolItem, width, height int) ParameterPage {
+	field := textinput.New()
+	field.Width = width - 20
+	return ParameterPage{
+		item:   item,
+		names:  item.ParameterNames(),
+		keys:   newParameterKeyMap(),
+		field:  field,
+		height: height,
+	}
+}
+
+// Item returns the route with the parameter edits made on the page.
+func (m ParameterPage) Item() models.RouteToolItem {
+	return m.item
+}
+
+// Editing reports whether an input is open, which esc closes first.
+func (m ParameterPage) Editing() bool {
+	return m.input != ""
+}
+
+// Update handles messages for the parameter page.
+func (m ParameterPage) Update(msg tea.Msg) (ParameterPage, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if msg.Type == tea.KeyCtrlC {
+			return m, tea.Quit
+		}
+		if m.input != "" {
+			return m.updateInput(msg)
+		}
+		if len(m.names) == 0 {
+			return m, nil
+		}
+		m.err = nil
+		name := m.names[m.cursor]
+		edit := m.item.Parameters[name]
+		switch {
+		case key.Matches(msg, m.keys.up):
+			m.cursor = max(m.cursor-1, 0)
+		case key.Matches(msg, m.keys.down):
+			m.cursor = min(m.cursor+1, len(m.names)-1)
+		case key.Matches(msg, m.keys.hide):
+			edit.Hidden = !edit.Hidden
+			if edit.Hidden {
+				// Hidden parameters are never sent, so they have no constant
+				edit.Constant = ""
+			}
+			m.item = m.item.UpdatedParameter(name, edit)
+		case key.Matches(msg, m.keys.editDescription):
+			if edit.Hidden || edit.Constant != "" {
+				m.err = fmt.Errorf("%s is not part of the tool", name)
+				return m, nil
+			}
+			description := edit.Description
+			if description == "" {
+				description = m.schemaDescription(name)
+			}
+			return m.openInput(inputDescription, description)
+		case key.Matches(msg, m.keys.constant):
+			return m.openInput(inputConstant, edit.Constant)
+		}
+		m.scroll()
+		return m, nil
+
+	case tea.WindowSizeMsg:
+		m.field.Width = msg.Width - 20
+		m.height = msg.Height
+		m.scroll()
+		return m, nil
+	}
+
+	if m.input == "" {
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.field, cmd = m.field.Update(msg)
+	return m, cmd
+}
+
+// openInput opens the input for the description or the constant of the
+// selected parameter
+func (m ParameterPage) openInput(input, value string) (ParameterPage, tea.Cmd) {
+	m.input = input
+	m.field.Prompt = input + ": "
+	m.field.SetValue(value)
+	m.field.CursorEnd()
+	return m, m.field.Focus()
+}
+
+// updateInput handles keys while an input is open
+func (m ParameterPage) updateInput(msg tea.KeyMsg) (ParameterPage, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.input = ""
+		m.field.Blur()
+		return m, nil
+	case tea.KeyEnter:
+		name := m.names[m.cursor]
+		edit := m.item.Parameters[name]
+		value := strings.TrimSpace(m.field.Value())
+		switch m.input {
+		case inputDescription:
+			// The description from the spec needs no edit
+			if value == m.schemaDescription(name) {
+				value = ""
+			}
+			edit.Description = value
+		case inputConstant:
+			edit.Constant = value
+			if value != "" {
+				edit.Hidden = false
+			}
+		}
+		m.item = m.item.UpdatedParameter(name, edit)
+		m.input = ""
+		m.field.Blur()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.field, cmd = m.field.Update(msg)
+	return m, cmd
+}
+
+// schemaDescription returns the description the spec gives a parameter
+func (m ParameterPage) schemaDescription(name string) string {
+	schema, _ := m.item.Tool.Tool.InputSchema.Properties[name].(map[string]any)
+	if parent, field, ok := strings.Cut(name, "."); ok {
+		object, _ := m.item.Tool.Tool.InputSchema.Properties[parent].(map[string]any)
+		fields, _ := object["properties"].(map[string]any)
+		schema, _ = fields[field].(map[string]any)
+	}
+	description, _ := schema["description"].(string)
+	return description
+}
+
+// rows returns how many parameter rows fit on the page
+func (m ParameterPage) rows() int {
+	return max(m.height-parameterPageChrome, 1)
+}
+
+// scroll keeps the selected row shown
+func (m *ParameterPage) scroll() {
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+m.rows() {
+		m.offset = m.cursor - m.rows() + 1
+	}
+}
+
+// View renders the parameter page UI.
+func (m ParameterPage) View() string {
+	var b strings.Builder
+	b.WriteString(editHeaderStyle.Render("Parameters of " + m.item.Title()))
+	b.WriteString("\n\n")
+	if len(m.names) == 0 {
+		b.WriteString("The route takes no parameters\n")
+	}
+
+	width := 0
+	for _, name := range m.names {
+		width = max(width, len(name))
+	}
+	end := min(m.offset+m.rows(), len(m.names))
+	for i := m.offset; i < end; i++ {
+		name := m.names[i]
+		cursor := "  "
+		if i == m.cursor {
+			cursor = "> "
+		}
+		fmt.Fprintf(&b, "%s%-*s  %s\n", cursor, width, name, m.state(name))
+	}
+	b.WriteString("\n")
+
+	switch {
+	case m.input != "":
+		b.WriteString(m.field.View())
+		b.WriteString("\n\n(enter to save, esc to cancel, empty to clear)")
+	case m.err != nil:
+		b.WriteString(statusMessageStyle(m.err.Error()))
+	default:
+		b.WriteString("(E to edit the description, H to hide, V to set a constant value, esc to go back)")
+	}
+	return b.String() + "\n"
+}
+
+// state describes the edit of a parameter, or its description from the spec
+func (m ParameterPage) state(name string) string {
+	edit := m.item.Parameters[name]
+	switch {
+	case edit.Hidden:
+		return statusMessageStyle("[hidden]")
+	case edit.Constant != "":
+		return statusMessageStyle(fmt.Sprintf("[constant %q]", edit.Constant))
+	case edit.Description != "":
+		return "* " + edit.Description
+	}
+	return m.schemaDescription(name)
+}
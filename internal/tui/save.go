@@ -0,0 +1,346 @@
+package tui
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	adjustments "github.com/brizzai/auto-mcp/internal/models"
+	"github.com/brizzai/auto-mcp/internal/tui/models"
+	"gopkg.in/yaml.v3"
+)
+
+// backupTimeFormat stamps the backups taken before saving in place
+const backupTimeFormat = "20060102-150405"
+
+// SaveAdjustments writes the edits of routes into the adjustments file at
+// filename, keeping every section and field the builder does not edit. The
+// file as it was is copied to a timestamped backup first; the backup's path
+// is returned, empty when there was no file.
+func SaveAdjustments(routes []*models.RouteToolItem, filename string) (string, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	merged, err := MergeAdjustments(data, routes)
+	if err != nil {
+		return "", err
+	}
+
+	var backup string
+	if data != nil {
+		backup = fmt.Sprintf("%s.%s.bak", filename, time.Now().Format(backupTimeFormat))
+		if err := os.WriteFile(backup, data, 0o644); err != nil {
+			return "", fmt.Errorf("failed to back up %s: %w", filename, err)
+		}
+	}
+	return backup, os.WriteFile(filename, merged, 0o644)
+}
+
+// RecoveryFile returns the file edits of the adjustments file at filename
+// are autosaved to
+func RecoveryFile(filename string) string {
+	if filename == "" {
+		return "adjustments.recovery.yaml"
+	}
+	return strings.TrimSuffix(filename, filepath.Ext(filename)) + ".recovery.yaml"
+}
+
+// MergeAdjustments returns the adjustments file data with the edits of
+// routes applied. Routes the builder doesn't know, such as entries for
+// operations missing from the spec, are left as they are, and so are the
+// comments and the order of the file's keys and entries.
+func MergeAdjustments(data []byte, routes []*models.RouteToolItem) ([]byte, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("failed to parse the adjustments file: %w", err)
+	}
+	var existing adjustments.MCPAdjustments
+	if document.Kind != 0 {
+		if err := document.Decode(&existing); err != nil {
+			return nil, fmt.Errorf("failed to parse the adjustments file: %w", err)
+		}
+	}
+
+	known := make(map[string]bool, len(routes))
+	for _, route := range routes {
+		known[route.Tool.RouteConfig.Method+" "+route.Tool.RouteConfig.Path] = true
+	}
+	built := BuildAdjustments(routes)
+	existing.Routes = mergeRoutes(existing.Routes, built.Routes, known)
+	existing.Descriptions = descriptionSection.merge(existing.Descriptions, built.Descriptions, known)
+	existing.Parameters = parameterSection.merge(existing.Parameters, built.Parameters, known)
+	existing.Defaults = defaultSection.merge(existing.Defaults, built.Defaults, known)
+
+	var merged yaml.Node
+	if err := merged.Encode(existing); err != nil {
+		return nil, err
+	}
+	if document.Kind == yaml.DocumentNode && len(document.Content) == 1 {
+		keepLayout(document.Content[0], &merged)
+		document.Content[0] = &merged
+		return yaml.Marshal(&document)
+	}
+	return yaml.Marshal(&merged)
+}
+
+// keepLayout carries the comments and styles of the file's nodes (original)
+// over to the nodes that replace them (merged), and orders merged's keys and
+// entries like the file's. New keys and entries follow the ones of the file.
+func keepLayout(original, merged *yaml.Node) {
+	if original.Kind != merged.Kind {
+		return
+	}
+	merged.HeadComment = original.HeadComment
+	merged.LineComment = original.LineComment
+	merged.FootComment = original.FootComment
+	if merged.Kind == yaml.ScalarNode {
+		if merged.Value == original.Value {
+			merged.Style = original.Style
+		}
+		return
+	}
+	merged.Style = original.Style
+
+	switch merged.Kind {
+	case yaml.MappingNode:
+		type pair struct{ key, value *yaml.Node }
+		originals := make(map[string]pair, len(original.Content)/2)
+		order := make(map[string]int, len(original.Content)/2)
+		for i := 0; i+1 < len(original.Content); i += 2 {
+			key := original.Content[i].Value
+			originals[key] = pair{original.Content[i], original.Content[i+1]}
+			order[key] = i
+		}
+		pairs := make([]pair, 0, len(merged.Content)/2)
+		for i := 0; i+1 < len(merged.Content); i += 2 {
+			p := pair{merged.Content[i], merged.Content[i+1]}
+			if o, ok := originals[p.key.Value]; ok {
+				keepLayout(o.key, p.key)
+				keepLayout(o.value, p.value)
+			}
+			pairs = append(pairs, p)
+		}
+		slices.SortStableFunc(pairs, func(a, b pair) int {
+			return compareOrder(order, a.key.Value, b.key.Value)
+		})
+		merged.Content = merged.Content[:0]
+		for _, p := range pairs {
+			merged.Content = append(merged.Content, p.key, p.value)
+		}
+	case yaml.SequenceNode:
+		originals := make(map[string]*yaml.Node, len(original.Content))
+		order := make(map[string]int, len(original.Content))
+		for i, item := range original.Content {
+			if id := entryID(item); id != "" {
+				originals[id] = item
+				order[id] = i
+			}
+		}
+		for _, item := range merged.Content {
+			if o, ok := originals[entryID(item)]; ok {
+				keepLayout(o, item)
+			}
+		}
+		slices.SortStableFunc(merged.Content, func(a, b *yaml.Node) int {
+			return compareOrder(order, entryID(a), entryID(b))
+		})
+	}
+}
+
+// compareOrder orders the keys found in order by their index, before the
+// ones that are not
+func compareOrder(order map[string]int, a, b string) int {
+	i, aOK := order[a]
+	j, bOK := order[b]
+	switch {
+	case aOK && bOK:
+		return i - j
+	case aOK:
+		return -1
+	case bOK:
+		return 1
+	}
+	return 0
+}
+
+// entryID identifies an entry of a sequence: a scalar by its value, and an
+// entry of a route section by its path and method
+func entryID(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return node.Value
+	case yaml.MappingNode:
+		var path, method string
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			switch node.Content[i].Value {
+			case "path":
+				path = node.Content[i+1].Value
+			case "method":
+				method = node.Content[i+1].Value
+			}
+		}
+		if path != "" || method != "" {
+			return method + " " + path
+		}
+	}
+	return ""
+}
+
+// mergeRoutes returns the routes the builder keeps followed by the routes of
+// the file it doesn't know
+func mergeRoutes(existing, built []adjustments.RouteSelection, known map[string]bool) []adjustments.RouteSelection {
+	merged := built
+	for _, route := range existing {
+		var methods []string
+		for _, method := range route.Methods {
+			if !known[method+" "+route.Path] {
+				methods = append(methods, method)
+			}
+		}
+		if len(methods) > 0 {
+			merged = append(merged, adjustments.RouteSelection{Path: route.Path, Methods: methods})
+		}
+	}
+	return merged
+}
+
+// routeSection accesses a section of the adjustments file holding updates
+// by method under a path
+type routeSection[E, U any] struct {
+	path    func(E) string
+	updates func(E) []U
+	method  func(U) string
+	entry   func(path string, updates []U) E
+	// edit returns existing with the fields the builder edits taken from
+	// edited, or cleared when edited is nil
+	edit func(existing U, edited *U) U
+	// empty reports whether an update changes nothing
+	empty func(U) bool
+}
+
+// merge applies the updates the builder made (edited) to the section of a
+// file (existing). Updates of known operations are edited, others kept as
+// they are; new updates are added under their path.
+func (s routeSection[E, U]) merge(existing, edited []E, known map[string]bool) []E {
+	pending := make(map[string]U)
+	for _, entry := range edited {
+		for _, update := range s.updates(entry) {
+			pending[s.method(update)+" "+s.path(entry)] = update
+		}
+	}
+
+	var merged []E
+	index := make(map[string]int) // Index of a path's entry in merged
+	for _, entry := range existing {
+		path := s.path(entry)
+		var updates []U
+		for _, update := range s.updates(entry) {
+			operation := s.method(update) + " " + path
+			if known[operation] {
+				var edit *U
+				if pendingUpdate, ok := pending[operation]; ok {
+					edit = &pendingUpdate
+					delete(pending, operation)
+				}
+				update = s.edit(update, edit)
+			}
+			if !s.empty(update) {
+				updates = append(updates, update)
+			}
+		}
+		if len(updates) > 0 {
+			index[path] = len(merged)
+			merged = append(merged, s.entry(path, updates))
+		}
+	}
+
+	for _, entry := range edited {
+		path := s.path(entry)
+		for _, update := range s.updates(entry) {
+			if _, ok := pending[s.method(update)+" "+path]; !ok {
+				continue
+			}
+			if i, ok := index[path]; ok {
+				merged[i] = s.entry(path, append(s.updates(merged[i]), update))
+			} else {
+				index[path] = len(merged)
+				merged = append(merged, s.entry(path, []U{update}))
+			}
+		}
+	}
+	return merged
+}
+
+// descriptionSection merges tool names and route descriptions, keeping
+// description templates
+var descriptionSection = routeSection[adjustments.RouteDescription, adjustments.RouteFieldUpdate]{
+	path:    func(e adjustments.RouteDescription) string { return e.Path },
+	updates: func(e adjustments.RouteDescription) []adjustments.RouteFieldUpdate { return e.Updates },
+	method:  func(u adjustments.RouteFieldUpdate) string { return u.Method },
+	entry: func(path string, updates []adjustments.RouteFieldUpdate) adjustments.RouteDescription {
+		return adjustments.RouteDescription{Path: path, Updates: updates}
+	},
+	edit: func(existing adjustments.RouteFieldUpdate, edited *adjustments.RouteFieldUpdate) adjustments.RouteFieldUpdate {
+		existing.NewDescription, existing.NewName = "", ""
+		if edited != nil {
+			existing.NewDescription, existing.NewName = edited.NewDescription, edited.NewName
+		}
+		return existing
+	},
+	empty: func(u adjustments.RouteFieldUpdate) bool {
+		return u.NewDescription == "" && u.NewName == "" && u.Template == ""
+	},
+}
+
+// parameterSection merges hidden parameters and parameter descriptions,
+// keeping renames
+var parameterSection = routeSection[adjustments.RouteParameters, adjustments.RouteParameterUpdate]{
+	path:    func(e adjustments.RouteParameters) string { return e.Path },
+	updates: func(e adjustments.RouteParameters) []adjustments.RouteParameterUpdate { return e.Updates },
+	method:  func(u adjustments.RouteParameterUpdate) string { return u.Method },
+	entry: func(path string, updates []adjustments.RouteParameterUpdate) adjustments.RouteParameters {
+		return adjustments.RouteParameters{Path: path, Updates: updates}
+	},
+	edit: func(existing adjustments.RouteParameterUpdate, edited *adjustments.RouteParameterUpdate) adjustments.RouteParameterUpdate {
+		existing.Hide, existing.Descriptions = nil, nil
+		if edited != nil {
+			existing.Hide, existing.Descriptions = edited.Hide, edited.Descriptions
+		}
+		// Parameters hidden in the builder are no longer renamed
+		if len(existing.Hide) > 0 && len(existing.Rename) > 0 {
+			existing.Rename = maps.Clone(existing.Rename)
+			for _, name := range existing.Hide {
+				delete(existing.Rename, name)
+			}
+		}
+		return existing
+	},
+	empty: func(u adjustments.RouteParameterUpdate) bool {
+		return len(u.Rename) == 0 && len(u.Hide) == 0 && len(u.Descriptions) == 0
+	},
+}
+
+// defaultSection merges constants, keeping default values
+var defaultSection = routeSection[adjustments.RouteDefaults, adjustments.RouteDefaultUpdate]{
+	path:    func(e adjustments.RouteDefaults) string { return e.Path },
+	updates: func(e adjustments.RouteDefaults) []adjustments.RouteDefaultUpdate { return e.Updates },
+	method:  func(u adjustments.RouteDefaultUpdate) string { return u.Method },
+	entry: func(path string, updates []adjustments.RouteDefaultUpdate) adjustments.RouteDefaults {
+		return adjustments.RouteDefaults{Path: path, Updates: updates}
+	},
+	edit: func(existing adjustments.RouteDefaultUpdate, edited *adjustments.RouteDefaultUpdate) adjustments.RouteDefaultUpdate {
+		existing.Constants = nil
+		if edited != nil {
+			existing.Constants = edited.Constants
+		}
+		return existing
+	},
+	empty: func(u adjustments.RouteDefaultUpdate) bool {
+		return len(u.Arguments) == 0 && len(u.Constants) == 0
+	},
+}
@@ -0,0 +1,189 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/brizzai/auto-mcp/internal/parser"
+	"github.com/brizzai/auto-mcp/internal/requester"
+	"github.com/brizzai/auto-mcp/internal/tui/models"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const savedAdjustments = `# Adjustments for the pet store
+descriptions:
+    - path: /pets
+      updates:
+        - method: GET
+          new_name: list_pets # Shorter than get_pets
+          template: '{{ .summary }}'
+    - path: /owners
+      updates:
+        - method: GET
+          new_description: Owners
+routes:
+    # Owners are not in the spec yet
+    - path: /owners
+      methods:
+        - GET
+    - path: /pets
+      methods:
+        - GET
+        - POST
+defaults:
+    - path: /pets
+      updates:
+        - method: POST
+          arguments:
+            body.status: available
+          constants:
+            body.owner: me
+parameters:
+    - path: /pets
+      updates:
+        - method: POST
+          rename:
+            body.nm: name
+            X-Trace: trace
+cache:
+    - path: /pets
+      updates:
+        - method: GET
+          max_age: 1m
+`
+
+func TestMergeAdjustments(t *testing.T) {
+	routes := createRouteItems([]*routeData{
+		{path: "/pets", method: "GET", description: "List pets", newDescription: "Every pet"},
+		{path: "/pets", method: "POST", description: "Add pet"},
+	}, []string{})
+	routes[1].Parameters = map[string]models.ParameterEdit{
+		"X-Trace":     {Hidden: true},
+		"body.nm":     {Description: "The pet's name"},
+		"body.status": {Constant: "sold"},
+	}
+
+	merged, err := MergeAdjustments([]byte(savedAdjustments), routes)
+	require.NoError(t, err)
+	// Templates, default values, renames, other sections and the entries for
+	// /owners, which is missing from the spec, are kept, and so are the
+	// comments and the order of the file
+	assert.Equal(t, `# Adjustments for the pet store
+descriptions:
+    - path: /pets
+      updates:
+        - method: GET
+          template: '{{ .summary }}'
+          new_description: Every pet
+    - path: /owners
+      updates:
+        - method: GET
+          new_description: Owners
+routes:
+    # Owners are not in the spec yet
+    - path: /owners
+      methods:
+        - GET
+    - path: /pets
+      methods:
+        - GET
+        - POST
+defaults:
+    - path: /pets
+      updates:
+        - method: POST
+          arguments:
+            body.status: available
+          constants:
+            body.status: sold
+parameters:
+    - path: /pets
+      updates:
+        - method: POST
+          rename:
+            body.nm: name
+          hide:
+            - X-Trace
+          descriptions:
+            body.nm: The pet's name
+cache:
+    - path: /pets
+      updates:
+        - method: GET
+          max_age: 1m
+`, string(merged))
+}
+
+func TestSaveAdjustments(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "adjustments.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(savedAdjustments), 0o600))
+	adjuster := parser.NewAdjuster()
+	require.NoError(t, adjuster.Load(file))
+
+	routeTools := []*parser.RouteTool{
+		{RouteConfig: &requester.RouteConfig{Method: "GET", Path: "/pets"}, Tool: mcp.Tool{Name: "get_pets"}},
+		{RouteConfig: &requester.RouteConfig{Method: "POST", Path: "/pets"}, Tool: mcp.Tool{Name: "post_pets"}},
+	}
+	m := NewListItemModel(routeTools, adjuster).WithAdjustmentsFile(file)
+	m = update(t, m, tea.WindowSizeMsg{Width: 120, Height: 80})
+
+	// Nothing is autosaved before the first edit
+	m = update(t, m, autosaveMsg{})
+	assert.NoFileExists(t, RecoveryFile(file))
+
+	m.list.Select(2)
+	m = update(t, m, keyPress("x"))
+	m = update(t, m, autosaveMsg{})
+	recovery, err := os.ReadFile(filepath.Join(filepath.Dir(file), "adjustments.recovery.yaml"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(recovery), "- POST")
+
+	m = update(t, m, tea.KeyMsg{Type: tea.KeyCtrlS})
+	assert.False(t, m.edited())
+	assert.NoFileExists(t, RecoveryFile(file))
+	saved, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, string(recovery), string(saved))
+
+	backups, err := filepath.Glob(file + ".*.bak")
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	backup, err := os.ReadFile(backups[0])
+	require.NoError(t, err)
+	assert.Equal(t, savedAdjustments, string(backup))
+}
+
+func TestAppModel_SaveOnFinish(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "adjustments.yaml")
+	routeTools := []*parser.RouteTool{
+		summaryRoute("GET", "/pets", "pet", "pets", "List pets").Tool,
+		summaryRoute("DELETE", "/pets", "pet", "deletePets", "Delete pets").Tool,
+	}
+	var m tea.Model = NewAppModel(routeTools, parser.NewAdjuster()).WithAdjustmentsFile(file).WithSaveOnFinish()
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m, _ = m.Update(cmd())
+
+	// Remove DELETE /pets, the first route by path then method
+	m, _ = m.Update(keyPress("s"))
+	m, _ = m.Update(keyPress("x"))
+	m, cmd = m.Update(keyPress("F"))
+	m, _ = m.Update(cmd())
+	assert.Contains(t, m.View(), "enter to save and serve")
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m, _ = m.Update(cmd())
+	require.NoError(t, m.(AppModel).Err())
+	assert.True(t, m.(AppModel).IsFinished())
+
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, `routes:
+    - path: /pets
+      methods:
+        - GET
+`, string(data))
+}
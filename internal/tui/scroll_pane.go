@@ -0,0 +1,71 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/brizzai/auto-mcp/internal/tui/models"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// scrollPaneChrome is the height of the pane's header and footer lines
+const scrollPaneChrome = 4
+
+// ScrollPane shows read-only text, such as a tool preview, in a scrollable
+// pane.
+type ScrollPane struct {
+	title    string
+	help     string // The keys besides scrolling
+	viewport viewport.Model
+}
+
+// NewScrollPane creates a ScrollPane showing content under title.
+func NewScrollPane(title, content string, width, height int) ScrollPane {
+	vp := viewport.New(width, max(height-scrollPaneChrome, 1))
+	vp.SetContent(content)
+	return ScrollPane{title: title, help: "esc to close", viewport: vp}
+}
+
+// WithHelp returns the pane describing the keys besides scrolling with help
+func (m ScrollPane) WithHelp(help string) ScrollPane {
+	m.help = help
+	return m
+}
+
+// NewToolPreview creates a ScrollPane showing the tool item generates with
+// its edits, as JSON.
+func NewToolPreview(item models.RouteToolItem, width, height int) ScrollPane {
+	content, err := json.MarshalIndent(item.PreviewTool(), "", "  ")
+	if err != nil {
+		return NewScrollPane(item.Title(), statusMessageStyle(err.Error()), width, height)
+	}
+	return NewScrollPane(item.Title(), string(content), width, height)
+}
+
+// Update scrolls the pane.
+func (m ScrollPane) Update(msg tea.Msg) (ScrollPane, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if msg.Type == tea.KeyCtrlC {
+			return m, tea.Quit
+		}
+	case tea.WindowSizeMsg:
+		m.viewport.Width = msg.Width
+		m.viewport.Height = max(msg.Height-scrollPaneChrome, 1)
+	}
+
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+	return m, cmd
+}
+
+// View renders the pane UI.
+func (m ScrollPane) View() string {
+	return fmt.Sprintf(
+		"%s\n\n%s\n\n%s",
+		editHeaderStyle.Render(m.title),
+		m.viewport.View(),
+		fmt.Sprintf("(%3.f%%, ↑/↓ to scroll, %s)", m.viewport.ScrollPercent()*100, m.help),
+	)
+}
@@ -0,0 +1,50 @@
+package tui
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/brizzai/auto-mcp/internal/tui/models"
+)
+
+// routeOrder is the order of the routes in the list
+type routeOrder int
+
+const (
+	// byTag groups the routes under a header per tag, by path within a tag
+	byTag routeOrder = iota
+	// byPath lists the routes by path, then method
+	byPath
+	// byMethod lists the routes by method, then path
+	byMethod
+)
+
+func (o routeOrder) String() string {
+	switch o {
+	case byPath:
+		return "path"
+	case byMethod:
+		return "method"
+	}
+	return "tag"
+}
+
+// next returns the order the sort key switches to
+func (o routeOrder) next() routeOrder {
+	return (o + 1) % (byMethod + 1)
+}
+
+// orderRoutes sorts routes in order
+func orderRoutes(routes []models.RouteToolItem, order routeOrder) {
+	slices.SortStableFunc(routes, func(a, b models.RouteToolItem) int {
+		path := cmp.Compare(a.Tool.RouteConfig.Path, b.Tool.RouteConfig.Path)
+		method := cmp.Compare(a.Tool.RouteConfig.Method, b.Tool.RouteConfig.Method)
+		switch order {
+		case byPath:
+			return cmp.Or(path, method)
+		case byMethod:
+			return cmp.Or(method, path)
+		}
+		return cmp.Or(cmp.Compare(a.Tag(), b.Tag()), path, method)
+	})
+}
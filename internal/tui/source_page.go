@@ -0,0 +1,186 @@
+package tui
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/brizzai/auto-mcp/internal/parser"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// specTimeout bounds downloading a spec
+const specTimeout = 30 * time.Second
+
+// specLoadedMsg carries the routes of the spec loaded on the source page
+type specLoadedMsg struct {
+	source     string
+	routeTools []*parser.RouteTool
+	err        error
+}
+
+// specFile returns source when it is the path of a spec file, "" for URLs and
+// pasted specs
+func specFile(source string) string {
+	if strings.HasPrefix(source, "{") || strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		return ""
+	}
+	return source
+}
+
+// LoadSpec returns the routes of the spec at source: a URL, downloaded with
+// header ("Name: value", may be empty), a pasted JSON spec or a file path.
+func LoadSpec(ctx context.Context, source, header string) ([]*parser.RouteTool, error) {
+	data, err := fetchSpec(ctx, source, header)
+	if err != nil {
+		return nil, err
+	}
+	// Adjustments are not applied, the builder edits them
+	swaggerParser := parser.NewSwaggerParser(parser.NewAdjuster())
+	if err := swaggerParser.ParseReader(bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	return swaggerParser.GetRouteTools(), nil
+}
+
+// fetchSpec returns the content of the spec at source
+func fetchSpec(ctx context.Context, source, header string) ([]byte, error) {
+	switch {
+	case strings.HasPrefix(source, "{"):
+		return []byte(source), nil
+	case !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://"):
+		data, err := os.ReadFile(source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read spec file: %w", err)
+		}
+		return data, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, specTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return nil, err
+	}
+	if header != "" {
+		name, value, ok := strings.Cut(header, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("header %q is not of the form Name: value", header)
+		}
+		req.Header.Set(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download spec: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download spec: %s", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// SourcePage prompts for the spec to edit when none was given on the
+// command line, and loads it.
+type SourcePage struct {
+	source  textinput.Model
+	header  textinput.Model
+	loading bool
+	err     error
+}
+
+// NewSourcePage creates a SourcePage with the spec input focused.
+func NewSourcePage() SourcePage {
+	source := textinput.New()
+	source.Prompt = "spec: "
+	source.Placeholder = "file path, URL or pasted JSON spec"
+	source.Focus()
+
+	header := textinput.New()
+	header.Prompt = "header: "
+	header.Placeholder = "sent with URLs, e.g. Authorization: Bearer <token>"
+	return SourcePage{source: source, header: header}
+}
+
+// Update handles messages for the source page.
+func (m SourcePage) Update(msg tea.Msg) (SourcePage, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "tab", "shift+tab", "up", "down":
+			if m.source.Focused() {
+				m.source.Blur()
+				return m, m.header.Focus()
+			}
+			m.header.Blur()
+			return m, m.source.Focus()
+		case "enter":
+			return m.load()
+		}
+
+	case specLoadedMsg:
+		m.loading = false
+		m.err = msg.err
+		if msg.err == nil && len(msg.routeTools) == 0 {
+			m.err = fmt.Errorf("the spec has no operations")
+		}
+		return m, nil
+
+	case tea.WindowSizeMsg:
+		m.source.Width = msg.Width - len(m.source.Prompt) - 1
+		m.header.Width = msg.Width - len(m.header.Prompt) - 1
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	if m.source.Focused() {
+		m.source, cmd = m.source.Update(msg)
+	} else {
+		m.header, cmd = m.header.Update(msg)
+	}
+	return m, cmd
+}
+
+// load loads the entered spec
+func (m SourcePage) load() (SourcePage, tea.Cmd) {
+	source := strings.TrimSpace(m.source.Value())
+	if m.loading || source == "" {
+		return m, nil
+	}
+	m.loading = true
+	m.err = nil
+	header := strings.TrimSpace(m.header.Value())
+	return m, func() tea.Msg {
+		routeTools, err := LoadSpec(context.Background(), source, header)
+		return specLoadedMsg{source: source, routeTools: routeTools, err: err}
+	}
+}
+
+// View renders the source page UI.
+func (m SourcePage) View() string {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("MCP API Routes Manager"))
+	b.WriteString("\n\nWhich Swagger/OpenAPI spec do you want to edit?\n\n")
+	b.WriteString(m.source.View())
+	b.WriteString("\n")
+	b.WriteString(m.header.View())
+	b.WriteString("\n\n")
+
+	switch {
+	case m.loading:
+		b.WriteString("Loading...")
+	case m.err != nil:
+		b.WriteString(statusMessageStyle(m.err.Error()))
+	default:
+		b.WriteString("(enter to load, tab to move, ctrl+c to quit)")
+	}
+	return docStyle.Render(b.String())
+}
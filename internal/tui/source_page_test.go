@@ -0,0 +1,97 @@
+package tui
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/brizzai/auto-mcp/internal/parser"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sourceSpec = `{
+  "openapi": "3.0.0",
+  "info": {"title": "Pets", "version": "1.0.0"},
+  "paths": {
+    "/pets": {
+      "get": {"operationId": "listPets", "responses": {"200": {"description": "ok"}}},
+      "post": {"operationId": "createPet", "responses": {"201": {"description": "created"}}}
+    }
+  }
+}`
+
+func TestLoadSpec(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(sourceSpec))
+	}))
+	defer server.Close()
+
+	file := filepath.Join(t.TempDir(), "swagger.json")
+	require.NoError(t, os.WriteFile(file, []byte(sourceSpec), 0o644))
+
+	tests := []struct {
+		name    string
+		source  string
+		header  string
+		wantErr string
+	}{
+		{name: "URL with header", source: server.URL, header: "Authorization: Bearer secret"},
+		{name: "URL without header", source: server.URL, wantErr: "failed to download spec: 401 Unauthorized"},
+		{name: "malformed header", source: server.URL, header: "Bearer secret", wantErr: `header "Bearer secret" is not of the form Name: value`},
+		{name: "file", source: file},
+		{name: "missing file", source: filepath.Join(t.TempDir(), "missing.json"), wantErr: "failed to read spec file"},
+		// Pasting into the input turns newlines into spaces
+		{name: "pasted JSON", source: `{"openapi": "3.0.0", "info": {"title": "Pets", "version": "1.0.0"}, "paths": {"/pets": {"get": {"responses": {"200": {"description": "ok"}}}, "post": {"responses": {"201": {"description": "created"}}}}}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			routeTools, err := LoadSpec(context.Background(), tt.source, tt.header)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, routeTools, 2)
+		})
+	}
+}
+
+func TestAppModel_SpecPrompt(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "swagger.json")
+	require.NoError(t, os.WriteFile(file, []byte(sourceSpec), 0o644))
+
+	var m tea.Model = NewAppModel(nil, parser.NewAdjuster()).WithSpecPrompt()
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	assert.Contains(t, m.View(), "Which Swagger/OpenAPI spec do you want to edit?")
+
+	// Enter without a spec does nothing
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Nil(t, cmd)
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(filepath.Join(t.TempDir(), "missing.json")), Paste: true})
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	require.NotNil(t, cmd)
+	assert.Contains(t, m.View(), "Loading...")
+	m, _ = m.Update(cmd())
+	assert.Contains(t, m.View(), "failed to read spec file")
+
+	app := m.(AppModel)
+	app.sourcePage.source.SetValue(file)
+	m, cmd = app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	require.NotNil(t, cmd)
+	m, _ = m.Update(cmd())
+	app = m.(AppModel)
+	assert.Equal(t, "main", app.page)
+	assert.Contains(t, app.View(), "POST /pets")
+	assert.Len(t, app.GetRoutesUpdates(), 2)
+}
@@ -1,23 +1,126 @@
 package tui
 
-import "github.com/charmbracelet/lipgloss"
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/brizzai/auto-mcp/internal/tui/models"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// Theme is the palette the TUI is styled with
+type Theme struct {
+	Accent   lipgloss.TerminalColor // Titles, headers and borders
+	OnAccent lipgloss.TerminalColor // Text of titles, drawn on the accent
+	Selected lipgloss.TerminalColor // The selected route
+	Status   lipgloss.TerminalColor // Status messages and errors
+	Success  lipgloss.TerminalColor
+	Muted    lipgloss.TerminalColor // Help text
+	Removed  lipgloss.TerminalColor // Routes removed from the MCP list
+}
+
+// DefaultTheme is the theme used unless another is chosen
+const DefaultTheme = "dark"
+
+// themes are the themes by name. high-contrast sticks to the 16 ANSI colors,
+// which terminals map to their own readable palette.
+var themes = map[string]Theme{
+	"dark": {
+		Accent:   lipgloss.Color("#f56a96"),
+		OnAccent: lipgloss.Color("#15202b"),
+		Selected: lipgloss.Color("#EE6FF8"),
+		Status:   lipgloss.AdaptiveColor{Light: "#f56a96", Dark: "#f23a74"},
+		Success:  lipgloss.Color("#56FF4E"),
+		Muted:    lipgloss.AdaptiveColor{Light: "#626262", Dark: "#A49FA5"},
+		Removed:  lipgloss.Color("#FF0000"),
+	},
+	"light": {
+		Accent:   lipgloss.Color("#b4235a"),
+		OnAccent: lipgloss.Color("#ffffff"),
+		Selected: lipgloss.Color("#7d2ea8"),
+		Status:   lipgloss.Color("#b00020"),
+		Success:  lipgloss.Color("#1a7f37"),
+		Muted:    lipgloss.Color("#5c5c5c"),
+		Removed:  lipgloss.Color("#b00020"),
+	},
+	"high-contrast": {
+		Accent:   lipgloss.Color("11"),
+		OnAccent: lipgloss.Color("0"),
+		Selected: lipgloss.Color("14"),
+		Status:   lipgloss.Color("9"),
+		Success:  lipgloss.Color("10"),
+		Muted:    lipgloss.Color("15"),
+		Removed:  lipgloss.Color("9"),
+	},
+	"no-color": {
+		Accent:   lipgloss.NoColor{},
+		OnAccent: lipgloss.NoColor{},
+		Selected: lipgloss.NoColor{},
+		Status:   lipgloss.NoColor{},
+		Success:  lipgloss.NoColor{},
+		Muted:    lipgloss.NoColor{},
+		Removed:  lipgloss.NoColor{},
+	},
+}
 
 var (
+	titleStyle           lipgloss.Style
+	editHeaderStyle      lipgloss.Style
+	statusMessageStyle   func(strs ...string) string
+	completeMessageStyle func(strs ...string) string
+
+	// theme is the theme the styles were built from
+	theme Theme
+)
+
+var docStyle = lipgloss.NewStyle().Margin(1, 2)
+
+func init() {
+	applyTheme(themes[DefaultTheme])
+}
+
+// ThemeNames lists the names of the themes, sorted.
+func ThemeNames() []string {
+	return slices.Sorted(maps.Keys(themes))
+}
+
+// SetTheme styles the TUI with the theme called name. no-color also drops
+// the colors of the list and its help, so nothing but text is written.
+func SetTheme(name string) error {
+	t, ok := themes[name]
+	if !ok {
+		return fmt.Errorf("unknown theme %q, use one of %s", name, strings.Join(ThemeNames(), ", "))
+	}
+	if name == "no-color" {
+		lipgloss.SetColorProfile(termenv.Ascii)
+	}
+	applyTheme(t)
+	return nil
+}
+
+// applyTheme builds the styles from t
+func applyTheme(t Theme) {
+	theme = t
 	titleStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#15202b")).
-			Background(lipgloss.Color("#f56a96")).
-			Padding(0, 1)
+		Foreground(t.OnAccent).
+		Background(t.Accent).
+		Padding(0, 1)
 
 	editHeaderStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#f56a96")).
-			Padding(0, 1)
+		Foreground(t.Accent).
+		Padding(0, 1)
 
 	statusMessageStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.AdaptiveColor{Light: "#f56a96", Dark: "#f23a74"}).
-				Render
+		Foreground(t.Status).
+		Render
 
 	completeMessageStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("#56FF4E")).
-				Render
-)
-var docStyle = lipgloss.NewStyle().Margin(1, 2)
+		Foreground(t.Success).
+		Render
+
+	models.RemovedStyle = lipgloss.NewStyle().Foreground(t.Removed)
+	models.BadgeStyle = lipgloss.NewStyle().Foreground(t.Accent)
+}
@@ -0,0 +1,27 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetTheme(t *testing.T) {
+	profile := lipgloss.ColorProfile()
+	t.Cleanup(func() {
+		lipgloss.SetColorProfile(profile)
+		require.NoError(t, SetTheme(DefaultTheme))
+	})
+
+	assert.EqualError(t, SetTheme("pink"), `unknown theme "pink", use one of dark, high-contrast, light, no-color`)
+
+	require.NoError(t, SetTheme("light"))
+	assert.Equal(t, themes["light"].Accent, editHeaderStyle.GetForeground())
+	assert.Equal(t, themes["light"].Accent, titleStyle.GetBackground())
+
+	require.NoError(t, SetTheme("no-color"))
+	assert.Equal(t, lipgloss.NoColor{}, titleStyle.GetBackground())
+	assert.Equal(t, "Error", statusMessageStyle("Error"))
+}
@@ -0,0 +1,113 @@
+package tui
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/brizzai/auto-mcp/internal/tui/models"
+)
+
+// suggestedDescriptionLength is the length descriptions are shortened to
+const suggestedDescriptionLength = 160
+
+// noisePath matches path segments of operational and internal routes, which
+// models rarely need: health checks, metrics, debugging and internal APIs
+var noisePath = regexp.MustCompile(`(?i)^(_.*|health|healthz|healthcheck|livez|readyz|ping|metrics|prometheus|debug|internal)$`)
+
+// methodRank ranks methods by how useful their tools usually are, lowest first
+var methodRank = map[string]int{"GET": 0, "POST": 1, "PUT": 2, "PATCH": 2, "DELETE": 3}
+
+// noise reports whether a segment of path is operational or internal
+func noise(path string) bool {
+	for _, segment := range strings.Split(path, "/") {
+		if noisePath.MatchString(segment) {
+			return true
+		}
+	}
+	return false
+}
+
+// suggestEdits proposes edits trimming routes to at most target tools:
+// operational and internal routes are removed, then deprecated routes and
+// the routes of the least useful methods until target are left. Long
+// descriptions of the routes kept are shortened. Routes already removed or
+// edited keep their edits. It returns a summary of the proposal.
+func suggestEdits(routes []models.RouteToolItem, target int) string {
+	var candidates []int // Indexes of the routes kept, most useful first
+	removed := 0
+	for i, route := range routes {
+		if route.IsRemoved {
+			continue
+		}
+		if noise(route.Tool.RouteConfig.Path) {
+			routes[i].IsRemoved = true
+			removed++
+			continue
+		}
+		candidates = append(candidates, i)
+	}
+	slices.SortStableFunc(candidates, func(a, b int) int {
+		ra, rb := routes[a].Tool, routes[b].Tool
+		return cmp.Or(
+			compareBool(ra.Deprecated, rb.Deprecated),
+			cmp.Compare(rank(ra.RouteConfig.Method), rank(rb.RouteConfig.Method)),
+			cmp.Compare(ra.RouteConfig.Path, rb.RouteConfig.Path),
+		)
+	})
+	for _, i := range candidates[min(target, len(candidates)):] {
+		routes[i].IsRemoved = true
+		removed++
+	}
+
+	shortened := 0
+	for _, i := range candidates[:min(target, len(candidates))] {
+		if routes[i].NewDescription != "" {
+			continue
+		}
+		if short, ok := shortenDescription(routes[i].Tool.RouteConfig.Description); ok {
+			routes[i].NewDescription = short
+			shortened++
+		}
+	}
+	return fmt.Sprintf("Suggested: %d routes removed, %d descriptions shortened, D shows the edits", removed, shortened)
+}
+
+// rank returns the rank of method, other methods such as HEAD last
+func rank(method string) int {
+	if r, ok := methodRank[method]; ok {
+		return r
+	}
+	return len(methodRank)
+}
+
+// compareBool orders false before true
+func compareBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return 1
+	}
+	return -1
+}
+
+// shortenDescription returns the first sentence of a description longer
+// than suggestedDescriptionLength, cut at a word when the sentence is long
+// too. ok is false when description is short enough.
+func shortenDescription(description string) (string, bool) {
+	description = strings.Join(strings.Fields(description), " ")
+	if len(description) <= suggestedDescriptionLength {
+		return "", false
+	}
+	if end := strings.Index(description, ". "); end > 0 && end < suggestedDescriptionLength {
+		return description[:end+1], true
+	}
+	short := description[:suggestedDescriptionLength]
+	if space := strings.LastIndex(short, " "); space > 0 {
+		short = short[:space]
+	}
+	return short + "…", true
+}
@@ -0,0 +1,65 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/brizzai/auto-mcp/internal/parser"
+	"github.com/brizzai/auto-mcp/internal/tui/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuggestEdits(t *testing.T) {
+	long := strings.Repeat("word ", 40)
+	deprecated := summaryRoute("GET", "/v1/pets", "pet", "oldPets", "")
+	deprecated.Tool.Deprecated = true
+	removed := summaryRoute("GET", "/owners", "owner", "owners", "")
+	removed.IsRemoved = true
+	routes := []models.RouteToolItem{
+		*summaryRoute("DELETE", "/pets/{id}", "pet", "deletePet", ""),
+		*summaryRoute("GET", "/healthz", "", "health", ""),
+		*summaryRoute("POST", "/pets", "pet", "createPet", "Create a pet. "+long),
+		*deprecated,
+		*summaryRoute("GET", "/pets", "pet", "listPets", long),
+		*summaryRoute("GET", "/_internal/cache", "", "cache", ""),
+		*summaryRoute("HEAD", "/pets", "pet", "headPets", ""),
+		*removed,
+	}
+
+	summary := suggestEdits(routes, 2)
+	assert.Equal(t, "Suggested: 5 routes removed, 2 descriptions shortened, D shows the edits", summary)
+
+	kept := make(map[string]string)
+	for _, route := range routes {
+		if !route.IsRemoved {
+			kept[route.ToolName()] = route.NewDescription
+		}
+	}
+	assert.Equal(t, map[string]string{
+		"listPets":  strings.TrimSpace(strings.Repeat("word ", 32)) + "…",
+		"createPet": "Create a pet.",
+	}, kept)
+}
+
+func TestSuggestEdits_UnderTarget(t *testing.T) {
+	routes := []models.RouteToolItem{
+		*summaryRoute("DELETE", "/pets/{id}", "pet", "deletePet", "Delete a pet"),
+		*summaryRoute("GET", "/metrics", "", "metrics", ""),
+	}
+	assert.Equal(t, "Suggested: 1 routes removed, 0 descriptions shortened, D shows the edits", suggestEdits(routes, 10))
+	assert.False(t, routes[0].IsRemoved)
+	assert.True(t, routes[1].IsRemoved)
+}
+
+func TestListItemModel_WithSuggestions(t *testing.T) {
+	routeTools := []*parser.RouteTool{
+		summaryRoute("GET", "/pets", "pet", "listPets", "List pets").Tool,
+		summaryRoute("GET", "/health", "", "health", "").Tool,
+	}
+	m := NewListItemModel(routeTools, parser.NewAdjuster()).WithSuggestions(5)
+	assert.Contains(t, m.list.Title, "Suggested: 1 routes removed")
+	for _, route := range m.routes {
+		assert.Equal(t, route.Tool.RouteConfig.Path == "/health", route.IsRemoved)
+		assert.Equal(t, route.Tool.RouteConfig.Path == "/health", route.Edited())
+	}
+}
@@ -0,0 +1,146 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/brizzai/auto-mcp/internal/parser"
+	"github.com/brizzai/auto-mcp/internal/tui/models"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// exportMsg is sent when the summary is confirmed, to prompt for the file
+// to export to
+type exportMsg struct{}
+
+// SummaryView sums up the tools the routes will generate before exporting
+// them, so their context cost can be gauged.
+type SummaryView struct {
+	pane ScrollPane
+}
+
+// NewSummaryView creates a SummaryView of routes for a window of width and
+// height.
+func NewSummaryView(routes []*models.RouteToolItem, width, height int) SummaryView {
+	h, v := docStyle.GetFrameSize()
+	pane := NewScrollPane("Summary", routesSummary(routes), width-h, height-v)
+	return SummaryView{pane: pane.WithHelp("enter to export, esc to go back")}
+}
+
+// WithHelp returns the summary with help, the keys shown under it
+func (m SummaryView) WithHelp(help string) SummaryView {
+	m.pane = m.pane.WithHelp(help)
+	return m
+}
+
+// Update handles messages for the summary.
+func (m SummaryView) Update(msg tea.Msg) (SummaryView, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "enter":
+			return m, func() tea.Msg { return exportMsg{} }
+		case "esc":
+			return m, func() tea.Msg { return BackToMainMsg{} }
+		}
+	case tea.WindowSizeMsg:
+		h, v := docStyle.GetFrameSize()
+		msg.Width -= h
+		msg.Height -= v
+		var cmd tea.Cmd
+		m.pane, cmd = m.pane.Update(msg)
+		return m, cmd
+	}
+	var cmd tea.Cmd
+	m.pane, cmd = m.pane.Update(msg)
+	return m, cmd
+}
+
+// View renders the summary UI.
+func (m SummaryView) View() string {
+	return docStyle.Render(m.pane.View())
+}
+
+// estimateTokens estimates how many tokens text takes in a model's context,
+// at about four characters a token
+func estimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+// routesSummary describes the tools the routes kept generate: how many by
+// method and tag, their estimated context cost and the problems of their
+// names and descriptions
+func routesSummary(routes []*models.RouteToolItem) string {
+	byMethod := make(map[string]int)
+	byTag := make(map[string]int)
+	operations := make(map[string][]string) // Operations by tool name
+	var descriptionTokens, toolTokens, kept int
+	var invalid, undescribed, generated []string
+	for _, route := range routes {
+		if route.IsRemoved {
+			continue
+		}
+		kept++
+		operation := route.Tool.RouteConfig.Method + " " + route.Tool.RouteConfig.Path
+		byMethod[route.Tool.RouteConfig.Method]++
+		byTag[route.Tag()]++
+
+		tool := route.PreviewTool()
+		descriptionTokens += estimateTokens(tool.Description)
+		if definition, err := json.Marshal(tool); err == nil {
+			toolTokens += estimateTokens(string(definition))
+		}
+		operations[tool.Name] = append(operations[tool.Name], operation)
+		if err := parser.ValidToolName(tool.Name); err != nil {
+			invalid = append(invalid, fmt.Sprintf("%s: %v", operation, err))
+		}
+		if strings.TrimSpace(route.RouteDescription()) == "" {
+			undescribed = append(undescribed, operation)
+		}
+		if route.Generated {
+			generated = append(generated, operation)
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Tools: %d of %d routes kept\n", kept, len(routes))
+	fmt.Fprintf(&b, "By method: %s\n", counts(byMethod))
+	fmt.Fprintf(&b, "By tag: %s\n", counts(byTag))
+	fmt.Fprintf(&b, "Estimated context cost: ~%d tokens of descriptions, ~%d tokens of tool definitions with their input schemas\n", descriptionTokens, toolTokens)
+
+	var duplicates []string
+	for _, name := range slices.Sorted(maps.Keys(operations)) {
+		if len(operations[name]) > 1 {
+			duplicates = append(duplicates, fmt.Sprintf("%s: %s", name, strings.Join(operations[name], ", ")))
+		}
+	}
+	problems := func(title string, lines []string) {
+		if len(lines) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "\n%s (%d):\n", title, len(lines))
+		for _, line := range lines {
+			fmt.Fprintf(&b, "  ! %s\n", line)
+		}
+	}
+	problems("Tool names used more than once, N renames them", duplicates)
+	problems("Invalid or over-long tool names, N renames them", invalid)
+	problems("Routes without a description, E describes them", undescribed)
+	problems("Generated descriptions to review, E edits or accepts them", generated)
+	return b.String()
+}
+
+// counts lists the counts by key, sorted by key
+func counts(byKey map[string]int) string {
+	if len(byKey) == 0 {
+		return "none"
+	}
+	var parts []string
+	for _, key := range slices.Sorted(maps.Keys(byKey)) {
+		parts = append(parts, fmt.Sprintf("%s %d", key, byKey[key]))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,84 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/brizzai/auto-mcp/internal/parser"
+	"github.com/brizzai/auto-mcp/internal/requester"
+	"github.com/brizzai/auto-mcp/internal/tui/models"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// summaryRoute creates a route generating a tool named name
+func summaryRoute(method, path, tag, name, description string) *models.RouteToolItem {
+	routeConfig := &requester.RouteConfig{Method: method, Path: path, Description: description}
+	if tag != "" {
+		routeConfig.Tags = []string{tag}
+	}
+	return &models.RouteToolItem{Tool: &parser.RouteTool{
+		RouteConfig: routeConfig,
+		Tool:        mcp.NewTool(name, mcp.WithDescription(method+" "+path+" \n "+description)),
+	}}
+}
+
+func TestRoutesSummary(t *testing.T) {
+	removed := summaryRoute("DELETE", "/pets/{id}", "pet", "deletePet", "Delete a pet")
+	removed.IsRemoved = true
+	renamed := summaryRoute("GET", "/store", "", "getStore", "Store inventory")
+	renamed.NewName = "store inventory"
+	renamed.NewDescription, renamed.Generated = "Store inventory", true
+
+	summary := routesSummary([]*models.RouteToolItem{
+		summaryRoute("GET", "/pets", "pet", "pets", "List pets"),
+		summaryRoute("POST", "/pets", "pet", "pets", ""),
+		removed,
+		renamed,
+	})
+
+	// 22, 13 and 28 characters of descriptions
+	assert.Contains(t, summary, `Tools: 3 of 4 routes kept
+By method: GET 2, POST 1
+By tag: pet 2, untagged 1
+Estimated context cost: ~17 tokens of descriptions, ~`)
+	assert.Contains(t, summary, `
+Tool names used more than once, N renames them (1):
+  ! pets: GET /pets, POST /pets
+
+Invalid or over-long tool names, N renames them (1):
+  ! GET /store: tool name "store inventory" must be 1 to 64 letters, digits, underscores or hyphens
+
+Routes without a description, E describes them (1):
+  ! POST /pets
+
+Generated descriptions to review, E edits or accepts them (1):
+  ! GET /store
+`)
+}
+
+func TestAppModel_SummaryBeforeExport(t *testing.T) {
+	routeTools := []*parser.RouteTool{summaryRoute("GET", "/pets", "pet", "pets", "List pets").Tool}
+	var m tea.Model = NewAppModel(routeTools, parser.NewAdjuster())
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m, _ = m.Update(cmd())
+	m, cmd = m.Update(keyPress("F"))
+	require.NotNil(t, cmd)
+	m, _ = m.Update(cmd())
+	assert.Equal(t, "summary", m.(AppModel).page)
+	assert.Contains(t, m.View(), "Tools: 1 of 1 routes kept")
+
+	// esc goes back to the routes
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m, _ = m.Update(cmd())
+	assert.Equal(t, "list", m.(AppModel).page)
+
+	m, cmd = m.Update(keyPress("F"))
+	m, _ = m.Update(cmd())
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m, _ = m.Update(cmd())
+	assert.Equal(t, "export", m.(AppModel).page)
+	assert.Contains(t, m.View(), "Enter filename to export routes:")
+}
@@ -0,0 +1,283 @@
+package tui
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/brizzai/auto-mcp/internal/requester"
+	"github.com/brizzai/auto-mcp/internal/tui/models"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// CallFunc sends the request of route with args to the upstream API
+type CallFunc func(ctx context.Context, route *requester.RouteConfig, args map[string]any) (*requester.Response, error)
+
+// tryChrome is the height of the try screen's lines besides the argument
+// inputs and the response
+const tryChrome = 8
+
+// tryField is the input of one tool argument
+type tryField struct {
+	name  string
+	kind  string // JSON schema type of the argument
+	input textinput.Model
+}
+
+// tryResultMsg carries the response of a request sent from the try screen
+type tryResultMsg struct {
+	route *requester.RouteConfig
+	resp  *requester.Response
+	err   error
+}
+
+// TryView prompts for the arguments of a route, sends its request to the
+// upstream API and shows the response.
+type TryView struct {
+	route       *requester.RouteConfig
+	call        CallFunc
+	fields      []tryField
+	focus       int
+	sending     bool
+	err         error
+	response    viewport.Model
+	hasResponse bool
+}
+
+// NewTryView creates a TryView with an input for every argument of the
+// route's tool, the required ones first.
+func NewTryView(item models.RouteToolItem, call CallFunc, width, height int) TryView {
+	schema := item.Tool.Tool.InputSchema
+	required := make(map[string]bool, len(schema.Required))
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if required[names[i]] != required[names[j]] {
+			return required[names[i]]
+		}
+		return names[i] < names[j]
+	})
+
+	fields := make([]tryField, len(names))
+	for i, name := range names {
+		property, _ := schema.Properties[name].(map[string]any)
+		kind, _ := property["type"].(string)
+		ti := textinput.New()
+		ti.Prompt = name + ": "
+		ti.Placeholder = kind
+		if required[name] {
+			ti.Placeholder += ", required"
+		}
+		ti.Width = width - len(ti.Prompt) - 1
+		fields[i] = tryField{name: name, kind: kind, input: ti}
+	}
+	if len(fields) > 0 {
+		fields[0].input.Focus()
+	}
+
+	vp := viewport.New(width, 0)
+	// Arrow keys move between the inputs, so only the page keys scroll
+	vp.KeyMap = viewport.KeyMap{
+		PageDown: key.NewBinding(key.WithKeys("pgdown")),
+		PageUp:   key.NewBinding(key.WithKeys("pgup")),
+	}
+	m := TryView{route: item.Tool.RouteConfig, call: call, fields: fields, response: vp}
+	m.setSize(height)
+	return m
+}
+
+// setSize gives the response the height left by the inputs
+func (m *TryView) setSize(height int) {
+	m.response.Height = max(height-len(m.fields)-tryChrome, 3)
+}
+
+// Update handles messages for the try screen.
+func (m TryView) Update(msg tea.Msg) (TryView, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "tab", "down":
+			m.moveFocus(1)
+			return m, nil
+		case "shift+tab", "up":
+			m.moveFocus(-1)
+			return m, nil
+		case "enter":
+			return m.send()
+		case "pgup", "pgdown":
+			var cmd tea.Cmd
+			m.response, cmd = m.response.Update(msg)
+			return m, cmd
+		}
+
+	case tryResultMsg:
+		// A response to a route tried before is dropped
+		if msg.route != m.route {
+			return m, nil
+		}
+		m.sending = false
+		m.err = msg.err
+		if msg.err == nil {
+			m.response.SetContent(formatResponse(msg.resp))
+			m.response.GotoTop()
+			m.hasResponse = true
+		}
+		return m, nil
+
+	case tea.WindowSizeMsg:
+		m.response.Width = msg.Width
+		m.setSize(msg.Height)
+		return m, nil
+	}
+
+	if len(m.fields) == 0 {
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.fields[m.focus].input, cmd = m.fields[m.focus].input.Update(msg)
+	return m, cmd
+}
+
+// moveFocus focuses the input delta positions away, wrapping around
+func (m *TryView) moveFocus(delta int) {
+	if len(m.fields) == 0 {
+		return
+	}
+	m.fields[m.focus].input.Blur()
+	m.focus = (m.focus + delta + len(m.fields)) % len(m.fields)
+	m.fields[m.focus].input.Focus()
+}
+
+// send sends the request with the entered arguments
+func (m TryView) send() (TryView, tea.Cmd) {
+	if m.sending {
+		return m, nil
+	}
+	args, err := m.arguments()
+	if err != nil {
+		m.err = err
+		return m, nil
+	}
+	m.sending = true
+	m.err = nil
+	route, call := m.route, m.call
+	return m, func() tea.Msg {
+		resp, err := call(context.Background(), route, args)
+		return tryResultMsg{route: route, resp: resp, err: err}
+	}
+}
+
+// arguments converts the entered values to arguments of the tool's types.
+// Empty inputs are left out.
+func (m TryView) arguments() (map[string]any, error) {
+	args := make(map[string]any)
+	for _, field := range m.fields {
+		text := strings.TrimSpace(field.input.Value())
+		if text == "" {
+			if strings.HasSuffix(field.input.Placeholder, "required") {
+				return nil, fmt.Errorf("%s is required", field.name)
+			}
+			continue
+		}
+		value, err := parseArgument(field.kind, text)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", field.name, err)
+		}
+		args[field.name] = value
+	}
+	return args, nil
+}
+
+// parseArgument converts text to a value of the JSON schema type kind.
+// Numbers keep their precision; objects and arrays are entered as JSON.
+func parseArgument(kind, text string) (any, error) {
+	switch kind {
+	case "string":
+		return text, nil
+	case "boolean":
+		value, err := strconv.ParseBool(text)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a boolean", text)
+		}
+		return value, nil
+	case "integer", "number":
+		if _, err := strconv.ParseFloat(text, 64); err != nil {
+			return nil, fmt.Errorf("%q is not a number", text)
+		}
+		return json.Number(text), nil
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(text))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil || decoder.More() {
+		if kind == "object" || kind == "array" {
+			return nil, fmt.Errorf("enter the %s as JSON", kind)
+		}
+		return text, nil
+	}
+	return value, nil
+}
+
+// formatResponse renders the status, content type and body of resp, with
+// JSON bodies indented
+func formatResponse(resp *requester.Response) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d %s\n", resp.StatusCode, http.StatusText(resp.StatusCode))
+	if contentType := resp.Headers.Get("Content-Type"); contentType != "" {
+		fmt.Fprintf(&b, "Content-Type: %s\n", contentType)
+	}
+	b.WriteString("\n")
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, resp.Body, "", "  "); err == nil {
+		b.Write(indented.Bytes())
+	} else {
+		b.Write(resp.Body)
+	}
+	return b.String()
+}
+
+// View renders the try screen UI.
+func (m TryView) View(title string) string {
+	var b strings.Builder
+	b.WriteString(editHeaderStyle.Render("Try " + title))
+	b.WriteString("\n\n")
+	if len(m.fields) == 0 {
+		b.WriteString("The route takes no arguments\n")
+	}
+	for _, field := range m.fields {
+		b.WriteString(field.input.View())
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	switch {
+	case m.sending:
+		b.WriteString("Sending...")
+	case m.err != nil:
+		b.WriteString(statusMessageStyle(m.err.Error()))
+	default:
+		b.WriteString("(enter to send, tab to move, pgup/pgdown to scroll, esc to close)")
+	}
+	if m.hasResponse {
+		b.WriteString("\n\n")
+		b.WriteString(m.response.View())
+	}
+	return b.String() + "\n"
+}
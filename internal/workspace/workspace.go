@@ -0,0 +1,193 @@
+// Package workspace manages the directory used for temporary files such as
+// downloaded artifacts and multipart buffers. Files are removed once they
+// outlive their TTL and the directory is kept under a size quota, so long
+// running servers do not slowly fill the disk.
+package workspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/brizzai/auto-mcp/internal/config"
+	"github.com/brizzai/auto-mcp/internal/logger"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// ErrQuotaExceeded is returned when the workspace is full even after cleanup
+var ErrQuotaExceeded = errors.New("workspace quota exceeded")
+
+// Module provides the workspace and runs its cleanup loop for the app lifetime
+var Module = fx.Options(
+	fx.Provide(NewFromConfig),
+	fx.Invoke(func(lc fx.Lifecycle, ws *Workspace) {
+		ctx, cancel := context.WithCancel(context.Background())
+		lc.Append(fx.Hook{
+			OnStart: func(context.Context) error {
+				go ws.Run(ctx)
+				return nil
+			},
+			OnStop: func(context.Context) error {
+				cancel()
+				return nil
+			},
+		})
+	}),
+)
+
+// Stats describes the workspace usage and cleanup activity
+type Stats struct {
+	Files        int
+	Bytes        int64
+	RemovedFiles int64
+	RemovedBytes int64
+	Rejected     int64
+}
+
+// Workspace is a managed temporary directory
+type Workspace struct {
+	cfg config.WorkspaceConfig
+	dir string
+	now func() time.Time
+
+	mu    sync.Mutex
+	stats Stats
+}
+
+// NewFromConfig creates the workspace described by the configuration
+func NewFromConfig(cfg *config.Config) (*Workspace, error) {
+	return New(cfg.Workspace)
+}
+
+// New creates the workspace directory if needed
+func New(cfg config.WorkspaceConfig) (*Workspace, error) {
+	dir := cfg.Directory()
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create workspace %s: %w", dir, err)
+	}
+	return &Workspace{cfg: cfg, dir: dir, now: time.Now}, nil
+}
+
+// Dir returns the workspace directory
+func (w *Workspace) Dir() string {
+	return w.dir
+}
+
+// CreateFile creates a new temporary file in the workspace, see os.CreateTemp.
+// Expired files are cleaned up first if the quota is exhausted.
+func (w *Workspace) CreateFile(pattern string) (*os.File, error) {
+	if w.cfg.MaxSizeBytes > 0 {
+		usage, err := w.Cleanup()
+		if err != nil {
+			return nil, err
+		}
+		if usage.Bytes >= w.cfg.MaxSizeBytes {
+			w.mu.Lock()
+			w.stats.Rejected++
+			w.mu.Unlock()
+			return nil, fmt.Errorf("%w: %d of %d bytes used", ErrQuotaExceeded, usage.Bytes, w.cfg.MaxSizeBytes)
+		}
+	}
+	return os.CreateTemp(w.dir, pattern)
+}
+
+// Run removes expired files on every cleanup interval until ctx is done
+func (w *Workspace) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.cfg.Interval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			stats, err := w.Cleanup()
+			if err != nil {
+				logger.Warn("Workspace cleanup failed", zap.Error(err))
+				continue
+			}
+			logger.Debug("Workspace cleanup",
+				zap.String("dir", w.dir),
+				zap.Int("files", stats.Files),
+				zap.Int64("bytes", stats.Bytes),
+				zap.Int64("removed_files", stats.RemovedFiles),
+				zap.Int64("removed_bytes", stats.RemovedBytes),
+			)
+		}
+	}
+}
+
+// Cleanup removes files older than the TTL, then the oldest files while the
+// workspace is over quota, and returns the resulting stats
+func (w *Workspace) Cleanup() (Stats, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	type entry struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	var files []entry
+	var total int64
+	ttl := w.cfg.TTLDuration()
+	now := w.now()
+
+	err := filepath.WalkDir(w.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			// Removed concurrently
+			return nil
+		}
+		if now.Sub(info.ModTime()) > ttl {
+			w.remove(path, info.Size())
+			return nil
+		}
+		files = append(files, entry{path: path, size: info.Size(), modTime: info.ModTime()})
+		total += info.Size()
+		return nil
+	})
+	if err != nil {
+		return w.stats, fmt.Errorf("failed to scan workspace: %w", err)
+	}
+
+	// Evict the oldest files until we are back under quota
+	if w.cfg.MaxSizeBytes > 0 && total > w.cfg.MaxSizeBytes {
+		sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+		for len(files) > 0 && total > w.cfg.MaxSizeBytes {
+			w.remove(files[0].path, files[0].size)
+			total -= files[0].size
+			files = files[1:]
+		}
+	}
+
+	w.stats.Files = len(files)
+	w.stats.Bytes = total
+	return w.stats, nil
+}
+
+// Stats returns the usage recorded by the last cleanup
+func (w *Workspace) Stats() Stats {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.stats
+}
+
+func (w *Workspace) remove(path string, size int64) {
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		logger.Warn("Failed to remove workspace file", zap.String("path", path), zap.Error(err))
+		return
+	}
+	w.stats.RemovedFiles++
+	w.stats.RemovedBytes += size
+}
@@ -0,0 +1,61 @@
+package workspace
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/brizzai/auto-mcp/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, ws *Workspace, size int, modTime time.Time) string {
+	t.Helper()
+	f, err := ws.CreateFile("test-*")
+	require.NoError(t, err)
+	_, err = f.Write(make([]byte, size))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	require.NoError(t, os.Chtimes(f.Name(), modTime, modTime))
+	return f.Name()
+}
+
+func TestWorkspace_CleanupRemovesExpiredFiles(t *testing.T) {
+	ws, err := New(config.WorkspaceConfig{Dir: t.TempDir(), TTL: time.Hour})
+	require.NoError(t, err)
+
+	now := time.Now()
+	expired := writeFile(t, ws, 10, now.Add(-2*time.Hour))
+	fresh := writeFile(t, ws, 20, now)
+
+	stats, err := ws.Cleanup()
+	require.NoError(t, err)
+
+	assert.NoFileExists(t, expired)
+	assert.FileExists(t, fresh)
+	assert.Equal(t, Stats{Files: 1, Bytes: 20, RemovedFiles: 1, RemovedBytes: 10}, stats)
+}
+
+func TestWorkspace_Quota(t *testing.T) {
+	ws, err := New(config.WorkspaceConfig{Dir: t.TempDir(), MaxSizeBytes: 100})
+	require.NoError(t, err)
+
+	now := time.Now()
+	oldest := writeFile(t, ws, 60, now.Add(-time.Minute))
+	newest := writeFile(t, ws, 60, now)
+
+	// Over quota: the oldest file is evicted
+	stats, err := ws.Cleanup()
+	require.NoError(t, err)
+	assert.NoFileExists(t, oldest)
+	assert.FileExists(t, newest)
+	assert.Equal(t, int64(60), stats.Bytes)
+
+	// Full after cleanup: new files are rejected
+	ws.cfg.MaxSizeBytes = 60
+	_, err = ws.CreateFile("test-*")
+	assert.True(t, errors.Is(err, ErrQuotaExceeded))
+	assert.Equal(t, int64(1), ws.Stats().Rejected)
+}
@@ -0,0 +1,73 @@
+// Package hooks defines the interfaces auto-mcp plugins implement to add
+// business logic, such as injecting fields into upstream requests or
+// scrubbing responses, without forking the project.
+//
+// A plugin is a Go plugin (go build -buildmode=plugin) exporting a New
+// function with the NewFunc signature. The value it returns implements any
+// of PreRequestHook, PostResponseHook and ToolFilterHook. Plugins must be
+// built with the same Go version and the same version of this module as the
+// auto-mcp binary loading them.
+package hooks
+
+import (
+	"context"
+	"net/http"
+)
+
+// NewSymbol is the name of the function every plugin exports
+const NewSymbol = "New"
+
+// NewFunc creates a plugin instance from the config map of its entry in
+// config.yaml
+type NewFunc = func(config map[string]string) (any, error)
+
+// Request is an upstream request about to be sent. Hooks may change any
+// field; the request is rebuilt from them.
+type Request struct {
+	// Tool is the name of the tool being called, including the tool prefix
+	Tool   string
+	Method string
+	URL    string
+	Header http.Header
+	// Body is the encoded request body, gzipped when request compression applies
+	Body []byte
+}
+
+// Response is an upstream response before auto-mcp turns it into a tool
+// result. Hooks may change the status code, headers and body.
+type Response struct {
+	Tool   string
+	Method string
+	URL    string
+
+	StatusCode int
+	Header     http.Header
+	// Body is the decoded response body
+	Body []byte
+}
+
+// Tool describes a generated tool
+type Tool struct {
+	Name        string
+	Method      string
+	Path        string
+	Description string
+	Tags        []string
+}
+
+// PreRequestHook runs before every upstream request. Returning an error
+// fails the tool call with that error.
+type PreRequestHook interface {
+	PreRequest(ctx context.Context, req *Request) error
+}
+
+// PostResponseHook runs after every upstream response, before caching.
+// Returning an error fails the tool call with that error.
+type PostResponseHook interface {
+	PostResponse(ctx context.Context, resp *Response) error
+}
+
+// ToolFilterHook decides which generated tools are registered
+type ToolFilterHook interface {
+	IncludeTool(tool Tool) bool
+}